@@ -0,0 +1,104 @@
+package main
+
+import (
+	"GophKeeper/internal/config"
+	"GophKeeper/internal/service"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"text/tabwriter"
+)
+
+const adminUsage = "usage: gkserver admin users | disable|enable|revoke-sessions <login> | " +
+	"delete-user <login> --yes | gc-stats | config"
+
+// runAdmin выполняет gkserver admin <команда> напрямую с БД и хранилищем блобов сервера.
+func runAdmin(ctx context.Context, cfg *config.Config, admin *service.AdminService, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(adminUsage)
+	}
+	switch cmd := args[0]; {
+	case cmd == "users" && len(args) == 1:
+		return printUsers(ctx, admin, out)
+	case (cmd == "disable" || cmd == "enable") && len(args) == 2:
+		if err := admin.SetDisabled(ctx, args[1], cmd == "disable"); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%s %sd\n", args[1], cmd)
+		return nil
+	case cmd == "revoke-sessions" && len(args) == 2:
+		if err := admin.RevokeSessions(ctx, args[1]); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "sessions of %s revoked\n", args[1])
+		return nil
+	case cmd == "delete-user" && len(args) == 3 && args[2] == "--yes":
+		blobs, err := admin.DeleteUser(ctx, args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "user %s deleted (%d blobs removed)\n", args[1], blobs)
+		return nil
+	case cmd == "delete-user" && len(args) == 2:
+		return fmt.Errorf("delete-user removes all data of %s irreversibly; add --yes to confirm", args[1])
+	case cmd == "gc-stats" && len(args) == 1:
+		return printBlobStats(ctx, admin, out)
+	case cmd == "config" && len(args) == 1:
+		return printConfig(cfg.Redacted(), out)
+	default:
+		return errors.New(adminUsage)
+	}
+}
+
+func printUsers(ctx context.Context, admin *service.AdminService, out io.Writer) error {
+	users, err := admin.Users(ctx)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tLOGIN\tSTATUS\tITEMS\tBLOB BYTES\tCREATED AT\tSESSIONS REVOKED AT")
+	for _, u := range users {
+		status, revoked := "active", "-"
+		if u.State.Disabled {
+			status = "disabled"
+		}
+		if u.State.SessionsRevokedAt != nil {
+			revoked = u.State.SessionsRevokedAt.UTC().Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%d\t%s\t%s\n", u.ID, u.Login, status, u.Usage.Items, u.Usage.BlobBytes,
+			u.CreatedAt.UTC().Format("2006-01-02 15:04:05"), revoked)
+	}
+	return tw.Flush()
+}
+
+func printBlobStats(ctx context.Context, admin *service.AdminService, out io.Writer) error {
+	st, err := admin.BlobStats(ctx)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "blobs\t%d\t%d bytes\n", st.Blobs, st.Bytes)
+	fmt.Fprintf(tw, "orphaned blobs\t%d\t%d bytes (not referenced by items or history)\n", st.Orphaned, st.OrphanedBytes)
+	fmt.Fprintf(tw, "inline blobs\t%d\t(move with gkserver migrate-blobs)\n", st.Inline)
+	fmt.Fprintf(tw, "deleted items\t%d\t\n", st.Tombstones)
+	fmt.Fprintf(tw, "history versions\t%d\t\n", st.Versions)
+	fmt.Fprintf(tw, "orphaned versions\t%d\t(item no longer exists)\n", st.OrphanedVersions)
+	return tw.Flush()
+}
+
+// printConfig выводит действующую конфигурацию как NAME=value по тегам env.
+func printConfig(cfg config.Config, out io.Writer) error {
+	v := reflect.ValueOf(cfg)
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Tag.Get("env")
+		if name == "" || name == "-" {
+			continue
+		}
+		if _, err := fmt.Fprintf(out, "%s=%v\n", name, v.Field(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
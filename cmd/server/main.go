@@ -2,18 +2,27 @@ package main
 
 import (
 	"GophKeeper/internal/config"
+	"GophKeeper/internal/grpcapi"
 	"GophKeeper/internal/handlers"
+	"GophKeeper/internal/metrics"
 	"GophKeeper/internal/middleware"
 	"GophKeeper/internal/repo"
 	"GophKeeper/internal/service"
+	"GophKeeper/internal/telemetry"
 	"context"
+	"crypto/tls"
 	"errors"
+	"flag"
+	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"time"
 
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 func main() {
@@ -39,6 +48,27 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// gkserver migrate up|down|status работает до проверки схемы
+	if args := flag.Args(); len(args) > 0 && args[0] == "migrate" {
+		if err := runMigrate(ctx, cfg.DatabaseDSN, args[1:], os.Stdout); err != nil {
+			sugar.Fatalw("migrate failed", "error", err)
+		}
+		return
+	}
+
+	// трассировка OpenTelemetry: при остановке отправляем накопленные спаны
+	shutdownTracing, err := telemetry.Setup(ctx, cfg.TracesExporter, cfg.OTLPEndpoint, "gkserver")
+	if err != nil {
+		sugar.Fatalw("failed to initialize tracing", "error", err)
+	}
+	defer func() {
+		flushCtx, flushCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer flushCancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			sugar.Errorw("Failed to flush traces", "error", err)
+		}
+	}()
+
 	gormDB, err := repo.InitDB(cfg.DatabaseDSN)
 	if err != nil {
 		sugar.Fatalw("failed to initialize database", "error", err)
@@ -46,11 +76,64 @@ func main() {
 
 	userRepo := repo.NewUserRepository(gormDB)
 	userService := service.NewUserService(userRepo)
-	itemRepo := repo.NewItemRepository(gormDB)
+	userService.SetDeviceRepository(repo.NewDeviceRepository(gormDB))
+	userService.SetKeyRepository(repo.NewKeyRepository(gormDB))
+	adminRepo := repo.NewAdminRepository(gormDB)
+	userService.SetAdminRepository(adminRepo)
+	// токены отключённых и удалённых пользователей и отозванные сессии отклоняются в HTTP и gRPC API
+	middleware.SetSessionCheck(userService.SessionActive)
+	blobStore, err := newBlobStore(cfg)
+	if err != nil {
+		sugar.Fatalw("failed to initialize blob storage", "error", err)
+	}
+
+	itemRepo := repo.NewItemRepositoryWithHistory(gormDB, repo.HistoryRetention{
+		MaxVersions: cfg.HistoryMaxVersions,
+		MaxAge:      time.Duration(cfg.HistoryMaxAgeDays) * 24 * time.Hour,
+	})
 	blobRepo := repo.NewBlobRepository(gormDB)
-	itemService := service.NewItemService(itemRepo, blobRepo, sugar)
+	itemService := service.NewItemService(itemRepo, blobRepo, blobStore, sugar)
+	itemService.SetShareRepository(repo.NewShareRepository(gormDB))
+	itemService.SetOrgRepository(repo.NewOrgRepository(gormDB))
+	itemService.SetEmergencyRepository(repo.NewEmergencyRepository(gormDB))
+	itemService.SetQuota(service.Quota{
+		MaxItems:     cfg.QuotaItems,
+		MaxBlobBytes: cfg.QuotaBlobMB * 1024 * 1024,
+		MaxItemBytes: cfg.QuotaItemKB * 1024,
+	})
+
+	// Служебные подкоманды: gkserver migrate-blobs, gkserver admin ...
+	if args := flag.Args(); len(args) > 0 {
+		switch args[0] {
+		case "admin":
+			adminService := service.NewAdminService(adminRepo, userRepo, itemService)
+			if err := runAdmin(ctx, cfg, adminService, args[1:], os.Stdout); err != nil {
+				sugar.Fatalw("admin command failed", "error", err)
+			}
+			return
+		case "migrate-blobs":
+			moved, err := itemService.MigrateInlineBlobs(ctx, 100)
+			if err != nil {
+				sugar.Fatalw("blob migration failed", "moved", moved, "error", err)
+			}
+			sugar.Infow("blob migration completed", "moved", moved, "storage", cfg.BlobStorage)
+			return
+		default:
+			sugar.Fatalw("unknown command", "command", args[0])
+		}
+	}
 
 	h := handlers.NewHandler(userService, itemService, sugar, cfg)
+	ready, err := repo.ReadinessCheck(gormDB)
+	if err != nil {
+		sugar.Fatalw("failed to initialize readiness check", "error", err)
+	}
+	h.SetReadinessCheck(ready)
+	if sqlDB, err := gormDB.DB(); err == nil {
+		if err := metrics.RegisterDB(sqlDB, gormDB.Dialector.Name()); err != nil {
+			sugar.Fatalw("failed to register DB metrics", "error", err)
+		}
+	}
 
 	addr := cfg.BaseURL
 
@@ -59,14 +142,57 @@ func main() {
 		"addr", addr,
 	)
 
+	// При ENABLE_HTTPS оба API работают по TLS с общим сертификатом, перечитываемым по SIGHUP
+	var (
+		tlsConfig  *tls.Config
+		grpcServer *grpc.Server
+	)
+	if cfg.TLSClientCA != "" && !cfg.EnableHTTPS {
+		sugar.Fatalw("TLS_CLIENT_CA requires ENABLE_HTTPS")
+	}
+	if cfg.EnableHTTPS {
+		certs, err := newCertReloader(cfg, sugar)
+		if err != nil {
+			sugar.Fatalw("failed to load TLS certificate", "error", err)
+		}
+		reloadOnSIGHUP(ctx, certs, sugar)
+		if tlsConfig, err = serverTLSConfig(cfg, certs); err != nil {
+			sugar.Fatalw("failed to configure TLS", "error", err)
+		}
+		grpcTLS, err := serverTLSConfig(cfg, certs)
+		if err != nil {
+			sugar.Fatalw("failed to configure TLS", "error", err)
+		}
+		grpcServer = grpcapi.NewServer(userService, itemService, sugar, cfg,
+			grpc.Creds(credentials.NewTLS(grpcTLS)))
+	} else {
+		grpcServer = grpcapi.NewServer(userService, itemService, sugar, cfg)
+	}
+
+	// gRPC API слушает отдельный адрес и работает поверх тех же сервисов
+	grpcLis, err := net.Listen("tcp", cfg.GRPCAddress)
+	if err != nil {
+		sugar.Fatalw("failed to listen gRPC address", "addr", cfg.GRPCAddress, "error", err)
+	}
+	go func() {
+		sugar.Infow("Starting gRPC server", "addr", cfg.GRPCAddress)
+		if err := grpcServer.Serve(grpcLis); err != nil {
+			sugar.Errorw("gRPC server failed", "error", err)
+		}
+	}()
+
+	// в лог попадает только копия без секретов: пароль БД не должен оседать в логах
+	logCfg := cfg.Redacted()
 	sugar.Infow("Config",
-		"BaseURL", cfg.BaseURL,
-		"EnableHTTPS", cfg.EnableHTTPS,
-		"DatabaseDSN", cfg.DatabaseDSN,
+		"BaseURL", logCfg.BaseURL,
+		"EnableHTTPS", logCfg.EnableHTTPS,
+		"GRPCAddress", logCfg.GRPCAddress,
+		"DatabaseDSN", logCfg.DatabaseDSN,
+		"BlobStorage", logCfg.BlobStorage,
 	)
 
 	// создаём http.Server, чтобы иметь возможность выполнить graceful shutdown
-	srv := &http.Server{Addr: addr, Handler: h.Router}
+	srv := &http.Server{Addr: addr, Handler: h.Router, TLSConfig: tlsConfig}
 
 	// Регистрируем сигнал о завершении приложения и останавливаем сервер аккуратно
 	idleConnsClosed := make(chan struct{})
@@ -86,13 +212,38 @@ func main() {
 		if shutdownErr := srv.Shutdown(shutdownCtx); shutdownErr != nil {
 			sugar.Errorw("Graceful shutdown error", "error", shutdownErr)
 		}
+		grpcServer.GracefulStop()
 		close(idleConnsClosed)
 	}()
 
-	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+	if tlsConfig != nil {
+		// сертификат берётся из TLSConfig.GetCertificate
+		err = srv.ListenAndServeTLS("", "")
+	} else {
+		err = srv.ListenAndServe()
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		sugar.Fatalw("Server failed", "error", err)
 	}
 
 	// ждём завершения горутины graceful shutdown (если она запускалась)
 	<-idleConnsClosed
 }
+
+// newBlobStore создаёт хранилище содержимого блобов согласно cfg.BlobStorage.
+func newBlobStore(cfg *config.Config) (repo.BlobStore, error) {
+	switch cfg.BlobStorage {
+	case "fs":
+		return repo.NewFSBlobStore(cfg.BlobDir)
+	case "s3":
+		return repo.NewS3BlobStore(repo.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Bucket:    cfg.S3Bucket,
+			Region:    cfg.S3Region,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+		}, nil)
+	default:
+		return nil, fmt.Errorf("unknown blob storage %q (expected fs|s3)", cfg.BlobStorage)
+	}
+}
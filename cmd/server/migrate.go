@@ -0,0 +1,72 @@
+package main
+
+import (
+	"GophKeeper/internal/repo"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+)
+
+// runMigrate выполняет gkserver migrate up|down [N]|status.
+func runMigrate(ctx context.Context, dsn string, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: gkserver migrate up|down [N]|status")
+	}
+	db, err := repo.OpenDB(dsn)
+	if err != nil {
+		return err
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+	m, err := repo.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		done, err := m.Up(ctx)
+		printMigrations(out, "applied", done)
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("bad step count %q", args[1])
+			}
+		}
+		done, err := m.Down(ctx, steps)
+		printMigrations(out, "reverted", done)
+		return err
+	case "status":
+		status, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED AT")
+		for _, st := range status {
+			applied := "pending"
+			if st.AppliedAt != nil {
+				applied = st.AppliedAt.UTC().Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(tw, "%04d\t%s\t%s\n", st.Version, st.Name, applied)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown migrate command %q (expected up|down|status)", args[0])
+	}
+}
+
+func printMigrations(out io.Writer, verb string, done []repo.Migration) {
+	if len(done) == 0 {
+		fmt.Fprintf(out, "nothing %s\n", verb)
+		return
+	}
+	for _, mg := range done {
+		fmt.Fprintf(out, "%s %04d_%s\n", verb, mg.Version, mg.Name)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"GophKeeper/internal/config"
+	"GophKeeper/internal/tlsutil"
+	"context"
+	"crypto/tls"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"go.uber.org/zap"
+)
+
+// newCertReloader загружает сертификат TLS_CERT_FILE/TLS_KEY_FILE, а без них —
+// самоподписанный сертификат из TLS_DEV_DIR (выпускается при первом запуске).
+func newCertReloader(cfg *config.Config, sugar *zap.SugaredLogger) (*tlsutil.CertReloader, error) {
+	certFile, keyFile := cfg.TLSCertFile, cfg.TLSKeyFile
+	switch {
+	case certFile != "" && keyFile != "":
+	case certFile != "" || keyFile != "":
+		return nil, errors.New("both TLS_CERT_FILE and TLS_KEY_FILE must be set")
+	default:
+		var err error
+		certFile, keyFile, err = tlsutil.EnsureSelfSigned(cfg.TLSDevDir, tlsutil.DevHosts(cfg.BaseURL, cfg.GRPCAddress))
+		if err != nil {
+			return nil, err
+		}
+		sugar.Warnw("TLS certificate is not configured, using self-signed development certificate",
+			"cert", certFile,
+		)
+	}
+	return tlsutil.NewCertReloader(certFile, keyFile)
+}
+
+// serverTLSConfig — настройки TLS для HTTP и gRPC; с TLS_CLIENT_CA — с проверкой клиентских сертификатов.
+func serverTLSConfig(cfg *config.Config, certs *tlsutil.CertReloader) (*tls.Config, error) {
+	conf := tlsutil.ServerConfig(certs)
+	if cfg.TLSClientCA == "" {
+		return conf, nil
+	}
+	if err := tlsutil.EnableClientAuth(conf, cfg.TLSClientCA); err != nil {
+		return nil, err
+	}
+	return conf, nil
+}
+
+// reloadOnSIGHUP перечитывает сертификат по SIGHUP до отмены ctx.
+// Установленные соединения продолжают работать со старым сертификатом.
+func reloadOnSIGHUP(ctx context.Context, r *tlsutil.CertReloader, sugar *zap.SugaredLogger) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(hup)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				if err := r.Reload(); err != nil {
+					sugar.Errorw("TLS certificate reload failed, keeping previous certificate", "error", err)
+					continue
+				}
+				sugar.Infow("TLS certificate reloaded")
+			}
+		}
+	}()
+}
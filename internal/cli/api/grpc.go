@@ -0,0 +1,40 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+
+	"GophKeeper/internal/pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// DialGRPC подключается к gRPC API сервера по адресу host:port; tlsConfig == nil — без TLS.
+// Каждый вызов помечается x-request-id, который дописывается к ошибкам сервера.
+// Соединение нужно закрыть вызовом возвращённой функции.
+func DialGRPC(addr string, tlsConfig *tls.Config) (pb.GophKeeperClient, func() error, error) {
+	creds := insecure.NewCredentials()
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	conn, err := grpc.NewClient(addr,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithChainUnaryInterceptor(unaryRequestID),
+		grpc.WithChainStreamInterceptor(streamRequestID),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pb.NewGophKeeperClient(conn), conn.Close, nil
+}
+
+// WithToken добавляет токен авторизации в metadata исходящего gRPC-вызова.
+func WithToken(ctx context.Context, token string) context.Context {
+	if token == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
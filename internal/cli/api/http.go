@@ -2,6 +2,8 @@ package api
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -13,13 +15,51 @@ import (
 	fsrepo "GophKeeper/internal/cli/repo/fs"
 )
 
+// compressMinBytes — тела запросов от этого размера отправляются сжатыми gzip.
+const compressMinBytes = 8 << 10
+
+// newPostRequest создаёт POST-запрос с новым X-Request-ID.
+func newPostRequest(url string, body []byte) (*http.Request, error) {
+	req, err := newCompressedRequest(url, body)
+	if err != nil {
+		return nil, err
+	}
+	setRequestID(req)
+	return req, nil
+}
+
+// newCompressedRequest создаёт POST-запрос; большое тело сжимается gzip (Content-Encoding: gzip),
+// если это уменьшает его размер — шифртексты блобов почти не сжимаются и уходят как есть.
+func newCompressedRequest(url string, body []byte) (*http.Request, error) {
+	if len(body) < compressMinBytes {
+		return http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	}
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err := gw.Write(body); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	if buf.Len() >= len(body) {
+		return http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	}
+	req, err := http.NewRequest(http.MethodPost, url, &buf)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Encoding", "gzip")
+	return req, nil
+}
+
 // PostJSON sends a JSON POST request. If token is non-empty, it is passed as auth cookie.
 func PostJSON(url string, payload any, token string) (*http.Response, []byte, error) {
 	b, err := json.Marshal(payload)
 	if err != nil {
 		return nil, nil, err
 	}
-	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(b))
+	req, err := newPostRequest(url, b)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -27,14 +67,80 @@ func PostJSON(url string, payload any, token string) (*http.Response, []byte, er
 	if token != "" {
 		req.Header.Set("Cookie", "auth_token="+token)
 	}
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := HTTPClient().Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	body, _ := io.ReadAll(resp.Body)
+	return resp, body, nil
+}
+
+// GetJSON sends a GET request expecting a JSON response. If token is non-empty, it is passed as auth cookie.
+func GetJSON(url string, token string) (*http.Response, []byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	setRequestID(req)
+	if token != "" {
+		req.Header.Set("Cookie", "auth_token="+token)
+	}
+	resp, err := HTTPClient().Do(req)
 	if err != nil {
 		return nil, nil, err
 	}
 	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
 	return resp, body, nil
 }
 
+// SendJSON sends a request with the given method (PUT, DELETE, ...) and an optional JSON body
+// (nil payload — no body). If token is non-empty, it is passed as auth cookie.
+func SendJSON(method, url string, payload any, token string) (*http.Response, []byte, error) {
+	var body io.Reader
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return nil, nil, err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	setRequestID(req)
+	if token != "" {
+		req.Header.Set("Cookie", "auth_token="+token)
+	}
+	resp, err := HTTPClient().Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	respBody, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	return resp, respBody, nil
+}
+
+// OpenStream opens a long-lived GET request (e.g. Server-Sent Events) bound to ctx.
+// The caller must close the response body. If token is non-empty, it is passed as auth cookie.
+func OpenStream(ctx context.Context, url, accept, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	setRequestID(req)
+	if token != "" {
+		req.Header.Set("Cookie", "auth_token="+token)
+	}
+	return HTTPClient().Do(req)
+}
+
 // PersistAuthFromResponse извлекает auth cookie из ответа и сохраняет его через файловое хранилище.
 func PersistAuthFromResponse(resp *http.Response) error {
 	store := fsrepo.AuthFSStore{}
@@ -78,7 +184,7 @@ func PostMultipartBlob(url, id string, cipher, nonce []byte, token string) (*htt
 	}
 	_ = mw.Close()
 
-	req, err := http.NewRequest(http.MethodPost, url, &buf)
+	req, err := newPostRequest(url, buf.Bytes())
 	if err != nil {
 		return nil, nil, err
 	}
@@ -87,7 +193,7 @@ func PostMultipartBlob(url, id string, cipher, nonce []byte, token string) (*htt
 		req.Header.Set("Cookie", "auth_token="+token)
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := HTTPClient().Do(req)
 	if err != nil {
 		return nil, nil, err
 	}
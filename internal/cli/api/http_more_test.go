@@ -1,6 +1,7 @@
 package api
 
 import (
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -68,3 +69,56 @@ func TestPostMultipartBlob_InvalidURL_NewRequestError(t *testing.T) {
 		t.Fatalf("expected new request error for invalid URL")
 	}
 }
+
+// GetJSON: метод GET, cookie с токеном и тело ответа
+func TestGetJSON_SendsTokenAndReadsBody(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Fatalf("method: %s", r.Method)
+		}
+		if c := r.Header.Get("Cookie"); c != "auth_token=tok" {
+			t.Fatalf("cookie: %q", c)
+		}
+		_, _ = w.Write([]byte(`{"items":1}`))
+	}))
+	defer ts.Close()
+
+	resp, body, err := GetJSON(ts.URL, "tok")
+	if err != nil {
+		t.Fatalf("GetJSON err: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || string(body) != `{"items":1}` {
+		t.Fatalf("unexpected: %d %s", resp.StatusCode, body)
+	}
+
+	if _, _, err := GetJSON("http://127.0.0.1:0", ""); err == nil {
+		t.Fatalf("expected network error")
+	}
+}
+
+func TestSendJSON_MethodsAndBody(t *testing.T) {
+	setTempCfg(t)
+	var gotMethod, gotType, gotBody string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotType = r.Method, r.Header.Get("Content-Type")
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	resp, _, err := SendJSON(http.MethodPut, ts.URL, map[string]int{"v": 1}, "tok")
+	if err != nil || resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("put: %v %v", resp, err)
+	}
+	if gotMethod != http.MethodPut || gotType != "application/json" || gotBody != `{"v":1}` {
+		t.Fatalf("put request: %s %q %q", gotMethod, gotType, gotBody)
+	}
+
+	if _, _, err := SendJSON(http.MethodDelete, ts.URL, nil, "tok"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if gotMethod != http.MethodDelete || gotType != "" || gotBody != "" {
+		t.Fatalf("delete request: %s %q %q", gotMethod, gotType, gotBody)
+	}
+}
@@ -2,7 +2,10 @@ package api
 
 import (
 	fsrepo "GophKeeper/internal/cli/repo/fs"
+	"compress/gzip"
+	"crypto/rand"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -164,3 +167,59 @@ func TestPostMultipartBlob_ValidationAndNetworkErrors(t *testing.T) {
 		t.Fatalf("expected network error")
 	}
 }
+
+// Большие тела уходят сжатыми gzip, маленькие и несжимаемые — как есть.
+func TestPost_CompressesLargeBodies(t *testing.T) {
+	type seen struct {
+		encoding string
+		body     []byte
+	}
+	var got seen
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = seen{encoding: r.Header.Get("Content-Encoding")}
+		var body io.Reader = r.Body
+		if got.encoding == "gzip" {
+			gr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Fatalf("gzip reader: %v", err)
+			}
+			body = gr
+		}
+		got.body, _ = io.ReadAll(body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	small := map[string]string{"login": "a"}
+	if _, _, err := PostJSON(ts.URL, small, ""); err != nil {
+		t.Fatal(err)
+	}
+	if got.encoding != "" {
+		t.Fatalf("small body must not be compressed, got %q", got.encoding)
+	}
+
+	large := map[string]string{"cipher": strings.Repeat("QUJD", 10<<10)}
+	if _, _, err := PostJSON(ts.URL, large, ""); err != nil {
+		t.Fatal(err)
+	}
+	want, _ := json.Marshal(large)
+	if got.encoding != "gzip" || string(got.body) != string(want) {
+		t.Fatalf("large JSON must arrive gzip-compressed and intact, encoding %q", got.encoding)
+	}
+
+	if _, _, err := PostMultipartBlob(ts.URL, "B1", make([]byte, 64<<10), []byte{1}, "tok"); err != nil {
+		t.Fatal(err)
+	}
+	if got.encoding != "gzip" || !strings.Contains(string(got.body), `name="cipher"`) {
+		t.Fatalf("compressible multipart must arrive gzip-compressed, encoding %q", got.encoding)
+	}
+
+	random := make([]byte, 64<<10)
+	_, _ = rand.Read(random)
+	if _, _, err := PostMultipartBlob(ts.URL, "B1", random, []byte{1}, "tok"); err != nil {
+		t.Fatal(err)
+	}
+	if got.encoding != "" {
+		t.Fatalf("incompressible cipher must be sent as is, got %q", got.encoding)
+	}
+}
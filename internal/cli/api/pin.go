@@ -0,0 +1,56 @@
+package api
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	fsrepo "GophKeeper/internal/cli/repo/fs"
+)
+
+// ErrServerKeyChanged — сервер предъявил ключ, отличный от закреплённого для профиля.
+var ErrServerKeyChanged = errors.New("server public key changed")
+
+// PublicKeyPin — отпечаток открытого ключа сертификата: sha256/<base64 SHA-256 от SPKI>.
+// Ключ, а не сертификат, чтобы перевыпуск сертификата с тем же ключом не ломал закрепление.
+func PublicKeyPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return "sha256/" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// keyPinner закрепляет ключ сервера при первом подключении (TOFU) и сверяет с ним последующие.
+type keyPinner struct {
+	profile string
+	mu      sync.Mutex
+}
+
+// verify вызывается из tls.Config.VerifyConnection после обычной проверки цепочки.
+func (p *keyPinner) verify(cs tls.ConnectionState) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("server presented no certificate")
+	}
+	got := PublicKeyPin(cs.PeerCertificates[0])
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pinned, err := fsrepo.LoadServerPin(p.profile)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		if err := fsrepo.SaveServerPin(p.profile, got); err != nil {
+			return fmt.Errorf("pin server key: %w", err)
+		}
+		return nil
+	case err != nil:
+		return fmt.Errorf("load pinned server key: %w", err)
+	case pinned != got:
+		return fmt.Errorf("%w for %s: pinned %s, presented %s. "+
+			"This may be a man-in-the-middle attack. If the server key was rotated on purpose, run \"gkcli trust-reset\"",
+			ErrServerKeyChanged, p.profile, pinned, got)
+	}
+	return nil
+}
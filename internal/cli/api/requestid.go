@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"GophKeeper/internal/logging"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// grpcRequestIDKey — ключ metadata gRPC с идентификатором запроса.
+var grpcRequestIDKey = strings.ToLower(logging.RequestIDHeader)
+
+// setRequestID помечает запрос новым X-Request-ID: сервер пишет его в логи и возвращает в ответе.
+func setRequestID(req *http.Request) {
+	req.Header.Set(logging.RequestIDHeader, logging.NewRequestID())
+}
+
+// RequestID возвращает идентификатор запроса из ответа сервера (или из самого запроса).
+func RequestID(resp *http.Response) string {
+	if resp == nil {
+		return ""
+	}
+	if id := resp.Header.Get(logging.RequestIDHeader); id != "" {
+		return id
+	}
+	if resp.Request != nil {
+		return resp.Request.Header.Get(logging.RequestIDHeader)
+	}
+	return ""
+}
+
+// WithRequestID дописывает к сообщению об ошибке идентификатор запроса, по которому
+// администратор найдёт строку лога сервера.
+func WithRequestID(msg string, resp *http.Response) string {
+	if id := RequestID(resp); id != "" {
+		return msg + " (request id: " + id + ")"
+	}
+	return msg
+}
+
+// StatusError — ошибка для неуспешного ответа сервера: статус, тело и идентификатор запроса.
+func StatusError(resp *http.Response, body []byte) error {
+	msg := fmt.Sprintf("server returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	return errors.New(WithRequestID(msg, resp))
+}
+
+// unaryRequestID добавляет x-request-id в исходящий unary-вызов и дописывает его к ошибке.
+func unaryRequestID(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	ctx, id := outgoingRequestID(ctx)
+	return annotateGRPCError(invoker(ctx, method, req, reply, cc, opts...), id)
+}
+
+// streamRequestID добавляет x-request-id в исходящий поток и дописывает его к ошибкам потока.
+func streamRequestID(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	ctx, id := outgoingRequestID(ctx)
+	cs, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		return nil, annotateGRPCError(err, id)
+	}
+	return &requestIDStream{ClientStream: cs, id: id}, nil
+}
+
+func outgoingRequestID(ctx context.Context) (context.Context, string) {
+	id := logging.NewRequestID()
+	return metadata.AppendToOutgoingContext(ctx, grpcRequestIDKey, id), id
+}
+
+// annotateGRPCError сохраняет код статуса gRPC и дописывает идентификатор к сообщению.
+func annotateGRPCError(err error, id string) error {
+	if err == nil || err == io.EOF {
+		return err
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	return status.Error(st.Code(), st.Message()+" (request id: "+id+")")
+}
+
+type requestIDStream struct {
+	grpc.ClientStream
+	id string
+}
+
+func (s *requestIDStream) SendMsg(m any) error {
+	return annotateGRPCError(s.ClientStream.SendMsg(m), s.id)
+}
+
+func (s *requestIDStream) RecvMsg(m any) error {
+	return annotateGRPCError(s.ClientStream.RecvMsg(m), s.id)
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"GophKeeper/internal/pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestStatusError_IncludesRequestID(t *testing.T) {
+	setTempCfg(t)
+	var sent string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = r.Header.Get("X-Request-ID")
+		w.Header().Set("X-Request-ID", sent)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	resp, body, err := PostJSON(ts.URL, map[string]string{}, "")
+	if err != nil {
+		t.Fatalf("PostJSON: %v", err)
+	}
+	if sent == "" {
+		t.Fatal("X-Request-ID is not sent")
+	}
+	want := "server returned status 500: internal error (request id: " + sent + ")"
+	if got := StatusError(resp, body).Error(); got != want {
+		t.Fatalf("StatusError = %q, want %q", got, want)
+	}
+
+	resp, _, err = GetJSON(ts.URL, "")
+	if err != nil {
+		t.Fatalf("GetJSON: %v", err)
+	}
+	if RequestID(resp) != sent {
+		t.Fatalf("GetJSON must send its own request id, got %q", RequestID(resp))
+	}
+}
+
+// failingServer отвечает на Login ошибкой Internal и запоминает x-request-id вызова.
+type failingServer struct {
+	pb.UnimplementedGophKeeperServer
+	seen string
+}
+
+func (s *failingServer) Login(ctx context.Context, _ *pb.Credentials) (*pb.AuthResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := md.Get("x-request-id"); len(v) > 0 {
+		s.seen = v[0]
+	}
+	return nil, status.Error(codes.Internal, "internal error")
+}
+
+func TestDialGRPC_AnnotatesErrorsWithRequestID(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := grpc.NewServer()
+	srv := &failingServer{}
+	pb.RegisterGophKeeperServer(gs, srv)
+	go func() { _ = gs.Serve(lis) }()
+	defer gs.Stop()
+
+	client, closeConn, err := DialGRPC(lis.Addr().String(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = closeConn() }()
+
+	_, err = client.Login(context.Background(), &pb.Credentials{Login: "a", Password: "b"})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("code must be preserved, got %v", err)
+	}
+	if srv.seen == "" || !strings.Contains(err.Error(), "(request id: "+srv.seen+")") {
+		t.Fatalf("error %q must contain request id %q", err, srv.seen)
+	}
+}
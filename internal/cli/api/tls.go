@@ -0,0 +1,86 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+)
+
+var (
+	tlsMu      sync.RWMutex
+	clientTLS  *tls.Config
+	httpClient = http.DefaultClient
+)
+
+// TLSOptions — настройки TLS клиента для HTTPS и gRPC.
+type TLSOptions struct {
+	// Profile — профиль (BASE_URL), для которого закрепляется ключ сервера; пустой — без закрепления.
+	Profile string
+	// CAFile — PEM с сертификатами частного CA; если задан, доверяем только им, а не системным.
+	CAFile string
+	// CertFile и KeyFile — сертификат устройства для mTLS.
+	CertFile string
+	KeyFile  string
+}
+
+// ConfigureTLS применяет opts ко всем запросам клиента. Пустые opts сбрасывают настройку.
+func ConfigureTLS(opts TLSOptions) error {
+	if opts == (TLSOptions{}) {
+		tlsMu.Lock()
+		clientTLS, httpClient = nil, http.DefaultClient
+		tlsMu.Unlock()
+		return nil
+	}
+	if (opts.CertFile == "") != (opts.KeyFile == "") {
+		return errors.New("both client certificate and key must be set")
+	}
+	conf := &tls.Config{MinVersion: tls.VersionTLS12}
+	if opts.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return fmt.Errorf("load client certificate: %w", err)
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+	if opts.CAFile != "" {
+		pemBytes, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return fmt.Errorf("read CA file: %w", err)
+		}
+		conf.RootCAs = x509.NewCertPool()
+		if !conf.RootCAs.AppendCertsFromPEM(pemBytes) {
+			return fmt.Errorf("CA file %s: no PEM certificates", opts.CAFile)
+		}
+	}
+	if opts.Profile != "" {
+		conf.VerifyConnection = (&keyPinner{profile: opts.Profile}).verify
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = conf
+
+	tlsMu.Lock()
+	clientTLS, httpClient = conf, &http.Client{Transport: transport}
+	tlsMu.Unlock()
+	return nil
+}
+
+// ClientTLS возвращает настройки TLS клиента (CA, сертификат устройства и закрепление ключа, если заданы).
+func ClientTLS() *tls.Config {
+	tlsMu.RLock()
+	defer tlsMu.RUnlock()
+	if clientTLS == nil {
+		return &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return clientTLS.Clone()
+}
+
+// HTTPClient возвращает HTTP-клиент для запросов к серверу.
+func HTTPClient() *http.Client {
+	tlsMu.RLock()
+	defer tlsMu.RUnlock()
+	return httpClient
+}
@@ -0,0 +1,181 @@
+package api
+
+import (
+	fsrepo "GophKeeper/internal/cli/repo/fs"
+	"GophKeeper/internal/tlsutil"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeDeviceCert сохраняет самоподписанный клиентский сертификат и ключ в dir.
+func writeDeviceCert(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "laptop"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile = filepath.Join(dir, "laptop.pem"), filepath.Join(dir, "laptop.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+// startTLSServer поднимает HTTPS-сервер с самоподписанным сертификатом из dir; caFile —
+// CA клиентских сертификатов (пустой — без mTLS). Тело ответа — имя устройства клиента.
+func startTLSServer(t *testing.T, dir, caFile string) (url, certFile string) {
+	t.Helper()
+	certFile, keyFile, err := tlsutil.EnsureSelfSigned(dir, tlsutil.DevHosts())
+	if err != nil {
+		t.Fatal(err)
+	}
+	certs, err := tlsutil.NewCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := tlsutil.ServerConfig(certs)
+	if caFile != "" {
+		if err := tlsutil.EnableClientAuth(conf, caFile); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, tlsutil.PeerName(r.TLS))
+		}),
+		TLSConfig: conf,
+		ErrorLog:  log.New(io.Discard, "", 0),
+	}
+	go func() { _ = srv.ServeTLS(ln, "", "") }()
+	t.Cleanup(func() { _ = srv.Close() })
+	return "https://" + ln.Addr().String() + "/", certFile
+}
+
+func TestConfigureTLS_PresentsClientCertificate(t *testing.T) {
+	setTempCfg(t)
+	dir := t.TempDir()
+	certFile, keyFile := writeDeviceCert(t, dir)
+	url, serverCert := startTLSServer(t, filepath.Join(dir, "server"), certFile)
+
+	if err := ConfigureTLS(TLSOptions{CertFile: certFile}); err == nil {
+		t.Fatalf("certificate without key must be rejected")
+	}
+	if err := ConfigureTLS(TLSOptions{CertFile: filepath.Join(dir, "missing.pem"), KeyFile: keyFile}); err == nil {
+		t.Fatalf("missing certificate must be rejected")
+	}
+	if err := ConfigureTLS(TLSOptions{CAFile: keyFile}); err == nil {
+		t.Fatalf("CA file without certificates must be rejected")
+	}
+	if err := ConfigureTLS(TLSOptions{CAFile: serverCert, CertFile: certFile, KeyFile: keyFile}); err != nil {
+		t.Fatalf("ConfigureTLS: %v", err)
+	}
+	t.Cleanup(func() { _ = ConfigureTLS(TLSOptions{}) })
+
+	if n := len(ClientTLS().Certificates); n != 1 {
+		t.Fatalf("gRPC TLS config must carry the client certificate, got %d", n)
+	}
+	resp, body, err := GetJSON(url, "")
+	if err != nil {
+		t.Fatalf("GetJSON: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || string(body) != "laptop" {
+		t.Fatalf("server must see the device certificate, got %d %q", resp.StatusCode, body)
+	}
+
+	if err := ConfigureTLS(TLSOptions{}); err != nil || HTTPClient() != http.DefaultClient || ClientTLS().Certificates != nil {
+		t.Fatalf("reset must restore defaults")
+	}
+}
+
+func TestConfigureTLS_CAFileAndPinning(t *testing.T) {
+	setTempCfg(t)
+	dir := t.TempDir()
+	first, firstCert := startTLSServer(t, filepath.Join(dir, "first"), "")
+	second, secondCert := startTLSServer(t, filepath.Join(dir, "second"), "")
+	t.Cleanup(func() { _ = ConfigureTLS(TLSOptions{}) })
+
+	// без --ca-file самоподписанному сертификату не доверяем
+	if err := ConfigureTLS(TLSOptions{Profile: "keeper:8081"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := GetJSON(first, ""); err == nil {
+		t.Fatalf("untrusted certificate must be rejected")
+	}
+	if _, err := fsrepo.LoadServerPin("keeper:8081"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("rejected server must not be pinned, got %v", err)
+	}
+
+	// первое подключение закрепляет ключ, повторные с ним проходят
+	if err := ConfigureTLS(TLSOptions{Profile: "keeper:8081", CAFile: firstCert}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, _, err := GetJSON(first, ""); err != nil {
+			t.Fatalf("GetJSON #%d: %v", i, err)
+		}
+	}
+	pin, err := fsrepo.LoadServerPin("keeper:8081")
+	if err != nil || !strings.HasPrefix(pin, "sha256/") {
+		t.Fatalf("key must be pinned, got %q, %v", pin, err)
+	}
+
+	// другой ключ под тем же профилем — громкая ошибка, даже если CA ему доверяет
+	if err := ConfigureTLS(TLSOptions{Profile: "keeper:8081", CAFile: secondCert}); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = GetJSON(second, "")
+	if !errors.Is(err, ErrServerKeyChanged) || !strings.Contains(err.Error(), "gkcli trust-reset") {
+		t.Fatalf("key change must fail with ErrServerKeyChanged and hint, got %v", err)
+	}
+
+	// после trust-reset закрепляется новый ключ
+	if _, err := fsrepo.ResetServerPin("keeper:8081"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := GetJSON(second, ""); err != nil {
+		t.Fatalf("after reset: %v", err)
+	}
+	if pin2, _ := fsrepo.LoadServerPin("keeper:8081"); pin2 == pin {
+		t.Fatalf("new key must be pinned")
+	}
+}
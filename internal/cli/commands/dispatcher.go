@@ -1,8 +1,10 @@
 package commands
 
 import (
+	"GophKeeper/internal/cli/api"
 	"GophKeeper/internal/config"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
@@ -51,6 +53,11 @@ func Dispatch(ctx context.Context, cfg *config.Config, args []string) int {
 		return 2
 	}
 
+	if err := configureTLS(cfg); err != nil {
+		fmt.Fprintf(Out, "%s error: %v\n", name, err)
+		return 1
+	}
+
 	err := c.Run(ctx, cfg, args[1:])
 	switch err {
 	case nil:
@@ -63,3 +70,20 @@ func Dispatch(ctx context.Context, cfg *config.Config, args []string) int {
 		return 1
 	}
 }
+
+// configureTLS настраивает HTTPS и gRPC клиента: закрепление ключа сервера для профиля
+// (BASE_URL), частный CA (--ca-file) и сертификат устройства (--client-cert/--client-key).
+func configureTLS(cfg *config.Config) error {
+	if !cfg.EnableHTTPS {
+		if cfg.ClientCert != "" || cfg.ClientKey != "" || cfg.CAFile != "" {
+			return errors.New("--client-cert, --client-key and --ca-file require --https")
+		}
+		return nil
+	}
+	return api.ConfigureTLS(api.TLSOptions{
+		Profile:  cfg.BaseURL,
+		CAFile:   cfg.CAFile,
+		CertFile: cfg.ClientCert,
+		KeyFile:  cfg.ClientKey,
+	})
+}
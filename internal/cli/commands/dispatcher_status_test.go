@@ -9,6 +9,7 @@ import (
 	"strings"
 	"testing"
 
+	fsrepo "GophKeeper/internal/cli/repo/fs"
 	"GophKeeper/internal/config"
 )
 
@@ -127,3 +128,50 @@ func TestStatus_Run_Success_Errors_and_Usage(t *testing.T) {
 		t.Fatalf("expected ErrUsage, got %v", err)
 	}
 }
+
+func TestStatus_Run_PrintsUsage(t *testing.T) {
+	withTempConfig(t)
+	if err := (fsrepo.AuthFSStore{}).Save("tok"); err != nil {
+		t.Fatalf("save token: %v", err)
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/api/user/test"):
+			_, _ = w.Write([]byte(`{"result":"User ID = 1"}`))
+		case strings.HasSuffix(r.URL.Path, "/api/user/usage"):
+			_, _ = w.Write([]byte(`{"items":3,"items_limit":100,"blob_bytes":1572864,"blob_bytes_limit":0,"item_bytes_limit":65536}`))
+		default:
+			t.Fatalf("path: %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	out := withStdoutCapture(t, func() {
+		if err := (statusCmd{}).Run(context.Background(), &config.Config{ServerURL: ts.URL}, nil); err != nil {
+			t.Fatalf("status: %v", err)
+		}
+	})
+	for _, want := range []string{"Items: 3 / 100", "Blobs: 1.5 MiB / unlimited", "Max item size: 64.0 KiB"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+
+	// сервер без /api/user/usage — статус всё равно успешен
+	tsOld := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/api/user/usage") {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":"User ID = 1"}`))
+	}))
+	defer tsOld.Close()
+	out = withStdoutCapture(t, func() {
+		if err := (statusCmd{}).Run(context.Background(), &config.Config{ServerURL: tsOld.URL}, nil); err != nil {
+			t.Fatalf("status: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Usage: unavailable") {
+		t.Fatalf("expected unavailable usage, got:\n%s", out)
+	}
+}
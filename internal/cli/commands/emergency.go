@@ -0,0 +1,172 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"GophKeeper/internal/cli/service"
+	"GophKeeper/internal/config"
+)
+
+type emergencyCmd struct{}
+
+func (emergencyCmd) Name() string { return "emergency" }
+func (emergencyCmd) Description() string {
+	return "Экстренный доступ: доверенные контакты, запросы доступа и просмотр чужого хранилища"
+}
+func (emergencyCmd) Usage() string {
+	return "emergency grant <login> [--wait=7] | emergency revoke|approve|reject <login> | " +
+		"emergency request <owner> | emergency view <owner> [<name>] | emergency list"
+}
+
+func (emergencyCmd) Run(ctx context.Context, cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return ErrUsage
+	}
+	if args[0] == "list" {
+		if len(args) != 1 {
+			return ErrUsage
+		}
+		return emergencyList(cfg)
+	}
+	if len(args) < 2 {
+		return ErrUsage
+	}
+	switch args[0] {
+	case "grant":
+		return emergencyGrant(cfg, args[1:])
+	case "revoke", "approve", "reject":
+		if len(args) != 2 {
+			return ErrUsage
+		}
+		return emergencyOwnerAction(cfg, args[0], args[1])
+	case "request":
+		if len(args) != 2 {
+			return ErrUsage
+		}
+		a, err := service.RequestEmergency(cfg, args[1])
+		if err != nil {
+			return err
+		}
+		if a.Status == "approved" {
+			fmt.Fprintf(Out, "✓ Доступ к хранилищу %s открыт: emergency view %s\n", args[1], args[1])
+			return nil
+		}
+		fmt.Fprintf(Out, "✓ Доступ запрошен; если %s не отклонит запрос, он откроется %s\n", args[1], a.AvailableAt)
+		return nil
+	case "view":
+		if len(args) > 3 {
+			return ErrUsage
+		}
+		return emergencyView(cfg, args[1], args[2:])
+	}
+	return ErrUsage
+}
+
+func emergencyGrant(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("emergency grant", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	wait := fs.Int("wait", service.DefaultEmergencyWaitDays, "период ожидания, дней")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+	// логин может стоять и до флага
+	if fs.NArg() < 1 {
+		return ErrUsage
+	}
+	login := fs.Arg(0)
+	if err := fs.Parse(fs.Args()[1:]); err != nil || fs.NArg() != 0 {
+		return ErrUsage
+	}
+	if *wait < 1 {
+		return ErrUsage
+	}
+	if err := service.GrantEmergency(cfg, login, *wait); err != nil {
+		return err
+	}
+	fmt.Fprintf(Out, "✓ %s — экстренный контакт, период ожидания %d дн.\n", login, *wait)
+	return nil
+}
+
+func emergencyOwnerAction(cfg *config.Config, action, login string) error {
+	var err error
+	var done string
+	switch action {
+	case "revoke":
+		err, done = service.RevokeEmergency(cfg, login), "экстренный доступ отозван"
+	case "approve":
+		err, done = service.ApproveEmergency(cfg, login), "доступ открыт"
+	case "reject":
+		err, done = service.RejectEmergency(cfg, login), "запрос отклонён"
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(Out, "✓ %s: %s\n", login, done)
+	return nil
+}
+
+func emergencyList(cfg *config.Config) error {
+	contacts, grants, err := service.EmergencyList(cfg)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(Out, "Ваши экстренные контакты:")
+	for _, c := range contacts {
+		fmt.Fprintf(Out, "- %s  %s  wait=%dd%s\n", c.Login, c.Status, c.WaitDays, emergencyAvailable(c))
+	}
+	if len(contacts) == 0 {
+		fmt.Fprintln(Out, "  нет")
+	}
+	fmt.Fprintln(Out, "Вы — экстренный контакт:")
+	for _, g := range grants {
+		fmt.Fprintf(Out, "- %s  %s  wait=%dd%s\n", g.Login, g.Status, g.WaitDays, emergencyAvailable(g))
+	}
+	if len(grants) == 0 {
+		fmt.Fprintln(Out, "  нет")
+	}
+	return nil
+}
+
+// emergencyAvailable — когда откроется запрошенный доступ.
+func emergencyAvailable(a service.EmergencyAccess) string {
+	if a.Status != "requested" || a.AvailableAt == "" {
+		return ""
+	}
+	return "  откроется " + a.AvailableAt
+}
+
+func emergencyView(cfg *config.Config, owner string, name []string) error {
+	items, err := service.EmergencyVault(cfg, owner)
+	if err != nil {
+		return err
+	}
+	if len(name) == 0 {
+		for _, it := range items {
+			fmt.Fprintf(Out, "- %s  name=%s  ver=%d\n", it.ID, it.Name, it.Version)
+		}
+		fmt.Fprintf(Out, "Записей %s: %d (только чтение)\n", owner, len(items))
+		return nil
+	}
+	for _, it := range items {
+		if it.Name != name[0] {
+			continue
+		}
+		fmt.Fprintf(Out, "owner:     %s (emergency, read-only)\n", owner)
+		fmt.Fprintf(Out, "id:        %s\n", it.ID)
+		fmt.Fprintf(Out, "name:      %s\n", it.Name)
+		fmt.Fprintf(Out, "updated:   %d\n", it.UpdatedAt)
+		fmt.Fprintf(Out, "version:   %d\n", it.Version)
+		fmt.Fprintf(Out, "login:     %s\n", it.Login)
+		fmt.Fprintf(Out, "password:  %s\n", it.Password)
+		fmt.Fprintf(Out, "text:      %s\n", it.Text)
+		fmt.Fprintf(Out, "card:      %s\n", it.Card)
+		fmt.Fprintf(Out, "file:      %s\n", it.FileName)
+		return nil
+	}
+	return fmt.Errorf("запись %q в хранилище %s не найдена", name[0], owner)
+}
+
+func init() { RegisterCmd(emergencyCmd{}) }
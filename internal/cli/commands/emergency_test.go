@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"GophKeeper/internal/cli/service"
+	"GophKeeper/internal/config"
+)
+
+func TestEmergency_Run_Usage(t *testing.T) {
+	for _, args := range [][]string{
+		{}, {"grant"}, {"grant", "--wait=3"}, {"grant", "bob", "extra"}, {"grant", "bob", "--wait=0"},
+		{"grant", "bob", "--wait=x"}, {"revoke"}, {"approve", "bob", "x"}, {"reject"}, {"request"},
+		{"request", "alice", "x"}, {"view"}, {"view", "alice", "wifi", "x"}, {"list", "x"}, {"open", "alice"},
+	} {
+		if err := (emergencyCmd{}).Run(context.Background(), &config.Config{}, args); err != ErrUsage {
+			t.Fatalf("args %v: expected ErrUsage, got %v", args, err)
+		}
+	}
+}
+
+func TestEmergencyAvailable(t *testing.T) {
+	tests := []struct {
+		access service.EmergencyAccess
+		want   string
+	}{
+		{service.EmergencyAccess{Status: "granted"}, ""},
+		{service.EmergencyAccess{Status: "requested", AvailableAt: "2026-01-08T00:00:00Z"}, "  откроется 2026-01-08T00:00:00Z"},
+		{service.EmergencyAccess{Status: "approved", AvailableAt: "2026-01-08T00:00:00Z"}, ""},
+	}
+	for _, tt := range tests {
+		if got := emergencyAvailable(tt.access); got != tt.want {
+			t.Fatalf("%+v: got %q, want %q", tt.access, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"GophKeeper/internal/cli/bootstrap"
+	"GophKeeper/internal/cli/service"
+	"GophKeeper/internal/config"
+)
+
+type historyCmd struct{}
+
+func (historyCmd) Name() string { return "history" }
+func (historyCmd) Description() string {
+	return "Показать историю версий записи на сервере"
+}
+func (historyCmd) Usage() string { return "history <name>" }
+
+func (historyCmd) Run(ctx context.Context, cfg *config.Config, args []string) error {
+	if len(args) != 1 {
+		return ErrUsage
+	}
+	repo, done, err := bootstrap.OpenItemRepo()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	h, err := service.FetchItemHistory(cfg, repo, args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(Out, "id: %s\n", h.ID)
+	printHistoryVersion("*", h.Current, h.Current.UpdatedAt)
+	for _, v := range h.Versions {
+		printHistoryVersion(" ", v, v.UpdatedAt)
+	}
+	if len(h.Versions) == 0 {
+		fmt.Fprintln(Out, "Предыдущих версий нет")
+		return nil
+	}
+	fmt.Fprintf(Out, "Восстановить: restore %s --version N\n", args[0])
+	return nil
+}
+
+func printHistoryVersion(mark string, v service.HistoryVersion, at string) {
+	del := ""
+	if v.Deleted {
+		del = " (deleted)"
+	}
+	fields := strings.Join(v.Fields(), ",")
+	if fields == "" {
+		fields = "-"
+	}
+	fmt.Fprintf(Out, "%s ver=%d  %s  name=%s  fields=%s%s\n", mark, v.Version, at, v.Name, fields, del)
+}
+
+func init() { RegisterCmd(historyCmd{}) }
@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	reposqlite "GophKeeper/internal/cli/repo/sqlite"
+	"GophKeeper/internal/config"
+)
+
+// addLocalItem создаёт локальную запись в БД пользователя login и возвращает её id.
+func addLocalItem(t *testing.T, login, name string) string {
+	t.Helper()
+	st, _, err := reposqlite.OpenForUser(login)
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer st.Close()
+	id, err := st.AddEncrypted(name, []byte("lc"), []byte("ln"), nil, nil)
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	return id
+}
+
+func TestHistory_Run_PrintsVersions(t *testing.T) {
+	setupSyncUserEnv(t, "hist")
+	id := addLocalItem(t, "hist", "mail")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/items/"+id+"/history" || r.Method != http.MethodGet {
+			t.Errorf("bad request: %s %s", r.Method, r.URL.Path)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id":      id,
+			"current": map[string]any{"id": id, "name": "mail", "version": 3, "updated_at": "2025-03-02T00:00:00Z"},
+			"versions": []map[string]any{
+				{"id": id, "name": "mail", "version": 2, "password_cipher": "AQ==", "updated_at": "2025-03-01T00:00:00Z"},
+			},
+		})
+	}))
+	defer ts.Close()
+
+	old := Out
+	var buf bytes.Buffer
+	Out = &buf
+	defer func() { Out = old }()
+
+	if err := (historyCmd{}).Run(context.Background(), &config.Config{ServerURL: ts.URL}, []string{"mail"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "* ver=3") || !strings.Contains(out, "  ver=2  2025-03-01T00:00:00Z  name=mail  fields=password") {
+		t.Fatalf("unexpected out: %s", out)
+	}
+}
+
+func TestRestore_Run_AppliesLocally(t *testing.T) {
+	setupSyncUserEnv(t, "rest")
+	id := addLocalItem(t, "rest", "mail")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/items/"+id+"/restore" || r.Method != http.MethodPost {
+			t.Errorf("bad request: %s %s", r.Method, r.URL.Path)
+		}
+		var req map[string]int64
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req["version"] != 2 {
+			t.Errorf("version = %d", req["version"])
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id": id, "name": "mail", "version": 5, "text_cipher": "AQI=", "text_nonce": "AwQ=",
+			"updated_at": "2025-03-02T00:00:00Z",
+		})
+	}))
+	defer ts.Close()
+
+	old := Out
+	var buf bytes.Buffer
+	Out = &buf
+	defer func() { Out = old }()
+
+	cfg := &config.Config{ServerURL: ts.URL}
+	if err := (restoreCmd{}).Run(context.Background(), cfg, []string{"mail", "--version", "2"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if !strings.Contains(buf.String(), "восстановлена версия 2, новая версия 5") {
+		t.Fatalf("unexpected out: %s", buf.String())
+	}
+
+	st, _, err := reposqlite.OpenForUser("rest")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer st.Close()
+	it, err := st.GetItemByName("mail")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if it.Version != 5 || !bytes.Equal(it.TextCipher, []byte{1, 2}) || len(it.LoginCipher) != 0 {
+		t.Fatalf("local item not replaced: %+v", it)
+	}
+}
+
+func TestRestore_Run_Usage(t *testing.T) {
+	for _, args := range [][]string{{}, {"mail"}, {"--version", "0", "mail"}, {"mail", "extra", "--version", "1"}} {
+		if err := (restoreCmd{}).Run(context.Background(), &config.Config{}, args); !errors.Is(err, ErrUsage) {
+			t.Fatalf("args %v: expected ErrUsage, got %v", args, err)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package commands
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
@@ -21,14 +22,14 @@ func (itemEditCmd) Description() string {
 	return "Отредактировать/добавить поле записи: login|password|text|card|file"
 }
 func (itemEditCmd) Usage() string {
-	return "item-edit [--resolve=client|server] <name> <type> <value> [<value2> <value3> <value4>]"
+	return "item-edit [--resolve=client|server|both] <name> <type> <value> [<value2> <value3> <value4>]"
 }
 
 func (itemEditCmd) Run(ctx context.Context, cfg *config.Config, args []string) error { // cfg зарезервирован на будущее
 	// Парсим флагами: разрешаем только префиксные флаги перед позиционными аргументами
 	fs := flag.NewFlagSet("item-edit", flag.ContinueOnError)
 	fs.SetOutput(io.Discard)
-	resolve := fs.String("resolve", "", "стратегия разрешения конфликта: client|server")
+	resolve := fs.String("resolve", "", "стратегия разрешения конфликта: client|server|both")
 	if err := fs.Parse(args); err != nil {
 		return ErrUsage
 	}
@@ -41,7 +42,7 @@ func (itemEditCmd) Run(ctx context.Context, cfg *config.Config, args []string) e
 	values := rest[2:]
 	var resolvePtr *string
 	if *resolve != "" {
-		if *resolve != "client" && *resolve != "server" {
+		if !validResolve(*resolve) {
 			return ErrUsage
 		}
 		resolvePtr = resolve
@@ -66,6 +67,11 @@ func (itemEditCmd) Run(ctx context.Context, cfg *config.Config, args []string) e
 		return err
 	}
 	defer done()
+	if _, gerr := repo.GetItemByName(name); gerr != nil {
+		if shared, ok := findSharedItem(repo, name); ok {
+			return fmt.Errorf("запись %q открыта вам пользователем %s только для чтения", name, shared.Owner)
+		}
+	}
 	svc := service.NewItemServiceLocal(repo)
 	id, created, err := svc.Edit(name, fieldType, values)
 	if err != nil {
@@ -106,10 +112,10 @@ func (itemEditCmd) Run(ctx context.Context, cfg *config.Config, args []string) e
 			fmt.Fprintf(Out, "! Конфликт на сервере: %s\n", conflicts)
 			reader := bufio.NewReader(os.Stdin)
 			for {
-				fmt.Fprint(Out, "Выберите действие [client|server|cancel]: ")
+				fmt.Fprint(Out, "Выберите действие [client|server|both|cancel]: ")
 				line, _ := reader.ReadString('\n')
 				choice := strings.TrimSpace(strings.ToLower(line))
-				if choice == "client" || choice == "server" {
+				if choice == "client" || choice == "server" || choice == "both" {
 					ch := choice
 					fmt.Fprintf(Out, "→ Повторная синхронизация (resolve=%s)...\n", ch)
 					applied2, newVer2, conflicts2, syncErr2 := service.SyncItemByName(cfg, repo, name, created, &ch)
@@ -119,9 +125,7 @@ func (itemEditCmd) Run(ctx context.Context, cfg *config.Config, args []string) e
 						fmt.Fprintf(Out, "✓ Синхронизировано. Новая версия: %d\n", newVer2)
 					} else if conflicts2 != "" {
 						fmt.Fprintf(Out, "! Конфликт на сервере: %s\n", conflicts2)
-						if ch == "server" {
-							fmt.Fprintln(Out, "• Локальная версия выровнена с серверной (resolve=server)")
-						}
+						printResolvedLocally(conflicts2, ch)
 					} else {
 						fmt.Fprintln(Out, "• Синхронизация завершена: изменений не применено")
 					}
@@ -131,14 +135,12 @@ func (itemEditCmd) Run(ctx context.Context, cfg *config.Config, args []string) e
 					fmt.Fprintln(Out, "• Отменено пользователем")
 					break
 				}
-				fmt.Fprintln(Out, "Некорректный выбор. Введите client, server или cancel.")
+				fmt.Fprintln(Out, "Некорректный выбор. Введите client, server, both или cancel.")
 			}
 		} else {
 			// --resolve уже задан
 			fmt.Fprintf(Out, "! Конфликт на сервере: %s\n", conflicts)
-			if *resolvePtr == "server" {
-				fmt.Fprintln(Out, "• Локальная версия выровнена с серверной (resolve=server)")
-			}
+			printResolvedLocally(conflicts, *resolvePtr)
 		}
 	} else {
 		fmt.Fprintln(Out, "• Синхронизация завершена: изменений не применено")
@@ -161,3 +163,20 @@ func (itemEditCmd) Run(ctx context.Context, cfg *config.Config, args []string) e
 }
 
 func init() { RegisterCmd(itemEditCmd{}) }
+
+// printResolvedLocally сообщает, как конфликт разрешён локально при стратегиях server|both.
+func printResolvedLocally(conflictsJSON, strategy string) {
+	if strategy != "server" && strategy != "both" {
+		return
+	}
+	var conflicts []struct {
+		LocalCopy string `json:"local_copy"`
+	}
+	_ = json.Unmarshal([]byte(conflictsJSON), &conflicts)
+	for _, c := range conflicts {
+		if c.LocalCopy != "" {
+			fmt.Fprintf(Out, "• Локальная версия сохранена как %s\n", c.LocalCopy)
+		}
+	}
+	fmt.Fprintf(Out, "• Локальная версия выровнена с серверной (resolve=%s)\n", strategy)
+}
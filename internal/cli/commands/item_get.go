@@ -15,7 +15,7 @@ func (itemGetCmd) Name() string { return "item-get" }
 func (itemGetCmd) Description() string {
 	return "Показать запись по имени (точное совпадение)"
 }
-func (itemGetCmd) Usage() string { return "item-get <name>" }
+func (itemGetCmd) Usage() string { return "item-get <name>|<owner>/<name>|<org>/<collection>/<name>" }
 
 func (itemGetCmd) Run(ctx context.Context, cfg *config.Config, args []string) error {
 	if len(args) != 1 {
@@ -30,7 +30,20 @@ func (itemGetCmd) Run(ctx context.Context, cfg *config.Config, args []string) er
 	svc := service.NewItemServiceLocal(repo)
 	it, err := svc.GetByName(name)
 	if err != nil {
-		return err
+		// нет своей записи — ищем среди открытых пользователю другими и в коллекциях организаций
+		if shared, ok := findSharedItem(repo, name); ok {
+			if it, err = service.DecryptSharedItem(shared); err != nil {
+				return err
+			}
+			fmt.Fprintf(Out, "owner:     %s (read-only)\n", shared.Owner)
+		} else if col, cit, ok := findCollectionItem(repo, name); ok {
+			if it, err = service.DecryptCollectionItem(col, cit); err != nil {
+				return err
+			}
+			fmt.Fprintf(Out, "org:       %s/%s (%s)\n", col.Org, col.Name, col.Role)
+		} else {
+			return err
+		}
 	}
 	fmt.Fprintf(Out, "id:        %s\n", it.ID)
 	fmt.Fprintf(Out, "name:      %s\n", it.Name)
@@ -5,6 +5,8 @@ import (
 	"fmt"
 
 	"GophKeeper/internal/cli/bootstrap"
+	"GophKeeper/internal/cli/model"
+	crepo "GophKeeper/internal/cli/repo"
 	"GophKeeper/internal/cli/service"
 	"GophKeeper/internal/config"
 )
@@ -31,7 +33,19 @@ func (itemsCmd) Run(ctx context.Context, cfg *config.Config, args []string) erro
 	if err != nil {
 		return err
 	}
-	if len(list) == 0 {
+	var shared []model.SharedItem
+	if sr, ok := service.SharedItems(repo); ok {
+		if shared, err = sr.ListSharedItems(); err != nil {
+			return err
+		}
+	}
+	var collections []collectionItems
+	if cols, ok := service.Collections(repo); ok {
+		if collections, err = listCollectionItems(cols); err != nil {
+			return err
+		}
+	}
+	if len(list) == 0 && len(shared) == 0 && len(collections) == 0 {
 		fmt.Fprintln(Out, "Нет записей")
 		return nil
 	}
@@ -43,7 +57,40 @@ func (itemsCmd) Run(ctx context.Context, cfg *config.Config, args []string) erro
 		fmt.Fprintf(Out, "- %s  name=%s  ver=%d%s\n", it.ID, it.Name, it.Version, del)
 	}
 	fmt.Fprintf(Out, "Всего: %d\n", len(list))
+	for _, s := range shared {
+		fmt.Fprintf(Out, "- %s  name=%s/%s  ver=%d  (shared by %s, read-only)\n", s.ItemID, s.Owner, s.Name, s.Version, s.Owner)
+	}
+	if len(shared) > 0 {
+		fmt.Fprintf(Out, "Открыто вам: %d\n", len(shared))
+	}
+	for _, c := range collections {
+		for _, it := range c.items {
+			fmt.Fprintf(Out, "- %s  name=%s/%s/%s  ver=%d  (%s)\n", it.ID, c.Org, c.Name, it.Name, it.Version, c.Role)
+		}
+	}
 	return nil
 }
 
+// collectionItems — коллекция организации с её записями.
+type collectionItems struct {
+	model.Collection
+	items []model.CollectionItem
+}
+
+func listCollectionItems(cols crepo.CollectionRepository) ([]collectionItems, error) {
+	list, err := cols.ListCollections()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]collectionItems, 0, len(list))
+	for _, c := range list {
+		items, err := cols.ListCollectionItems(c.ID)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, collectionItems{Collection: c, items: items})
+	}
+	return res, nil
+}
+
 func init() { RegisterCmd(itemsCmd{}) }
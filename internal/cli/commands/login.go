@@ -11,6 +11,7 @@ import (
 	"GophKeeper/internal/cli/api"
 	fsrepo "GophKeeper/internal/cli/repo/fs"
 	reposqlite "GophKeeper/internal/cli/repo/sqlite"
+	"GophKeeper/internal/cli/service"
 )
 
 type LoginRequest struct {
@@ -30,6 +31,16 @@ func (loginCmd) Run(ctx context.Context, cfg *config.Config, args []string) erro
 	}
 	login := args[0]
 	password := args[1]
+	if cfg.Transport == config.TransportGRPC {
+		token, err := service.AuthGRPC(ctx, cfg, login, password, false)
+		if err != nil {
+			return err
+		}
+		if err := (fsrepo.AuthFSStore{}).Save(token); err != nil {
+			return fmt.Errorf("saving auth: %w", err)
+		}
+		return finishAuth(login, "Logged in successfully")
+	}
 	baseURL := cfg.ServerURL
 	endpoint := strings.TrimRight(baseURL, "/") + "/api/user/login"
 	req := LoginRequest{Login: login, Password: password}
@@ -42,26 +53,31 @@ func (loginCmd) Run(ctx context.Context, cfg *config.Config, args []string) erro
 		if err := api.PersistAuthFromResponse(resp); err != nil {
 			return fmt.Errorf("saving auth: %w", err)
 		}
-		// remember last successful login
-		if err := (fsrepo.AuthFSStore{}).SaveLogin(login); err != nil {
-			return fmt.Errorf("save last login: %w", err)
-		}
-		// prepare per-user DB and run migrations
-		st, _, err := reposqlite.OpenForUser(login)
-		if err != nil {
-			return fmt.Errorf("open user db: %w", err)
-		}
-		defer st.Close()
-		if err := st.Migrate(); err != nil {
-			return fmt.Errorf("migrate user db: %w", err)
-		}
-		fmt.Fprintln(Out, "Logged in successfully")
-		return nil
+		return finishAuth(login, "Logged in successfully")
 	}
 	if resp.StatusCode == http.StatusUnauthorized {
 		return errors.New("invalid login or password")
 	}
-	return fmt.Errorf("server error: %s", strings.TrimSpace(string(body)))
+	return fmt.Errorf("server error: %s", api.WithRequestID(strings.TrimSpace(string(body)), resp))
+}
+
+// finishAuth запоминает логин после успешного входа/регистрации и готовит БД пользователя.
+func finishAuth(login, msg string) error {
+	// remember last successful login
+	if err := (fsrepo.AuthFSStore{}).SaveLogin(login); err != nil {
+		return fmt.Errorf("save last login: %w", err)
+	}
+	// prepare per-user DB and run migrations
+	st, _, err := reposqlite.OpenForUser(login)
+	if err != nil {
+		return fmt.Errorf("open user db: %w", err)
+	}
+	defer st.Close()
+	if err := st.Migrate(); err != nil {
+		return fmt.Errorf("migrate user db: %w", err)
+	}
+	fmt.Fprintln(Out, msg)
+	return nil
 }
 
 func init() { RegisterCmd(loginCmd{}) }
@@ -2,6 +2,8 @@ package commands
 
 import (
 	"context"
+	"errors"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -9,7 +11,15 @@ import (
 	"strings"
 	"testing"
 
+	fsrepo "GophKeeper/internal/cli/repo/fs"
+	"GophKeeper/internal/cli/service"
 	"GophKeeper/internal/config"
+	"GophKeeper/internal/openapi"
+	"GophKeeper/internal/pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // --- login tests ---
@@ -148,3 +158,61 @@ func TestRegister_Run_SuccessAndErrors(t *testing.T) {
 	}
 	t.Fatalf("client.sqlite not found in any user directory")
 }
+
+// authGRPCServer — gRPC-сервер, принимающий только пароль "pw".
+type authGRPCServer struct {
+	pb.UnimplementedGophKeeperServer
+}
+
+func (authGRPCServer) Login(_ context.Context, req *pb.Credentials) (*pb.AuthResponse, error) {
+	if req.GetPassword() != "pw" {
+		return nil, status.Error(codes.Unauthenticated, "invalid login or password")
+	}
+	return &pb.AuthResponse{Token: "grpc-tok"}, nil
+}
+
+func TestLogin_Run_GRPCTransport(t *testing.T) {
+	withTempConfig(t)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := grpc.NewServer()
+	pb.RegisterGophKeeperServer(gs, authGRPCServer{})
+	go func() { _ = gs.Serve(lis) }()
+	defer gs.Stop()
+
+	cfg := &config.Config{Transport: config.TransportGRPC, GRPCAddress: lis.Addr().String()}
+	if err := (loginCmd{}).Run(context.Background(), cfg, []string{"carol", "pw"}); err != nil {
+		t.Fatalf("login over grpc should succeed: %v", err)
+	}
+	if tok, err := (fsrepo.AuthFSStore{}).Load(); err != nil || tok != "grpc-tok" {
+		t.Fatalf("token not saved: %q %v", tok, err)
+	}
+	if login, _ := (fsrepo.AuthFSStore{}).LoadLogin(); login != "carol" {
+		t.Fatalf("last login not saved: %q", login)
+	}
+	if err := (loginCmd{}).Run(context.Background(), cfg, []string{"carol", "bad"}); !errors.Is(err, service.ErrInvalidCredentials) {
+		t.Fatalf("expected ErrInvalidCredentials, got %v", err)
+	}
+}
+
+// Тела login/register и ответы status/usage соответствуют контракту OpenAPI.
+func TestCommandDTOsConformToOpenAPI(t *testing.T) {
+	for name, dto := range map[string]any{
+		"Credentials":    LoginRequest{},
+		"StatusResponse": dataResponse{},
+		"UsageResponse":  usageResponse{},
+	} {
+		d, err := openapi.Diff(name, dto)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.Unknown != nil || d.Missing != nil || d.Mismatched != nil {
+			t.Fatalf("%s differs from contract: %+v", name, d)
+		}
+	}
+	if d, _ := openapi.Diff("Credentials", RegisterRequest{}); d.Unknown != nil || d.Missing != nil || d.Mismatched != nil {
+		t.Fatalf("RegisterRequest differs from contract: %+v", d)
+	}
+}
@@ -0,0 +1,172 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"GophKeeper/internal/cli/bootstrap"
+	"GophKeeper/internal/cli/model"
+	crepo "GophKeeper/internal/cli/repo"
+	"GophKeeper/internal/cli/service"
+	"GophKeeper/internal/config"
+)
+
+type orgCmd struct{}
+
+func (orgCmd) Name() string { return "org" }
+func (orgCmd) Description() string {
+	return "Организации: создание, участники и роли, записи общих коллекций"
+}
+func (orgCmd) Usage() string {
+	return "org create <org> | org invite <org> <login> [--role=owner|admin|member|read-only] | " +
+		"org members <org> | org remove <org> <login> | org put <org>/<collection>/<name> <type> <value...>"
+}
+
+func (orgCmd) Run(ctx context.Context, cfg *config.Config, args []string) error {
+	if len(args) < 2 {
+		return ErrUsage
+	}
+	switch args[0] {
+	case "create":
+		return orgCreate(cfg, args[1:])
+	case "invite":
+		return orgInvite(cfg, args[1:])
+	case "members":
+		return orgMembers(cfg, args[1:])
+	case "remove":
+		return orgRemove(cfg, args[1:])
+	case "put":
+		return orgPut(cfg, args[1:])
+	}
+	return ErrUsage
+}
+
+func orgCreate(cfg *config.Config, args []string) error {
+	if len(args) != 1 {
+		return ErrUsage
+	}
+	repo, done, err := bootstrap.OpenItemRepo()
+	if err != nil {
+		return err
+	}
+	defer done()
+	if err := service.CreateOrg(cfg, repo, args[0]); err != nil {
+		return err
+	}
+	fmt.Fprintf(Out, "✓ Организация %s создана, коллекция %s/%s\n", args[0], args[0], service.DefaultCollection)
+	return nil
+}
+
+func orgInvite(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("org invite", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	role := fs.String("role", "member", "роль участника")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+	// позиционные аргументы могут стоять и до флага
+	if fs.NArg() < 2 {
+		return ErrUsage
+	}
+	org, login := fs.Arg(0), fs.Arg(1)
+	if err := fs.Parse(fs.Args()[2:]); err != nil || fs.NArg() != 0 {
+		return ErrUsage
+	}
+
+	repo, done, err := bootstrap.OpenItemRepo()
+	if err != nil {
+		return err
+	}
+	defer done()
+	if err := service.InviteMember(cfg, repo, org, login, *role); err != nil {
+		return err
+	}
+	fmt.Fprintf(Out, "✓ %s: %s — %s\n", org, login, *role)
+	return nil
+}
+
+func orgMembers(cfg *config.Config, args []string) error {
+	if len(args) != 1 {
+		return ErrUsage
+	}
+	members, err := service.OrgMembers(cfg, args[0])
+	if err != nil {
+		return err
+	}
+	for _, m := range members {
+		note := ""
+		if !m.HasKey {
+			note = "  (нет открытого ключа)"
+		}
+		fmt.Fprintf(Out, "- %s  %s%s\n", m.Login, m.Role, note)
+	}
+	fmt.Fprintf(Out, "Всего: %d\n", len(members))
+	return nil
+}
+
+func orgRemove(cfg *config.Config, args []string) error {
+	if len(args) != 2 {
+		return ErrUsage
+	}
+	if err := service.RemoveMember(cfg, args[0], args[1]); err != nil {
+		return err
+	}
+	fmt.Fprintf(Out, "✓ %s: %s исключён из организации\n", args[0], args[1])
+	return nil
+}
+
+func orgPut(cfg *config.Config, args []string) error {
+	if len(args) < 3 {
+		return ErrUsage
+	}
+	org, collection, name, ok := splitCollectionRef(args[0])
+	if !ok {
+		return ErrUsage
+	}
+	repo, done, err := bootstrap.OpenItemRepo()
+	if err != nil {
+		return err
+	}
+	defer done()
+	ver, err := service.PutCollectionItem(cfg, repo, org, collection, name, args[1], args[2:])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(Out, "✓ %s: %s сохранено (ver=%d)\n", args[0], args[1], ver)
+	return nil
+}
+
+// splitCollectionRef разбирает ссылку org/collection/name на запись коллекции.
+func splitCollectionRef(ref string) (org, collection, name string, ok bool) {
+	parts := strings.SplitN(ref, "/", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], parts[2], true
+}
+
+// findCollectionItem ищет запись коллекции по ссылке org/collection/name.
+func findCollectionItem(repo crepo.ItemRepository, ref string) (*model.Collection, *model.CollectionItem, bool) {
+	cols, ok := service.Collections(repo)
+	if !ok {
+		return nil, nil, false
+	}
+	org, collection, name, ok := splitCollectionRef(ref)
+	if !ok {
+		return nil, nil, false
+	}
+	c, err := cols.GetCollection(org, collection)
+	if err != nil {
+		return nil, nil, false
+	}
+	it, err := cols.GetCollectionItem(c.ID, name)
+	if err != nil {
+		return nil, nil, false
+	}
+	return c, it, true
+}
+
+func init() { RegisterCmd(orgCmd{}) }
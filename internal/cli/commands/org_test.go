@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"GophKeeper/internal/cli/crypto"
+	"GophKeeper/internal/cli/model"
+	fsrepo "GophKeeper/internal/cli/repo/fs"
+	reposqlite "GophKeeper/internal/cli/repo/sqlite"
+	"GophKeeper/internal/config"
+)
+
+func TestOrg_Run_Usage(t *testing.T) {
+	for _, args := range [][]string{
+		{}, {"create"}, {"create", "a", "b"}, {"invite", "acme"}, {"invite", "acme", "bob", "x"},
+		{"members", "a", "b"}, {"remove", "acme"}, {"put", "acme/default", "text", "x"},
+		{"put", "acme/default/note", "text"}, {"rename", "acme"},
+	} {
+		if err := (orgCmd{}).Run(context.Background(), &config.Config{}, args); err != ErrUsage {
+			t.Fatalf("args %v: expected ErrUsage, got %v", args, err)
+		}
+	}
+}
+
+// Записи коллекций организаций видны в items и item-get по ссылке org/collection/name.
+func TestCollectionItem_ListGet(t *testing.T) {
+	withTempConfig(t)
+	_ = (fsrepo.AuthFSStore{}).SaveLogin("bob")
+	st, _, err := reposqlite.OpenForUser("bob")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer st.Close()
+	if err := st.Migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	// ключ коллекции, запечатанный открытым ключом bob
+	vault, _ := crypto.LoadOrCreateKey("bob")
+	kp, err := crypto.LoadOrCreateKeyPair("bob", vault)
+	if err != nil {
+		t.Fatalf("key pair: %v", err)
+	}
+	key, _ := crypto.NewItemKey()
+	eph, wrapped, nonce, err := crypto.SealKey(kp.Public, key)
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	tc, tn, _ := crypto.Encrypt([]byte("runbook"), key)
+	err = st.ReplaceCollections(
+		[]model.Collection{{ID: "c1", Org: "acme", Name: "default", Role: "member",
+			EphemeralKey: eph, WrappedKey: wrapped, WrappedKeyNonce: nonce}},
+		[]model.CollectionItem{{ID: "i1", CollectionID: "c1", Name: "ops", Version: 3, TextCipher: tc, TextNonce: tn}})
+	if err != nil {
+		t.Fatalf("replace collections: %v", err)
+	}
+
+	out := withStdoutCapture(t, func() { _ = (itemsCmd{}).Run(context.Background(), &config.Config{}, nil) })
+	if !strings.Contains(out, "name=acme/default/ops  ver=3  (member)") {
+		t.Fatalf("collection item not listed: %s", out)
+	}
+
+	out = withStdoutCapture(t, func() {
+		if err := (itemGetCmd{}).Run(context.Background(), &config.Config{}, []string{"acme/default/ops"}); err != nil {
+			t.Fatalf("item-get: %v", err)
+		}
+	})
+	if !strings.Contains(out, "org:       acme/default (member)") || !strings.Contains(out, "text:      runbook") {
+		t.Fatalf("item-get: unexpected output: %s", out)
+	}
+	if err := (itemGetCmd{}).Run(context.Background(), &config.Config{}, []string{"acme/default/missing"}); err == nil {
+		t.Fatal("expected error for missing collection item")
+	}
+}
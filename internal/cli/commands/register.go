@@ -10,7 +10,7 @@ import (
 
 	"GophKeeper/internal/cli/api"
 	fsrepo "GophKeeper/internal/cli/repo/fs"
-	reposqlite "GophKeeper/internal/cli/repo/sqlite"
+	"GophKeeper/internal/cli/service"
 )
 
 type RegisterRequest struct {
@@ -30,6 +30,16 @@ func (registerCmd) Run(ctx context.Context, cfg *config.Config, args []string) e
 	}
 	login := args[0]
 	password := args[1]
+	if cfg.Transport == config.TransportGRPC {
+		token, err := service.AuthGRPC(ctx, cfg, login, password, true)
+		if err != nil {
+			return err
+		}
+		if err := (fsrepo.AuthFSStore{}).Save(token); err != nil {
+			return fmt.Errorf("saving auth: %w", err)
+		}
+		return finishAuth(login, "Registered successfully")
+	}
 	baseURL := cfg.ServerURL
 	endpoint := strings.TrimRight(baseURL, "/") + "/api/user/register"
 	req := RegisterRequest{Login: login, Password: password}
@@ -42,26 +52,12 @@ func (registerCmd) Run(ctx context.Context, cfg *config.Config, args []string) e
 		if err := api.PersistAuthFromResponse(resp); err != nil {
 			return fmt.Errorf("saving auth: %w", err)
 		}
-		// remember last successful login
-		if err := (fsrepo.AuthFSStore{}).SaveLogin(login); err != nil {
-			return fmt.Errorf("save last login: %w", err)
-		}
-		// prepare per-user DB and run migrations
-		st, _, err := reposqlite.OpenForUser(login)
-		if err != nil {
-			return fmt.Errorf("open user db: %w", err)
-		}
-		defer st.Close()
-		if err := st.Migrate(); err != nil {
-			return fmt.Errorf("migrate user db: %w", err)
-		}
-		fmt.Fprintln(Out, "Registered successfully")
-		return nil
+		return finishAuth(login, "Registered successfully")
 	}
 	if resp.StatusCode == http.StatusConflict {
 		return errors.New("login already in use")
 	}
-	return fmt.Errorf("server error: %s", strings.TrimSpace(string(body)))
+	return fmt.Errorf("server error: %s", api.WithRequestID(strings.TrimSpace(string(body)), resp))
 }
 
 func init() { RegisterCmd(registerCmd{}) }
@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"GophKeeper/internal/cli/bootstrap"
+	"GophKeeper/internal/cli/service"
+	"GophKeeper/internal/config"
+)
+
+type restoreCmd struct{}
+
+func (restoreCmd) Name() string { return "restore" }
+func (restoreCmd) Description() string {
+	return "Восстановить запись из версии в истории сервера"
+}
+func (restoreCmd) Usage() string { return "restore <name> --version N" }
+
+func (restoreCmd) Run(ctx context.Context, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	version := fs.Int64("version", 0, "версия из history")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+	// имя может стоять как до, так и после флагов
+	if fs.NArg() < 1 {
+		return ErrUsage
+	}
+	name := fs.Arg(0)
+	if err := fs.Parse(fs.Args()[1:]); err != nil || fs.NArg() != 0 || *version <= 0 {
+		return ErrUsage
+	}
+
+	repo, done, err := bootstrap.OpenItemRepo()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	newVer, err := service.RestoreItemVersion(cfg, repo, name, *version)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(Out, "✓ %s: восстановлена версия %d, новая версия %d\n", name, *version, newVer)
+	return nil
+}
+
+func init() { RegisterCmd(restoreCmd{}) }
@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"GophKeeper/internal/cli/bootstrap"
+	"GophKeeper/internal/cli/model"
+	crepo "GophKeeper/internal/cli/repo"
+	"GophKeeper/internal/cli/service"
+	"GophKeeper/internal/config"
+)
+
+type shareCmd struct{}
+
+func (shareCmd) Name() string { return "share" }
+func (shareCmd) Description() string {
+	return "Открыть запись другому пользователю (только чтение) или отозвать доступ"
+}
+func (shareCmd) Usage() string { return "share <name> <login> [--revoke]" }
+
+func (shareCmd) Run(ctx context.Context, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("share", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	revoke := fs.Bool("revoke", false, "закрыть доступ вместо выдачи")
+	if err := fs.Parse(args); err != nil {
+		return ErrUsage
+	}
+	// позиционные аргументы могут стоять и до флага
+	if fs.NArg() < 2 {
+		return ErrUsage
+	}
+	name, login := fs.Arg(0), fs.Arg(1)
+	if err := fs.Parse(fs.Args()[2:]); err != nil || fs.NArg() != 0 {
+		return ErrUsage
+	}
+
+	repo, done, err := bootstrap.OpenItemRepo()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	if *revoke {
+		if err := service.RevokeShare(cfg, repo, name, login); err != nil {
+			return err
+		}
+		fmt.Fprintf(Out, "✓ %s: доступ пользователя %s закрыт\n", name, login)
+		return nil
+	}
+	if err := service.ShareItem(cfg, repo, name, login); err != nil {
+		return err
+	}
+	fmt.Fprintf(Out, "✓ %s: открыта пользователю %s (только чтение)\n", name, login)
+	return nil
+}
+
+// findSharedItem ищет общую запись по ссылке name или owner/name.
+func findSharedItem(repo crepo.ItemRepository, ref string) (*model.SharedItem, bool) {
+	shared, ok := service.SharedItems(repo)
+	if !ok {
+		return nil, false
+	}
+	owner, name := "", ref
+	if i := strings.Index(ref, "/"); i > 0 {
+		owner, name = ref[:i], ref[i+1:]
+	}
+	s, err := shared.GetSharedItem(owner, name)
+	if err != nil {
+		return nil, false
+	}
+	return s, true
+}
+
+// syncShares обновляет общие записи после синхронизации; ошибки не фатальны:
+// записи пользователя уже синхронизированы.
+func syncShares(cfg *config.Config, repo crepo.ItemRepository) {
+	res, err := service.SyncShares(cfg, repo)
+	if err != nil {
+		fmt.Fprintf(Out, "! Общие записи не обновлены: %v\n", err)
+		return
+	}
+	if res.Resealed > 0 {
+		fmt.Fprintf(Out, "• Обновлено открытых вами записей: %d\n", res.Resealed)
+	}
+	if res.Incoming > 0 {
+		fmt.Fprintf(Out, "• Записей, открытых вам: %d\n", res.Incoming)
+	}
+}
+
+func init() { RegisterCmd(shareCmd{}) }
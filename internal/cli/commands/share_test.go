@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"GophKeeper/internal/cli/crypto"
+	"GophKeeper/internal/cli/model"
+	fsrepo "GophKeeper/internal/cli/repo/fs"
+	reposqlite "GophKeeper/internal/cli/repo/sqlite"
+	"GophKeeper/internal/config"
+)
+
+func TestShare_Run_Usage(t *testing.T) {
+	for _, args := range [][]string{{}, {"wifi"}, {"wifi", "bob", "extra"}, {"--bad", "wifi", "bob"}} {
+		if err := (shareCmd{}).Run(context.Background(), &config.Config{}, args); err != ErrUsage {
+			t.Fatalf("args %v: expected ErrUsage, got %v", args, err)
+		}
+	}
+}
+
+// Открытая пользователю запись видна в items и item-get, но не редактируется.
+func TestSharedItem_ListGetReadOnly(t *testing.T) {
+	withTempConfig(t)
+	_ = (fsrepo.AuthFSStore{}).SaveLogin("bob")
+	st, _, err := reposqlite.OpenForUser("bob")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer st.Close()
+	if err := st.Migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	// доля от alice, запечатанная открытым ключом bob
+	vault, _ := crypto.LoadOrCreateKey("bob")
+	kp, err := crypto.LoadOrCreateKeyPair("bob", vault)
+	if err != nil {
+		t.Fatalf("key pair: %v", err)
+	}
+	itemKey, _ := crypto.NewItemKey()
+	eph, wrapped, nonce, err := crypto.SealKey(kp.Public, itemKey)
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	pc, pn, _ := crypto.Encrypt([]byte("s3cret"), itemKey)
+	if err := st.ReplaceSharedItems([]model.SharedItem{{
+		ItemID: "i1", Name: "wifi", Owner: "alice", Version: 2, ItemVersion: 2,
+		EphemeralKey: eph, WrappedKey: wrapped, WrappedKeyNonce: nonce,
+		PasswordCipher: pc, PasswordNonce: pn,
+	}}); err != nil {
+		t.Fatalf("replace shared: %v", err)
+	}
+
+	out := withStdoutCapture(t, func() { _ = (itemsCmd{}).Run(context.Background(), &config.Config{}, nil) })
+	if !strings.Contains(out, "name=alice/wifi") || !strings.Contains(out, "shared by alice, read-only") {
+		t.Fatalf("shared item not listed: %s", out)
+	}
+
+	for _, ref := range []string{"wifi", "alice/wifi"} {
+		out = withStdoutCapture(t, func() {
+			if err := (itemGetCmd{}).Run(context.Background(), &config.Config{}, []string{ref}); err != nil {
+				t.Fatalf("item-get %s: %v", ref, err)
+			}
+		})
+		if !strings.Contains(out, "owner:     alice (read-only)") || !strings.Contains(out, "password:  s3cret") {
+			t.Fatalf("item-get %s: unexpected output: %s", ref, out)
+		}
+	}
+
+	err = (itemEditCmd{}).Run(context.Background(), &config.Config{}, []string{"wifi", "password", "x"})
+	if err == nil || !strings.Contains(err.Error(), "только для чтения") {
+		t.Fatalf("expected read-only error, got %v", err)
+	}
+}
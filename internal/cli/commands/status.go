@@ -7,21 +7,33 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	"GophKeeper/internal/cli/api"
 	fsrepo "GophKeeper/internal/cli/repo/fs"
 	"GophKeeper/internal/config"
+	"GophKeeper/internal/logging"
 )
 
 type dataResponse struct {
 	Result string `json:"result"`
 }
 
+// usageResponse соответствует ответу GET /api/user/usage (0 в *_limit — без ограничения).
+type usageResponse struct {
+	Items          int64 `json:"items"`
+	ItemsLimit     int64 `json:"items_limit"`
+	BlobBytes      int64 `json:"blob_bytes"`
+	BlobBytesLimit int64 `json:"blob_bytes_limit"`
+	ItemBytesLimit int64 `json:"item_bytes_limit"`
+}
+
 type statusCmd struct{}
 
 func (statusCmd) Name() string        { return "status" }
-func (statusCmd) Description() string { return "Check auth status (calls /api/user/test)" }
+func (statusCmd) Description() string { return "Check auth status and storage usage" }
 func (statusCmd) Usage() string       { return "status" }
 
 func (statusCmd) Run(ctx context.Context, cfg *config.Config, args []string) error {
@@ -48,10 +60,11 @@ func (statusCmd) Run(ctx context.Context, cfg *config.Config, args []string) err
 		return err
 	}
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(logging.RequestIDHeader, logging.NewRequestID())
 	if token != "" {
 		req.Header.Set("Cookie", "auth_token="+token)
 	}
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := api.HTTPClient().Do(req)
 	if err != nil {
 		// Если это контекстная отмена/таймаут — вернём её явно
 		return err
@@ -59,7 +72,7 @@ func (statusCmd) Run(ctx context.Context, cfg *config.Config, args []string) err
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("server status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return api.StatusError(resp, body)
 	}
 	var dr dataResponse
 	body, _ := io.ReadAll(resp.Body)
@@ -67,7 +80,50 @@ func (statusCmd) Run(ctx context.Context, cfg *config.Config, args []string) err
 		return fmt.Errorf("decode: %w", err)
 	}
 	fmt.Fprintln(Out, "Status:", dr.Result)
+	if token != "" {
+		printUsage(strings.TrimRight(baseURL, "/")+"/api/user/usage", token)
+	}
 	return nil
 }
 
+// printUsage выводит использование квот. Ошибки не фатальны: статус авторизации уже выведен.
+func printUsage(endpoint, token string) {
+	resp, body, err := api.GetJSON(endpoint, token)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		fmt.Fprintln(Out, "Usage: unavailable")
+		return
+	}
+	var u usageResponse
+	if err := json.Unmarshal(body, &u); err != nil {
+		fmt.Fprintln(Out, "Usage: unavailable")
+		return
+	}
+	fmt.Fprintf(Out, "Items: %d / %s\n", u.Items, formatLimit(u.ItemsLimit, strconv.FormatInt))
+	fmt.Fprintf(Out, "Blobs: %s / %s\n", formatBytes(u.BlobBytes), formatLimit(u.BlobBytesLimit, func(v int64, _ int) string { return formatBytes(v) }))
+	if u.ItemBytesLimit > 0 {
+		fmt.Fprintf(Out, "Max item size: %s\n", formatBytes(u.ItemBytesLimit))
+	}
+}
+
+func formatLimit(v int64, f func(int64, int) string) string {
+	if v <= 0 {
+		return "unlimited"
+	}
+	return f(v, 10)
+}
+
+// formatBytes форматирует размер в двоичных единицах: 512 B, 1.5 KiB, 3.0 MiB.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
 func init() { RegisterCmd(statusCmd{}) }
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"GophKeeper/internal/cli/bootstrap"
+	crepo "GophKeeper/internal/cli/repo"
 	"GophKeeper/internal/cli/service"
 	"GophKeeper/internal/config"
 )
@@ -21,20 +22,21 @@ func (syncCmd) Description() string {
 	return "Синхронизировать все записи с сервером"
 }
 func (syncCmd) Usage() string {
-	return "sync [--all] [--resolve=client|server]"
+	return "sync [--all] [--atomic] [--resolve=client|server|both]"
 }
 
 func (syncCmd) Run(ctx context.Context, cfg *config.Config, args []string) error {
 	fs := flag.NewFlagSet("sync", flag.ContinueOnError)
 	fs.SetOutput(io.Discard)
 	all := fs.Bool("all", false, "полная синхронизация с начала времён")
-	resolve := fs.String("resolve", "", "стратегия разрешения конфликта: client|server")
+	atomic := fs.Bool("atomic", false, "применить изменения на сервере целиком или не применять ни одного")
+	resolve := fs.String("resolve", "", "стратегия разрешения конфликта: client|server|both")
 	if err := fs.Parse(args); err != nil {
 		return ErrUsage
 	}
 	var resolvePtr *string
 	if *resolve != "" {
-		if *resolve != "client" && *resolve != "server" {
+		if !validResolve(*resolve) {
 			return ErrUsage
 		}
 		resolvePtr = resolve
@@ -56,8 +58,10 @@ func (syncCmd) Run(ctx context.Context, cfg *config.Config, args []string) error
 	go func() {
 		defer close(resCh)
 		res := service.RunSyncBatch(ctx, cfg, repo, service.BatchSyncOptions{
-			All:     *all,
-			Resolve: resolvePtr,
+			All:         *all,
+			Resolve:     resolvePtr,
+			Atomic:      *atomic,
+			ChangedOnly: true,
 		})
 		resCh <- res
 	}()
@@ -68,43 +72,126 @@ func (syncCmd) Run(ctx context.Context, cfg *config.Config, args []string) error
 		return nil
 	}
 
+	if len(res.Conflicts) > 0 && resolvePtr == nil {
+		return resolveConflictsInteractive(ctx, cfg, repo, service.BatchSyncOptions{All: *all, Atomic: *atomic, ChangedOnly: true}, res)
+	}
 	if res.ConflictsJSON != "" {
-		if resolvePtr == nil {
-			fmt.Fprintf(Out, "! Конфликты на сервере: %s\n", res.ConflictsJSON)
-			reader := bufio.NewReader(os.Stdin)
-			for {
-				fmt.Fprint(Out, "Выберите действие [client|server|cancel]: ")
-				line, _ := reader.ReadString('\n')
-				choice := strings.TrimSpace(strings.ToLower(line))
-				if choice == "client" || choice == "server" {
-					ch := choice
-					fmt.Fprintf(Out, "→ Повторная синхронизация (resolve=%s)…\n", ch)
-					res2 := service.RunSyncBatch(ctx, cfg, repo, service.BatchSyncOptions{
-						All:     *all,
-						Resolve: &ch,
-					})
-					if res2.Err != nil {
-						fmt.Fprintf(Out, "× Ошибка синхронизации: %v\n", res2.Err)
-						return nil
-					}
-					printBatchSummary(res2)
-					return nil
-				}
-				if choice == "cancel" || choice == "c" {
-					fmt.Fprintln(Out, "• Отменено пользователем")
-					return nil
-				}
-				fmt.Fprintln(Out, "Некорректный выбор. Введите client, server или cancel.")
+		fmt.Fprintf(Out, "! Конфликты на сервере: %s\n", res.ConflictsJSON)
+	}
+	printConflictCopies(res.ConflictCopies)
+
+	printBatchSummary(res)
+	syncShares(cfg, repo)
+	return nil
+}
+
+// resolveConflictsInteractive по очереди спрашивает стратегию для каждого конфликта
+// и повторяет синхронизацию с выбранными стратегиями (и прежними опциями opts).
+// Изменения, отменённые атомарным режимом, не спрашиваются: они уйдут при повторе.
+// Первая синхронизация не затирает локальные правки конфликтующих записей, поэтому
+// client отправляет, а both копирует именно их.
+func resolveConflictsInteractive(ctx context.Context, cfg *config.Config, repo crepo.ItemRepository, opts service.BatchSyncOptions, res service.BatchSyncResult) error {
+	reader := bufio.NewReader(os.Stdin)
+	resolutions := map[string]string{}
+	skipped := 0
+	conflicts := make([]service.SyncConflict, 0, len(res.Conflicts))
+	for _, c := range res.Conflicts {
+		if c.Reason != service.ReasonAtomicAborted {
+			conflicts = append(conflicts, c)
+		}
+	}
+	if aborted := len(res.Conflicts) - len(conflicts); aborted > 0 {
+		fmt.Fprintf(Out, "• Не применено из-за конфликтов (атомарный режим): %d\n", aborted)
+	}
+	for i, c := range conflicts {
+		fmt.Fprintf(Out, "! Конфликт %d/%d: %s\n", i+1, len(conflicts), describeConflict(c))
+		choice, ok := promptConflictChoice(reader)
+		if !ok {
+			fmt.Fprintln(Out, "• Отменено пользователем")
+			return nil
+		}
+		switch choice {
+		case "client", "server":
+			resolutions[c.ID] = choice
+		case "both":
+			if c.Name == "" {
+				fmt.Fprintln(Out, "× Локальная запись не найдена — конфликт пропущен")
+				skipped++
+				continue
 			}
-		} else {
-			fmt.Fprintf(Out, "! Конфликты на сервере: %s\n", res.ConflictsJSON)
+			resolutions[c.ID] = choice
+		default: // skip
+			skipped++
 		}
 	}
+	if skipped > 0 {
+		fmt.Fprintf(Out, "• Пропущено конфликтов: %d\n", skipped)
+	}
+	if len(resolutions) == 0 {
+		printBatchSummary(res)
+		return nil
+	}
 
-	printBatchSummary(res)
+	fmt.Fprintf(Out, "→ Повторная синхронизация с выбранными стратегиями (%d)…\n", len(resolutions))
+	opts.Resolutions = resolutions
+	res2 := service.RunSyncBatch(ctx, cfg, repo, opts)
+	if res2.Err != nil {
+		fmt.Fprintf(Out, "× Ошибка синхронизации: %v\n", res2.Err)
+		return nil
+	}
+	printConflictCopies(res2.ConflictCopies)
+	if len(res2.Conflicts) > 0 {
+		fmt.Fprintf(Out, "! Осталось неразрешённых конфликтов: %d\n", len(res2.Conflicts))
+	}
+	printBatchSummary(res2)
 	return nil
 }
 
+// promptConflictChoice читает выбор пользователя; ok=false — отмена (или конец ввода).
+func promptConflictChoice(reader *bufio.Reader) (string, bool) {
+	for {
+		fmt.Fprint(Out, "Выберите действие [client|server|both|skip|cancel]: ")
+		line, err := reader.ReadString('\n')
+		choice := strings.TrimSpace(strings.ToLower(line))
+		switch choice {
+		case "client", "server", "both", "skip":
+			return choice, true
+		case "cancel", "c":
+			return "", false
+		}
+		if err != nil {
+			return "", false
+		}
+		fmt.Fprintln(Out, "Некорректный выбор. Введите client, server, both, skip или cancel.")
+	}
+}
+
+func printConflictCopies(names []string) {
+	for _, n := range names {
+		fmt.Fprintf(Out, "• Локальная версия сохранена как %s\n", n)
+	}
+}
+
+// validResolve проверяет значение флага --resolve.
+func validResolve(s string) bool {
+	return s == "client" || s == "server" || s == "both"
+}
+
+func describeConflict(c service.SyncConflict) string {
+	name := c.Name
+	if name == "" {
+		name = c.ID
+	}
+	desc := fmt.Sprintf("%s (id=%s, причина: %s", name, c.ID, c.Reason)
+	if c.ServerVersion > 0 {
+		desc += fmt.Sprintf(", версия на сервере: %d", c.ServerVersion)
+	}
+	if len(c.Fields) > 0 {
+		desc += ", поля: " + strings.Join(c.Fields, ", ")
+	}
+	return desc + ")"
+}
+
 func printBatchSummary(res service.BatchSyncResult) {
 	if res.AppliedCount > 0 {
 		fmt.Fprintf(Out, "✓ Применено изменений: %d\n", res.AppliedCount)
@@ -115,6 +202,9 @@ func printBatchSummary(res service.BatchSyncResult) {
 	if len(res.QueuedBlobIDs) > 0 {
 		fmt.Fprintf(Out, "• Поставлено на догрузку blob'ов: %d\n", len(res.QueuedBlobIDs))
 	}
+	if res.Collections > 0 {
+		fmt.Fprintf(Out, "• Коллекций организаций: %d\n", res.Collections)
+	}
 	if res.ServerTime != "" {
 		fmt.Fprintf(Out, "• Метка сервера: %s\n", res.ServerTime)
 	}
@@ -44,10 +44,22 @@ func setupSyncUserEnv(t *testing.T, login string) {
 	}
 }
 
+// noShares отвечает 404 на запросы обмена записями, которые sync делает после синхронизации.
+func noShares(w http.ResponseWriter, r *http.Request) bool {
+	if strings.HasPrefix(r.URL.Path, "/api/user/keys") || strings.HasPrefix(r.URL.Path, "/api/shares") {
+		http.NotFound(w, r)
+		return true
+	}
+	return false
+}
+
 func TestSync_Run_Applied_PrintSummary(t *testing.T) {
 	setupSyncUserEnv(t, "john")
 	// сервер: applied=2, server_time задан
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if noShares(w, r) {
+			return
+		}
 		if !strings.HasSuffix(r.URL.Path, "/api/items/sync") {
 			t.Fatalf("bad path: %s", r.URL.Path)
 		}
@@ -89,14 +101,14 @@ func TestSync_Run_Conflicts_Interactive_ClientThenApplied(t *testing.T) {
 				"conflicts":   []map[string]any{{"id": "x", "reason": "version_conflict"}},
 				"server_time": time.Now().UTC().Format(time.RFC3339),
 			})
-		default: // второй вызов — resolve=client и applied
-			// Проверим, что клиент отправил resolve=client
+		default: // второй вызов — стратегия client для x и applied
+			// Проверим, что клиент отправил стратегию для конкретной записи
 			var req struct {
-				Resolve *string `json:"resolve"`
+				Resolutions map[string]string `json:"resolutions"`
 			}
 			_ = json.NewDecoder(r.Body).Decode(&req)
-			if req.Resolve == nil || *req.Resolve != "client" {
-				t.Fatalf("expect resolve=client, got %v", req.Resolve)
+			if req.Resolutions["x"] != "client" {
+				t.Fatalf("expect resolutions[x]=client, got %v", req.Resolutions)
 			}
 			_ = json.NewEncoder(w).Encode(map[string]any{
 				"applied":        []map[string]any{{"id": "x", "new_version": 10}},
@@ -122,7 +134,7 @@ func TestSync_Run_Conflicts_Interactive_ClientThenApplied(t *testing.T) {
 		t.Fatalf("run err: %v", err)
 	}
 	out := buf.String()
-	if !(strings.Contains(out, "Конфликты на сервере") && strings.Contains(out, "Повторная синхронизация (resolve=client)")) {
+	if !(strings.Contains(out, "Конфликт 1/1: x") && strings.Contains(out, "Повторная синхронизация с выбранными стратегиями (1)")) {
 		t.Fatalf("unexpected out: %s", out)
 	}
 }
@@ -141,11 +153,11 @@ func TestSync_Run_Conflicts_Interactive_ServerThenSummary(t *testing.T) {
 			})
 		default:
 			var req struct {
-				Resolve *string `json:"resolve"`
+				Resolutions map[string]string `json:"resolutions"`
 			}
 			_ = json.NewDecoder(r.Body).Decode(&req)
-			if req.Resolve == nil || *req.Resolve != "server" {
-				t.Fatalf("expect resolve=server, got %v", req.Resolve)
+			if req.Resolutions["y"] != "server" {
+				t.Fatalf("expect resolutions[y]=server, got %v", req.Resolutions)
 			}
 			// При resolve=server RunSyncBatch увеличивает ServerUpserts после применения server_item
 			_ = json.NewEncoder(w).Encode(map[string]any{
@@ -172,7 +184,7 @@ func TestSync_Run_Conflicts_Interactive_ServerThenSummary(t *testing.T) {
 	}
 	out := buf.String()
 	// Проверим, что была повторная синхронизация и вывод резюме состоялся (строка о метке сервера или об отсутствии изменений)
-	if !(strings.Contains(out, "Повторная синхронизация (resolve=server)") && (strings.Contains(out, "Метка сервера:") || strings.Contains(out, "изменений не применено"))) {
+	if !(strings.Contains(out, "версия на сервере: 5") && strings.Contains(out, "Повторная синхронизация с выбранными стратегиями") && (strings.Contains(out, "Метка сервера:") || strings.Contains(out, "изменений не применено"))) {
 		t.Fatalf("unexpected out: %s", out)
 	}
 }
@@ -206,17 +218,154 @@ func TestSync_Run_Conflicts_Interactive_Cancel(t *testing.T) {
 	}
 }
 
+func TestSync_Run_Conflicts_PerItem_KeepBothAndSkip(t *testing.T) {
+	setupSyncUserEnv(t, "mia")
+	mailID := addLocalItem(t, "mia", "mail")
+	bankID := addLocalItem(t, "mia", "bank")
+	var second, upload map[string]any
+	phase := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		now := time.Now().UTC().Format(time.RFC3339)
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		phase++
+		switch phase {
+		case 1:
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"applied": []any{},
+				"conflicts": []map[string]any{
+					{"id": mailID, "reason": "version_conflict", "fields": []string{"login"}},
+					{"id": bankID, "reason": "version_conflict"},
+				},
+				"server_time": now,
+			})
+		case 2:
+			second = body
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"applied": []any{},
+				"conflicts": []map[string]any{{
+					"id": mailID, "reason": "version_conflict",
+					"server_item": map[string]any{"id": mailID, "name": "mail", "version": 5},
+				}},
+				"server_changes": []any{}, "server_time": now,
+			})
+		default:
+			// выгрузка копии локальной версии
+			upload = body
+			changes, _ := body["changes"].([]any)
+			ch, _ := changes[0].(map[string]any)
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"applied":   []map[string]any{{"id": ch["id"], "new_version": 1}},
+				"conflicts": []any{}, "server_changes": []any{}, "server_time": now,
+			})
+		}
+	}))
+	defer ts.Close()
+	cfg := &config.Config{ServerURL: ts.URL}
+
+	restore := setStdin(t, "oops\nboth\nskip\n")
+	defer restore()
+	var buf bytes.Buffer
+	old := Out
+	Out = &buf
+	defer func() { Out = old }()
+
+	if err := (syncCmd{}).Run(context.Background(), cfg, []string{}); err != nil {
+		t.Fatalf("run err: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"Конфликт 1/2: mail", "поля: login", "Конфликт 2/2: bank", "Некорректный выбор", "Локальная версия сохранена как mail.conflict-", "Пропущено конфликтов: 1"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("output misses %q: %s", want, out)
+		}
+	}
+	// keep-both уходит на сервер как есть, пропущенный конфликт — без стратегии
+	res, _ := second["resolutions"].(map[string]any)
+	if res[mailID] != "both" || res[bankID] != nil {
+		t.Fatalf("unexpected resolutions: %v", second["resolutions"])
+	}
+	// копия локальной версии выгружается отдельной новой записью
+	changes, _ := upload["changes"].([]any)
+	if len(changes) != 1 {
+		t.Fatalf("expected copy upload, got %v", upload)
+	}
+	ch, _ := changes[0].(map[string]any)
+	if name, _ := ch["name"].(string); !strings.HasPrefix(name, "mail.conflict-") || ch["id"] == mailID {
+		t.Fatalf("unexpected copy change: %v", ch)
+	}
+}
+
+func TestSync_Run_Atomic_SkipsAbortedInPrompt(t *testing.T) {
+	setupSyncUserEnv(t, "olga")
+	var requests []map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+		now := time.Now().UTC().Format(time.RFC3339)
+		if len(requests) == 1 {
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"applied": []any{},
+				"conflicts": []map[string]any{
+					{"id": "x", "reason": "version_conflict"},
+					{"id": "y", "reason": "atomic_aborted"},
+				},
+				"server_time": now,
+			})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"applied":   []map[string]any{{"id": "x", "new_version": 3}, {"id": "y", "new_version": 2}},
+			"conflicts": []any{}, "server_changes": []any{}, "server_time": now,
+		})
+	}))
+	defer ts.Close()
+	cfg := &config.Config{ServerURL: ts.URL}
+
+	restore := setStdin(t, "client\n")
+	defer restore()
+	var buf bytes.Buffer
+	old := Out
+	Out = &buf
+	defer func() { Out = old }()
+
+	if err := (syncCmd{}).Run(context.Background(), cfg, []string{"--atomic"}); err != nil {
+		t.Fatalf("run err: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"атомарный режим): 1", "Конфликт 1/1: x", "Применено изменений: 2"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("output misses %q: %s", want, out)
+		}
+	}
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+	for i, req := range requests {
+		if req["atomic"] != true {
+			t.Fatalf("request %d without atomic: %v", i, req)
+		}
+	}
+	res, _ := requests[1]["resolutions"].(map[string]any)
+	if res["x"] != "client" || res["y"] != nil {
+		t.Fatalf("unexpected resolutions: %v", requests[1]["resolutions"])
+	}
+}
+
 func TestSync_Run_AllAndResolveClient_Flags(t *testing.T) {
 	setupSyncUserEnv(t, "nick")
-	// Проверим, что last_sync_at = epoch и resolve=client уходит в тело
+	// Проверим, что cursor = "0" (полная синхронизация) и resolve=client уходит в тело
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if noShares(w, r) {
+			return
+		}
 		var req struct {
-			LastSyncAt string  `json:"last_sync_at"`
-			Resolve    *string `json:"resolve"`
+			Cursor  string  `json:"cursor"`
+			Resolve *string `json:"resolve"`
 		}
 		_ = json.NewDecoder(r.Body).Decode(&req)
-		if req.LastSyncAt != "1970-01-01T00:00:00Z" {
-			t.Fatalf("expect epoch, got %s", req.LastSyncAt)
+		if req.Cursor != "0" {
+			t.Fatalf("expect full sync cursor, got %s", req.Cursor)
 		}
 		if req.Resolve == nil || *req.Resolve != "client" {
 			t.Fatalf("expect resolve=client, got %v", req.Resolve)
@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	fsrepo "GophKeeper/internal/cli/repo/fs"
+	"GophKeeper/internal/config"
+)
+
+type trustResetCmd struct{}
+
+func (trustResetCmd) Name() string { return "trust-reset" }
+func (trustResetCmd) Description() string {
+	return "Забыть закреплённый ключ сервера (после плановой смены ключа)"
+}
+func (trustResetCmd) Usage() string { return "trust-reset" }
+
+func (trustResetCmd) Run(_ context.Context, cfg *config.Config, args []string) error {
+	if len(args) != 0 {
+		return ErrUsage
+	}
+	removed, err := fsrepo.ResetServerPin(cfg.BaseURL)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		fmt.Fprintf(Out, "Ключ сервера %s не закреплён\n", cfg.BaseURL)
+		return nil
+	}
+	fmt.Fprintf(Out, "✓ Закреплённый ключ сервера %s удалён; новый будет закреплён при следующем подключении\n", cfg.BaseURL)
+	return nil
+}
+
+func init() { RegisterCmd(trustResetCmd{}) }
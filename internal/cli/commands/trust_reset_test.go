@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	fsrepo "GophKeeper/internal/cli/repo/fs"
+	"GophKeeper/internal/config"
+)
+
+func TestTrustReset(t *testing.T) {
+	withTempConfig(t)
+	cfg := &config.Config{BaseURL: "keeper.local:8081"}
+	if err := fsrepo.SaveServerPin(cfg.BaseURL, "sha256/AAA="); err != nil {
+		t.Fatal(err)
+	}
+	if err := fsrepo.SaveServerPin("other:8081", "sha256/BBB="); err != nil {
+		t.Fatal(err)
+	}
+
+	out := withStdoutCapture(t, func() {
+		if code := Dispatch(context.Background(), cfg, []string{"trust-reset"}); code != 0 {
+			t.Fatalf("exit code %d", code)
+		}
+	})
+	if !strings.Contains(out, "удалён") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+	if _, err := fsrepo.LoadServerPin(cfg.BaseURL); err == nil {
+		t.Fatalf("pin must be removed")
+	}
+	if _, err := fsrepo.LoadServerPin("other:8081"); err != nil {
+		t.Fatalf("other profile must keep its pin: %v", err)
+	}
+
+	out = withStdoutCapture(t, func() { _ = Dispatch(context.Background(), cfg, []string{"trust-reset"}) })
+	if !strings.Contains(out, "не закреплён") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+	if err := (trustResetCmd{}).Run(context.Background(), cfg, []string{"x"}); err != ErrUsage {
+		t.Fatalf("extra args must be a usage error, got %v", err)
+	}
+}
+
+func TestDispatcher_TLSFlagsRequireHTTPS(t *testing.T) {
+	withTempConfig(t)
+	cfg := &config.Config{BaseURL: "keeper.local:8081", CAFile: "ca.pem"}
+	out := withStdoutCapture(t, func() {
+		if code := Dispatch(context.Background(), cfg, []string{"trust-reset"}); code != 1 {
+			t.Fatalf("expected exit 1, got %d", code)
+		}
+	})
+	if !strings.Contains(out, "require --https") {
+		t.Fatalf("error line expected, got: %s", out)
+	}
+}
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"GophKeeper/internal/cli/bootstrap"
+	crepo "GophKeeper/internal/cli/repo"
+	fsrepo "GophKeeper/internal/cli/repo/fs"
+	"GophKeeper/internal/cli/service"
+	"GophKeeper/internal/config"
+)
+
+// Пауза перед переподключением к потоку событий: удваивается после каждого
+// неудачного подключения до watchRetryMax и сбрасывается после успешного.
+var (
+	watchRetryMin = time.Second
+	watchRetryMax = 30 * time.Second
+)
+
+type watchCmd struct{}
+
+func (watchCmd) Name() string { return "watch" }
+func (watchCmd) Description() string {
+	return "Следить за изменениями на сервере и синхронизироваться автоматически"
+}
+func (watchCmd) Usage() string { return "watch" }
+
+func (watchCmd) Run(ctx context.Context, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil || fs.NArg() > 0 {
+		return ErrUsage
+	}
+	login, err := (fsrepo.AuthFSStore{}).LoadLogin()
+	if err != nil {
+		return fmt.Errorf("нет активного пользователя: %w", err)
+	}
+
+	repo, done, err := bootstrap.OpenItemRepo()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	fmt.Fprintln(Out, "→ Наблюдение за изменениями на сервере (Ctrl+C — остановить)…")
+	// после (пере)подключения догоняем изменения, пропущенные без соединения;
+	// событие с уже сохранённым курсором — эхо нашей же синхронизации
+	onConnect := func() {
+		fmt.Fprintln(Out, "• Подключено к серверу")
+		watchSync(ctx, cfg, repo)
+	}
+	onEvent := func(ev service.ChangeEvent) {
+		if ev.Cursor != "" {
+			if cur, err := fsrepo.LoadSyncCursor(login); err == nil && cur == ev.Cursor {
+				return
+			}
+		}
+		watchSync(ctx, cfg, repo)
+	}
+
+	delay := watchRetryMin
+	for {
+		connected := false
+		err := service.WatchEvents(ctx, cfg, func() {
+			connected = true
+			onConnect()
+		}, onEvent)
+		if ctx.Err() != nil {
+			fmt.Fprintln(Out, "• Наблюдение остановлено")
+			return nil
+		}
+		if errors.Is(err, service.ErrEventsUnauthorized) {
+			fmt.Fprintln(Out, "× Сервер отклонил токен — выполните gkcli login")
+			return nil
+		}
+		if connected {
+			delay = watchRetryMin
+		}
+		fmt.Fprintf(Out, "× Соединение потеряно: %v; повтор через %s\n", err, delay)
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(Out, "• Наблюдение остановлено")
+			return nil
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, watchRetryMax)
+	}
+}
+
+// watchSync выполняет инкрементальную синхронизацию только изменённых локально записей.
+// Конфликты в фоне не разрешаются — о них лишь сообщается.
+func watchSync(ctx context.Context, cfg *config.Config, repo crepo.ItemRepository) {
+	res := service.RunSyncBatch(ctx, cfg, repo, service.BatchSyncOptions{ChangedOnly: true})
+	if res.Err != nil {
+		fmt.Fprintf(Out, "× Ошибка синхронизации: %v\n", res.Err)
+		return
+	}
+	printBatchSummary(res)
+	if len(res.Conflicts) > 0 {
+		fmt.Fprintf(Out, "! Неразрешённых конфликтов: %d — выполните gkcli sync\n", len(res.Conflicts))
+	}
+}
+
+func init() { RegisterCmd(watchCmd{}) }
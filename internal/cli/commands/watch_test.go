@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	fsrepo "GophKeeper/internal/cli/repo/fs"
+	"GophKeeper/internal/config"
+)
+
+func TestWatch_Run_SyncsOnConnectAndOnEvent(t *testing.T) {
+	setupSyncUserEnv(t, "wendy")
+	_ = fsrepo.SaveSyncCursor("wendy", "5")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var mu sync.Mutex
+	syncs := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/events":
+			w.Header().Set("Content-Type", "text/event-stream")
+			// событие с уже известным курсором — эхо, синхронизация не нужна
+			_, _ = fmt.Fprint(w, ": connected\n\nevent: change\ndata: {\"cursor\":\"5\",\"item_ids\":[\"a\"]}\n\n")
+			_, _ = fmt.Fprint(w, "event: change\ndata: {\"cursor\":\"9\",\"item_ids\":[\"b\"]}\n\n")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		case "/api/items/sync":
+			mu.Lock()
+			syncs++
+			n := syncs
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"applied":     []map[string]any{{"id": fmt.Sprintf("i%d", n), "new_version": 1}},
+				"cursor":      "5",
+				"server_time": time.Now().UTC().Format(time.RFC3339),
+			})
+			if n == 2 {
+				cancel()
+			}
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	old := Out
+	var buf bytes.Buffer
+	Out = &buf
+	defer func() { Out = old }()
+
+	if err := (watchCmd{}).Run(ctx, &config.Config{ServerURL: ts.URL}, nil); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	out := buf.String()
+	mu.Lock()
+	defer mu.Unlock()
+	if syncs != 2 {
+		t.Fatalf("expected sync on connect and on new event, got %d\n%s", syncs, out)
+	}
+	for _, want := range []string{"• Подключено к серверу", "✓ Применено изменений: 1", "• Наблюдение остановлено"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestWatch_Run_StopsOnUnauthorized(t *testing.T) {
+	setupSyncUserEnv(t, "wes")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+
+	old := Out
+	var buf bytes.Buffer
+	Out = &buf
+	defer func() { Out = old }()
+
+	if err := (watchCmd{}).Run(context.Background(), &config.Config{ServerURL: ts.URL}, nil); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if !strings.Contains(buf.String(), "gkcli login") {
+		t.Fatalf("unexpected out: %s", buf.String())
+	}
+}
+
+func TestWatch_Run_RejectsArgs(t *testing.T) {
+	if err := (watchCmd{}).Run(context.Background(), &config.Config{}, []string{"x"}); err != ErrUsage {
+		t.Fatalf("expected ErrUsage, got %v", err)
+	}
+}
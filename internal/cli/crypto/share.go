@@ -0,0 +1,155 @@
+package crypto
+
+import (
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// shareInfo — контекст HKDF для ключа, которым запечатывается ключ записи получателю.
+const shareInfo = "GophKeeper share v1"
+
+// nonceLen — длина nonce AES‑GCM в файле пары ключей.
+const nonceLen = 12
+
+// KeyPair — пара ключей X25519 пользователя; закрытый ключ хранится зашифрованным
+// ключом хранилища (WrappedPrivate, Nonce) — в таком виде он публикуется и на сервере.
+type KeyPair struct {
+	Private        *ecdh.PrivateKey
+	Public         []byte
+	WrappedPrivate []byte
+	Nonce          []byte
+}
+
+// keyPairFilePath возвращает путь к файлу пары ключей рядом с key.bin.
+func keyPairFilePath(login string) (string, error) {
+	keyPath, err := keyFilePath(login)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(keyPath), "x25519.bin"), nil
+}
+
+// LoadOrCreateKeyPair загружает пару ключей X25519 пользователя или создаёт новую.
+// Файл содержит nonce и закрытый ключ, зашифрованный ключом хранилища vaultKey.
+func LoadOrCreateKeyPair(login string, vaultKey []byte) (*KeyPair, error) {
+	path, err := keyPairFilePath(login)
+	if err != nil {
+		return nil, err
+	}
+	if b, err := os.ReadFile(path); err == nil {
+		if len(b) < nonceLen {
+			return nil, errors.New("invalid key pair file")
+		}
+		return UnwrapKeyPair(b[nonceLen:], b[:nonceLen], vaultKey)
+	}
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	kp, err := wrapKeyPair(priv, vaultKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := SaveKeyPair(login, kp); err != nil {
+		return nil, err
+	}
+	return kp, nil
+}
+
+// SaveKeyPair сохраняет пару ключей (например, восстановленную с сервера) в файл пользователя.
+func SaveKeyPair(login string, kp *KeyPair) error {
+	path, err := keyPairFilePath(login)
+	if err != nil {
+		return err
+	}
+	out := make([]byte, 0, len(kp.Nonce)+len(kp.WrappedPrivate))
+	out = append(append(out, kp.Nonce...), kp.WrappedPrivate...)
+	return os.WriteFile(path, out, 0o600)
+}
+
+// UnwrapKeyPair расшифровывает закрытый ключ X25519 ключом хранилища.
+func UnwrapKeyPair(wrapped, nonce, vaultKey []byte) (*KeyPair, error) {
+	raw, err := Decrypt(wrapped, nonce, vaultKey)
+	if err != nil {
+		return nil, err
+	}
+	priv, err := ecdh.X25519().NewPrivateKey(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyPair{Private: priv, Public: priv.PublicKey().Bytes(), WrappedPrivate: wrapped, Nonce: nonce}, nil
+}
+
+func wrapKeyPair(priv *ecdh.PrivateKey, vaultKey []byte) (*KeyPair, error) {
+	wrapped, nonce, err := Encrypt(priv.Bytes(), vaultKey)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyPair{Private: priv, Public: priv.PublicKey().Bytes(), WrappedPrivate: wrapped, Nonce: nonce}, nil
+}
+
+// NewItemKey создаёт случайный ключ AES‑256 для полей одной общей записи.
+func NewItemKey() ([]byte, error) {
+	key := make([]byte, keyLen)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// SealKey запечатывает key для владельца открытого ключа recipientPub: эфемерный ECDH X25519,
+// HKDF-SHA256 и AES‑GCM. Возвращает эфемерный открытый ключ, шифртекст и nonce.
+func SealKey(recipientPub, key []byte) (ephemeral, wrapped, nonce []byte, err error) {
+	pub, err := ecdh.X25519().NewPublicKey(recipientPub)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	eph, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	secret, err := eph.ECDH(pub)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	kek, err := sharedKey(secret, eph.PublicKey().Bytes(), recipientPub)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	wrapped, nonce, err = Encrypt(key, kek)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return eph.PublicKey().Bytes(), wrapped, nonce, nil
+}
+
+// OpenKey открывает ключ, запечатанный SealKey для пары priv.
+func OpenKey(priv *ecdh.PrivateKey, ephemeral, wrapped, nonce []byte) ([]byte, error) {
+	pub, err := ecdh.X25519().NewPublicKey(ephemeral)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := priv.ECDH(pub)
+	if err != nil {
+		return nil, err
+	}
+	kek, err := sharedKey(secret, ephemeral, priv.PublicKey().Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return Decrypt(wrapped, nonce, kek)
+}
+
+// sharedKey выводит ключ шифрования ключа из общего секрета ECDH;
+// соль — эфемерный открытый ключ и открытый ключ получателя.
+func sharedKey(secret, ephemeral, recipient []byte) ([]byte, error) {
+	salt := make([]byte, 0, len(ephemeral)+len(recipient))
+	salt = append(append(salt, ephemeral...), recipient...)
+	return hkdf.Key(sha256.New, secret, salt, shareInfo, keyLen)
+}
@@ -0,0 +1,72 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLoadOrCreateKeyPair_CreateAndReuse(t *testing.T) {
+	setTempUserEnv(t)
+	vault, err := LoadOrCreateKey("alice")
+	if err != nil {
+		t.Fatalf("vault key: %v", err)
+	}
+	kp1, err := LoadOrCreateKeyPair("alice", vault)
+	if err != nil {
+		t.Fatalf("create key pair: %v", err)
+	}
+	if len(kp1.Public) != 32 {
+		t.Fatalf("public key len want 32, got %d", len(kp1.Public))
+	}
+	kp2, err := LoadOrCreateKeyPair("alice", vault)
+	if err != nil {
+		t.Fatalf("reuse key pair: %v", err)
+	}
+	if !bytes.Equal(kp1.Public, kp2.Public) {
+		t.Fatalf("key pair must be reused")
+	}
+	// опубликованный вид восстанавливается тем же ключом хранилища
+	kp3, err := UnwrapKeyPair(kp1.WrappedPrivate, kp1.Nonce, vault)
+	if err != nil || !bytes.Equal(kp3.Public, kp1.Public) {
+		t.Fatalf("unwrap: %v", err)
+	}
+	// чужим ключом хранилища — нет
+	other := bytes.Repeat([]byte{7}, 32)
+	if _, err := LoadOrCreateKeyPair("alice", other); err == nil {
+		t.Fatalf("expected error for foreign vault key")
+	}
+}
+
+func TestSealOpenKey(t *testing.T) {
+	setTempUserEnv(t)
+	vault, _ := LoadOrCreateKey("bob")
+	bob, err := LoadOrCreateKeyPair("bob", vault)
+	if err != nil {
+		t.Fatalf("key pair: %v", err)
+	}
+	itemKey, err := NewItemKey()
+	if err != nil {
+		t.Fatalf("item key: %v", err)
+	}
+	eph, wrapped, nonce, err := SealKey(bob.Public, itemKey)
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	got, err := OpenKey(bob.Private, eph, wrapped, nonce)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if !bytes.Equal(got, itemKey) {
+		t.Fatalf("opened key differs")
+	}
+
+	// другой получатель открыть не может
+	vault2, _ := LoadOrCreateKey("carol")
+	carol, _ := LoadOrCreateKeyPair("carol", vault2)
+	if _, err := OpenKey(carol.Private, eph, wrapped, nonce); err == nil {
+		t.Fatalf("expected error for wrong recipient")
+	}
+	if _, _, _, err := SealKey([]byte{1, 2, 3}, itemKey); err == nil {
+		t.Fatalf("expected error for invalid public key")
+	}
+}
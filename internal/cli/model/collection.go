@@ -0,0 +1,31 @@
+package model
+
+// Collection — коллекция организации, доступная пользователю. Её ключ запечатан
+// открытым ключом пользователя; записи коллекции зашифрованы этим ключом.
+type Collection struct {
+	ID              string
+	Org             string // имя организации
+	Name            string
+	Role            string // роль пользователя в организации
+	EphemeralKey    []byte
+	WrappedKey      []byte
+	WrappedKeyNonce []byte
+}
+
+// CollectionItem — запись коллекции организации.
+type CollectionItem struct {
+	ID             string
+	CollectionID   string
+	Name           string
+	Version        int64
+	Deleted        bool
+	UpdatedAt      int64
+	LoginCipher    []byte
+	LoginNonce     []byte
+	PasswordCipher []byte
+	PasswordNonce  []byte
+	TextCipher     []byte
+	TextNonce      []byte
+	CardCipher     []byte
+	CardNonce      []byte
+}
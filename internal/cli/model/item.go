@@ -18,4 +18,16 @@ type Item struct {
 	TextNonce      []byte // nonce для текста
 	CardCipher     []byte // шифртекст JSON-объекта с данными карты
 	CardNonce      []byte // nonce для данных карты
+	// BaseVersions — для каждой изменённой локально группы полей (Field*)
+	// серверная версия, от которой началась правка; пусто — локальных правок нет.
+	BaseVersions map[string]int64
 }
+
+// Группы полей записи для слияния при синхронизации (совпадают с серверными).
+const (
+	FieldFile     = "file"
+	FieldLogin    = "login"
+	FieldPassword = "password"
+	FieldText     = "text"
+	FieldCard     = "card"
+)
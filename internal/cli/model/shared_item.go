@@ -0,0 +1,23 @@
+package model
+
+// SharedItem — запись другого пользователя, открытая текущему (только чтение).
+// Поля зашифрованы ключом записи, который запечатан открытым ключом получателя.
+type SharedItem struct {
+	ItemID          string
+	Name            string
+	Owner           string // логин владельца
+	Version         int64  // версия записи, из которой собрана доля
+	ItemVersion     int64  // текущая версия записи у владельца
+	UpdatedAt       int64
+	EphemeralKey    []byte // эфемерный открытый ключ X25519 отправителя
+	WrappedKey      []byte // ключ записи, зашифрованный общим секретом
+	WrappedKeyNonce []byte
+	LoginCipher     []byte
+	LoginNonce      []byte
+	PasswordCipher  []byte
+	PasswordNonce   []byte
+	TextCipher      []byte
+	TextNonce       []byte
+	CardCipher      []byte
+	CardNonce       []byte
+}
@@ -0,0 +1,28 @@
+package repo
+
+import "GophKeeper/internal/cli/model"
+
+// CollectionRepository — локальная копия коллекций организаций пользователя.
+// Реализуется тем же хранилищем, что и ItemRepository.
+type CollectionRepository interface {
+	// ReplaceCollections заменяет все коллекции и их записи снимком с сервера.
+	ReplaceCollections(cols []model.Collection, items []model.CollectionItem) error
+
+	// SaveCollection добавляет или обновляет одну коллекцию (например, только что созданную).
+	SaveCollection(c model.Collection) error
+
+	// ListCollections возвращает коллекции, отсортированные по организации и имени.
+	ListCollections() ([]model.Collection, error)
+
+	// GetCollection находит коллекцию name организации org.
+	GetCollection(org, name string) (*model.Collection, error)
+
+	// SaveCollectionItem добавляет или обновляет запись коллекции.
+	SaveCollectionItem(it model.CollectionItem) error
+
+	// ListCollectionItems возвращает неудалённые записи коллекции по имени.
+	ListCollectionItems(collectionID string) ([]model.CollectionItem, error)
+
+	// GetCollectionItem находит неудалённую запись коллекции по имени.
+	GetCollectionItem(collectionID, name string) (*model.CollectionItem, error)
+}
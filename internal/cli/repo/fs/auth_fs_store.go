@@ -37,9 +37,9 @@ func lastLoginPath() (string, error) {
 	return filepath.Join(dir, "last_login"), nil
 }
 
-func lastSyncAtPath(login string) (string, error) {
+func syncCursorPath(login string) (string, error) {
 	if login == "" {
-		return "", errors.New("empty login for last_sync_at")
+		return "", errors.New("empty login for sync cursor")
 	}
 	dir, err := configDir()
 	if err != nil {
@@ -47,7 +47,7 @@ func lastSyncAtPath(login string) (string, error) {
 	}
 	// Храним per-user, чтобы поддерживать несколько аккаунтов
 	safe := login
-	return filepath.Join(dir, "last_sync_at_"+safe), nil
+	return filepath.Join(dir, "sync_cursor_"+safe), nil
 }
 
 // Save сохраняет auth‑токен в файл.
@@ -121,24 +121,24 @@ func (AuthFSStore) LoadLogin() (string, error) {
 	return string(b), nil
 }
 
-// SaveLastSyncAt сохраняет значение last_sync_at (RFC3339) для указанного пользователя
-func SaveLastSyncAt(login, ts string) error {
+// SaveSyncCursor сохраняет курсор синхронизации (непрозрачная строка от сервера) для указанного пользователя
+func SaveSyncCursor(login, cursor string) error {
 	if login == "" {
 		return errors.New("empty login")
 	}
-	p, err := lastSyncAtPath(login)
+	p, err := syncCursorPath(login)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(p, []byte(ts), 0o600)
+	return os.WriteFile(p, []byte(cursor), 0o600)
 }
 
-// LoadLastSyncAt читает last_sync_at для указанного пользователя
-func LoadLastSyncAt(login string) (string, error) {
+// LoadSyncCursor читает курсор синхронизации для указанного пользователя
+func LoadSyncCursor(login string) (string, error) {
 	if login == "" {
 		return "", errors.New("empty login")
 	}
-	p, err := lastSyncAtPath(login)
+	p, err := syncCursorPath(login)
 	if err != nil {
 		return "", err
 	}
@@ -147,7 +147,7 @@ func LoadLastSyncAt(login string) (string, error) {
 		return "", err
 	}
 	if len(b) == 0 {
-		return "", errors.New("empty last_sync_at file")
+		return "", errors.New("empty sync cursor file")
 	}
 	// trim trailing whitespace
 	for len(b) > 0 {
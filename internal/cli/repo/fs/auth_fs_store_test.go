@@ -80,26 +80,29 @@ func TestAuthFSStore_SaveLogin_EmptyError(t *testing.T) {
 	}
 }
 
-func TestSaveLoadLastSyncAt(t *testing.T) {
+func TestSaveLoadSyncCursor(t *testing.T) {
 	setTempCfg(t)
 	const user = "bob"
 	// пустой логин → ошибка
-	if _, err := LoadLastSyncAt(""); err == nil {
-		t.Fatalf("expected error on empty login for LoadLastSyncAt")
+	if _, err := LoadSyncCursor(""); err == nil {
+		t.Fatalf("expected error on empty login for LoadSyncCursor")
 	}
-	if err := SaveLastSyncAt("", "2024-01-01T00:00:00Z"); err == nil {
-		t.Fatalf("expected error on empty login for SaveLastSyncAt")
+	if err := SaveSyncCursor("", "42"); err == nil {
+		t.Fatalf("expected error on empty login for SaveSyncCursor")
+	}
+	// курсора ещё нет
+	if _, err := LoadSyncCursor(user); err == nil {
+		t.Fatalf("expected error when cursor is absent")
 	}
 	// success
-	ts := "2024-01-01T00:00:00Z\n"
-	if err := SaveLastSyncAt(user, ts); err != nil {
-		t.Fatalf("save last_sync_at: %v", err)
+	if err := SaveSyncCursor(user, "42\n"); err != nil {
+		t.Fatalf("save cursor: %v", err)
 	}
-	got, err := LoadLastSyncAt(user)
+	got, err := LoadSyncCursor(user)
 	if err != nil {
-		t.Fatalf("load last_sync_at: %v", err)
+		t.Fatalf("load cursor: %v", err)
 	}
-	if got != "2024-01-01T00:00:00Z" {
-		t.Fatalf("expected trimmed timestamp, got %q", got)
+	if got != "42" {
+		t.Fatalf("expected trimmed cursor, got %q", got)
 	}
 }
@@ -0,0 +1,70 @@
+package fs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// serverPinPath — файл закреплённого ключа сервера профиля (BASE_URL вида host:port).
+func serverPinPath(profile string) (string, error) {
+	if profile == "" {
+		return "", errors.New("empty profile for server pin")
+	}
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	// ':' недопустим в именах файлов Windows
+	safe := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' {
+			return r
+		}
+		return '_'
+	}, profile)
+	return filepath.Join(dir, "server_pin_"+safe), nil
+}
+
+// SaveServerPin закрепляет отпечаток открытого ключа сервера для профиля.
+func SaveServerPin(profile, pin string) error {
+	if pin == "" {
+		return errors.New("empty server pin")
+	}
+	p, err := serverPinPath(profile)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, []byte(pin), 0o600)
+}
+
+// LoadServerPin читает закреплённый отпечаток; если его нет — ошибка os.ErrNotExist.
+func LoadServerPin(profile string) (string, error) {
+	p, err := serverPinPath(profile)
+	if err != nil {
+		return "", err
+	}
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return "", err
+	}
+	pin := strings.TrimSpace(string(b))
+	if pin == "" {
+		return "", errors.New("empty server pin file")
+	}
+	return pin, nil
+}
+
+// ResetServerPin удаляет закреплённый отпечаток: следующее подключение закрепит ключ заново.
+// Возвращает false, если закреплённого ключа не было.
+func ResetServerPin(profile string) (bool, error) {
+	p, err := serverPinPath(profile)
+	if err != nil {
+		return false, err
+	}
+	err = os.Remove(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,47 @@
+package fs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestServerPin_SaveLoadReset(t *testing.T) {
+	setTempCfg(t)
+	if _, err := LoadServerPin("keeper.local:8081"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("missing pin must be os.ErrNotExist, got %v", err)
+	}
+	if err := SaveServerPin("keeper.local:8081", "sha256/AAA="); err != nil {
+		t.Fatalf("save pin: %v", err)
+	}
+	if err := SaveServerPin("localhost:8081", "sha256/BBB="); err != nil {
+		t.Fatalf("save pin: %v", err)
+	}
+	// профили не пересекаются
+	if pin, err := LoadServerPin("keeper.local:8081"); err != nil || pin != "sha256/AAA=" {
+		t.Fatalf("pin for keeper.local: %q, %v", pin, err)
+	}
+	p, _ := serverPinPath("keeper.local:8081")
+	if filepath.Base(p) != "server_pin_keeper.local_8081" {
+		t.Fatalf("unexpected pin file name %q", filepath.Base(p))
+	}
+
+	removed, err := ResetServerPin("keeper.local:8081")
+	if err != nil || !removed {
+		t.Fatalf("reset: %v, %v", removed, err)
+	}
+	if removed, err = ResetServerPin("keeper.local:8081"); err != nil || removed {
+		t.Fatalf("second reset must report nothing to remove: %v, %v", removed, err)
+	}
+	if pin, err := LoadServerPin("localhost:8081"); err != nil || pin != "sha256/BBB=" {
+		t.Fatalf("other profile must keep its pin: %q, %v", pin, err)
+	}
+
+	if err := SaveServerPin("", "sha256/AAA="); err == nil {
+		t.Fatalf("empty profile must be rejected")
+	}
+	if err := SaveServerPin("localhost:8081", ""); err == nil {
+		t.Fatalf("empty pin must be rejected")
+	}
+}
@@ -38,4 +38,13 @@ type ItemRepository interface {
 
 	// UpsertFullFromServer полностью вставляет/обновляет запись items по снимку с сервера
 	UpsertFullFromServer(it model.Item) error
+
+	// ApplySyncBatch в одной транзакции проставляет серверные версии принятых изменений
+	// (id → версия) и применяет снимки записей с сервера. Записи с неотправленными
+	// локальными правками снимки не заменяют, если их id нет в versions.
+	ApplySyncBatch(versions map[string]int64, items []model.Item) error
+
+	// InsertFull создаёт новую запись со всеми полями it (например, копию при конфликте).
+	// Возвращает ошибку, если запись с таким именем уже есть.
+	InsertFull(it model.Item) error
 }
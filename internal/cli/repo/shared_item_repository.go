@@ -0,0 +1,17 @@
+package repo
+
+import "GophKeeper/internal/cli/model"
+
+// SharedItemRepository — локальная копия записей, открытых пользователю другими.
+// Реализуется тем же хранилищем, что и ItemRepository.
+type SharedItemRepository interface {
+	// ReplaceSharedItems заменяет все общие записи списком, полученным с сервера.
+	ReplaceSharedItems(items []model.SharedItem) error
+
+	// ListSharedItems возвращает общие записи, отсортированные по владельцу и имени.
+	ListSharedItems() ([]model.SharedItem, error)
+
+	// GetSharedItem находит общую запись по имени; owner == "" — у любого владельца,
+	// если имя единственное.
+	GetSharedItem(owner, name string) (*model.SharedItem, error)
+}
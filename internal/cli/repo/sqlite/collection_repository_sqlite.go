@@ -0,0 +1,169 @@
+package sqlite
+
+import (
+	"GophKeeper/internal/cli/model"
+	"GophKeeper/internal/cli/repo"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+var _ repo.CollectionRepository = (*ItemRepositorySQLite)(nil)
+
+const collectionColumns = `id, org, name, role, ephemeral_key, wrapped_key, wrapped_key_nonce`
+
+const collectionItemColumns = `id, collection_id, name, version, deleted, updated_at,
+     login_cipher, login_nonce, password_cipher, password_nonce,
+     text_cipher, text_nonce, card_cipher, card_nonce`
+
+// ReplaceCollections заменяет все коллекции и их записи в одной транзакции.
+func (r *ItemRepositorySQLite) ReplaceCollections(cols []model.Collection, items []model.CollectionItem) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`DELETE FROM collection_items`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM collections`); err != nil {
+		return err
+	}
+	for _, c := range cols {
+		if err := saveCollection(tx, c); err != nil {
+			return err
+		}
+	}
+	for _, it := range items {
+		if err := saveCollectionItem(tx, it); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// SaveCollection добавляет или обновляет коллекцию.
+func (r *ItemRepositorySQLite) SaveCollection(c model.Collection) error {
+	return saveCollection(r.db, c)
+}
+
+// ListCollections возвращает коллекции, отсортированные по организации и имени.
+func (r *ItemRepositorySQLite) ListCollections() ([]model.Collection, error) {
+	rows, err := r.db.Query(`SELECT ` + collectionColumns + ` FROM collections ORDER BY org, name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []model.Collection
+	for rows.Next() {
+		var c model.Collection
+		if err := rows.Scan(&c.ID, &c.Org, &c.Name, &c.Role, &c.EphemeralKey, &c.WrappedKey, &c.WrappedKeyNonce); err != nil {
+			return nil, err
+		}
+		res = append(res, c)
+	}
+	return res, rows.Err()
+}
+
+// GetCollection находит коллекцию name организации org.
+func (r *ItemRepositorySQLite) GetCollection(org, name string) (*model.Collection, error) {
+	var c model.Collection
+	err := r.db.QueryRow(`SELECT `+collectionColumns+` FROM collections WHERE org = ? AND name = ?`, org, name).
+		Scan(&c.ID, &c.Org, &c.Name, &c.Role, &c.EphemeralKey, &c.WrappedKey, &c.WrappedKeyNonce)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("collection %s/%s not found", org, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// SaveCollectionItem добавляет или обновляет запись коллекции.
+func (r *ItemRepositorySQLite) SaveCollectionItem(it model.CollectionItem) error {
+	return saveCollectionItem(r.db, it)
+}
+
+// ListCollectionItems возвращает неудалённые записи коллекции по имени.
+func (r *ItemRepositorySQLite) ListCollectionItems(collectionID string) ([]model.CollectionItem, error) {
+	rows, err := r.db.Query(`SELECT `+collectionItemColumns+` FROM collection_items
+        WHERE collection_id = ? AND deleted = 0 ORDER BY name`, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []model.CollectionItem
+	for rows.Next() {
+		it, err := scanCollectionItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *it)
+	}
+	return res, rows.Err()
+}
+
+// GetCollectionItem находит неудалённую запись коллекции по имени.
+func (r *ItemRepositorySQLite) GetCollectionItem(collectionID, name string) (*model.CollectionItem, error) {
+	rows, err := r.db.Query(`SELECT `+collectionItemColumns+` FROM collection_items
+        WHERE collection_id = ? AND name = ? AND deleted = 0 LIMIT 1`, collectionID, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("collection item %q not found", name)
+	}
+	return scanCollectionItem(rows)
+}
+
+func saveCollection(db execer, c model.Collection) error {
+	if c.ID == "" {
+		return errors.New("empty id")
+	}
+	_, err := db.Exec(`INSERT INTO collections(`+collectionColumns+`) VALUES(?, ?, ?, ?, ?, ?, ?)
+        ON CONFLICT(id) DO UPDATE SET org = excluded.org, name = excluded.name, role = excluded.role,
+        ephemeral_key = excluded.ephemeral_key, wrapped_key = excluded.wrapped_key,
+        wrapped_key_nonce = excluded.wrapped_key_nonce`,
+		c.ID, c.Org, c.Name, c.Role, c.EphemeralKey, c.WrappedKey, c.WrappedKeyNonce)
+	if err != nil {
+		return fmt.Errorf("save collection %s: %w", c.ID, err)
+	}
+	return nil
+}
+
+func saveCollectionItem(db execer, it model.CollectionItem) error {
+	if it.ID == "" {
+		return errors.New("empty id")
+	}
+	deleted := 0
+	if it.Deleted {
+		deleted = 1
+	}
+	_, err := db.Exec(`INSERT OR REPLACE INTO collection_items(`+collectionItemColumns+`)
+        VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		it.ID, it.CollectionID, it.Name, it.Version, deleted, it.UpdatedAt,
+		it.LoginCipher, it.LoginNonce, it.PasswordCipher, it.PasswordNonce,
+		it.TextCipher, it.TextNonce, it.CardCipher, it.CardNonce)
+	if err != nil {
+		return fmt.Errorf("save collection item %s: %w", it.ID, err)
+	}
+	return nil
+}
+
+func scanCollectionItem(rows *sql.Rows) (*model.CollectionItem, error) {
+	var it model.CollectionItem
+	var deleted int
+	err := rows.Scan(&it.ID, &it.CollectionID, &it.Name, &it.Version, &deleted, &it.UpdatedAt,
+		&it.LoginCipher, &it.LoginNonce, &it.PasswordCipher, &it.PasswordNonce,
+		&it.TextCipher, &it.TextNonce, &it.CardCipher, &it.CardNonce)
+	if err != nil {
+		return nil, err
+	}
+	it.Deleted = deleted != 0
+	return &it, nil
+}
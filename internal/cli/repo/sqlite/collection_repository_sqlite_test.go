@@ -0,0 +1,63 @@
+package sqlite
+
+import (
+	"testing"
+
+	cmodel "GophKeeper/internal/cli/model"
+)
+
+func TestCollections_ReplaceSaveGet(t *testing.T) {
+	setTempUserEnv(t)
+	r, _, err := OpenForUser("bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if err := r.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+
+	col := func(id, org, name string) cmodel.Collection {
+		return cmodel.Collection{ID: id, Org: org, Name: name, Role: "member",
+			EphemeralKey: []byte{1}, WrappedKey: []byte{2}, WrappedKeyNonce: []byte{3}}
+	}
+	if err := r.SaveCollection(col("old", "gone", "default")); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	err = r.ReplaceCollections(
+		[]cmodel.Collection{col("c2", "acme", "ops"), col("c1", "acme", "default")},
+		[]cmodel.CollectionItem{
+			{ID: "i1", CollectionID: "c1", Name: "wifi", Version: 2, PasswordCipher: []byte{4}},
+			{ID: "i2", CollectionID: "c1", Name: "db", Version: 1, Deleted: true},
+		})
+	if err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+	list, err := r.ListCollections()
+	if err != nil || len(list) != 2 || list[0].ID != "c1" || list[1].ID != "c2" {
+		t.Fatalf("list: %+v %v", list, err)
+	}
+
+	c, err := r.GetCollection("acme", "default")
+	if err != nil || c.ID != "c1" || string(c.WrappedKey) != "\x02" {
+		t.Fatalf("get: %+v %v", c, err)
+	}
+	if _, err := r.GetCollection("gone", "default"); err == nil {
+		t.Fatal("replaced collection must be gone")
+	}
+
+	items, err := r.ListCollectionItems("c1")
+	if err != nil || len(items) != 1 || items[0].Name != "wifi" {
+		t.Fatalf("deleted items must be hidden: %+v %v", items, err)
+	}
+	if err := r.SaveCollectionItem(cmodel.CollectionItem{ID: "i1", CollectionID: "c1", Name: "wifi", Version: 3, PasswordCipher: []byte{5}}); err != nil {
+		t.Fatalf("save item: %v", err)
+	}
+	it, err := r.GetCollectionItem("c1", "wifi")
+	if err != nil || it.Version != 3 || string(it.PasswordCipher) != "\x05" {
+		t.Fatalf("get item: %+v %v", it, err)
+	}
+	if _, err := r.GetCollectionItem("c1", "db"); err == nil {
+		t.Fatal("deleted item must not be found")
+	}
+}
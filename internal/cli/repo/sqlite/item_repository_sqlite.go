@@ -4,6 +4,7 @@ import (
 	"GophKeeper/internal/cli/model"
 	"GophKeeper/internal/cli/repo"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -59,7 +60,22 @@ func (r *ItemRepositorySQLite) Close() error {
 
 // Migrate гарантирует наличие необходимых таблиц/индексов.
 func (r *ItemRepositorySQLite) Migrate() error {
-	_, err := r.db.Exec(initialDDL())
+	if _, err := r.db.Exec(initialDDL()); err != nil {
+		return err
+	}
+	return r.addColumnIfMissing("items", "field_base_versions", "TEXT")
+}
+
+// addColumnIfMissing добавляет столбец в таблицу БД, созданной прежней версией клиента.
+func (r *ItemRepositorySQLite) addColumnIfMissing(table, column, decl string) error {
+	var n int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&n); err != nil {
+		return err
+	}
+	if n > 0 {
+		return nil
+	}
+	_, err := r.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, decl))
 	return err
 }
 
@@ -122,15 +138,16 @@ func (r *ItemRepositorySQLite) GetItemByName(name string) (*model.Item, error) {
 	}
 	var it model.Item
 	var delInt int
+	var bases sql.NullString
 	err := r.db.QueryRow(`SELECT id, name, created_at, updated_at, version, deleted,
      IFNULL(file_name, ''), IFNULL(blob_id, ''),
      login_cipher, login_nonce, password_cipher, password_nonce,
-     text_cipher, text_nonce, card_cipher, card_nonce
+     text_cipher, text_nonce, card_cipher, card_nonce, field_base_versions
    FROM items WHERE name = ?`, name).
 		Scan(&it.ID, &it.Name, &it.CreatedAt, &it.UpdatedAt, &it.Version, &delInt,
 			&it.FileName, &it.BlobID,
 			&it.LoginCipher, &it.LoginNonce, &it.PasswordCipher, &it.PasswordNonce,
-			&it.TextCipher, &it.TextNonce, &it.CardCipher, &it.CardNonce)
+			&it.TextCipher, &it.TextNonce, &it.CardCipher, &it.CardNonce, &bases)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, fmt.Errorf("item with name %q not found", name)
@@ -138,6 +155,7 @@ func (r *ItemRepositorySQLite) GetItemByName(name string) (*model.Item, error) {
 		return nil, err
 	}
 	it.Deleted = delInt != 0
+	it.BaseVersions = decodeBaseVersions(bases)
 	return &it, nil
 }
 
@@ -167,9 +185,9 @@ func (r *ItemRepositorySQLite) ensureItem(name string) (string, bool, error) {
 	return id, true, nil
 }
 
-// upsertFields обновляет указанные столбцы и увеличивает version/updated_at.
-// Если записи не было — создаёт её и устанавливает поля.
-func (r *ItemRepositorySQLite) upsertFields(name string, cols map[string][]byte) (string, bool, error) {
+// upsertFields обновляет указанные столбцы группы полей field и updated_at,
+// запоминая базовую серверную версию правки. Если записи не было — создаёт её и устанавливает поля.
+func (r *ItemRepositorySQLite) upsertFields(name, field string, cols map[string][]byte) (string, bool, error) {
 	id, created, err := r.ensureItem(name)
 	if err != nil {
 		return "", false, err
@@ -189,18 +207,60 @@ func (r *ItemRepositorySQLite) upsertFields(name string, cols map[string][]byte)
 		args = append(args, val)
 		first = false
 	}
+	bases, err := fieldBaseWith(r.db, id, field)
+	if err != nil {
+		return "", false, err
+	}
 	now := time.Now().Unix()
-	args = append(args, now, id)
-	q := fmt.Sprintf("UPDATE items SET %s, updated_at = ? WHERE id = ?", setParts)
+	args = append(args, bases, now, id)
+	q := fmt.Sprintf("UPDATE items SET %s, field_base_versions = ?, updated_at = ? WHERE id = ?", setParts)
 	if _, err := r.db.Exec(q, args...); err != nil {
 		return "", false, err
 	}
 	return id, created, nil
 }
 
+type rowQuerier interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// fieldBaseWith возвращает базовые версии записи id, дополненные группой field:
+// база фиксируется при первой правке группы и сохраняется до синхронизации.
+func fieldBaseWith(q rowQuerier, id, field string) (string, error) {
+	var version int64
+	var raw sql.NullString
+	if err := q.QueryRow(`SELECT version, field_base_versions FROM items WHERE id = ?`, id).Scan(&version, &raw); err != nil {
+		return "", err
+	}
+	bases := decodeBaseVersions(raw)
+	if bases == nil {
+		bases = map[string]int64{}
+	}
+	if _, ok := bases[field]; !ok {
+		bases[field] = version
+	}
+	b, err := json.Marshal(bases)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// decodeBaseVersions разбирает field_base_versions; повреждённое значение считается пустым.
+func decodeBaseVersions(raw sql.NullString) map[string]int64 {
+	if !raw.Valid || raw.String == "" {
+		return nil
+	}
+	var bases map[string]int64
+	if err := json.Unmarshal([]byte(raw.String), &bases); err != nil || len(bases) == 0 {
+		return nil
+	}
+	return bases
+}
+
 // UpsertLogin устанавливает/обновляет зашифрованный логин для записи name.
 func (r *ItemRepositorySQLite) UpsertLogin(name string, loginCipher, loginNonce []byte) (string, bool, error) {
-	return r.upsertFields(name, map[string][]byte{
+	return r.upsertFields(name, model.FieldLogin, map[string][]byte{
 		"login_cipher": loginCipher,
 		"login_nonce":  loginNonce,
 	})
@@ -208,7 +268,7 @@ func (r *ItemRepositorySQLite) UpsertLogin(name string, loginCipher, loginNonce
 
 // UpsertPassword устанавливает/обновляет зашифрованный пароль для записи name.
 func (r *ItemRepositorySQLite) UpsertPassword(name string, passCipher, passNonce []byte) (string, bool, error) {
-	return r.upsertFields(name, map[string][]byte{
+	return r.upsertFields(name, model.FieldPassword, map[string][]byte{
 		"password_cipher": passCipher,
 		"password_nonce":  passNonce,
 	})
@@ -216,7 +276,7 @@ func (r *ItemRepositorySQLite) UpsertPassword(name string, passCipher, passNonce
 
 // UpsertText устанавливает/обновляет зашифрованный произвольный текст для записи name.
 func (r *ItemRepositorySQLite) UpsertText(name string, textCipher, textNonce []byte) (string, bool, error) {
-	return r.upsertFields(name, map[string][]byte{
+	return r.upsertFields(name, model.FieldText, map[string][]byte{
 		"text_cipher": textCipher,
 		"text_nonce":  textNonce,
 	})
@@ -224,7 +284,7 @@ func (r *ItemRepositorySQLite) UpsertText(name string, textCipher, textNonce []b
 
 // UpsertCard устанавливает/обновляет зашифрованные данные карты (JSON) для записи name.
 func (r *ItemRepositorySQLite) UpsertCard(name string, cardCipher, cardNonce []byte) (string, bool, error) {
-	return r.upsertFields(name, map[string][]byte{
+	return r.upsertFields(name, model.FieldCard, map[string][]byte{
 		"card_cipher": cardCipher,
 		"card_nonce":  cardNonce,
 	})
@@ -251,9 +311,13 @@ func (r *ItemRepositorySQLite) UpsertFile(name, fileName string, blobCipher, blo
 	if _, err := tx.Exec(`INSERT INTO blobs(id, cipher, nonce) VALUES(?, ?, ?)`, blobID, blobCipher, blobNonce); err != nil {
 		return "", false, err
 	}
+	bases, err := fieldBaseWith(tx, id, model.FieldFile)
+	if err != nil {
+		return "", false, err
+	}
 	now := time.Now().Unix()
-	if _, err := tx.Exec(`UPDATE items SET file_name = ?, blob_id = ?, updated_at = ? WHERE id = ?`,
-		fileName, blobID, now, id); err != nil {
+	if _, err := tx.Exec(`UPDATE items SET file_name = ?, blob_id = ?, field_base_versions = ?, updated_at = ? WHERE id = ?`,
+		fileName, blobID, bases, now, id); err != nil {
 		return "", false, err
 	}
 	if err := tx.Commit(); err != nil {
@@ -262,13 +326,21 @@ func (r *ItemRepositorySQLite) UpsertFile(name, fileName string, blobCipher, blo
 	return id, created, nil
 }
 
-// SetServerVersion устанавливает серверную версию для записи по id и обновляет updated_at
+// SetServerVersion устанавливает серверную версию для записи по id и обновляет updated_at.
+// Локальные правки считаются принятыми сервером: базовые версии полей сбрасываются.
 func (r *ItemRepositorySQLite) SetServerVersion(id string, version int64) error {
 	if id == "" {
 		return errors.New("empty id")
 	}
-	now := time.Now().Unix()
-	_, err := r.db.Exec(`UPDATE items SET version = ?, updated_at = ? WHERE id = ?`, version, now, id)
+	return setServerVersion(r.db, id, version, time.Now().Unix())
+}
+
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+func setServerVersion(db execer, id string, version, now int64) error {
+	_, err := db.Exec(`UPDATE items SET version = ?, field_base_versions = NULL, updated_at = ? WHERE id = ?`, version, now, id)
 	return err
 }
 
@@ -289,43 +361,86 @@ func (r *ItemRepositorySQLite) GetBlobByID(id string) (*model.Blob, error) {
 	return &b, nil
 }
 
-// UpsertFullFromServer полностью вставляет/обновляет запись items по снимку с сервера
+// UpsertFullFromServer полностью вставляет/обновляет запись items по снимку с сервера.
+// Локальные правки записи заменяются серверным состоянием (базовые версии сбрасываются).
 func (r *ItemRepositorySQLite) UpsertFullFromServer(it model.Item) error {
+	return r.ApplySyncBatch(map[string]int64{it.ID: it.Version}, []model.Item{it})
+}
+
+// ApplySyncBatch применяет ответ синхронизации в одной транзакции: проставляет серверные
+// версии принятых сервером изменений (versions: id → версия) и сохраняет снимки с сервера.
+// Снимок не заменяет запись с неотправленными локальными правками: они уйдут на сервер
+// со следующей синхронизацией и сольются там по полям. Чтобы снимок заменил такую запись
+// (конфликт разрешён в пользу сервера), её id передаётся и в versions.
+// Либо применяется всё, либо ничего.
+func (r *ItemRepositorySQLite) ApplySyncBatch(versions map[string]int64, items []model.Item) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now().Unix()
+	for id, version := range versions {
+		if id == "" {
+			return errors.New("empty id")
+		}
+		if err := setServerVersion(tx, id, version, now); err != nil {
+			return fmt.Errorf("set server version %s: %w", id, err)
+		}
+	}
+	for _, it := range items {
+		if it.ID == "" {
+			return errors.New("empty id")
+		}
+		if err := upsertFull(tx, it); err != nil {
+			return fmt.Errorf("upsert item %s: %w", it.ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// InsertFull создаёт новую запись со всеми полями it.
+func (r *ItemRepositorySQLite) InsertFull(it model.Item) error {
+	if err := ValidateName(it.Name); err != nil {
+		return err
+	}
 	tx, err := r.db.Begin()
 	if err != nil {
 		return err
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	// Проверим существование по id
 	var exists int
-	if err := tx.QueryRow(`SELECT 1 FROM items WHERE id = ?`, it.ID).Scan(&exists); err != nil {
+	err = tx.QueryRow(`SELECT 1 FROM items WHERE name = ?`, it.Name).Scan(&exists)
+	if err == nil {
+		return fmt.Errorf("item with name %q already exists", it.Name)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if err := insertFull(tx, it); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// upsertFull сохраняет снимок с сервера, кроме записей с локальными правками:
+// новых (version 0) и изменённых после синхронизации (есть базовые версии полей).
+func upsertFull(tx *sql.Tx, it model.Item) error {
+	var version int64
+	var bases sql.NullString
+	if err := tx.QueryRow(`SELECT version, field_base_versions FROM items WHERE id = ?`, it.ID).Scan(&version, &bases); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			// вставка
-			_, ierr := tx.Exec(`INSERT INTO items(
-                id, name, created_at, updated_at, version, deleted,
-                file_name, blob_id,
-                login_cipher, login_nonce,
-                password_cipher, password_nonce,
-                text_cipher, text_nonce,
-                card_cipher, card_nonce
-            ) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-				it.ID, it.Name, it.CreatedAt, it.UpdatedAt, it.Version, boolToInt(it.Deleted),
-				it.FileName, nullIfEmpty(it.BlobID),
-				it.LoginCipher, it.LoginNonce,
-				it.PasswordCipher, it.PasswordNonce,
-				it.TextCipher, it.TextNonce,
-				it.CardCipher, it.CardNonce,
-			)
-			if ierr != nil {
-				return ierr
-			}
-		} else {
-			return err
+			return insertFull(tx, it)
 		}
-	} else {
-		// обновление
-		_, uerr := tx.Exec(`UPDATE items SET
+		return err
+	}
+	if version == 0 || decodeBaseVersions(bases) != nil {
+		return nil
+	}
+	// обновление
+	_, uerr := tx.Exec(`UPDATE items SET
             name = ?,
             created_at = ?,
             updated_at = ?,
@@ -336,26 +451,42 @@ func (r *ItemRepositorySQLite) UpsertFullFromServer(it model.Item) error {
             login_cipher = ?, login_nonce = ?,
             password_cipher = ?, password_nonce = ?,
             text_cipher = ?, text_nonce = ?,
-            card_cipher = ?, card_nonce = ?
+            card_cipher = ?, card_nonce = ?,
+            field_base_versions = NULL
             WHERE id = ?`,
-			it.Name,
-			it.CreatedAt,
-			it.UpdatedAt,
-			it.Version,
-			boolToInt(it.Deleted),
-			it.FileName,
-			nullIfEmpty(it.BlobID),
-			it.LoginCipher, it.LoginNonce,
-			it.PasswordCipher, it.PasswordNonce,
-			it.TextCipher, it.TextNonce,
-			it.CardCipher, it.CardNonce,
-			it.ID,
-		)
-		if uerr != nil {
-			return uerr
-		}
-	}
-	return tx.Commit()
+		it.Name,
+		it.CreatedAt,
+		it.UpdatedAt,
+		it.Version,
+		boolToInt(it.Deleted),
+		it.FileName,
+		nullIfEmpty(it.BlobID),
+		it.LoginCipher, it.LoginNonce,
+		it.PasswordCipher, it.PasswordNonce,
+		it.TextCipher, it.TextNonce,
+		it.CardCipher, it.CardNonce,
+		it.ID,
+	)
+	return uerr
+}
+
+func insertFull(tx *sql.Tx, it model.Item) error {
+	_, err := tx.Exec(`INSERT INTO items(
+        id, name, created_at, updated_at, version, deleted,
+        file_name, blob_id,
+        login_cipher, login_nonce,
+        password_cipher, password_nonce,
+        text_cipher, text_nonce,
+        card_cipher, card_nonce
+    ) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		it.ID, it.Name, it.CreatedAt, it.UpdatedAt, it.Version, boolToInt(it.Deleted),
+		it.FileName, nullIfEmpty(it.BlobID),
+		it.LoginCipher, it.LoginNonce,
+		it.PasswordCipher, it.PasswordNonce,
+		it.TextCipher, it.TextNonce,
+		it.CardCipher, it.CardNonce,
+	)
+	return err
 }
 
 func boolToInt(b bool) int {
@@ -419,3 +419,229 @@ func TestUpsertFile_UpdateExisting_ReturnsCreatedFalse(t *testing.T) {
 		t.Fatalf("expected same id across upserts: %s vs %s", id1, id2)
 	}
 }
+
+func TestApplySyncBatch_InsertAndUpdate(t *testing.T) {
+	setTempUserEnv(t)
+	r, _, err := OpenForUser("batch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if err := r.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+
+	id, _, err := r.UpsertText("old", []byte{1}, []byte{2})
+	if err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+	if err := r.SetServerVersion(id, 3); err != nil {
+		t.Fatalf("set version: %v", err)
+	}
+	page := []cmodel.Item{
+		{ID: id, Name: "old", Version: 4, TextCipher: []byte{7}, TextNonce: []byte{8}},
+		{ID: "srv-new", Name: "new", Version: 2, LoginCipher: []byte{3}, LoginNonce: []byte{4}},
+	}
+	if err := r.ApplySyncBatch(nil, page); err != nil {
+		t.Fatalf("batch: %v", err)
+	}
+
+	old, err := r.GetItemByName("old")
+	if err != nil {
+		t.Fatalf("get old: %v", err)
+	}
+	if old.Version != 4 || !bytes.Equal(old.TextCipher, []byte{7}) {
+		t.Fatalf("existing item not updated: %+v", old)
+	}
+	nw, err := r.GetItemByName("new")
+	if err != nil {
+		t.Fatalf("get new: %v", err)
+	}
+	if nw.ID != "srv-new" || nw.Version != 2 {
+		t.Fatalf("new item not inserted: %+v", nw)
+	}
+
+	// пустая страница — no-op
+	if err := r.ApplySyncBatch(nil, nil); err != nil {
+		t.Fatalf("empty batch: %v", err)
+	}
+}
+
+func TestApplySyncBatch_KeepsLocalEdits(t *testing.T) {
+	setTempUserEnv(t)
+	r, _, err := OpenForUser("dirty")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if err := r.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	fresh, _, err := r.UpsertText("fresh", []byte{1}, []byte{1})
+	if err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+	edited, _, err := r.UpsertText("edited", []byte{2}, []byte{2})
+	if err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+	if err := r.SetServerVersion(edited, 4); err != nil {
+		t.Fatalf("set version: %v", err)
+	}
+	if _, _, err := r.UpsertText("edited", []byte{3}, []byte{3}); err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+
+	// снимки с сервера не затирают неотправленные правки и их базовые версии
+	page := []cmodel.Item{
+		{ID: fresh, Name: "fresh", Version: 2, TextCipher: []byte{9}},
+		{ID: edited, Name: "edited", Version: 5, TextCipher: []byte{9}},
+	}
+	if err := r.ApplySyncBatch(nil, page); err != nil {
+		t.Fatalf("batch: %v", err)
+	}
+	f, _ := r.GetItemByName("fresh")
+	if f.Version != 0 || !bytes.Equal(f.TextCipher, []byte{1}) {
+		t.Fatalf("new local item overwritten: %+v", f)
+	}
+	e, _ := r.GetItemByName("edited")
+	if e.Version != 4 || !bytes.Equal(e.TextCipher, []byte{3}) || e.BaseVersions["text"] != 4 {
+		t.Fatalf("local edit overwritten: %+v", e)
+	}
+
+	// с версией в versions снимок заменяет правки (конфликт разрешён в пользу сервера)
+	if err := r.ApplySyncBatch(map[string]int64{edited: 5}, page[1:]); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	e, _ = r.GetItemByName("edited")
+	if e.Version != 5 || !bytes.Equal(e.TextCipher, []byte{9}) || len(e.BaseVersions) != 0 {
+		t.Fatalf("server snapshot not applied: %+v", e)
+	}
+}
+
+func TestApplySyncBatch_VersionsAndRollback(t *testing.T) {
+	setTempUserEnv(t)
+	r, _, err := OpenForUser("applybatch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if err := r.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	idA, _, err := r.UpsertText("a", []byte{1}, []byte{2})
+	if err != nil {
+		t.Fatalf("prepare: %v", err)
+	}
+
+	// некорректный снимок отменяет всю транзакцию, включая версию «a»
+	bad := []cmodel.Item{{ID: "srv-x", Name: "x", Version: 3}, {Name: "noid", Version: 1}}
+	if err := r.ApplySyncBatch(map[string]int64{idA: 5}, bad); err == nil {
+		t.Fatalf("expected error for item without id")
+	}
+	if _, err := r.GetItemByName("x"); err == nil {
+		t.Fatalf("item from failed batch must be rolled back")
+	}
+	a, err := r.GetItemByName("a")
+	if err != nil {
+		t.Fatalf("get a: %v", err)
+	}
+	if a.Version != 0 {
+		t.Fatalf("version must be rolled back, got %d", a.Version)
+	}
+
+	good := []cmodel.Item{{ID: "srv-y", Name: "c", Version: 2}}
+	if err := r.ApplySyncBatch(map[string]int64{idA: 5}, good); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	a, _ = r.GetItemByName("a")
+	if a.Version != 5 || len(a.BaseVersions) != 0 {
+		t.Fatalf("server version not applied: %+v", a)
+	}
+	if c, err := r.GetItemByName("c"); err != nil || c.Version != 2 {
+		t.Fatalf("server item not applied: %+v, %v", c, err)
+	}
+}
+
+func TestFieldBaseVersions_TrackedUntilSynced(t *testing.T) {
+	setTempUserEnv(t)
+	r, _, err := OpenForUser("bases")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if err := r.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	// повторная миграция не должна падать на уже добавленном столбце
+	if err := r.Migrate(); err != nil {
+		t.Fatalf("migrate twice: %v", err)
+	}
+
+	id, _, err := r.UpsertText("rec", []byte{1}, []byte{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SetServerVersion(id, 3); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := r.GetItemByName("rec")
+	if got.BaseVersions != nil {
+		t.Fatalf("bases must be cleared after sync, got %v", got.BaseVersions)
+	}
+
+	// правки пароля дважды: база фиксируется при первой
+	if _, _, err := r.UpsertPassword("rec", []byte{2}, []byte{2}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.db.Exec(`UPDATE items SET version = 4 WHERE id = ?`, id); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := r.UpsertPassword("rec", []byte{3}, []byte{3}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := r.UpsertFile("rec", "f.bin", []byte{4}, []byte{4}); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = r.GetItemByName("rec")
+	if got.BaseVersions[cmodel.FieldPassword] != 3 || got.BaseVersions[cmodel.FieldFile] != 4 || len(got.BaseVersions) != 2 {
+		t.Fatalf("unexpected bases: %v", got.BaseVersions)
+	}
+
+	// снимок с сервера заменяет локальные правки
+	got.Version = 6
+	if err := r.UpsertFullFromServer(*got); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = r.GetItemByName("rec")
+	if got.BaseVersions != nil {
+		t.Fatalf("bases must be cleared by server snapshot, got %v", got.BaseVersions)
+	}
+}
+
+func TestInsertFull_RejectsDuplicateName(t *testing.T) {
+	setTempUserEnv(t)
+	r, _, err := OpenForUser("insert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if err := r.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	it := cmodel.Item{ID: "c1", Name: "mail.conflict-pc-20260101", TextCipher: []byte{1}, TextNonce: []byte{2}}
+	if err := r.InsertFull(it); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	got, err := r.GetItemByName(it.Name)
+	if err != nil || got.ID != "c1" || got.Version != 0 || !bytes.Equal(got.TextCipher, []byte{1}) {
+		t.Fatalf("unexpected copy: %+v err=%v", got, err)
+	}
+	it.ID = "c2"
+	if err := r.InsertFull(it); err == nil {
+		t.Fatalf("duplicate name must be rejected")
+	}
+	if err := r.InsertFull(cmodel.Item{ID: "c3", Name: "bad name"}); err == nil {
+		t.Fatalf("invalid name must be rejected")
+	}
+}
@@ -27,3 +27,57 @@ CREATE TABLE IF NOT EXISTS items (
 
 CREATE INDEX IF NOT EXISTS idx_items_deleted_updated_at ON items(deleted, updated_at);
 CREATE INDEX IF NOT EXISTS idx_items_name ON items(name);
+
+-- Записи других пользователей, открытые текущему (только чтение)
+CREATE TABLE IF NOT EXISTS shared_items (
+  item_id TEXT PRIMARY KEY,
+  name TEXT NOT NULL,
+  owner TEXT NOT NULL,
+  version INTEGER NOT NULL,
+  item_version INTEGER NOT NULL,
+  updated_at INTEGER NOT NULL,
+  ephemeral_key BLOB NOT NULL,
+  wrapped_key BLOB NOT NULL,
+  wrapped_key_nonce BLOB NOT NULL,
+  login_cipher BLOB,
+  login_nonce BLOB,
+  password_cipher BLOB,
+  password_nonce BLOB,
+  text_cipher BLOB,
+  text_nonce BLOB,
+  card_cipher BLOB,
+  card_nonce BLOB
+);
+
+CREATE INDEX IF NOT EXISTS idx_shared_items_name ON shared_items(name);
+
+CREATE TABLE IF NOT EXISTS collections (
+  id TEXT PRIMARY KEY,
+  org TEXT NOT NULL,
+  name TEXT NOT NULL,
+  role TEXT NOT NULL,
+  ephemeral_key BLOB NOT NULL,
+  wrapped_key BLOB NOT NULL,
+  wrapped_key_nonce BLOB NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_collections_org_name ON collections(org, name);
+
+CREATE TABLE IF NOT EXISTS collection_items (
+  id TEXT PRIMARY KEY,
+  collection_id TEXT NOT NULL,
+  name TEXT NOT NULL,
+  version INTEGER NOT NULL,
+  deleted INTEGER NOT NULL DEFAULT 0,
+  updated_at INTEGER NOT NULL,
+  login_cipher BLOB,
+  login_nonce BLOB,
+  password_cipher BLOB,
+  password_nonce BLOB,
+  text_cipher BLOB,
+  text_nonce BLOB,
+  card_cipher BLOB,
+  card_nonce BLOB
+);
+
+CREATE INDEX IF NOT EXISTS idx_collection_items_collection ON collection_items(collection_id, name);
@@ -0,0 +1,109 @@
+package sqlite
+
+import (
+	"GophKeeper/internal/cli/model"
+	"GophKeeper/internal/cli/repo"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+var _ repo.SharedItemRepository = (*ItemRepositorySQLite)(nil)
+
+const sharedItemColumns = `item_id, name, owner, version, item_version, updated_at,
+     ephemeral_key, wrapped_key, wrapped_key_nonce,
+     login_cipher, login_nonce, password_cipher, password_nonce,
+     text_cipher, text_nonce, card_cipher, card_nonce`
+
+// ReplaceSharedItems заменяет все общие записи в одной транзакции.
+func (r *ItemRepositorySQLite) ReplaceSharedItems(items []model.SharedItem) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`DELETE FROM shared_items`); err != nil {
+		return err
+	}
+	for _, s := range items {
+		if s.ItemID == "" {
+			return errors.New("empty id")
+		}
+		_, err := tx.Exec(`INSERT INTO shared_items(`+sharedItemColumns+`)
+        VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			s.ItemID, s.Name, s.Owner, s.Version, s.ItemVersion, s.UpdatedAt,
+			s.EphemeralKey, s.WrappedKey, s.WrappedKeyNonce,
+			s.LoginCipher, s.LoginNonce, s.PasswordCipher, s.PasswordNonce,
+			s.TextCipher, s.TextNonce, s.CardCipher, s.CardNonce,
+		)
+		if err != nil {
+			return fmt.Errorf("insert shared item %s: %w", s.ItemID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// ListSharedItems возвращает общие записи, отсортированные по владельцу и имени.
+func (r *ItemRepositorySQLite) ListSharedItems() ([]model.SharedItem, error) {
+	rows, err := r.db.Query(`SELECT ` + sharedItemColumns + ` FROM shared_items ORDER BY owner, name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []model.SharedItem
+	for rows.Next() {
+		s, err := scanSharedItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *s)
+	}
+	return res, rows.Err()
+}
+
+// GetSharedItem находит общую запись по имени (и владельцу, если он задан).
+func (r *ItemRepositorySQLite) GetSharedItem(owner, name string) (*model.SharedItem, error) {
+	q := `SELECT ` + sharedItemColumns + ` FROM shared_items WHERE name = ?`
+	args := []any{name}
+	if owner != "" {
+		q += ` AND owner = ?`
+		args = append(args, owner)
+	}
+	rows, err := r.db.Query(q+` LIMIT 2`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var found []*model.SharedItem
+	for rows.Next() {
+		s, err := scanSharedItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("shared item %q not found", name)
+	case 1:
+		return found[0], nil
+	default:
+		return nil, fmt.Errorf("shared item %q is ambiguous: use <owner>/%s", name, name)
+	}
+}
+
+func scanSharedItem(rows *sql.Rows) (*model.SharedItem, error) {
+	var s model.SharedItem
+	err := rows.Scan(&s.ItemID, &s.Name, &s.Owner, &s.Version, &s.ItemVersion, &s.UpdatedAt,
+		&s.EphemeralKey, &s.WrappedKey, &s.WrappedKeyNonce,
+		&s.LoginCipher, &s.LoginNonce, &s.PasswordCipher, &s.PasswordNonce,
+		&s.TextCipher, &s.TextNonce, &s.CardCipher, &s.CardNonce)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
@@ -0,0 +1,58 @@
+package sqlite
+
+import (
+	"testing"
+
+	cmodel "GophKeeper/internal/cli/model"
+)
+
+func TestSharedItems_ReplaceListGet(t *testing.T) {
+	setTempUserEnv(t)
+	r, _, err := OpenForUser("bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if err := r.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+
+	share := func(id, owner, name string) cmodel.SharedItem {
+		return cmodel.SharedItem{ItemID: id, Name: name, Owner: owner, Version: 2, ItemVersion: 3,
+			EphemeralKey: []byte{1}, WrappedKey: []byte{2}, WrappedKeyNonce: []byte{3}, TextCipher: []byte{4}}
+	}
+	if err := r.ReplaceSharedItems([]cmodel.SharedItem{
+		share("i1", "carol", "wifi"), share("i2", "alice", "wifi"), share("i3", "alice", "bank"),
+	}); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+	list, err := r.ListSharedItems()
+	if err != nil || len(list) != 3 {
+		t.Fatalf("list: %v %d", err, len(list))
+	}
+	if list[0].Owner != "alice" || list[0].Name != "bank" || list[2].Owner != "carol" {
+		t.Fatalf("unexpected order: %+v", list)
+	}
+
+	got, err := r.GetSharedItem("", "bank")
+	if err != nil || got.ItemID != "i3" || string(got.TextCipher) != "\x04" || got.ItemVersion != 3 {
+		t.Fatalf("get unique: %+v %v", got, err)
+	}
+	if _, err := r.GetSharedItem("", "wifi"); err == nil {
+		t.Fatalf("expected ambiguity error")
+	}
+	if got, err := r.GetSharedItem("carol", "wifi"); err != nil || got.ItemID != "i1" {
+		t.Fatalf("get by owner: %+v %v", got, err)
+	}
+	if _, err := r.GetSharedItem("", "missing"); err == nil {
+		t.Fatalf("expected not found")
+	}
+
+	// повторная замена удаляет отозванные
+	if err := r.ReplaceSharedItems([]cmodel.SharedItem{share("i3", "alice", "bank")}); err != nil {
+		t.Fatalf("replace again: %v", err)
+	}
+	if list, _ := r.ListSharedItems(); len(list) != 1 {
+		t.Fatalf("revoked shares must be removed, got %d", len(list))
+	}
+}
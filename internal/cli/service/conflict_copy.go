@@ -0,0 +1,69 @@
+package service
+
+import (
+	crepo "GophKeeper/internal/cli/repo"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxConflictCopies ограничивает перебор суффиксов имени копии.
+const maxConflictCopies = 100
+
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// KeepBothCopy сохраняет локальную версию записи name как новую запись
+// `<name>.conflict-<device>-<YYYYMMDD>` (новый UUID, version 0, та же ссылка на блоб),
+// чтобы исходная запись могла принять версию сервера без потери данных. Неотправленные
+// правки записи к этому моменту на месте: снимки server_changes их не затирают.
+// Копия отправится на сервер при следующей синхронизации. Возвращает имя копии.
+func KeepBothCopy(r crepo.ItemRepository, name string, now time.Time) (string, error) {
+	it, err := r.GetItemByName(name)
+	if err != nil {
+		return "", err
+	}
+	base := conflictCopyName(name, deviceName(), now)
+	for i := 1; i <= maxConflictCopies; i++ {
+		cp := *it
+		cp.ID = uuid.NewString()
+		cp.Name = base
+		if i > 1 {
+			cp.Name = fmt.Sprintf("%s-%d", base, i)
+		}
+		cp.Version = 0
+		cp.CreatedAt = now.Unix()
+		cp.UpdatedAt = now.Unix()
+		cp.BaseVersions = nil
+		if _, err := r.GetItemByName(cp.Name); err == nil {
+			continue
+		}
+		if err := r.InsertFull(cp); err != nil {
+			return "", fmt.Errorf("create conflict copy: %w", err)
+		}
+		return cp.Name, nil
+	}
+	return "", fmt.Errorf("too many conflict copies of %q", name)
+}
+
+// conflictCopyName формирует имя копии конфликтующей записи.
+func conflictCopyName(name, device string, now time.Time) string {
+	return fmt.Sprintf("%s.conflict-%s-%s", name, device, now.Format("20060102"))
+}
+
+// deviceName — имя устройства для копий конфликтующих записей (из hostname),
+// приведённое к допустимым в имени записи символам.
+func deviceName() string {
+	host, err := os.Hostname()
+	if err != nil {
+		return "device"
+	}
+	host = strings.Trim(unsafeNameChars.ReplaceAllString(host, "-"), "-")
+	if host == "" {
+		return "device"
+	}
+	return host
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"GophKeeper/internal/cli/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestConflictCopyName(t *testing.T) {
+	now := time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, "mail.conflict-laptop-20260304", conflictCopyName("mail", "laptop", now))
+	assert.Regexp(t, regexp.MustCompile(`^[A-Za-z0-9_-]+$`), deviceName())
+}
+
+func TestKeepBothCopy(t *testing.T) {
+	now := time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)
+	base := conflictCopyName("mail", deviceName(), now)
+	orig := &model.Item{ID: "orig", Name: "mail", Version: 7, BlobID: "B1", LoginCipher: []byte{1},
+		BaseVersions: map[string]int64{model.FieldLogin: 6}}
+
+	r := new(syncMockRepo)
+	r.On("GetItemByName", "mail").Return(orig, nil).Once()
+	// первое имя занято копией прошлого конфликта — берём следующий суффикс
+	r.On("GetItemByName", base).Return(&model.Item{ID: "old-copy"}, nil).Once()
+	r.On("GetItemByName", base+"-2").Return(nil, errors.New("not found")).Once()
+	r.On("InsertFull", mock.MatchedBy(func(it model.Item) bool {
+		return it.ID != "orig" && it.Name == base+"-2" && it.Version == 0 &&
+			it.BlobID == "B1" && len(it.LoginCipher) == 1 && it.BaseVersions == nil
+	})).Return(nil).Once()
+
+	name, err := KeepBothCopy(r, "mail", now)
+	assert.NoError(t, err)
+	assert.Equal(t, base+"-2", name)
+	r.AssertExpectations(t)
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"GophKeeper/internal/cli/api"
+	"GophKeeper/internal/cli/crypto"
+	view "GophKeeper/internal/cli/model/view"
+	"GophKeeper/internal/config"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultEmergencyWaitDays — период ожидания экстренного доступа по умолчанию.
+const DefaultEmergencyWaitDays = 7
+
+// emergencyGrantRequest — тело PUT /api/emergency/contacts/{login}.
+type emergencyGrantRequest struct {
+	WaitDays int `json:"wait_days"`
+	sealedKey
+}
+
+// emergencyVaultView — ответ GET /api/emergency/grants/{owner}/vault.
+type emergencyVaultView struct {
+	Owner string `json:"owner"`
+	sealedKey
+	Items []HistoryVersion `json:"items"`
+}
+
+// EmergencyAccess — экстренный доступ: Login — контакт (в списке владельца)
+// или владелец (в списке контакта).
+type EmergencyAccess struct {
+	Login       string `json:"login"`
+	Status      string `json:"status"`
+	WaitDays    int    `json:"wait_days"`
+	RequestedAt string `json:"requested_at,omitempty"`
+	AvailableAt string `json:"available_at,omitempty"`
+}
+
+// GrantEmergency назначает пользователя login экстренным контактом: ключ хранилища
+// запечатывается его открытым ключом и будет выдан ему, если владелец не отклонит
+// запрос доступа за waitDays дней.
+func GrantEmergency(cfg *config.Config, login string, waitDays int) error {
+	s, err := newShareSession(cfg)
+	if err != nil {
+		return err
+	}
+	if _, err := s.publishKeys(cfg); err != nil {
+		return fmt.Errorf("publish keys: %w", err)
+	}
+	pub, err := s.publicKey(cfg, login)
+	if err != nil {
+		return err
+	}
+	eph, wrapped, nonce, err := crypto.SealKey(pub, s.vault)
+	if err != nil {
+		return fmt.Errorf("seal vault key: %w", err)
+	}
+	req := emergencyGrantRequest{WaitDays: waitDays,
+		sealedKey: sealedKey{EphemeralKey: eph, WrappedKey: wrapped, WrappedKeyNonce: nonce}}
+	resp, body, err := api.SendJSON(http.MethodPut, emergencyContactURL(cfg, login, ""), req, s.token)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusNoContent {
+		return emergencyStatusError(resp, body)
+	}
+	return nil
+}
+
+// RevokeEmergency отзывает экстренный доступ контакта login.
+func RevokeEmergency(cfg *config.Config, login string) error {
+	return emergencyContactAction(cfg, http.MethodDelete, login, "")
+}
+
+// ApproveEmergency открывает контакту login доступ, не дожидаясь конца периода ожидания.
+func ApproveEmergency(cfg *config.Config, login string) error {
+	return emergencyContactAction(cfg, http.MethodPost, login, "/approve")
+}
+
+// RejectEmergency отклоняет запрос контакта login; он сможет запросить доступ снова.
+func RejectEmergency(cfg *config.Config, login string) error {
+	return emergencyContactAction(cfg, http.MethodPost, login, "/reject")
+}
+
+// RequestEmergency запрашивает экстренный доступ к хранилищу владельца owner.
+func RequestEmergency(cfg *config.Config, owner string) (*EmergencyAccess, error) {
+	s, err := newShareSession(cfg)
+	if err != nil {
+		return nil, err
+	}
+	resp, body, err := api.SendJSON(http.MethodPost, emergencyGrantURL(cfg, owner, "/request"), nil, s.token)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, emergencyStatusError(resp, body)
+	}
+	var out EmergencyAccess
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("decode emergency access: %w", err)
+	}
+	return &out, nil
+}
+
+// EmergencyList возвращает экстренные контакты пользователя и владельцев,
+// назначивших контактом его самого.
+func EmergencyList(cfg *config.Config) (contacts, grants []EmergencyAccess, err error) {
+	s, err := newShareSession(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	if contacts, err = s.emergencyList(cfg, "/api/emergency/contacts"); err != nil {
+		return nil, nil, err
+	}
+	if grants, err = s.emergencyList(cfg, "/api/emergency/grants"); err != nil {
+		return nil, nil, err
+	}
+	return contacts, grants, nil
+}
+
+// EmergencyVault загружает записи владельца owner и расшифровывает их ключом его
+// хранилища, открытым закрытым ключом пользователя. Записи только для чтения
+// и локально не сохраняются.
+func EmergencyVault(cfg *config.Config, owner string) ([]view.DecryptedItem, error) {
+	s, err := newShareSession(cfg)
+	if err != nil {
+		return nil, err
+	}
+	kp, err := s.publishKeys(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("publish keys: %w", err)
+	}
+	resp, body, err := api.GetJSON(emergencyGrantURL(cfg, owner, "/vault"), s.token)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusForbidden {
+		return nil, fmt.Errorf("доступ к хранилищу %s ещё не открыт: запросите его (emergency request) и дождитесь "+
+			"одобрения владельца или конца периода ожидания", owner)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, emergencyStatusError(resp, body)
+	}
+	var v emergencyVaultView
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil, fmt.Errorf("decode vault: %w", err)
+	}
+	key, err := crypto.OpenKey(kp.Private, v.EphemeralKey, v.WrappedKey, v.WrappedKeyNonce)
+	if err != nil {
+		return nil, fmt.Errorf("open vault key of %s: %w", owner, err)
+	}
+	out := make([]view.DecryptedItem, 0, len(v.Items))
+	for _, it := range v.Items {
+		d := view.DecryptedItem{
+			ID:       it.ID,
+			Name:     it.Name,
+			Version:  it.Version,
+			Login:    decryptField(it.LoginCipher, it.LoginNonce, key),
+			Password: decryptField(it.PasswordCipher, it.PasswordNonce, key),
+			Text:     decryptField(it.TextCipher, it.TextNonce, key),
+			Card:     decryptField(it.CardCipher, it.CardNonce, key),
+			FileName: "<not set>",
+		}
+		if it.FileName != "" {
+			d.FileName = it.FileName + " (содержимое недоступно)"
+		}
+		if t, err := time.Parse(time.RFC3339, it.UpdatedAt); err == nil {
+			d.UpdatedAt = t.Unix()
+		}
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+func (s *shareSession) emergencyList(cfg *config.Config, path string) ([]EmergencyAccess, error) {
+	resp, body, err := api.GetJSON(apiURL(cfg, path), s.token)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, emergencyStatusError(resp, body)
+	}
+	var out []EmergencyAccess
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("decode emergency access: %w", err)
+	}
+	return out, nil
+}
+
+// emergencyContactAction выполняет действие владельца над контактом login.
+func emergencyContactAction(cfg *config.Config, method, login, suffix string) error {
+	s, err := newShareSession(cfg)
+	if err != nil {
+		return err
+	}
+	resp, body, err := api.SendJSON(method, emergencyContactURL(cfg, login, suffix), nil, s.token)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusNoContent {
+		return emergencyStatusError(resp, body)
+	}
+	return nil
+}
+
+func emergencyContactURL(cfg *config.Config, login, suffix string) string {
+	return apiURL(cfg, "/api/emergency/contacts/"+url.PathEscape(login)+suffix)
+}
+
+func emergencyGrantURL(cfg *config.Config, owner, suffix string) string {
+	return apiURL(cfg, "/api/emergency/grants/"+url.PathEscape(owner)+suffix)
+}
+
+// emergencyStatusError переводит ответ сервера об экстренном доступе в ошибку для пользователя.
+func emergencyStatusError(resp *http.Response, body []byte) error {
+	msg := strings.TrimSpace(string(body))
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return fmt.Errorf("экстренный доступ не найден: %s", msg)
+	case http.StatusConflict:
+		return fmt.Errorf("сервер отклонил изменение: %s", msg)
+	}
+	return api.StatusError(resp, body)
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"GophKeeper/internal/cli/crypto"
+	"GophKeeper/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEmergencyServer — имитация эндпоинтов экстренного доступа поверх fakeShareServer:
+// период ожидания не идёт, доступ открывает только одобрение владельца.
+type fakeEmergencyServer struct {
+	mu     sync.Mutex
+	access map[string]EmergencyAccess // owner/contact
+	keys   map[string]sealedKey       // owner/contact
+	items  map[string][]HistoryVersion
+}
+
+func newFakeEmergencyServer(t *testing.T) (*fakeEmergencyServer, *httptest.Server) {
+	t.Helper()
+	_, share := newFakeShareServer(t)
+	f := &fakeEmergencyServer{
+		access: map[string]EmergencyAccess{},
+		keys:   map[string]sealedKey{},
+		items:  map[string][]HistoryVersion{},
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/", share.Config.Handler)
+	user := func(r *http.Request) string {
+		c, _ := r.Cookie("auth_token")
+		return strings.TrimPrefix(c.Value, "tok-")
+	}
+	mux.HandleFunc("PUT /api/emergency/contacts/{login}", func(w http.ResponseWriter, r *http.Request) {
+		var req emergencyGrantRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		key := user(r) + "/" + r.PathValue("login")
+		f.access[key] = EmergencyAccess{Status: "granted", WaitDays: req.WaitDays}
+		f.keys[key] = req.sealedKey
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("DELETE /api/emergency/contacts/{login}", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		delete(f.access, user(r)+"/"+r.PathValue("login"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	setStatus := func(key, from, to string, w http.ResponseWriter) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		a, ok := f.access[key]
+		switch {
+		case !ok:
+			http.Error(w, "emergency access not found", http.StatusNotFound)
+		case from != "" && a.Status != from:
+			http.Error(w, "emergency access was not requested", http.StatusConflict)
+		default:
+			a.Status = to
+			f.access[key] = a
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+	mux.HandleFunc("POST /api/emergency/contacts/{login}/approve", func(w http.ResponseWriter, r *http.Request) {
+		setStatus(user(r)+"/"+r.PathValue("login"), "requested", "approved", w)
+	})
+	mux.HandleFunc("POST /api/emergency/contacts/{login}/reject", func(w http.ResponseWriter, r *http.Request) {
+		setStatus(user(r)+"/"+r.PathValue("login"), "", "granted", w)
+	})
+	mux.HandleFunc("GET /api/emergency/contacts", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(f.list(user(r), true))
+	})
+	mux.HandleFunc("GET /api/emergency/grants", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(f.list(user(r), false))
+	})
+	mux.HandleFunc("POST /api/emergency/grants/{owner}/request", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		key := r.PathValue("owner") + "/" + user(r)
+		a, ok := f.access[key]
+		if !ok {
+			http.Error(w, "emergency access not found", http.StatusNotFound)
+			return
+		}
+		if a.Status == "granted" {
+			a.Status, a.RequestedAt, a.AvailableAt = "requested", "2026-01-01T00:00:00Z", "2026-01-08T00:00:00Z"
+			f.access[key] = a
+		}
+		a.Login = r.PathValue("owner")
+		_ = json.NewEncoder(w).Encode(a)
+	})
+	mux.HandleFunc("GET /api/emergency/grants/{owner}/vault", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		key := r.PathValue("owner") + "/" + user(r)
+		a, ok := f.access[key]
+		if !ok {
+			http.Error(w, "emergency access not found", http.StatusNotFound)
+			return
+		}
+		if a.Status != "approved" {
+			http.Error(w, "emergency access is pending", http.StatusForbidden)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(emergencyVaultView{Owner: r.PathValue("owner"), sealedKey: f.keys[key],
+			Items: f.items[r.PathValue("owner")]})
+	})
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return f, ts
+}
+
+// list возвращает доступы пользователя login как владельца (owner=true) или как контакта.
+func (f *fakeEmergencyServer) list(login string, owner bool) []EmergencyAccess {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := []EmergencyAccess{}
+	for key, a := range f.access {
+		o, c, _ := strings.Cut(key, "/")
+		switch {
+		case owner && o == login:
+			a.Login = c
+		case !owner && c == login:
+			a.Login = o
+		default:
+			continue
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+func TestEmergency_GrantRequestApproveView(t *testing.T) {
+	setupUserEnv(t)
+	f, ts := newFakeEmergencyServer(t)
+	cfg := &config.Config{ServerURL: ts.URL}
+
+	useAccount(t, "bob")
+	_, err := PublishKeys(cfg)
+	require.NoError(t, err)
+
+	useAccount(t, "alice")
+	assert.ErrorContains(t, GrantEmergency(cfg, "carol", 3), "carol")
+	require.NoError(t, GrantEmergency(cfg, "bob", 3))
+	vault, err := crypto.LoadOrCreateKey("alice")
+	require.NoError(t, err)
+	cipher, nonce, err := crypto.Encrypt([]byte("s3cret"), vault)
+	require.NoError(t, err)
+	f.items["alice"] = []HistoryVersion{
+		{ID: "i1", Name: "wifi", Version: 2, PasswordCipher: cipher, PasswordNonce: nonce, UpdatedAt: "2026-01-02T00:00:00Z"},
+		{ID: "i2", Name: "scan", Version: 1, FileName: "passport.pdf"},
+	}
+	assert.ErrorContains(t, ApproveEmergency(cfg, "bob"), "сервер отклонил")
+
+	useAccount(t, "bob")
+	_, err = EmergencyVault(cfg, "alice")
+	assert.ErrorContains(t, err, "ещё не открыт")
+	a, err := RequestEmergency(cfg, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, EmergencyAccess{Login: "alice", Status: "requested", WaitDays: 3,
+		RequestedAt: "2026-01-01T00:00:00Z", AvailableAt: "2026-01-08T00:00:00Z"}, *a)
+	_, grants, err := EmergencyList(cfg)
+	require.NoError(t, err)
+	require.Len(t, grants, 1)
+	assert.Equal(t, "alice", grants[0].Login)
+
+	useAccount(t, "alice")
+	contacts, _, err := EmergencyList(cfg)
+	require.NoError(t, err)
+	require.Len(t, contacts, 1)
+	assert.Equal(t, "requested", contacts[0].Status)
+	require.NoError(t, ApproveEmergency(cfg, "bob"))
+
+	// bob открывает ключ хранилища alice своим закрытым ключом
+	useAccount(t, "bob")
+	items, err := EmergencyVault(cfg, "alice")
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, "s3cret", items[0].Password)
+	assert.Equal(t, "<not set>", items[0].Login)
+	assert.Equal(t, int64(1767312000), items[0].UpdatedAt)
+	assert.Equal(t, "passport.pdf (содержимое недоступно)", items[1].FileName)
+
+	useAccount(t, "alice")
+	require.NoError(t, RevokeEmergency(cfg, "bob"))
+	useAccount(t, "bob")
+	_, err = EmergencyVault(cfg, "alice")
+	assert.ErrorContains(t, err, "не найден")
+}
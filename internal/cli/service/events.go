@@ -0,0 +1,107 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"GophKeeper/internal/cli/api"
+	fsrepo "GophKeeper/internal/cli/repo/fs"
+	"GophKeeper/internal/config"
+)
+
+// ErrEventsUnauthorized — сервер отклонил подписку на события: нужен повторный login.
+var ErrEventsUnauthorized = errors.New("events: unauthorized")
+
+// ChangeEvent — событие change из потока GET /api/events.
+type ChangeEvent struct {
+	Cursor  string   `json:"cursor"`   // курсор синхронизации после изменения ("" — неизвестен)
+	ItemIDs []string `json:"item_ids"` // изменённые записи
+}
+
+// WatchEvents подписывается на поток событий сервера и вызывает onEvent для каждого
+// события change, пока соединение открыто. onConnect вызывается сразу после подключения —
+// например, чтобы догнать изменения, пропущенные без соединения.
+// Возвращает nil при отмене ctx, иначе — причину разрыва.
+func WatchEvents(ctx context.Context, cfg *config.Config, onConnect func(), onEvent func(ChangeEvent)) error {
+	if cfg == nil || cfg.ServerURL == "" {
+		return errors.New("server url is not configured")
+	}
+	token, err := (fsrepo.AuthFSStore{}).Load()
+	if err != nil {
+		return fmt.Errorf("нет токена авторизации: %w", err)
+	}
+	resp, err := api.OpenStream(ctx, cfg.ServerURL+"/api/events", "text/event-stream", token)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return ErrEventsUnauthorized
+	case resp.StatusCode != http.StatusOK:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return api.StatusError(resp, body)
+	}
+
+	if onConnect != nil {
+		onConnect()
+	}
+	err = readSSE(resp.Body, func(event, data string) {
+		if event != "change" {
+			return
+		}
+		var ev ChangeEvent
+		if json.Unmarshal([]byte(data), &ev) == nil {
+			onEvent(ev)
+		}
+	})
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err == nil {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// readSSE разбирает поток text/event-stream и вызывает dispatch для каждого события.
+// Комментарии (строки с ':') и поле id пропускаются; событие без поля event — "message".
+func readSSE(r io.Reader, dispatch func(event, data string)) error {
+	sc := bufio.NewScanner(r)
+	event := ""
+	var data []string
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" {
+			if len(data) > 0 {
+				if event == "" {
+					event = "message"
+				}
+				dispatch(event, strings.Join(data, "\n"))
+			}
+			event, data = "", nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	return sc.Err()
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"GophKeeper/internal/cli/model"
+	"GophKeeper/internal/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadSSE_ParsesEventsAndSkipsComments(t *testing.T) {
+	stream := ": connected\n\n" +
+		"id: 7\nevent: change\ndata: {\"cursor\":\"7\",\n" +
+		"data: \"item_ids\":[\"a\"]}\n\n" +
+		": ping\n\n" +
+		"data: plain\n\n"
+	type got struct{ event, data string }
+	var events []got
+	err := readSSE(strings.NewReader(stream), func(event, data string) {
+		events = append(events, got{event, data})
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []got{
+		{"change", "{\"cursor\":\"7\",\n\"item_ids\":[\"a\"]}"},
+		{"message", "plain"},
+	}, events)
+}
+
+func TestWatchEvents_DeliversChangeEvents(t *testing.T) {
+	setupUserEnv(t)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/events", r.URL.Path)
+		assert.Equal(t, "text/event-stream", r.Header.Get("Accept"))
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(w, ": connected\n\nid: 3\nevent: change\ndata: {\"cursor\":\"3\",\"item_ids\":[\"x\"]}\n\n")
+	}))
+	defer ts.Close()
+
+	connected := false
+	var events []ChangeEvent
+	err := WatchEvents(t.Context(), &config.Config{ServerURL: ts.URL}, func() { connected = true }, func(ev ChangeEvent) {
+		events = append(events, ev)
+	})
+	// сервер закрыл поток — это разрыв соединения, а не штатное завершение
+	assert.Error(t, err)
+	assert.True(t, connected)
+	assert.Equal(t, []ChangeEvent{{Cursor: "3", ItemIDs: []string{"x"}}}, events)
+}
+
+func TestWatchEvents_Unauthorized(t *testing.T) {
+	setupUserEnv(t)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+
+	err := WatchEvents(t.Context(), &config.Config{ServerURL: ts.URL}, nil, func(ChangeEvent) {
+		t.Fatal("unexpected event")
+	})
+	assert.ErrorIs(t, err, ErrEventsUnauthorized)
+}
+
+func TestWatchEvents_ContextCancelIsNotError(t *testing.T) {
+	setupUserEnv(t)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(w, ": connected\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	err := WatchEvents(ctx, &config.Config{ServerURL: ts.URL}, func() {
+		time.AfterFunc(10*time.Millisecond, cancel)
+	}, func(ChangeEvent) {})
+	assert.NoError(t, err)
+}
+
+func TestRunSyncBatch_ChangedOnly_SendsOnlyLocalEdits(t *testing.T) {
+	setupUserEnv(t)
+	var sent []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Changes []struct {
+				ID string `json:"id"`
+			} `json:"changes"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		for _, c := range req.Changes {
+			sent = append(sent, c.ID)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"server_time": time.Now().UTC().Format(time.RFC3339)})
+	}))
+	defer ts.Close()
+
+	r := new(syncMockRepo)
+	r.On("ListItems").Return([]model.Item{{ID: "new", Name: "N"}, {ID: "edited", Name: "E"}, {ID: "clean", Name: "C"}}, nil).Once()
+	r.On("GetItemByName", "N").Return(&model.Item{ID: "new", Name: "N"}, nil).Once()
+	r.On("GetItemByName", "E").Return(&model.Item{ID: "edited", Name: "E", Version: 2, BaseVersions: map[string]int64{model.FieldLogin: 2}}, nil).Once()
+	r.On("GetItemByName", "C").Return(&model.Item{ID: "clean", Name: "C", Version: 5}, nil).Once()
+
+	res := RunSyncBatch(t.Context(), &config.Config{ServerURL: ts.URL}, r, BatchSyncOptions{ChangedOnly: true})
+	assert.NoError(t, res.Err)
+	assert.Equal(t, []string{"new", "edited"}, sent)
+	r.AssertExpectations(t)
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"GophKeeper/internal/cli/api"
+	"GophKeeper/internal/cli/model"
+	"GophKeeper/internal/config"
+	"GophKeeper/internal/pb"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// grpcBlobChunkSize — размер части блоба при загрузке через gRPC.
+const grpcBlobChunkSize = 64 * 1024
+
+var (
+	// ErrInvalidCredentials — сервер отклонил логин или пароль.
+	ErrInvalidCredentials = errors.New("invalid login or password")
+	// ErrLoginTaken — логин уже занят.
+	ErrLoginTaken = errors.New("login already in use")
+)
+
+// useGRPC сообщает, выбран ли транспорт gRPC.
+func useGRPC(cfg *config.Config) bool {
+	return cfg != nil && cfg.Transport == config.TransportGRPC
+}
+
+// grpcTLS — TLS для gRPC при ENABLE_HTTPS (сервер шифрует оба API одним сертификатом);
+// сертификат устройства, заданный через api.ConfigureTLS, предъявляется и здесь.
+func grpcTLS(cfg *config.Config) *tls.Config {
+	if !cfg.EnableHTTPS {
+		return nil
+	}
+	return api.ClientTLS()
+}
+
+// syncPages запрашивает страницу синхронизации. Для HTTP каждая страница — отдельный
+// POST /api/items/sync; для gRPC первый вызов открывает поток Sync, а следующие
+// читают из него очередную страницу (сервер сам запрашивает их с новым курсором).
+type syncPages func(payload syncRequest) (*syncResponse, error)
+
+// openSyncPages выбирает транспорт синхронизации согласно cfg.Transport.
+// Возвращённую функцию закрытия нужно вызвать после чтения страниц.
+func openSyncPages(ctx context.Context, cfg *config.Config, token string) (syncPages, func(), error) {
+	if !useGRPC(cfg) {
+		url := cfg.ServerURL + "/api/items/sync"
+		return func(payload syncRequest) (*syncResponse, error) {
+			return postSyncPage(url, payload, token)
+		}, func() {}, nil
+	}
+	client, closeConn, err := api.DialGRPC(cfg.GRPCAddress, grpcTLS(cfg))
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx, cancel := context.WithCancel(api.WithToken(ctx, token))
+	var stream pb.GophKeeper_SyncClient
+	next := func(payload syncRequest) (*syncResponse, error) {
+		if stream == nil {
+			s, err := client.Sync(ctx, syncRequestToPB(payload))
+			if err != nil {
+				return nil, grpcError(err)
+			}
+			stream = s
+		}
+		page, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("grpc sync: stream closed before has_more=false")
+		}
+		if err != nil {
+			return nil, grpcError(err)
+		}
+		return syncResponseFromPB(page), nil
+	}
+	return next, func() { cancel(); _ = closeConn() }, nil
+}
+
+// AuthGRPC выполняет Login (или Register при register=true) через gRPC и возвращает токен.
+func AuthGRPC(ctx context.Context, cfg *config.Config, login, password string, register bool) (string, error) {
+	client, closeConn, err := api.DialGRPC(cfg.GRPCAddress, grpcTLS(cfg))
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = closeConn() }()
+	creds := &pb.Credentials{Login: login, Password: password}
+	var resp *pb.AuthResponse
+	if register {
+		resp, err = client.Register(ctx, creds)
+	} else {
+		resp, err = client.Login(ctx, creds)
+	}
+	switch status.Code(err) {
+	case codes.OK:
+		return resp.GetToken(), nil
+	case codes.Unauthenticated:
+		return "", ErrInvalidCredentials
+	case codes.AlreadyExists:
+		return "", ErrLoginTaken
+	default:
+		return "", grpcError(err)
+	}
+}
+
+// uploadBlobGRPC загружает блоб потоком частей; возвращает true, если блоб создан впервые.
+func uploadBlobGRPC(ctx context.Context, cfg *config.Config, token string, b *model.Blob) (bool, error) {
+	client, closeConn, err := api.DialGRPC(cfg.GRPCAddress, grpcTLS(cfg))
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = closeConn() }()
+	stream, err := client.UploadBlob(api.WithToken(ctx, token))
+	if err != nil {
+		return false, grpcError(err)
+	}
+	data := b.Cipher
+	chunk := &pb.BlobChunk{Id: b.ID, Nonce: b.Nonce, Size: int64(len(data))}
+	for {
+		n := min(len(data), grpcBlobChunkSize)
+		chunk.Data = data[:n]
+		// ошибку отправки вернёт CloseAndRecv со статусом сервера
+		if err := stream.Send(chunk); err != nil {
+			break
+		}
+		data = data[n:]
+		if len(data) == 0 {
+			break
+		}
+		chunk = &pb.BlobChunk{}
+	}
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		return false, fmt.Errorf("upload failed: %w", grpcError(err))
+	}
+	return res.GetCreated(), nil
+}
+
+// getItemGRPC загружает полный снимок записи id через GetItem.
+func getItemGRPC(ctx context.Context, cfg *config.Config, token, id string) (HistoryVersion, error) {
+	client, closeConn, err := api.DialGRPC(cfg.GRPCAddress, grpcTLS(cfg))
+	if err != nil {
+		return HistoryVersion{}, err
+	}
+	defer func() { _ = closeConn() }()
+	it, err := client.GetItem(api.WithToken(ctx, token), &pb.GetItemRequest{Id: id})
+	if err != nil {
+		return HistoryVersion{}, grpcError(err)
+	}
+	return itemFromPB(it), nil
+}
+
+// grpcError переводит статус gRPC в ошибку с кодом и сообщением сервера.
+func grpcError(err error) error {
+	if st, ok := status.FromError(err); ok {
+		return fmt.Errorf("server returned %s: %s", st.Code(), st.Message())
+	}
+	return err
+}
+
+func syncRequestToPB(p syncRequest) *pb.SyncRequest {
+	out := &pb.SyncRequest{
+		Cursor:      p.Cursor,
+		Limit:       int32(p.Limit),
+		Changes:     make([]*pb.ItemChange, 0, len(p.Changes)),
+		Resolutions: p.Resolutions,
+		Atomic:      p.Atomic,
+	}
+	if p.Resolve != nil {
+		out.Resolve = *p.Resolve
+	}
+	for _, ch := range p.Changes {
+		out.Changes = append(out.Changes, &pb.ItemChange{
+			Id:             ch.ID,
+			Version:        ch.Version,
+			Deleted:        ch.Deleted,
+			Name:           ch.Name,
+			FileName:       ch.FileName,
+			BlobId:         ch.BlobID,
+			LoginCipher:    ch.LoginCipher,
+			LoginNonce:     ch.LoginNonce,
+			PasswordCipher: ch.PasswordCipher,
+			PasswordNonce:  ch.PasswordNonce,
+			TextCipher:     ch.TextCipher,
+			TextNonce:      ch.TextNonce,
+			CardCipher:     ch.CardCipher,
+			CardNonce:      ch.CardNonce,
+			BaseVersions:   ch.BaseVersions,
+		})
+	}
+	return out
+}
+
+func syncResponseFromPB(r *pb.SyncResponse) *syncResponse {
+	out := &syncResponse{
+		Applied:       make([]appliedDTO, 0, len(r.GetApplied())),
+		Conflicts:     make([]conflictDTO, 0, len(r.GetConflicts())),
+		ServerChanges: make([]HistoryVersion, 0, len(r.GetServerChanges())),
+		Cursor:        r.GetCursor(),
+		HasMore:       r.GetHasMore(),
+	}
+	if r.GetServerTime() != nil {
+		out.ServerTime = r.GetServerTime().AsTime().UTC().Format(time.RFC3339)
+	}
+	for _, a := range r.GetApplied() {
+		out.Applied = append(out.Applied, appliedDTO{ID: a.GetId(), NewVersion: a.GetNewVersion()})
+	}
+	for _, c := range r.GetConflicts() {
+		dto := conflictDTO{ID: c.GetId(), Reason: c.GetReason(), Fields: c.GetFields()}
+		if c.GetServerItem() != nil {
+			si := itemFromPB(c.GetServerItem())
+			dto.ServerItem = &si
+		}
+		out.Conflicts = append(out.Conflicts, dto)
+	}
+	for _, it := range r.GetServerChanges() {
+		out.ServerChanges = append(out.ServerChanges, itemFromPB(it))
+	}
+	if r.GetHasCollections() {
+		cols := collectionsFromPB(r.GetCollections())
+		out.Collections = &cols
+	}
+	return out
+}
+
+// collectionsFromPB переводит коллекции организаций из gRPC-ответа в то же представление,
+// что и JSON API.
+func collectionsFromPB(pcs []*pb.Collection) []collectionSnapshot {
+	out := make([]collectionSnapshot, 0, len(pcs))
+	for _, c := range pcs {
+		snap := collectionSnapshot{
+			ID:   c.GetId(),
+			Name: c.GetName(),
+			Org:  c.GetOrg(),
+			Role: c.GetRole(),
+			sealedKey: sealedKey{
+				EphemeralKey:    c.GetEphemeralKey(),
+				WrappedKey:      c.GetWrappedKey(),
+				WrappedKeyNonce: c.GetWrappedKeyNonce(),
+			},
+			Items: make([]collectionItemView, 0, len(c.GetItems())),
+		}
+		for _, it := range c.GetItems() {
+			v := collectionItemView{
+				ID:      it.GetId(),
+				Name:    it.GetName(),
+				Version: it.GetVersion(),
+				Deleted: it.GetDeleted(),
+				collectionItemFields: collectionItemFields{
+					LoginCipher:    it.GetLoginCipher(),
+					LoginNonce:     it.GetLoginNonce(),
+					PasswordCipher: it.GetPasswordCipher(),
+					PasswordNonce:  it.GetPasswordNonce(),
+					TextCipher:     it.GetTextCipher(),
+					TextNonce:      it.GetTextNonce(),
+					CardCipher:     it.GetCardCipher(),
+					CardNonce:      it.GetCardNonce(),
+				},
+			}
+			if it.GetUpdatedAt() != nil {
+				v.UpdatedAt = it.GetUpdatedAt().AsTime().UTC().Format(time.RFC3339)
+			}
+			snap.Items = append(snap.Items, v)
+		}
+		out = append(out, snap)
+	}
+	return out
+}
+
+// itemFromPB переводит запись из gRPC-ответа в то же представление, что и JSON API.
+func itemFromPB(it *pb.Item) HistoryVersion {
+	v := HistoryVersion{
+		ID:             it.GetId(),
+		Name:           it.GetName(),
+		FileName:       it.GetFileName(),
+		Version:        it.GetVersion(),
+		Deleted:        it.GetDeleted(),
+		LoginCipher:    it.GetLoginCipher(),
+		LoginNonce:     it.GetLoginNonce(),
+		PasswordCipher: it.GetPasswordCipher(),
+		PasswordNonce:  it.GetPasswordNonce(),
+		TextCipher:     it.GetTextCipher(),
+		TextNonce:      it.GetTextNonce(),
+		CardCipher:     it.GetCardCipher(),
+		CardNonce:      it.GetCardNonce(),
+	}
+	if id := it.GetBlobId(); id != "" {
+		v.BlobID = &id
+	}
+	if it.GetUpdatedAt() != nil {
+		v.UpdatedAt = it.GetUpdatedAt().AsTime().UTC().Format(time.RFC3339)
+	}
+	return v
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"GophKeeper/internal/cli/model"
+	fsrepo "GophKeeper/internal/cli/repo/fs"
+	"GophKeeper/internal/config"
+	"GophKeeper/internal/pb"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fakeGRPCServer — имитация gRPC API: Sync отдаёт заранее заданные страницы,
+// UploadBlob собирает присланные части, GetItem отдаёт записи из items.
+type fakeGRPCServer struct {
+	pb.UnimplementedGophKeeperServer
+	pages []*pb.SyncResponse
+	items map[string]*pb.Item
+
+	mu       sync.Mutex
+	syncReq  *pb.SyncRequest
+	tokens   []string
+	uploaded bytes.Buffer
+	upNonce  []byte
+}
+
+func (s *fakeGRPCServer) remember(ctx context.Context) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.mu.Lock()
+	s.tokens = append(s.tokens, md.Get("authorization")...)
+	s.mu.Unlock()
+}
+
+func (s *fakeGRPCServer) Login(_ context.Context, req *pb.Credentials) (*pb.AuthResponse, error) {
+	if req.GetPassword() != "pw" {
+		return nil, status.Error(codes.Unauthenticated, "invalid login or password")
+	}
+	return &pb.AuthResponse{Token: "tok-" + req.GetLogin()}, nil
+}
+
+func (s *fakeGRPCServer) Register(_ context.Context, req *pb.Credentials) (*pb.AuthResponse, error) {
+	if req.GetLogin() == "taken" {
+		return nil, status.Error(codes.AlreadyExists, "login already in use")
+	}
+	return &pb.AuthResponse{Token: "tok-" + req.GetLogin()}, nil
+}
+
+func (s *fakeGRPCServer) Sync(req *pb.SyncRequest, stream pb.GophKeeper_SyncServer) error {
+	s.remember(stream.Context())
+	s.mu.Lock()
+	s.syncReq = req
+	s.mu.Unlock()
+	for _, p := range s.pages {
+		if err := stream.Send(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *fakeGRPCServer) UploadBlob(stream pb.GophKeeper_UploadBlobServer) error {
+	s.remember(stream.Context())
+	for {
+		ch, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		s.mu.Lock()
+		if len(ch.GetNonce()) > 0 {
+			s.upNonce = ch.GetNonce()
+		}
+		s.uploaded.Write(ch.GetData())
+		s.mu.Unlock()
+	}
+	return stream.SendAndClose(&pb.UploadBlobResponse{Created: true, Size: int64(s.uploaded.Len())})
+}
+
+func (s *fakeGRPCServer) GetItem(ctx context.Context, req *pb.GetItemRequest) (*pb.Item, error) {
+	s.remember(ctx)
+	it, ok := s.items[req.GetId()]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "item not found")
+	}
+	return it, nil
+}
+
+// startFakeGRPC поднимает fake-сервер на локальном порту и возвращает конфиг клиента с transport=grpc.
+func startFakeGRPC(t *testing.T, srv *fakeGRPCServer) *config.Config {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	gs := grpc.NewServer()
+	pb.RegisterGophKeeperServer(gs, srv)
+	go func() { _ = gs.Serve(lis) }()
+	t.Cleanup(gs.Stop)
+	return &config.Config{Transport: config.TransportGRPC, GRPCAddress: lis.Addr().String()}
+}
+
+func TestRunSyncBatch_GRPCStreamsPages(t *testing.T) {
+	setupUserEnv(t)
+	now := timestamppb.Now()
+	srv := &fakeGRPCServer{pages: []*pb.SyncResponse{
+		{
+			Applied:       []*pb.Applied{{Id: "l1", NewVersion: 4}},
+			ServerChanges: []*pb.Item{{Id: "s1", Name: "A", Version: 2, UpdatedAt: now, LoginCipher: []byte{1, 2}, LoginNonce: []byte{3}}},
+			Cursor:        "5",
+			HasMore:       true,
+			ServerTime:    now,
+		},
+		{
+			ServerChanges: []*pb.Item{{Id: "s2", Name: "B", Version: 3, UpdatedAt: now, BlobId: "BLOB-X"}},
+			Cursor:        "7",
+			ServerTime:    now,
+		},
+	}}
+	cfg := startFakeGRPC(t, srv)
+
+	local := model.Item{ID: "l1", Name: "mine", Version: 0, PasswordCipher: []byte{9}, PasswordNonce: []byte{8}}
+	r := new(syncMockRepo)
+	r.On("ListItems").Return([]model.Item{local}, nil).Once()
+	r.On("GetItemByName", "mine").Return(&local, nil).Once()
+	r.On("ApplySyncBatch", map[string]int64{"l1": 4}, mock.MatchedBy(func(items []model.Item) bool {
+		return len(items) == 1 && items[0].ID == "s1" && bytes.Equal(items[0].LoginCipher, []byte{1, 2})
+	})).Return(nil).Once()
+	r.On("ApplySyncBatch", map[string]int64(nil), mock.MatchedBy(func(items []model.Item) bool {
+		return len(items) == 1 && items[0].ID == "s2" && items[0].BlobID == "BLOB-X"
+	})).Return(nil).Once()
+	r.On("GetBlobByID", "BLOB-X").Return((*model.Blob)(nil), assert.AnError).Once()
+
+	res := RunSyncBatch(t.Context(), cfg, r, BatchSyncOptions{})
+	require.NoError(t, res.Err)
+	assert.Equal(t, 1, res.AppliedCount)
+	assert.Equal(t, []string{"BLOB-X"}, res.QueuedBlobIDs)
+	r.AssertExpectations(t)
+
+	// запрос ушёл одним потоком с токеном; шифртексты — байтами
+	require.NotNil(t, srv.syncReq)
+	assert.Equal(t, fullSyncCursor, srv.syncReq.GetCursor())
+	require.Len(t, srv.syncReq.GetChanges(), 1)
+	assert.Equal(t, []byte{9}, srv.syncReq.GetChanges()[0].GetPasswordCipher())
+	assert.Equal(t, []string{"Bearer token-abc"}, srv.tokens)
+	cursor, err := fsrepo.LoadSyncCursor("user1")
+	require.NoError(t, err)
+	assert.Equal(t, "7", cursor)
+}
+
+func TestSyncResponseFromPB_Collections(t *testing.T) {
+	now := timestamppb.Now()
+	sr := syncResponseFromPB(&pb.SyncResponse{
+		HasCollections: true,
+		Collections: []*pb.Collection{{
+			Id: "c1", Name: "default", Org: "acme", Role: "owner",
+			EphemeralKey: []byte{1}, WrappedKey: []byte{2}, WrappedKeyNonce: []byte{3},
+			Items: []*pb.CollectionItem{{Id: "i1", Name: "wifi", Version: 2, UpdatedAt: now, TextCipher: []byte{7}, TextNonce: []byte{8}}},
+		}},
+	})
+	require.NotNil(t, sr.Collections)
+	require.Len(t, *sr.Collections, 1)
+	col := (*sr.Collections)[0]
+	assert.Equal(t, "acme", col.Org)
+	assert.Equal(t, []byte{2}, col.WrappedKey)
+	require.Len(t, col.Items, 1)
+	assert.Equal(t, "wifi", col.Items[0].Name)
+	assert.Equal(t, []byte{7}, col.Items[0].TextCipher)
+	assert.NotEmpty(t, col.Items[0].UpdatedAt)
+
+	// пустой список — клиент очищает коллекции; не переданный — сохраняет прежние
+	sr = syncResponseFromPB(&pb.SyncResponse{HasCollections: true})
+	require.NotNil(t, sr.Collections)
+	assert.Empty(t, *sr.Collections)
+	assert.Nil(t, syncResponseFromPB(&pb.SyncResponse{}).Collections)
+}
+
+func TestSyncItemToServer_GRPCConflict(t *testing.T) {
+	setupUserEnv(t)
+	srv := &fakeGRPCServer{pages: []*pb.SyncResponse{{
+		Conflicts: []*pb.Conflict{{Id: "i1", Reason: "version_conflict", ServerItem: &pb.Item{Id: "i1", Version: 6}}},
+		Cursor:    "1",
+	}}}
+	cfg := startFakeGRPC(t, srv)
+
+	applied, _, serverVer, conflicts, err := SyncItemToServer(cfg, model.Item{ID: "i1", Name: "n", Version: 5}, false, nil)
+	require.NoError(t, err)
+	assert.False(t, applied)
+	assert.Equal(t, int64(6), serverVer)
+	assert.Contains(t, conflicts, "version_conflict")
+}
+
+func TestSyncItemByName_GRPCFetchesMergedItem(t *testing.T) {
+	setupUserEnv(t)
+	srv := &fakeGRPCServer{
+		pages: []*pb.SyncResponse{{Applied: []*pb.Applied{{Id: "m1", NewVersion: 5}}, Cursor: "9"}},
+		// сервер слил пароль с другого устройства с нашим текстом
+		items: map[string]*pb.Item{"m1": {Id: "m1", Name: "nm", Version: 5, PasswordCipher: []byte{9}, TextCipher: []byte{3}}},
+	}
+	cfg := startFakeGRPC(t, srv)
+
+	r := new(syncMockRepo)
+	it := &model.Item{ID: "m1", Name: "nm", Version: 3, TextCipher: []byte{3}, BaseVersions: map[string]int64{model.FieldText: 3}}
+	r.On("GetItemByName", "nm").Return(it, nil).Once()
+	r.On("UpsertFullFromServer", mock.MatchedBy(func(m model.Item) bool {
+		return m.ID == "m1" && m.Version == 5 && bytes.Equal(m.PasswordCipher, []byte{9}) && bytes.Equal(m.TextCipher, []byte{3})
+	})).Return(nil).Once()
+
+	applied, newVer, _, err := SyncItemByName(cfg, r, "nm", false, nil)
+	require.NoError(t, err)
+	assert.True(t, applied)
+	assert.Equal(t, int64(5), newVer)
+	r.AssertExpectations(t)
+	assert.Len(t, srv.tokens, 2)
+}
+
+func TestUploadBlobGRPC_SendsChunks(t *testing.T) {
+	setupUserEnv(t)
+	srv := &fakeGRPCServer{}
+	cfg := startFakeGRPC(t, srv)
+
+	data := bytes.Repeat([]byte("x"), 3*grpcBlobChunkSize+10)
+	created, err := uploadBlobGRPC(t.Context(), cfg, "token-abc", &model.Blob{ID: "b1", Cipher: data, Nonce: []byte{7}})
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, data, srv.uploaded.Bytes())
+	assert.Equal(t, []byte{7}, srv.upNonce)
+	assert.Equal(t, []string{"Bearer token-abc"}, srv.tokens)
+}
+
+func TestAuthGRPC(t *testing.T) {
+	cfg := startFakeGRPC(t, &fakeGRPCServer{})
+
+	token, err := AuthGRPC(t.Context(), cfg, "ann", "pw", false)
+	require.NoError(t, err)
+	assert.Equal(t, "tok-ann", token)
+
+	_, err = AuthGRPC(t.Context(), cfg, "ann", "bad", false)
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	_, err = AuthGRPC(t.Context(), cfg, "taken", "pw", true)
+	assert.ErrorIs(t, err, ErrLoginTaken)
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"GophKeeper/internal/cli/api"
+	"GophKeeper/internal/cli/model"
+	crepo "GophKeeper/internal/cli/repo"
+	fsrepo "GophKeeper/internal/cli/repo/fs"
+	"GophKeeper/internal/config"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// HistoryVersion — состояние записи на сервере: текущее или сохранённое в истории.
+type HistoryVersion struct {
+	ID             string  `json:"id"`
+	Name           string  `json:"name"`
+	FileName       string  `json:"file_name,omitempty"`
+	BlobID         *string `json:"blob_id,omitempty"`
+	Version        int64   `json:"version"`
+	Deleted        bool    `json:"deleted"`
+	LoginCipher    []byte  `json:"login_cipher,omitempty"`
+	LoginNonce     []byte  `json:"login_nonce,omitempty"`
+	PasswordCipher []byte  `json:"password_cipher,omitempty"`
+	PasswordNonce  []byte  `json:"password_nonce,omitempty"`
+	TextCipher     []byte  `json:"text_cipher,omitempty"`
+	TextNonce      []byte  `json:"text_nonce,omitempty"`
+	CardCipher     []byte  `json:"card_cipher,omitempty"`
+	CardNonce      []byte  `json:"card_nonce,omitempty"`
+	UpdatedAt      string  `json:"updated_at,omitempty"`
+	ArchivedAt     string  `json:"archived_at,omitempty"`
+}
+
+// ItemHistory — ответ GET /api/items/{id}/history.
+type ItemHistory struct {
+	ID       string           `json:"id"`
+	Current  HistoryVersion   `json:"current"`
+	Versions []HistoryVersion `json:"versions"`
+}
+
+// Fields перечисляет заполненные в версии поля: login, password, text, card, file.
+func (v HistoryVersion) Fields() []string {
+	var out []string
+	if len(v.LoginCipher) > 0 {
+		out = append(out, "login")
+	}
+	if len(v.PasswordCipher) > 0 {
+		out = append(out, "password")
+	}
+	if len(v.TextCipher) > 0 {
+		out = append(out, "text")
+	}
+	if len(v.CardCipher) > 0 {
+		out = append(out, "card")
+	}
+	if v.BlobID != nil && *v.BlobID != "" {
+		out = append(out, "file")
+	}
+	return out
+}
+
+// FetchItemHistory запрашивает историю версий локальной записи name с сервера.
+func FetchItemHistory(cfg *config.Config, r crepo.ItemRepository, name string) (*ItemHistory, error) {
+	it, token, err := historyTarget(cfg, r, name)
+	if err != nil {
+		return nil, err
+	}
+	resp, body, err := api.GetJSON(itemURL(cfg, it.ID, "history"), token)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, historyStatusError(resp, body)
+	}
+	var h ItemHistory
+	if err := json.Unmarshal(body, &h); err != nil {
+		return nil, fmt.Errorf("decode history: %w", err)
+	}
+	return &h, nil
+}
+
+// RestoreItemVersion просит сервер восстановить версию version записи name и
+// применяет полученное состояние локально. Возвращает новую серверную версию.
+// Несинхронизированные локальные изменения записи при этом перезаписываются.
+func RestoreItemVersion(cfg *config.Config, r crepo.ItemRepository, name string, version int64) (int64, error) {
+	it, token, err := historyTarget(cfg, r, name)
+	if err != nil {
+		return 0, err
+	}
+	payload := map[string]int64{"version": version}
+	resp, body, err := api.PostJSON(itemURL(cfg, it.ID, "restore"), payload, token)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, historyStatusError(resp, body)
+	}
+	var sv HistoryVersion
+	if err := json.Unmarshal(body, &sv); err != nil {
+		return 0, fmt.Errorf("decode restored item: %w", err)
+	}
+	restored := sv.toLocal(it.CreatedAt)
+	if err := r.UpsertFullFromServer(restored); err != nil {
+		return 0, fmt.Errorf("apply restored item locally: %w", err)
+	}
+	if err := r.SetServerVersion(restored.ID, restored.Version); err != nil {
+		return 0, fmt.Errorf("failed to persist server version locally: %w", err)
+	}
+	if restored.BlobID != "" {
+		if _, err := r.GetBlobByID(restored.BlobID); err != nil {
+			QueueBlobsForDownload([]string{restored.BlobID})
+		}
+	}
+	return restored.Version, nil
+}
+
+func historyTarget(cfg *config.Config, r crepo.ItemRepository, name string) (*model.Item, string, error) {
+	if cfg == nil {
+		return nil, "", fmt.Errorf("nil config")
+	}
+	token, err := (fsrepo.AuthFSStore{}).Load()
+	if err != nil {
+		return nil, "", fmt.Errorf("нет токена авторизации: %w", err)
+	}
+	it, err := r.GetItemByName(name)
+	if err != nil {
+		return nil, "", err
+	}
+	return it, token, nil
+}
+
+func itemURL(cfg *config.Config, id, action string) string {
+	return strings.TrimRight(cfg.ServerURL, "/") + "/api/items/" + url.PathEscape(id) + "/" + action
+}
+
+func historyStatusError(resp *http.Response, body []byte) error {
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("не найдено на сервере: %s", strings.TrimSpace(string(body)))
+	}
+	return api.StatusError(resp, body)
+}
+
+func (v HistoryVersion) toLocal(createdAt int64) model.Item {
+	updated := time.Now().Unix()
+	if t, err := time.Parse(time.RFC3339, v.UpdatedAt); err == nil {
+		updated = t.Unix()
+	}
+	if createdAt == 0 {
+		createdAt = updated
+	}
+	blobID := ""
+	if v.BlobID != nil {
+		blobID = *v.BlobID
+	}
+	return model.Item{
+		ID:             v.ID,
+		Name:           v.Name,
+		CreatedAt:      createdAt,
+		UpdatedAt:      updated,
+		Version:        v.Version,
+		Deleted:        v.Deleted,
+		FileName:       v.FileName,
+		BlobID:         blobID,
+		LoginCipher:    v.LoginCipher,
+		LoginNonce:     v.LoginNonce,
+		PasswordCipher: v.PasswordCipher,
+		PasswordNonce:  v.PasswordNonce,
+		TextCipher:     v.TextCipher,
+		TextNonce:      v.TextNonce,
+		CardCipher:     v.CardCipher,
+		CardNonce:      v.CardNonce,
+	}
+}
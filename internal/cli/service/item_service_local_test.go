@@ -59,6 +59,10 @@ func (m *mockItemRepo) UpsertFile(name, fileName string, blobCipher, blobNonce [
 func (m *mockItemRepo) SetServerVersion(id string, version int64) error { return nil }
 func (m *mockItemRepo) GetBlobByID(id string) (*model.Blob, error)      { return nil, nil }
 func (m *mockItemRepo) UpsertFullFromServer(it model.Item) error        { return nil }
+func (m *mockItemRepo) ApplySyncBatch(versions map[string]int64, items []model.Item) error {
+	return nil
+}
+func (m *mockItemRepo) InsertFull(it model.Item) error { return nil }
 
 var _ crepo.ItemRepository = (*mockItemRepo)(nil)
 
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"GophKeeper/internal/openapi"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Клиентские DTO соответствуют опубликованному контракту: клиент не отправляет
+// и не ожидает полей вне схемы, а типы полей совпадают.
+func TestClientDTOsConformToOpenAPI(t *testing.T) {
+	d, err := openapi.Diff("SyncRequest", syncRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, d.Unknown)
+	assert.Empty(t, d.Mismatched)
+	// устаревший last_sync_at клиент не использует
+	assert.Equal(t, []string{"last_sync_at"}, d.Missing)
+
+	d, err = openapi.Diff("SyncResponse", syncResponse{})
+	require.NoError(t, err)
+	// local_copy клиент заполняет сам (resolve=both), сервер его не присылает
+	assert.Equal(t, []string{"conflicts[].local_copy"}, d.Unknown)
+	assert.Empty(t, d.Mismatched)
+
+	for name, dto := range map[string]any{
+		"Item":                   HistoryVersion{},
+		"HistoryResponse":        ItemHistory{},
+		"UserKeys":               userKeys{},
+		"PublicKeyResponse":      publicKeyResponse{},
+		"ShareRequest":           shareRequest{},
+		"IncomingShare":          incomingShare{},
+		"OutgoingShare":          outgoingShare{},
+		"CreateOrgRequest":       createOrgRequest{},
+		"Org":                    orgView{},
+		"OrgMemberRequest":       orgMemberRequest{},
+		"OrgMember":              orgMemberView{},
+		"CollectionItemRequest":  collectionItemRequest{},
+		"CollectionItemResponse": collectionItemResponse{},
+		"CollectionSnapshot":     collectionSnapshot{},
+		"EmergencyGrantRequest":  emergencyGrantRequest{},
+		"EmergencyAccess":        EmergencyAccess{},
+		"EmergencyVault":         emergencyVaultView{},
+	} {
+		d, err = openapi.Diff(name, dto)
+		require.NoError(t, err)
+		assert.Empty(t, d.Unknown, name)
+		assert.Empty(t, d.Mismatched, name)
+	}
+}
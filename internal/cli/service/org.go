@@ -0,0 +1,475 @@
+package service
+
+import (
+	"GophKeeper/internal/cli/api"
+	"GophKeeper/internal/cli/crypto"
+	"GophKeeper/internal/cli/model"
+	view "GophKeeper/internal/cli/model/view"
+	crepo "GophKeeper/internal/cli/repo"
+	fsrepo "GophKeeper/internal/cli/repo/fs"
+	"GophKeeper/internal/config"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultCollection — коллекция, которую сервер создаёт вместе с организацией.
+const DefaultCollection = "default"
+
+// sealedKey — ключ коллекции, запечатанный открытым ключом участника.
+type sealedKey struct {
+	EphemeralKey    []byte `json:"ephemeral_key"`
+	WrappedKey      []byte `json:"wrapped_key"`
+	WrappedKeyNonce []byte `json:"wrapped_key_nonce"`
+}
+
+// createOrgRequest — тело POST /api/orgs.
+type createOrgRequest struct {
+	Name           string `json:"name"`
+	CollectionName string `json:"collection_name,omitempty"`
+	sealedKey
+}
+
+// collectionView — коллекция в ответах /api/orgs.
+type collectionView struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// orgView — элемент ответа GET /api/orgs.
+type orgView struct {
+	Name        string           `json:"name"`
+	Role        string           `json:"role"`
+	Collections []collectionView `json:"collections"`
+}
+
+// collectionKeyBody — ключ коллекции для участника в PUT /api/orgs/{org}/members/{login}.
+type collectionKeyBody struct {
+	CollectionID string `json:"collection_id"`
+	sealedKey
+}
+
+// orgMemberRequest — тело PUT /api/orgs/{org}/members/{login}.
+type orgMemberRequest struct {
+	Role string              `json:"role"`
+	Keys []collectionKeyBody `json:"keys,omitempty"`
+}
+
+// orgMemberView — элемент ответа GET /api/orgs/{org}/members.
+type orgMemberView struct {
+	Login     string `json:"login"`
+	Role      string `json:"role"`
+	PublicKey []byte `json:"public_key,omitempty"`
+}
+
+// collectionItemFields — поля записи коллекции, зашифрованные ключом коллекции.
+type collectionItemFields struct {
+	LoginCipher    []byte `json:"login_cipher,omitempty"`
+	LoginNonce     []byte `json:"login_nonce,omitempty"`
+	PasswordCipher []byte `json:"password_cipher,omitempty"`
+	PasswordNonce  []byte `json:"password_nonce,omitempty"`
+	TextCipher     []byte `json:"text_cipher,omitempty"`
+	TextNonce      []byte `json:"text_nonce,omitempty"`
+	CardCipher     []byte `json:"card_cipher,omitempty"`
+	CardNonce      []byte `json:"card_nonce,omitempty"`
+}
+
+// collectionItemRequest — тело PUT /api/collections/{id}/items/{item_id}.
+type collectionItemRequest struct {
+	Name    string `json:"name"`
+	Version int64  `json:"version"`
+	Deleted bool   `json:"deleted,omitempty"`
+	collectionItemFields
+}
+
+// collectionItemResponse — ответ PUT /api/collections/{id}/items/{item_id}.
+type collectionItemResponse struct {
+	ID      string `json:"id"`
+	Version int64  `json:"version"`
+}
+
+// collectionItemView — запись коллекции в ответе sync.
+type collectionItemView struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Version   int64  `json:"version"`
+	Deleted   bool   `json:"deleted"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+	collectionItemFields
+}
+
+// collectionSnapshot — коллекция в поле collections ответа sync.
+type collectionSnapshot struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Org  string `json:"org"`
+	Role string `json:"role"`
+	sealedKey
+	Items []collectionItemView `json:"items"`
+}
+
+// OrgMember — участник организации.
+type OrgMember struct {
+	Login  string
+	Role   string
+	HasKey bool // пользователь опубликовал открытый ключ
+}
+
+// Collections возвращает хранилище коллекций организаций, если его реализует репозиторий r.
+func Collections(r crepo.ItemRepository) (crepo.CollectionRepository, bool) {
+	c, ok := r.(crepo.CollectionRepository)
+	return c, ok
+}
+
+// CreateOrg создаёт организацию name; пользователь становится её владельцем. Ключ
+// коллекции DefaultCollection запечатывается открытым ключом пользователя и сохраняется
+// локально, чтобы сразу приглашать участников.
+func CreateOrg(cfg *config.Config, r crepo.ItemRepository, name string) error {
+	s, err := newShareSession(cfg)
+	if err != nil {
+		return err
+	}
+	kp, err := s.publishKeys(cfg)
+	if err != nil {
+		return fmt.Errorf("publish keys: %w", err)
+	}
+	key, err := crypto.NewItemKey()
+	if err != nil {
+		return err
+	}
+	eph, wrapped, nonce, err := crypto.SealKey(kp.Public, key)
+	if err != nil {
+		return fmt.Errorf("seal collection key: %w", err)
+	}
+	sealed := sealedKey{EphemeralKey: eph, WrappedKey: wrapped, WrappedKeyNonce: nonce}
+	req := createOrgRequest{Name: name, CollectionName: DefaultCollection, sealedKey: sealed}
+	resp, body, err := api.SendJSON(http.MethodPost, apiURL(cfg, "/api/orgs"), req, s.token)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusConflict {
+		return fmt.Errorf("организация %q уже существует", name)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		return orgStatusError(resp, body)
+	}
+	var org orgView
+	if err := json.Unmarshal(body, &org); err != nil {
+		return fmt.Errorf("decode organization: %w", err)
+	}
+	cols, ok := Collections(r)
+	if !ok {
+		return nil
+	}
+	for _, c := range org.Collections {
+		err := cols.SaveCollection(model.Collection{ID: c.ID, Org: org.Name, Name: c.Name, Role: org.Role,
+			EphemeralKey: eph, WrappedKey: wrapped, WrappedKeyNonce: nonce})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InviteMember добавляет пользователя login в организацию org с ролью role или меняет
+// его роль. Ключи всех коллекций организации открываются закрытым ключом пользователя
+// и запечатываются открытым ключом приглашаемого.
+func InviteMember(cfg *config.Config, r crepo.ItemRepository, org, login, role string) error {
+	cols, ok := Collections(r)
+	if !ok {
+		return errors.New("local storage does not support collections")
+	}
+	s, err := newShareSession(cfg)
+	if err != nil {
+		return err
+	}
+	kp, err := s.publishKeys(cfg)
+	if err != nil {
+		return fmt.Errorf("publish keys: %w", err)
+	}
+	o, err := s.findOrg(cfg, org)
+	if err != nil {
+		return err
+	}
+	pub, err := s.publicKey(cfg, login)
+	if err != nil {
+		return err
+	}
+	req := orgMemberRequest{Role: role, Keys: make([]collectionKeyBody, 0, len(o.Collections))}
+	for _, c := range o.Collections {
+		local, err := cols.GetCollection(org, c.Name)
+		if err != nil || local.ID != c.ID {
+			return fmt.Errorf("нет ключа коллекции %s/%s: выполните sync", org, c.Name)
+		}
+		key, err := crypto.OpenKey(kp.Private, local.EphemeralKey, local.WrappedKey, local.WrappedKeyNonce)
+		if err != nil {
+			return fmt.Errorf("open collection key %s/%s: %w", org, c.Name, err)
+		}
+		eph, wrapped, nonce, err := crypto.SealKey(pub, key)
+		if err != nil {
+			return fmt.Errorf("seal collection key: %w", err)
+		}
+		req.Keys = append(req.Keys, collectionKeyBody{CollectionID: c.ID,
+			sealedKey: sealedKey{EphemeralKey: eph, WrappedKey: wrapped, WrappedKeyNonce: nonce}})
+	}
+	resp, body, err := api.SendJSON(http.MethodPut, memberURL(cfg, org, login), req, s.token)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusNoContent {
+		return orgStatusError(resp, body)
+	}
+	return nil
+}
+
+// OrgMembers возвращает участников организации org.
+func OrgMembers(cfg *config.Config, org string) ([]OrgMember, error) {
+	s, err := newShareSession(cfg)
+	if err != nil {
+		return nil, err
+	}
+	resp, body, err := api.GetJSON(apiURL(cfg, "/api/orgs/"+url.PathEscape(org)+"/members"), s.token)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, orgStatusError(resp, body)
+	}
+	var members []orgMemberView
+	if err := json.Unmarshal(body, &members); err != nil {
+		return nil, fmt.Errorf("decode members: %w", err)
+	}
+	out := make([]OrgMember, 0, len(members))
+	for _, m := range members {
+		out = append(out, OrgMember{Login: m.Login, Role: m.Role, HasKey: len(m.PublicKey) > 0})
+	}
+	return out, nil
+}
+
+// RemoveMember исключает пользователя login из организации org. Скачанные им ранее
+// записи остаются у него: ключи коллекций не перевыпускаются.
+func RemoveMember(cfg *config.Config, org, login string) error {
+	s, err := newShareSession(cfg)
+	if err != nil {
+		return err
+	}
+	resp, body, err := api.SendJSON(http.MethodDelete, memberURL(cfg, org, login), nil, s.token)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusNoContent {
+		return orgStatusError(resp, body)
+	}
+	return nil
+}
+
+// PutCollectionItem записывает поле fieldType записи name коллекции org/collection
+// (login|password|text|card) и сохраняет новую версию локально.
+func PutCollectionItem(cfg *config.Config, r crepo.ItemRepository, org, collection, name, fieldType string, value []string) (int64, error) {
+	cols, ok := Collections(r)
+	if !ok {
+		return 0, errors.New("local storage does not support collections")
+	}
+	c, err := cols.GetCollection(org, collection)
+	if err != nil {
+		return 0, fmt.Errorf("коллекция %s/%s не найдена: выполните sync", org, collection)
+	}
+	plain, err := fieldPlaintext(fieldType, value)
+	if err != nil {
+		return 0, err
+	}
+	s, err := newShareSession(cfg)
+	if err != nil {
+		return 0, err
+	}
+	key, err := openCollectionKey(c)
+	if err != nil {
+		return 0, err
+	}
+	cipher, nonce, err := crypto.Encrypt(plain, key)
+	if err != nil {
+		return 0, err
+	}
+
+	it, err := cols.GetCollectionItem(c.ID, name)
+	if err != nil {
+		it = &model.CollectionItem{ID: uuid.NewString(), CollectionID: c.ID, Name: name}
+	}
+	switch fieldType {
+	case "login":
+		it.LoginCipher, it.LoginNonce = cipher, nonce
+	case "password":
+		it.PasswordCipher, it.PasswordNonce = cipher, nonce
+	case "text":
+		it.TextCipher, it.TextNonce = cipher, nonce
+	case "card":
+		it.CardCipher, it.CardNonce = cipher, nonce
+	}
+	req := collectionItemRequest{Name: name, Version: it.Version, collectionItemFields: collectionItemFields{
+		LoginCipher: it.LoginCipher, LoginNonce: it.LoginNonce,
+		PasswordCipher: it.PasswordCipher, PasswordNonce: it.PasswordNonce,
+		TextCipher: it.TextCipher, TextNonce: it.TextNonce,
+		CardCipher: it.CardCipher, CardNonce: it.CardNonce,
+	}}
+	u := apiURL(cfg, "/api/collections/"+url.PathEscape(c.ID)+"/items/"+url.PathEscape(it.ID))
+	resp, body, err := api.SendJSON(http.MethodPut, u, req, s.token)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode == http.StatusConflict {
+		return 0, errors.New("запись коллекции изменилась на сервере: выполните sync и повторите")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, orgStatusError(resp, body)
+	}
+	var out collectionItemResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		return 0, fmt.Errorf("decode collection item: %w", err)
+	}
+	it.Version, it.UpdatedAt = out.Version, time.Now().Unix()
+	return it.Version, cols.SaveCollectionItem(*it)
+}
+
+// DecryptCollectionItem открывает ключ коллекции c закрытым ключом пользователя
+// и расшифровывает поля записи it для отображения.
+func DecryptCollectionItem(c *model.Collection, it *model.CollectionItem) (*view.DecryptedItem, error) {
+	key, err := openCollectionKey(c)
+	if err != nil {
+		return nil, err
+	}
+	return &view.DecryptedItem{
+		ID:        it.ID,
+		Name:      it.Name,
+		UpdatedAt: it.UpdatedAt,
+		Version:   it.Version,
+		Deleted:   it.Deleted,
+		Login:     decryptField(it.LoginCipher, it.LoginNonce, key),
+		Password:  decryptField(it.PasswordCipher, it.PasswordNonce, key),
+		Text:      decryptField(it.TextCipher, it.TextNonce, key),
+		Card:      decryptField(it.CardCipher, it.CardNonce, key),
+		FileName:  "<not set>",
+	}, nil
+}
+
+// openCollectionKey открывает ключ коллекции закрытым ключом текущего пользователя.
+func openCollectionKey(c *model.Collection) ([]byte, error) {
+	login, err := (fsrepo.AuthFSStore{}).LoadLogin()
+	if err != nil {
+		return nil, fmt.Errorf("нет активного пользователя: %w", err)
+	}
+	vault, err := crypto.LoadOrCreateKey(login)
+	if err != nil {
+		return nil, err
+	}
+	kp, err := crypto.LoadOrCreateKeyPair(login, vault)
+	if err != nil {
+		return nil, err
+	}
+	key, err := crypto.OpenKey(kp.Private, c.EphemeralKey, c.WrappedKey, c.WrappedKeyNonce)
+	if err != nil {
+		return nil, fmt.Errorf("open collection key %s/%s: %w", c.Org, c.Name, err)
+	}
+	return key, nil
+}
+
+// storeCollections заменяет локальные коллекции снимком из ответа sync.
+func storeCollections(r crepo.ItemRepository, snaps []collectionSnapshot) error {
+	cols, ok := Collections(r)
+	if !ok {
+		return nil
+	}
+	local := make([]model.Collection, 0, len(snaps))
+	var items []model.CollectionItem
+	for _, s := range snaps {
+		local = append(local, model.Collection{ID: s.ID, Org: s.Org, Name: s.Name, Role: s.Role,
+			EphemeralKey: s.EphemeralKey, WrappedKey: s.WrappedKey, WrappedKeyNonce: s.WrappedKeyNonce})
+		for _, it := range s.Items {
+			items = append(items, it.toLocal(s.ID))
+		}
+	}
+	return cols.ReplaceCollections(local, items)
+}
+
+// findOrg находит организацию пользователя по имени.
+func (s *shareSession) findOrg(cfg *config.Config, name string) (*orgView, error) {
+	resp, body, err := api.GetJSON(apiURL(cfg, "/api/orgs"), s.token)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, orgStatusError(resp, body)
+	}
+	var orgs []orgView
+	if err := json.Unmarshal(body, &orgs); err != nil {
+		return nil, fmt.Errorf("decode organizations: %w", err)
+	}
+	for i := range orgs {
+		if orgs[i].Name == name {
+			return &orgs[i], nil
+		}
+	}
+	return nil, fmt.Errorf("организация %q не найдена", name)
+}
+
+// fieldPlaintext собирает открытое значение поля записи коллекции.
+func fieldPlaintext(fieldType string, value []string) ([]byte, error) {
+	switch fieldType {
+	case "login", "password", "text":
+		if len(value) != 1 {
+			return nil, fmt.Errorf("ожидается 1 аргумент для %s", fieldType)
+		}
+		return []byte(value[0]), nil
+	case "card":
+		if len(value) != 4 {
+			return nil, fmt.Errorf("ожидается 4 аргумента для card: <number> <card_holder> <exp> <cvc>")
+		}
+		return []byte(fmt.Sprintf(`{"number":%q,"card_holder":%q,"exp":%q,"cvc":%q}`, value[0], value[1], value[2], value[3])), nil
+	default:
+		return nil, fmt.Errorf("неизвестный тип: %s (ожидается: login|password|text|card)", fieldType)
+	}
+}
+
+func memberURL(cfg *config.Config, org, login string) string {
+	return apiURL(cfg, "/api/orgs/"+url.PathEscape(org)+"/members/"+url.PathEscape(login))
+}
+
+// orgStatusError переводит ответ сервера об организации в ошибку для пользователя.
+func orgStatusError(resp *http.Response, body []byte) error {
+	msg := strings.TrimSpace(string(body))
+	switch resp.StatusCode {
+	case http.StatusForbidden:
+		return fmt.Errorf("недостаточно прав в организации: %s", msg)
+	case http.StatusConflict, http.StatusUnprocessableEntity:
+		return fmt.Errorf("сервер отклонил изменение: %s", msg)
+	}
+	return historyStatusError(resp, body)
+}
+
+func (v collectionItemView) toLocal(collectionID string) model.CollectionItem {
+	updated := time.Now().Unix()
+	if t, err := time.Parse(time.RFC3339, v.UpdatedAt); err == nil {
+		updated = t.Unix()
+	}
+	return model.CollectionItem{
+		ID:             v.ID,
+		CollectionID:   collectionID,
+		Name:           v.Name,
+		Version:        v.Version,
+		Deleted:        v.Deleted,
+		UpdatedAt:      updated,
+		LoginCipher:    v.LoginCipher,
+		LoginNonce:     v.LoginNonce,
+		PasswordCipher: v.PasswordCipher,
+		PasswordNonce:  v.PasswordNonce,
+		TextCipher:     v.TextCipher,
+		TextNonce:      v.TextNonce,
+		CardCipher:     v.CardCipher,
+		CardNonce:      v.CardNonce,
+	}
+}
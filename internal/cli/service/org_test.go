@@ -0,0 +1,217 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"GophKeeper/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOrgServer — имитация эндпоинтов организаций поверх fakeShareServer (ключи пользователей):
+// одна коллекция на организацию, ключи и записи хранятся как пришли.
+type fakeOrgServer struct {
+	mu      sync.Mutex
+	orgs    map[string]orgView                       // имя → организация с коллекциями
+	members map[string]map[string]string             // org → login → role
+	keys    map[string]sealedKey                     // collectionID/login
+	items   map[string]map[string]collectionItemView // collectionID → itemID → запись
+}
+
+func newFakeOrgServer(t *testing.T) (*fakeOrgServer, *httptest.Server) {
+	t.Helper()
+	_, share := newFakeShareServer(t)
+	f := &fakeOrgServer{
+		orgs:    map[string]orgView{},
+		members: map[string]map[string]string{},
+		keys:    map[string]sealedKey{},
+		items:   map[string]map[string]collectionItemView{},
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/", share.Config.Handler)
+	user := func(r *http.Request) string {
+		c, _ := r.Cookie("auth_token")
+		return strings.TrimPrefix(c.Value, "tok-")
+	}
+	mux.HandleFunc("POST /api/orgs", func(w http.ResponseWriter, r *http.Request) {
+		var req createOrgRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if _, ok := f.orgs[req.Name]; ok {
+			http.Error(w, "organization exists", http.StatusConflict)
+			return
+		}
+		col := collectionView{ID: "col-" + req.Name, Name: req.CollectionName}
+		f.orgs[req.Name] = orgView{Name: req.Name, Collections: []collectionView{col}}
+		f.members[req.Name] = map[string]string{user(r): "owner"}
+		f.keys[col.ID+"/"+user(r)] = req.sealedKey
+		f.items[col.ID] = map[string]collectionItemView{}
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(orgView{Name: req.Name, Role: "owner", Collections: []collectionView{col}})
+	})
+	mux.HandleFunc("GET /api/orgs", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		out := []orgView{}
+		for name, o := range f.orgs {
+			if role, ok := f.members[name][user(r)]; ok {
+				o.Role = role
+				out = append(out, o)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(out)
+	})
+	mux.HandleFunc("GET /api/orgs/{org}/members", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		out := []orgMemberView{}
+		for login, role := range f.members[r.PathValue("org")] {
+			out = append(out, orgMemberView{Login: login, Role: role})
+		}
+		_ = json.NewEncoder(w).Encode(out)
+	})
+	mux.HandleFunc("PUT /api/orgs/{org}/members/{login}", func(w http.ResponseWriter, r *http.Request) {
+		var req orgMemberRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		org := r.PathValue("org")
+		if role := f.members[org][user(r)]; role != "owner" && role != "admin" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		for _, k := range req.Keys {
+			f.keys[k.CollectionID+"/"+r.PathValue("login")] = k.sealedKey
+		}
+		f.members[org][r.PathValue("login")] = req.Role
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("DELETE /api/orgs/{org}/members/{login}", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		delete(f.members[r.PathValue("org")], r.PathValue("login"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("PUT /api/collections/{id}/items/{item_id}", func(w http.ResponseWriter, r *http.Request) {
+		var req collectionItemRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		items := f.items[r.PathValue("id")]
+		cur := items[r.PathValue("item_id")]
+		if req.Version != cur.Version {
+			http.Error(w, "version mismatch", http.StatusConflict)
+			return
+		}
+		items[r.PathValue("item_id")] = collectionItemView{ID: r.PathValue("item_id"), Name: req.Name,
+			Version: cur.Version + 1, collectionItemFields: req.collectionItemFields}
+		_ = json.NewEncoder(w).Encode(collectionItemResponse{ID: r.PathValue("item_id"), Version: cur.Version + 1})
+	})
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return f, ts
+}
+
+// snapshots собирает поле collections ответа sync для пользователя login.
+func (f *fakeOrgServer) snapshots(login string) []collectionSnapshot {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []collectionSnapshot
+	for name, o := range f.orgs {
+		role, ok := f.members[name][login]
+		if !ok {
+			continue
+		}
+		for _, c := range o.Collections {
+			s := collectionSnapshot{ID: c.ID, Name: c.Name, Org: name, Role: role, sealedKey: f.keys[c.ID+"/"+login]}
+			for _, it := range f.items[c.ID] {
+				s.Items = append(s.Items, it)
+			}
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func TestOrgs_CreateInvitePutSync(t *testing.T) {
+	setupUserEnv(t)
+	f, ts := newFakeOrgServer(t)
+	cfg := &config.Config{ServerURL: ts.URL}
+
+	bobRepo := useAccount(t, "bob")
+	_, err := PublishKeys(cfg)
+	require.NoError(t, err)
+
+	aliceRepo := useAccount(t, "alice")
+	require.NoError(t, CreateOrg(cfg, aliceRepo, "acme"))
+	assert.ErrorContains(t, CreateOrg(cfg, aliceRepo, "acme"), "уже существует")
+	col, err := aliceRepo.GetCollection("acme", DefaultCollection)
+	require.NoError(t, err)
+	assert.Equal(t, "owner", col.Role)
+
+	ver, err := PutCollectionItem(cfg, aliceRepo, "acme", DefaultCollection, "wifi", "password", []string{"s3cret"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), ver)
+	ver, err = PutCollectionItem(cfg, aliceRepo, "acme", DefaultCollection, "wifi", "login", []string{"guest"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), ver)
+
+	assert.ErrorContains(t, InviteMember(cfg, aliceRepo, "acme", "carol", "member"), "не найден")
+	require.NoError(t, InviteMember(cfg, aliceRepo, "acme", "bob", "read-only"))
+	members, err := OrgMembers(cfg, "acme")
+	require.NoError(t, err)
+	assert.Len(t, members, 2)
+
+	// bob получает коллекцию при синхронизации и расшифровывает её своим ключом
+	useAccount(t, "bob")
+	require.NoError(t, storeCollections(bobRepo, f.snapshots("bob")))
+	bcol, err := bobRepo.GetCollection("acme", DefaultCollection)
+	require.NoError(t, err)
+	assert.Equal(t, "read-only", bcol.Role)
+	it, err := bobRepo.GetCollectionItem(bcol.ID, "wifi")
+	require.NoError(t, err)
+	d, err := DecryptCollectionItem(bcol, it)
+	require.NoError(t, err)
+	assert.Equal(t, "guest", d.Login)
+	assert.Equal(t, "s3cret", d.Password)
+
+	// read-only участник не может приглашать
+	assert.ErrorContains(t, InviteMember(cfg, bobRepo, "acme", "alice", "member"), "недостаточно прав")
+
+	useAccount(t, "alice")
+	require.NoError(t, RemoveMember(cfg, "acme", "bob"))
+	assert.Empty(t, f.snapshots("bob"))
+}
+
+func TestOrgs_PutCollectionItemConflict(t *testing.T) {
+	setupUserEnv(t)
+	f, ts := newFakeOrgServer(t)
+	cfg := &config.Config{ServerURL: ts.URL}
+
+	r := useAccount(t, "alice")
+	require.NoError(t, CreateOrg(cfg, r, "acme"))
+	_, err := PutCollectionItem(cfg, r, "acme", DefaultCollection, "note", "text", []string{"v1"})
+	require.NoError(t, err)
+
+	// запись изменил другой участник — локальная версия устарела
+	f.mu.Lock()
+	for id, it := range f.items["col-acme"] {
+		it.Version++
+		f.items["col-acme"][id] = it
+	}
+	f.mu.Unlock()
+	_, err = PutCollectionItem(cfg, r, "acme", DefaultCollection, "note", "text", []string{"v2"})
+	assert.ErrorContains(t, err, "выполните sync")
+
+	_, err = PutCollectionItem(cfg, r, "acme", "missing", "note", "text", []string{"v2"})
+	assert.ErrorContains(t, err, "не найдена")
+	_, err = PutCollectionItem(cfg, r, "acme", DefaultCollection, "card", "card", []string{"1"})
+	assert.ErrorContains(t, err, "4 аргумента")
+}
@@ -0,0 +1,414 @@
+package service
+
+import (
+	"GophKeeper/internal/cli/api"
+	"GophKeeper/internal/cli/crypto"
+	"GophKeeper/internal/cli/model"
+	view "GophKeeper/internal/cli/model/view"
+	crepo "GophKeeper/internal/cli/repo"
+	fsrepo "GophKeeper/internal/cli/repo/fs"
+	"GophKeeper/internal/config"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrItemNotSynced — запись ещё не выгружена на сервер или изменена локально.
+var ErrItemNotSynced = errors.New("запись не синхронизирована с сервером: выполните sync")
+
+// userKeys — тело PUT и ответ GET /api/user/keys.
+type userKeys struct {
+	PublicKey         []byte `json:"public_key"`
+	WrappedPrivateKey []byte `json:"wrapped_private_key"`
+	PrivateKeyNonce   []byte `json:"private_key_nonce"`
+}
+
+// publicKeyResponse — ответ GET /api/users/{login}/public-key.
+type publicKeyResponse struct {
+	Login     string `json:"login"`
+	PublicKey []byte `json:"public_key"`
+}
+
+// shareRequest — тело PUT /api/items/{id}/shares/{login}.
+type shareRequest struct {
+	Version         int64  `json:"version"`
+	EphemeralKey    []byte `json:"ephemeral_key"`
+	WrappedKey      []byte `json:"wrapped_key"`
+	WrappedKeyNonce []byte `json:"wrapped_key_nonce"`
+	LoginCipher     []byte `json:"login_cipher,omitempty"`
+	LoginNonce      []byte `json:"login_nonce,omitempty"`
+	PasswordCipher  []byte `json:"password_cipher,omitempty"`
+	PasswordNonce   []byte `json:"password_nonce,omitempty"`
+	TextCipher      []byte `json:"text_cipher,omitempty"`
+	TextNonce       []byte `json:"text_nonce,omitempty"`
+	CardCipher      []byte `json:"card_cipher,omitempty"`
+	CardNonce       []byte `json:"card_nonce,omitempty"`
+}
+
+// incomingShare — элемент ответа GET /api/shares.
+type incomingShare struct {
+	ItemID      string `json:"item_id"`
+	Name        string `json:"name"`
+	Owner       string `json:"owner"`
+	ItemVersion int64  `json:"item_version"`
+	shareRequest
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// outgoingShare — элемент ответа GET /api/shares/outgoing.
+type outgoingShare struct {
+	ItemID             string `json:"item_id"`
+	Name               string `json:"name"`
+	Recipient          string `json:"recipient"`
+	RecipientPublicKey []byte `json:"recipient_public_key"`
+	Version            int64  `json:"version"`
+	ItemVersion        int64  `json:"item_version"`
+}
+
+// ShareSyncResult — итог обновления общих записей после синхронизации.
+type ShareSyncResult struct {
+	Resealed int // доли, перешифрованные из новой версии записи владельца
+	Incoming int // записи, открытые пользователю другими
+}
+
+// SharedItems возвращает хранилище общих записей, если его реализует репозиторий r.
+func SharedItems(r crepo.ItemRepository) (crepo.SharedItemRepository, bool) {
+	s, ok := r.(crepo.SharedItemRepository)
+	return s, ok
+}
+
+// shareSession — данные текущего пользователя, нужные для обмена записями.
+type shareSession struct {
+	login string
+	token string
+	vault []byte
+}
+
+func newShareSession(cfg *config.Config) (*shareSession, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("nil config")
+	}
+	token, err := (fsrepo.AuthFSStore{}).Load()
+	if err != nil {
+		return nil, fmt.Errorf("нет токена авторизации: %w", err)
+	}
+	login, err := (fsrepo.AuthFSStore{}).LoadLogin()
+	if err != nil {
+		return nil, fmt.Errorf("нет активного пользователя: %w", err)
+	}
+	vault, err := crypto.LoadOrCreateKey(login)
+	if err != nil {
+		return nil, err
+	}
+	return &shareSession{login: login, token: token, vault: vault}, nil
+}
+
+func apiURL(cfg *config.Config, path string) string {
+	return strings.TrimRight(cfg.ServerURL, "/") + path
+}
+
+// PublishKeys публикует пару ключей X25519 пользователя на сервере. Если на сервере уже
+// есть пара, которую открывает ключ хранилища, она сохраняется локально и используется.
+func PublishKeys(cfg *config.Config) (*crypto.KeyPair, error) {
+	s, err := newShareSession(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return s.publishKeys(cfg)
+}
+
+func (s *shareSession) publishKeys(cfg *config.Config) (*crypto.KeyPair, error) {
+	resp, body, err := api.GetJSON(apiURL(cfg, "/api/user/keys"), s.token)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var k userKeys
+		if err := json.Unmarshal(body, &k); err != nil {
+			return nil, fmt.Errorf("decode keys: %w", err)
+		}
+		if kp, err := crypto.UnwrapKeyPair(k.WrappedPrivateKey, k.PrivateKeyNonce, s.vault); err == nil && bytes.Equal(kp.Public, k.PublicKey) {
+			if err := crypto.SaveKeyPair(s.login, kp); err != nil {
+				return nil, err
+			}
+			return kp, nil
+		}
+	case http.StatusNotFound:
+	default:
+		return nil, api.StatusError(resp, body)
+	}
+	kp, err := crypto.LoadOrCreateKeyPair(s.login, s.vault)
+	if err != nil {
+		return nil, err
+	}
+	payload := userKeys{PublicKey: kp.Public, WrappedPrivateKey: kp.WrappedPrivate, PrivateKeyNonce: kp.Nonce}
+	resp, body, err = api.SendJSON(http.MethodPut, apiURL(cfg, "/api/user/keys"), payload, s.token)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusNoContent {
+		return nil, api.StatusError(resp, body)
+	}
+	return kp, nil
+}
+
+// ShareItem открывает синхронизированную запись name пользователю recipient: поля
+// перешифровываются новым ключом записи, а он запечатывается открытым ключом получателя.
+func ShareItem(cfg *config.Config, r crepo.ItemRepository, name, recipient string) error {
+	s, err := newShareSession(cfg)
+	if err != nil {
+		return err
+	}
+	it, err := r.GetItemByName(name)
+	if err != nil {
+		return err
+	}
+	if it.Version == 0 || len(it.BaseVersions) > 0 {
+		return ErrItemNotSynced
+	}
+	if it.BlobID != "" {
+		return errors.New("файлы нельзя открыть другим пользователям")
+	}
+	// получатель должен видеть и ключ отправителя: публикуем свою пару, если ещё не
+	if _, err := s.publishKeys(cfg); err != nil {
+		return fmt.Errorf("publish keys: %w", err)
+	}
+	pub, err := s.publicKey(cfg, recipient)
+	if err != nil {
+		return err
+	}
+	return s.putShare(cfg, it, recipient, pub)
+}
+
+// publicKey возвращает опубликованный открытый ключ пользователя login.
+func (s *shareSession) publicKey(cfg *config.Config, login string) ([]byte, error) {
+	resp, body, err := api.GetJSON(apiURL(cfg, "/api/users/"+url.PathEscape(login)+"/public-key"), s.token)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("пользователь %q не найден или ещё не опубликовал ключ (ему нужно выполнить sync)", login)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, api.StatusError(resp, body)
+	}
+	var pk publicKeyResponse
+	if err := json.Unmarshal(body, &pk); err != nil {
+		return nil, fmt.Errorf("decode public key: %w", err)
+	}
+	return pk.PublicKey, nil
+}
+
+// putShare собирает долю записи it для получателя и отправляет её на сервер.
+func (s *shareSession) putShare(cfg *config.Config, it *model.Item, recipient string, recipientPub []byte) error {
+	req, err := buildShare(it, s.vault, recipientPub)
+	if err != nil {
+		return err
+	}
+	resp, body, err := api.SendJSON(http.MethodPut, shareURL(cfg, it.ID, recipient), req, s.token)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusNoContent {
+		return historyStatusError(resp, body)
+	}
+	return nil
+}
+
+// RevokeShare закрывает пользователю recipient доступ к записи name.
+func RevokeShare(cfg *config.Config, r crepo.ItemRepository, name, recipient string) error {
+	it, token, err := historyTarget(cfg, r, name)
+	if err != nil {
+		return err
+	}
+	resp, body, err := api.SendJSON(http.MethodDelete, shareURL(cfg, it.ID, recipient), nil, token)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusNoContent {
+		return historyStatusError(resp, body)
+	}
+	return nil
+}
+
+// SyncShares публикует ключи пользователя, перешифровывает устаревшие доли его записей
+// (запись изменилась или получатель сменил ключ) и обновляет локальную копию записей,
+// открытых пользователю другими. Долю перешифровывает только клиент с той же версией записи.
+func SyncShares(cfg *config.Config, r crepo.ItemRepository) (ShareSyncResult, error) {
+	var res ShareSyncResult
+	shared, ok := SharedItems(r)
+	if !ok {
+		return res, errors.New("local storage does not support shared items")
+	}
+	s, err := newShareSession(cfg)
+	if err != nil {
+		return res, err
+	}
+	if _, err := s.publishKeys(cfg); err != nil {
+		return res, fmt.Errorf("publish keys: %w", err)
+	}
+
+	resp, body, err := api.GetJSON(apiURL(cfg, "/api/shares/outgoing"), s.token)
+	if err != nil {
+		return res, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return res, api.StatusError(resp, body)
+	}
+	var outgoing []outgoingShare
+	if err := json.Unmarshal(body, &outgoing); err != nil {
+		return res, fmt.Errorf("decode outgoing shares: %w", err)
+	}
+	for _, o := range outgoing {
+		if o.Version >= o.ItemVersion {
+			continue
+		}
+		it, err := r.GetItemByName(o.Name)
+		if err != nil || it.ID != o.ItemID || it.Version != o.ItemVersion || len(it.BaseVersions) > 0 {
+			continue
+		}
+		// ошибка одной доли не мешает остальным: повторим при следующей синхронизации
+		if err := s.putShare(cfg, it, o.Recipient, o.RecipientPublicKey); err == nil {
+			res.Resealed++
+		}
+	}
+
+	resp, body, err = api.GetJSON(apiURL(cfg, "/api/shares"), s.token)
+	if err != nil {
+		return res, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return res, api.StatusError(resp, body)
+	}
+	var incoming []incomingShare
+	if err := json.Unmarshal(body, &incoming); err != nil {
+		return res, fmt.Errorf("decode shares: %w", err)
+	}
+	items := make([]model.SharedItem, 0, len(incoming))
+	for _, in := range incoming {
+		items = append(items, in.toLocal())
+	}
+	if err := shared.ReplaceSharedItems(items); err != nil {
+		return res, fmt.Errorf("save shared items: %w", err)
+	}
+	res.Incoming = len(items)
+	return res, nil
+}
+
+// DecryptSharedItem открывает ключ общей записи закрытым ключом пользователя
+// и расшифровывает её поля для отображения.
+func DecryptSharedItem(s *model.SharedItem) (*view.DecryptedItem, error) {
+	login, err := (fsrepo.AuthFSStore{}).LoadLogin()
+	if err != nil {
+		return nil, fmt.Errorf("нет активного пользователя: выполните login/register: %w", err)
+	}
+	vault, err := crypto.LoadOrCreateKey(login)
+	if err != nil {
+		return nil, err
+	}
+	kp, err := crypto.LoadOrCreateKeyPair(login, vault)
+	if err != nil {
+		return nil, err
+	}
+	key, err := crypto.OpenKey(kp.Private, s.EphemeralKey, s.WrappedKey, s.WrappedKeyNonce)
+	if err != nil {
+		return nil, fmt.Errorf("open shared item key: %w", err)
+	}
+	return &view.DecryptedItem{
+		ID:        s.ItemID,
+		Name:      s.Name,
+		UpdatedAt: s.UpdatedAt,
+		Version:   s.Version,
+		Login:     decryptField(s.LoginCipher, s.LoginNonce, key),
+		Password:  decryptField(s.PasswordCipher, s.PasswordNonce, key),
+		Text:      decryptField(s.TextCipher, s.TextNonce, key),
+		Card:      decryptField(s.CardCipher, s.CardNonce, key),
+		FileName:  "<not set>",
+	}, nil
+}
+
+// decryptField расшифровывает поле для отображения: "<not set>" — поле пусто.
+func decryptField(cipher, nonce, key []byte) string {
+	if len(cipher) == 0 || len(nonce) == 0 {
+		return "<not set>"
+	}
+	plain, err := crypto.Decrypt(cipher, nonce, key)
+	if err != nil {
+		return "<decrypt error>"
+	}
+	return string(plain)
+}
+
+// buildShare перешифровывает поля записи it из ключа хранилища новым ключом записи
+// и запечатывает этот ключ открытым ключом получателя.
+func buildShare(it *model.Item, vault, recipientPub []byte) (*shareRequest, error) {
+	itemKey, err := crypto.NewItemKey()
+	if err != nil {
+		return nil, err
+	}
+	eph, wrapped, nonce, err := crypto.SealKey(recipientPub, itemKey)
+	if err != nil {
+		return nil, fmt.Errorf("seal item key: %w", err)
+	}
+	req := &shareRequest{Version: it.Version, EphemeralKey: eph, WrappedKey: wrapped, WrappedKeyNonce: nonce}
+	fields := []struct {
+		cipher, nonce       []byte
+		dstCipher, dstNonce *[]byte
+	}{
+		{it.LoginCipher, it.LoginNonce, &req.LoginCipher, &req.LoginNonce},
+		{it.PasswordCipher, it.PasswordNonce, &req.PasswordCipher, &req.PasswordNonce},
+		{it.TextCipher, it.TextNonce, &req.TextCipher, &req.TextNonce},
+		{it.CardCipher, it.CardNonce, &req.CardCipher, &req.CardNonce},
+	}
+	for _, f := range fields {
+		if len(f.cipher) == 0 || len(f.nonce) == 0 {
+			continue
+		}
+		plain, err := crypto.Decrypt(f.cipher, f.nonce, vault)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt item: %w", err)
+		}
+		c, n, err := crypto.Encrypt(plain, itemKey)
+		if err != nil {
+			return nil, err
+		}
+		*f.dstCipher, *f.dstNonce = c, n
+	}
+	return req, nil
+}
+
+func shareURL(cfg *config.Config, id, recipient string) string {
+	return itemURL(cfg, id, "shares/"+url.PathEscape(recipient))
+}
+
+func (in incomingShare) toLocal() model.SharedItem {
+	updated := time.Now().Unix()
+	if t, err := time.Parse(time.RFC3339, in.UpdatedAt); err == nil {
+		updated = t.Unix()
+	}
+	return model.SharedItem{
+		ItemID:          in.ItemID,
+		Name:            in.Name,
+		Owner:           in.Owner,
+		Version:         in.Version,
+		ItemVersion:     in.ItemVersion,
+		UpdatedAt:       updated,
+		EphemeralKey:    in.EphemeralKey,
+		WrappedKey:      in.WrappedKey,
+		WrappedKeyNonce: in.WrappedKeyNonce,
+		LoginCipher:     in.LoginCipher,
+		LoginNonce:      in.LoginNonce,
+		PasswordCipher:  in.PasswordCipher,
+		PasswordNonce:   in.PasswordNonce,
+		TextCipher:      in.TextCipher,
+		TextNonce:       in.TextNonce,
+		CardCipher:      in.CardCipher,
+		CardNonce:       in.CardNonce,
+	}
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	fsrepo "GophKeeper/internal/cli/repo/fs"
+	reposqlite "GophKeeper/internal/cli/repo/sqlite"
+	"GophKeeper/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeShareServer — имитация эндпоинтов обмена записями: пользователь определяется
+// по cookie auth_token=tok-<login>, версии записей задаёт тест.
+type fakeShareServer struct {
+	mu     sync.Mutex
+	keys   map[string]userKeys
+	items  map[string]fakeSharedItem
+	shares map[string]incomingShare // itemID/recipient
+}
+
+// fakeSharedItem — имя, владелец и текущая версия записи на сервере.
+type fakeSharedItem struct {
+	name, owner string
+	version     int64
+}
+
+func newFakeShareServer(t *testing.T) (*fakeShareServer, *httptest.Server) {
+	t.Helper()
+	f := &fakeShareServer{
+		keys:   map[string]userKeys{},
+		items:  map[string]fakeSharedItem{},
+		shares: map[string]incomingShare{},
+	}
+	mux := http.NewServeMux()
+	user := func(r *http.Request) string {
+		c, _ := r.Cookie("auth_token")
+		return strings.TrimPrefix(c.Value, "tok-")
+	}
+	mux.HandleFunc("GET /api/user/keys", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		k, ok := f.keys[user(r)]
+		if !ok {
+			http.Error(w, "keys not published", http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(k)
+	})
+	mux.HandleFunc("PUT /api/user/keys", func(w http.ResponseWriter, r *http.Request) {
+		var k userKeys
+		_ = json.NewDecoder(r.Body).Decode(&k)
+		f.mu.Lock()
+		f.keys[user(r)] = k
+		f.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /api/users/{login}/public-key", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		k, ok := f.keys[r.PathValue("login")]
+		if !ok {
+			http.Error(w, "public key not found", http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(publicKeyResponse{Login: r.PathValue("login"), PublicKey: k.PublicKey})
+	})
+	mux.HandleFunc("PUT /api/items/{id}/shares/{login}", func(w http.ResponseWriter, r *http.Request) {
+		var req shareRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		it := f.items[r.PathValue("id")]
+		if req.Version != it.version {
+			http.Error(w, "version mismatch", http.StatusConflict)
+			return
+		}
+		f.shares[r.PathValue("id")+"/"+r.PathValue("login")] = incomingShare{
+			ItemID: r.PathValue("id"), Name: it.name, Owner: user(r), shareRequest: req,
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("DELETE /api/items/{id}/shares/{login}", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		delete(f.shares, r.PathValue("id")+"/"+r.PathValue("login"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /api/shares", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		out := []incomingShare{}
+		for key, s := range f.shares {
+			if strings.HasSuffix(key, "/"+user(r)) {
+				s.ItemVersion = f.items[s.ItemID].version
+				out = append(out, s)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(out)
+	})
+	mux.HandleFunc("GET /api/shares/outgoing", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		out := []outgoingShare{}
+		for key, s := range f.shares {
+			if s.Owner != user(r) {
+				continue
+			}
+			recipient := key[strings.LastIndex(key, "/")+1:]
+			out = append(out, outgoingShare{ItemID: s.ItemID, Name: s.Name, Recipient: recipient,
+				RecipientPublicKey: f.keys[recipient].PublicKey, Version: s.Version, ItemVersion: f.items[s.ItemID].version})
+		}
+		_ = json.NewEncoder(w).Encode(out)
+	})
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return f, ts
+}
+
+// useAccount делает login текущим пользователем CLI и открывает его локальную БД.
+func useAccount(t *testing.T, login string) *reposqlite.ItemRepositorySQLite {
+	t.Helper()
+	require.NoError(t, (fsrepo.AuthFSStore{}).Save("tok-"+login))
+	require.NoError(t, (fsrepo.AuthFSStore{}).SaveLogin(login))
+	r, _, err := reposqlite.OpenForUser(login)
+	require.NoError(t, err)
+	require.NoError(t, r.Migrate())
+	t.Cleanup(func() { _ = r.Close() })
+	return r
+}
+
+func TestShares_ShareResealRevoke(t *testing.T) {
+	setupUserEnv(t)
+	f, ts := newFakeShareServer(t)
+	cfg := &config.Config{ServerURL: ts.URL}
+
+	// получатель публикует ключ при синхронизации
+	bob := useAccount(t, "bob")
+	res, err := SyncShares(cfg, bob)
+	require.NoError(t, err)
+	assert.Equal(t, 0, res.Incoming)
+	require.Contains(t, f.keys, "bob")
+
+	alice := useAccount(t, "alice")
+	svc := NewItemServiceLocal(alice)
+	id, _, err := svc.Edit("wifi", "password", []string{"s3cret"})
+	require.NoError(t, err)
+	// несинхронизированную запись открыть нельзя
+	assert.ErrorIs(t, ShareItem(cfg, alice, "wifi", "bob"), ErrItemNotSynced)
+	require.NoError(t, alice.SetServerVersion(id, 1))
+	f.items[id] = fakeSharedItem{"wifi", "alice", 1}
+
+	err = ShareItem(cfg, alice, "wifi", "nobody")
+	assert.ErrorContains(t, err, "nobody")
+	require.NoError(t, ShareItem(cfg, alice, "wifi", "bob"))
+
+	bob = useAccount(t, "bob")
+	res, err = SyncShares(cfg, bob)
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Incoming)
+	shared, err := bob.GetSharedItem("", "wifi")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", shared.Owner)
+	dto, err := DecryptSharedItem(shared)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", dto.Password)
+	assert.Equal(t, "<not set>", dto.Login)
+
+	// владелец изменил запись: доля перешифровывается при его синхронизации
+	alice = useAccount(t, "alice")
+	_, _, err = NewItemServiceLocal(alice).Edit("wifi", "password", []string{"n3w"})
+	require.NoError(t, err)
+	require.NoError(t, alice.SetServerVersion(id, 2))
+	f.items[id] = fakeSharedItem{"wifi", "alice", 2}
+	res, err = SyncShares(cfg, alice)
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Resealed)
+
+	bob = useAccount(t, "bob")
+	_, err = SyncShares(cfg, bob)
+	require.NoError(t, err)
+	shared, err = bob.GetSharedItem("alice", "wifi")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), shared.Version)
+	dto, err = DecryptSharedItem(shared)
+	require.NoError(t, err)
+	assert.Equal(t, "n3w", dto.Password)
+
+	// отзыв убирает запись у получателя
+	alice = useAccount(t, "alice")
+	require.NoError(t, RevokeShare(cfg, alice, "wifi", "bob"))
+	bob = useAccount(t, "bob")
+	res, err = SyncShares(cfg, bob)
+	require.NoError(t, err)
+	assert.Equal(t, 0, res.Incoming)
+	list, err := bob.ListSharedItems()
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestPublishKeys_RestoresPublishedPair(t *testing.T) {
+	setupUserEnv(t)
+	f, ts := newFakeShareServer(t)
+	cfg := &config.Config{ServerURL: ts.URL}
+	useAccount(t, "user1")
+
+	kp1, err := PublishKeys(cfg)
+	require.NoError(t, err)
+	// повторная публикация не меняет ключ и не отправляет его заново
+	f.keys["user1"] = userKeys{PublicKey: kp1.Public, WrappedPrivateKey: kp1.WrappedPrivate, PrivateKeyNonce: kp1.Nonce}
+	kp2, err := PublishKeys(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, kp1.Public, kp2.Public)
+}
@@ -7,9 +7,12 @@ import (
 	fsrepo "GophKeeper/internal/cli/repo/fs"
 	"GophKeeper/internal/config"
 	"context"
-	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
 	"time"
 )
 
@@ -29,12 +32,19 @@ type syncChange struct {
 	TextNonce      []byte  `json:"text_nonce,omitempty"`
 	CardCipher     []byte  `json:"card_cipher,omitempty"`
 	CardNonce      []byte  `json:"card_nonce,omitempty"`
+	// BaseVersions — серверные версии, от которых начаты локальные правки групп полей
+	BaseVersions map[string]int64 `json:"base_versions,omitempty"`
 }
 
 type syncRequest struct {
-	LastSyncAt string       `json:"last_sync_at,omitempty"`
-	Changes    []syncChange `json:"changes"`
-	Resolve    *string      `json:"resolve,omitempty"`
+	Cursor  string       `json:"cursor,omitempty"`
+	Limit   int          `json:"limit,omitempty"`
+	Changes []syncChange `json:"changes"`
+	Resolve *string      `json:"resolve,omitempty"`
+	// Resolutions — стратегии для отдельных записей: id → client|server|both
+	Resolutions map[string]string `json:"resolutions,omitempty"`
+	// Atomic — сервер применяет changes в одной транзакции: все или ни одного
+	Atomic bool `json:"atomic,omitempty"`
 }
 
 type appliedDTO struct {
@@ -43,22 +53,39 @@ type appliedDTO struct {
 }
 
 type conflictDTO struct {
-	ID         string         `json:"id"`
-	Reason     string         `json:"reason"`
-	ServerItem map[string]any `json:"server_item,omitempty"`
+	ID         string          `json:"id"`
+	Reason     string          `json:"reason"`
+	ServerItem *HistoryVersion `json:"server_item,omitempty"`
+	Fields     []string        `json:"fields,omitempty"`     // поля, изменённые и локально, и на сервере
+	LocalCopy  string          `json:"local_copy,omitempty"` // копия локальной версии (resolve=both)
 }
 
 type syncResponse struct {
 	Applied       []appliedDTO     `json:"applied"`
 	Conflicts     []conflictDTO    `json:"conflicts"`
-	ServerChanges []map[string]any `json:"server_changes"`
+	ServerChanges []HistoryVersion `json:"server_changes"`
+	Cursor        string           `json:"cursor,omitempty"`
+	HasMore       bool             `json:"has_more"`
 	ServerTime    string           `json:"server_time"`
+	// Collections — коллекции организаций пользователя; приходят на последней странице
+	Collections *[]collectionSnapshot `json:"collections,omitempty"`
 }
 
-// SyncItemToServer отправляет один item на сервер через /api/items/sync.
+// fullSyncCursor — курсор, с которым сервер возвращает все записи пользователя.
+const fullSyncCursor = "0"
+
+// validStrategy проверяет стратегию разрешения конфликтов: client|server|both.
+func validStrategy(s string) bool {
+	return s == "client" || s == "server" || s == "both"
+}
+
+// syncPageSize — сколько server_changes запрашивать за одну страницу синхронизации.
+const syncPageSize = 500
+
+// SyncItemToServer отправляет один item на сервер через /api/items/sync (или gRPC Sync).
 // isNew указывает, что запись только что создана локально — в этом случае отправляем version=0.
 // Возвращает (applied, newVersion, conflictsText, err).
-// resolve: nil (по умолчанию), либо указатель на строку "client" или "server"
+// resolve: nil (по умолчанию), либо указатель на строку "client", "server" или "both"
 // Возвращает: applied, newVersion (если применено), serverVersion (если конфликт и сервер версию вернул), conflictsText, err
 func SyncItemToServer(cfg *config.Config, item model.Item, isNew bool, resolve *string) (bool, int64, int64, string, error) {
 	if cfg == nil {
@@ -69,68 +96,19 @@ func SyncItemToServer(cfg *config.Config, item model.Item, isNew bool, resolve *
 	if err != nil {
 		return false, 0, 0, "", fmt.Errorf("нет токена авторизации: %w", err)
 	}
-	// собираем change
-	chg := syncChange{ID: item.ID}
-	// Версия: для новой записи — 0, иначе локальная версия
-	if isNew {
-		v := int64(0)
-		chg.Version = &v
-	} else {
-		v := item.Version
-		chg.Version = &v
-	}
-	if item.Name != "" {
-		n := item.Name
-		chg.Name = &n
-	}
-	if item.FileName != "" {
-		fn := item.FileName
-		chg.FileName = &fn
-	}
-	if item.BlobID != "" {
-		bid := item.BlobID
-		chg.BlobID = &bid
-	}
-	// Зашифрованные поля (если есть значения)
-	if len(item.LoginCipher) > 0 {
-		chg.LoginCipher = item.LoginCipher
-	}
-	if len(item.LoginNonce) > 0 {
-		chg.LoginNonce = item.LoginNonce
-	}
-	if len(item.PasswordCipher) > 0 {
-		chg.PasswordCipher = item.PasswordCipher
-	}
-	if len(item.PasswordNonce) > 0 {
-		chg.PasswordNonce = item.PasswordNonce
-	}
-	if len(item.TextCipher) > 0 {
-		chg.TextCipher = item.TextCipher
-	}
-	if len(item.TextNonce) > 0 {
-		chg.TextNonce = item.TextNonce
-	}
-	if len(item.CardCipher) > 0 {
-		chg.CardCipher = item.CardCipher
-	}
-	if len(item.CardNonce) > 0 {
-		chg.CardNonce = item.CardNonce
-	}
+	chg := changeFromItem(item, isNew)
 
 	payload := syncRequest{Changes: []syncChange{chg}}
-	if resolve != nil && (*resolve == "client" || *resolve == "server") {
+	if resolve != nil && validStrategy(*resolve) {
 		payload.Resolve = resolve
 	}
-	url := cfg.ServerURL + "/api/items/sync"
-	resp, body, err := api.PostJSON(url, payload, token)
+	pages, closePages, err := openSyncPages(context.Background(), cfg, token)
 	if err != nil {
 		return false, 0, 0, "", err
 	}
-	if resp.StatusCode != 200 {
-		return false, 0, 0, "", fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
-	}
-	var sr syncResponse
-	if err := json.Unmarshal(body, &sr); err != nil {
+	defer closePages()
+	sr, err := pages(payload)
+	if err != nil {
 		return false, 0, 0, "", err
 	}
 	if len(sr.Applied) > 0 {
@@ -141,18 +119,7 @@ func SyncItemToServer(cfg *config.Config, item model.Item, isNew bool, resolve *
 		// попробуем вытащить серверную версию из первого конфликта, если она есть
 		var serverVer int64
 		if si := sr.Conflicts[0].ServerItem; si != nil {
-			if v, ok := si["version"]; ok {
-				switch vv := v.(type) {
-				case float64:
-					serverVer = int64(vv)
-				case int64:
-					serverVer = vv
-				case json.Number:
-					if iv, err := vv.Int64(); err == nil {
-						serverVer = iv
-					}
-				}
-			}
+			serverVer = si.Version
 		}
 		b, _ := json.Marshal(sr.Conflicts)
 		return false, 0, serverVer, string(b), nil
@@ -160,6 +127,60 @@ func SyncItemToServer(cfg *config.Config, item model.Item, isNew bool, resolve *
 	return false, 0, 0, "", nil
 }
 
+// changeFromItem собирает изменение для /api/items/sync из локальной записи.
+// Для новой записи (isNew) отправляется version=0 и все заполненные поля.
+// Если у синхронизированной записи есть локальные правки (BaseVersions), отправляются
+// только изменённые группы полей вместе с базовыми версиями — сервер сольёт их
+// с изменениями других устройств и сообщит о конфликте лишь по полям, изменённым обеими сторонами.
+func changeFromItem(item model.Item, isNew bool) syncChange {
+	chg := syncChange{ID: item.ID}
+	v := item.Version
+	if isNew {
+		v = 0
+	}
+	chg.Version = &v
+
+	edited := func(field string) bool { return true }
+	if v > 0 && len(item.BaseVersions) > 0 {
+		chg.BaseVersions = item.BaseVersions
+		edited = func(field string) bool {
+			_, ok := item.BaseVersions[field]
+			return ok
+		}
+	} else if item.Name != "" {
+		n := item.Name
+		chg.Name = &n
+	}
+	if edited(model.FieldFile) {
+		if item.FileName != "" {
+			fn := item.FileName
+			chg.FileName = &fn
+		}
+		if item.BlobID != "" {
+			bid := item.BlobID
+			chg.BlobID = &bid
+		}
+	}
+	// Зашифрованные поля (если есть значения)
+	if edited(model.FieldLogin) && len(item.LoginCipher) > 0 {
+		chg.LoginCipher = item.LoginCipher
+		chg.LoginNonce = item.LoginNonce
+	}
+	if edited(model.FieldPassword) && len(item.PasswordCipher) > 0 {
+		chg.PasswordCipher = item.PasswordCipher
+		chg.PasswordNonce = item.PasswordNonce
+	}
+	if edited(model.FieldText) && len(item.TextCipher) > 0 {
+		chg.TextCipher = item.TextCipher
+		chg.TextNonce = item.TextNonce
+	}
+	if edited(model.FieldCard) && len(item.CardCipher) > 0 {
+		chg.CardCipher = item.CardCipher
+		chg.CardNonce = item.CardNonce
+	}
+	return chg
+}
+
 // SyncItemByName загружает локальный item по имени и синхронизирует его на сервере.
 func SyncItemByName(cfg *config.Config, r crepo.ItemRepository, name string, isNew bool, resolve *string) (bool, int64, string, error) {
 	it, err := r.GetItemByName(name)
@@ -171,6 +192,14 @@ func SyncItemByName(cfg *config.Config, r crepo.ItemRepository, name string, isN
 		return false, 0, conflicts, syncErr
 	}
 	if applied {
+		// Правки по полям могли быть слиты с изменениями других устройств —
+		// заберём итоговое состояние записи целиком
+		if it.Version > 0 && len(it.BaseVersions) > 0 {
+			if err := refreshItemFromServer(cfg, r, it); err != nil {
+				return applied, newVer, conflicts, fmt.Errorf("failed to fetch merged item: %w", err)
+			}
+			return applied, newVer, conflicts, nil
+		}
 		// После успешного применения на сервере — зафиксировать серверную версию локально
 		if err := r.SetServerVersion(it.ID, newVer); err != nil {
 			// Не считаем это фатальной ошибкой отправки: версию можно синхронизировать позже
@@ -179,103 +208,45 @@ func SyncItemByName(cfg *config.Config, r crepo.ItemRepository, name string, isN
 		}
 		return applied, newVer, conflicts, nil
 	}
-	// Если не применено и запрошено resolve=server — применяем полный server_item (если пришёл) и выравниваем версию
-	if resolve != nil && *resolve == "server" && conflicts != "" {
+	// Если не применено и запрошено resolve=server|both — применяем полный server_item (если пришёл) и выравниваем версию;
+	// при both локальная версия предварительно сохраняется копией
+	if resolve != nil && (*resolve == "server" || *resolve == "both") && conflicts != "" {
 		var confs []conflictDTO
 		if err := json.Unmarshal([]byte(conflicts), &confs); err == nil {
 			// Соберём blob_id для последующей догрузки (если локально отсутствуют)
 			pendingBlobIDs := make(map[string]struct{})
-			for _, c := range confs {
+			var copyErr error
+			var serverItems []model.Item
+			for i, c := range confs {
 				if c.ServerItem == nil {
 					continue
 				}
-				// построим client model.Item из server_item
-				sit := c.ServerItem
-				// обязательные поля
-				sid, _ := sit["id"].(string)
-				sname, _ := sit["name"].(string)
-				sfile, _ := sit["file_name"].(string)
-				// версия
-				var sver int64
-				switch v := sit["version"].(type) {
-				case float64:
-					sver = int64(v)
-				case int64:
-					sver = v
-				case json.Number:
-					if iv, err := v.Int64(); err == nil {
-						sver = iv
-					}
-				}
-				// updated_at
-				updUnix := time.Now().Unix()
-				if us, ok := sit["updated_at"].(string); ok {
-					if t, err := time.Parse(time.RFC3339, us); err == nil {
-						updUnix = t.Unix()
-					}
-				}
-				// blob_id
-				blobID := ""
-				if braw, ok := sit["blob_id"]; ok && braw != nil {
-					switch bv := braw.(type) {
-					case string:
-						blobID = bv
-					case *string:
-						if bv != nil {
-							blobID = *bv
-						}
-					}
-				}
-				// шифр-поля: сервер отдаёт []byte как base64-строки в JSON; поддержим также []byte на всякий случай
-				toBytes := func(m map[string]any, key string) []byte {
-					if val, ok := m[key]; ok && val != nil {
-						switch vv := val.(type) {
-						case string:
-							// base64 → []byte
-							b, err := base64.StdEncoding.DecodeString(vv)
-							if err == nil {
-								return b
-							}
-						case []byte:
-							return vv
-						}
-					}
-					return nil
-				}
-				itm := model.Item{
-					ID:             sid,
-					Name:           sname,
-					CreatedAt:      updUnix,
-					UpdatedAt:      updUnix,
-					Version:        sver,
-					Deleted:        false,
-					FileName:       sfile,
-					BlobID:         blobID,
-					LoginCipher:    toBytes(sit, "login_cipher"),
-					LoginNonce:     toBytes(sit, "login_nonce"),
-					PasswordCipher: toBytes(sit, "password_cipher"),
-					PasswordNonce:  toBytes(sit, "password_nonce"),
-					TextCipher:     toBytes(sit, "text_cipher"),
-					TextNonce:      toBytes(sit, "text_nonce"),
-					CardCipher:     toBytes(sit, "card_cipher"),
-					CardNonce:      toBytes(sit, "card_nonce"),
-				}
-				// deleted
-				if del, ok := sit["deleted"].(bool); ok {
-					itm.Deleted = del
+				itm := c.ServerItem.toLocal(0)
+				if itm.ID == "" {
+					continue
 				}
-				// применяем локально
-				if sid != "" {
-					_ = r.UpsertFullFromServer(itm)
-					// выравниваем версию
-					_ = r.SetServerVersion(itm.ID, itm.Version)
-					// проверим blob_id
-					if blobID != "" {
-						if _, err := r.GetBlobByID(blobID); err != nil {
-							pendingBlobIDs[blobID] = struct{}{}
-						}
+				if *resolve == "both" {
+					copyName, err := keepBoth(cfg, r, name)
+					if copyName == "" {
+						// без копии не трогаем локальную версию
+						return applied, newVer, conflicts, err
 					}
+					confs[i].LocalCopy = copyName
+					copyErr = err
 				}
+				serverItems = append(serverItems, itm)
+			}
+			// применяем локально вместе с серверной версией в одной транзакции;
+			// версии в versions разрешают снимку заменить локальные правки
+			versions := make(map[string]int64, len(serverItems))
+			for _, itm := range serverItems {
+				versions[itm.ID] = itm.Version
+			}
+			if err := r.ApplySyncBatch(versions, serverItems); err != nil {
+				return applied, newVer, conflicts, fmt.Errorf("apply server item: %w", err)
+			}
+			for _, itm := range serverItems {
+				collectMissingBlob(r, itm, pendingBlobIDs)
 			}
 			if len(pendingBlobIDs) > 0 {
 				ids := make([]string, 0, len(pendingBlobIDs))
@@ -284,11 +255,31 @@ func SyncItemByName(cfg *config.Config, r crepo.ItemRepository, name string, isN
 				}
 				QueueBlobsForDownload(ids)
 			}
+			if b, err := json.Marshal(confs); err == nil {
+				conflicts = string(b)
+			}
+			if copyErr != nil {
+				return applied, newVer, conflicts, copyErr
+			}
 		}
 	}
 	return applied, newVer, conflicts, nil
 }
 
+// keepBoth сохраняет локальную версию записи name копией (см. KeepBothCopy)
+// и сразу отправляет копию на сервер как новую запись. Возвращает имя копии;
+// если отправка не удалась, копия остаётся локально и уйдёт при следующей синхронизации.
+func keepBoth(cfg *config.Config, r crepo.ItemRepository, name string) (string, error) {
+	copyName, err := KeepBothCopy(r, name, time.Now())
+	if err != nil {
+		return "", fmt.Errorf("keep local version of %q: %w", name, err)
+	}
+	if _, _, _, err := SyncItemByName(cfg, r, copyName, true, nil); err != nil {
+		return copyName, fmt.Errorf("upload conflict copy %q: %w", copyName, err)
+	}
+	return copyName, nil
+}
+
 // UploadResult результат асинхронной загрузки блоба
 type UploadResult struct {
 	BlobID  string
@@ -318,6 +309,15 @@ func UploadBlobAsync(cfg *config.Config, r crepo.ItemRepository, blobID string)
 			out <- UploadResult{BlobID: blobID, Err: err}
 			return
 		}
+		if useGRPC(cfg) {
+			created, err := uploadBlobGRPC(context.Background(), cfg, token, b)
+			if err != nil {
+				out <- UploadResult{BlobID: blobID, Err: err}
+				return
+			}
+			out <- UploadResult{BlobID: blobID, Created: created, Size: len(b.Cipher)}
+			return
+		}
 		url := cfg.ServerURL + "/api/blobs/upload"
 		resp, body, err := api.PostMultipartBlob(url, b.ID, b.Cipher, b.Nonce, token)
 		if err != nil {
@@ -331,10 +331,10 @@ func UploadBlobAsync(cfg *config.Config, r crepo.ItemRepository, blobID string)
 		case 201:
 			created = true
 		case 400, 401, 413:
-			out <- UploadResult{BlobID: blobID, Err: fmt.Errorf("upload failed: %s", string(body))}
+			out <- UploadResult{BlobID: blobID, Err: errors.New(api.WithRequestID("upload failed: "+string(body), resp))}
 			return
 		default:
-			out <- UploadResult{BlobID: blobID, Err: fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))}
+			out <- UploadResult{BlobID: blobID, Err: api.StatusError(resp, body)}
 			return
 		}
 		out <- UploadResult{BlobID: blobID, Created: created, Size: len(b.Cipher), Err: nil}
@@ -353,7 +353,38 @@ func QueueBlobsForDownload(ids []string) {
 // BatchSyncOptions задаёт параметры пакетной синхронизации
 type BatchSyncOptions struct {
 	All     bool    // если true — использовать last_sync_at с эпохи
-	Resolve *string // опциональная стратегия для всех конфликтов: client|server
+	Resolve *string // опциональная стратегия для всех конфликтов: client|server|both
+	// Resolutions — стратегии для отдельных записей (id → client|server|both); важнее Resolve
+	Resolutions map[string]string
+	// Atomic — попросить сервер применить батч целиком или не применять ничего
+	Atomic bool
+	// ChangedOnly — отправлять только новые и изменённые локально записи
+	// (без него отправляются все записи, что поднимает их серверные версии)
+	ChangedOnly bool
+}
+
+// strategyFor возвращает стратегию разрешения конфликта для записи id ("" — не задана).
+func (o BatchSyncOptions) strategyFor(id string) string {
+	if v, ok := o.Resolutions[id]; ok {
+		return v
+	}
+	if o.Resolve != nil {
+		return *o.Resolve
+	}
+	return ""
+}
+
+// ReasonAtomicAborted — изменение без собственного конфликта не применено сервером,
+// потому что атомарный батч откатен из-за конфликтов в других изменениях.
+const ReasonAtomicAborted = "atomic_aborted"
+
+// SyncConflict — конфликт, оставшийся неразрешённым после синхронизации.
+type SyncConflict struct {
+	ID            string
+	Name          string   // локальное имя записи (или серверное, если локально не найдено)
+	Reason        string   // причина: version_conflict, not_found, quota_exceeded, ...
+	ServerVersion int64    // версия записи на сервере, если сервер её сообщил
+	Fields        []string // поля, изменённые обеими сторонами (при слиянии по полям)
 }
 
 // BatchSyncResult результат пакетной синхронизации
@@ -361,35 +392,39 @@ type BatchSyncResult struct {
 	AppliedCount  int
 	ServerUpserts int
 	ConflictsJSON string
-	QueuedBlobIDs []string
-	ServerTime    string
-	Err           error
+	Conflicts     []SyncConflict
+	// ConflictCopies — имена копий локальных версий, созданных стратегией both
+	ConflictCopies []string
+	QueuedBlobIDs  []string
+	ServerTime     string
+	// Collections — сколько коллекций организаций доступно пользователю после синхронизации
+	Collections int
+	Err         error
 }
 
-// RunSyncBatch выполняет пакетную синхронизацию всех локальных записей с сервером.
-// Простой вариант: отправляем все локальные записи как changes; также указываем last_sync_at
-// (эпоха при opts.All или прочитанное из конфигурации пользователя), применяем server_changes локально.
+// RunSyncBatch выполняет пакетную синхронизацию всех локальных записей с сервером
+// через транспорт cfg.Transport (HTTP или gRPC-поток Sync).
+// Простой вариант: отправляем все локальные записи как changes; также указываем курсор
+// (fullSyncCursor при opts.All или сохранённый в конфигурации пользователя).
+// server_changes приходят страницами: пока сервер отвечает has_more, запрашиваем
+// следующую страницу с новым курсором и применяем каждую в отдельной транзакции.
 func RunSyncBatch(ctx context.Context, cfg *config.Config, r crepo.ItemRepository, opts BatchSyncOptions) BatchSyncResult {
 	// Загрузка токена
 	token, err := (fsrepo.AuthFSStore{}).Load()
 	if err != nil {
 		return BatchSyncResult{Err: fmt.Errorf("нет токена авторизации: %w", err)}
 	}
-	// Определим логин пользователя (для хранения last_sync_at в пользовательском конфиге)
+	// Определим логин пользователя (для хранения курсора в пользовательском конфиге)
 	login, lerr := (fsrepo.AuthFSStore{}).LoadLogin()
 	if lerr != nil {
 		return BatchSyncResult{Err: fmt.Errorf("нет активного пользователя: %w", lerr)}
 	}
 
-	// last_sync_at
-	lastSyncAt := ""
-	if opts.All {
-		lastSyncAt = "1970-01-01T00:00:00Z"
-	} else {
-		if v, err := fsrepo.LoadLastSyncAt(login); err == nil && v != "" {
-			lastSyncAt = v
-		} else {
-			lastSyncAt = "1970-01-01T00:00:00Z"
+	// Курсор: без сохранённого курсора запрашиваем все изменения
+	cursor := fullSyncCursor
+	if !opts.All {
+		if v, err := fsrepo.LoadSyncCursor(login); err == nil && v != "" {
+			cursor = v
 		}
 	}
 
@@ -399,281 +434,231 @@ func RunSyncBatch(ctx context.Context, cfg *config.Config, r crepo.ItemRepositor
 		return BatchSyncResult{Err: err}
 	}
 	changes := make([]syncChange, 0, len(items))
+	nameByID := make(map[string]string, len(items))
 	for _, meta := range items {
+		nameByID[meta.ID] = meta.Name
 		// Берём полную запись (включая зашифрованные поля)
 		it, gerr := r.GetItemByName(meta.Name)
 		if gerr != nil {
 			// пропустим одну запись, но продолжим остальные
 			continue
 		}
-		ch := syncChange{ID: it.ID}
-		v := it.Version
-		ch.Version = &v
-		if it.Name != "" {
-			n := it.Name
-			ch.Name = &n
-		}
-		if it.FileName != "" {
-			fn := it.FileName
-			ch.FileName = &fn
-		}
-		if it.BlobID != "" {
-			bid := it.BlobID
-			ch.BlobID = &bid
-		}
-		if len(it.LoginCipher) > 0 {
-			ch.LoginCipher = it.LoginCipher
-		}
-		if len(it.LoginNonce) > 0 {
-			ch.LoginNonce = it.LoginNonce
-		}
-		if len(it.PasswordCipher) > 0 {
-			ch.PasswordCipher = it.PasswordCipher
-		}
-		if len(it.PasswordNonce) > 0 {
-			ch.PasswordNonce = it.PasswordNonce
-		}
-		if len(it.TextCipher) > 0 {
-			ch.TextCipher = it.TextCipher
-		}
-		if len(it.TextNonce) > 0 {
-			ch.TextNonce = it.TextNonce
-		}
-		if len(it.CardCipher) > 0 {
-			ch.CardCipher = it.CardCipher
-		}
-		if len(it.CardNonce) > 0 {
-			ch.CardNonce = it.CardNonce
+		if opts.ChangedOnly && it.Version > 0 && len(it.BaseVersions) == 0 {
+			continue
 		}
+		ch := changeFromItem(*it, false)
 		changes = append(changes, ch)
 	}
 
-	payload := syncRequest{Changes: changes}
-	if lastSyncAt != "" {
-		payload.LastSyncAt = lastSyncAt
-	}
-	if opts.Resolve != nil && (*opts.Resolve == "client" || *opts.Resolve == "server") {
+	payload := syncRequest{Changes: changes, Cursor: cursor, Limit: syncPageSize, Atomic: opts.Atomic}
+	if opts.Resolve != nil && validStrategy(*opts.Resolve) {
 		payload.Resolve = opts.Resolve
 	}
-	url := cfg.ServerURL + "/api/items/sync"
-	resp, body, err := api.PostJSON(url, payload, token)
+	for id, strategy := range opts.Resolutions {
+		if validStrategy(strategy) {
+			if payload.Resolutions == nil {
+				payload.Resolutions = map[string]string{}
+			}
+			payload.Resolutions[id] = strategy
+		}
+	}
+	pages, closePages, err := openSyncPages(ctx, cfg, token)
 	if err != nil {
 		return BatchSyncResult{Err: err}
 	}
-	if resp.StatusCode != 200 {
-		return BatchSyncResult{Err: fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))}
-	}
-	var sr syncResponse
-	if err := json.Unmarshal(body, &sr); err != nil {
+	defer closePages()
+	sr, err := pages(payload)
+	if err != nil {
 		return BatchSyncResult{Err: err}
 	}
 
 	res := BatchSyncResult{}
-	// Applied count
 	res.AppliedCount = len(sr.Applied)
+	pending := map[string]struct{}{}
+
+	// Серверные версии принятых изменений и снимки записей, принятых по стратегии server|both,
+	// применяются вместе с первой страницей server_changes в одной транзакции SQLite.
+	// Снимки server_changes не заменяют записи с локальными правками (в том числе
+	// конфликтующие): правки сохраняются до выбора стратегии.
+	versions := make(map[string]int64, len(sr.Applied))
+	for _, a := range sr.Applied {
+		if a.ID != "" {
+			versions[a.ID] = a.NewVersion
+		}
+	}
+	var resolved []model.Item
 
 	// Обработка конфликтов
 	if len(sr.Conflicts) > 0 {
-		// Если resolve=server — применим полные server_item (если они присутствуют) локально
-		if opts.Resolve != nil && *opts.Resolve == "server" {
-			pending := map[string]struct{}{}
-			for _, c := range sr.Conflicts {
-				if c.ServerItem == nil {
-					continue
-				}
-				sit := c.ServerItem
-				sid, _ := sit["id"].(string)
-				sname, _ := sit["name"].(string)
-				sfile, _ := sit["file_name"].(string)
-				var sver int64
-				if v, ok := sit["version"]; ok {
-					switch vv := v.(type) {
-					case float64:
-						sver = int64(vv)
-					case int64:
-						sver = vv
-					case json.Number:
-						if iv, e := vv.Int64(); e == nil {
-							sver = iv
-						}
-					}
-				}
-				// updated_at
-				updUnix := time.Now().Unix()
-				if us, ok := sit["updated_at"].(string); ok {
-					if t, e := time.Parse(time.RFC3339, us); e == nil {
-						updUnix = t.Unix()
-					}
-				}
-				// blob_id
-				blobID := ""
-				if braw, ok := sit["blob_id"]; ok && braw != nil {
-					switch bv := braw.(type) {
-					case string:
-						blobID = bv
-					case *string:
-						if bv != nil {
-							blobID = *bv
+		for i, c := range sr.Conflicts {
+			// Стратегия server|both — применим полный server_item (если он присутствует) локально;
+			// при both локальная версия сначала сохраняется копией
+			strategy := opts.strategyFor(c.ID)
+			if (strategy == "server" || strategy == "both") && c.ServerItem != nil {
+				itm := c.ServerItem.toLocal(0)
+				if itm.ID != "" {
+					if localName := nameByID[c.ID]; strategy == "both" && localName != "" {
+						// ошибка выгрузки копии не критична: копия уйдёт со следующей синхронизацией
+						copyName, _ := keepBoth(cfg, r, localName)
+						if copyName == "" {
+							// без копии не трогаем локальную версию — конфликт остаётся
+							res.Conflicts = append(res.Conflicts, newSyncConflict(c, nameByID))
+							continue
 						}
+						res.ConflictCopies = append(res.ConflictCopies, copyName)
+						sr.Conflicts[i].LocalCopy = copyName
 					}
+					// версия снимка в versions заменяет локальные правки
+					resolved = append(resolved, itm)
+					versions[itm.ID] = itm.Version
+					continue
 				}
-				// helper для []byte
-				toBytes := func(m map[string]any, key string) []byte {
-					if val, ok := m[key]; ok && val != nil {
-						switch vv := val.(type) {
-						case string:
-							if b, e := base64.StdEncoding.DecodeString(vv); e == nil {
-								return b
-							}
-						case []byte:
-							return vv
-						}
-					}
-					return nil
-				}
-				itm := model.Item{
-					ID:             sid,
-					Name:           sname,
-					CreatedAt:      updUnix,
-					UpdatedAt:      updUnix,
-					Version:        sver,
-					Deleted:        false,
-					FileName:       sfile,
-					BlobID:         blobID,
-					LoginCipher:    toBytes(sit, "login_cipher"),
-					LoginNonce:     toBytes(sit, "login_nonce"),
-					PasswordCipher: toBytes(sit, "password_cipher"),
-					PasswordNonce:  toBytes(sit, "password_nonce"),
-					TextCipher:     toBytes(sit, "text_cipher"),
-					TextNonce:      toBytes(sit, "text_nonce"),
-					CardCipher:     toBytes(sit, "card_cipher"),
-					CardNonce:      toBytes(sit, "card_nonce"),
-				}
-				if del, ok := sit["deleted"].(bool); ok {
-					itm.Deleted = del
-				}
-				if sid != "" {
-					_ = r.UpsertFullFromServer(itm)
-					_ = r.SetServerVersion(itm.ID, itm.Version)
-					res.ServerUpserts++
-					if blobID != "" {
-						if _, e := r.GetBlobByID(blobID); e != nil {
-							pending[blobID] = struct{}{}
-						}
-					}
-				}
-			}
-			if len(pending) > 0 {
-				res.QueuedBlobIDs = make([]string, 0, len(pending))
-				for id := range pending {
-					res.QueuedBlobIDs = append(res.QueuedBlobIDs, id)
-				}
-				QueueBlobsForDownload(res.QueuedBlobIDs)
 			}
+			res.Conflicts = append(res.Conflicts, newSyncConflict(c, nameByID))
 		}
 		if b, e := json.Marshal(sr.Conflicts); e == nil {
 			res.ConflictsJSON = string(b)
 		}
 	}
 
-	// Применим server_changes локально
-	if len(sr.ServerChanges) > 0 {
-		pending := map[string]struct{}{}
+	// Применяем server_changes постранично: каждая страница — одна транзакция SQLite.
+	// Курсор сохраняем только после применения страницы, чтобы не пропустить изменения.
+	for {
+		page := make([]model.Item, 0, len(resolved)+len(sr.ServerChanges))
+		page = append(page, resolved...)
 		for _, sit := range sr.ServerChanges {
-			sid, _ := sit["id"].(string)
-			sname, _ := sit["name"].(string)
-			sfile, _ := sit["file_name"].(string)
-			// версия
-			var sver int64
-			if v, ok := sit["version"]; ok {
-				switch vv := v.(type) {
-				case float64:
-					sver = int64(vv)
-				case int64:
-					sver = vv
-				case json.Number:
-					if iv, e := vv.Int64(); e == nil {
-						sver = iv
-					}
-				}
-			}
-			// updated_at
-			updUnix := time.Now().Unix()
-			if us, ok := sit["updated_at"].(string); ok {
-				if t, e := time.Parse(time.RFC3339, us); e == nil {
-					updUnix = t.Unix()
-				}
-			}
-			// blob_id
-			blobID := ""
-			if braw, ok := sit["blob_id"]; ok && braw != nil {
-				switch bv := braw.(type) {
-				case string:
-					blobID = bv
-				case *string:
-					if bv != nil {
-						blobID = *bv
-					}
-				}
-			}
-			//[]byte из base64 строки
-			toBytes := func(m map[string]any, key string) []byte {
-				if val, ok := m[key]; ok && val != nil {
-					switch vv := val.(type) {
-					case string:
-						if b, e := base64.StdEncoding.DecodeString(vv); e == nil {
-							return b
-						}
-					case []byte:
-						return vv
-					}
-				}
-				return nil
-			}
-			itm := model.Item{
-				ID:             sid,
-				Name:           sname,
-				CreatedAt:      updUnix,
-				UpdatedAt:      updUnix,
-				Version:        sver,
-				Deleted:        false,
-				FileName:       sfile,
-				BlobID:         blobID,
-				LoginCipher:    toBytes(sit, "login_cipher"),
-				LoginNonce:     toBytes(sit, "login_nonce"),
-				PasswordCipher: toBytes(sit, "password_cipher"),
-				PasswordNonce:  toBytes(sit, "password_nonce"),
-				TextCipher:     toBytes(sit, "text_cipher"),
-				TextNonce:      toBytes(sit, "text_nonce"),
-				CardCipher:     toBytes(sit, "card_cipher"),
-				CardNonce:      toBytes(sit, "card_nonce"),
+			itm := sit.toLocal(0)
+			if itm.ID != "" {
+				page = append(page, itm)
 			}
-			if del, ok := sit["deleted"].(bool); ok {
-				itm.Deleted = del
+		}
+		if len(page) > 0 || len(versions) > 0 {
+			if err := r.ApplySyncBatch(versions, page); err != nil {
+				res.Err = fmt.Errorf("apply server changes: %w", err)
+				break
 			}
-			if sid != "" {
-				_ = r.UpsertFullFromServer(itm)
-				_ = r.SetServerVersion(itm.ID, itm.Version)
-				if blobID != "" {
-					if _, e := r.GetBlobByID(blobID); e != nil {
-						pending[blobID] = struct{}{}
-					}
-				}
+			res.ServerUpserts += len(resolved)
+			for _, itm := range page {
+				collectMissingBlob(r, itm, pending)
 			}
 		}
-		if len(pending) > 0 {
-			res.QueuedBlobIDs = make([]string, 0, len(pending))
-			for id := range pending {
-				res.QueuedBlobIDs = append(res.QueuedBlobIDs, id)
-			}
-			QueueBlobsForDownload(res.QueuedBlobIDs)
+		versions, resolved = nil, nil
+		if sr.Cursor != "" {
+			_ = fsrepo.SaveSyncCursor(login, sr.Cursor)
+		}
+		res.ServerTime = sr.ServerTime
+
+		// Сервер не вернул курсор или не сдвинул его — дальше запрашивать нечего
+		if !sr.HasMore || sr.Cursor == "" || sr.Cursor == payload.Cursor {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			res.Err = err
+			break
+		}
+		payload = syncRequest{Cursor: sr.Cursor, Limit: syncPageSize, Changes: []syncChange{}}
+		sr, err = pages(payload)
+		if err != nil {
+			res.Err = err
+			break
 		}
 	}
 
-	// Сохраним server_time как last_sync_at в конфиг пользователя
-	if sr.ServerTime != "" {
-		_ = fsrepo.SaveLastSyncAt(login, sr.ServerTime)
-		res.ServerTime = sr.ServerTime
+	if res.Err == nil && sr.Collections != nil {
+		if err := storeCollections(r, *sr.Collections); err != nil {
+			res.Err = fmt.Errorf("save collections: %w", err)
+		}
+		res.Collections = len(*sr.Collections)
+	}
+
+	if len(pending) > 0 {
+		res.QueuedBlobIDs = make([]string, 0, len(pending))
+		for id := range pending {
+			res.QueuedBlobIDs = append(res.QueuedBlobIDs, id)
+		}
+		QueueBlobsForDownload(res.QueuedBlobIDs)
 	}
 	return res
 }
+
+// refreshItemFromServer загружает текущее состояние записи с сервера через транспорт
+// cfg.Transport (GET /api/data/{id} или gRPC GetItem) и заменяет им локальную копию.
+func refreshItemFromServer(cfg *config.Config, r crepo.ItemRepository, it *model.Item) error {
+	token, err := (fsrepo.AuthFSStore{}).Load()
+	if err != nil {
+		return fmt.Errorf("нет токена авторизации: %w", err)
+	}
+	var sv HistoryVersion
+	if useGRPC(cfg) {
+		sv, err = getItemGRPC(context.Background(), cfg, token, it.ID)
+	} else {
+		sv, err = getItemHTTP(cfg, token, it.ID)
+	}
+	if err != nil {
+		return err
+	}
+	merged := sv.toLocal(it.CreatedAt)
+	if err := r.UpsertFullFromServer(merged); err != nil {
+		return err
+	}
+	if merged.BlobID != "" {
+		if _, err := r.GetBlobByID(merged.BlobID); err != nil {
+			QueueBlobsForDownload([]string{merged.BlobID})
+		}
+	}
+	return nil
+}
+
+// getItemHTTP загружает полный снимок записи id через GET /api/data/{id}.
+func getItemHTTP(cfg *config.Config, token, id string) (HistoryVersion, error) {
+	resp, body, err := api.GetJSON(strings.TrimRight(cfg.ServerURL, "/")+"/api/data/"+url.PathEscape(id), token)
+	if err != nil {
+		return HistoryVersion{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return HistoryVersion{}, api.StatusError(resp, body)
+	}
+	var sv HistoryVersion
+	if err := json.Unmarshal(body, &sv); err != nil {
+		return HistoryVersion{}, fmt.Errorf("decode item: %w", err)
+	}
+	return sv, nil
+}
+
+func newSyncConflict(c conflictDTO, nameByID map[string]string) SyncConflict {
+	sc := SyncConflict{ID: c.ID, Reason: c.Reason, Fields: c.Fields, Name: nameByID[c.ID]}
+	if c.ServerItem != nil {
+		srv := c.ServerItem.toLocal(0)
+		sc.ServerVersion = srv.Version
+		if sc.Name == "" {
+			sc.Name = srv.Name
+		}
+	}
+	return sc
+}
+
+// postSyncPage отправляет один запрос /api/items/sync и разбирает ответ.
+func postSyncPage(url string, payload syncRequest, token string) (*syncResponse, error) {
+	resp, body, err := api.PostJSON(url, payload, token)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		return nil, api.StatusError(resp, body)
+	}
+	var sr syncResponse
+	if err := json.Unmarshal(body, &sr); err != nil {
+		return nil, err
+	}
+	return &sr, nil
+}
+
+// collectMissingBlob добавляет blob_id записи в pending, если блоба ещё нет локально.
+func collectMissingBlob(r crepo.ItemRepository, itm model.Item, pending map[string]struct{}) {
+	if itm.BlobID == "" {
+		return
+	}
+	if _, e := r.GetBlobByID(itm.BlobID); e != nil {
+		pending[itm.BlobID] = struct{}{}
+	}
+}
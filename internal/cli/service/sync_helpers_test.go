@@ -12,6 +12,7 @@ import (
 	"GophKeeper/internal/config"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 // Дополнительные точечные тесты для добора покрытия слоя service (CLI)
@@ -60,20 +61,20 @@ func TestSyncItemToServer_NeutralResponse(t *testing.T) {
 	assert.Equal(t, "", conflicts)
 }
 
-func TestRunSyncBatch_AllFlag_UsesEpoch_And_SkipsBadItem(t *testing.T) {
+func TestRunSyncBatch_AllFlag_UsesFullCursor_And_SkipsBadItem(t *testing.T) {
 	setupUserEnv(t)
-	// сохраним last_sync_at другое значение, но с opts.All=true должен отправиться epoch
-	_ = fsrepo.SaveLastSyncAt("user1", "2020-01-01T00:00:00Z")
+	// сохраним другой курсор, но с opts.All=true должен отправиться "0"
+	_ = fsrepo.SaveSyncCursor("user1", "17")
 
 	// сервер проверяет тело запроса
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var req struct {
-			LastSyncAt string           `json:"last_sync_at"`
-			Changes    []map[string]any `json:"changes"`
+			Cursor  string           `json:"cursor"`
+			Changes []map[string]any `json:"changes"`
 		}
 		_ = json.NewDecoder(r.Body).Decode(&req)
-		if req.LastSyncAt != "1970-01-01T00:00:00Z" {
-			t.Fatalf("expected epoch last_sync_at, got %s", req.LastSyncAt)
+		if req.Cursor != "0" {
+			t.Fatalf("expected full sync cursor, got %s", req.Cursor)
 		}
 		if len(req.Changes) != 1 {
 			t.Fatalf("expected exactly 1 change (second skipped), got %d", len(req.Changes))
@@ -143,18 +144,17 @@ func TestRunSyncBatch_ListItemsError_Non200_And_JSONError(t *testing.T) {
 	}
 }
 
-func TestRunSyncBatch_DefaultsToEpochWhenNoStoredLastSyncAt(t *testing.T) {
+func TestRunSyncBatch_DefaultsToFullCursorWhenNoneStored(t *testing.T) {
 	setupUserEnv(t)
-	// Удалим файл last_sync_at для пользователя, чтобы сработал дефолт epoch
-	// (setupUserEnv сохраняет login, но не last_sync_at)
+	// setupUserEnv сохраняет login, но не курсор — должен уйти "0"
 
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var req struct {
-			LastSyncAt string `json:"last_sync_at"`
+			Cursor string `json:"cursor"`
 		}
 		_ = json.NewDecoder(r.Body).Decode(&req)
-		if req.LastSyncAt != "1970-01-01T00:00:00Z" {
-			t.Fatalf("expected epoch by default, got %s", req.LastSyncAt)
+		if req.Cursor != "0" {
+			t.Fatalf("expected full sync cursor by default, got %s", req.Cursor)
 		}
 		_ = json.NewEncoder(w).Encode(map[string]any{
 			"applied": []any{}, "conflicts": []any{}, "server_changes": []any{},
@@ -305,25 +305,30 @@ func TestUploadBlobAsync_UnexpectedStatus(t *testing.T) {
 	}
 }
 
-func TestRunSyncBatch_UsesStoredLastSyncAt_And_SavesServerTime(t *testing.T) {
+func TestRunSyncBatch_UsesStoredCursor_And_SavesNewCursor(t *testing.T) {
 	setupUserEnv(t)
-	// сохраним last_sync_at пользователя
-	stored := "2024-01-02T03:04:05Z"
-	_ = fsrepo.SaveLastSyncAt("user1", stored)
+	// сохраним курсор пользователя
+	stored := "41"
+	_ = fsrepo.SaveSyncCursor("user1", stored)
 
 	serverTime := time.Now().UTC().Format(time.RFC3339)
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var req struct {
+			Cursor     string `json:"cursor"`
 			LastSyncAt string `json:"last_sync_at"`
 		}
 		_ = json.NewDecoder(r.Body).Decode(&req)
-		if req.LastSyncAt != stored {
-			t.Fatalf("expected last_sync_at from store %s, got %s", stored, req.LastSyncAt)
+		if req.Cursor != stored {
+			t.Fatalf("expected cursor from store %s, got %s", stored, req.Cursor)
+		}
+		if req.LastSyncAt != "" {
+			t.Fatalf("last_sync_at must not be sent, got %s", req.LastSyncAt)
 		}
 		_ = json.NewEncoder(w).Encode(map[string]any{
 			"applied":        []any{},
 			"conflicts":      []any{},
 			"server_changes": []any{},
+			"cursor":         "45",
 			"server_time":    serverTime,
 		})
 	}))
@@ -334,10 +339,92 @@ func TestRunSyncBatch_UsesStoredLastSyncAt_And_SavesServerTime(t *testing.T) {
 	r.On("ListItems").Return([]model.Item{}, nil).Once()
 	res := RunSyncBatch(t.Context(), cfg, r, BatchSyncOptions{All: false})
 	assert.NoError(t, res.Err)
-	// Проверим, что server_time также сохранён как last_sync_at
-	got, err := fsrepo.LoadLastSyncAt("user1")
+	assert.Equal(t, serverTime, res.ServerTime)
+	// Проверим, что новый курсор сохранён
+	got, err := fsrepo.LoadSyncCursor("user1")
+	assert.NoError(t, err)
+	assert.Equal(t, "45", got)
+	r.AssertExpectations(t)
+}
+
+func TestRunSyncBatch_PagesUntilHasMoreFalse(t *testing.T) {
+	setupUserEnv(t)
+	serverTime := time.Now().UTC().Format(time.RFC3339)
+	var requests []map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+		resp := map[string]any{"applied": []any{}, "conflicts": []any{}, "server_time": serverTime}
+		switch req["cursor"] {
+		case "0":
+			resp["server_changes"] = []map[string]any{{"id": "p1", "name": "A", "version": 1}}
+			resp["cursor"] = "1"
+			resp["has_more"] = true
+		case "1":
+			resp["server_changes"] = []map[string]any{{"id": "p2", "name": "B", "version": 1}}
+			resp["cursor"] = "2"
+			resp["has_more"] = false
+		default:
+			t.Fatalf("unexpected cursor %v", req["cursor"])
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer ts.Close()
+	cfg := &config.Config{ServerURL: ts.URL}
+
+	r := new(syncMockRepo)
+	r.On("ListItems").Return([]model.Item{}, nil).Once()
+	r.On("ApplySyncBatch", mock.Anything, mock.MatchedBy(func(items []model.Item) bool {
+		return len(items) == 1 && items[0].ID == "p1"
+	})).Return(nil).Once()
+	r.On("ApplySyncBatch", mock.Anything, mock.MatchedBy(func(items []model.Item) bool {
+		return len(items) == 1 && items[0].ID == "p2"
+	})).Return(nil).Once()
+
+	res := RunSyncBatch(t.Context(), cfg, r, BatchSyncOptions{All: true})
+	assert.NoError(t, res.Err)
+	assert.Equal(t, serverTime, res.ServerTime)
+	if assert.Len(t, requests, 2) {
+		assert.Equal(t, float64(syncPageSize), requests[0]["limit"])
+		// следующие страницы не повторяют локальные изменения
+		assert.Empty(t, requests[1]["changes"])
+		assert.Equal(t, float64(syncPageSize), requests[1]["limit"])
+	}
+	got, err := fsrepo.LoadSyncCursor("user1")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", got)
+	r.AssertExpectations(t)
+}
+
+func TestRunSyncBatch_PageApplyErrorKeepsCursor(t *testing.T) {
+	setupUserEnv(t)
+	_ = fsrepo.SaveSyncCursor("user1", "5")
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"applied":        []any{},
+			"conflicts":      []any{},
+			"server_changes": []map[string]any{{"id": "p1", "name": "A", "version": 1}},
+			"cursor":         "6",
+			"has_more":       true,
+		})
+	}))
+	defer ts.Close()
+	cfg := &config.Config{ServerURL: ts.URL}
+
+	r := new(syncMockRepo)
+	r.On("ListItems").Return([]model.Item{}, nil).Once()
+	r.On("ApplySyncBatch", mock.Anything, mock.Anything).Return(assert.AnError).Once()
+
+	res := RunSyncBatch(t.Context(), cfg, r, BatchSyncOptions{})
+	assert.ErrorIs(t, res.Err, assert.AnError)
+	assert.Equal(t, 1, calls)
+	// страница не применена — курсор остался прежним
+	got, err := fsrepo.LoadSyncCursor("user1")
 	assert.NoError(t, err)
-	assert.Equal(t, serverTime, got)
+	assert.Equal(t, "5", got)
 	r.AssertExpectations(t)
 }
 
@@ -4,6 +4,7 @@ import (
 	"GophKeeper/internal/cli/model"
 	crepo "GophKeeper/internal/cli/repo"
 	fsrepo "GophKeeper/internal/cli/repo/fs"
+	csqlite "GophKeeper/internal/cli/repo/sqlite"
 	"GophKeeper/internal/config"
 	"encoding/json"
 	"net/http"
@@ -16,6 +17,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // --- Мок репозитория для sync ---
@@ -74,6 +76,14 @@ func (m *syncMockRepo) UpsertFullFromServer(it model.Item) error {
 	args := m.Called(it)
 	return args.Error(0)
 }
+func (m *syncMockRepo) ApplySyncBatch(versions map[string]int64, items []model.Item) error {
+	args := m.Called(versions, items)
+	return args.Error(0)
+}
+func (m *syncMockRepo) InsertFull(it model.Item) error {
+	args := m.Called(it)
+	return args.Error(0)
+}
 
 var _ crepo.ItemRepository = (*syncMockRepo)(nil)
 
@@ -290,11 +300,12 @@ func TestRunSyncBatch_ServerChangesApplied(t *testing.T) {
 	r := new(syncMockRepo)
 	// список локальных элементов пуст — изменений не отправляем
 	r.On("ListItems").Return([]model.Item{}, nil).Once()
-	// ожидаем применение обоих server_changes локально и установку версий
-	r.On("UpsertFullFromServer", mock.MatchedBy(func(it model.Item) bool { return it.ID == "s1" && it.Version == 2 })).Return(nil).Once()
-	r.On("SetServerVersion", "s1", int64(2)).Return(nil).Once()
-	r.On("UpsertFullFromServer", mock.MatchedBy(func(it model.Item) bool { return it.ID == "s2" && it.Version == 3 && len(it.LoginCipher) == 1 })).Return(nil).Once()
-	r.On("SetServerVersion", "s2", int64(3)).Return(nil).Once()
+	// ожидаем применение обоих server_changes одной пачкой (версии приходят в снимке)
+	r.On("ApplySyncBatch", mock.Anything, mock.MatchedBy(func(items []model.Item) bool {
+		return len(items) == 2 &&
+			items[0].ID == "s1" && items[0].Version == 2 &&
+			items[1].ID == "s2" && items[1].Version == 3 && len(items[1].LoginCipher) == 1
+	})).Return(nil).Once()
 	// блоб для второго отсутствует локально — пойдёт в очередь
 	r.On("GetBlobByID", "BLOB-X").Return((*model.Blob)(nil), assert.AnError).Once()
 
@@ -388,9 +399,13 @@ func TestRunSyncBatch_AppliedAndResolveServer(t *testing.T) {
 	r.On("GetItemByName", "A").Return(&model.Item{ID: "i1", Name: "A", Version: 1}, nil).Twice()
 	r.On("GetItemByName", "B").Return(&model.Item{ID: "i2", Name: "B", Version: 4}, nil).Twice()
 
-	// Для второго прогона (resolve=server) ожидаем применение server_item
-	r.On("UpsertFullFromServer", mock.MatchedBy(func(it model.Item) bool { return it.ID == "i2" && it.Version == 5 })).Return(nil).Once()
-	r.On("SetServerVersion", "i2", int64(5)).Return(nil).Once()
+	// Первый прогон фиксирует версию принятого изменения
+	r.On("ApplySyncBatch", map[string]int64{"i1": 2}, mock.MatchedBy(func(items []model.Item) bool { return len(items) == 0 })).Return(nil).Once()
+	// Для второго прогона (resolve=server) ожидаем применение server_item в той же транзакции;
+	// его версия в versions заменяет локальные правки
+	r.On("ApplySyncBatch", map[string]int64{"i2": 5}, mock.MatchedBy(func(items []model.Item) bool {
+		return len(items) == 1 && items[0].ID == "i2" && items[0].Version == 5
+	})).Return(nil).Once()
 	// Блоб отсутствует локально — вернём ошибку, чтобы он попал в очередь
 	r.On("GetBlobByID", "BID-1").Return((*model.Blob)(nil), assert.AnError).Once()
 
@@ -411,3 +426,319 @@ func TestRunSyncBatch_AppliedAndResolveServer(t *testing.T) {
 
 	r.AssertExpectations(t)
 }
+
+func TestChangeFromItem_SendsOnlyEditedFields(t *testing.T) {
+	it := model.Item{
+		ID: "m1", Name: "nm", Version: 4,
+		LoginCipher: []byte{1}, LoginNonce: []byte{2},
+		TextCipher: []byte{3}, TextNonce: []byte{4},
+		BaseVersions: map[string]int64{model.FieldText: 3},
+	}
+	ch := changeFromItem(it, false)
+	assert.Equal(t, int64(4), *ch.Version)
+	assert.Equal(t, map[string]int64{model.FieldText: 3}, ch.BaseVersions)
+	assert.Equal(t, []byte{3}, ch.TextCipher)
+	assert.Nil(t, ch.LoginCipher)
+	assert.Nil(t, ch.Name)
+
+	// новая запись отправляется целиком
+	ch = changeFromItem(it, true)
+	assert.Equal(t, int64(0), *ch.Version)
+	assert.Nil(t, ch.BaseVersions)
+	assert.Equal(t, []byte{1}, ch.LoginCipher)
+	assert.Equal(t, "nm", *ch.Name)
+}
+
+func TestSyncItemByName_MergedRefreshesFromServer(t *testing.T) {
+	setupUserEnv(t)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/items/sync":
+			var req struct {
+				Changes []map[string]any `json:"changes"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if len(req.Changes) != 1 || req.Changes[0]["base_versions"] == nil {
+				t.Fatalf("base_versions not sent: %v", req.Changes)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"applied":   []map[string]any{{"id": "m1", "new_version": 5}},
+				"conflicts": []any{},
+			})
+		case r.Method == http.MethodGet && r.URL.Path == "/api/data/m1":
+			// сервер вернул слитую запись: пароль с другого устройства + наш текст
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id": "m1", "name": "nm", "version": 5,
+				"password_cipher": "CQ==", "text_cipher": "Aw==",
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	cfg := &config.Config{ServerURL: ts.URL}
+
+	r := new(syncMockRepo)
+	it := &model.Item{ID: "m1", Name: "nm", Version: 3, TextCipher: []byte{3}, BaseVersions: map[string]int64{model.FieldText: 3}}
+	r.On("GetItemByName", "nm").Return(it, nil).Once()
+	r.On("UpsertFullFromServer", mock.MatchedBy(func(m model.Item) bool {
+		return m.ID == "m1" && m.Version == 5 && len(m.PasswordCipher) == 1 && len(m.TextCipher) == 1
+	})).Return(nil).Once()
+
+	applied, newVer, _, err := SyncItemByName(cfg, r, "nm", false, nil)
+	assert.NoError(t, err)
+	assert.True(t, applied)
+	assert.Equal(t, int64(5), newVer)
+	r.AssertExpectations(t)
+}
+
+func TestRunSyncBatch_ResolveBoth_ForksLocalCopy(t *testing.T) {
+	setupUserEnv(t)
+	var copyUpload map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Changes     []map[string]any  `json:"changes"`
+			Resolutions map[string]string `json:"resolutions"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		now := time.Now().UTC().Format(time.RFC3339)
+		if len(req.Changes) == 1 && req.Changes[0]["id"] != "i2" {
+			// выгрузка копии локальной версии
+			copyUpload = req.Changes[0]
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"applied":   []map[string]any{{"id": req.Changes[0]["id"], "new_version": 1}},
+				"conflicts": []any{},
+			})
+			return
+		}
+		assert.Equal(t, "both", req.Resolutions["i2"])
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"applied": []any{},
+			"conflicts": []map[string]any{{
+				"id": "i2", "reason": "version_conflict",
+				"server_item": map[string]any{"id": "i2", "name": "B", "version": 5},
+			}},
+			"server_time": now,
+		})
+	}))
+	defer ts.Close()
+	cfg := &config.Config{ServerURL: ts.URL}
+
+	isCopy := func(n string) bool { return strings.HasPrefix(n, "B.conflict-") }
+	local := &model.Item{ID: "i2", Name: "B", Version: 4, LoginCipher: []byte{7}}
+	copied := &model.Item{}
+	r := new(syncMockRepo)
+	r.On("ListItems").Return([]model.Item{{ID: "i2", Name: "B"}}, nil).Once()
+	r.On("GetItemByName", "B").Return(local, nil).Twice()
+	r.On("GetItemByName", mock.MatchedBy(isCopy)).Return((*model.Item)(nil), assert.AnError).Once()
+	r.On("InsertFull", mock.MatchedBy(func(it model.Item) bool {
+		*copied = it
+		return isCopy(it.Name) && it.ID != "i2" && it.Version == 0 && len(it.LoginCipher) == 1
+	})).Return(nil).Once()
+	r.On("GetItemByName", mock.MatchedBy(isCopy)).Return(copied, nil).Once()
+	r.On("SetServerVersion", mock.MatchedBy(func(id string) bool { return id != "i2" }), int64(1)).Return(nil).Once()
+	r.On("ApplySyncBatch", mock.Anything, mock.MatchedBy(func(items []model.Item) bool {
+		return len(items) == 1 && items[0].ID == "i2" && items[0].Version == 5
+	})).Return(nil).Once()
+
+	res := RunSyncBatch(t.Context(), cfg, r, BatchSyncOptions{Resolutions: map[string]string{"i2": "both"}})
+	assert.NoError(t, res.Err)
+	assert.Equal(t, 1, res.ServerUpserts)
+	assert.Empty(t, res.Conflicts)
+	if assert.Len(t, res.ConflictCopies, 1) {
+		assert.True(t, isCopy(res.ConflictCopies[0]))
+		assert.Contains(t, res.ConflictsJSON, `"local_copy":"`+res.ConflictCopies[0]+`"`)
+	}
+	assert.Equal(t, float64(0), copyUpload["version"])
+	r.AssertExpectations(t)
+}
+
+func TestRunSyncBatch_ResolveBoth_CopyFailureKeepsConflict(t *testing.T) {
+	setupUserEnv(t)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"applied": []any{},
+			"conflicts": []map[string]any{{
+				"id": "i2", "reason": "version_conflict",
+				"server_item": map[string]any{"id": "i2", "name": "B", "version": 5},
+			}},
+		})
+	}))
+	defer ts.Close()
+	cfg := &config.Config{ServerURL: ts.URL}
+
+	r := new(syncMockRepo)
+	r.On("ListItems").Return([]model.Item{{ID: "i2", Name: "B"}}, nil).Once()
+	r.On("GetItemByName", "B").Return(&model.Item{ID: "i2", Name: "B", Version: 4}, nil).Twice()
+	r.On("GetItemByName", mock.Anything).Return((*model.Item)(nil), assert.AnError).Once()
+	r.On("InsertFull", mock.Anything).Return(assert.AnError).Once()
+
+	both := "both"
+	res := RunSyncBatch(t.Context(), cfg, r, BatchSyncOptions{Resolve: &both})
+	assert.NoError(t, res.Err)
+	assert.Equal(t, 0, res.ServerUpserts)
+	assert.Empty(t, res.ConflictCopies)
+	if assert.Len(t, res.Conflicts, 1) {
+		assert.Equal(t, "B", res.Conflicts[0].Name)
+	}
+	// локальная версия не перезаписана
+	r.AssertNotCalled(t, "ApplySyncBatch", mock.Anything, mock.Anything)
+	r.AssertExpectations(t)
+}
+
+func TestRunSyncBatch_AtomicAppliesVersionsAndChangesTogether(t *testing.T) {
+	setupUserEnv(t)
+	var atomic any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		atomic = req["atomic"]
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"applied":        []map[string]any{{"id": "i1", "new_version": 2}},
+			"conflicts":      []any{},
+			"server_changes": []map[string]any{{"id": "s1", "name": "S", "version": 7}},
+			"cursor":         "9",
+		})
+	}))
+	defer ts.Close()
+	cfg := &config.Config{ServerURL: ts.URL}
+
+	r := new(syncMockRepo)
+	r.On("ListItems").Return([]model.Item{{ID: "i1", Name: "A"}}, nil).Once()
+	r.On("GetItemByName", "A").Return(&model.Item{ID: "i1", Name: "A", Version: 1}, nil).Once()
+	// версия принятого изменения и снимок с сервера — одна транзакция
+	r.On("ApplySyncBatch", map[string]int64{"i1": 2}, mock.MatchedBy(func(items []model.Item) bool {
+		return len(items) == 1 && items[0].ID == "s1" && items[0].Version == 7
+	})).Return(nil).Once()
+
+	res := RunSyncBatch(t.Context(), cfg, r, BatchSyncOptions{Atomic: true})
+	assert.NoError(t, res.Err)
+	assert.Equal(t, 1, res.AppliedCount)
+	assert.Equal(t, true, atomic)
+	got, err := fsrepo.LoadSyncCursor("user1")
+	assert.NoError(t, err)
+	assert.Equal(t, "9", got)
+	r.AssertExpectations(t)
+}
+
+// openSyncTestRepo открывает локальную БД пользователя с синхронизированной записью "B"
+// (серверная версия 4), текст которой затем изменён локально. Возвращает id записи.
+func openSyncTestRepo(t *testing.T) (*csqlite.ItemRepositorySQLite, string) {
+	t.Helper()
+	r, _, err := csqlite.OpenForUser("user1")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = r.Close() })
+	require.NoError(t, r.Migrate())
+	id, _, err := r.UpsertText("B", []byte("base"), []byte("n0"))
+	require.NoError(t, err)
+	require.NoError(t, r.SetServerVersion(id, 4))
+	_, _, err = r.UpsertText("B", []byte("local"), []byte("n1"))
+	require.NoError(t, err)
+	return r, id
+}
+
+// Конфликтующая запись приходит и в server_changes: до выбора стратегии локальная правка
+// не затирается, а стратегия client отправляет именно её.
+func TestRunSyncBatch_ConflictKeepsLocalEditUntilResolved(t *testing.T) {
+	setupUserEnv(t)
+	r, id := openSyncTestRepo(t)
+	var resolvedChange syncChange
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var sreq syncRequest
+		_ = json.NewDecoder(req.Body).Decode(&sreq)
+		if sreq.Resolutions[id] == "client" {
+			require.Len(t, sreq.Changes, 1)
+			resolvedChange = sreq.Changes[0]
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"applied":        []map[string]any{{"id": id, "new_version": 6}},
+				"server_changes": []map[string]any{{"id": id, "name": "B", "version": 6, "text_cipher": []byte("local"), "text_nonce": []byte("n1")}},
+				"cursor":         "6",
+			})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"conflicts": []map[string]any{{
+				"id": id, "reason": "version_conflict", "fields": []string{"text"},
+				"server_item": map[string]any{"id": id, "name": "B", "version": 5},
+			}},
+			"server_changes": []map[string]any{{"id": id, "name": "B", "version": 5, "text_cipher": []byte("server"), "text_nonce": []byte("n2")}},
+			"cursor":         "5",
+		})
+	}))
+	defer ts.Close()
+	cfg := &config.Config{ServerURL: ts.URL}
+
+	res := RunSyncBatch(t.Context(), cfg, r, BatchSyncOptions{})
+	require.NoError(t, res.Err)
+	require.Len(t, res.Conflicts, 1)
+	it, err := r.GetItemByName("B")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("local"), it.TextCipher)
+	assert.Equal(t, int64(4), it.Version)
+	assert.Equal(t, map[string]int64{"text": 4}, it.BaseVersions)
+
+	res = RunSyncBatch(t.Context(), cfg, r, BatchSyncOptions{Resolutions: map[string]string{id: "client"}})
+	require.NoError(t, res.Err)
+	assert.Equal(t, []byte("local"), resolvedChange.TextCipher)
+	assert.Equal(t, map[string]int64{"text": 4}, resolvedChange.BaseVersions)
+	it, err = r.GetItemByName("B")
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), it.Version)
+	assert.Equal(t, []byte("local"), it.TextCipher)
+	assert.Empty(t, it.BaseVersions)
+}
+
+// Стратегия both после первого прохода копирует локальную правку, а не снимок сервера.
+func TestRunSyncBatch_ResolveBoth_CopyKeepsLocalEdit(t *testing.T) {
+	setupUserEnv(t)
+	r, id := openSyncTestRepo(t)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var sreq syncRequest
+		_ = json.NewDecoder(req.Body).Decode(&sreq)
+		switch {
+		case len(sreq.Changes) == 1 && sreq.Changes[0].ID != id:
+			// выгрузка копии локальной версии
+			assert.Equal(t, []byte("local"), sreq.Changes[0].TextCipher)
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"applied": []map[string]any{{"id": sreq.Changes[0].ID, "new_version": 1}},
+			})
+		case sreq.Resolutions[id] == "both":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"conflicts": []map[string]any{{
+					"id": id, "reason": "version_conflict",
+					"server_item": map[string]any{"id": id, "name": "B", "version": 5, "text_cipher": []byte("server"), "text_nonce": []byte("n2")},
+				}},
+				"cursor": "5",
+			})
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"conflicts": []map[string]any{{
+					"id": id, "reason": "version_conflict", "fields": []string{"text"},
+					"server_item": map[string]any{"id": id, "name": "B", "version": 5},
+				}},
+				"server_changes": []map[string]any{{"id": id, "name": "B", "version": 5, "text_cipher": []byte("server"), "text_nonce": []byte("n2")}},
+				"cursor":         "5",
+			})
+		}
+	}))
+	defer ts.Close()
+	cfg := &config.Config{ServerURL: ts.URL}
+
+	res := RunSyncBatch(t.Context(), cfg, r, BatchSyncOptions{})
+	require.NoError(t, res.Err)
+	require.Len(t, res.Conflicts, 1)
+
+	res = RunSyncBatch(t.Context(), cfg, r, BatchSyncOptions{Resolutions: map[string]string{id: "both"}})
+	require.NoError(t, res.Err)
+	require.Len(t, res.ConflictCopies, 1)
+	cp, err := r.GetItemByName(res.ConflictCopies[0])
+	require.NoError(t, err)
+	assert.Equal(t, []byte("local"), cp.TextCipher)
+	assert.Equal(t, []byte("n1"), cp.TextNonce)
+	assert.Equal(t, int64(1), cp.Version)
+	it, err := r.GetItemByName("B")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("server"), it.TextCipher)
+	assert.Equal(t, int64(5), it.Version)
+	assert.Empty(t, it.BaseVersions)
+}
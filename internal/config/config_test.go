@@ -3,6 +3,7 @@ package config
 import (
 	"flag"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -53,6 +54,31 @@ func TestNewConfig_DefaultsWhenEnvEmpty(t *testing.T) {
 	}
 }
 
+func TestNewConfig_GRPCAndTransport(t *testing.T) {
+	t.Setenv("GRPC_ADDRESS", "")
+	t.Setenv("TRANSPORT", "")
+	resetFlagSet(t)
+	cfg := NewConfig()
+	if cfg.GRPCAddress != "localhost:8082" || cfg.Transport != TransportHTTP {
+		t.Fatalf("defaults expected localhost:8082/http, got %q/%q", cfg.GRPCAddress, cfg.Transport)
+	}
+
+	t.Setenv("GRPC_ADDRESS", "keeper.local:9090")
+	t.Setenv("TRANSPORT", "grpc")
+	resetFlagSet(t)
+	cfg = NewConfig()
+	if cfg.GRPCAddress != "keeper.local:9090" || cfg.Transport != TransportGRPC {
+		t.Fatalf("env values expected, got %q/%q", cfg.GRPCAddress, cfg.Transport)
+	}
+
+	// неизвестный транспорт откатывается на http
+	t.Setenv("TRANSPORT", "carrier-pigeon")
+	resetFlagSet(t)
+	if cfg = NewConfig(); cfg.Transport != TransportHTTP {
+		t.Fatalf("unknown transport must fall back to http, got %q", cfg.Transport)
+	}
+}
+
 func TestNewConfig_BaseURLAndHTTPS(t *testing.T) {
 	t.Setenv("BASE_URL", "example.com:443")
 	t.Setenv("ENABLE_HTTPS", "true")
@@ -91,3 +117,93 @@ func TestNewConfig_InvalidBaseURLFallback(t *testing.T) {
 		t.Fatalf("ServerURL must reflect fallback base, got %q", cfg.ServerURL)
 	}
 }
+
+func TestNewConfig_HistoryRetention(t *testing.T) {
+	unsetEnv(t, "HISTORY_MAX_VERSIONS", "HISTORY_MAX_AGE_DAYS")
+	resetFlagSet(t)
+	cfg := NewConfig()
+	if cfg.HistoryMaxVersions != 20 || cfg.HistoryMaxAgeDays != 0 {
+		t.Fatalf("history defaults expected 20/0, got %d/%d", cfg.HistoryMaxVersions, cfg.HistoryMaxAgeDays)
+	}
+
+	t.Setenv("HISTORY_MAX_VERSIONS", "0")
+	t.Setenv("HISTORY_MAX_AGE_DAYS", "30")
+	resetFlagSet(t)
+	cfg = NewConfig()
+	if cfg.HistoryMaxVersions != 0 || cfg.HistoryMaxAgeDays != 30 {
+		t.Fatalf("history from env expected 0/30, got %d/%d", cfg.HistoryMaxVersions, cfg.HistoryMaxAgeDays)
+	}
+}
+
+func TestNewConfig_TLS(t *testing.T) {
+	t.Setenv("TLS_CERT_FILE", "/etc/gk/cert.pem")
+	t.Setenv("TLS_KEY_FILE", "/etc/gk/key.pem")
+	t.Setenv("TLS_DEV_DIR", "")
+	resetFlagSet(t)
+	cfg := NewConfig()
+	if cfg.TLSCertFile != "/etc/gk/cert.pem" || cfg.TLSKeyFile != "/etc/gk/key.pem" {
+		t.Fatalf("TLS files from env expected, got %q/%q", cfg.TLSCertFile, cfg.TLSKeyFile)
+	}
+	if cfg.TLSDevDir != filepath.Join("data", "tls") {
+		t.Fatalf("TLSDevDir default expected data/tls, got %q", cfg.TLSDevDir)
+	}
+}
+
+func TestNewConfig_ClientCertificates(t *testing.T) {
+	t.Setenv("TLS_CLIENT_CA", "/etc/gk/clients.pem")
+	t.Setenv("CLIENT_CERT", "/home/u/laptop.pem")
+	t.Setenv("CLIENT_KEY", "/home/u/laptop.key")
+	t.Setenv("CA_FILE", "/home/u/keeper-ca.pem")
+	resetFlagSet(t)
+	cfg := NewConfig()
+	if cfg.TLSClientCA != "/etc/gk/clients.pem" {
+		t.Fatalf("TLSClientCA from env expected, got %q", cfg.TLSClientCA)
+	}
+	if cfg.ClientCert != "/home/u/laptop.pem" || cfg.ClientKey != "/home/u/laptop.key" {
+		t.Fatalf("client certificate from env expected, got %q/%q", cfg.ClientCert, cfg.ClientKey)
+	}
+	if cfg.CAFile != "/home/u/keeper-ca.pem" {
+		t.Fatalf("CAFile from env expected, got %q", cfg.CAFile)
+	}
+}
+
+func TestNewConfig_Tracing(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318")
+	resetFlagSet(t)
+	cfg := NewConfig()
+	if cfg.TracesExporter != "otlp" || cfg.OTLPEndpoint != "http://collector:4318" {
+		t.Fatalf("tracing settings from env expected, got %q/%q", cfg.TracesExporter, cfg.OTLPEndpoint)
+	}
+}
+
+func TestConfig_Redacted(t *testing.T) {
+	cfg := Config{
+		DatabaseDSN: "postgres://gk:s3cret@db:5432/gk?sslmode=disable",
+		AuthSecret:  "jwt-secret",
+		S3AccessKey: "minio",
+		S3SecretKey: "minio-secret",
+	}
+	r := cfg.Redacted()
+	if r.AuthSecret != "xxxxx" || r.S3SecretKey != "xxxxx" {
+		t.Fatalf("secrets not redacted: %+v", r)
+	}
+	if r.DatabaseDSN != "postgres://gk:xxxxx@db:5432/gk?sslmode=disable" {
+		t.Fatalf("dsn: %q", r.DatabaseDSN)
+	}
+	if r.S3AccessKey != "minio" || cfg.AuthSecret != "jwt-secret" {
+		t.Fatalf("unexpected change: %+v / %+v", r, cfg)
+	}
+
+	for dsn, want := range map[string]string{
+		"host=db user=gk password=s3cret dbname=gk": "host=db user=gk password=xxxxx dbname=gk",
+		"host=db password='a b' dbname=gk":          "host=db password=xxxxx dbname=gk",
+		"sqlite://data/gk.db":                       "sqlite://data/gk.db",
+		"postgres://gk@db/gk":                       "postgres://gk@db/gk",
+		"":                                          "",
+	} {
+		if got := (Config{DatabaseDSN: dsn}).Redacted().DatabaseDSN; got != want {
+			t.Fatalf("redact %q: got %q, want %q", dsn, got, want)
+		}
+	}
+}
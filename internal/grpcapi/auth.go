@@ -0,0 +1,84 @@
+package grpcapi
+
+import (
+	"GophKeeper/internal/middleware"
+	"context"
+	"crypto/tls"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// publicMethods доступны без токена.
+var publicMethods = map[string]bool{
+	"/gophkeeper.v1.GophKeeper/Register": true,
+	"/gophkeeper.v1.GophKeeper/Login":    true,
+}
+
+// authenticate проверяет токен из metadata "authorization: Bearer <token>"
+// и кладёт user_id в контекст так же, как middleware.WithAuth для HTTP.
+// requireCert — сервер требует mTLS: токен должен быть привязан к сертификату соединения.
+func authenticate(ctx context.Context, method, secret string, requireCert bool) (context.Context, error) {
+	if publicMethods[method] {
+		return ctx, nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("authorization") {
+		raw, ok := strings.CutPrefix(v, "Bearer ")
+		if !ok {
+			continue
+		}
+		if userID, ok := middleware.AuthorizeToken(ctx, raw, secret, peerTLS(ctx), requireCert); ok {
+			return middleware.WithUserID(ctx, userID), nil
+		}
+	}
+	return nil, status.Error(codes.Unauthenticated, "unauthorized")
+}
+
+// peerTLS возвращает состояние TLS соединения клиента или nil без TLS.
+func peerTLS(ctx context.Context) *tls.ConnectionState {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return nil
+	}
+	return &info.State
+}
+
+// unaryAuth — interceptor авторизации для unary-вызовов.
+func unaryAuth(secret string, requireCert bool) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, info.FullMethod, secret, requireCert)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// streamAuth — interceptor авторизации для потоковых вызовов.
+func streamAuth(secret string, requireCert bool) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), info.FullMethod, secret, requireCert)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authStream подменяет контекст потока (user_id, идентификатор запроса, спан).
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authStream) Context() context.Context { return s.ctx }
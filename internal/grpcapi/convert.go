@@ -0,0 +1,149 @@
+package grpcapi
+
+import (
+	"GophKeeper/internal/model"
+	"GophKeeper/internal/pb"
+	"GophKeeper/internal/service"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// changeFromPB переводит изменение из gRPC-запроса в сервисный DTO.
+func changeFromPB(ch *pb.ItemChange) service.SyncChange {
+	return service.SyncChange{
+		ID:             ch.GetId(),
+		Version:        ch.Version,
+		Deleted:        ch.Deleted,
+		Name:           ch.Name,
+		FileName:       ch.FileName,
+		BlobID:         ch.BlobId,
+		LoginCipher:    ch.GetLoginCipher(),
+		LoginNonce:     ch.GetLoginNonce(),
+		PasswordCipher: ch.GetPasswordCipher(),
+		PasswordNonce:  ch.GetPasswordNonce(),
+		TextCipher:     ch.GetTextCipher(),
+		TextNonce:      ch.GetTextNonce(),
+		CardCipher:     ch.GetCardCipher(),
+		CardNonce:      ch.GetCardNonce(),
+		BaseVersions:   ch.GetBaseVersions(),
+	}
+}
+
+// itemToPB переводит полный снимок записи в gRPC-сообщение.
+func itemToPB(it model.Item) *pb.Item {
+	out := &pb.Item{
+		Id:             it.ID,
+		Version:        it.Version,
+		Deleted:        it.Deleted,
+		UpdatedAt:      timestamppb.New(it.UpdatedAt),
+		Name:           it.Name,
+		FileName:       it.FileName,
+		LoginCipher:    it.LoginCipher,
+		LoginNonce:     it.LoginNonce,
+		PasswordCipher: it.PasswordCipher,
+		PasswordNonce:  it.PasswordNonce,
+		TextCipher:     it.TextCipher,
+		TextNonce:      it.TextNonce,
+		CardCipher:     it.CardCipher,
+		CardNonce:      it.CardNonce,
+	}
+	if it.BlobID != nil {
+		out.BlobId = *it.BlobID
+	}
+	return out
+}
+
+// itemFromView переводит представление server_item конфликта (см. service.ConflictResult)
+// в gRPC-сообщение; шифрованные поля есть только в полном представлении.
+func itemFromView(v any) *pb.Item {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil
+	}
+	out := &pb.Item{}
+	out.Id, _ = m["id"].(string)
+	out.Version, _ = m["version"].(int64)
+	out.Deleted, _ = m["deleted"].(bool)
+	out.Name, _ = m["name"].(string)
+	out.FileName, _ = m["file_name"].(string)
+	if s, ok := m["updated_at"].(string); ok {
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			out.UpdatedAt = timestamppb.New(t)
+		}
+	}
+	if b, ok := m["blob_id"].(*string); ok && b != nil {
+		out.BlobId = *b
+	}
+	bytesOf := func(key string) []byte {
+		b, _ := m[key].([]byte)
+		return b
+	}
+	out.LoginCipher, out.LoginNonce = bytesOf("login_cipher"), bytesOf("login_nonce")
+	out.PasswordCipher, out.PasswordNonce = bytesOf("password_cipher"), bytesOf("password_nonce")
+	out.TextCipher, out.TextNonce = bytesOf("text_cipher"), bytesOf("text_nonce")
+	out.CardCipher, out.CardNonce = bytesOf("card_cipher"), bytesOf("card_nonce")
+	return out
+}
+
+// syncResultToPB собирает страницу ответа Sync.
+func syncResultToPB(res service.SyncResult) *pb.SyncResponse {
+	out := &pb.SyncResponse{
+		Applied:       make([]*pb.Applied, 0, len(res.Applied)),
+		Conflicts:     make([]*pb.Conflict, 0, len(res.Conflicts)),
+		ServerChanges: make([]*pb.Item, 0, len(res.ServerChanges)),
+		Cursor:        service.FormatSyncCursor(res.Cursor),
+		HasMore:       res.HasMore,
+		ServerTime:    timestamppb.New(res.ServerTime),
+	}
+	for _, a := range res.Applied {
+		out.Applied = append(out.Applied, &pb.Applied{Id: a.ID, NewVersion: a.NewVersion})
+	}
+	for _, c := range res.Conflicts {
+		out.Conflicts = append(out.Conflicts, &pb.Conflict{Id: c.ID, Reason: c.Reason, ServerItem: itemFromView(c.ServerItem), Fields: c.Fields})
+	}
+	for _, it := range res.ServerChanges {
+		out.ServerChanges = append(out.ServerChanges, itemToPB(it))
+	}
+	if res.Collections != nil {
+		out.HasCollections = true
+		out.Collections = collectionsToPB(res.Collections)
+	}
+	return out
+}
+
+// collectionsToPB переводит снимки коллекций организаций в gRPC-сообщения.
+func collectionsToPB(snaps []model.CollectionSnapshot) []*pb.Collection {
+	out := make([]*pb.Collection, 0, len(snaps))
+	for _, s := range snaps {
+		col := &pb.Collection{
+			Id:              s.ID,
+			Name:            s.Name,
+			Org:             s.OrgName,
+			Role:            s.Role,
+			EphemeralKey:    s.Key.EphemeralKey,
+			WrappedKey:      s.Key.WrappedKey,
+			WrappedKeyNonce: s.Key.WrappedKeyNonce,
+			Items:           make([]*pb.CollectionItem, 0, len(s.Items)),
+		}
+		for _, it := range s.Items {
+			col.Items = append(col.Items, &pb.CollectionItem{
+				Id:             it.ID,
+				Name:           it.Name,
+				Version:        it.Version,
+				Deleted:        it.Deleted,
+				UpdatedAt:      timestamppb.New(it.UpdatedAt),
+				LoginCipher:    it.LoginCipher,
+				LoginNonce:     it.LoginNonce,
+				PasswordCipher: it.PasswordCipher,
+				PasswordNonce:  it.PasswordNonce,
+				TextCipher:     it.TextCipher,
+				TextNonce:      it.TextNonce,
+				CardCipher:     it.CardCipher,
+				CardNonce:      it.CardNonce,
+			})
+		}
+		out = append(out, col)
+	}
+	return out
+}
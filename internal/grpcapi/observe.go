@@ -0,0 +1,81 @@
+package grpcapi
+
+import (
+	"GophKeeper/internal/logging"
+	"GophKeeper/internal/telemetry"
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// requestIDKey — ключ metadata gRPC с идентификатором запроса.
+var requestIDKey = strings.ToLower(logging.RequestIDHeader)
+
+// metadataCarrier — metadata gRPC как носитель traceparent для пропагатора.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) { metadata.MD(c).Set(key, value) }
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// observe принимает x-request-id клиента (или генерирует новый), возвращает его в заголовке
+// ответа и открывает серверный спан вызова, продолжая трассу из traceparent — как
+// middleware.WithRequestID и WithTracing для HTTP.
+func observe(ctx context.Context, method string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	id := logging.AcceptRequestID(metadataCarrier(md).Get(requestIDKey))
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, id))
+	ctx = logging.WithRequestID(ctx, id)
+
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	return telemetry.Start(ctx, method,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.method", method),
+			attribute.String("request_id", id),
+		))
+}
+
+// endObserve завершает спан вызова; ошибка отмечается вместе с кодом gRPC.
+func endObserve(span trace.Span, err error) {
+	span.SetAttributes(attribute.String("rpc.grpc.status_code", status.Code(err).String()))
+	telemetry.End(span, err)
+}
+
+// unaryObserve — interceptor идентификатора запроса и трассировки для unary-вызовов.
+func unaryObserve() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		ctx, span := observe(ctx, info.FullMethod)
+		defer func() { endObserve(span, err) }()
+		return handler(ctx, req)
+	}
+}
+
+// streamObserve — interceptor идентификатора запроса и трассировки для потоковых вызовов.
+func streamObserve() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		ctx, span := observe(ss.Context(), info.FullMethod)
+		defer func() { endObserve(span, err) }()
+		return handler(srv, &authStream{ServerStream: ss, ctx: ctx})
+	}
+}
@@ -0,0 +1,263 @@
+// Package grpcapi — gRPC API GophKeeper поверх тех же сервисов, что и HTTP-хендлеры.
+package grpcapi
+
+import (
+	"GophKeeper/internal/config"
+	"GophKeeper/internal/logging"
+	"GophKeeper/internal/middleware"
+	"GophKeeper/internal/pb"
+	"GophKeeper/internal/repo"
+	"GophKeeper/internal/service"
+	"GophKeeper/internal/tlsutil"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// blobChunkSize — размер части блоба в потоке DownloadBlob.
+const blobChunkSize = 64 * 1024
+
+// Server реализует pb.GophKeeperServer.
+type Server struct {
+	pb.UnimplementedGophKeeperServer
+	UserService *service.UserService
+	ItemService *service.ItemService
+	Logger      *zap.SugaredLogger
+	Config      *config.Config
+}
+
+// NewServer создаёт gRPC-сервер с авторизацией по тому же JWT, что и HTTP API.
+// opts дополняют настройки (например, grpc.Creds для TLS).
+func NewServer(userService *service.UserService, itemService *service.ItemService, logger *zap.SugaredLogger, cfg *config.Config, opts ...grpc.ServerOption) *grpc.Server {
+	gs := grpc.NewServer(append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unaryObserve(), unaryAuth(cfg.AuthSecret, cfg.TLSClientCA != "")),
+		grpc.ChainStreamInterceptor(streamObserve(), streamAuth(cfg.AuthSecret, cfg.TLSClientCA != "")),
+	}, opts...)...)
+	pb.RegisterGophKeeperServer(gs, &Server{UserService: userService, ItemService: itemService, Logger: logger, Config: cfg})
+	return gs
+}
+
+// Register регистрирует пользователя и возвращает токен.
+func (s *Server) Register(ctx context.Context, req *pb.Credentials) (*pb.AuthResponse, error) {
+	if req.GetLogin() == "" || req.GetPassword() == "" {
+		return nil, status.Error(codes.InvalidArgument, "login and password are required")
+	}
+	fp, err := s.clientCertificate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.UserService.Register(ctx, req.GetLogin(), req.GetPassword())
+	if errors.Is(err, service.ErrLoginTaken) {
+		return nil, status.Error(codes.AlreadyExists, "login already in use")
+	}
+	if err != nil {
+		s.log(ctx).Errorw("grpc Register: failed to register user", "error", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	return s.issueToken(ctx, user.ID, fp)
+}
+
+// Login проверяет логин/пароль и возвращает токен.
+func (s *Server) Login(ctx context.Context, req *pb.Credentials) (*pb.AuthResponse, error) {
+	fp, err := s.clientCertificate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.UserService.Login(ctx, req.GetLogin(), req.GetPassword())
+	if errors.Is(err, service.ErrUserDisabled) {
+		return nil, status.Error(codes.PermissionDenied, "user is disabled")
+	}
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid login or password")
+	}
+	return s.issueToken(ctx, user.ID, fp)
+}
+
+// clientCertificate возвращает отпечаток клиентского сертификата, если сервер требует mTLS.
+func (s *Server) clientCertificate(ctx context.Context) (string, error) {
+	if s.Config.TLSClientCA == "" {
+		return "", nil
+	}
+	fp := tlsutil.PeerFingerprint(peerTLS(ctx))
+	if fp == "" {
+		return "", status.Error(codes.Unauthenticated, "client certificate required")
+	}
+	return fp, nil
+}
+
+// issueToken привязывает сертификат устройства (если есть) и выдаёт токен, связанный с ним.
+func (s *Server) issueToken(ctx context.Context, userID int64, fp string) (*pb.AuthResponse, error) {
+	if fp != "" {
+		err := s.UserService.BindDevice(ctx, userID, fp, tlsutil.PeerName(peerTLS(ctx)))
+		if errors.Is(err, service.ErrDeviceTaken) {
+			return nil, status.Error(codes.PermissionDenied, "client certificate belongs to another user")
+		}
+		if err != nil {
+			s.log(ctx).Errorw("grpc: failed to bind device", "user_id", userID, "error", err)
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+	}
+	token, err := middleware.NewBoundToken(userID, s.Config.AuthSecret, fp)
+	if err != nil {
+		s.log(ctx).Errorw("grpc: failed to sign token", "user_id", userID, "error", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	return &pb.AuthResponse{Token: token}, nil
+}
+
+// Sync применяет изменения клиента и отдаёт server_changes страницами: после каждой
+// страницы с has_more=true сервер сам запрашивает следующую с новым курсором.
+func (s *Server) Sync(req *pb.SyncRequest, stream pb.GophKeeper_SyncServer) error {
+	ctx := stream.Context()
+	userID, _ := middleware.GetUserIDFromContext(ctx)
+
+	var cursor int64
+	if req.GetCursor() != "" {
+		c, err := service.ParseSyncCursor(req.GetCursor())
+		if err != nil {
+			return status.Error(codes.InvalidArgument, "invalid cursor")
+		}
+		cursor = c
+	}
+	if req.GetLimit() < 0 {
+		return status.Error(codes.InvalidArgument, "invalid limit")
+	}
+	svcReq := service.SyncRequest{
+		Cursor:  &cursor,
+		Limit:   int(req.GetLimit()),
+		Changes: make([]service.SyncChange, 0, len(req.GetChanges())),
+		Atomic:  req.GetAtomic(),
+	}
+	if r := req.GetResolve(); r != "" {
+		if !service.ValidResolution(r) {
+			return status.Error(codes.InvalidArgument, "invalid resolve")
+		}
+		svcReq.Resolve = &r
+	}
+	for id, strategy := range req.GetResolutions() {
+		if !service.ValidResolution(strategy) {
+			return status.Error(codes.InvalidArgument, "invalid resolution for item "+id)
+		}
+	}
+	svcReq.Resolutions = req.GetResolutions()
+	for _, ch := range req.GetChanges() {
+		svcReq.Changes = append(svcReq.Changes, changeFromPB(ch))
+	}
+
+	for {
+		res, err := s.ItemService.Sync(ctx, userID, svcReq)
+		if err != nil {
+			s.log(ctx).Errorw("grpc Sync: service error", "user_id", userID, "error", err)
+			return status.Error(codes.Internal, "internal error")
+		}
+		if err := stream.Send(syncResultToPB(res)); err != nil {
+			return err
+		}
+		if !res.HasMore || res.Cursor == *svcReq.Cursor {
+			return nil
+		}
+		next := res.Cursor
+		svcReq = service.SyncRequest{Cursor: &next, Limit: svcReq.Limit}
+	}
+}
+
+// UploadBlob принимает блоб частями; первая часть обязана содержать id и nonce.
+func (s *Server) UploadBlob(stream pb.GophKeeper_UploadBlobServer) error {
+	ctx := stream.Context()
+	userID, _ := middleware.GetUserIDFromContext(ctx)
+	maxCipher := int64(s.Config.BlobMaxSizeMB) * 1024 * 1024
+
+	first, err := stream.Recv()
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "empty upload")
+	}
+	id, nonce := first.GetId(), first.GetNonce()
+	if id == "" || len(nonce) == 0 {
+		return status.Error(codes.InvalidArgument, "missing id or nonce")
+	}
+	var buf bytes.Buffer
+	buf.Write(first.GetData())
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		buf.Write(chunk.GetData())
+		if int64(buf.Len()) > maxCipher {
+			s.log(ctx).Warnw("grpc UploadBlob: payload too large", "id", id, "limit", maxCipher)
+			return status.Error(codes.ResourceExhausted, "payload too large")
+		}
+	}
+	if int64(buf.Len()) > maxCipher {
+		return status.Error(codes.ResourceExhausted, "payload too large")
+	}
+	if buf.Len() == 0 {
+		return status.Error(codes.InvalidArgument, "empty blob")
+	}
+
+	created, err := s.ItemService.SaveBlob(ctx, userID, id, buf.Bytes(), nonce)
+	if errors.Is(err, service.ErrQuotaExceeded) {
+		return status.Error(codes.ResourceExhausted, "storage quota exceeded")
+	}
+	if err != nil {
+		s.log(ctx).Errorw("grpc UploadBlob: service error", "id", id, "error", err)
+		return status.Error(codes.Internal, "internal error")
+	}
+	return stream.SendAndClose(&pb.UploadBlobResponse{Id: id, Created: created, Size: int64(buf.Len())})
+}
+
+// DownloadBlob отдаёт блоб частями по blobChunkSize; первая часть содержит id, nonce и size.
+func (s *Server) DownloadBlob(req *pb.DownloadBlobRequest, stream pb.GophKeeper_DownloadBlobServer) error {
+	ctx := stream.Context()
+	userID, _ := middleware.GetUserIDFromContext(ctx)
+
+	meta, data, err := s.ItemService.LoadBlob(ctx, userID, req.GetId())
+	if errors.Is(err, repo.ErrBlobNotFound) {
+		return status.Error(codes.NotFound, "blob not found")
+	}
+	if err != nil {
+		s.log(ctx).Errorw("grpc DownloadBlob: service error", "id", req.GetId(), "error", err)
+		return status.Error(codes.Internal, "internal error")
+	}
+	chunk := &pb.BlobChunk{Id: meta.ID, Nonce: meta.Nonce, Size: int64(len(data))}
+	for {
+		n := min(len(data), blobChunkSize)
+		chunk.Data = data[:n]
+		if err := stream.Send(chunk); err != nil {
+			return err
+		}
+		data = data[n:]
+		if len(data) == 0 {
+			return nil
+		}
+		chunk = &pb.BlobChunk{}
+	}
+}
+
+// GetItem возвращает полный снимок записи пользователя.
+func (s *Server) GetItem(ctx context.Context, req *pb.GetItemRequest) (*pb.Item, error) {
+	userID, _ := middleware.GetUserIDFromContext(ctx)
+	it, err := s.ItemService.GetItem(ctx, userID, req.GetId())
+	if errors.Is(err, service.ErrItemNotFound) {
+		return nil, status.Error(codes.NotFound, "item not found")
+	}
+	if err != nil {
+		s.log(ctx).Errorw("grpc GetItem: service error", "id", req.GetId(), "error", err)
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+	return itemToPB(*it), nil
+}
+
+// log — логгер сервера с request_id и trace_id вызова.
+func (s *Server) log(ctx context.Context) *zap.SugaredLogger {
+	return logging.FromContext(ctx, s.Logger)
+}
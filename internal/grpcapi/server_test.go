@@ -0,0 +1,488 @@
+package grpcapi
+
+import (
+	"GophKeeper/internal/config"
+	"GophKeeper/internal/middleware"
+	"GophKeeper/internal/model"
+	"GophKeeper/internal/pb"
+	"GophKeeper/internal/repo"
+	"GophKeeper/internal/service"
+	"GophKeeper/internal/tlsutil"
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	gormsqlite "gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	_ "modernc.org/sqlite"
+)
+
+// newTestClient поднимает gRPC-сервер на bufconn поверх настоящих репозиториев (SQLite в памяти).
+func newTestClient(t *testing.T) pb.GophKeeperClient {
+	t.Helper()
+	c, _ := newTestServer(t)
+	return c
+}
+
+// newTestServer — как newTestClient, но ещё возвращает сервис записей для подготовки данных.
+func newTestServer(t *testing.T) (pb.GophKeeperClient, *service.ItemService) {
+	t.Helper()
+	dsn := "file:" + strings.ReplaceAll(t.Name(), "/", "_") + "?mode=memory&cache=shared"
+	db, err := gorm.Open(gormsqlite.Dialector{DriverName: "sqlite", DSN: dsn}, &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&model.User{}, &model.Item{}, &model.Blob{}, &model.ItemVersion{}, &model.UserChangeSeq{},
+		&model.Organization{}, &model.OrgMember{}, &model.Collection{}, &model.CollectionKey{}, &model.CollectionItem{}))
+	store, err := repo.NewFSBlobStore(t.TempDir())
+	require.NoError(t, err)
+
+	logger := zap.NewNop().Sugar()
+	cfg := &config.Config{AuthSecret: "test-secret", BlobMaxSizeMB: 1}
+	itemSvc := service.NewItemService(repo.NewItemRepository(db), repo.NewBlobRepository(db), store, logger)
+	itemSvc.SetOrgRepository(repo.NewOrgRepository(db))
+	gs := NewServer(service.NewUserService(repo.NewUserRepository(db)), itemSvc, logger, cfg)
+
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = gs.Serve(lis) }()
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return pb.NewGophKeeperClient(conn), itemSvc
+}
+
+// registerCtx регистрирует пользователя и возвращает контекст с его токеном.
+func registerCtx(t *testing.T, c pb.GophKeeperClient, login string) context.Context {
+	t.Helper()
+	resp, err := c.Register(context.Background(), &pb.Credentials{Login: login, Password: "pw"})
+	require.NoError(t, err)
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+resp.GetToken())
+}
+
+func recvAll(t *testing.T, stream pb.GophKeeper_SyncClient) []*pb.SyncResponse {
+	t.Helper()
+	var pages []*pb.SyncResponse
+	for {
+		page, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return pages
+		}
+		require.NoError(t, err)
+		pages = append(pages, page)
+	}
+}
+
+func TestServer_RegisterLogin(t *testing.T) {
+	c := newTestClient(t)
+	ctx := context.Background()
+
+	_, err := c.Register(ctx, &pb.Credentials{Login: "ann", Password: "pw"})
+	require.NoError(t, err)
+	_, err = c.Register(ctx, &pb.Credentials{Login: "ann", Password: "pw"})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	_, err = c.Register(ctx, &pb.Credentials{Login: "", Password: "pw"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	resp, err := c.Login(ctx, &pb.Credentials{Login: "ann", Password: "pw"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.GetToken())
+	_, err = c.Login(ctx, &pb.Credentials{Login: "ann", Password: "bad"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestServer_RequestID(t *testing.T) {
+	c := newTestClient(t)
+
+	// id клиента возвращается в заголовке ответа, в том числе при ошибке
+	var header metadata.MD
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "cli-req-1")
+	_, err := c.Login(ctx, &pb.Credentials{Login: "nobody", Password: "pw"}, grpc.Header(&header))
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, []string{"cli-req-1"}, header.Get("x-request-id"))
+
+	// без id клиента сервер генерирует свой
+	stream, err := c.Sync(context.Background(), &pb.SyncRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	header, err = stream.Header()
+	require.NoError(t, err)
+	assert.NotEmpty(t, header.Get("x-request-id"))
+}
+
+func TestServer_RequiresToken(t *testing.T) {
+	c := newTestClient(t)
+	stream, err := c.Sync(context.Background(), &pb.SyncRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	bad := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer nope")
+	down, err := c.DownloadBlob(bad, &pb.DownloadBlobRequest{Id: "x"})
+	require.NoError(t, err)
+	_, err = down.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestServer_SyncStreamsPages(t *testing.T) {
+	c := newTestClient(t)
+	ctx := registerCtx(t, c, "bob")
+
+	v0 := int64(0)
+	changes := make([]*pb.ItemChange, 0, 3)
+	for _, name := range []string{"a", "b", "c"} {
+		n := name
+		changes = append(changes, &pb.ItemChange{Id: uuid.NewString(), Version: &v0, Name: &n, PasswordCipher: []byte{0, 1, 2}, PasswordNonce: []byte{9}})
+	}
+	stream, err := c.Sync(ctx, &pb.SyncRequest{Changes: changes, Limit: 2})
+	require.NoError(t, err)
+	pages := recvAll(t, stream)
+
+	require.Len(t, pages, 2)
+	assert.Len(t, pages[0].GetApplied(), 3)
+	assert.True(t, pages[0].GetHasMore())
+	assert.Len(t, pages[0].GetServerChanges(), 2)
+	assert.False(t, pages[1].GetHasMore())
+	assert.Len(t, pages[1].GetServerChanges(), 1)
+	assert.Empty(t, pages[1].GetApplied())
+	// шифртексты приходят байтами, без base64
+	assert.Equal(t, []byte{0, 1, 2}, pages[0].GetServerChanges()[0].GetPasswordCipher())
+
+	// с последним курсором новых изменений нет
+	stream, err = c.Sync(ctx, &pb.SyncRequest{Cursor: pages[1].GetCursor()})
+	require.NoError(t, err)
+	pages = recvAll(t, stream)
+	require.Len(t, pages, 1)
+	assert.Empty(t, pages[0].GetServerChanges())
+}
+
+func TestServer_SyncConflictCarriesServerItem(t *testing.T) {
+	c := newTestClient(t)
+	ctx := registerCtx(t, c, "eve")
+
+	id := uuid.NewString()
+	v0, v1 := int64(0), int64(1)
+	name, other := "mail", "other"
+	stream, err := c.Sync(ctx, &pb.SyncRequest{Changes: []*pb.ItemChange{{Id: id, Version: &v0, Name: &name}}})
+	require.NoError(t, err)
+	recvAll(t, stream)
+	stream, err = c.Sync(ctx, &pb.SyncRequest{Changes: []*pb.ItemChange{{Id: id, Version: &v1, Name: &other}}})
+	require.NoError(t, err)
+	recvAll(t, stream)
+
+	// устаревшая версия — конфликт с полным server_item (resolve=server)
+	stream, err = c.Sync(ctx, &pb.SyncRequest{Resolve: "server", Changes: []*pb.ItemChange{{Id: id, Version: &v1, Name: &name}}})
+	require.NoError(t, err)
+	pages := recvAll(t, stream)
+	require.Len(t, pages[0].GetConflicts(), 1)
+	si := pages[0].GetConflicts()[0].GetServerItem()
+	assert.Equal(t, id, si.GetId())
+	assert.Equal(t, int64(2), si.GetVersion())
+	assert.Equal(t, "other", si.GetName())
+
+	stream, err = c.Sync(ctx, &pb.SyncRequest{Cursor: "-1"})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	stream, err = c.Sync(ctx, &pb.SyncRequest{Resolutions: map[string]string{id: "mine"}})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestServer_SyncCarriesCollections(t *testing.T) {
+	c, itemSvc := newTestServer(t)
+	resp, err := c.Register(context.Background(), &pb.Credentials{Login: "olga", Password: "pw"})
+	require.NoError(t, err)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+resp.GetToken())
+	userID, ok := middleware.ParseToken(resp.GetToken(), "test-secret")
+	require.True(t, ok)
+
+	key := &model.CollectionKey{EphemeralKey: []byte{1}, WrappedKey: []byte{2}, WrappedKeyNonce: []byte{3}}
+	m, err := itemSvc.CreateOrg(context.Background(), userID, "acme", "", key)
+	require.NoError(t, err)
+	colID := m.Collections[0].ID
+	_, err = itemSvc.SaveCollectionItem(context.Background(), userID, &model.CollectionItem{
+		ID: uuid.NewString(), CollectionID: colID, Name: "wifi", TextCipher: []byte{7}, TextNonce: []byte{8},
+	}, 0)
+	require.NoError(t, err)
+
+	v0 := int64(0)
+	changes := make([]*pb.ItemChange, 0, 2)
+	for _, name := range []string{"a", "b"} {
+		n := name
+		changes = append(changes, &pb.ItemChange{Id: uuid.NewString(), Version: &v0, Name: &n})
+	}
+	stream, err := c.Sync(ctx, &pb.SyncRequest{Changes: changes, Limit: 1})
+	require.NoError(t, err)
+	pages := recvAll(t, stream)
+	require.Len(t, pages, 2)
+
+	// коллекции приходят только на последней странице
+	assert.False(t, pages[0].GetHasCollections())
+	assert.Empty(t, pages[0].GetCollections())
+	last := pages[1]
+	require.True(t, last.GetHasCollections())
+	require.Len(t, last.GetCollections(), 1)
+	col := last.GetCollections()[0]
+	assert.Equal(t, colID, col.GetId())
+	assert.Equal(t, "default", col.GetName())
+	assert.Equal(t, "acme", col.GetOrg())
+	assert.Equal(t, model.RoleOwner, col.GetRole())
+	assert.Equal(t, []byte{2}, col.GetWrappedKey())
+	require.Len(t, col.GetItems(), 1)
+	assert.Equal(t, "wifi", col.GetItems()[0].GetName())
+	assert.Equal(t, int64(1), col.GetItems()[0].GetVersion())
+	assert.Equal(t, []byte{7}, col.GetItems()[0].GetTextCipher())
+
+	// без организаций — пустой, но переданный список: клиент очистит локальный снимок
+	stream, err = c.Sync(registerCtx(t, c, "oleg"), &pb.SyncRequest{})
+	require.NoError(t, err)
+	pages = recvAll(t, stream)
+	require.Len(t, pages, 1)
+	assert.True(t, pages[0].GetHasCollections())
+	assert.Empty(t, pages[0].GetCollections())
+}
+
+func TestServer_GetItem(t *testing.T) {
+	c := newTestClient(t)
+	ctx := registerCtx(t, c, "gia")
+	v0 := int64(0)
+	name := "mail"
+	stream, err := c.Sync(ctx, &pb.SyncRequest{Changes: []*pb.ItemChange{{Id: "g1", Version: &v0, Name: &name, TextCipher: []byte{1}, TextNonce: []byte{2}}}})
+	require.NoError(t, err)
+	recvAll(t, stream)
+
+	it, err := c.GetItem(ctx, &pb.GetItemRequest{Id: "g1"})
+	require.NoError(t, err)
+	assert.Equal(t, "mail", it.GetName())
+	assert.Equal(t, int64(1), it.GetVersion())
+	assert.Equal(t, []byte{1}, it.GetTextCipher())
+
+	_, err = c.GetItem(ctx, &pb.GetItemRequest{Id: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	// чужая запись неотличима от отсутствующей
+	_, err = c.GetItem(registerCtx(t, c, "gib"), &pb.GetItemRequest{Id: "g1"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestServer_BlobUploadDownloadRoundTrip(t *testing.T) {
+	c := newTestClient(t)
+	ctx := registerCtx(t, c, "kim")
+
+	id := uuid.NewString()
+	data := bytes.Repeat([]byte("0123456789abcdef"), 10_000) // 160 КБ — несколько частей
+	up, err := c.UploadBlob(ctx)
+	require.NoError(t, err)
+	require.NoError(t, up.Send(&pb.BlobChunk{Id: id, Nonce: []byte{7}, Data: data[:100_000]}))
+	require.NoError(t, up.Send(&pb.BlobChunk{Data: data[100_000:]}))
+	res, err := up.CloseAndRecv()
+	require.NoError(t, err)
+	assert.True(t, res.GetCreated())
+	assert.Equal(t, int64(len(data)), res.GetSize())
+
+	down, err := c.DownloadBlob(ctx, &pb.DownloadBlobRequest{Id: id})
+	require.NoError(t, err)
+	var got bytes.Buffer
+	chunks := 0
+	for {
+		ch, err := down.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		if chunks == 0 {
+			assert.Equal(t, []byte{7}, ch.GetNonce())
+			assert.Equal(t, int64(len(data)), ch.GetSize())
+		}
+		chunks++
+		got.Write(ch.GetData())
+	}
+	assert.Equal(t, data, got.Bytes())
+	assert.Greater(t, chunks, 1)
+
+	// чужой блоб не отдаётся
+	other := registerCtx(t, c, "lee")
+	down, err = c.DownloadBlob(other, &pb.DownloadBlobRequest{Id: id})
+	require.NoError(t, err)
+	_, err = down.Recv()
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestServer_BlobUploadLimits(t *testing.T) {
+	c := newTestClient(t)
+	ctx := registerCtx(t, c, "max")
+
+	up, err := c.UploadBlob(ctx)
+	require.NoError(t, err)
+	require.NoError(t, up.Send(&pb.BlobChunk{Data: []byte{1}}))
+	_, err = up.CloseAndRecv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	up, err = c.UploadBlob(ctx)
+	require.NoError(t, err)
+	big := make([]byte, 600*1024)
+	_ = up.Send(&pb.BlobChunk{Id: uuid.NewString(), Nonce: []byte{1}, Data: big})
+	_ = up.Send(&pb.BlobChunk{Data: big})
+	_, err = up.CloseAndRecv()
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+// С grpc.Creds сервер принимает только TLS-соединения с сертификатом из tlsutil.
+func TestServer_TLS(t *testing.T) {
+	certFile, keyFile, err := tlsutil.EnsureSelfSigned(t.TempDir(), tlsutil.DevHosts())
+	require.NoError(t, err)
+	certs, err := tlsutil.NewCertReloader(certFile, keyFile)
+	require.NoError(t, err)
+
+	dsn := "file:" + t.Name() + "?mode=memory&cache=shared"
+	db, err := gorm.Open(gormsqlite.Dialector{DriverName: "sqlite", DSN: dsn}, &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&model.User{}))
+	logger := zap.NewNop().Sugar()
+	gs := NewServer(service.NewUserService(repo.NewUserRepository(db)), nil, logger, &config.Config{AuthSecret: "s"},
+		grpc.Creds(credentials.NewTLS(tlsutil.ServerConfig(certs))))
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = gs.Serve(lis) }()
+	t.Cleanup(gs.Stop)
+
+	pemBytes, err := os.ReadFile(certFile)
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(pemBytes))
+
+	dial := func(creds credentials.TransportCredentials) pb.GophKeeperClient {
+		conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(creds))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = conn.Close() })
+		return pb.NewGophKeeperClient(conn)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := dial(credentials.NewTLS(&tls.Config{RootCAs: roots})).Register(ctx, &pb.Credentials{Login: "tls", Password: "p"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.GetToken())
+
+	_, err = dial(insecure.NewCredentials()).Login(ctx, &pb.Credentials{Login: "tls", Password: "p"})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+// deviceCert выпускает самоподписанный клиентский сертификат; его PEM служит и CA для сервера.
+func deviceCert(t *testing.T, dir, cn string) (tls.Certificate, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	caFile := filepath.Join(dir, cn+".pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644))
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, caFile
+}
+
+// С TLS_CLIENT_CA вход требует сертификат устройства, а токен работает только с тем же сертификатом.
+func TestServer_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, err := tlsutil.EnsureSelfSigned(dir, tlsutil.DevHosts())
+	require.NoError(t, err)
+	certs, err := tlsutil.NewCertReloader(certFile, keyFile)
+	require.NoError(t, err)
+	laptop, caFile := deviceCert(t, dir, "laptop")
+	serverTLS := tlsutil.ServerConfig(certs)
+	require.NoError(t, tlsutil.EnableClientAuth(serverTLS, caFile))
+
+	dsn := "file:" + t.Name() + "?mode=memory&cache=shared"
+	db, err := gorm.Open(gormsqlite.Dialector{DriverName: "sqlite", DSN: dsn}, &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&model.User{}, &model.Device{}, &model.Blob{}))
+	store, err := repo.NewFSBlobStore(t.TempDir())
+	require.NoError(t, err)
+	logger := zap.NewNop().Sugar()
+	userSvc := service.NewUserService(repo.NewUserRepository(db))
+	userSvc.SetDeviceRepository(repo.NewDeviceRepository(db))
+	itemSvc := service.NewItemService(repo.NewItemRepository(db), repo.NewBlobRepository(db), store, logger)
+	itemSvc.SetOrgRepository(repo.NewOrgRepository(db))
+	gs := NewServer(userSvc, itemSvc, logger, &config.Config{AuthSecret: "s", TLSClientCA: caFile},
+		grpc.Creds(credentials.NewTLS(serverTLS)))
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = gs.Serve(lis) }()
+	t.Cleanup(gs.Stop)
+
+	pemBytes, err := os.ReadFile(certFile)
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(pemBytes))
+	dial := func(certs ...tls.Certificate) pb.GophKeeperClient {
+		conn, err := grpc.NewClient(lis.Addr().String(),
+			grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{RootCAs: roots, Certificates: certs})))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = conn.Close() })
+		return pb.NewGophKeeperClient(conn)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	download := func(c pb.GophKeeperClient, token string) codes.Code {
+		stream, err := c.DownloadBlob(metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token),
+			&pb.DownloadBlobRequest{Id: uuid.NewString()})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		return status.Code(err)
+	}
+
+	anonymous, withCert := dial(), dial(laptop)
+	_, err = anonymous.Register(ctx, &pb.Credentials{Login: "mtls", Password: "p"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	resp, err := withCert.Register(ctx, &pb.Credentials{Login: "mtls", Password: "p"})
+	require.NoError(t, err)
+	assert.Equal(t, codes.NotFound, download(withCert, resp.GetToken()), "токен принят с тем же сертификатом")
+	assert.Equal(t, codes.Unauthenticated, download(anonymous, resp.GetToken()), "украденного токена недостаточно")
+
+	unbound, err := middleware.NewToken(1, "s")
+	require.NoError(t, err)
+	assert.Equal(t, codes.Unauthenticated, download(withCert, unbound))
+
+	// сертификат уже привязан к mtls, другой пользователь войти с ним не может
+	_, err = withCert.Register(ctx, &pb.Credentials{Login: "other", Password: "p"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"GophKeeper/internal/middleware"
+	"GophKeeper/internal/model"
+	"GophKeeper/internal/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// DataItem — представление элемента в REST API /api/data.
+// Шифрованные поля ([]byte) кодируются в JSON как base64-строки.
+type DataItem struct {
+	ID             string  `json:"id"`
+	Name           string  `json:"name"`
+	FileName       string  `json:"file_name,omitempty"`
+	BlobID         *string `json:"blob_id,omitempty"`
+	Version        int64   `json:"version"`
+	Deleted        bool    `json:"deleted"`
+	LoginCipher    []byte  `json:"login_cipher,omitempty"`
+	LoginNonce     []byte  `json:"login_nonce,omitempty"`
+	PasswordCipher []byte  `json:"password_cipher,omitempty"`
+	PasswordNonce  []byte  `json:"password_nonce,omitempty"`
+	TextCipher     []byte  `json:"text_cipher,omitempty"`
+	TextNonce      []byte  `json:"text_nonce,omitempty"`
+	CardCipher     []byte  `json:"card_cipher,omitempty"`
+	CardNonce      []byte  `json:"card_nonce,omitempty"`
+	CreatedAt      string  `json:"created_at,omitempty"`
+	UpdatedAt      string  `json:"updated_at,omitempty"`
+}
+
+// DataItemRequest — тело POST /api/data и PUT /api/data/{id}.
+// PUT заменяет все изменяемые поля: не переданные шифрованные поля очищаются.
+type DataItemRequest struct {
+	ID             string  `json:"id,omitempty"`
+	Name           string  `json:"name"`
+	FileName       string  `json:"file_name,omitempty"`
+	BlobID         *string `json:"blob_id,omitempty"`
+	LoginCipher    []byte  `json:"login_cipher,omitempty"`
+	LoginNonce     []byte  `json:"login_nonce,omitempty"`
+	PasswordCipher []byte  `json:"password_cipher,omitempty"`
+	PasswordNonce  []byte  `json:"password_nonce,omitempty"`
+	TextCipher     []byte  `json:"text_cipher,omitempty"`
+	TextNonce      []byte  `json:"text_nonce,omitempty"`
+	CardCipher     []byte  `json:"card_cipher,omitempty"`
+	CardNonce      []byte  `json:"card_nonce,omitempty"`
+}
+
+// ListData GET /api/data — список неудалённых элементов пользователя
+func (h *ItemHandler) ListData(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	items, err := h.ItemService.ListItems(r.Context(), userID)
+	if err != nil {
+		h.log(r).Errorw("ListData: service error", "user_id", userID, "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	out := make([]DataItem, 0, len(items))
+	for i := range items {
+		out = append(out, toDataItem(&items[i]))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// GetData GET /api/data/{id}
+func (h *ItemHandler) GetData(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id := chi.URLParam(r, "id")
+	it, err := h.ItemService.GetItem(r.Context(), userID, id)
+	if err != nil {
+		h.writeDataError(w, r, "GetData", userID, id, err)
+		return
+	}
+	writeDataItem(w, http.StatusOK, it)
+}
+
+// CreateData POST /api/data — создаёт элемент (id генерируется, если не передан)
+func (h *ItemHandler) CreateData(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req DataItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log(r).Warnw("CreateData: invalid request body", "error", err)
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	if req.ID == "" {
+		req.ID = uuid.NewString()
+	} else if _, err := uuid.Parse(req.ID); err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+	it, err := h.ItemService.CreateItem(r.Context(), userID, req.toChange(req.ID))
+	if err != nil {
+		h.writeDataError(w, r, "CreateData", userID, req.ID, err)
+		return
+	}
+	w.Header().Set("Location", "/api/data/"+it.ID)
+	writeDataItem(w, http.StatusCreated, it)
+}
+
+// UpdateData PUT /api/data/{id} — замена полей элемента; требует If-Match с текущей версией
+func (h *ItemHandler) UpdateData(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id := chi.URLParam(r, "id")
+	version, ok := parseIfMatch(r)
+	if !ok {
+		http.Error(w, "If-Match header with item version is required", http.StatusPreconditionRequired)
+		return
+	}
+	var req DataItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.log(r).Warnw("UpdateData: invalid request body", "error", err)
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	if req.ID != "" && req.ID != id {
+		http.Error(w, "id in body does not match URL", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+	it, err := h.ItemService.UpdateItem(r.Context(), userID, id, version, req.toReplaceChange(id))
+	if err != nil {
+		h.writeDataError(w, r, "UpdateData", userID, id, err)
+		return
+	}
+	writeDataItem(w, http.StatusOK, it)
+}
+
+// DeleteData DELETE /api/data/{id} — мягкое удаление; требует If-Match с текущей версией
+func (h *ItemHandler) DeleteData(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id := chi.URLParam(r, "id")
+	version, ok := parseIfMatch(r)
+	if !ok {
+		http.Error(w, "If-Match header with item version is required", http.StatusPreconditionRequired)
+		return
+	}
+	it, err := h.ItemService.DeleteItem(r.Context(), userID, id, version)
+	if err != nil {
+		h.writeDataError(w, r, "DeleteData", userID, id, err)
+		return
+	}
+	w.Header().Set("ETag", formatETag(it.Version))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeDataError маппит ошибки сервиса в HTTP-статусы.
+func (h *ItemHandler) writeDataError(w http.ResponseWriter, r *http.Request, op string, userID int64, id string, err error) {
+	switch {
+	case errors.Is(err, service.ErrItemNotFound):
+		http.Error(w, "not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrItemExists):
+		http.Error(w, "item already exists", http.StatusConflict)
+	case errors.Is(err, service.ErrVersionMismatch):
+		http.Error(w, "version mismatch", http.StatusConflict)
+	case errors.Is(err, service.ErrItemTooLarge):
+		http.Error(w, "item too large", http.StatusRequestEntityTooLarge)
+	case errors.Is(err, service.ErrQuotaExceeded):
+		http.Error(w, "storage quota exceeded", http.StatusInsufficientStorage)
+	default:
+		h.log(r).Errorw(op+": service error", "user_id", userID, "item_id", id, "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
+
+// toChange переводит тело запроса в модель изменения сервиса (только переданные поля).
+func (req DataItemRequest) toChange(id string) service.SyncChange {
+	name := req.Name
+	fileName := req.FileName
+	return service.SyncChange{
+		ID:             id,
+		Name:           &name,
+		FileName:       &fileName,
+		BlobID:         req.BlobID,
+		LoginCipher:    req.LoginCipher,
+		LoginNonce:     req.LoginNonce,
+		PasswordCipher: req.PasswordCipher,
+		PasswordNonce:  req.PasswordNonce,
+		TextCipher:     req.TextCipher,
+		TextNonce:      req.TextNonce,
+		CardCipher:     req.CardCipher,
+		CardNonce:      req.CardNonce,
+	}
+}
+
+// toReplaceChange как toChange, но не переданные поля очищаются (семантика PUT).
+func (req DataItemRequest) toReplaceChange(id string) service.SyncChange {
+	ch := req.toChange(id)
+	if ch.BlobID == nil {
+		empty := ""
+		ch.BlobID = &empty
+	}
+	for _, f := range []*[]byte{
+		&ch.LoginCipher, &ch.LoginNonce,
+		&ch.PasswordCipher, &ch.PasswordNonce,
+		&ch.TextCipher, &ch.TextNonce,
+		&ch.CardCipher, &ch.CardNonce,
+	} {
+		if *f == nil {
+			*f = []byte{}
+		}
+	}
+	return ch
+}
+
+func toDataItem(it *model.Item) DataItem {
+	var blobID *string
+	if it.BlobID != nil && *it.BlobID != "" {
+		s := *it.BlobID
+		blobID = &s
+	}
+	return DataItem{
+		ID:             it.ID,
+		Name:           it.Name,
+		FileName:       it.FileName,
+		BlobID:         blobID,
+		Version:        it.Version,
+		Deleted:        it.Deleted,
+		LoginCipher:    it.LoginCipher,
+		LoginNonce:     it.LoginNonce,
+		PasswordCipher: it.PasswordCipher,
+		PasswordNonce:  it.PasswordNonce,
+		TextCipher:     it.TextCipher,
+		TextNonce:      it.TextNonce,
+		CardCipher:     it.CardCipher,
+		CardNonce:      it.CardNonce,
+		CreatedAt:      formatTime(it.CreatedAt),
+		UpdatedAt:      formatTime(it.UpdatedAt),
+	}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func writeDataItem(w http.ResponseWriter, status int, it *model.Item) {
+	w.Header().Set("ETag", formatETag(it.Version))
+	writeJSON(w, status, toDataItem(it))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// formatETag версия элемента как сильный ETag: "3".
+func formatETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseIfMatch читает версию из If-Match: допускаются `3`, `"3"` и `W/"3"`.
+func parseIfMatch(r *http.Request) (int64, bool) {
+	v := strings.TrimSpace(r.Header.Get("If-Match"))
+	v = strings.TrimPrefix(v, "W/")
+	v = strings.Trim(v, `"`)
+	if v == "" {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
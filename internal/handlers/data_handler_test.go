@@ -0,0 +1,174 @@
+package handlers_test
+
+import (
+	"GophKeeper/internal/handlers"
+	"GophKeeper/internal/model"
+	"GophKeeper/internal/repo"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+const dataItemID = "11111111-2222-3333-4444-555555555555"
+
+func TestData_Unauthorized(t *testing.T) {
+	r, _, _, _ := newItemTestRouter(t)
+	req := httptest.NewRequest(http.MethodGet, "/api/data", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func TestData_List(t *testing.T) {
+	r, cfg, ir, _ := newItemTestRouter(t)
+	ir.On("ListAll", mock.Anything, int64(1)).Return([]model.Item{
+		{ID: "a", Name: "mail", Version: 2, TextCipher: []byte{1, 2}},
+		{ID: "b", Name: "gone", Deleted: true},
+	}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/data", nil)
+	addItemAuthCookie(t, req, 1, cfg.AuthSecret)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var out []handlers.DataItem
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &out))
+	if assert.Len(t, out, 1) {
+		assert.Equal(t, "mail", out[0].Name)
+		assert.Equal(t, []byte{1, 2}, out[0].TextCipher)
+	}
+	assert.Contains(t, rr.Body.String(), `"text_cipher":"AQI="`)
+}
+
+func TestData_Get(t *testing.T) {
+	r, cfg, ir, _ := newItemTestRouter(t)
+	ir.On("GetByID", mock.Anything, int64(1), dataItemID).Return(&model.Item{ID: dataItemID, Name: "n", Version: 3}, nil).Once()
+	ir.On("GetByID", mock.Anything, int64(1), "missing").Return(nil, gorm.ErrRecordNotFound).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/data/"+dataItemID, nil)
+	addItemAuthCookie(t, req, 1, cfg.AuthSecret)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `"3"`, rr.Header().Get("ETag"))
+
+	req = httptest.NewRequest(http.MethodGet, "/api/data/missing", nil)
+	addItemAuthCookie(t, req, 1, cfg.AuthSecret)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestData_Create(t *testing.T) {
+	r, cfg, ir, _ := newItemTestRouter(t)
+	ir.On("GetByID", mock.Anything, int64(1), dataItemID).Return(nil, gorm.ErrRecordNotFound).Once()
+	ir.On("Create", mock.Anything, mock.MatchedBy(func(it *model.Item) bool {
+		return it.ID == dataItemID && it.Name == "card" && it.Version == 1
+	})).Return(nil).Once()
+
+	body := `{"id":"` + dataItemID + `","name":"card","card_cipher":"AQI=","card_nonce":"AwQ="}`
+	req := httptest.NewRequest(http.MethodPost, "/api/data", strings.NewReader(body))
+	addItemAuthCookie(t, req, 1, cfg.AuthSecret)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, "/api/data/"+dataItemID, rr.Header().Get("Location"))
+	assert.Equal(t, `"1"`, rr.Header().Get("ETag"))
+	ir.AssertExpectations(t)
+
+	// повторное создание того же id — конфликт
+	ir.On("GetByID", mock.Anything, int64(1), dataItemID).Return(&model.Item{ID: dataItemID}, nil).Once()
+	req = httptest.NewRequest(http.MethodPost, "/api/data", strings.NewReader(body))
+	addItemAuthCookie(t, req, 1, cfg.AuthSecret)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+}
+
+func TestData_Create_IDOfAnotherUser(t *testing.T) {
+	r, cfg, ir, _ := newItemTestRouter(t)
+	// у пользователя 1 записи нет, но id занят записью другого пользователя
+	ir.On("GetByID", mock.Anything, int64(1), dataItemID).Return(nil, gorm.ErrRecordNotFound).Once()
+	ir.On("Create", mock.Anything, mock.AnythingOfType("*model.Item")).Return(repo.ErrItemExists).Once()
+
+	body := `{"id":"` + dataItemID + `","name":"card"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/data", strings.NewReader(body))
+	addItemAuthCookie(t, req, 1, cfg.AuthSecret)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	ir.AssertExpectations(t)
+}
+
+func TestData_Create_BadRequests(t *testing.T) {
+	r, cfg, _, _ := newItemTestRouter(t)
+	for _, body := range []string{`{`, `{"name":""}`, `{"id":"not-a-uuid","name":"x"}`} {
+		req := httptest.NewRequest(http.MethodPost, "/api/data", strings.NewReader(body))
+		addItemAuthCookie(t, req, 1, cfg.AuthSecret)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, body)
+	}
+}
+
+func TestData_Update(t *testing.T) {
+	r, cfg, ir, _ := newItemTestRouter(t)
+
+	// без If-Match
+	req := httptest.NewRequest(http.MethodPut, "/api/data/"+dataItemID, strings.NewReader(`{"name":"x"}`))
+	addItemAuthCookie(t, req, 1, cfg.AuthSecret)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusPreconditionRequired, rr.Code)
+
+	// устаревшая версия
+	ir.On("GetByID", mock.Anything, int64(1), dataItemID).Return(&model.Item{ID: dataItemID, Version: 5}, nil).Once()
+	req = httptest.NewRequest(http.MethodPut, "/api/data/"+dataItemID, strings.NewReader(`{"name":"x"}`))
+	req.Header.Set("If-Match", `"4"`)
+	addItemAuthCookie(t, req, 1, cfg.AuthSecret)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	// успешная замена: непереданные поля очищаются
+	ir.On("GetByID", mock.Anything, int64(1), dataItemID).Return(&model.Item{ID: dataItemID, Version: 5, TextCipher: []byte{9}}, nil).Once()
+	ir.On("UpdateWithVersion", mock.Anything, int64(1), dataItemID, int64(5), mock.MatchedBy(func(m map[string]any) bool {
+		tc, ok := m["text_cipher"].([]byte)
+		return m["name"] == "x" && ok && len(tc) == 0
+	})).Return(int64(6), nil).Once()
+	ir.On("GetByID", mock.Anything, int64(1), dataItemID).Return(&model.Item{ID: dataItemID, Name: "x", Version: 6}, nil).Once()
+
+	req = httptest.NewRequest(http.MethodPut, "/api/data/"+dataItemID, strings.NewReader(`{"name":"x"}`))
+	req.Header.Set("If-Match", `W/"5"`)
+	addItemAuthCookie(t, req, 1, cfg.AuthSecret)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `"6"`, rr.Header().Get("ETag"))
+	ir.AssertExpectations(t)
+}
+
+func TestData_Delete(t *testing.T) {
+	r, cfg, ir, _ := newItemTestRouter(t)
+	ir.On("GetByID", mock.Anything, int64(1), dataItemID).Return(&model.Item{ID: dataItemID, Version: 2}, nil).Once()
+	ir.On("UpdateWithVersion", mock.Anything, int64(1), dataItemID, int64(2), map[string]any{"deleted": true}).Return(int64(3), nil).Once()
+	ir.On("GetByID", mock.Anything, int64(1), dataItemID).Return(&model.Item{ID: dataItemID, Version: 3, Deleted: true}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/data/"+dataItemID, nil)
+	req.Header.Set("If-Match", "2")
+	addItemAuthCookie(t, req, 1, cfg.AuthSecret)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Equal(t, `"3"`, rr.Header().Get("ETag"))
+	ir.AssertExpectations(t)
+}
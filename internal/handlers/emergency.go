@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"GophKeeper/internal/middleware"
+	"GophKeeper/internal/model"
+	"GophKeeper/internal/repo"
+	"GophKeeper/internal/service"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// EmergencyGrantRequest — тело PUT /api/emergency/contacts/{login}: период ожидания
+// и ключ хранилища владельца, запечатанный открытым ключом контакта.
+type EmergencyGrantRequest struct {
+	WaitDays int `json:"wait_days"`
+	SealedKey
+}
+
+// EmergencyAccessView — экстренный доступ в списках и ответе на запрос. Login — контакт
+// в списке владельца и владелец в списке контакта; available_at — когда запрос будет
+// одобрен автоматически.
+type EmergencyAccessView struct {
+	Login       string `json:"login"`
+	Status      string `json:"status"`
+	WaitDays    int    `json:"wait_days"`
+	RequestedAt string `json:"requested_at,omitempty"`
+	AvailableAt string `json:"available_at,omitempty"`
+}
+
+// EmergencyVaultView — ответ GET /api/emergency/grants/{owner}/vault: ключ хранилища
+// владельца, запечатанный для контакта, и неудалённые записи владельца.
+type EmergencyVaultView struct {
+	Owner string `json:"owner"`
+	SealedKey
+	Items []DataItem `json:"items"`
+}
+
+// EmergencyContacts GET /api/emergency/contacts — экстренные контакты пользователя
+func (h *ItemHandler) EmergencyContacts(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	list, err := h.ItemService.EmergencyContacts(r.Context(), userID)
+	if err != nil {
+		h.writeEmergencyError(w, r, "EmergencyContacts", userID, err)
+		return
+	}
+	out := make([]EmergencyAccessView, 0, len(list))
+	for i := range list {
+		out = append(out, toEmergencyAccessView(list[i].ContactLogin, &list[i].EmergencyAccess))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// GrantEmergency PUT /api/emergency/contacts/{login} — назначает экстренный контакт
+func (h *ItemHandler) GrantEmergency(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req EmergencyGrantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.WrappedKey) == 0 {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	key := &model.EmergencyAccess{EphemeralKey: req.EphemeralKey, WrappedKey: req.WrappedKey, WrappedKeyNonce: req.WrappedKeyNonce}
+	if err := h.ItemService.GrantEmergency(r.Context(), userID, chi.URLParam(r, "login"), req.WaitDays, key); err != nil {
+		h.writeEmergencyError(w, r, "GrantEmergency", userID, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RevokeEmergency DELETE /api/emergency/contacts/{login} — отзывает экстренный доступ
+func (h *ItemHandler) RevokeEmergency(w http.ResponseWriter, r *http.Request) {
+	h.emergencyOwnerAction(w, r, "RevokeEmergency", h.ItemService.RevokeEmergency)
+}
+
+// ApproveEmergency POST /api/emergency/contacts/{login}/approve — открывает доступ до истечения периода
+func (h *ItemHandler) ApproveEmergency(w http.ResponseWriter, r *http.Request) {
+	h.emergencyOwnerAction(w, r, "ApproveEmergency", h.ItemService.ApproveEmergency)
+}
+
+// RejectEmergency POST /api/emergency/contacts/{login}/reject — отклоняет запрос контакта
+func (h *ItemHandler) RejectEmergency(w http.ResponseWriter, r *http.Request) {
+	h.emergencyOwnerAction(w, r, "RejectEmergency", h.ItemService.RejectEmergency)
+}
+
+// EmergencyGrants GET /api/emergency/grants — владельцы, назначившие пользователя контактом
+func (h *ItemHandler) EmergencyGrants(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	list, err := h.ItemService.EmergencyGrants(r.Context(), userID)
+	if err != nil {
+		h.writeEmergencyError(w, r, "EmergencyGrants", userID, err)
+		return
+	}
+	out := make([]EmergencyAccessView, 0, len(list))
+	for i := range list {
+		out = append(out, toEmergencyAccessView(list[i].OwnerLogin, &list[i].EmergencyAccess))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// RequestEmergency POST /api/emergency/grants/{owner}/request — запрашивает экстренный доступ
+func (h *ItemHandler) RequestEmergency(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	owner := chi.URLParam(r, "owner")
+	e, err := h.ItemService.RequestEmergency(r.Context(), userID, owner)
+	if err != nil {
+		h.writeEmergencyError(w, r, "RequestEmergency", userID, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toEmergencyAccessView(owner, e))
+}
+
+// EmergencyVault GET /api/emergency/grants/{owner}/vault — ключ и записи владельца (только чтение)
+func (h *ItemHandler) EmergencyVault(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	owner := chi.URLParam(r, "owner")
+	e, items, err := h.ItemService.EmergencyVault(r.Context(), userID, owner)
+	if err != nil {
+		h.writeEmergencyError(w, r, "EmergencyVault", userID, err)
+		return
+	}
+	out := EmergencyVaultView{
+		Owner:     owner,
+		SealedKey: SealedKey{EphemeralKey: e.EphemeralKey, WrappedKey: e.WrappedKey, WrappedKeyNonce: e.WrappedKeyNonce},
+		Items:     make([]DataItem, 0, len(items)),
+	}
+	for i := range items {
+		out.Items = append(out.Items, toDataItem(&items[i]))
+	}
+	h.log(r).Infow("EmergencyVault: vault released", "user_id", userID, "owner", owner)
+	writeJSON(w, http.StatusOK, out)
+}
+
+// emergencyOwnerAction выполняет действие владельца над контактом {login} и отвечает 204.
+func (h *ItemHandler) emergencyOwnerAction(w http.ResponseWriter, r *http.Request, op string,
+	action func(ctx context.Context, ownerID int64, contactLogin string) error) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := action(r.Context(), userID, chi.URLParam(r, "login")); err != nil {
+		h.writeEmergencyError(w, r, op, userID, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeEmergencyError переводит ошибки экстренного доступа в ответ; остальные — как writeDataError.
+func (h *ItemHandler) writeEmergencyError(w http.ResponseWriter, r *http.Request, op string, userID int64, err error) {
+	switch {
+	case errors.Is(err, repo.ErrEmergencyNotFound):
+		http.Error(w, "emergency access not found", http.StatusNotFound)
+	case errors.Is(err, repo.ErrRecipientNotFound):
+		http.Error(w, "user not found", http.StatusNotFound)
+	case errors.Is(err, repo.ErrRecipientHasNoKey):
+		http.Error(w, "user has not published a public key", http.StatusConflict)
+	case errors.Is(err, service.ErrEmergencyNotRequested):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, service.ErrEmergencyPending):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrEmergencySelf), errors.Is(err, service.ErrInvalidWaitPeriod):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.writeDataError(w, r, op, userID, "", err)
+	}
+}
+
+func toEmergencyAccessView(login string, e *model.EmergencyAccess) EmergencyAccessView {
+	v := EmergencyAccessView{Login: login, Status: e.Status, WaitDays: e.WaitDays}
+	if e.RequestedAt != nil {
+		v.RequestedAt = formatTime(*e.RequestedAt)
+		v.AvailableAt = formatTime(*e.AvailableAt())
+	}
+	return v
+}
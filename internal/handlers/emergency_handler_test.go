@@ -0,0 +1,157 @@
+package handlers_test
+
+import (
+	"GophKeeper/internal/handlers"
+	"GophKeeper/internal/model"
+	"GophKeeper/internal/repo"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type hMockEmergencyRepo struct{ mock.Mock }
+
+func (m *hMockEmergencyRepo) User(ctx context.Context, login string) (int64, []byte, error) {
+	args := m.Called(ctx, login)
+	pub, _ := args.Get(1).([]byte)
+	return args.Get(0).(int64), pub, args.Error(2)
+}
+func (m *hMockEmergencyRepo) Save(ctx context.Context, e *model.EmergencyAccess) error {
+	return m.Called(ctx, e).Error(0)
+}
+func (m *hMockEmergencyRepo) Get(ctx context.Context, ownerID, contactID int64) (*model.EmergencyAccess, error) {
+	args := m.Called(ctx, ownerID, contactID)
+	v, _ := args.Get(0).(*model.EmergencyAccess)
+	return v, args.Error(1)
+}
+func (m *hMockEmergencyRepo) SetStatus(ctx context.Context, ownerID, contactID int64, status string, requestedAt *time.Time) error {
+	return m.Called(ctx, ownerID, contactID, status, requestedAt).Error(0)
+}
+func (m *hMockEmergencyRepo) Delete(ctx context.Context, ownerID, contactID int64) error {
+	return m.Called(ctx, ownerID, contactID).Error(0)
+}
+func (m *hMockEmergencyRepo) ListByOwner(ctx context.Context, ownerID int64) ([]model.EmergencyContact, error) {
+	args := m.Called(ctx, ownerID)
+	v, _ := args.Get(0).([]model.EmergencyContact)
+	return v, args.Error(1)
+}
+func (m *hMockEmergencyRepo) ListByContact(ctx context.Context, contactID int64) ([]model.EmergencyContact, error) {
+	args := m.Called(ctx, contactID)
+	v, _ := args.Get(0).([]model.EmergencyContact)
+	return v, args.Error(1)
+}
+
+var _ repo.EmergencyRepository = (*hMockEmergencyRepo)(nil)
+
+// stubEmergencyUsers заводит пользователей alice (1, владелец) и bob (2, контакт).
+func stubEmergencyUsers(er *hMockEmergencyRepo) *hMockEmergencyRepo {
+	er.On("User", mock.Anything, "alice").Return(int64(1), []byte("pub-a"), nil).Maybe()
+	er.On("User", mock.Anything, "bob").Return(int64(2), []byte("pub-b"), nil).Maybe()
+	er.On("User", mock.Anything, "nobody").Return(int64(0), nil, repo.ErrRecipientNotFound).Maybe()
+	return er
+}
+
+func TestEmergency_GrantAndList(t *testing.T) {
+	f := newTestFixture(t, fixtureRepos{emergency: stubEmergencyUsers(&hMockEmergencyRepo{})})
+	f.emergency.On("Save", mock.Anything, mock.MatchedBy(func(e *model.EmergencyAccess) bool {
+		return e.OwnerID == 1 && e.ContactID == 2 && e.WaitDays == 7 && e.Status == model.EmergencyGranted
+	})).Return(nil).Once()
+
+	body := `{"wait_days":7,"ephemeral_key":"AQ==","wrapped_key":"Ag==","wrapped_key_nonce":"Aw=="}`
+	rr := f.serveAs(t, 1, http.MethodPut, "/api/emergency/contacts/bob", body)
+	require.Equal(t, http.StatusNoContent, rr.Code, rr.Body.String())
+
+	// период ожидания вне контракта отклоняет валидатор OpenAPI
+	rr = f.serveAs(t, 1, http.MethodPut, "/api/emergency/contacts/bob",
+		`{"wait_days":0,"ephemeral_key":"AQ==","wrapped_key":"Ag==","wrapped_key_nonce":"Aw=="}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	rr = f.serveAs(t, 1, http.MethodPut, "/api/emergency/contacts/alice", body)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "самому себе")
+	rr = f.serveAs(t, 1, http.MethodPut, "/api/emergency/contacts/nobody", body)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	requested := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	f.emergency.On("ListByOwner", mock.Anything, int64(1)).Return([]model.EmergencyContact{{
+		EmergencyAccess: model.EmergencyAccess{WaitDays: 7, Status: model.EmergencyRequested, RequestedAt: &requested},
+		ContactLogin:    "bob",
+	}}, nil).Once()
+	rr = f.serveAs(t, 1, http.MethodGet, "/api/emergency/contacts", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	var contacts []handlers.EmergencyAccessView
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &contacts))
+	require.Len(t, contacts, 1)
+	// период ожидания давно истёк — запрос одобрен автоматически
+	assert.Equal(t, handlers.EmergencyAccessView{Login: "bob", Status: model.EmergencyApproved, WaitDays: 7,
+		RequestedAt: "2026-01-01T00:00:00Z", AvailableAt: "2026-01-08T00:00:00Z"}, contacts[0])
+	f.emergency.AssertExpectations(t)
+}
+
+func TestEmergency_ApproveReject(t *testing.T) {
+	f := newTestFixture(t, fixtureRepos{emergency: stubEmergencyUsers(&hMockEmergencyRepo{})})
+	requested := time.Now().Add(-time.Hour)
+	f.emergency.On("Get", mock.Anything, int64(1), int64(2)).Return(&model.EmergencyAccess{
+		OwnerID: 1, ContactID: 2, WaitDays: 7, Status: model.EmergencyRequested, RequestedAt: &requested,
+	}, nil)
+	f.emergency.On("SetStatus", mock.Anything, int64(1), int64(2), model.EmergencyApproved, mock.Anything).Return(nil).Once()
+	f.emergency.On("SetStatus", mock.Anything, int64(1), int64(2), model.EmergencyGranted, (*time.Time)(nil)).Return(nil).Once()
+	f.emergency.On("Get", mock.Anything, int64(2), int64(1)).Return(nil, repo.ErrEmergencyNotFound)
+
+	rr := f.serveAs(t, 1, http.MethodPost, "/api/emergency/contacts/bob/approve", "")
+	assert.Equal(t, http.StatusNoContent, rr.Code, rr.Body.String())
+	rr = f.serveAs(t, 1, http.MethodPost, "/api/emergency/contacts/bob/reject", "")
+	assert.Equal(t, http.StatusNoContent, rr.Code, rr.Body.String())
+	rr = f.serveAs(t, 2, http.MethodPost, "/api/emergency/contacts/alice/approve", "")
+	assert.Equal(t, http.StatusNotFound, rr.Code, "bob не назначал alice контактом")
+	f.emergency.AssertExpectations(t)
+}
+
+func TestEmergency_RequestAndVault(t *testing.T) {
+	f := newTestFixture(t, fixtureRepos{emergency: stubEmergencyUsers(&hMockEmergencyRepo{})})
+	access := &model.EmergencyAccess{OwnerID: 1, ContactID: 2, WaitDays: 7, Status: model.EmergencyGranted,
+		EphemeralKey: []byte{1}, WrappedKey: []byte{2}, WrappedKeyNonce: []byte{3}}
+	f.emergency.On("Get", mock.Anything, int64(1), int64(2)).Return(access, nil)
+	f.emergency.On("SetStatus", mock.Anything, int64(1), int64(2), model.EmergencyRequested, mock.Anything).
+		Run(func(args mock.Arguments) {
+			access.Status, access.RequestedAt = model.EmergencyRequested, args.Get(4).(*time.Time)
+		}).Return(nil).Once()
+
+	// до запроса хранилище закрыто
+	rr := f.serveAs(t, 2, http.MethodGet, "/api/emergency/grants/alice/vault", "")
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	rr = f.serveAs(t, 2, http.MethodPost, "/api/emergency/grants/alice/request", "")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var v handlers.EmergencyAccessView
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &v))
+	assert.Equal(t, "alice", v.Login)
+	assert.Equal(t, model.EmergencyRequested, v.Status)
+	assert.NotEmpty(t, v.AvailableAt)
+
+	// идёт период ожидания
+	rr = f.serveAs(t, 2, http.MethodGet, "/api/emergency/grants/alice/vault", "")
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	// период истёк, владелец не отклонил запрос
+	expired := time.Now().Add(-8 * 24 * time.Hour)
+	access.RequestedAt = &expired
+	f.items.On("ListAll", mock.Anything, int64(1)).Return([]model.Item{{ID: "i1", Name: "wifi", Version: 2, PasswordCipher: []byte{9}}}, nil).Once()
+	rr = f.serveAs(t, 2, http.MethodGet, "/api/emergency/grants/alice/vault", "")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var vault handlers.EmergencyVaultView
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &vault))
+	assert.Equal(t, "alice", vault.Owner)
+	assert.Equal(t, []byte{2}, vault.WrappedKey)
+	require.Len(t, vault.Items, 1)
+	assert.Equal(t, "wifi", vault.Items[0].Name)
+
+	rr = f.serveAs(t, 2, http.MethodGet, "/api/emergency/grants/nobody/vault", "")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	f.emergency.AssertExpectations(t)
+	f.items.AssertExpectations(t)
+}
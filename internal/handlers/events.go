@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"GophKeeper/internal/middleware"
+	"GophKeeper/internal/service"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// eventsKeepAlive — период комментариев-пингов в потоке событий, чтобы прокси
+// не закрывали простаивающее соединение.
+var eventsKeepAlive = 25 * time.Second
+
+// ChangeEventDTO — данные события change в потоке GET /api/events.
+type ChangeEventDTO struct {
+	Cursor  string   `json:"cursor,omitempty"` // курсор синхронизации после изменения
+	ItemIDs []string `json:"item_ids"`
+}
+
+// Events GET /api/events — поток Server-Sent Events об изменениях данных пользователя.
+// После каждого применённого изменения приходит событие change с новым курсором
+// и id изменённых записей; клиент в ответ выполняет инкрементальную синхронизацию.
+func (h *ItemHandler) Events(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		h.log(r).Errorw("Events: streaming unsupported", "user_id", userID)
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	events, cancel := h.ItemService.SubscribeEvents(r.Context(), userID)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	_, _ = fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	ticker := time.NewTicker(eventsKeepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := writeChangeEvent(w, ev); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// writeChangeEvent пишет событие в формате SSE: id — курсор, data — JSON.
+func writeChangeEvent(w http.ResponseWriter, ev service.ChangeEvent) error {
+	dto := ChangeEventDTO{ItemIDs: ev.ItemIDs}
+	if ev.Cursor > 0 {
+		dto.Cursor = service.FormatSyncCursor(ev.Cursor)
+	}
+	data, err := json.Marshal(dto)
+	if err != nil {
+		return err
+	}
+	if dto.Cursor != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", dto.Cursor); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: change\ndata: %s\n\n", data)
+	return err
+}
@@ -0,0 +1,71 @@
+package handlers_test
+
+import (
+	"GophKeeper/internal/model"
+	"bufio"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestHandlers_Events_StreamsAppliedChanges(t *testing.T) {
+	router, cfg, ir := newHandlersTestRouter(t)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/events", nil)
+	addAuth(t, req, 9, cfg.AuthSecret)
+	resp, err := srv.Client().Do(req)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	lines := bufio.NewScanner(resp.Body)
+	// подписка оформлена, когда пришёл первый комментарий
+	if !lines.Scan() || lines.Text() != ": connected" {
+		t.Fatalf("expected connected comment, got %q", lines.Text())
+	}
+
+	ir.On("GetByID", mock.Anything, int64(9), "e1").Return((*model.Item)(nil), gorm.ErrRecordNotFound).Once()
+	ir.On("Create", mock.Anything, mock.AnythingOfType("*model.Item")).Return(nil).Once()
+	sync := httptest.NewRequest(http.MethodPost, "/api/items/sync", bytes.NewBufferString(`{"changes":[{"id":"e1","version":0,"name":"n"}]}`))
+	addAuth(t, sync, 9, cfg.AuthSecret)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, sync)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var got []string
+	for lines.Scan() {
+		line := lines.Text()
+		if line == "" {
+			if len(got) > 0 {
+				break
+			}
+			continue
+		}
+		got = append(got, line)
+	}
+	assert.Equal(t, []string{"event: change", `data: {"item_ids":["e1"]}`}, got)
+}
+
+func TestHandlers_Events_Unauthorized(t *testing.T) {
+	router, _, _ := newHandlersTestRouter(t)
+	req := httptest.NewRequest(http.MethodGet, "/api/events", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.False(t, strings.HasPrefix(rr.Header().Get("Content-Type"), "text/event-stream"))
+}
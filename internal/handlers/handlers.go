@@ -2,8 +2,12 @@ package handlers
 
 import (
 	"GophKeeper/internal/config"
+	"GophKeeper/internal/metrics"
 	"GophKeeper/internal/middleware"
+	"GophKeeper/internal/openapi"
 	"GophKeeper/internal/service"
+	"context"
+	"net/http"
 
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
@@ -11,6 +15,8 @@ import (
 
 type Handler struct {
 	Router chi.Router
+
+	ready func(ctx context.Context) error // проверка для /readyz, см. SetReadinessCheck
 }
 
 // NewHandler разводящий для хендлеров
@@ -21,25 +27,86 @@ func NewHandler(
 	config *config.Config,
 ) *Handler {
 	r := chi.NewRouter()
+	h := &Handler{Router: r}
 
 	middleware.SetLogger(logger)
 
+	r.Use(middleware.WithRequestID)
+	r.Use(middleware.WithTracing)
 	r.Use(middleware.WithGzip)
 	r.Use(middleware.WithLogging)
-	r.Use(middleware.WithAuth(config.AuthSecret))
+	r.Use(middleware.WithMetrics)
+	// распакованное тело не больше самого большого допустимого запроса — загрузки блоба
+	r.Use(middleware.WithDecompression(int64(config.BlobMaxSizeMB)*1024*1024 + 1*1024*1024))
+	if config.TLSClientCA != "" {
+		// mTLS: токен действителен только вместе с сертификатом, к которому привязан
+		r.Use(middleware.WithCertAuth(config.AuthSecret))
+	} else {
+		r.Use(middleware.WithAuth(config.AuthSecret))
+	}
+	r.Use(middleware.WithValidation(openapi.MustLoad()))
 
 	// Handlers
 	userHandler := NewUserHandler(userService, logger, config)
 	itemHandler := NewItemHandler(itemService, logger, config)
 
+	// Служебные: живость, готовность, метрики Prometheus
+	r.Get("/healthz", Healthz)
+	r.Get("/readyz", h.Readyz)
+	r.Method(http.MethodGet, "/metrics", metrics.Handler())
+
+	// Контракт API
+	r.Get("/api/openapi.json", OpenAPI)
+
 	// User routes
 	r.Post("/api/user/register", userHandler.Register)
 	r.Post("/api/user/login", userHandler.Login)
 	r.Post("/api/user/test", userHandler.Status)
+	r.Get("/api/user/usage", itemHandler.Usage)
+
+	// Ключи X25519 и обмен записями между пользователями
+	r.Get("/api/user/keys", userHandler.GetKeys)
+	r.Put("/api/user/keys", userHandler.PutKeys)
+	r.Get("/api/users/{login}/public-key", userHandler.PublicKey)
+	r.Put("/api/items/{id}/shares/{login}", itemHandler.Share)
+	r.Delete("/api/items/{id}/shares/{login}", itemHandler.Unshare)
+	r.Get("/api/shares", itemHandler.IncomingShares)
+	r.Get("/api/shares/outgoing", itemHandler.OutgoingShares)
+
+	// Организации, участники и общие коллекции
+	r.Post("/api/orgs", itemHandler.CreateOrg)
+	r.Get("/api/orgs", itemHandler.ListOrgs)
+	r.Post("/api/orgs/{org}/collections", itemHandler.CreateCollection)
+	r.Get("/api/orgs/{org}/members", itemHandler.OrgMembers)
+	r.Put("/api/orgs/{org}/members/{login}", itemHandler.SetOrgMember)
+	r.Delete("/api/orgs/{org}/members/{login}", itemHandler.RemoveOrgMember)
+	r.Put("/api/collections/{id}/items/{item_id}", itemHandler.PutCollectionItem)
+
+	// Экстренный доступ доверенных контактов
+	r.Get("/api/emergency/contacts", itemHandler.EmergencyContacts)
+	r.Put("/api/emergency/contacts/{login}", itemHandler.GrantEmergency)
+	r.Delete("/api/emergency/contacts/{login}", itemHandler.RevokeEmergency)
+	r.Post("/api/emergency/contacts/{login}/approve", itemHandler.ApproveEmergency)
+	r.Post("/api/emergency/contacts/{login}/reject", itemHandler.RejectEmergency)
+	r.Get("/api/emergency/grants", itemHandler.EmergencyGrants)
+	r.Post("/api/emergency/grants/{owner}/request", itemHandler.RequestEmergency)
+	r.Get("/api/emergency/grants/{owner}/vault", itemHandler.EmergencyVault)
 
 	// Items/Blobs routes (stubs for now)
 	r.Post("/api/items/sync", itemHandler.Sync)
+	r.Get("/api/events", itemHandler.Events)
 	r.Post("/api/blobs/upload", itemHandler.UploadBlob)
 
-	return &Handler{Router: r}
+	// История версий item и восстановление
+	r.Get("/api/items/{id}/history", itemHandler.History)
+	r.Post("/api/items/{id}/restore", itemHandler.Restore)
+
+	// REST CRUD по отдельным items (OCC через If-Match/ETag)
+	r.Get("/api/data", itemHandler.ListData)
+	r.Post("/api/data", itemHandler.CreateData)
+	r.Get("/api/data/{id}", itemHandler.GetData)
+	r.Put("/api/data/{id}", itemHandler.UpdateData)
+	r.Delete("/api/data/{id}", itemHandler.DeleteData)
+
+	return h
 }
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// readinessTimeout ограничивает проверку зависимостей в /readyz.
+const readinessTimeout = 2 * time.Second
+
+// HealthResponse — ответ /healthz и /readyz.
+type HealthResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// SetReadinessCheck задаёт проверку зависимостей для /readyz (БД и миграции).
+// Без проверки /readyz отвечает так же, как /healthz.
+func (h *Handler) SetReadinessCheck(check func(ctx context.Context) error) {
+	h.ready = check
+}
+
+// Healthz GET /healthz — процесс запущен и обслуживает запросы.
+func Healthz(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, http.StatusOK, HealthResponse{Status: "ok"})
+}
+
+// Readyz GET /readyz — сервер готов принимать трафик: БД отвечает, схема актуальна.
+func (h *Handler) Readyz(w http.ResponseWriter, r *http.Request) {
+	if h.ready != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+		defer cancel()
+		if err := h.ready(ctx); err != nil {
+			writeHealth(w, http.StatusServiceUnavailable, HealthResponse{Status: "unavailable", Error: err.Error()})
+			return
+		}
+	}
+	writeHealth(w, http.StatusOK, HealthResponse{Status: "ok"})
+}
+
+func writeHealth(w http.ResponseWriter, status int, resp HealthResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,61 @@
+package handlers_test
+
+import (
+	"GophKeeper/internal/handlers"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getHealth(t *testing.T, h http.Handler, path string) (int, handlers.HealthResponse) {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+	var resp handlers.HealthResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	return rr.Code, resp
+}
+
+func TestHealthz_Readyz(t *testing.T) {
+	h, _, _ := newHandlersTestHandler(t)
+
+	code, resp := getHealth(t, h.Router, "/healthz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", resp.Status)
+
+	// без проверки готовность совпадает с живостью
+	code, _ = getHealth(t, h.Router, "/readyz")
+	assert.Equal(t, http.StatusOK, code)
+
+	h.SetReadinessCheck(func(ctx context.Context) error {
+		_, ok := ctx.Deadline()
+		assert.True(t, ok, "проверка ограничена таймаутом")
+		return errors.New("database schema is outdated")
+	})
+	code, resp = getHealth(t, h.Router, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "unavailable", resp.Status)
+	assert.Contains(t, resp.Error, "outdated")
+
+	// живость от зависимостей не зависит
+	code, _ = getHealth(t, h.Router, "/healthz")
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestMetrics_Served(t *testing.T) {
+	router, _, _ := newHandlersTestRouter(t)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.True(t, strings.HasPrefix(rr.Header().Get("Content-Type"), "text/plain"))
+	assert.Contains(t, rr.Body.String(), `gophkeeper_http_requests_total{method="GET",route="/healthz",status="200"}`)
+}
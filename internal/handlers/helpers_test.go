@@ -10,8 +10,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -52,14 +54,78 @@ func (m *hMockItemRepo) ListAll(ctx context.Context, userID int64) ([]model.Item
 	return nil, args.Error(1)
 }
 
+func (m *hMockItemRepo) CountByUser(ctx context.Context, userID int64) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *hMockItemRepo) ListVersions(ctx context.Context, userID int64, id string) ([]model.ItemVersion, error) {
+	args := m.Called(ctx, userID, id)
+	if v, ok := args.Get(0).([]model.ItemVersion); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *hMockItemRepo) GetVersion(ctx context.Context, userID int64, id string, version int64) (*model.ItemVersion, error) {
+	args := m.Called(ctx, userID, id, version)
+	if v, ok := args.Get(0).(*model.ItemVersion); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *hMockItemRepo) LatestChangeSeq(ctx context.Context, userID int64) (int64, error) {
+	return 0, nil
+}
+
+// Transaction выполняет fn без реальной транзакции: мок не хранит состояние.
+func (m *hMockItemRepo) Transaction(ctx context.Context, fn func(tx repo.ItemRepository) error) error {
+	return fn(m)
+}
+
+func (m *hMockItemRepo) GetItemsChangedSince(ctx context.Context, userID int64, cursor int64, limit int) ([]model.Item, error) {
+	args := m.Called(ctx, userID, cursor, limit)
+	if v, ok := args.Get(0).([]model.Item); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 var _ repo.ItemRepository = (*hMockItemRepo)(nil)
 
 type hMockBlobRepo struct{ mock.Mock }
 
-func (m *hMockBlobRepo) CreateIfAbsent(ctx context.Context, id string, cipher, nonce []byte) (bool, error) {
-	args := m.Called(ctx, id, cipher, nonce)
+func (m *hMockBlobRepo) CreateIfAbsent(ctx context.Context, userID int64, id string, nonce []byte, size int64) (bool, error) {
+	args := m.Called(ctx, userID, id, nonce, size)
 	return args.Bool(0), args.Error(1)
 }
+func (m *hMockBlobRepo) GetByID(ctx context.Context, id string) (*model.Blob, error) {
+	args := m.Called(ctx, id)
+	if v, ok := args.Get(0).(*model.Blob); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *hMockBlobRepo) ListInline(ctx context.Context, limit int) ([]model.Blob, error) {
+	args := m.Called(ctx, limit)
+	if v, ok := args.Get(0).([]model.Blob); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *hMockBlobRepo) ClearInline(ctx context.Context, id string, size int64) error {
+	return m.Called(ctx, id, size).Error(0)
+}
+
+func (m *hMockBlobRepo) Delete(ctx context.Context, id string) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *hMockBlobRepo) SumSizeByUser(ctx context.Context, userID int64) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
 
 var _ repo.BlobRepository = (*hMockBlobRepo)(nil)
 
@@ -84,16 +150,82 @@ var _ repo.UserRepository = (*hMockUserRepo)(nil)
 
 func newHandlersTestRouter(t *testing.T) (http.Handler, *config.Config, *hMockItemRepo) {
 	t.Helper()
-	cfg := &config.Config{AuthSecret: "test-secret", BlobMaxSizeMB: 1}
+	f := newTestFixture(t, fixtureRepos{})
+	return f.router, f.cfg, f.items
+}
+
+func newHandlersTestHandler(t *testing.T) (*handlers.Handler, *config.Config, *hMockItemRepo) {
+	t.Helper()
+	f := newTestFixture(t, fixtureRepos{})
+	return f.handler, f.cfg, f.items
+}
+
+// fixtureRepos — необязательные репозитории; к сервисам подключаются только заданные.
+type fixtureRepos struct {
+	shares    *hMockShareRepo
+	keys      *hMockKeyRepo
+	orgs      *hMockOrgRepo
+	emergency *hMockEmergencyRepo
+}
+
+// testFixture — обработчики поверх моков репозиториев.
+type testFixture struct {
+	fixtureRepos
+	handler *handlers.Handler
+	router  http.Handler
+	cfg     *config.Config
+	items   *hMockItemRepo
+	users   *hMockUserRepo
+}
+
+func newTestFixture(t *testing.T, opt fixtureRepos) *testFixture {
+	t.Helper()
+	f := &testFixture{
+		fixtureRepos: opt,
+		cfg:          &config.Config{AuthSecret: "test-secret", BlobMaxSizeMB: 1},
+		items:        &hMockItemRepo{},
+		users:        &hMockUserRepo{},
+	}
 	logger := zap.NewNop().Sugar()
-	ur := &hMockUserRepo{}
-	ir := &hMockItemRepo{}
-	br := &hMockBlobRepo{}
 
-	userSvc := service.NewUserService(ur)
-	itemSvc := service.NewItemService(ir, br, logger)
-	h := handlers.NewHandler(userSvc, itemSvc, logger, cfg)
-	return h.Router, cfg, ir
+	userSvc := service.NewUserService(f.users)
+	if f.keys != nil {
+		userSvc.SetKeyRepository(f.keys)
+	}
+	blobStore, err := repo.NewFSBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("blob store: %v", err)
+	}
+	itemSvc := service.NewItemService(f.items, &hMockBlobRepo{}, blobStore, logger)
+	if f.shares != nil {
+		itemSvc.SetShareRepository(f.shares)
+	}
+	if f.orgs != nil {
+		itemSvc.SetOrgRepository(f.orgs)
+	}
+	if f.emergency != nil {
+		itemSvc.SetEmergencyRepository(f.emergency)
+	}
+	f.handler = handlers.NewHandler(userSvc, itemSvc, logger, f.cfg)
+	f.router = f.handler.Router
+	return f
+}
+
+// serveAs выполняет запрос от имени пользователя userID.
+func (f *testFixture) serveAs(t *testing.T, userID int64, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	var rd io.Reader
+	if body != "" {
+		rd = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, rd)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	addAuth(t, req, userID, f.cfg.AuthSecret)
+	rr := httptest.NewRecorder()
+	f.router.ServeHTTP(rr, req)
+	return rr
 }
 
 func addAuth(t *testing.T, req *http.Request, userID int64, secret string) {
@@ -158,3 +290,112 @@ func TestHandlers_Sync_InvalidLastSyncAtStillOK(t *testing.T) {
 	_, hasServerTime := m["server_time"]
 	assert.True(t, hasApplied && hasConflicts && hasServerTime)
 }
+
+func TestHandlers_Sync_Cursor(t *testing.T) {
+	router, cfg, ir := newHandlersTestRouter(t)
+	// limit=1: сервер запрашивает limit+1, вторая запись означает has_more
+	ir.On("GetItemsChangedSince", mock.Anything, int64(9), int64(41), 2).
+		Return([]model.Item{{ID: "i1", Version: 2, ChangeSeq: 42}, {ID: "i2", Version: 1, ChangeSeq: 43}}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/items/sync", bytes.NewBufferString(`{"cursor":"41","limit":1,"last_sync_at":"2020-01-01T00:00:00Z","changes":[]}`))
+	addAuth(t, req, 9, cfg.AuthSecret)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var resp struct {
+		ServerChanges []map[string]any `json:"server_changes"`
+		Cursor        string           `json:"cursor"`
+		HasMore       bool             `json:"has_more"`
+	}
+	_ = json.NewDecoder(bytes.NewReader(rr.Body.Bytes())).Decode(&resp)
+	assert.Len(t, resp.ServerChanges, 1)
+	assert.Equal(t, "42", resp.Cursor)
+	assert.True(t, resp.HasMore)
+	ir.AssertExpectations(t)
+
+	// некорректные курсор и limit — 400
+	for _, bad := range []string{`"cursor":"x"`, `"cursor":"-1"`, `"cursor":"1","limit":-5`} {
+		req = httptest.NewRequest(http.MethodPost, "/api/items/sync", bytes.NewBufferString(`{`+bad+`,"changes":[]}`))
+		addAuth(t, req, 9, cfg.AuthSecret)
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, bad)
+	}
+}
+
+func TestHandlers_Sync_FieldConflictReportsFields(t *testing.T) {
+	router, cfg, ir := newHandlersTestRouter(t)
+	current := &model.Item{ID: "f1", UserID: 9, Version: 3, TextCipher: []byte{3},
+		FieldVersions: model.FieldVersions{model.FieldText: 3}}
+	ir.On("GetByID", mock.Anything, int64(9), "f1").Return(current, nil).Once()
+
+	body := `{"changes":[{"id":"f1","version":1,"text_cipher":"AQ==","base_versions":{"text":1}}]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/items/sync", bytes.NewBufferString(body))
+	addAuth(t, req, 9, cfg.AuthSecret)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var resp struct {
+		Conflicts []struct {
+			ID     string   `json:"id"`
+			Fields []string `json:"fields"`
+		} `json:"conflicts"`
+	}
+	_ = json.NewDecoder(bytes.NewReader(rr.Body.Bytes())).Decode(&resp)
+	if assert.Len(t, resp.Conflicts, 1) {
+		assert.Equal(t, []string{"text"}, resp.Conflicts[0].Fields)
+	}
+	ir.AssertExpectations(t)
+}
+
+func TestHandlers_Sync_Resolutions(t *testing.T) {
+	router, cfg, ir := newHandlersTestRouter(t)
+	current := &model.Item{ID: "r1", UserID: 9, Version: 3, Name: "srv"}
+	ir.On("GetByID", mock.Anything, int64(9), "r1").Return(current, nil).Once()
+	ir.On("UpdateWithVersion", mock.Anything, int64(9), "r1", int64(3), mock.Anything).Return(int64(4), nil).Once()
+
+	body := `{"changes":[{"id":"r1","version":1,"name":"mine"}],"resolutions":{"r1":"client"}}`
+	req := httptest.NewRequest(http.MethodPost, "/api/items/sync", bytes.NewBufferString(body))
+	addAuth(t, req, 9, cfg.AuthSecret)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"new_version":4`)
+	ir.AssertExpectations(t)
+
+	// both — конфликт с полным снимком сервера, копию создаёт клиент
+	ir.On("GetByID", mock.Anything, int64(9), "r1").Return(current, nil).Once()
+	req = httptest.NewRequest(http.MethodPost, "/api/items/sync", bytes.NewBufferString(`{"changes":[{"id":"r1","version":1,"name":"mine"}],"resolve":"both"}`))
+	addAuth(t, req, 9, cfg.AuthSecret)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"server_item":{`)
+	assert.Contains(t, rr.Body.String(), `"name":"srv"`)
+
+	// неизвестная стратегия — 400
+	req = httptest.NewRequest(http.MethodPost, "/api/items/sync", bytes.NewBufferString(`{"changes":[],"resolutions":{"r1":"mine"}}`))
+	addAuth(t, req, 9, cfg.AuthSecret)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestHandlers_Sync_AtomicAbort(t *testing.T) {
+	router, cfg, ir := newHandlersTestRouter(t)
+	ir.On("GetByID", mock.Anything, int64(9), "a1").Return(&model.Item{ID: "a1", UserID: 9, Version: 1}, nil).Once()
+	ir.On("GetByID", mock.Anything, int64(9), "a2").Return(&model.Item{ID: "a2", UserID: 9, Version: 4, Name: "srv"}, nil).Once()
+	ir.On("UpdateWithVersion", mock.Anything, int64(9), "a1", int64(1), mock.Anything).Return(int64(2), nil).Once()
+
+	body := `{"atomic":true,"changes":[{"id":"a1","version":1,"name":"x"},{"id":"a2","version":1,"name":"y"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/items/sync", bytes.NewBufferString(body))
+	addAuth(t, req, 9, cfg.AuthSecret)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"applied":[]`)
+	assert.Contains(t, rr.Body.String(), `{"id":"a1","reason":"atomic_aborted"}`)
+	ir.AssertExpectations(t)
+}
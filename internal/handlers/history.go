@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"GophKeeper/internal/middleware"
+	"GophKeeper/internal/model"
+	"GophKeeper/internal/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ItemVersionView — снимок предыдущей версии элемента в ответе истории.
+type ItemVersionView struct {
+	DataItem
+	ArchivedAt string `json:"archived_at,omitempty"` // когда версия была заменена следующей
+}
+
+// HistoryResponse — ответ GET /api/items/{id}/history.
+type HistoryResponse struct {
+	ID       string            `json:"id"`
+	Current  DataItem          `json:"current"`
+	Versions []ItemVersionView `json:"versions"`
+}
+
+// RestoreRequest — тело POST /api/items/{id}/restore.
+type RestoreRequest struct {
+	Version int64 `json:"version"`
+}
+
+// History GET /api/items/{id}/history — текущая версия и сохранённые предыдущие (новые первыми)
+func (h *ItemHandler) History(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id := chi.URLParam(r, "id")
+	current, versions, err := h.ItemService.ItemHistory(r.Context(), userID, id)
+	if err != nil {
+		h.writeDataError(w, r, "History", userID, id, err)
+		return
+	}
+	resp := HistoryResponse{ID: id, Current: toDataItem(current), Versions: make([]ItemVersionView, 0, len(versions))}
+	for i := range versions {
+		resp.Versions = append(resp.Versions, toItemVersionView(&versions[i]))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// Restore POST /api/items/{id}/restore — записывает снимок версии как новую версию элемента
+func (h *ItemHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id := chi.URLParam(r, "id")
+	var req RestoreRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Version <= 0 {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	it, err := h.ItemService.RestoreItemVersion(r.Context(), userID, id, req.Version)
+	if err != nil {
+		if errors.Is(err, service.ErrHistoryVersionNotFound) {
+			http.Error(w, "version not found", http.StatusNotFound)
+			return
+		}
+		h.writeDataError(w, r, "Restore", userID, id, err)
+		return
+	}
+	writeDataItem(w, http.StatusOK, it)
+}
+
+func toItemVersionView(v *model.ItemVersion) ItemVersionView {
+	return ItemVersionView{
+		DataItem: toDataItem(&model.Item{
+			ID:             v.ItemID,
+			Name:           v.Name,
+			FileName:       v.FileName,
+			BlobID:         v.BlobID,
+			Version:        v.Version,
+			Deleted:        v.Deleted,
+			LoginCipher:    v.LoginCipher,
+			LoginNonce:     v.LoginNonce,
+			PasswordCipher: v.PasswordCipher,
+			PasswordNonce:  v.PasswordNonce,
+			TextCipher:     v.TextCipher,
+			TextNonce:      v.TextNonce,
+			CardCipher:     v.CardCipher,
+			CardNonce:      v.CardNonce,
+			UpdatedAt:      v.ItemUpdatedAt,
+		}),
+		ArchivedAt: formatTime(v.CreatedAt),
+	}
+}
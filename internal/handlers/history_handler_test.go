@@ -0,0 +1,81 @@
+package handlers_test
+
+import (
+	"GophKeeper/internal/handlers"
+	"GophKeeper/internal/model"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestItem_History(t *testing.T) {
+	r, cfg, ir, _ := newItemTestRouter(t)
+	archived := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	ir.On("GetByID", mock.Anything, int64(1), dataItemID).Return(&model.Item{ID: dataItemID, Name: "now", Version: 3}, nil).Once()
+	ir.On("ListVersions", mock.Anything, int64(1), dataItemID).Return([]model.ItemVersion{
+		{ItemID: dataItemID, Version: 2, Name: "before", TextCipher: []byte{1}, CreatedAt: archived},
+	}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/items/"+dataItemID+"/history", nil)
+	addItemAuthCookie(t, req, 1, cfg.AuthSecret)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var resp handlers.HistoryResponse
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, int64(3), resp.Current.Version)
+	if assert.Len(t, resp.Versions, 1) {
+		assert.Equal(t, "before", resp.Versions[0].Name)
+		assert.Equal(t, "2025-03-01T10:00:00Z", resp.Versions[0].ArchivedAt)
+		assert.Equal(t, []byte{1}, resp.Versions[0].TextCipher)
+	}
+
+	ir.On("GetByID", mock.Anything, int64(1), "missing").Return(nil, gorm.ErrRecordNotFound).Once()
+	req = httptest.NewRequest(http.MethodGet, "/api/items/missing/history", nil)
+	addItemAuthCookie(t, req, 1, cfg.AuthSecret)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestItem_Restore(t *testing.T) {
+	r, cfg, ir, _ := newItemTestRouter(t)
+
+	// некорректная версия
+	req := httptest.NewRequest(http.MethodPost, "/api/items/"+dataItemID+"/restore", strings.NewReader(`{"version":0}`))
+	addItemAuthCookie(t, req, 1, cfg.AuthSecret)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// версии нет в истории
+	ir.On("GetByID", mock.Anything, int64(1), dataItemID).Return(&model.Item{ID: dataItemID, Version: 4}, nil).Once()
+	ir.On("GetVersion", mock.Anything, int64(1), dataItemID, int64(1)).Return(nil, gorm.ErrRecordNotFound).Once()
+	req = httptest.NewRequest(http.MethodPost, "/api/items/"+dataItemID+"/restore", strings.NewReader(`{"version":1}`))
+	addItemAuthCookie(t, req, 1, cfg.AuthSecret)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	// успешное восстановление
+	ir.On("GetByID", mock.Anything, int64(1), dataItemID).Return(&model.Item{ID: dataItemID, Version: 4}, nil).Once()
+	ir.On("GetVersion", mock.Anything, int64(1), dataItemID, int64(2)).Return(&model.ItemVersion{ItemID: dataItemID, Version: 2, Name: "old"}, nil).Once()
+	ir.On("UpdateWithVersion", mock.Anything, int64(1), dataItemID, int64(4), mock.Anything).Return(int64(5), nil).Once()
+	ir.On("GetByID", mock.Anything, int64(1), dataItemID).Return(&model.Item{ID: dataItemID, Version: 5, Name: "old"}, nil).Once()
+	req = httptest.NewRequest(http.MethodPost, "/api/items/"+dataItemID+"/restore", strings.NewReader(`{"version":2}`))
+	addItemAuthCookie(t, req, 1, cfg.AuthSecret)
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `"5"`, rr.Header().Get("ETag"))
+	assert.Contains(t, rr.Body.String(), `"name":"old"`)
+	ir.AssertExpectations(t)
+}
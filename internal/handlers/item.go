@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"GophKeeper/internal/config"
+	"GophKeeper/internal/logging"
 	"GophKeeper/internal/middleware"
 	"GophKeeper/internal/service"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"time"
@@ -25,11 +27,27 @@ func NewItemHandler(itemService *service.ItemService, logger *zap.SugaredLogger,
 	return &ItemHandler{ItemService: itemService, Logger: logger, Config: cfg}
 }
 
+// log — логгер с request_id и trace_id запроса.
+func (h *ItemHandler) log(r *http.Request) *zap.SugaredLogger {
+	return logging.FromContext(r.Context(), h.Logger)
+}
+
 // SyncRequest — минимальный контракт синхронизации (батч изменений).
+// Cursor — непрозрачный курсор из предыдущего ответа ("0" — полная синхронизация);
+// Limit — размер страницы server_changes; пока в ответе has_more=true, клиент
+// повторяет запрос с полученным cursor.
+// LastSyncAt оставлен для старых клиентов и игнорируется, если передан cursor.
 type SyncRequest struct {
+	Cursor     string       `json:"cursor,omitempty"`
+	Limit      int          `json:"limit,omitempty"`
 	LastSyncAt string       `json:"last_sync_at,omitempty"`
 	Changes    []ItemChange `json:"changes"`
 	Resolve    *string      `json:"resolve,omitempty"`
+	// Resolutions — стратегия для отдельных записей: {"<id>": "client"|"server"|"both"}; важнее resolve.
+	Resolutions map[string]string `json:"resolutions,omitempty"`
+	// Atomic — применить changes в одной транзакции: при любом конфликте не применяется ничего,
+	// а изменения без собственных конфликтов возвращаются с причиной atomic_aborted.
+	Atomic bool `json:"atomic,omitempty"`
 }
 
 // ItemChange — элемент изменения. Значения могут быть опциональными.
@@ -48,6 +66,9 @@ type ItemChange struct {
 	TextNonce      []byte  `json:"text_nonce,omitempty"`
 	CardCipher     []byte  `json:"card_cipher,omitempty"`
 	CardNonce      []byte  `json:"card_nonce,omitempty"`
+	// BaseVersions — версии сервера, от которых клиент начал правку каждой группы полей
+	// (name, file, login, password, text, card, deleted); включает слияние по полям.
+	BaseVersions map[string]int64 `json:"base_versions,omitempty"`
 }
 
 // Ответ синхронизации
@@ -60,13 +81,19 @@ type ConflictDTO struct {
 	ID         string      `json:"id"`
 	Reason     string      `json:"reason"`
 	ServerItem interface{} `json:"server_item,omitempty"`
+	Fields     []string    `json:"fields,omitempty"`
 }
 
 type SyncResponse struct {
 	Applied       []AppliedDTO  `json:"applied"`
 	Conflicts     []ConflictDTO `json:"conflicts"`
 	ServerChanges []any         `json:"server_changes"`
+	Cursor        string        `json:"cursor,omitempty"`
+	HasMore       bool          `json:"has_more"`
 	ServerTime    string        `json:"server_time"`
+	// Collections — коллекции организаций на последней странице; nil — поле не передаётся
+	// (клиент оставляет прежний снимок), пустой срез — коллекций нет.
+	Collections *[]CollectionSnapshotView `json:"collections,omitempty"`
 }
 
 // Sync синхронизация item от клиента
@@ -78,7 +105,7 @@ func (h *ItemHandler) Sync(w http.ResponseWriter, r *http.Request) {
 
 	var req SyncRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.Logger.Warnw("Sync: invalid request body", "error", err)
+		h.log(r).Warnw("Sync: invalid request body", "error", err)
 		http.Error(w, "invalid request", http.StatusBadRequest)
 		return
 	}
@@ -86,17 +113,39 @@ func (h *ItemHandler) Sync(w http.ResponseWriter, r *http.Request) {
 	userID, _ := middleware.GetUserIDFromContext(r.Context())
 
 	// Преобразуем запрос хендлера в сервисный DTO
+	var cursorPtr *int64
+	if req.Cursor != "" {
+		c, err := service.ParseSyncCursor(req.Cursor)
+		if err != nil {
+			h.log(r).Warnw("Sync: invalid cursor", "value", req.Cursor, "error", err)
+			http.Error(w, "invalid cursor", http.StatusBadRequest)
+			return
+		}
+		cursorPtr = &c
+	}
 	var sincePtr *time.Time
-	if req.LastSyncAt != "" {
+	if req.LastSyncAt != "" && cursorPtr == nil {
 		if t, err := time.Parse(time.RFC3339, req.LastSyncAt); err == nil {
 			sincePtr = &t
 		} else {
-			h.Logger.Warnw("Sync: invalid last_sync_at", "value", req.LastSyncAt, "error", err)
+			h.log(r).Warnw("Sync: invalid last_sync_at", "value", req.LastSyncAt, "error", err)
 		}
 	}
-	svcReq := service.SyncRequest{LastSyncAt: sincePtr, Changes: make([]service.SyncChange, 0, len(req.Changes))}
-	// Стратегия разрешения на уровень батча (опционально)
+	if req.Limit < 0 {
+		http.Error(w, "invalid limit", http.StatusBadRequest)
+		return
+	}
+	svcReq := service.SyncRequest{Cursor: cursorPtr, Limit: req.Limit, LastSyncAt: sincePtr, Changes: make([]service.SyncChange, 0, len(req.Changes))}
+	// Стратегия разрешения на уровень батча и для отдельных записей (опционально)
 	svcReq.Resolve = req.Resolve
+	for id, strategy := range req.Resolutions {
+		if !service.ValidResolution(strategy) {
+			http.Error(w, "invalid resolution for item "+id, http.StatusBadRequest)
+			return
+		}
+	}
+	svcReq.Resolutions = req.Resolutions
+	svcReq.Atomic = req.Atomic
 	for _, ch := range req.Changes {
 		svcReq.Changes = append(svcReq.Changes, service.SyncChange{
 			ID:             ch.ID,
@@ -113,12 +162,13 @@ func (h *ItemHandler) Sync(w http.ResponseWriter, r *http.Request) {
 			TextNonce:      ch.TextNonce,
 			CardCipher:     ch.CardCipher,
 			CardNonce:      ch.CardNonce,
+			BaseVersions:   ch.BaseVersions,
 		})
 	}
 
 	res, err := h.ItemService.Sync(r.Context(), userID, svcReq)
 	if err != nil {
-		h.Logger.Errorw("Sync: service error", "user_id", userID, "error", err)
+		h.log(r).Errorw("Sync: service error", "user_id", userID, "error", err)
 		http.Error(w, "internal error", http.StatusInternalServerError)
 		return
 	}
@@ -130,7 +180,7 @@ func (h *ItemHandler) Sync(w http.ResponseWriter, r *http.Request) {
 	}
 	conflicts := make([]ConflictDTO, 0, len(res.Conflicts))
 	for _, c := range res.Conflicts {
-		conflicts = append(conflicts, ConflictDTO{ID: c.ID, Reason: c.Reason, ServerItem: c.ServerItem})
+		conflicts = append(conflicts, ConflictDTO{ID: c.ID, Reason: c.Reason, ServerItem: c.ServerItem, Fields: c.Fields})
 	}
 	// server_changes теперь всегда отдаются как ПОЛНЫЕ снимки записей
 	serverChanges := make([]any, 0, len(res.ServerChanges))
@@ -167,6 +217,14 @@ func (h *ItemHandler) Sync(w http.ResponseWriter, r *http.Request) {
 		ServerChanges: serverChanges,
 		ServerTime:    res.ServerTime.UTC().Format(time.RFC3339),
 	}
+	if cursorPtr != nil {
+		resp.Cursor = service.FormatSyncCursor(res.Cursor)
+		resp.HasMore = res.HasMore
+	}
+	if res.Collections != nil {
+		cols := toCollectionSnapshotViews(res.Collections)
+		resp.Collections = &cols
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -175,7 +233,8 @@ func (h *ItemHandler) Sync(w http.ResponseWriter, r *http.Request) {
 
 // UploadBlob загрузка файла blob
 func (h *ItemHandler) UploadBlob(w http.ResponseWriter, r *http.Request) {
-	if _, ok := middleware.GetUserIDFromContext(r.Context()); !ok {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
 		http.Error(w, "unauthorized", http.StatusUnauthorized)
 		return
 	}
@@ -186,14 +245,19 @@ func (h *ItemHandler) UploadBlob(w http.ResponseWriter, r *http.Request) {
 
 	// Парсим multipart/form-data
 	if err := r.ParseMultipartForm(10 << 20); err != nil {
-		h.Logger.Warnw("UploadBlob: invalid multipart form", "error", err)
+		if middleware.IsBodyTooLarge(err) {
+			h.log(r).Warnw("UploadBlob: request body too large", "limit", maxBody)
+			http.Error(w, "payload too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		h.log(r).Warnw("UploadBlob: invalid multipart form", "error", err)
 		http.Error(w, "invalid multipart form", http.StatusBadRequest)
 		return
 	}
 
 	id := r.FormValue("id")
 	if id == "" {
-		h.Logger.Warnw("UploadBlob: missing id")
+		h.log(r).Warnw("UploadBlob: missing id")
 		http.Error(w, "missing id", http.StatusBadRequest)
 		return
 	}
@@ -201,20 +265,20 @@ func (h *ItemHandler) UploadBlob(w http.ResponseWriter, r *http.Request) {
 	// Читаем cipher как файл
 	cipherFile, _, err := r.FormFile("cipher")
 	if err != nil {
-		h.Logger.Warnw("UploadBlob: missing cipher file", "error", err)
+		h.log(r).Warnw("UploadBlob: missing cipher file", "error", err)
 		http.Error(w, "missing cipher file", http.StatusBadRequest)
 		return
 	}
 	defer cipherFile.Close()
 	cipherBytes, err := io.ReadAll(cipherFile)
 	if err != nil {
-		h.Logger.Warnw("UploadBlob: failed to read cipher", "error", err)
+		h.log(r).Warnw("UploadBlob: failed to read cipher", "error", err)
 		http.Error(w, "failed to read cipher", http.StatusBadRequest)
 		return
 	}
 	maxCipher := int64(h.Config.BlobMaxSizeMB) * 1024 * 1024
 	if int64(len(cipherBytes)) > maxCipher {
-		h.Logger.Warnw("UploadBlob: payload too large", "id", id, "size", len(cipherBytes), "limit", maxCipher)
+		h.log(r).Warnw("UploadBlob: payload too large", "id", id, "size", len(cipherBytes), "limit", maxCipher)
 		http.Error(w, "payload too large", http.StatusRequestEntityTooLarge)
 		return
 	}
@@ -223,7 +287,7 @@ func (h *ItemHandler) UploadBlob(w http.ResponseWriter, r *http.Request) {
 	if nonceStr := r.FormValue("nonce"); nonceStr != "" {
 		nb, decErr := base64.StdEncoding.DecodeString(nonceStr)
 		if decErr != nil {
-			h.Logger.Warnw("UploadBlob: invalid nonce base64", "id", id, "error", decErr)
+			h.log(r).Warnw("UploadBlob: invalid nonce base64", "id", id, "error", decErr)
 			http.Error(w, "invalid nonce (base64)", http.StatusBadRequest)
 			return
 		}
@@ -232,25 +296,30 @@ func (h *ItemHandler) UploadBlob(w http.ResponseWriter, r *http.Request) {
 		defer nonceFile.Close()
 		nb, readErr := io.ReadAll(nonceFile)
 		if readErr != nil {
-			h.Logger.Warnw("UploadBlob: failed to read nonce file", "id", id, "error", readErr)
+			h.log(r).Warnw("UploadBlob: failed to read nonce file", "id", id, "error", readErr)
 			http.Error(w, "failed to read nonce", http.StatusBadRequest)
 			return
 		}
 		nonceBytes = nb
 	} else {
-		h.Logger.Warnw("UploadBlob: missing nonce", "id", id)
+		h.log(r).Warnw("UploadBlob: missing nonce", "id", id)
 		http.Error(w, "missing nonce", http.StatusBadRequest)
 		return
 	}
 	if len(nonceBytes) == 0 {
-		h.Logger.Warnw("UploadBlob: empty nonce", "id", id)
+		h.log(r).Warnw("UploadBlob: empty nonce", "id", id)
 		http.Error(w, "empty nonce", http.StatusBadRequest)
 		return
 	}
 
-	created, err := h.ItemService.SaveBlob(r.Context(), id, cipherBytes, nonceBytes)
+	created, err := h.ItemService.SaveBlob(r.Context(), userID, id, cipherBytes, nonceBytes)
+	if errors.Is(err, service.ErrQuotaExceeded) {
+		h.log(r).Warnw("UploadBlob: quota exceeded", "user_id", userID, "id", id, "size", len(cipherBytes))
+		http.Error(w, "storage quota exceeded", http.StatusInsufficientStorage)
+		return
+	}
 	if err != nil {
-		h.Logger.Errorw("UploadBlob: service error", "id", id, "error", err)
+		h.log(r).Errorw("UploadBlob: service error", "id", id, "error", err)
 		http.Error(w, "internal error", http.StatusInternalServerError)
 		return
 	}
@@ -267,3 +336,38 @@ func (h *ItemHandler) UploadBlob(w http.ResponseWriter, r *http.Request) {
 		"size":    len(cipherBytes),
 	})
 }
+
+// UsageResponse — использование хранилища пользователем и лимиты (0 — без ограничения).
+type UsageResponse struct {
+	Items          int64 `json:"items"`
+	ItemsLimit     int64 `json:"items_limit"`
+	BlobBytes      int64 `json:"blob_bytes"`
+	BlobBytesLimit int64 `json:"blob_bytes_limit"`
+	ItemBytesLimit int64 `json:"item_bytes_limit"`
+}
+
+// Usage возвращает текущее использование квот пользователем
+func (h *ItemHandler) Usage(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	u, err := h.ItemService.Usage(r.Context(), userID)
+	if err != nil {
+		h.log(r).Errorw("Usage: service error", "user_id", userID, "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(UsageResponse{
+		Items:          u.Items,
+		ItemsLimit:     u.Quota.MaxItems,
+		BlobBytes:      u.BlobBytes,
+		BlobBytesLimit: u.Quota.MaxBlobBytes,
+		ItemBytesLimit: u.Quota.MaxItemBytes,
+	})
+}
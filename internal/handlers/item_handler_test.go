@@ -19,6 +19,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 // Minimal mocks
@@ -53,14 +54,78 @@ func (m *itemMockItemRepo) ListAll(ctx context.Context, userID int64) ([]model.I
 	return nil, args.Error(1)
 }
 
+func (m *itemMockItemRepo) CountByUser(ctx context.Context, userID int64) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *itemMockItemRepo) ListVersions(ctx context.Context, userID int64, id string) ([]model.ItemVersion, error) {
+	args := m.Called(ctx, userID, id)
+	if v, ok := args.Get(0).([]model.ItemVersion); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *itemMockItemRepo) GetVersion(ctx context.Context, userID int64, id string, version int64) (*model.ItemVersion, error) {
+	args := m.Called(ctx, userID, id, version)
+	if v, ok := args.Get(0).(*model.ItemVersion); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *itemMockItemRepo) LatestChangeSeq(ctx context.Context, userID int64) (int64, error) {
+	return 0, nil
+}
+
+// Transaction выполняет fn без реальной транзакции: мок не хранит состояние.
+func (m *itemMockItemRepo) Transaction(ctx context.Context, fn func(tx repo.ItemRepository) error) error {
+	return fn(m)
+}
+
+func (m *itemMockItemRepo) GetItemsChangedSince(ctx context.Context, userID int64, cursor int64, limit int) ([]model.Item, error) {
+	args := m.Called(ctx, userID, cursor, limit)
+	if v, ok := args.Get(0).([]model.Item); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 var _ repo.ItemRepository = (*itemMockItemRepo)(nil)
 
 type itemMockBlobRepo struct{ mock.Mock }
 
-func (m *itemMockBlobRepo) CreateIfAbsent(ctx context.Context, id string, cipher, nonce []byte) (bool, error) {
-	args := m.Called(ctx, id, cipher, nonce)
+func (m *itemMockBlobRepo) CreateIfAbsent(ctx context.Context, userID int64, id string, nonce []byte, size int64) (bool, error) {
+	args := m.Called(ctx, userID, id, nonce, size)
 	return args.Bool(0), args.Error(1)
 }
+func (m *itemMockBlobRepo) GetByID(ctx context.Context, id string) (*model.Blob, error) {
+	args := m.Called(ctx, id)
+	if v, ok := args.Get(0).(*model.Blob); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *itemMockBlobRepo) ListInline(ctx context.Context, limit int) ([]model.Blob, error) {
+	args := m.Called(ctx, limit)
+	if v, ok := args.Get(0).([]model.Blob); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *itemMockBlobRepo) ClearInline(ctx context.Context, id string, size int64) error {
+	return m.Called(ctx, id, size).Error(0)
+}
+
+func (m *itemMockBlobRepo) Delete(ctx context.Context, id string) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *itemMockBlobRepo) SumSizeByUser(ctx context.Context, userID int64) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
 
 var _ repo.BlobRepository = (*itemMockBlobRepo)(nil)
 
@@ -92,7 +157,11 @@ func newItemTestRouter(t *testing.T) (http.Handler, *config.Config, *itemMockIte
 	br := &itemMockBlobRepo{}
 
 	userSvc := service.NewUserService(ur)
-	itemSvc := service.NewItemService(ir, br, logger)
+	blobStore, err := repo.NewFSBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("blob store: %v", err)
+	}
+	itemSvc := service.NewItemService(ir, br, blobStore, logger)
 	h := handlers.NewHandler(userSvc, itemSvc, logger, cfg)
 	return h.Router, cfg, ir, br
 }
@@ -232,7 +301,8 @@ func TestItem_UploadBlob_PayloadTooLargeAndSuccess(t *testing.T) {
 	// created=true -> 201
 	{
 		br.ExpectedCalls = nil
-		br.On("CreateIfAbsent", mock.Anything, "bid1", mock.Anything, mock.Anything).Return(true, nil).Once()
+		br.On("GetByID", mock.Anything, "bid1").Return(nil, gorm.ErrRecordNotFound).Once()
+		br.On("CreateIfAbsent", mock.Anything, int64(5), "bid1", mock.Anything, mock.Anything).Return(true, nil).Once()
 		ct, body := makeMultipart(t, map[string]string{"id": "bid1", "nonce": "AQ=="}, map[string][]byte{"cipher": []byte{1, 2, 3}})
 		req := httptest.NewRequest(http.MethodPost, "/api/blobs/upload", body)
 		req.Header.Set("Content-Type", ct)
@@ -246,7 +316,8 @@ func TestItem_UploadBlob_PayloadTooLargeAndSuccess(t *testing.T) {
 	// created=false -> 200
 	{
 		br.ExpectedCalls = nil
-		br.On("CreateIfAbsent", mock.Anything, "bid2", mock.Anything, mock.Anything).Return(false, nil).Once()
+		br.On("GetByID", mock.Anything, "bid2").Return(nil, gorm.ErrRecordNotFound).Once()
+		br.On("CreateIfAbsent", mock.Anything, int64(5), "bid2", mock.Anything, mock.Anything).Return(false, nil).Once()
 		ct, body := makeMultipart(t, map[string]string{"id": "bid2", "nonce": "AQ=="}, map[string][]byte{"cipher": []byte{1}})
 		req := httptest.NewRequest(http.MethodPost, "/api/blobs/upload", body)
 		req.Header.Set("Content-Type", ct)
@@ -267,3 +338,56 @@ func TestItem_Sync_BadJSON(t *testing.T) {
 	router.ServeHTTP(rr, req)
 	assert.Equal(t, http.StatusBadRequest, rr.Code)
 }
+
+func TestItem_Usage(t *testing.T) {
+	router, cfg, ir, br := newItemTestRouter(t)
+
+	// без авторизации
+	req := httptest.NewRequest(http.MethodGet, "/api/user/usage", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	// успех
+	ir.On("CountByUser", mock.Anything, int64(5)).Return(int64(4), nil).Once()
+	br.On("SumSizeByUser", mock.Anything, int64(5)).Return(int64(2048), nil).Once()
+	req = httptest.NewRequest(http.MethodGet, "/api/user/usage", nil)
+	addItemAuthCookie(t, req, 5, cfg.AuthSecret)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"items":4,"items_limit":0,"blob_bytes":2048,"blob_bytes_limit":0,"item_bytes_limit":0}`, rr.Body.String())
+
+	// ошибка сервиса
+	ir.On("CountByUser", mock.Anything, int64(5)).Return(int64(0), assert.AnError).Once()
+	req = httptest.NewRequest(http.MethodGet, "/api/user/usage", nil)
+	addItemAuthCookie(t, req, 5, cfg.AuthSecret)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+}
+
+func TestItem_UploadBlob_QuotaExceeded(t *testing.T) {
+	cfg := &config.Config{AuthSecret: "test-secret", BlobMaxSizeMB: 1}
+	logger := zap.NewNop().Sugar()
+	br := &itemMockBlobRepo{}
+	blobStore, err := repo.NewFSBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("blob store: %v", err)
+	}
+	itemSvc := service.NewItemService(&itemMockItemRepo{}, br, blobStore, logger)
+	itemSvc.SetQuota(service.Quota{MaxBlobBytes: 4})
+	router := handlers.NewHandler(service.NewUserService(&itemMockUserRepo{}), itemSvc, logger, cfg).Router
+
+	br.On("GetByID", mock.Anything, "bq1").Return(nil, gorm.ErrRecordNotFound).Once()
+	br.On("SumSizeByUser", mock.Anything, int64(5)).Return(int64(3), nil).Once()
+	ct, body := makeMultipart(t, map[string]string{"id": "bq1", "nonce": "AQ=="}, map[string][]byte{"cipher": {1, 2}})
+	req := httptest.NewRequest(http.MethodPost, "/api/blobs/upload", body)
+	req.Header.Set("Content-Type", ct)
+	addItemAuthCookie(t, req, 5, cfg.AuthSecret)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusInsufficientStorage, rr.Code)
+	assert.Contains(t, rr.Body.String(), "quota")
+	br.AssertExpectations(t)
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"GophKeeper/internal/middleware"
+	"GophKeeper/internal/model"
+	"GophKeeper/internal/repo"
+	"GophKeeper/internal/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// UserKeysBody — тело PUT и ответ GET /api/user/keys: пара ключей X25519,
+// закрытый ключ зашифрован ключом хранилища клиента.
+type UserKeysBody struct {
+	PublicKey         []byte `json:"public_key"`
+	WrappedPrivateKey []byte `json:"wrapped_private_key"`
+	PrivateKeyNonce   []byte `json:"private_key_nonce"`
+}
+
+// PublicKeyResponse — ответ GET /api/users/{login}/public-key.
+type PublicKeyResponse struct {
+	Login     string `json:"login"`
+	PublicKey []byte `json:"public_key"`
+}
+
+// PutKeys PUT /api/user/keys — публикует ключи пользователя (повторно — заменяет)
+func (h *UserHandler) PutKeys(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req UserKeysBody
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	err := h.UserService.PublishKeys(r.Context(), &model.UserKeys{
+		UserID:            userID,
+		PublicKey:         req.PublicKey,
+		WrappedPrivateKey: req.WrappedPrivateKey,
+		PrivateKeyNonce:   req.PrivateKeyNonce,
+	})
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, service.ErrInvalidPublicKey):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.log(r).Errorw("failed to publish keys", "user_id", userID, "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
+
+// GetKeys GET /api/user/keys — опубликованные ключи пользователя
+func (h *UserHandler) GetKeys(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	k, err := h.UserService.Keys(r.Context(), userID)
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, UserKeysBody{
+			PublicKey:         k.PublicKey,
+			WrappedPrivateKey: k.WrappedPrivateKey,
+			PrivateKeyNonce:   k.PrivateKeyNonce,
+		})
+	case errors.Is(err, repo.ErrKeysNotFound):
+		http.Error(w, "keys not published", http.StatusNotFound)
+	default:
+		h.log(r).Errorw("failed to load keys", "user_id", userID, "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
+
+// PublicKey GET /api/users/{login}/public-key — открытый ключ другого пользователя
+func (h *UserHandler) PublicKey(w http.ResponseWriter, r *http.Request) {
+	if _, ok := middleware.GetUserIDFromContext(r.Context()); !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	login := chi.URLParam(r, "login")
+	pub, err := h.UserService.PublicKey(r.Context(), login)
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, PublicKeyResponse{Login: login, PublicKey: pub})
+	case errors.Is(err, repo.ErrKeysNotFound):
+		http.Error(w, "public key not found", http.StatusNotFound)
+	default:
+		h.log(r).Errorw("failed to load public key", "login", login, "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
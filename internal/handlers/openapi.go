@@ -0,0 +1,13 @@
+package handlers
+
+import (
+	"GophKeeper/internal/openapi"
+	"net/http"
+)
+
+// OpenAPI GET /api/openapi.json — контракт HTTP API, по которому middleware.WithValidation проверяет запросы
+func OpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(openapi.JSON)
+}
@@ -0,0 +1,168 @@
+package handlers_test
+
+import (
+	"GophKeeper/internal/handlers"
+	"GophKeeper/internal/middleware"
+	"GophKeeper/internal/model"
+	"GophKeeper/internal/openapi"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPI_Served(t *testing.T) {
+	router, _, _ := newHandlersTestRouter(t)
+	req := httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+	doc, err := openapi3.NewLoader().LoadFromData(rr.Body.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+}
+
+// Каждый маршрут chi описан в контракте, и в контракте нет операций без маршрута.
+func TestOpenAPI_CoversRoutes(t *testing.T) {
+	router, _, _ := newHandlersTestRouter(t)
+	doc := openapi.MustLoad()
+
+	routes := map[string]bool{}
+	require.NoError(t, chi.Walk(router.(chi.Routes), func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		routes[method+" "+route] = true
+		return nil
+	}))
+	described := map[string]bool{}
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			described[method+" "+path] = true
+		}
+	}
+	assert.Equal(t, routes, described)
+}
+
+// Серверные DTO совпадают со схемами: запросы принимают все поля контракта,
+// ответы не содержат полей вне контракта.
+func TestOpenAPI_ServerDTOsConform(t *testing.T) {
+	requests := map[string]any{
+		"SyncRequest":             handlers.SyncRequest{},
+		"DataItemRequest":         handlers.DataItemRequest{},
+		"RestoreRequest":          handlers.RestoreRequest{},
+		"Credentials":             handlers.LoginRequest{},
+		"UserKeys":                handlers.UserKeysBody{},
+		"ShareRequest":            handlers.ShareRequest{},
+		"CreateOrgRequest":        handlers.CreateOrgRequest{},
+		"CreateCollectionRequest": handlers.CreateCollectionRequest{},
+		"OrgMemberRequest":        handlers.OrgMemberRequest{},
+		"CollectionItemRequest":   handlers.CollectionItemRequest{},
+		"EmergencyGrantRequest":   handlers.EmergencyGrantRequest{},
+	}
+	for name, dto := range requests {
+		d, err := openapi.Diff(name, dto)
+		require.NoError(t, err)
+		assert.Equal(t, openapi.DTODiff{}, d, name)
+	}
+	d, err := openapi.Diff("Credentials", handlers.RegisterRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, openapi.DTODiff{}, d, "RegisterRequest")
+
+	responses := map[string]any{
+		"SyncResponse":           handlers.SyncResponse{},
+		"Item":                   handlers.ItemVersionView{},
+		"HistoryResponse":        handlers.HistoryResponse{},
+		"UsageResponse":          handlers.UsageResponse{},
+		"StatusResponse":         handlers.DataResponse{},
+		"HealthResponse":         handlers.HealthResponse{},
+		"ValidationError":        middleware.ValidationError{},
+		"PublicKeyResponse":      handlers.PublicKeyResponse{},
+		"IncomingShare":          handlers.IncomingShareView{},
+		"OutgoingShare":          handlers.OutgoingShareView{},
+		"Org":                    handlers.OrgView{},
+		"Collection":             handlers.CollectionView{},
+		"OrgMember":              handlers.OrgMemberView{},
+		"CollectionItemResponse": handlers.CollectionItemResponse{},
+		"CollectionSnapshot":     handlers.CollectionSnapshotView{},
+		"EmergencyAccess":        handlers.EmergencyAccessView{},
+		"EmergencyVault":         handlers.EmergencyVaultView{},
+	}
+	for name, dto := range responses {
+		d, err := openapi.Diff(name, dto)
+		require.NoError(t, err)
+		assert.Empty(t, d.Unknown, name)
+		assert.Empty(t, d.Mismatched, name)
+	}
+}
+
+// Ответ sync с полными снимками (server_changes собираются map-ами) соответствует контракту.
+func TestOpenAPI_SyncResponseMatchesSchema(t *testing.T) {
+	router, cfg, ir := newHandlersTestRouter(t)
+	blobID := "BID"
+	ir.On("ListAll", mock.Anything, int64(9)).Return([]model.Item{
+		{ID: "i1", Version: 1, UpdatedAt: time.Now()},
+		{ID: "i2", Name: "n", Version: 2, UpdatedAt: time.Now(), BlobID: &blobID, LoginCipher: []byte{1}, LoginNonce: []byte{2}},
+	}, nil).Once()
+
+	body := `{"last_sync_at":"1970-01-01T00:00:00Z","changes":[]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/items/sync", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	addAuth(t, req, 9, cfg.AuthSecret)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	validateResponse(t, httptest.NewRequest(http.MethodPost, "/api/items/sync", strings.NewReader(body)), rr)
+}
+
+func TestOpenAPI_ValidationRejectsMalformedBody(t *testing.T) {
+	router, cfg, _ := newHandlersTestRouter(t)
+	req := httptest.NewRequest(http.MethodPost, "/api/items/sync",
+		bytes.NewBufferString(`{"changes":[{"id":"a","version":"one","login_cipher":"***"}],"resolve":"mine","extra":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	addAuth(t, req, 9, cfg.AuthSecret)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	validateResponse(t, httptest.NewRequest(http.MethodPost, "/api/items/sync", nil), rr)
+	var ve middleware.ValidationError
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &ve))
+	fields := map[string]bool{}
+	for _, d := range ve.Details {
+		fields[d.Field] = true
+	}
+	assert.True(t, fields["/changes/0/version"], ve.Details)
+	assert.True(t, fields["/resolve"], ve.Details)
+	assert.True(t, fields["/changes/0/login_cipher"], ve.Details)
+}
+
+// validateResponse проверяет записанный ответ по схеме операции запроса req.
+func validateResponse(t *testing.T, req *http.Request, rr *httptest.ResponseRecorder) {
+	t.Helper()
+	doc := openapi.MustLoad()
+	r, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+	route, params, err := r.FindRoute(req)
+	require.NoError(t, err)
+	err = openapi3filter.ValidateResponse(req.Context(), &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{Request: req, PathParams: params, Route: route},
+		Status:                 rr.Code,
+		Header:                 rr.Header(),
+		Body:                   io.NopCloser(bytes.NewReader(rr.Body.Bytes())),
+		Options:                &openapi3filter.Options{IncludeResponseStatus: true},
+	})
+	assert.NoError(t, err)
+}
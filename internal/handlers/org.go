@@ -0,0 +1,355 @@
+package handlers
+
+import (
+	"GophKeeper/internal/middleware"
+	"GophKeeper/internal/model"
+	"GophKeeper/internal/repo"
+	"GophKeeper/internal/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// SealedKey — ключ коллекции, запечатанный открытым ключом участника
+// (эфемерный открытый ключ X25519, шифртекст и nonce AES-GCM).
+type SealedKey struct {
+	EphemeralKey    []byte `json:"ephemeral_key"`
+	WrappedKey      []byte `json:"wrapped_key"`
+	WrappedKeyNonce []byte `json:"wrapped_key_nonce"`
+}
+
+// CreateOrgRequest — тело POST /api/orgs: имя организации, имя первой коллекции
+// и её ключ, запечатанный для создателя.
+type CreateOrgRequest struct {
+	Name           string `json:"name"`
+	CollectionName string `json:"collection_name,omitempty"`
+	SealedKey
+}
+
+// CollectionView — коллекция в списке организаций.
+type CollectionView struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// OrgView — организация пользователя с его ролью.
+type OrgView struct {
+	Name        string           `json:"name"`
+	Role        string           `json:"role"`
+	Collections []CollectionView `json:"collections"`
+}
+
+// MemberKey — ключ новой коллекции для участника login.
+type MemberKey struct {
+	Login string `json:"login"`
+	SealedKey
+}
+
+// CreateCollectionRequest — тело POST /api/orgs/{org}/collections.
+type CreateCollectionRequest struct {
+	Name string      `json:"name"`
+	Keys []MemberKey `json:"keys"`
+}
+
+// CollectionKeyBody — ключ коллекции collection_id для приглашаемого участника.
+type CollectionKeyBody struct {
+	CollectionID string `json:"collection_id"`
+	SealedKey
+}
+
+// OrgMemberRequest — тело PUT /api/orgs/{org}/members/{login}.
+type OrgMemberRequest struct {
+	Role string              `json:"role"`
+	Keys []CollectionKeyBody `json:"keys,omitempty"`
+}
+
+// OrgMemberView — участник организации в ответе GET /api/orgs/{org}/members.
+type OrgMemberView struct {
+	Login     string `json:"login"`
+	Role      string `json:"role"`
+	PublicKey []byte `json:"public_key,omitempty"`
+}
+
+// CollectionItemFields — поля записи коллекции, зашифрованные ключом коллекции.
+type CollectionItemFields struct {
+	LoginCipher    []byte `json:"login_cipher,omitempty"`
+	LoginNonce     []byte `json:"login_nonce,omitempty"`
+	PasswordCipher []byte `json:"password_cipher,omitempty"`
+	PasswordNonce  []byte `json:"password_nonce,omitempty"`
+	TextCipher     []byte `json:"text_cipher,omitempty"`
+	TextNonce      []byte `json:"text_nonce,omitempty"`
+	CardCipher     []byte `json:"card_cipher,omitempty"`
+	CardNonce      []byte `json:"card_nonce,omitempty"`
+}
+
+// CollectionItemRequest — тело PUT /api/collections/{id}/items/{item_id}; version —
+// версия, от которой сделано изменение (0 — новая запись).
+type CollectionItemRequest struct {
+	Name    string `json:"name"`
+	Version int64  `json:"version"`
+	Deleted bool   `json:"deleted,omitempty"`
+	CollectionItemFields
+}
+
+// CollectionItemResponse — новая версия сохранённой записи коллекции.
+type CollectionItemResponse struct {
+	ID      string `json:"id"`
+	Version int64  `json:"version"`
+}
+
+// CollectionItemView — запись коллекции в ответе sync.
+type CollectionItemView struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Version   int64  `json:"version"`
+	Deleted   bool   `json:"deleted"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+	CollectionItemFields
+}
+
+// CollectionSnapshotView — доступная пользователю коллекция в ответе sync:
+// её ключ, запечатанный для пользователя, и все записи.
+type CollectionSnapshotView struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Org  string `json:"org"`
+	Role string `json:"role"`
+	SealedKey
+	Items []CollectionItemView `json:"items"`
+}
+
+// CreateOrg POST /api/orgs — создаёт организацию, пользователь становится владельцем
+func (h *ItemHandler) CreateOrg(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req CreateOrgRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name == "" {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	m, err := h.ItemService.CreateOrg(r.Context(), userID, req.Name, req.CollectionName, toCollectionKey(req.SealedKey))
+	if err != nil {
+		h.writeOrgError(w, r, "CreateOrg", userID, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, toOrgView(m))
+}
+
+// ListOrgs GET /api/orgs — организации пользователя с его ролью и коллекциями
+func (h *ItemHandler) ListOrgs(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	orgs, err := h.ItemService.Orgs(r.Context(), userID)
+	if err != nil {
+		h.writeOrgError(w, r, "ListOrgs", userID, err)
+		return
+	}
+	out := make([]OrgView, 0, len(orgs))
+	for i := range orgs {
+		out = append(out, toOrgView(&orgs[i]))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// CreateCollection POST /api/orgs/{org}/collections — новая коллекция организации
+func (h *ItemHandler) CreateCollection(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req CreateCollectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name == "" {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	keys := make(map[string]model.CollectionKey, len(req.Keys))
+	for _, k := range req.Keys {
+		keys[k.Login] = *toCollectionKey(k.SealedKey)
+	}
+	col, err := h.ItemService.CreateCollection(r.Context(), userID, chi.URLParam(r, "org"), req.Name, keys)
+	if err != nil {
+		h.writeOrgError(w, r, "CreateCollection", userID, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, CollectionView{ID: col.ID, Name: col.Name})
+}
+
+// OrgMembers GET /api/orgs/{org}/members — участники организации и их открытые ключи
+func (h *ItemHandler) OrgMembers(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	members, err := h.ItemService.OrgMembers(r.Context(), userID, chi.URLParam(r, "org"))
+	if err != nil {
+		h.writeOrgError(w, r, "OrgMembers", userID, err)
+		return
+	}
+	out := make([]OrgMemberView, 0, len(members))
+	for _, m := range members {
+		out = append(out, OrgMemberView{Login: m.Login, Role: m.Role, PublicKey: m.PublicKey})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// SetOrgMember PUT /api/orgs/{org}/members/{login} — приглашает пользователя или меняет его роль
+func (h *ItemHandler) SetOrgMember(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req OrgMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	keys := make([]model.CollectionKey, 0, len(req.Keys))
+	for _, k := range req.Keys {
+		ck := toCollectionKey(k.SealedKey)
+		ck.CollectionID = k.CollectionID
+		keys = append(keys, *ck)
+	}
+	err := h.ItemService.SetOrgMember(r.Context(), userID, chi.URLParam(r, "org"), chi.URLParam(r, "login"), req.Role, keys)
+	if err != nil {
+		h.writeOrgError(w, r, "SetOrgMember", userID, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveOrgMember DELETE /api/orgs/{org}/members/{login} — исключает участника из организации
+func (h *ItemHandler) RemoveOrgMember(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := h.ItemService.RemoveOrgMember(r.Context(), userID, chi.URLParam(r, "org"), chi.URLParam(r, "login")); err != nil {
+		h.writeOrgError(w, r, "RemoveOrgMember", userID, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PutCollectionItem PUT /api/collections/{id}/items/{item_id} — создаёт или обновляет запись коллекции
+func (h *ItemHandler) PutCollectionItem(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req CollectionItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name == "" || req.Version < 0 {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	it := &model.CollectionItem{
+		ID:             chi.URLParam(r, "item_id"),
+		CollectionID:   chi.URLParam(r, "id"),
+		Name:           req.Name,
+		Deleted:        req.Deleted,
+		LoginCipher:    req.LoginCipher,
+		LoginNonce:     req.LoginNonce,
+		PasswordCipher: req.PasswordCipher,
+		PasswordNonce:  req.PasswordNonce,
+		TextCipher:     req.TextCipher,
+		TextNonce:      req.TextNonce,
+		CardCipher:     req.CardCipher,
+		CardNonce:      req.CardNonce,
+	}
+	v, err := h.ItemService.SaveCollectionItem(r.Context(), userID, it, req.Version)
+	if err != nil {
+		h.writeOrgError(w, r, "PutCollectionItem", userID, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, CollectionItemResponse{ID: it.ID, Version: v})
+}
+
+// writeOrgError переводит ошибки организаций и коллекций в ответ; остальные — как writeDataError.
+func (h *ItemHandler) writeOrgError(w http.ResponseWriter, r *http.Request, op string, userID int64, err error) {
+	switch {
+	case errors.Is(err, repo.ErrOrgNotFound):
+		http.Error(w, "organization not found", http.StatusNotFound)
+	case errors.Is(err, repo.ErrOrgMemberNotFound):
+		http.Error(w, "member not found", http.StatusNotFound)
+	case errors.Is(err, repo.ErrCollectionNotFound):
+		http.Error(w, "collection not found", http.StatusNotFound)
+	case errors.Is(err, repo.ErrRecipientNotFound):
+		http.Error(w, "user not found", http.StatusNotFound)
+	case errors.Is(err, repo.ErrOrgExists), errors.Is(err, repo.ErrCollectionExists), errors.Is(err, service.ErrLastOwner):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, repo.ErrRecipientHasNoKey):
+		http.Error(w, "user has not published a public key", http.StatusConflict)
+	case errors.Is(err, service.ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrInvalidRole):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrMissingCollectionKey):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	default:
+		h.writeDataError(w, r, op, userID, "", err)
+	}
+}
+
+func toCollectionKey(k SealedKey) *model.CollectionKey {
+	return &model.CollectionKey{EphemeralKey: k.EphemeralKey, WrappedKey: k.WrappedKey, WrappedKeyNonce: k.WrappedKeyNonce}
+}
+
+func toOrgView(m *model.OrgMembership) OrgView {
+	cols := make([]CollectionView, 0, len(m.Collections))
+	for _, c := range m.Collections {
+		cols = append(cols, CollectionView{ID: c.ID, Name: c.Name})
+	}
+	return OrgView{Name: m.Name, Role: m.Role, Collections: cols}
+}
+
+// toCollectionSnapshotViews переводит коллекции из результата sync в DTO.
+func toCollectionSnapshotViews(snaps []model.CollectionSnapshot) []CollectionSnapshotView {
+	out := make([]CollectionSnapshotView, 0, len(snaps))
+	for _, s := range snaps {
+		items := make([]CollectionItemView, 0, len(s.Items))
+		for _, it := range s.Items {
+			items = append(items, CollectionItemView{
+				ID:        it.ID,
+				Name:      it.Name,
+				Version:   it.Version,
+				Deleted:   it.Deleted,
+				UpdatedAt: formatTime(it.UpdatedAt),
+				CollectionItemFields: CollectionItemFields{
+					LoginCipher:    it.LoginCipher,
+					LoginNonce:     it.LoginNonce,
+					PasswordCipher: it.PasswordCipher,
+					PasswordNonce:  it.PasswordNonce,
+					TextCipher:     it.TextCipher,
+					TextNonce:      it.TextNonce,
+					CardCipher:     it.CardCipher,
+					CardNonce:      it.CardNonce,
+				},
+			})
+		}
+		out = append(out, CollectionSnapshotView{
+			ID:   s.ID,
+			Name: s.Name,
+			Org:  s.OrgName,
+			Role: s.Role,
+			SealedKey: SealedKey{
+				EphemeralKey:    s.Key.EphemeralKey,
+				WrappedKey:      s.Key.WrappedKey,
+				WrappedKeyNonce: s.Key.WrappedKeyNonce,
+			},
+			Items: items,
+		})
+	}
+	return out
+}
@@ -0,0 +1,216 @@
+package handlers_test
+
+import (
+	"GophKeeper/internal/handlers"
+	"GophKeeper/internal/model"
+	"GophKeeper/internal/repo"
+	"GophKeeper/internal/service"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type hMockOrgRepo struct{ mock.Mock }
+
+func (m *hMockOrgRepo) CreateOrg(ctx context.Context, org *model.Organization, ownerID int64, col *model.Collection, key *model.CollectionKey) error {
+	return m.Called(ctx, org, ownerID, col, key).Error(0)
+}
+func (m *hMockOrgRepo) GetOrgByName(ctx context.Context, name string) (*model.Organization, error) {
+	args := m.Called(ctx, name)
+	v, _ := args.Get(0).(*model.Organization)
+	return v, args.Error(1)
+}
+func (m *hMockOrgRepo) User(ctx context.Context, login string) (int64, []byte, error) {
+	args := m.Called(ctx, login)
+	pub, _ := args.Get(1).([]byte)
+	return args.Get(0).(int64), pub, args.Error(2)
+}
+func (m *hMockOrgRepo) Member(ctx context.Context, orgID, userID int64) (*model.OrgMember, error) {
+	args := m.Called(ctx, orgID, userID)
+	v, _ := args.Get(0).(*model.OrgMember)
+	return v, args.Error(1)
+}
+func (m *hMockOrgRepo) CountOwners(ctx context.Context, orgID int64) (int64, error) {
+	args := m.Called(ctx, orgID)
+	return args.Get(0).(int64), args.Error(1)
+}
+func (m *hMockOrgRepo) SaveMember(ctx context.Context, mem *model.OrgMember, keys []model.CollectionKey) error {
+	return m.Called(ctx, mem, keys).Error(0)
+}
+func (m *hMockOrgRepo) RemoveMember(ctx context.Context, orgID, userID int64) error {
+	return m.Called(ctx, orgID, userID).Error(0)
+}
+func (m *hMockOrgRepo) ListMembers(ctx context.Context, orgID int64) ([]model.OrgMemberInfo, error) {
+	args := m.Called(ctx, orgID)
+	v, _ := args.Get(0).([]model.OrgMemberInfo)
+	return v, args.Error(1)
+}
+func (m *hMockOrgRepo) ListMemberships(ctx context.Context, userID int64) ([]model.OrgMembership, error) {
+	args := m.Called(ctx, userID)
+	v, _ := args.Get(0).([]model.OrgMembership)
+	return v, args.Error(1)
+}
+func (m *hMockOrgRepo) ListCollections(ctx context.Context, orgID int64) ([]model.Collection, error) {
+	args := m.Called(ctx, orgID)
+	v, _ := args.Get(0).([]model.Collection)
+	return v, args.Error(1)
+}
+func (m *hMockOrgRepo) CreateCollection(ctx context.Context, col *model.Collection, keys []model.CollectionKey) error {
+	return m.Called(ctx, col, keys).Error(0)
+}
+func (m *hMockOrgRepo) GetCollection(ctx context.Context, id string) (*model.Collection, error) {
+	args := m.Called(ctx, id)
+	v, _ := args.Get(0).(*model.Collection)
+	return v, args.Error(1)
+}
+func (m *hMockOrgRepo) SaveCollectionItem(ctx context.Context, it *model.CollectionItem, expectedVersion int64) (int64, error) {
+	args := m.Called(ctx, it, expectedVersion)
+	return args.Get(0).(int64), args.Error(1)
+}
+func (m *hMockOrgRepo) CollectionsForUser(ctx context.Context, userID int64) ([]model.CollectionSnapshot, error) {
+	args := m.Called(ctx, userID)
+	v, _ := args.Get(0).([]model.CollectionSnapshot)
+	return v, args.Error(1)
+}
+
+var _ repo.OrgRepository = (*hMockOrgRepo)(nil)
+
+func TestOrg_CreateAndList(t *testing.T) {
+	f := newTestFixture(t, fixtureRepos{orgs: &hMockOrgRepo{}})
+	body := `{"name":"acme","ephemeral_key":"AQ==","wrapped_key":"Ag==","wrapped_key_nonce":"Aw=="}`
+
+	f.orgs.On("CreateOrg", mock.Anything, mock.MatchedBy(func(o *model.Organization) bool { return o.Name == "acme" }), int64(9),
+		mock.MatchedBy(func(c *model.Collection) bool { return c.Name == service.DefaultCollectionName }),
+		mock.MatchedBy(func(k *model.CollectionKey) bool { return string(k.WrappedKey) == "\x02" })).
+		Return(nil).Once()
+	rr := f.serveAs(t, 9, http.MethodPost, "/api/orgs", body)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	var created handlers.OrgView
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+	assert.Equal(t, "owner", created.Role)
+	require.Len(t, created.Collections, 1)
+
+	f.orgs.On("CreateOrg", mock.Anything, mock.Anything, int64(9), mock.Anything, mock.Anything).Return(repo.ErrOrgExists).Once()
+	rr = f.serveAs(t, 9, http.MethodPost, "/api/orgs", body)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	// без ключа коллекции запрос не проходит контракт
+	rr = f.serveAs(t, 9, http.MethodPost, "/api/orgs", `{"name":"acme"}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	f.orgs.On("ListMemberships", mock.Anything, int64(9)).Return([]model.OrgMembership{{
+		Organization: model.Organization{ID: 1, Name: "acme"}, Role: model.RoleAdmin,
+		Collections: []model.Collection{{ID: "c1", Name: "default"}},
+	}}, nil).Once()
+	rr = f.serveAs(t, 9, http.MethodGet, "/api/orgs", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `[{"name":"acme","role":"admin","collections":[{"id":"c1","name":"default"}]}]`, rr.Body.String())
+	f.orgs.AssertExpectations(t)
+}
+
+func TestOrg_Members(t *testing.T) {
+	f := newTestFixture(t, fixtureRepos{orgs: &hMockOrgRepo{}})
+	f.orgs.On("GetOrgByName", mock.Anything, "acme").Return(&model.Organization{ID: 1, Name: "acme"}, nil)
+	f.orgs.On("Member", mock.Anything, int64(1), int64(9)).Return(&model.OrgMember{OrgID: 1, UserID: 9, Role: model.RoleAdmin}, nil)
+	f.orgs.On("Member", mock.Anything, int64(1), int64(5)).Return(nil, repo.ErrOrgMemberNotFound)
+	f.orgs.On("User", mock.Anything, "bob").Return(int64(7), []byte{1}, nil)
+	f.orgs.On("ListCollections", mock.Anything, int64(1)).Return([]model.Collection{{ID: "c1", OrgID: 1}}, nil)
+
+	// не участник не видит организацию
+	rr := f.serveAs(t, 5, http.MethodGet, "/api/orgs/acme/members", "")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	f.orgs.On("ListMembers", mock.Anything, int64(1)).Return([]model.OrgMemberInfo{
+		{UserID: 9, Login: "alice", Role: model.RoleAdmin, PublicKey: []byte{1}},
+	}, nil).Once()
+	rr = f.serveAs(t, 9, http.MethodGet, "/api/orgs/acme/members", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `[{"login":"alice","role":"admin","public_key":"AQ=="}]`, rr.Body.String())
+
+	// админ не назначает админов
+	f.orgs.On("Member", mock.Anything, int64(1), int64(7)).Return(nil, repo.ErrOrgMemberNotFound).Times(3)
+	rr = f.serveAs(t, 9, http.MethodPut, "/api/orgs/acme/members/bob", `{"role":"admin"}`)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	// новому участнику нужны ключи всех коллекций
+	rr = f.serveAs(t, 9, http.MethodPut, "/api/orgs/acme/members/bob", `{"role":"member"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+	f.orgs.On("SaveMember", mock.Anything, mock.MatchedBy(func(m *model.OrgMember) bool {
+		return m.OrgID == 1 && m.UserID == 7 && m.Role == model.RoleReadOnly
+	}), mock.MatchedBy(func(keys []model.CollectionKey) bool {
+		return len(keys) == 1 && keys[0].CollectionID == "c1" && keys[0].UserID == 7
+	})).Return(nil).Once()
+	rr = f.serveAs(t, 9, http.MethodPut, "/api/orgs/acme/members/bob",
+		`{"role":"read-only","keys":[{"collection_id":"c1","ephemeral_key":"AQ==","wrapped_key":"Ag==","wrapped_key_nonce":"Aw=="}]}`)
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+
+	// неизвестная роль отклоняется контрактом
+	rr = f.serveAs(t, 9, http.MethodPut, "/api/orgs/acme/members/bob", `{"role":"root"}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	f.orgs.On("Member", mock.Anything, int64(1), int64(7)).Return(&model.OrgMember{OrgID: 1, UserID: 7, Role: model.RoleReadOnly}, nil).Once()
+	f.orgs.On("RemoveMember", mock.Anything, int64(1), int64(7)).Return(nil).Once()
+	rr = f.serveAs(t, 9, http.MethodDelete, "/api/orgs/acme/members/bob", "")
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	f.orgs.AssertExpectations(t)
+}
+
+func TestOrg_PutCollectionItem(t *testing.T) {
+	f := newTestFixture(t, fixtureRepos{orgs: &hMockOrgRepo{}})
+	f.orgs.On("GetCollection", mock.Anything, "c1").Return(&model.Collection{ID: "c1", OrgID: 1}, nil)
+	f.orgs.On("Member", mock.Anything, int64(1), int64(9)).Return(&model.OrgMember{OrgID: 1, UserID: 9, Role: model.RoleMember}, nil)
+	f.orgs.On("Member", mock.Anything, int64(1), int64(7)).Return(&model.OrgMember{OrgID: 1, UserID: 7, Role: model.RoleReadOnly}, nil)
+	body := `{"name":"wifi","version":0,"password_cipher":"AQ==","password_nonce":"Ag=="}`
+
+	rr := f.serveAs(t, 7, http.MethodPut, "/api/collections/c1/items/i1", body)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	f.orgs.On("SaveCollectionItem", mock.Anything, mock.MatchedBy(func(it *model.CollectionItem) bool {
+		return it.ID == "i1" && it.CollectionID == "c1" && it.UpdatedBy == 9 && string(it.PasswordCipher) == "\x01"
+	}), int64(0)).Return(int64(1), nil).Once()
+	rr = f.serveAs(t, 9, http.MethodPut, "/api/collections/c1/items/i1", body)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"id":"i1","version":1}`, rr.Body.String())
+
+	f.orgs.On("SaveCollectionItem", mock.Anything, mock.Anything, int64(0)).Return(int64(0), repo.ErrCollectionItemConflict).Once()
+	rr = f.serveAs(t, 9, http.MethodPut, "/api/collections/c1/items/i1", body)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	f.orgs.AssertExpectations(t)
+}
+
+// На последней странице sync отдаёт доступные коллекции, и ответ соответствует контракту.
+func TestOrg_SyncIncludesCollections(t *testing.T) {
+	f := newTestFixture(t, fixtureRepos{orgs: &hMockOrgRepo{}})
+	f.items.On("GetItemsChangedSince", mock.Anything, int64(9), int64(0), mock.Anything).Return([]model.Item{}, nil).Once()
+	f.orgs.On("CollectionsForUser", mock.Anything, int64(9)).Return([]model.CollectionSnapshot{{
+		Collection: model.Collection{ID: "c1", Name: "default"},
+		OrgName:    "acme",
+		Role:       model.RoleMember,
+		Key:        model.CollectionKey{EphemeralKey: []byte{1}, WrappedKey: []byte{2}, WrappedKeyNonce: []byte{3}},
+		Items:      []model.CollectionItem{{ID: "i1", Name: "wifi", Version: 2, PasswordCipher: []byte{4}, UpdatedAt: time.Now()}},
+	}}, nil).Once()
+
+	body := `{"cursor":"0","changes":[]}`
+	rr := f.serveAs(t, 9, http.MethodPost, "/api/items/sync", body)
+	require.Equal(t, http.StatusOK, rr.Code)
+	validateResponse(t, httptest.NewRequest(http.MethodPost, "/api/items/sync", strings.NewReader(body)), rr)
+
+	var resp handlers.SyncResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.NotNil(t, resp.Collections)
+	cols := *resp.Collections
+	require.Len(t, cols, 1)
+	assert.Equal(t, "acme", cols[0].Org)
+	assert.Equal(t, []byte{2}, cols[0].WrappedKey)
+	require.Len(t, cols[0].Items, 1)
+	assert.Equal(t, []byte{4}, cols[0].Items[0].PasswordCipher)
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"GophKeeper/internal/middleware"
+	"GophKeeper/internal/model"
+	"GophKeeper/internal/repo"
+	"GophKeeper/internal/service"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ShareRequest — тело PUT /api/items/{id}/shares/{login}: поля записи версии version,
+// зашифрованные ключом записи, и этот ключ, запечатанный для получателя
+// (эфемерный открытый ключ X25519, шифртекст и nonce AES-GCM).
+type ShareRequest struct {
+	Version         int64  `json:"version"`
+	EphemeralKey    []byte `json:"ephemeral_key"`
+	WrappedKey      []byte `json:"wrapped_key"`
+	WrappedKeyNonce []byte `json:"wrapped_key_nonce"`
+	LoginCipher     []byte `json:"login_cipher,omitempty"`
+	LoginNonce      []byte `json:"login_nonce,omitempty"`
+	PasswordCipher  []byte `json:"password_cipher,omitempty"`
+	PasswordNonce   []byte `json:"password_nonce,omitempty"`
+	TextCipher      []byte `json:"text_cipher,omitempty"`
+	TextNonce       []byte `json:"text_nonce,omitempty"`
+	CardCipher      []byte `json:"card_cipher,omitempty"`
+	CardNonce       []byte `json:"card_nonce,omitempty"`
+}
+
+// IncomingShareView — запись, открытая пользователю, в ответе GET /api/shares.
+type IncomingShareView struct {
+	ItemID      string `json:"item_id"`
+	Name        string `json:"name"`
+	Owner       string `json:"owner"`
+	ItemVersion int64  `json:"item_version"`
+	ShareRequest
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// OutgoingShareView — выданная пользователем доля в ответе GET /api/shares/outgoing.
+type OutgoingShareView struct {
+	ItemID             string `json:"item_id"`
+	Name               string `json:"name"`
+	Recipient          string `json:"recipient"`
+	RecipientPublicKey []byte `json:"recipient_public_key"`
+	Version            int64  `json:"version"`
+	ItemVersion        int64  `json:"item_version"`
+}
+
+// Share PUT /api/items/{id}/shares/{login} — открывает запись получателю или обновляет его долю
+func (h *ItemHandler) Share(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, login := chi.URLParam(r, "id"), chi.URLParam(r, "login")
+	var req ShareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	err := h.ItemService.ShareItem(r.Context(), userID, id, login, &model.ItemShare{
+		Version:         req.Version,
+		EphemeralKey:    req.EphemeralKey,
+		WrappedKey:      req.WrappedKey,
+		WrappedKeyNonce: req.WrappedKeyNonce,
+		LoginCipher:     req.LoginCipher,
+		LoginNonce:      req.LoginNonce,
+		PasswordCipher:  req.PasswordCipher,
+		PasswordNonce:   req.PasswordNonce,
+		TextCipher:      req.TextCipher,
+		TextNonce:       req.TextNonce,
+		CardCipher:      req.CardCipher,
+		CardNonce:       req.CardNonce,
+	})
+	if err != nil {
+		h.writeShareError(w, r, "Share", userID, id, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Unshare DELETE /api/items/{id}/shares/{login} — закрывает получателю доступ к записи
+func (h *ItemHandler) Unshare(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id := chi.URLParam(r, "id")
+	if err := h.ItemService.RevokeShare(r.Context(), userID, id, chi.URLParam(r, "login")); err != nil {
+		h.writeShareError(w, r, "Unshare", userID, id, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// IncomingShares GET /api/shares — записи, открытые пользователю другими (только чтение)
+func (h *ItemHandler) IncomingShares(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	shares, err := h.ItemService.IncomingShares(r.Context(), userID)
+	if err != nil {
+		h.writeShareError(w, r, "IncomingShares", userID, "", err)
+		return
+	}
+	out := make([]IncomingShareView, 0, len(shares))
+	for i := range shares {
+		out = append(out, toIncomingShareView(&shares[i]))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// OutgoingShares GET /api/shares/outgoing — доли, выданные пользователем
+func (h *ItemHandler) OutgoingShares(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	shares, err := h.ItemService.OutgoingShares(r.Context(), userID)
+	if err != nil {
+		h.writeShareError(w, r, "OutgoingShares", userID, "", err)
+		return
+	}
+	out := make([]OutgoingShareView, 0, len(shares))
+	for _, s := range shares {
+		out = append(out, OutgoingShareView{
+			ItemID:             s.ItemID,
+			Name:               s.Name,
+			Recipient:          s.RecipientLogin,
+			RecipientPublicKey: s.RecipientPublicKey,
+			Version:            s.Version,
+			ItemVersion:        s.ItemVersion,
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// writeShareError переводит ошибки обмена записями в ответ; остальные — как writeDataError.
+func (h *ItemHandler) writeShareError(w http.ResponseWriter, r *http.Request, op string, userID int64, id string, err error) {
+	switch {
+	case errors.Is(err, repo.ErrRecipientNotFound):
+		http.Error(w, "recipient not found", http.StatusNotFound)
+	case errors.Is(err, repo.ErrShareNotFound):
+		http.Error(w, "share not found", http.StatusNotFound)
+	case errors.Is(err, repo.ErrRecipientHasNoKey):
+		http.Error(w, "recipient has not published a public key", http.StatusConflict)
+	case errors.Is(err, service.ErrShareWithSelf):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrShareFileItem):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	default:
+		h.writeDataError(w, r, op, userID, id, err)
+	}
+}
+
+func toIncomingShareView(s *model.IncomingShare) IncomingShareView {
+	return IncomingShareView{
+		ItemID:      s.ItemID,
+		Name:        s.Name,
+		Owner:       s.OwnerLogin,
+		ItemVersion: s.ItemVersion,
+		ShareRequest: ShareRequest{
+			Version:         s.Version,
+			EphemeralKey:    s.EphemeralKey,
+			WrappedKey:      s.WrappedKey,
+			WrappedKeyNonce: s.WrappedKeyNonce,
+			LoginCipher:     s.LoginCipher,
+			LoginNonce:      s.LoginNonce,
+			PasswordCipher:  s.PasswordCipher,
+			PasswordNonce:   s.PasswordNonce,
+			TextCipher:      s.TextCipher,
+			TextNonce:       s.TextNonce,
+			CardCipher:      s.CardCipher,
+			CardNonce:       s.CardNonce,
+		},
+		UpdatedAt: formatTime(s.UpdatedAt),
+	}
+}
@@ -0,0 +1,150 @@
+package handlers_test
+
+import (
+	"GophKeeper/internal/handlers"
+	"GophKeeper/internal/model"
+	"GophKeeper/internal/repo"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type hMockShareRepo struct{ mock.Mock }
+
+func (m *hMockShareRepo) Recipient(ctx context.Context, login string) (int64, []byte, error) {
+	args := m.Called(ctx, login)
+	pub, _ := args.Get(1).([]byte)
+	return args.Get(0).(int64), pub, args.Error(2)
+}
+func (m *hMockShareRepo) Upsert(ctx context.Context, s *model.ItemShare) error {
+	return m.Called(ctx, s).Error(0)
+}
+func (m *hMockShareRepo) Delete(ctx context.Context, ownerID int64, itemID string, recipientID int64) error {
+	return m.Called(ctx, ownerID, itemID, recipientID).Error(0)
+}
+func (m *hMockShareRepo) ListIncoming(ctx context.Context, recipientID int64) ([]model.IncomingShare, error) {
+	args := m.Called(ctx, recipientID)
+	v, _ := args.Get(0).([]model.IncomingShare)
+	return v, args.Error(1)
+}
+func (m *hMockShareRepo) ListOutgoing(ctx context.Context, ownerID int64) ([]model.OutgoingShare, error) {
+	args := m.Called(ctx, ownerID)
+	v, _ := args.Get(0).([]model.OutgoingShare)
+	return v, args.Error(1)
+}
+
+var _ repo.ShareRepository = (*hMockShareRepo)(nil)
+
+type hMockKeyRepo struct{ mock.Mock }
+
+func (m *hMockKeyRepo) GetKeys(ctx context.Context, userID int64) (*model.UserKeys, error) {
+	args := m.Called(ctx, userID)
+	v, _ := args.Get(0).(*model.UserKeys)
+	return v, args.Error(1)
+}
+func (m *hMockKeyRepo) SaveKeys(ctx context.Context, k *model.UserKeys) error {
+	return m.Called(ctx, k).Error(0)
+}
+
+var _ repo.KeyRepository = (*hMockKeyRepo)(nil)
+
+func TestShare_Keys(t *testing.T) {
+	f := newTestFixture(t, fixtureRepos{keys: &hMockKeyRepo{}})
+	pub := make([]byte, 32)
+
+	// ключи ещё не опубликованы
+	f.keys.On("GetKeys", mock.Anything, int64(9)).Return(nil, repo.ErrKeysNotFound).Once()
+	rr := f.serveAs(t, 9, http.MethodGet, "/api/user/keys", "")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	// открытый ключ неверной длины
+	rr = f.serveAs(t, 9, http.MethodPut, "/api/user/keys", `{"public_key":"AQ==","wrapped_private_key":"AQ==","private_key_nonce":"AQ=="}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	f.keys.On("SaveKeys", mock.Anything, mock.MatchedBy(func(k *model.UserKeys) bool {
+		return k.UserID == 9 && len(k.PublicKey) == 32
+	})).Return(nil).Once()
+	body, _ := json.Marshal(handlers.UserKeysBody{PublicKey: pub, WrappedPrivateKey: []byte{1}, PrivateKeyNonce: []byte{2}})
+	rr = f.serveAs(t, 9, http.MethodPut, "/api/user/keys", string(body))
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+
+	// открытый ключ другого пользователя
+	f.users.On("GetUserByLogin", mock.Anything, "bob").Return(&model.User{ID: 7, Login: "bob"}, nil).Once()
+	f.keys.On("GetKeys", mock.Anything, int64(7)).Return(&model.UserKeys{UserID: 7, PublicKey: pub}, nil).Once()
+	rr = f.serveAs(t, 9, http.MethodGet, "/api/users/bob/public-key", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var resp handlers.PublicKeyResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, "bob", resp.Login)
+	assert.Equal(t, pub, resp.PublicKey)
+	f.keys.AssertExpectations(t)
+}
+
+func TestShare_ShareAndRevoke(t *testing.T) {
+	f := newTestFixture(t, fixtureRepos{shares: &hMockShareRepo{}})
+	share := `{"version":3,"ephemeral_key":"AQ==","wrapped_key":"Ag==","wrapped_key_nonce":"Aw==","text_cipher":"BA==","text_nonce":"BQ=="}`
+
+	// версия доли устарела
+	f.items.On("GetByID", mock.Anything, int64(9), dataItemID).Return(&model.Item{ID: dataItemID, UserID: 9, Version: 4}, nil).Once()
+	rr := f.serveAs(t, 9, http.MethodPut, "/api/items/"+dataItemID+"/shares/bob", share)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	// получатель без ключа
+	f.items.On("GetByID", mock.Anything, int64(9), dataItemID).Return(&model.Item{ID: dataItemID, UserID: 9, Version: 3}, nil).Once()
+	f.shares.On("Recipient", mock.Anything, "carol").Return(int64(0), nil, repo.ErrRecipientHasNoKey).Once()
+	rr = f.serveAs(t, 9, http.MethodPut, "/api/items/"+dataItemID+"/shares/carol", share)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	// успешная выдача
+	f.items.On("GetByID", mock.Anything, int64(9), dataItemID).Return(&model.Item{ID: dataItemID, UserID: 9, Version: 3}, nil).Once()
+	f.shares.On("Recipient", mock.Anything, "bob").Return(int64(7), []byte{1}, nil).Once()
+	f.shares.On("Upsert", mock.Anything, mock.MatchedBy(func(s *model.ItemShare) bool {
+		return s.ItemID == dataItemID && s.OwnerID == 9 && s.RecipientID == 7 && s.Version == 3 &&
+			string(s.TextCipher) == "\x04" && string(s.WrappedKey) == "\x02"
+	})).Return(nil).Once()
+	rr = f.serveAs(t, 9, http.MethodPut, "/api/items/"+dataItemID+"/shares/bob", share)
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+
+	// отзыв несуществующей доли
+	f.shares.On("Recipient", mock.Anything, "bob").Return(int64(7), []byte{1}, nil).Once()
+	f.shares.On("Delete", mock.Anything, int64(9), dataItemID, int64(7)).Return(repo.ErrShareNotFound).Once()
+	rr = f.serveAs(t, 9, http.MethodDelete, "/api/items/"+dataItemID+"/shares/bob", "")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	f.items.AssertExpectations(t)
+	f.shares.AssertExpectations(t)
+}
+
+func TestShare_Lists(t *testing.T) {
+	f := newTestFixture(t, fixtureRepos{shares: &hMockShareRepo{}})
+	f.shares.On("ListIncoming", mock.Anything, int64(7)).Return([]model.IncomingShare{{
+		ItemShare:   model.ItemShare{ItemID: dataItemID, Version: 3, WrappedKey: []byte{2}, TextCipher: []byte{4}},
+		Name:        "wifi",
+		OwnerLogin:  "alice",
+		ItemVersion: 4,
+	}}, nil).Once()
+	rr := f.serveAs(t, 7, http.MethodGet, "/api/shares", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	var incoming []handlers.IncomingShareView
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &incoming))
+	if assert.Len(t, incoming, 1) {
+		assert.Equal(t, "alice", incoming[0].Owner)
+		assert.Equal(t, "wifi", incoming[0].Name)
+		assert.Equal(t, int64(3), incoming[0].Version)
+		assert.Equal(t, []byte{4}, incoming[0].TextCipher)
+	}
+
+	f.shares.On("ListOutgoing", mock.Anything, int64(9)).Return([]model.OutgoingShare{{
+		ItemID: dataItemID, Name: "wifi", RecipientLogin: "bob", RecipientPublicKey: []byte{1}, Version: 3, ItemVersion: 4,
+	}}, nil).Once()
+	rr = f.serveAs(t, 9, http.MethodGet, "/api/shares/outgoing", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"recipient":"bob"`)
+	assert.Contains(t, rr.Body.String(), `"item_version":4`)
+	f.shares.AssertExpectations(t)
+}
@@ -2,8 +2,10 @@ package handlers
 
 import (
 	"GophKeeper/internal/config"
+	"GophKeeper/internal/logging"
 	"GophKeeper/internal/middleware"
 	"GophKeeper/internal/service"
+	"GophKeeper/internal/tlsutil"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -27,6 +29,11 @@ func NewUserHandler(userService *service.UserService, logger *zap.SugaredLogger,
 	}
 }
 
+// log — логгер с request_id и trace_id запроса.
+func (h *UserHandler) log(r *http.Request) *zap.SugaredLogger {
+	return logging.FromContext(r.Context(), h.Logger)
+}
+
 type DataResponse struct {
 	Result string `json:"result"`
 }
@@ -61,15 +68,23 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	fp, ok := h.clientCertificate(w, r)
+	if !ok {
+		return
+	}
+
 	user, err := h.UserService.Register(r.Context(), req.Login, req.Password)
 	switch {
 	case err == nil:
-		_ = middleware.SetLoginCookie(w, user.ID, h.Config.AuthSecret)
+		if !h.bindDevice(w, r, user.ID, fp) {
+			return
+		}
+		_ = middleware.SetBoundLoginCookie(w, user.ID, h.Config.AuthSecret, fp)
 		w.WriteHeader(http.StatusOK)
 	case errors.Is(err, service.ErrLoginTaken):
 		http.Error(w, "login already in use", http.StatusConflict)
 	default:
-		h.Logger.Errorw("failed to register user", "error", err)
+		h.log(r).Errorw("failed to register user", "error", err)
 		http.Error(w, "internal error", http.StatusInternalServerError)
 	}
 }
@@ -86,17 +101,61 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	fp, ok := h.clientCertificate(w, r)
+	if !ok {
+		return
+	}
+
 	user, err := h.UserService.Login(r.Context(), req.Login, req.Password)
+	if errors.Is(err, service.ErrUserDisabled) {
+		http.Error(w, "user is disabled", http.StatusForbidden)
+		return
+	}
 	if err != nil {
 		http.Error(w, "invalid login or password", http.StatusUnauthorized)
 		return
 	}
+	if !h.bindDevice(w, r, user.ID, fp) {
+		return
+	}
 
-	if err := middleware.SetLoginCookie(w, user.ID, h.Config.AuthSecret); err != nil {
-		h.Logger.Errorw("failed to set cookie", "error", err)
+	if err := middleware.SetBoundLoginCookie(w, user.ID, h.Config.AuthSecret, fp); err != nil {
+		h.log(r).Errorw("failed to set cookie", "error", err)
 		http.Error(w, "failed to login", http.StatusInternalServerError)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// clientCertificate возвращает отпечаток клиентского сертификата. Если сервер требует mTLS
+// (TLS_CLIENT_CA), запрос без сертификата отклоняется с 401.
+func (h *UserHandler) clientCertificate(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if h.Config.TLSClientCA == "" {
+		return "", true
+	}
+	fp := tlsutil.PeerFingerprint(r.TLS)
+	if fp == "" {
+		http.Error(w, "client certificate required", http.StatusUnauthorized)
+		return "", false
+	}
+	return fp, true
+}
+
+// bindDevice привязывает сертификат к пользователю; чужой сертификат — 403.
+func (h *UserHandler) bindDevice(w http.ResponseWriter, r *http.Request, userID int64, fp string) bool {
+	if fp == "" {
+		return true
+	}
+	err := h.UserService.BindDevice(r.Context(), userID, fp, tlsutil.PeerName(r.TLS))
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, service.ErrDeviceTaken):
+		http.Error(w, "client certificate belongs to another user", http.StatusForbidden)
+	default:
+		h.log(r).Errorw("failed to bind device", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+	return false
+}
@@ -7,9 +7,19 @@ import (
 	"GophKeeper/internal/model"
 	"GophKeeper/internal/repo"
 	"GophKeeper/internal/service"
+	"GophKeeper/internal/tlsutil"
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"errors"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -19,6 +29,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -75,14 +86,78 @@ func (m *mockItemRepo) ListAll(ctx context.Context, userID int64) ([]model.Item,
 	return nil, args.Error(1)
 }
 
+func (m *mockItemRepo) CountByUser(ctx context.Context, userID int64) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockItemRepo) ListVersions(ctx context.Context, userID int64, id string) ([]model.ItemVersion, error) {
+	args := m.Called(ctx, userID, id)
+	if v, ok := args.Get(0).([]model.ItemVersion); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockItemRepo) GetVersion(ctx context.Context, userID int64, id string, version int64) (*model.ItemVersion, error) {
+	args := m.Called(ctx, userID, id, version)
+	if v, ok := args.Get(0).(*model.ItemVersion); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockItemRepo) LatestChangeSeq(ctx context.Context, userID int64) (int64, error) {
+	return 0, nil
+}
+
+// Transaction выполняет fn без реальной транзакции: мок не хранит состояние.
+func (m *mockItemRepo) Transaction(ctx context.Context, fn func(tx repo.ItemRepository) error) error {
+	return fn(m)
+}
+
+func (m *mockItemRepo) GetItemsChangedSince(ctx context.Context, userID int64, cursor int64, limit int) ([]model.Item, error) {
+	args := m.Called(ctx, userID, cursor, limit)
+	if v, ok := args.Get(0).([]model.Item); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 var _ repo.ItemRepository = (*mockItemRepo)(nil)
 
 type mockBlobRepo struct{ mock.Mock }
 
-func (m *mockBlobRepo) CreateIfAbsent(ctx context.Context, id string, cipher, nonce []byte) (bool, error) {
-	args := m.Called(ctx, id, cipher, nonce)
+func (m *mockBlobRepo) CreateIfAbsent(ctx context.Context, userID int64, id string, nonce []byte, size int64) (bool, error) {
+	args := m.Called(ctx, userID, id, nonce, size)
 	return args.Bool(0), args.Error(1)
 }
+func (m *mockBlobRepo) GetByID(ctx context.Context, id string) (*model.Blob, error) {
+	args := m.Called(ctx, id)
+	if v, ok := args.Get(0).(*model.Blob); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *mockBlobRepo) ListInline(ctx context.Context, limit int) ([]model.Blob, error) {
+	args := m.Called(ctx, limit)
+	if v, ok := args.Get(0).([]model.Blob); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *mockBlobRepo) ClearInline(ctx context.Context, id string, size int64) error {
+	return m.Called(ctx, id, size).Error(0)
+}
+
+func (m *mockBlobRepo) Delete(ctx context.Context, id string) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *mockBlobRepo) SumSizeByUser(ctx context.Context, userID int64) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
 
 var _ repo.BlobRepository = (*mockBlobRepo)(nil)
 
@@ -94,7 +169,7 @@ func newTestRouter(t *testing.T, ur repo.UserRepository) http.Handler {
 
 	userSvc := service.NewUserService(ur)
 	// для user‑тестов item‑сервисы не используются, дадим заглушки
-	itemSvc := service.NewItemService(&mockItemRepo{}, &mockBlobRepo{}, logger)
+	itemSvc := service.NewItemService(&mockItemRepo{}, &mockBlobRepo{}, nil, logger)
 
 	h := handlers.NewHandler(userSvc, itemSvc, logger, cfg)
 	return h.Router
@@ -219,3 +294,156 @@ func TestUser_Status(t *testing.T) {
 		assert.Contains(t, body.Result, "User ID = 77")
 	})
 }
+
+type mockDeviceRepo struct{ mock.Mock }
+
+func (m *mockDeviceRepo) Bind(ctx context.Context, userID int64, fingerprint, name string) (*model.Device, error) {
+	args := m.Called(ctx, userID, fingerprint, name)
+	if d, ok := args.Get(0).(*model.Device); ok {
+		return d, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockDeviceRepo) ListByUser(ctx context.Context, userID int64) ([]model.Device, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]model.Device), args.Error(1)
+}
+
+var _ repo.DeviceRepository = (*mockDeviceRepo)(nil)
+
+// peerCert — состояние TLS соединения с клиентским сертификатом устройства cn.
+func peerCert(t *testing.T, cn string) *tls.ConnectionState {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: cn}, NotAfter: time.Now().Add(time.Hour)}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+}
+
+// С TLS_CLIENT_CA вход требует сертификат устройства, а выданный токен работает только с ним.
+func TestUser_LoginWithClientCertificate(t *testing.T) {
+	m := new(mockUserRepo)
+	d := new(mockDeviceRepo)
+	cfg := &config.Config{AuthSecret: "test-secret", BlobMaxSizeMB: 1, TLSClientCA: "clients.pem"}
+	logger := zap.NewNop().Sugar()
+	userSvc := service.NewUserService(m)
+	userSvc.SetDeviceRepository(d)
+	router := handlers.NewHandler(userSvc, service.NewItemService(&mockItemRepo{}, &mockBlobRepo{}, nil, logger), logger, cfg).Router
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	m.On("GetUserByLogin", mock.Anything, "alice").Return(&model.User{ID: 2, Login: "alice", Password: string(hash)}, nil)
+	laptop, phone := peerCert(t, "laptop"), peerCert(t, "phone")
+	fp := tlsutil.PeerFingerprint(laptop)
+
+	login := func(cs *tls.ConnectionState) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/user/login", strings.NewReader(`{"login":"alice","password":"secret"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.TLS = cs
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	status := func(cookies []*http.Cookie, cs *tls.ConnectionState) string {
+		req := httptest.NewRequest(http.MethodPost, "/api/user/test", nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		req.TLS = cs
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		var body struct {
+			Result string `json:"result"`
+		}
+		_ = json.NewDecoder(rr.Body).Decode(&body)
+		return body.Result
+	}
+
+	t.Run("no certificate", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, login(nil).Code)
+	})
+
+	t.Run("bound to device", func(t *testing.T) {
+		d.On("Bind", mock.Anything, int64(2), fp, "laptop").Return(&model.Device{ID: 1, UserID: 2}, nil).Once()
+		rr := login(laptop)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		cookies := rr.Result().Cookies()
+		assert.Equal(t, "User ID = 2", status(cookies, laptop))
+		// тот же токен с другим сертификатом или без него не действует
+		assert.Equal(t, "anonymous", status(cookies, phone))
+		assert.Equal(t, "anonymous", status(cookies, nil))
+	})
+
+	t.Run("unbound token rejected", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/user/test", nil)
+		addAuthCookie(t, req, 2, cfg.AuthSecret)
+		assert.Equal(t, "anonymous", status(req.Cookies(), laptop))
+	})
+
+	t.Run("certificate of another user", func(t *testing.T) {
+		d.On("Bind", mock.Anything, int64(2), tlsutil.PeerFingerprint(phone), "phone").Return(nil, repo.ErrDeviceOwnedByOther).Once()
+		assert.Equal(t, http.StatusForbidden, login(phone).Code)
+	})
+	d.AssertExpectations(t)
+}
+
+// Тело запроса со сжатием gzip распаковывается до проверки контракта и хендлера.
+func TestUser_Login_GzipBody(t *testing.T) {
+	m := new(mockUserRepo)
+	router := newTestRouter(t, m)
+	hash, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	m.On("GetUserByLogin", mock.Anything, "alice").Return(&model.User{ID: 2, Login: "alice", Password: string(hash)}, nil).Once()
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	_, _ = gw.Write([]byte(`{"login":"alice","password":"secret"}`))
+	_ = gw.Close()
+	req := httptest.NewRequest(http.MethodPost, "/api/user/login", &buf)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	req = httptest.NewRequest(http.MethodPost, "/api/user/login", strings.NewReader(`{}`))
+	req.Header.Set("Content-Encoding", "br")
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+	m.AssertExpectations(t)
+}
+
+func TestUser_Register_InternalErrorCarriesRequestID(t *testing.T) {
+	m := new(mockUserRepo)
+	m.On("GetUserByLogin", mock.Anything, "bob").Return(nil, errors.New("not found")).Once()
+	m.On("CreateUser", mock.Anything, mock.Anything).Return(nil, errors.New("db down")).Once()
+
+	core, logs := observer.New(zap.ErrorLevel)
+	logger := zap.New(core).Sugar()
+	cfg := &config.Config{AuthSecret: "test-secret", BlobMaxSizeMB: 1}
+	router := handlers.NewHandler(service.NewUserService(m), service.NewItemService(&mockItemRepo{}, &mockBlobRepo{}, nil, logger), logger, cfg).Router
+
+	req := httptest.NewRequest(http.MethodPost, "/api/user/register", strings.NewReader(`{"login":"bob","password":"secret"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-ID", "cli-req-7")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.Equal(t, "cli-req-7", rr.Header().Get("X-Request-ID"), "id клиента возвращается в ответе с ошибкой")
+	entries := logs.FilterMessage("failed to register user").All()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "cli-req-7", entries[0].ContextMap()["request_id"], "строку лога можно найти по id из ответа")
+	}
+	m.AssertExpectations(t)
+}
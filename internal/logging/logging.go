@@ -0,0 +1,67 @@
+// Package logging — сквозной идентификатор запроса в контексте и логгер с ним.
+package logging
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// RequestIDHeader — заголовок HTTP (и ключ metadata gRPC в нижнем регистре) с идентификатором запроса.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen — входящие идентификаторы длиннее отбрасываются и генерируются заново.
+const maxRequestIDLen = 128
+
+type requestIDKey struct{}
+
+// WithRequestID кладёт идентификатор запроса в контекст.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID возвращает идентификатор запроса из контекста или "".
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID генерирует идентификатор запроса.
+func NewRequestID() string {
+	return uuid.NewString()
+}
+
+// AcceptRequestID возвращает id клиента, если он пригоден для логов (печатные ASCII без
+// пробелов, не длиннее 128 символов), иначе — новый идентификатор.
+func AcceptRequestID(id string) string {
+	if id == "" || len(id) > maxRequestIDLen {
+		return NewRequestID()
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return NewRequestID()
+		}
+	}
+	return id
+}
+
+// FromContext дополняет base полями request_id и trace_id из ctx, чтобы строку лога
+// можно было найти по идентификатору из ответа или по трассе.
+func FromContext(ctx context.Context, base *zap.SugaredLogger) *zap.SugaredLogger {
+	if base == nil {
+		base = zap.NewNop().Sugar()
+	}
+	var fields []any
+	if id := RequestID(ctx); id != "" {
+		fields = append(fields, "request_id", id)
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		fields = append(fields, "trace_id", sc.TraceID().String())
+	}
+	if len(fields) == 0 {
+		return base
+	}
+	return base.With(fields...)
+}
@@ -0,0 +1,44 @@
+package logging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestAcceptRequestID(t *testing.T) {
+	assert.Equal(t, "abc-123", AcceptRequestID("abc-123"))
+	for _, bad := range []string{"", "with space", "юникод", string(make([]byte, 129))} {
+		got := AcceptRequestID(bad)
+		assert.NotEqual(t, bad, got)
+		assert.Len(t, got, 36, "вместо непригодного id генерируется uuid")
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	base := zap.New(core).Sugar()
+
+	FromContext(context.Background(), base).Info("plain")
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(WithRequestID(context.Background(), "req-1"),
+		trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+	FromContext(ctx, base).Info("tagged")
+
+	entries := logs.All()
+	require.Len(t, entries, 2)
+	assert.Empty(t, entries[0].ContextMap())
+	assert.Equal(t, map[string]any{
+		"request_id": "req-1",
+		"trace_id":   "4bf92f3577b34da6a3ce929d0e0e4736",
+	}, entries[1].ContextMap())
+
+	assert.NotNil(t, FromContext(ctx, nil), "без базового логгера — Nop")
+}
@@ -0,0 +1,89 @@
+// Package metrics — метрики сервера в формате Prometheus (GET /metrics).
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "gophkeeper"
+
+// Registry — реестр метрик сервера (отдельный от глобального, чтобы не тащить метрики библиотек).
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests — число HTTP-запросов по маршруту chi, методу и коду ответа.
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route, method and status.",
+	}, []string{"route", "method", "status"})
+
+	// HTTPDuration — длительность HTTP-запросов.
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route, method and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	// SyncBatchSize — число изменений в одном запросе синхронизации.
+	SyncBatchSize = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "sync_batch_size",
+		Help:      "Changes per sync request.",
+		Buckets:   []float64{0, 1, 5, 10, 25, 50, 100, 250, 500, 1000},
+	})
+
+	// SyncConflicts — конфликты синхронизации по причине.
+	SyncConflicts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sync_conflicts_total",
+		Help:      "Sync conflicts by reason.",
+	}, []string{"reason"})
+
+	// BlobBytesUploaded — объём загруженных шифртекстов блобов.
+	BlobBytesUploaded = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "blob_uploaded_bytes_total",
+		Help:      "Bytes of blob ciphertext uploaded.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests, HTTPDuration, SyncBatchSize, SyncConflicts, BlobBytesUploaded,
+	)
+}
+
+// RegisterDB добавляет статистику пула соединений БД (go_sql_* с меткой db_name).
+func RegisterDB(db *sql.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// ObserveHTTP учитывает завершённый HTTP-запрос.
+func ObserveHTTP(route, method string, status int, seconds float64) {
+	code := strconv.Itoa(status)
+	HTTPRequests.WithLabelValues(route, method, code).Inc()
+	HTTPDuration.WithLabelValues(route, method, code).Observe(seconds)
+}
+
+// ObserveSync учитывает размер батча синхронизации и причины его конфликтов.
+func ObserveSync(changes int, reasons []string) {
+	SyncBatchSize.Observe(float64(changes))
+	for _, r := range reasons {
+		SyncConflicts.WithLabelValues(r).Inc()
+	}
+}
+
+// Handler отдаёт метрики в текстовом формате Prometheus.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
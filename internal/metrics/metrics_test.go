@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func TestObserveSync(t *testing.T) {
+	conflict := SyncConflicts.WithLabelValues("version_conflict")
+	tooLarge := SyncConflicts.WithLabelValues("item_too_large")
+	c0, l0 := testutil.ToFloat64(conflict), testutil.ToFloat64(tooLarge)
+
+	ObserveSync(3, []string{"version_conflict", "version_conflict", "item_too_large"})
+
+	assert.Equal(t, c0+2, testutil.ToFloat64(conflict))
+	assert.Equal(t, l0+1, testutil.ToFloat64(tooLarge))
+}
+
+func TestHandler_ExposesServerAndDBMetrics(t *testing.T) {
+	db, err := sql.Open("sqlite", "file::memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, RegisterDB(db, "test"))
+
+	ObserveHTTP("/api/items/sync", http.MethodPost, http.StatusOK, 0.01)
+	BlobBytesUploaded.Add(5)
+
+	rr := httptest.NewRecorder()
+	Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	body := rr.Body.String()
+	for _, name := range []string{
+		`gophkeeper_http_requests_total{method="POST",route="/api/items/sync",status="200"}`,
+		`gophkeeper_http_request_duration_seconds_bucket{method="POST",route="/api/items/sync",status="200"`,
+		"gophkeeper_sync_batch_size_bucket",
+		"gophkeeper_blob_uploaded_bytes_total",
+		`go_sql_open_connections{db_name="test"}`,
+	} {
+		assert.Contains(t, body, name)
+	}
+}
@@ -1,7 +1,10 @@
 package middleware
 
 import (
+	"GophKeeper/internal/tlsutil"
 	"context"
+	"crypto/tls"
+	"math"
 	"net/http"
 	"time"
 
@@ -14,25 +17,38 @@ const (
 
 type contextKey string
 
+// SessionCheck проверяет по БД, действителен ли токен пользователя, выданный в issuedAt
+// (пользователь не отключён и не удалён, сессии не отозваны).
+type SessionCheck func(ctx context.Context, userID int64, issuedAt time.Time) (bool, error)
+
+var sessionCheck SessionCheck
+
+// SetSessionCheck задаёт проверку сессий для HTTP и gRPC API; nil — только подпись и срок токена.
+func SetSessionCheck(c SessionCheck) {
+	sessionCheck = c
+}
+
 const UserKey contextKey = "user_id"
 
-// WithAuth добавляет user_id в контекст, если токен валиден
+// WithAuth добавляет user_id в контекст, если токен валиден.
+// Токен, привязанный к клиентскому сертификату, принимается только с тем же сертификатом.
 func WithAuth(secret string) func(http.Handler) http.Handler {
+	return withAuth(secret, false)
+}
+
+// WithCertAuth как WithAuth, но принимает только токены, привязанные к клиентскому
+// сертификату соединения (mTLS как второй фактор): украденного JWT недостаточно.
+func WithCertAuth(secret string) func(http.Handler) http.Handler {
+	return withAuth(secret, true)
+}
+
+func withAuth(secret string, requireCert bool) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			cookie, err := r.Cookie(authCookieName)
 			if err == nil {
-				token, err := jwt.Parse(cookie.Value, func(token *jwt.Token) (interface{}, error) {
-					return []byte(secret), nil
-				})
-				if err == nil && token.Valid {
-					if claims, ok := token.Claims.(jwt.MapClaims); ok {
-						if userIDFloat, ok := claims["user_id"].(float64); ok {
-							userID := int64(userIDFloat)
-							ctx := context.WithValue(r.Context(), UserKey, userID)
-							r = r.WithContext(ctx)
-						}
-					}
+				if userID, ok := AuthorizeToken(r.Context(), cookie.Value, secret, r.TLS, requireCert); ok {
+					r = r.WithContext(WithUserID(r.Context(), userID))
 				}
 			}
 			next.ServeHTTP(w, r)
@@ -40,13 +56,106 @@ func WithAuth(secret string) func(http.Handler) http.Handler {
 	}
 }
 
-// SetLoginCookie устанавливает токен с user_id
-func SetLoginCookie(w http.ResponseWriter, userID int64, secret string) error {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+// AuthorizeToken проверяет токен, его привязку к клиентскому сертификату соединения cs
+// и сессию пользователя (используется и HTTP, и gRPC API). requireCert — непривязанные
+// токены не принимаются.
+func AuthorizeToken(ctx context.Context, raw, secret string, cs *tls.ConnectionState, requireCert bool) (int64, bool) {
+	userID, certFP, issuedAt, ok := parseToken(raw, secret)
+	if !ok {
+		return 0, false
+	}
+	if certFP == "" && requireCert {
+		return 0, false
+	}
+	if certFP != "" && certFP != tlsutil.PeerFingerprint(cs) {
+		return 0, false
+	}
+	if sessionCheck != nil {
+		active, err := sessionCheck(ctx, userID, issuedAt)
+		if err != nil && sugar != nil {
+			sugar.Errorw("session check failed", "user_id", userID, "error", err)
+		}
+		if err != nil || !active {
+			return 0, false
+		}
+	}
+	return userID, true
+}
+
+// NewToken подписывает JWT с user_id (используется и HTTP, и gRPC API)
+func NewToken(userID int64, secret string) (string, error) {
+	return NewBoundToken(userID, secret, "")
+}
+
+// NewBoundToken подписывает JWT, привязанный к клиентскому сертификату с отпечатком
+// certFP (claim cnf.x5t#S256, RFC 8705); пустой certFP — токен без привязки.
+func NewBoundToken(userID int64, secret, certFP string) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
 		"user_id": userID,
-		"exp":     time.Now().Add(365 * 24 * time.Hour).Unix(),
+		// дробные секунды: вход сразу после отзыва сессий не должен попасть под отзыв
+		"iat": float64(now.UnixMicro()) / 1e6,
+		"exp": now.Add(365 * 24 * time.Hour).Unix(),
+	}
+	if certFP != "" {
+		claims["cnf"] = map[string]string{"x5t#S256": certFP}
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+}
+
+// ParseToken проверяет JWT и возвращает user_id
+func ParseToken(raw, secret string) (int64, bool) {
+	userID, _, ok := ParseBoundToken(raw, secret)
+	return userID, ok
+}
+
+// ParseBoundToken проверяет JWT и возвращает user_id и отпечаток привязанного сертификата ("" — без привязки).
+func ParseBoundToken(raw, secret string) (int64, string, bool) {
+	userID, certFP, _, ok := parseToken(raw, secret)
+	return userID, certFP, ok
+}
+
+// parseToken проверяет JWT и возвращает user_id, отпечаток привязанного сертификата
+// и время выдачи (нулевое у токенов, выданных до появления claim iat).
+func parseToken(raw, secret string) (int64, string, time.Time, bool) {
+	token, err := jwt.Parse(raw, func(token *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
 	})
-	signed, err := token.SignedString([]byte(secret))
+	if err != nil || !token.Valid {
+		return 0, "", time.Time{}, false
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return 0, "", time.Time{}, false
+	}
+	userIDFloat, ok := claims["user_id"].(float64)
+	if !ok {
+		return 0, "", time.Time{}, false
+	}
+	var certFP string
+	if cnf, ok := claims["cnf"].(map[string]any); ok {
+		certFP, _ = cnf["x5t#S256"].(string)
+		if certFP == "" {
+			// привязка есть, но нечитаема — токен не принимаем
+			return 0, "", time.Time{}, false
+		}
+	}
+	// GetIssuedAt отбрасывает доли секунды, поэтому iat читается напрямую
+	var issuedAt time.Time
+	if iat, ok := claims["iat"].(float64); ok {
+		issuedAt = time.UnixMicro(int64(math.Round(iat * 1e6)))
+	}
+	return int64(userIDFloat), certFP, issuedAt, true
+}
+
+// SetLoginCookie устанавливает токен с user_id
+func SetLoginCookie(w http.ResponseWriter, userID int64, secret string) error {
+	return SetBoundLoginCookie(w, userID, secret, "")
+}
+
+// SetBoundLoginCookie устанавливает токен, привязанный к клиентскому сертификату certFP.
+func SetBoundLoginCookie(w http.ResponseWriter, userID int64, secret, certFP string) error {
+	signed, err := NewBoundToken(userID, secret, certFP)
 	if err != nil {
 		return err
 	}
@@ -60,6 +169,11 @@ func SetLoginCookie(w http.ResponseWriter, userID int64, secret string) error {
 	return nil
 }
 
+// WithUserID кладёт user_id в контекст
+func WithUserID(ctx context.Context, userID int64) context.Context {
+	return context.WithValue(ctx, UserKey, userID)
+}
+
 // GetUserIDFromContext достаёт user_id из контекста
 func GetUserIDFromContext(ctx context.Context) (int64, bool) {
 	userID, ok := ctx.Value(UserKey).(int64)
@@ -1,9 +1,20 @@
 package middleware
 
 import (
+	"GophKeeper/internal/tlsutil"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 // Тест: SetLoginCookie + WithAuth — user_id попадает в контекст
@@ -78,3 +89,128 @@ func TestWithAuth_InvalidToken(t *testing.T) {
 		t.Fatalf("expected 200, got %d", rr.Code)
 	}
 }
+
+func certState(t *testing.T, cn string) *tls.ConnectionState {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: cn}, NotAfter: time.Now().Add(time.Hour)}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+}
+
+// Токен, привязанный к сертификату, работает только с ним; WithCertAuth не принимает непривязанные.
+func TestWithAuth_CertificateBinding(t *testing.T) {
+	const secret = "s"
+	laptop, phone := certState(t, "laptop"), certState(t, "phone")
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := GetUserIDFromContext(r.Context()); ok {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	call := func(mw func(http.Handler) http.Handler, certFP string, cs *tls.ConnectionState) int {
+		rr := httptest.NewRecorder()
+		if err := SetBoundLoginCookie(rr, 5, secret, certFP); err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for _, c := range rr.Result().Cookies() {
+			req.AddCookie(c)
+		}
+		req.TLS = cs
+		out := httptest.NewRecorder()
+		mw(next).ServeHTTP(out, req)
+		return out.Code
+	}
+	fp := tlsutil.PeerFingerprint(laptop)
+
+	cases := []struct {
+		name   string
+		mw     func(http.Handler) http.Handler
+		certFP string
+		cs     *tls.ConnectionState
+		want   int
+	}{
+		{"bound, same cert", WithAuth(secret), fp, laptop, http.StatusOK},
+		{"bound, other cert", WithAuth(secret), fp, phone, http.StatusUnauthorized},
+		{"bound, no cert", WithAuth(secret), fp, nil, http.StatusUnauthorized},
+		{"unbound, plain auth", WithAuth(secret), "", nil, http.StatusOK},
+		{"cert auth, same cert", WithCertAuth(secret), fp, laptop, http.StatusOK},
+		{"cert auth, other cert", WithCertAuth(secret), fp, phone, http.StatusUnauthorized},
+		{"cert auth, unbound token", WithCertAuth(secret), "", laptop, http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		if got := call(tc.mw, tc.certFP, tc.cs); got != tc.want {
+			t.Errorf("%s: got %d, want %d", tc.name, got, tc.want)
+		}
+	}
+
+	uid, gotFP, ok := ParseBoundToken(mustToken(t, secret, fp), secret)
+	if !ok || uid != 5 || gotFP != fp {
+		t.Fatalf("ParseBoundToken = %d, %q, %v", uid, gotFP, ok)
+	}
+}
+
+func mustToken(t *testing.T, secret, fp string) string {
+	t.Helper()
+	tok, err := NewBoundToken(5, secret, fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tok
+}
+
+// Тест: проверка сессии отклоняет токены отключённых пользователей и получает время выдачи
+func TestAuthorizeToken_SessionCheck(t *testing.T) {
+	const secret = "test-secret"
+	raw, err := NewToken(5, secret)
+	if err != nil {
+		t.Fatalf("NewToken: %v", err)
+	}
+	var gotIssued time.Time
+	active, checkErr := true, error(nil)
+	SetSessionCheck(func(_ context.Context, userID int64, issuedAt time.Time) (bool, error) {
+		if userID != 5 {
+			t.Fatalf("unexpected user id %d", userID)
+		}
+		gotIssued = issuedAt
+		return active, checkErr
+	})
+	t.Cleanup(func() { SetSessionCheck(nil) })
+
+	if uid, ok := AuthorizeToken(context.Background(), raw, secret, nil, false); !ok || uid != 5 {
+		t.Fatalf("active session: got %d, %v", uid, ok)
+	}
+	if time.Since(gotIssued) > time.Minute {
+		t.Fatalf("issued at not passed to session check: %v", gotIssued)
+	}
+	// токен, выданный сразу после отзыва, не должен попасть под отзыв в ту же секунду
+	revokedAt := time.Now()
+	time.Sleep(2 * time.Millisecond)
+	fresh, err := NewToken(5, secret)
+	if err != nil {
+		t.Fatalf("NewToken: %v", err)
+	}
+	if _, ok := AuthorizeToken(context.Background(), fresh, secret, nil, false); !ok || !gotIssued.After(revokedAt) {
+		t.Fatalf("issued at must keep sub-second precision: %v <= %v", gotIssued, revokedAt)
+	}
+	active = false
+	if _, ok := AuthorizeToken(context.Background(), raw, secret, nil, false); ok {
+		t.Fatalf("revoked session must be rejected")
+	}
+	active, checkErr = true, errors.New("db is down")
+	if _, ok := AuthorizeToken(context.Background(), raw, secret, nil, false); ok {
+		t.Fatalf("session must be rejected when check fails")
+	}
+}
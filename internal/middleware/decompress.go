@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// supportedEncodings — значение Accept-Encoding в ответе 415 (RFC 7694).
+const supportedEncodings = "gzip, zstd"
+
+// WithDecompression прозрачно распаковывает тела запросов с Content-Encoding gzip или zstd.
+// Распакованное тело ограничено maxBytes (защита от zip-бомб): при превышении чтение
+// возвращает *http.MaxBytesError, а WithValidation и хендлеры отвечают 413.
+// Неизвестная кодировка — 415, повреждённый поток — 400.
+func WithDecompression(maxBytes int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+			var body io.ReadCloser
+			switch encoding {
+			case "", "identity":
+				next.ServeHTTP(w, r)
+				return
+			case "gzip", "x-gzip":
+				gr, err := gzip.NewReader(r.Body)
+				if err != nil {
+					http.Error(w, "invalid gzip body", http.StatusBadRequest)
+					return
+				}
+				body = gr
+			case "zstd":
+				zr, err := zstd.NewReader(r.Body,
+					zstd.WithDecoderConcurrency(1),
+					zstd.WithDecoderMaxMemory(uint64(maxBytes)))
+				if err != nil {
+					http.Error(w, "invalid zstd body", http.StatusBadRequest)
+					return
+				}
+				body = zr.IOReadCloser()
+			default:
+				w.Header().Set("Accept-Encoding", supportedEncodings)
+				http.Error(w, "unsupported content encoding", http.StatusUnsupportedMediaType)
+				return
+			}
+			defer body.Close()
+
+			r.Body = http.MaxBytesReader(w, &decodeErrorReader{body}, maxBytes)
+			r.ContentLength = -1
+			r.Header.Del("Content-Length")
+			r.Header.Del("Content-Encoding")
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// IsBodyTooLarge сообщает, что чтение тела запроса упёрлось в лимит размера.
+func IsBodyTooLarge(err error) bool {
+	var maxErr *http.MaxBytesError
+	return errors.As(err, &maxErr)
+}
+
+// decodeErrorReader превращает превышение памяти декодера zstd в *http.MaxBytesError,
+// чтобы лимит распакованного размера обрабатывался одинаково для всех кодировок.
+type decodeErrorReader struct {
+	io.ReadCloser
+}
+
+func (r *decodeErrorReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if errors.Is(err, zstd.ErrDecoderSizeExceeded) || errors.Is(err, zstd.ErrWindowSizeExceeded) {
+		return n, &http.MaxBytesError{}
+	}
+	return n, err
+}
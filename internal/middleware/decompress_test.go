@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gzipBytes(t *testing.T, b []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	_, err := gw.Write(b)
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+	return buf.Bytes()
+}
+
+func zstdBytes(t *testing.T, b []byte) []byte {
+	t.Helper()
+	enc, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	defer enc.Close()
+	return enc.EncodeAll(b, nil)
+}
+
+// echoHandler возвращает прочитанное тело; ошибка чтения — 413 или 400, как в хендлерах.
+func echoHandler(t *testing.T) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NotContains(t, []string{"gzip", "zstd"}, r.Header.Get("Content-Encoding"), "распакованное тело без Content-Encoding")
+		b, err := io.ReadAll(r.Body)
+		switch {
+		case IsBodyTooLarge(err):
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		case err != nil:
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			_, _ = w.Write(b)
+		}
+	})
+}
+
+func TestWithDecompression(t *testing.T) {
+	h := WithDecompression(1024)(echoHandler(t))
+	payload := []byte(strings.Repeat(`{"a":"b"}`, 100)) // 900 байт — в пределах лимита
+
+	cases := []struct {
+		name, encoding string
+		body           []byte
+		want           int
+		wantBody       []byte
+	}{
+		{"plain", "", payload, http.StatusOK, payload},
+		{"identity", "identity", payload, http.StatusOK, payload},
+		{"gzip", "gzip", gzipBytes(t, payload), http.StatusOK, payload},
+		{"zstd", "zstd", zstdBytes(t, payload), http.StatusOK, payload},
+		{"gzip bomb", "gzip", gzipBytes(t, make([]byte, 1<<20)), http.StatusRequestEntityTooLarge, nil},
+		{"zstd bomb", "zstd", zstdBytes(t, make([]byte, 1<<20)), http.StatusRequestEntityTooLarge, nil},
+		{"corrupt gzip header", "gzip", []byte("not gzip"), http.StatusBadRequest, nil},
+		{"truncated gzip", "gzip", gzipBytes(t, payload)[:20], http.StatusBadRequest, nil},
+		{"unsupported", "br", payload, http.StatusUnsupportedMediaType, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(tc.body))
+			req.Header.Set("Content-Encoding", tc.encoding)
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+			require.Equal(t, tc.want, rr.Code, rr.Body.String())
+			if tc.wantBody != nil {
+				assert.Equal(t, tc.wantBody, rr.Body.Bytes())
+			}
+			if tc.want == http.StatusUnsupportedMediaType {
+				assert.Equal(t, "gzip, zstd", rr.Header().Get("Accept-Encoding"))
+			}
+		})
+	}
+}
+
+// Сжатое тело проходит проверку контракта, а превышение лимита — 413, а не 400.
+func TestWithDecompression_BeforeValidation(t *testing.T) {
+	h, body, called := validationChain(t)
+	h = WithDecompression(4096)(h)
+
+	payload := `{"cursor":"0","changes":[{"id":"a","version":0,"login_cipher":"AQI=","base_versions":{"login":1}}]}`
+	req := authed(t, httptest.NewRequest(http.MethodPost, "/api/items/sync", bytes.NewReader(gzipBytes(t, []byte(payload)))))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.True(t, *called)
+	assert.JSONEq(t, payload, string(*body))
+
+	*called = false
+	huge := `{"cursor":"0","changes":[],"pad":"` + strings.Repeat("A", 1<<20) + `"}`
+	req = authed(t, httptest.NewRequest(http.MethodPost, "/api/items/sync", bytes.NewReader(gzipBytes(t, []byte(huge)))))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	assert.False(t, *called)
+}
@@ -20,6 +20,14 @@ func (w *gzipResponseWriter) WriteHeader(code int) {
 	w.ResponseWriter.WriteHeader(code)
 }
 
+// Flush сбрасывает сжатые данные клиенту (нужно для потоковых ответов, например SSE).
+func (w *gzipResponseWriter) Flush() {
+	_ = w.gw.Flush()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 func WithGzip(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// проверка, поддерживает ли клиент gzip
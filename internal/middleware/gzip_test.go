@@ -64,3 +64,31 @@ func TestWithGzip_WithAcceptEncoding(t *testing.T) {
 		t.Fatalf("unexpected ungzipped body: %q", string(data))
 	}
 }
+
+// Тест: Flush отдаёт клиенту уже записанные данные без закрытия потока (нужно для SSE)
+func TestWithGzip_FlushStreamsPartialData(t *testing.T) {
+	flushed := make(chan []byte, 1)
+	rr := httptest.NewRecorder()
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("event: change\n\n"))
+		w.(http.Flusher).Flush()
+		flushed <- append([]byte(nil), rr.Body.Bytes()...)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	WithGzip(next).ServeHTTP(rr, req)
+
+	if !rr.Flushed {
+		t.Fatalf("underlying writer was not flushed")
+	}
+	// до закрытия gzip-потока уже сброшенные данные распаковываются
+	gr, err := gzip.NewReader(bytes.NewReader(<-flushed))
+	if err != nil {
+		t.Fatalf("failed to create gzip reader: %v", err)
+	}
+	buf := make([]byte, 64)
+	n, _ := gr.Read(buf)
+	if string(buf[:n]) != "event: change\n\n" {
+		t.Fatalf("unexpected flushed data: %q", buf[:n])
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"GophKeeper/internal/logging"
 	"go.uber.org/zap"
 	"net/http"
 	"time"
@@ -39,6 +40,13 @@ func (r *loggingResponseWriter) WriteHeader(statusCode int) {
 	r.responseData.status = statusCode // захватываем код статуса
 }
 
+// Flush передаёт сброс буфера исходному http.ResponseWriter, если он это поддерживает.
+func (r *loggingResponseWriter) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 // WithLogging добавляет дополнительный код для регистрации сведений о запросе
 // и возвращает новый http.Handler.
 func WithLogging(h http.Handler) http.Handler {
@@ -57,7 +65,7 @@ func WithLogging(h http.Handler) http.Handler {
 
 		duration := time.Since(start)
 
-		sugar.Infow("request completed",
+		logging.FromContext(r.Context(), sugar).Infow("request completed",
 			"uri", r.RequestURI,
 			"method", r.Method,
 			"status", responseData.status,
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"GophKeeper/internal/metrics"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// unmatchedRoute — метка маршрута для запросов, не попавших ни в один маршрут
+// (подставлять путь нельзя: число рядов метрик стало бы неограниченным).
+const unmatchedRoute = "unmatched"
+
+// WithMetrics учитывает число и длительность запросов по шаблону маршрута chi и коду ответа.
+func WithMetrics(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		responseData := &responseData{}
+		mw := loggingResponseWriter{ResponseWriter: w, responseData: responseData}
+		h.ServeHTTP(&mw, r)
+
+		route := unmatchedRoute
+		if rc := chi.RouteContext(r.Context()); rc != nil && rc.RoutePattern() != "" {
+			route = rc.RoutePattern()
+		}
+		status := responseData.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		metrics.ObserveHTTP(route, r.Method, status, time.Since(start).Seconds())
+	})
+}
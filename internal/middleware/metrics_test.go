@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"GophKeeper/internal/metrics"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithMetrics_LabelsByRoutePattern(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(WithMetrics)
+	r.Get("/things/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	r.Get("/ok", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok")) // статус по умолчанию — 200
+	})
+
+	teapot := metrics.HTTPRequests.WithLabelValues("/things/{id}", http.MethodGet, "418")
+	ok := metrics.HTTPRequests.WithLabelValues("/ok", http.MethodGet, "200")
+	unmatched := metrics.HTTPRequests.WithLabelValues(unmatchedRoute, http.MethodGet, "404")
+	before := []float64{testutil.ToFloat64(teapot), testutil.ToFloat64(ok), testutil.ToFloat64(unmatched)}
+
+	for _, path := range []string{"/things/1", "/things/2", "/ok", "/nope/123"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// разные id попадают в один ряд шаблона маршрута
+	assert.Equal(t, before[0]+2, testutil.ToFloat64(teapot))
+	assert.Equal(t, before[1]+1, testutil.ToFloat64(ok))
+	assert.Equal(t, before[2]+1, testutil.ToFloat64(unmatched))
+}
@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"GophKeeper/internal/logging"
+	"net/http"
+)
+
+// WithRequestID принимает X-Request-ID клиента (или генерирует новый), кладёт его в контекст
+// для логов и возвращает в заголовке каждого ответа, в том числе с ошибкой, чтобы по нему
+// можно было найти строку лога сервера.
+func WithRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := logging.AcceptRequestID(r.Header.Get(logging.RequestIDHeader))
+		w.Header().Set(logging.RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), id)))
+	})
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"GophKeeper/internal/logging"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithRequestID(t *testing.T) {
+	var seen string
+	h := WithRequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = logging.RequestID(r.Context())
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}))
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{name: "клиентский id принимается", incoming: "cli-42", keep: true},
+		{name: "без заголовка — генерируется", incoming: ""},
+		{name: "с пробелами — генерируется", incoming: "bad id"},
+		{name: "слишком длинный — генерируется", incoming: strings.Repeat("a", 129)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/data", nil)
+			if tt.incoming != "" {
+				req.Header.Set(logging.RequestIDHeader, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			got := rec.Header().Get(logging.RequestIDHeader)
+			assert.NotEmpty(t, got, "id возвращается и в ответе с ошибкой")
+			assert.Equal(t, got, seen, "в контексте тот же id, что в ответе")
+			if tt.keep {
+				assert.Equal(t, tt.incoming, got)
+			} else {
+				assert.NotEqual(t, tt.incoming, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"GophKeeper/internal/logging"
+	"GophKeeper/internal/telemetry"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithTracing открывает серверный спан на каждый запрос, продолжая трассу клиента из
+// traceparent. Спан называется по шаблону маршрута chi, ответы 5xx отмечаются ошибкой.
+// Подключается после WithRequestID, чтобы спан и логи были связаны идентификатором запроса.
+func WithTracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := telemetry.Start(ctx, "HTTP "+r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.path", r.URL.Path),
+				attribute.String("request_id", logging.RequestID(ctx)),
+			))
+		defer span.End()
+
+		responseData := &responseData{}
+		next.ServeHTTP(&loggingResponseWriter{ResponseWriter: w, responseData: responseData}, r.WithContext(ctx))
+
+		if rc := chi.RouteContext(ctx); rc != nil && rc.RoutePattern() != "" {
+			span.SetName(r.Method + " " + rc.RoutePattern())
+			span.SetAttributes(attribute.String("http.route", rc.RoutePattern()))
+		}
+		status := responseData.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	})
+}
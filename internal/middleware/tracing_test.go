@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// recordSpans подключает провайдер трасс, запоминающий завершённые спаны, на время теста.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	rec := tracetest.NewSpanRecorder()
+	prevTP, prevProp := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevTP)
+		otel.SetTextMapPropagator(prevProp)
+	})
+	return rec
+}
+
+func TestWithTracing(t *testing.T) {
+	spans := recordSpans(t)
+
+	r := chi.NewRouter()
+	r.Use(WithTracing)
+	r.Get("/api/data/{id}", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	})
+	r.Get("/api/ok", func(w http.ResponseWriter, r *http.Request) {})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/data/abc", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/ok", nil))
+
+	ended := spans.Ended()
+	require.Len(t, ended, 2)
+
+	failed := ended[0]
+	assert.Equal(t, "GET /api/data/{id}", failed.Name(), "спан называется по шаблону маршрута")
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", failed.SpanContext().TraceID().String(), "трасса клиента продолжается")
+	assert.Equal(t, codes.Error, failed.Status().Code, "5xx отмечается ошибкой")
+
+	ok := ended[1]
+	assert.Equal(t, "GET /api/ok", ok.Name())
+	assert.NotEqual(t, codes.Error, ok.Status().Code)
+}
@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+// ValidationError — тело ответа 400, если запрос не соответствует контракту OpenAPI.
+type ValidationError struct {
+	Error   string            `json:"error"`
+	Details []ValidationIssue `json:"details"`
+}
+
+// ValidationIssue — одно нарушение: где (body, path, query, header, cookie), какое поле и что не так.
+type ValidationIssue struct {
+	In      string `json:"in"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// errNoUser — запрос к защищённой операции без действительного токена.
+var errNoUser = errors.New("unauthorized")
+
+// WithValidation проверяет запросы к описанным в doc операциям: параметры, JSON-тело
+// и требование авторизации (cookieAuth — user_id в контексте от WithAuth, поэтому
+// подключается после него). Нарушения контракта — 400 с ValidationError, без токена — 401.
+// Запросы к неописанным путям проходят дальше без проверки. Тела multipart/form-data
+// (загрузка блобов) не читаются: их размер и поля проверяет хендлер.
+// Паникует, если по документу нельзя построить маршрутизатор.
+func WithValidation(doc *openapi3.T) func(http.Handler) http.Handler {
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		panic(err)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, pathParams, err := router.FindRoute(r)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			// хендлеры читают тело как JSON и без Content-Type — проверяем так же
+			if r.ContentLength != 0 && r.Header.Get("Content-Type") == "" {
+				r.Header.Set("Content-Type", "application/json")
+			}
+			input := &openapi3filter.RequestValidationInput{
+				Request:    r,
+				PathParams: pathParams,
+				Route:      route,
+				Options: &openapi3filter.Options{
+					MultiError:         true,
+					ExcludeRequestBody: isMultipart(r),
+					AuthenticationFunc: func(ctx context.Context, in *openapi3filter.AuthenticationInput) error {
+						if _, ok := GetUserIDFromContext(ctx); !ok {
+							return errNoUser
+						}
+						return nil
+					},
+				},
+			}
+			if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+				writeValidationError(w, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func isMultipart(r *http.Request) bool {
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mt == "multipart/form-data"
+}
+
+// writeValidationError отвечает 401 на отсутствие авторизации, 413 на превышение лимита
+// распакованного тела и 400 со списком нарушений на остальное.
+func writeValidationError(w http.ResponseWriter, err error) {
+	if IsBodyTooLarge(err) {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	issues, unauthorized := collectIssues(err, nil)
+	if unauthorized {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(ValidationError{Error: "invalid_request", Details: issues})
+}
+
+// collectIssues раскрывает ошибки openapi3filter (в т.ч. MultiError) в плоский список нарушений.
+func collectIssues(err error, issues []ValidationIssue) ([]ValidationIssue, bool) {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		for _, sub := range e {
+			var unauthorized bool
+			if issues, unauthorized = collectIssues(sub, issues); unauthorized {
+				return nil, true
+			}
+		}
+		return issues, false
+	case *openapi3filter.SecurityRequirementsError:
+		return nil, true
+	case *openapi3filter.RequestError:
+		in, field := "body", ""
+		if e.Parameter != nil {
+			in, field = e.Parameter.In, e.Parameter.Name
+		}
+		if e.Err == nil {
+			return append(issues, ValidationIssue{In: in, Field: field, Message: e.Reason}), false
+		}
+		subs := []error{e.Err}
+		if multi, ok := e.Err.(openapi3.MultiError); ok {
+			subs = multi
+		}
+		for _, sub := range subs {
+			issue := ValidationIssue{In: in, Field: field, Message: sub.Error()}
+			var schemaErr *openapi3.SchemaError
+			if errors.As(sub, &schemaErr) {
+				issue.Message = schemaErr.Reason
+				if ptr := schemaErr.JSONPointer(); e.Parameter == nil && len(ptr) > 0 {
+					issue.Field = "/" + strings.Join(ptr, "/")
+				}
+			}
+			issues = append(issues, issue)
+		}
+		return issues, false
+	default:
+		return append(issues, ValidationIssue{In: "body", Message: err.Error()}), false
+	}
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"GophKeeper/internal/openapi"
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// validationChain — WithAuth + WithValidation; next запоминает прочитанное тело.
+func validationChain(t *testing.T) (http.Handler, *[]byte, *bool) {
+	t.Helper()
+	var body []byte
+	called := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	})
+	return WithAuth("s")(WithValidation(openapi.MustLoad())(next)), &body, &called
+}
+
+func authed(t *testing.T, req *http.Request) *http.Request {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	require.NoError(t, SetLoginCookie(rr, 1, "s"))
+	for _, c := range rr.Result().Cookies() {
+		req.AddCookie(c)
+	}
+	return req
+}
+
+func TestWithValidation_ValidRequestPassesBody(t *testing.T) {
+	h, body, called := validationChain(t)
+	payload := `{"cursor":"0","changes":[{"id":"a","version":0,"login_cipher":"AQI=","base_versions":{"login":1}}]}`
+	req := authed(t, httptest.NewRequest(http.MethodPost, "/api/items/sync", strings.NewReader(payload)))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.True(t, *called)
+	// тело без Content-Type проверено как JSON и дошло до хендлера целиком
+	assert.JSONEq(t, payload, string(*body))
+}
+
+func TestWithValidation_StructuredErrors(t *testing.T) {
+	h, _, called := validationChain(t)
+	req := httptest.NewRequest(http.MethodPost, "/api/user/register", strings.NewReader(`{"login":5}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.False(t, *called)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	var ve ValidationError
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &ve))
+	assert.Equal(t, "invalid_request", ve.Error)
+	require.Len(t, ve.Details, 2)
+	for _, d := range ve.Details {
+		assert.Equal(t, "body", d.In)
+		assert.NotEmpty(t, d.Message)
+	}
+	assert.Equal(t, "/login", ve.Details[0].Field)
+
+	// битый JSON — тоже структурированная ошибка
+	req = httptest.NewRequest(http.MethodPost, "/api/user/login", strings.NewReader(`{`))
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &ve))
+	assert.Len(t, ve.Details, 1)
+}
+
+func TestWithValidation_RequiresAuth(t *testing.T) {
+	h, _, called := validationChain(t)
+	req := httptest.NewRequest(http.MethodPost, "/api/items/sync", strings.NewReader(`{"resolve":"bad"}`))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	// без токена — 401 раньше проверки тела
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.False(t, *called)
+}
+
+func TestWithValidation_SkipsUndescribedAndMultipart(t *testing.T) {
+	h, body, called := validationChain(t)
+	req := httptest.NewRequest(http.MethodGet, "/unknown", nil)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.True(t, *called)
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	_ = mw.WriteField("id", "b1")
+	_ = mw.Close()
+	req = authed(t, httptest.NewRequest(http.MethodPost, "/api/blobs/upload", bytes.NewReader(buf.Bytes())))
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rr = httptest.NewRecorder()
+	*called = false
+	h.ServeHTTP(rr, req)
+	// форму без cipher проверяет хендлер; тело не прочитано middleware
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.True(t, *called)
+	assert.Equal(t, buf.Bytes(), *body)
+}
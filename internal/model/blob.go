@@ -1,9 +1,15 @@
 package model
 
-// Серверная модель Blob — бинарное содержимое.
+// Серверная модель Blob — метаданные бинарного содержимого.
+// Сами байты хранятся в repo.BlobStore (файловая система или S3).
 type Blob struct {
-	ID string `gorm:"primaryKey;type:uuid"`
+	ID     string `gorm:"primaryKey;type:uuid"`
+	UserID int64  `gorm:"not null;default:0;index"` // владелец (для учёта квот)
 
-	Cipher []byte `gorm:"not null"`
-	Nonce  []byte `gorm:"not null"`
+	Nonce []byte `gorm:"not null"`
+	Size  int64  `gorm:"not null;default:0"` // размер шифртекста в байтах
+
+	// Cipher — содержимое в устаревшем формате (bytea в БД).
+	// Новые блобы сюда не пишутся; старые переносятся командой migrate-blobs.
+	Cipher []byte
 }
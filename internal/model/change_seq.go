@@ -0,0 +1,7 @@
+package model
+
+// UserChangeSeq — последний выданный номер изменения пользователя.
+type UserChangeSeq struct {
+	UserID int64 `gorm:"primaryKey;autoIncrement:false"`
+	Seq    int64 `gorm:"not null;default:0"`
+}
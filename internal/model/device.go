@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// Device — устройство пользователя, подтверждённое клиентским сертификатом (mTLS).
+// Отпечаток сертификата принадлежит ровно одному пользователю.
+type Device struct {
+	ID          int64  `gorm:"primaryKey;autoIncrement"`
+	UserID      int64  `gorm:"not null;index"`
+	Fingerprint string `gorm:"not null;uniqueIndex"` // SHA-256 сертификата, base64url (x5t#S256)
+	Name        string `gorm:"not null"`             // CommonName сертификата
+
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	LastSeenAt time.Time // последний вход с этого устройства
+}
@@ -0,0 +1,61 @@
+package model
+
+import "time"
+
+// Состояния экстренного доступа.
+const (
+	// EmergencyGranted — контакт назначен, запроса нет.
+	EmergencyGranted = "granted"
+	// EmergencyRequested — контакт запросил доступ, идёт период ожидания.
+	EmergencyRequested = "requested"
+	// EmergencyApproved — доступ открыт: владелец одобрил запрос или период ожидания истёк.
+	EmergencyApproved = "approved"
+)
+
+// EmergencyAccess — доверенный контакт владельца. Ключ хранилища владельца запечатан
+// открытым ключом контакта (эфемерный X25519 + AES-GCM) и выдаётся ему только в
+// состоянии EmergencyApproved.
+type EmergencyAccess struct {
+	OwnerID   int64 `gorm:"primaryKey;autoIncrement:false"`
+	ContactID int64 `gorm:"primaryKey;autoIncrement:false;index"`
+
+	// WaitDays — сколько дней после запроса владелец может его отклонить.
+	WaitDays int    `gorm:"not null"`
+	Status   string `gorm:"not null"`
+	// RequestedAt — время запроса доступа; nil — запроса нет.
+	RequestedAt *time.Time
+
+	EphemeralKey    []byte `gorm:"not null"`
+	WrappedKey      []byte `gorm:"not null"`
+	WrappedKeyNonce []byte `gorm:"not null"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// AvailableAt — когда запрос будет одобрен автоматически; nil — запроса нет.
+func (e *EmergencyAccess) AvailableAt() *time.Time {
+	if e.RequestedAt == nil {
+		return nil
+	}
+	t := e.RequestedAt.Add(time.Duration(e.WaitDays) * 24 * time.Hour)
+	return &t
+}
+
+// EffectiveStatus — состояние на момент now: запрос, который владелец не отклонил
+// за период ожидания, считается одобренным.
+func (e *EmergencyAccess) EffectiveStatus(now time.Time) string {
+	if e.Status == EmergencyRequested {
+		if at := e.AvailableAt(); at != nil && !now.Before(*at) {
+			return EmergencyApproved
+		}
+	}
+	return e.Status
+}
+
+// EmergencyContact — экстренный доступ вместе с логинами владельца и контакта.
+type EmergencyContact struct {
+	EmergencyAccess
+	OwnerLogin   string
+	ContactLogin string
+}
@@ -0,0 +1,149 @@
+package model
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// Группы полей Item, для которых отслеживается версия последнего изменения.
+// Шифртекст и nonce меняются вместе, file_name и blob_id — тоже.
+const (
+	FieldName     = "name"
+	FieldFile     = "file"
+	FieldLogin    = "login"
+	FieldPassword = "password"
+	FieldText     = "text"
+	FieldCard     = "card"
+	FieldDeleted  = "deleted"
+)
+
+// FieldGroups — все отслеживаемые группы полей.
+var FieldGroups = []string{FieldName, FieldFile, FieldLogin, FieldPassword, FieldText, FieldCard, FieldDeleted}
+
+var columnFields = map[string]string{
+	"name":            FieldName,
+	"file_name":       FieldFile,
+	"blob_id":         FieldFile,
+	"login_cipher":    FieldLogin,
+	"login_nonce":     FieldLogin,
+	"password_cipher": FieldPassword,
+	"password_nonce":  FieldPassword,
+	"text_cipher":     FieldText,
+	"text_nonce":      FieldText,
+	"card_cipher":     FieldCard,
+	"card_nonce":      FieldCard,
+	"deleted":         FieldDeleted,
+}
+
+// FieldOfColumn возвращает группу поля для столбца items или "", если столбец не отслеживается.
+func FieldOfColumn(col string) string { return columnFields[col] }
+
+// FieldVersions — для каждой группы полей версия элемента, в которой она менялась последний раз.
+// Хранится в БД как JSON.
+type FieldVersions map[string]int64
+
+// Value реализует driver.Valuer.
+func (fv FieldVersions) Value() (driver.Value, error) {
+	if fv == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(map[string]int64(fv))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan реализует sql.Scanner.
+func (fv *FieldVersions) Scan(src any) error {
+	var raw []byte
+	switch v := src.(type) {
+	case nil:
+		*fv = nil
+		return nil
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	default:
+		return fmt.Errorf("field versions: unsupported type %T", src)
+	}
+	if len(raw) == 0 {
+		*fv = nil
+		return nil
+	}
+	m := map[string]int64{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return err
+	}
+	*fv = m
+	return nil
+}
+
+// FieldVersion возвращает версию последнего изменения группы полей.
+// Для записей, созданных до отслеживания полей, группа считается изменённой
+// в текущей версии — так слияние никогда не затрёт неизвестное изменение.
+func (it *Item) FieldVersion(field string) int64 {
+	if v, ok := it.FieldVersions[field]; ok {
+		return v
+	}
+	return it.Version
+}
+
+// ColumnEquals сообщает, совпадает ли текущее значение столбца с val
+// (значение в формате карты обновлений gorm).
+func (it *Item) ColumnEquals(col string, val any) bool {
+	var cur any
+	switch col {
+	case "name":
+		cur = it.Name
+	case "file_name":
+		cur = it.FileName
+	case "blob_id":
+		cur = it.BlobID
+	case "deleted":
+		cur = it.Deleted
+	case "login_cipher":
+		cur = it.LoginCipher
+	case "login_nonce":
+		cur = it.LoginNonce
+	case "password_cipher":
+		cur = it.PasswordCipher
+	case "password_nonce":
+		cur = it.PasswordNonce
+	case "text_cipher":
+		cur = it.TextCipher
+	case "text_nonce":
+		cur = it.TextNonce
+	case "card_cipher":
+		cur = it.CardCipher
+	case "card_nonce":
+		cur = it.CardNonce
+	default:
+		return false
+	}
+	return bytes.Equal(columnBytes(cur), columnBytes(val)) && columnBool(cur) == columnBool(val)
+}
+
+// columnBytes приводит значение столбца к байтам; nil, пустая строка и пустой срез равны.
+func columnBytes(v any) []byte {
+	switch x := v.(type) {
+	case string:
+		return []byte(x)
+	case *string:
+		if x == nil {
+			return nil
+		}
+		return []byte(*x)
+	case []byte:
+		return x
+	}
+	return nil
+}
+
+func columnBool(v any) bool {
+	b, _ := v.(bool)
+	return b
+}
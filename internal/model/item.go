@@ -18,6 +18,13 @@ type Item struct {
 	Version int64 `gorm:"not null;default:1"`
 	Deleted bool  `gorm:"not null;default:false"`
 
+	// ChangeSeq — номер изменения в пределах пользователя; растёт монотонно
+	// и назначается в той же транзакции, что и запись (курсор синхронизации).
+	ChangeSeq int64 `gorm:"not null;default:0;index"`
+
+	// FieldVersions — версии последнего изменения групп полей (для слияния в Sync).
+	FieldVersions FieldVersions `gorm:"type:text"`
+
 	LoginCipher    []byte
 	LoginNonce     []byte
 	PasswordCipher []byte
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// ItemVersion — снимок предыдущего состояния Item, сохраняемый при каждом обновлении.
+// (ItemID, Version) уникальны: версия снимка — версия элемента до изменения.
+type ItemVersion struct {
+	ID     int64  `gorm:"primaryKey;autoIncrement"`
+	ItemID string `gorm:"not null;type:uuid;uniqueIndex:idx_item_versions_item_version"`
+	UserID int64  `gorm:"not null;index"`
+
+	Version int64 `gorm:"not null;uniqueIndex:idx_item_versions_item_version"`
+	Deleted bool  `gorm:"not null;default:false"`
+
+	Name     string `gorm:"not null"`
+	FileName string
+	BlobID   *string `gorm:"type:uuid"`
+
+	LoginCipher    []byte
+	LoginNonce     []byte
+	PasswordCipher []byte
+	PasswordNonce  []byte
+	TextCipher     []byte
+	TextNonce      []byte
+	CardCipher     []byte
+	CardNonce      []byte
+
+	ItemUpdatedAt time.Time // когда была записана эта версия элемента
+	CreatedAt     time.Time `gorm:"autoCreateTime"` // когда версия была вытеснена новой
+}
@@ -0,0 +1,95 @@
+package model
+
+import "time"
+
+// Роли участников организации (от старшей к младшей).
+const (
+	RoleOwner    = "owner"
+	RoleAdmin    = "admin"
+	RoleMember   = "member"
+	RoleReadOnly = "read-only"
+)
+
+// Organization — организация: общие коллекции записей её участников.
+type Organization struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement"`
+	Name      string    `gorm:"not null;uniqueIndex"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// OrgMember — участник организации и его роль.
+type OrgMember struct {
+	OrgID     int64     `gorm:"primaryKey;autoIncrement:false"`
+	UserID    int64     `gorm:"primaryKey;autoIncrement:false;index"`
+	Role      string    `gorm:"not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// Collection — коллекция записей организации. Записи зашифрованы ключом коллекции,
+// который хранится только запечатанным для каждого участника (CollectionKey).
+type Collection struct {
+	ID        string    `gorm:"type:uuid;primaryKey"`
+	OrgID     int64     `gorm:"not null;uniqueIndex:idx_collections_org_name"`
+	Name      string    `gorm:"not null;uniqueIndex:idx_collections_org_name"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// CollectionKey — ключ коллекции, запечатанный открытым ключом X25519 участника.
+type CollectionKey struct {
+	CollectionID    string `gorm:"type:uuid;primaryKey"`
+	UserID          int64  `gorm:"primaryKey;autoIncrement:false;index"`
+	EphemeralKey    []byte `gorm:"not null"`
+	WrappedKey      []byte `gorm:"not null"`
+	WrappedKeyNonce []byte `gorm:"not null"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// CollectionItem — запись коллекции; поля зашифрованы ключом коллекции.
+type CollectionItem struct {
+	ID           string `gorm:"type:uuid;primaryKey"`
+	CollectionID string `gorm:"type:uuid;not null;index"`
+	Name         string `gorm:"not null"`
+	Version      int64  `gorm:"not null"`
+	Deleted      bool   `gorm:"not null;default:false"`
+
+	LoginCipher    []byte
+	LoginNonce     []byte
+	PasswordCipher []byte
+	PasswordNonce  []byte
+	TextCipher     []byte
+	TextNonce      []byte
+	CardCipher     []byte
+	CardNonce      []byte
+
+	// UpdatedBy — пользователь, записавший текущую версию.
+	UpdatedBy int64     `gorm:"not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// OrgMembership — организация пользователя с его ролью и коллекциями.
+type OrgMembership struct {
+	Organization
+	Role        string
+	Collections []Collection
+}
+
+// OrgMemberInfo — участник организации для списка участников.
+type OrgMemberInfo struct {
+	UserID    int64
+	Login     string
+	Role      string
+	PublicKey []byte // пусто — пользователь не опубликовал ключ
+}
+
+// CollectionSnapshot — доступная пользователю коллекция: его запечатанный ключ и все записи.
+type CollectionSnapshot struct {
+	Collection
+	OrgName string
+	Role    string
+	Key     CollectionKey
+	Items   []CollectionItem
+}
@@ -0,0 +1,62 @@
+package model
+
+import "time"
+
+// UserKeys — пара ключей X25519 пользователя для обмена записями. Открытый ключ
+// публикуется, закрытый хранится только зашифрованным ключом хранилища клиента.
+type UserKeys struct {
+	UserID            int64  `gorm:"primaryKey;autoIncrement:false"`
+	PublicKey         []byte `gorm:"not null"`
+	WrappedPrivateKey []byte `gorm:"not null"`
+	PrivateKeyNonce   []byte `gorm:"not null"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// ItemShare — запись, открытая владельцем другому пользователю. Поля зашифрованы
+// отдельным ключом записи, который запечатан открытым ключом получателя
+// (эфемерный X25519 + AES-GCM); сервер не может их прочитать.
+type ItemShare struct {
+	ID          int64  `gorm:"primaryKey;autoIncrement"`
+	ItemID      string `gorm:"type:uuid;not null;uniqueIndex:idx_item_shares_item_recipient"`
+	OwnerID     int64  `gorm:"not null;index"`
+	RecipientID int64  `gorm:"not null;uniqueIndex:idx_item_shares_item_recipient;index"`
+
+	// Version — версия записи владельца, из которой зашифрованы поля.
+	Version int64 `gorm:"not null"`
+
+	EphemeralKey    []byte `gorm:"not null"`
+	WrappedKey      []byte `gorm:"not null"`
+	WrappedKeyNonce []byte `gorm:"not null"`
+
+	LoginCipher    []byte
+	LoginNonce     []byte
+	PasswordCipher []byte
+	PasswordNonce  []byte
+	TextCipher     []byte
+	TextNonce      []byte
+	CardCipher     []byte
+	CardNonce      []byte
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// IncomingShare — запись, открытая пользователю: доля вместе с именем записи и логином владельца.
+type IncomingShare struct {
+	ItemShare
+	Name        string
+	OwnerLogin  string
+	ItemVersion int64 // текущая версия записи владельца; больше Version — доля ещё не обновлена
+}
+
+// OutgoingShare — доля, выданная пользователем: кому и из какой версии записи.
+type OutgoingShare struct {
+	ItemID             string
+	Name               string
+	RecipientLogin     string
+	RecipientPublicKey []byte
+	Version            int64
+	ItemVersion        int64
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// UserState — административное состояние пользователя (gkserver admin).
+// Нет строки — пользователь активен и его токены не отзывались.
+type UserState struct {
+	UserID   int64 `gorm:"primaryKey;autoIncrement:false"`
+	Disabled bool  `gorm:"not null;default:false"`
+	// SessionsRevokedAt — токены, выданные не позже этого момента, недействительны.
+	SessionsRevokedAt *time.Time
+	UpdatedAt         time.Time
+}
+
+// SessionActive сообщает, действителен ли токен пользователя, выданный в issuedAt.
+// Время выдачи в JWT хранится с точностью до микросекунды, поэтому токен, полученный
+// после отзыва в ту же секунду, действителен. У токенов со временем выдачи в целых
+// секундах (выданных раньше) оно округлено вниз: выданные в секунду отзыва отклоняются.
+func (s *UserState) SessionActive(issuedAt time.Time) bool {
+	if s.Disabled {
+		return false
+	}
+	return s.SessionsRevokedAt == nil || issuedAt.After(*s.SessionsRevokedAt)
+}
@@ -0,0 +1,168 @@
+// Package openapi — опубликованный контракт HTTP/JSON API (OpenAPI 3), общий для сервера и клиента.
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// JSON — документ OpenAPI, который сервер отдаёт на GET /api/openapi.json.
+//
+//go:embed openapi.json
+var JSON []byte
+
+var loadOnce = sync.OnceValues(func() (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(JSON)
+	if err != nil {
+		return nil, fmt.Errorf("load openapi: %w", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("validate openapi: %w", err)
+	}
+	return doc, nil
+})
+
+// Load разбирает и проверяет встроенный документ (один раз на процесс).
+func Load() (*openapi3.T, error) {
+	return loadOnce()
+}
+
+// MustLoad как Load, но паникует: документ встроен в бинарник и проверяется тестами.
+func MustLoad() *openapi3.T {
+	doc, err := Load()
+	if err != nil {
+		panic(err)
+	}
+	return doc
+}
+
+// DTODiff — расхождения Go-типа DTO со схемой из components.schemas.
+// Поля записываются путями вида changes[].base_versions.
+type DTODiff struct {
+	Unknown    []string // поля DTO, которых нет в схеме
+	Missing    []string // свойства схемы, которых нет в DTO
+	Mismatched []string // поля с несовместимыми типами
+}
+
+// Diff сравнивает JSON-представление типа dto (по тегам json) со схемой components.schemas[name].
+func Diff(name string, dto any) (DTODiff, error) {
+	doc, err := Load()
+	if err != nil {
+		return DTODiff{}, err
+	}
+	ref, ok := doc.Components.Schemas[name]
+	if !ok || ref.Value == nil {
+		return DTODiff{}, fmt.Errorf("schema %q not found", name)
+	}
+	var d DTODiff
+	d.compare("", reflect.TypeOf(dto), ref.Value)
+	sort.Strings(d.Unknown)
+	sort.Strings(d.Missing)
+	sort.Strings(d.Mismatched)
+	return d, nil
+}
+
+func (d *DTODiff) compare(path string, t reflect.Type, s *openapi3.Schema) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t.Kind() == reflect.Interface:
+		// any допускает любое значение схемы
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		d.expect(path, s, "string", "byte")
+	case t.Kind() == reflect.Slice:
+		if d.expect(path, s, "array", "") && s.Items != nil {
+			d.compare(path+"[]", t.Elem(), s.Items.Value)
+		}
+	case t.Kind() == reflect.Map:
+		if d.expect(path, s, "object", "") && s.AdditionalProperties.Schema != nil {
+			d.compare(path+"{}", t.Elem(), s.AdditionalProperties.Schema.Value)
+		}
+	case t.Kind() == reflect.Struct:
+		if !d.expect(path, s, "object", "") {
+			return
+		}
+		seen := map[string]bool{}
+		d.fields(path, t, s, seen)
+		for prop := range s.Properties {
+			if !seen[prop] {
+				d.Missing = append(d.Missing, join(path, prop))
+			}
+		}
+	case t.Kind() == reflect.String:
+		d.expect(path, s, "string", "")
+	case t.Kind() == reflect.Bool:
+		d.expect(path, s, "boolean", "")
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		d.expect(path, s, "integer", "")
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		if !s.Type.Is("number") && !s.Type.Is("integer") {
+			d.Mismatched = append(d.Mismatched, fmt.Sprintf("%s: Go type needs number, schema has %s", path, strings.Join(s.Type.Slice(), "|")))
+		}
+	default:
+		d.Mismatched = append(d.Mismatched, fmt.Sprintf("%s: unsupported Go type %s", path, t))
+	}
+}
+
+// fields обходит поля структуры (со встроенными структурами) так же, как encoding/json.
+func (d *DTODiff) fields(path string, t reflect.Type, s *openapi3.Schema, seen map[string]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				d.fields(path, ft, s, seen)
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+		seen[name] = true
+		prop, ok := s.Properties[name]
+		if !ok || prop.Value == nil {
+			d.Unknown = append(d.Unknown, join(path, name))
+			continue
+		}
+		d.compare(join(path, name), f.Type, prop.Value)
+	}
+}
+
+// expect проверяет тип (и формат, если задан) схемы; false — типы не совпали.
+func (d *DTODiff) expect(path string, s *openapi3.Schema, typ, format string) bool {
+	if !s.Type.Is(typ) || (format != "" && s.Format != format) {
+		d.Mismatched = append(d.Mismatched, fmt.Sprintf("%s: Go type needs %s, schema has %s", path, typeName(typ, format), typeName(strings.Join(s.Type.Slice(), "|"), s.Format)))
+		return false
+	}
+	return true
+}
+
+func typeName(typ, format string) string {
+	if format == "" {
+		return typ
+	}
+	return typ + "(" + format + ")"
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,60 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	doc, err := Load()
+	require.NoError(t, err)
+	assert.NotNil(t, doc.Paths.Find("/api/items/sync"))
+	assert.Contains(t, doc.Components.Schemas, "SyncRequest")
+}
+
+type diffInner struct {
+	ID     string `json:"id"`
+	Reason int    `json:"reason"` // в схеме string
+}
+
+type diffBase struct {
+	Applied []struct {
+		ID         string `json:"id"`
+		NewVersion int64  `json:"new_version"`
+	} `json:"applied"`
+}
+
+type diffDTO struct {
+	diffBase
+	Conflicts     []diffInner `json:"conflicts"`
+	ServerChanges []any       `json:"server_changes"`
+	Cursor        *string     `json:"cursor,omitempty"`
+	HasMore       bool        `json:"has_more"`
+	Extra         int         `json:"extra"`
+	ignored       int
+	Skipped       string `json:"-"`
+}
+
+func TestDiff(t *testing.T) {
+	d, err := Diff("SyncResponse", diffDTO{ignored: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"extra"}, d.Unknown)
+	assert.Equal(t, []string{"collections", "conflicts[].fields", "conflicts[].server_item", "server_time"}, d.Missing)
+	require.Len(t, d.Mismatched, 1)
+	assert.Contains(t, d.Mismatched[0], "conflicts[].reason")
+
+	d, err = Diff("ItemChange", struct {
+		ID           string           `json:"id"`
+		LoginCipher  []byte           `json:"login_cipher"`
+		BaseVersions map[string]int64 `json:"base_versions"`
+		Name         []string         `json:"name"`
+	}{})
+	require.NoError(t, err)
+	assert.Empty(t, d.Unknown)
+	assert.Equal(t, []string{"name: Go type needs array, schema has string"}, d.Mismatched)
+
+	_, err = Diff("NoSuchSchema", struct{}{})
+	assert.Error(t, err)
+}
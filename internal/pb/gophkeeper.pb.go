@@ -0,0 +1,1433 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        v5.29.3
+// source: gophkeeper.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Credentials struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Login         string                 `protobuf:"bytes,1,opt,name=login,proto3" json:"login,omitempty"`
+	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Credentials) Reset() {
+	*x = Credentials{}
+	mi := &file_gophkeeper_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Credentials) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Credentials) ProtoMessage() {}
+
+func (x *Credentials) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Credentials.ProtoReflect.Descriptor instead.
+func (*Credentials) Descriptor() ([]byte, []int) {
+	return file_gophkeeper_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Credentials) GetLogin() string {
+	if x != nil {
+		return x.Login
+	}
+	return ""
+}
+
+func (x *Credentials) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type AuthResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuthResponse) Reset() {
+	*x = AuthResponse{}
+	mi := &file_gophkeeper_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuthResponse) ProtoMessage() {}
+
+func (x *AuthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuthResponse.ProtoReflect.Descriptor instead.
+func (*AuthResponse) Descriptor() ([]byte, []int) {
+	return file_gophkeeper_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AuthResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type Item struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Version        int64                  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	Deleted        bool                   `protobuf:"varint,3,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	UpdatedAt      *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Name           string                 `protobuf:"bytes,5,opt,name=name,proto3" json:"name,omitempty"`
+	FileName       string                 `protobuf:"bytes,6,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	BlobId         string                 `protobuf:"bytes,7,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
+	LoginCipher    []byte                 `protobuf:"bytes,8,opt,name=login_cipher,json=loginCipher,proto3" json:"login_cipher,omitempty"`
+	LoginNonce     []byte                 `protobuf:"bytes,9,opt,name=login_nonce,json=loginNonce,proto3" json:"login_nonce,omitempty"`
+	PasswordCipher []byte                 `protobuf:"bytes,10,opt,name=password_cipher,json=passwordCipher,proto3" json:"password_cipher,omitempty"`
+	PasswordNonce  []byte                 `protobuf:"bytes,11,opt,name=password_nonce,json=passwordNonce,proto3" json:"password_nonce,omitempty"`
+	TextCipher     []byte                 `protobuf:"bytes,12,opt,name=text_cipher,json=textCipher,proto3" json:"text_cipher,omitempty"`
+	TextNonce      []byte                 `protobuf:"bytes,13,opt,name=text_nonce,json=textNonce,proto3" json:"text_nonce,omitempty"`
+	CardCipher     []byte                 `protobuf:"bytes,14,opt,name=card_cipher,json=cardCipher,proto3" json:"card_cipher,omitempty"`
+	CardNonce      []byte                 `protobuf:"bytes,15,opt,name=card_nonce,json=cardNonce,proto3" json:"card_nonce,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Item) Reset() {
+	*x = Item{}
+	mi := &file_gophkeeper_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Item) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Item) ProtoMessage() {}
+
+func (x *Item) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Item.ProtoReflect.Descriptor instead.
+func (*Item) Descriptor() ([]byte, []int) {
+	return file_gophkeeper_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Item) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Item) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Item) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+func (x *Item) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Item) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Item) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *Item) GetBlobId() string {
+	if x != nil {
+		return x.BlobId
+	}
+	return ""
+}
+
+func (x *Item) GetLoginCipher() []byte {
+	if x != nil {
+		return x.LoginCipher
+	}
+	return nil
+}
+
+func (x *Item) GetLoginNonce() []byte {
+	if x != nil {
+		return x.LoginNonce
+	}
+	return nil
+}
+
+func (x *Item) GetPasswordCipher() []byte {
+	if x != nil {
+		return x.PasswordCipher
+	}
+	return nil
+}
+
+func (x *Item) GetPasswordNonce() []byte {
+	if x != nil {
+		return x.PasswordNonce
+	}
+	return nil
+}
+
+func (x *Item) GetTextCipher() []byte {
+	if x != nil {
+		return x.TextCipher
+	}
+	return nil
+}
+
+func (x *Item) GetTextNonce() []byte {
+	if x != nil {
+		return x.TextNonce
+	}
+	return nil
+}
+
+func (x *Item) GetCardCipher() []byte {
+	if x != nil {
+		return x.CardCipher
+	}
+	return nil
+}
+
+func (x *Item) GetCardNonce() []byte {
+	if x != nil {
+		return x.CardNonce
+	}
+	return nil
+}
+
+type ItemChange struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Version        *int64                 `protobuf:"varint,2,opt,name=version,proto3,oneof" json:"version,omitempty"`
+	Deleted        *bool                  `protobuf:"varint,3,opt,name=deleted,proto3,oneof" json:"deleted,omitempty"`
+	Name           *string                `protobuf:"bytes,4,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	FileName       *string                `protobuf:"bytes,5,opt,name=file_name,json=fileName,proto3,oneof" json:"file_name,omitempty"`
+	BlobId         *string                `protobuf:"bytes,6,opt,name=blob_id,json=blobId,proto3,oneof" json:"blob_id,omitempty"`
+	LoginCipher    []byte                 `protobuf:"bytes,7,opt,name=login_cipher,json=loginCipher,proto3" json:"login_cipher,omitempty"`
+	LoginNonce     []byte                 `protobuf:"bytes,8,opt,name=login_nonce,json=loginNonce,proto3" json:"login_nonce,omitempty"`
+	PasswordCipher []byte                 `protobuf:"bytes,9,opt,name=password_cipher,json=passwordCipher,proto3" json:"password_cipher,omitempty"`
+	PasswordNonce  []byte                 `protobuf:"bytes,10,opt,name=password_nonce,json=passwordNonce,proto3" json:"password_nonce,omitempty"`
+	TextCipher     []byte                 `protobuf:"bytes,11,opt,name=text_cipher,json=textCipher,proto3" json:"text_cipher,omitempty"`
+	TextNonce      []byte                 `protobuf:"bytes,12,opt,name=text_nonce,json=textNonce,proto3" json:"text_nonce,omitempty"`
+	CardCipher     []byte                 `protobuf:"bytes,13,opt,name=card_cipher,json=cardCipher,proto3" json:"card_cipher,omitempty"`
+	CardNonce      []byte                 `protobuf:"bytes,14,opt,name=card_nonce,json=cardNonce,proto3" json:"card_nonce,omitempty"`
+	BaseVersions   map[string]int64       `protobuf:"bytes,15,rep,name=base_versions,json=baseVersions,proto3" json:"base_versions,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ItemChange) Reset() {
+	*x = ItemChange{}
+	mi := &file_gophkeeper_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ItemChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ItemChange) ProtoMessage() {}
+
+func (x *ItemChange) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ItemChange.ProtoReflect.Descriptor instead.
+func (*ItemChange) Descriptor() ([]byte, []int) {
+	return file_gophkeeper_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ItemChange) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ItemChange) GetVersion() int64 {
+	if x != nil && x.Version != nil {
+		return *x.Version
+	}
+	return 0
+}
+
+func (x *ItemChange) GetDeleted() bool {
+	if x != nil && x.Deleted != nil {
+		return *x.Deleted
+	}
+	return false
+}
+
+func (x *ItemChange) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *ItemChange) GetFileName() string {
+	if x != nil && x.FileName != nil {
+		return *x.FileName
+	}
+	return ""
+}
+
+func (x *ItemChange) GetBlobId() string {
+	if x != nil && x.BlobId != nil {
+		return *x.BlobId
+	}
+	return ""
+}
+
+func (x *ItemChange) GetLoginCipher() []byte {
+	if x != nil {
+		return x.LoginCipher
+	}
+	return nil
+}
+
+func (x *ItemChange) GetLoginNonce() []byte {
+	if x != nil {
+		return x.LoginNonce
+	}
+	return nil
+}
+
+func (x *ItemChange) GetPasswordCipher() []byte {
+	if x != nil {
+		return x.PasswordCipher
+	}
+	return nil
+}
+
+func (x *ItemChange) GetPasswordNonce() []byte {
+	if x != nil {
+		return x.PasswordNonce
+	}
+	return nil
+}
+
+func (x *ItemChange) GetTextCipher() []byte {
+	if x != nil {
+		return x.TextCipher
+	}
+	return nil
+}
+
+func (x *ItemChange) GetTextNonce() []byte {
+	if x != nil {
+		return x.TextNonce
+	}
+	return nil
+}
+
+func (x *ItemChange) GetCardCipher() []byte {
+	if x != nil {
+		return x.CardCipher
+	}
+	return nil
+}
+
+func (x *ItemChange) GetCardNonce() []byte {
+	if x != nil {
+		return x.CardNonce
+	}
+	return nil
+}
+
+func (x *ItemChange) GetBaseVersions() map[string]int64 {
+	if x != nil {
+		return x.BaseVersions
+	}
+	return nil
+}
+
+type SyncRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cursor        string                 `protobuf:"bytes,1,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Changes       []*ItemChange          `protobuf:"bytes,3,rep,name=changes,proto3" json:"changes,omitempty"`
+	Resolve       string                 `protobuf:"bytes,4,opt,name=resolve,proto3" json:"resolve,omitempty"`
+	Resolutions   map[string]string      `protobuf:"bytes,5,rep,name=resolutions,proto3" json:"resolutions,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Atomic        bool                   `protobuf:"varint,6,opt,name=atomic,proto3" json:"atomic,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncRequest) Reset() {
+	*x = SyncRequest{}
+	mi := &file_gophkeeper_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncRequest) ProtoMessage() {}
+
+func (x *SyncRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncRequest.ProtoReflect.Descriptor instead.
+func (*SyncRequest) Descriptor() ([]byte, []int) {
+	return file_gophkeeper_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SyncRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *SyncRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *SyncRequest) GetChanges() []*ItemChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *SyncRequest) GetResolve() string {
+	if x != nil {
+		return x.Resolve
+	}
+	return ""
+}
+
+func (x *SyncRequest) GetResolutions() map[string]string {
+	if x != nil {
+		return x.Resolutions
+	}
+	return nil
+}
+
+func (x *SyncRequest) GetAtomic() bool {
+	if x != nil {
+		return x.Atomic
+	}
+	return false
+}
+
+type Applied struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	NewVersion    int64                  `protobuf:"varint,2,opt,name=new_version,json=newVersion,proto3" json:"new_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Applied) Reset() {
+	*x = Applied{}
+	mi := &file_gophkeeper_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Applied) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Applied) ProtoMessage() {}
+
+func (x *Applied) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Applied.ProtoReflect.Descriptor instead.
+func (*Applied) Descriptor() ([]byte, []int) {
+	return file_gophkeeper_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Applied) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Applied) GetNewVersion() int64 {
+	if x != nil {
+		return x.NewVersion
+	}
+	return 0
+}
+
+type Conflict struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	ServerItem    *Item                  `protobuf:"bytes,3,opt,name=server_item,json=serverItem,proto3" json:"server_item,omitempty"`
+	Fields        []string               `protobuf:"bytes,4,rep,name=fields,proto3" json:"fields,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Conflict) Reset() {
+	*x = Conflict{}
+	mi := &file_gophkeeper_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Conflict) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Conflict) ProtoMessage() {}
+
+func (x *Conflict) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Conflict.ProtoReflect.Descriptor instead.
+func (*Conflict) Descriptor() ([]byte, []int) {
+	return file_gophkeeper_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Conflict) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Conflict) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Conflict) GetServerItem() *Item {
+	if x != nil {
+		return x.ServerItem
+	}
+	return nil
+}
+
+func (x *Conflict) GetFields() []string {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+type SyncResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Applied        []*Applied             `protobuf:"bytes,1,rep,name=applied,proto3" json:"applied,omitempty"`
+	Conflicts      []*Conflict            `protobuf:"bytes,2,rep,name=conflicts,proto3" json:"conflicts,omitempty"`
+	ServerChanges  []*Item                `protobuf:"bytes,3,rep,name=server_changes,json=serverChanges,proto3" json:"server_changes,omitempty"`
+	Cursor         string                 `protobuf:"bytes,4,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	HasMore        bool                   `protobuf:"varint,5,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
+	ServerTime     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=server_time,json=serverTime,proto3" json:"server_time,omitempty"`
+	Collections    []*Collection          `protobuf:"bytes,7,rep,name=collections,proto3" json:"collections,omitempty"`
+	HasCollections bool                   `protobuf:"varint,8,opt,name=has_collections,json=hasCollections,proto3" json:"has_collections,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SyncResponse) Reset() {
+	*x = SyncResponse{}
+	mi := &file_gophkeeper_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncResponse) ProtoMessage() {}
+
+func (x *SyncResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncResponse.ProtoReflect.Descriptor instead.
+func (*SyncResponse) Descriptor() ([]byte, []int) {
+	return file_gophkeeper_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SyncResponse) GetApplied() []*Applied {
+	if x != nil {
+		return x.Applied
+	}
+	return nil
+}
+
+func (x *SyncResponse) GetConflicts() []*Conflict {
+	if x != nil {
+		return x.Conflicts
+	}
+	return nil
+}
+
+func (x *SyncResponse) GetServerChanges() []*Item {
+	if x != nil {
+		return x.ServerChanges
+	}
+	return nil
+}
+
+func (x *SyncResponse) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *SyncResponse) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+func (x *SyncResponse) GetServerTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ServerTime
+	}
+	return nil
+}
+
+func (x *SyncResponse) GetCollections() []*Collection {
+	if x != nil {
+		return x.Collections
+	}
+	return nil
+}
+
+func (x *SyncResponse) GetHasCollections() bool {
+	if x != nil {
+		return x.HasCollections
+	}
+	return false
+}
+
+type Collection struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name            string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Org             string                 `protobuf:"bytes,3,opt,name=org,proto3" json:"org,omitempty"`
+	Role            string                 `protobuf:"bytes,4,opt,name=role,proto3" json:"role,omitempty"`
+	EphemeralKey    []byte                 `protobuf:"bytes,5,opt,name=ephemeral_key,json=ephemeralKey,proto3" json:"ephemeral_key,omitempty"`
+	WrappedKey      []byte                 `protobuf:"bytes,6,opt,name=wrapped_key,json=wrappedKey,proto3" json:"wrapped_key,omitempty"`
+	WrappedKeyNonce []byte                 `protobuf:"bytes,7,opt,name=wrapped_key_nonce,json=wrappedKeyNonce,proto3" json:"wrapped_key_nonce,omitempty"`
+	Items           []*CollectionItem      `protobuf:"bytes,8,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Collection) Reset() {
+	*x = Collection{}
+	mi := &file_gophkeeper_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Collection) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Collection) ProtoMessage() {}
+
+func (x *Collection) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Collection.ProtoReflect.Descriptor instead.
+func (*Collection) Descriptor() ([]byte, []int) {
+	return file_gophkeeper_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Collection) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Collection) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Collection) GetOrg() string {
+	if x != nil {
+		return x.Org
+	}
+	return ""
+}
+
+func (x *Collection) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *Collection) GetEphemeralKey() []byte {
+	if x != nil {
+		return x.EphemeralKey
+	}
+	return nil
+}
+
+func (x *Collection) GetWrappedKey() []byte {
+	if x != nil {
+		return x.WrappedKey
+	}
+	return nil
+}
+
+func (x *Collection) GetWrappedKeyNonce() []byte {
+	if x != nil {
+		return x.WrappedKeyNonce
+	}
+	return nil
+}
+
+func (x *Collection) GetItems() []*CollectionItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type CollectionItem struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name           string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Version        int64                  `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	Deleted        bool                   `protobuf:"varint,4,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	UpdatedAt      *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	LoginCipher    []byte                 `protobuf:"bytes,6,opt,name=login_cipher,json=loginCipher,proto3" json:"login_cipher,omitempty"`
+	LoginNonce     []byte                 `protobuf:"bytes,7,opt,name=login_nonce,json=loginNonce,proto3" json:"login_nonce,omitempty"`
+	PasswordCipher []byte                 `protobuf:"bytes,8,opt,name=password_cipher,json=passwordCipher,proto3" json:"password_cipher,omitempty"`
+	PasswordNonce  []byte                 `protobuf:"bytes,9,opt,name=password_nonce,json=passwordNonce,proto3" json:"password_nonce,omitempty"`
+	TextCipher     []byte                 `protobuf:"bytes,10,opt,name=text_cipher,json=textCipher,proto3" json:"text_cipher,omitempty"`
+	TextNonce      []byte                 `protobuf:"bytes,11,opt,name=text_nonce,json=textNonce,proto3" json:"text_nonce,omitempty"`
+	CardCipher     []byte                 `protobuf:"bytes,12,opt,name=card_cipher,json=cardCipher,proto3" json:"card_cipher,omitempty"`
+	CardNonce      []byte                 `protobuf:"bytes,13,opt,name=card_nonce,json=cardNonce,proto3" json:"card_nonce,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CollectionItem) Reset() {
+	*x = CollectionItem{}
+	mi := &file_gophkeeper_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CollectionItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollectionItem) ProtoMessage() {}
+
+func (x *CollectionItem) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollectionItem.ProtoReflect.Descriptor instead.
+func (*CollectionItem) Descriptor() ([]byte, []int) {
+	return file_gophkeeper_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CollectionItem) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CollectionItem) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CollectionItem) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *CollectionItem) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+func (x *CollectionItem) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *CollectionItem) GetLoginCipher() []byte {
+	if x != nil {
+		return x.LoginCipher
+	}
+	return nil
+}
+
+func (x *CollectionItem) GetLoginNonce() []byte {
+	if x != nil {
+		return x.LoginNonce
+	}
+	return nil
+}
+
+func (x *CollectionItem) GetPasswordCipher() []byte {
+	if x != nil {
+		return x.PasswordCipher
+	}
+	return nil
+}
+
+func (x *CollectionItem) GetPasswordNonce() []byte {
+	if x != nil {
+		return x.PasswordNonce
+	}
+	return nil
+}
+
+func (x *CollectionItem) GetTextCipher() []byte {
+	if x != nil {
+		return x.TextCipher
+	}
+	return nil
+}
+
+func (x *CollectionItem) GetTextNonce() []byte {
+	if x != nil {
+		return x.TextNonce
+	}
+	return nil
+}
+
+func (x *CollectionItem) GetCardCipher() []byte {
+	if x != nil {
+		return x.CardCipher
+	}
+	return nil
+}
+
+func (x *CollectionItem) GetCardNonce() []byte {
+	if x != nil {
+		return x.CardNonce
+	}
+	return nil
+}
+
+type BlobChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Nonce         []byte                 `protobuf:"bytes,2,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	Size          int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	Data          []byte                 `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BlobChunk) Reset() {
+	*x = BlobChunk{}
+	mi := &file_gophkeeper_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BlobChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlobChunk) ProtoMessage() {}
+
+func (x *BlobChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlobChunk.ProtoReflect.Descriptor instead.
+func (*BlobChunk) Descriptor() ([]byte, []int) {
+	return file_gophkeeper_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *BlobChunk) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *BlobChunk) GetNonce() []byte {
+	if x != nil {
+		return x.Nonce
+	}
+	return nil
+}
+
+func (x *BlobChunk) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *BlobChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type UploadBlobResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Created       bool                   `protobuf:"varint,2,opt,name=created,proto3" json:"created,omitempty"`
+	Size          int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadBlobResponse) Reset() {
+	*x = UploadBlobResponse{}
+	mi := &file_gophkeeper_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadBlobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadBlobResponse) ProtoMessage() {}
+
+func (x *UploadBlobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadBlobResponse.ProtoReflect.Descriptor instead.
+func (*UploadBlobResponse) Descriptor() ([]byte, []int) {
+	return file_gophkeeper_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *UploadBlobResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UploadBlobResponse) GetCreated() bool {
+	if x != nil {
+		return x.Created
+	}
+	return false
+}
+
+func (x *UploadBlobResponse) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+type DownloadBlobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadBlobRequest) Reset() {
+	*x = DownloadBlobRequest{}
+	mi := &file_gophkeeper_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadBlobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadBlobRequest) ProtoMessage() {}
+
+func (x *DownloadBlobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadBlobRequest.ProtoReflect.Descriptor instead.
+func (*DownloadBlobRequest) Descriptor() ([]byte, []int) {
+	return file_gophkeeper_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DownloadBlobRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetItemRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetItemRequest) Reset() {
+	*x = GetItemRequest{}
+	mi := &file_gophkeeper_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetItemRequest) ProtoMessage() {}
+
+func (x *GetItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophkeeper_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetItemRequest.ProtoReflect.Descriptor instead.
+func (*GetItemRequest) Descriptor() ([]byte, []int) {
+	return file_gophkeeper_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetItemRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+var File_gophkeeper_proto protoreflect.FileDescriptor
+
+const file_gophkeeper_proto_rawDesc = "" +
+	"\n" +
+	"\x10gophkeeper.proto\x12\rgophkeeper.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"?\n" +
+	"\vCredentials\x12\x14\n" +
+	"\x05login\x18\x01 \x01(\tR\x05login\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\"$\n" +
+	"\fAuthResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\"\xe3\x03\n" +
+	"\x04Item\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x03R\aversion\x12\x18\n" +
+	"\adeleted\x18\x03 \x01(\bR\adeleted\x129\n" +
+	"\n" +
+	"updated_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x12\n" +
+	"\x04name\x18\x05 \x01(\tR\x04name\x12\x1b\n" +
+	"\tfile_name\x18\x06 \x01(\tR\bfileName\x12\x17\n" +
+	"\ablob_id\x18\a \x01(\tR\x06blobId\x12!\n" +
+	"\flogin_cipher\x18\b \x01(\fR\vloginCipher\x12\x1f\n" +
+	"\vlogin_nonce\x18\t \x01(\fR\n" +
+	"loginNonce\x12'\n" +
+	"\x0fpassword_cipher\x18\n" +
+	" \x01(\fR\x0epasswordCipher\x12%\n" +
+	"\x0epassword_nonce\x18\v \x01(\fR\rpasswordNonce\x12\x1f\n" +
+	"\vtext_cipher\x18\f \x01(\fR\n" +
+	"textCipher\x12\x1d\n" +
+	"\n" +
+	"text_nonce\x18\r \x01(\fR\ttextNonce\x12\x1f\n" +
+	"\vcard_cipher\x18\x0e \x01(\fR\n" +
+	"cardCipher\x12\x1d\n" +
+	"\n" +
+	"card_nonce\x18\x0f \x01(\fR\tcardNonce\"\x95\x05\n" +
+	"\n" +
+	"ItemChange\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1d\n" +
+	"\aversion\x18\x02 \x01(\x03H\x00R\aversion\x88\x01\x01\x12\x1d\n" +
+	"\adeleted\x18\x03 \x01(\bH\x01R\adeleted\x88\x01\x01\x12\x17\n" +
+	"\x04name\x18\x04 \x01(\tH\x02R\x04name\x88\x01\x01\x12 \n" +
+	"\tfile_name\x18\x05 \x01(\tH\x03R\bfileName\x88\x01\x01\x12\x1c\n" +
+	"\ablob_id\x18\x06 \x01(\tH\x04R\x06blobId\x88\x01\x01\x12!\n" +
+	"\flogin_cipher\x18\a \x01(\fR\vloginCipher\x12\x1f\n" +
+	"\vlogin_nonce\x18\b \x01(\fR\n" +
+	"loginNonce\x12'\n" +
+	"\x0fpassword_cipher\x18\t \x01(\fR\x0epasswordCipher\x12%\n" +
+	"\x0epassword_nonce\x18\n" +
+	" \x01(\fR\rpasswordNonce\x12\x1f\n" +
+	"\vtext_cipher\x18\v \x01(\fR\n" +
+	"textCipher\x12\x1d\n" +
+	"\n" +
+	"text_nonce\x18\f \x01(\fR\ttextNonce\x12\x1f\n" +
+	"\vcard_cipher\x18\r \x01(\fR\n" +
+	"cardCipher\x12\x1d\n" +
+	"\n" +
+	"card_nonce\x18\x0e \x01(\fR\tcardNonce\x12P\n" +
+	"\rbase_versions\x18\x0f \x03(\v2+.gophkeeper.v1.ItemChange.BaseVersionsEntryR\fbaseVersions\x1a?\n" +
+	"\x11BaseVersionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01B\n" +
+	"\n" +
+	"\b_versionB\n" +
+	"\n" +
+	"\b_deletedB\a\n" +
+	"\x05_nameB\f\n" +
+	"\n" +
+	"_file_nameB\n" +
+	"\n" +
+	"\b_blob_id\"\xb1\x02\n" +
+	"\vSyncRequest\x12\x16\n" +
+	"\x06cursor\x18\x01 \x01(\tR\x06cursor\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x123\n" +
+	"\achanges\x18\x03 \x03(\v2\x19.gophkeeper.v1.ItemChangeR\achanges\x12\x18\n" +
+	"\aresolve\x18\x04 \x01(\tR\aresolve\x12M\n" +
+	"\vresolutions\x18\x05 \x03(\v2+.gophkeeper.v1.SyncRequest.ResolutionsEntryR\vresolutions\x12\x16\n" +
+	"\x06atomic\x18\x06 \x01(\bR\x06atomic\x1a>\n" +
+	"\x10ResolutionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\":\n" +
+	"\aApplied\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1f\n" +
+	"\vnew_version\x18\x02 \x01(\x03R\n" +
+	"newVersion\"\x80\x01\n" +
+	"\bConflict\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x124\n" +
+	"\vserver_item\x18\x03 \x01(\v2\x13.gophkeeper.v1.ItemR\n" +
+	"serverItem\x12\x16\n" +
+	"\x06fields\x18\x04 \x03(\tR\x06fields\"\x89\x03\n" +
+	"\fSyncResponse\x120\n" +
+	"\aapplied\x18\x01 \x03(\v2\x16.gophkeeper.v1.AppliedR\aapplied\x125\n" +
+	"\tconflicts\x18\x02 \x03(\v2\x17.gophkeeper.v1.ConflictR\tconflicts\x12:\n" +
+	"\x0eserver_changes\x18\x03 \x03(\v2\x13.gophkeeper.v1.ItemR\rserverChanges\x12\x16\n" +
+	"\x06cursor\x18\x04 \x01(\tR\x06cursor\x12\x19\n" +
+	"\bhas_more\x18\x05 \x01(\bR\ahasMore\x12;\n" +
+	"\vserver_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"serverTime\x12;\n" +
+	"\vcollections\x18\a \x03(\v2\x19.gophkeeper.v1.CollectionR\vcollections\x12'\n" +
+	"\x0fhas_collections\x18\b \x01(\bR\x0ehasCollections\"\xfd\x01\n" +
+	"\n" +
+	"Collection\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x10\n" +
+	"\x03org\x18\x03 \x01(\tR\x03org\x12\x12\n" +
+	"\x04role\x18\x04 \x01(\tR\x04role\x12#\n" +
+	"\rephemeral_key\x18\x05 \x01(\fR\fephemeralKey\x12\x1f\n" +
+	"\vwrapped_key\x18\x06 \x01(\fR\n" +
+	"wrappedKey\x12*\n" +
+	"\x11wrapped_key_nonce\x18\a \x01(\fR\x0fwrappedKeyNonce\x123\n" +
+	"\x05items\x18\b \x03(\v2\x1d.gophkeeper.v1.CollectionItemR\x05items\"\xb7\x03\n" +
+	"\x0eCollectionItem\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\x03R\aversion\x12\x18\n" +
+	"\adeleted\x18\x04 \x01(\bR\adeleted\x129\n" +
+	"\n" +
+	"updated_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12!\n" +
+	"\flogin_cipher\x18\x06 \x01(\fR\vloginCipher\x12\x1f\n" +
+	"\vlogin_nonce\x18\a \x01(\fR\n" +
+	"loginNonce\x12'\n" +
+	"\x0fpassword_cipher\x18\b \x01(\fR\x0epasswordCipher\x12%\n" +
+	"\x0epassword_nonce\x18\t \x01(\fR\rpasswordNonce\x12\x1f\n" +
+	"\vtext_cipher\x18\n" +
+	" \x01(\fR\n" +
+	"textCipher\x12\x1d\n" +
+	"\n" +
+	"text_nonce\x18\v \x01(\fR\ttextNonce\x12\x1f\n" +
+	"\vcard_cipher\x18\f \x01(\fR\n" +
+	"cardCipher\x12\x1d\n" +
+	"\n" +
+	"card_nonce\x18\r \x01(\fR\tcardNonce\"Y\n" +
+	"\tBlobChunk\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05nonce\x18\x02 \x01(\fR\x05nonce\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\x12\x12\n" +
+	"\x04data\x18\x04 \x01(\fR\x04data\"R\n" +
+	"\x12UploadBlobResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\acreated\x18\x02 \x01(\bR\acreated\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\"%\n" +
+	"\x13DownloadBlobRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\" \n" +
+	"\x0eGetItemRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id2\xb2\x03\n" +
+	"\n" +
+	"GophKeeper\x12C\n" +
+	"\bRegister\x12\x1a.gophkeeper.v1.Credentials\x1a\x1b.gophkeeper.v1.AuthResponse\x12@\n" +
+	"\x05Login\x12\x1a.gophkeeper.v1.Credentials\x1a\x1b.gophkeeper.v1.AuthResponse\x12A\n" +
+	"\x04Sync\x12\x1a.gophkeeper.v1.SyncRequest\x1a\x1b.gophkeeper.v1.SyncResponse0\x01\x12K\n" +
+	"\n" +
+	"UploadBlob\x12\x18.gophkeeper.v1.BlobChunk\x1a!.gophkeeper.v1.UploadBlobResponse(\x01\x12N\n" +
+	"\fDownloadBlob\x12\".gophkeeper.v1.DownloadBlobRequest\x1a\x18.gophkeeper.v1.BlobChunk0\x01\x12=\n" +
+	"\aGetItem\x12\x1d.gophkeeper.v1.GetItemRequest\x1a\x13.gophkeeper.v1.ItemB\x1bZ\x19GophKeeper/internal/pb;pbb\x06proto3"
+
+var (
+	file_gophkeeper_proto_rawDescOnce sync.Once
+	file_gophkeeper_proto_rawDescData []byte
+)
+
+func file_gophkeeper_proto_rawDescGZIP() []byte {
+	file_gophkeeper_proto_rawDescOnce.Do(func() {
+		file_gophkeeper_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_gophkeeper_proto_rawDesc), len(file_gophkeeper_proto_rawDesc)))
+	})
+	return file_gophkeeper_proto_rawDescData
+}
+
+var file_gophkeeper_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_gophkeeper_proto_goTypes = []any{
+	(*Credentials)(nil),           // 0: gophkeeper.v1.Credentials
+	(*AuthResponse)(nil),          // 1: gophkeeper.v1.AuthResponse
+	(*Item)(nil),                  // 2: gophkeeper.v1.Item
+	(*ItemChange)(nil),            // 3: gophkeeper.v1.ItemChange
+	(*SyncRequest)(nil),           // 4: gophkeeper.v1.SyncRequest
+	(*Applied)(nil),               // 5: gophkeeper.v1.Applied
+	(*Conflict)(nil),              // 6: gophkeeper.v1.Conflict
+	(*SyncResponse)(nil),          // 7: gophkeeper.v1.SyncResponse
+	(*Collection)(nil),            // 8: gophkeeper.v1.Collection
+	(*CollectionItem)(nil),        // 9: gophkeeper.v1.CollectionItem
+	(*BlobChunk)(nil),             // 10: gophkeeper.v1.BlobChunk
+	(*UploadBlobResponse)(nil),    // 11: gophkeeper.v1.UploadBlobResponse
+	(*DownloadBlobRequest)(nil),   // 12: gophkeeper.v1.DownloadBlobRequest
+	(*GetItemRequest)(nil),        // 13: gophkeeper.v1.GetItemRequest
+	nil,                           // 14: gophkeeper.v1.ItemChange.BaseVersionsEntry
+	nil,                           // 15: gophkeeper.v1.SyncRequest.ResolutionsEntry
+	(*timestamppb.Timestamp)(nil), // 16: google.protobuf.Timestamp
+}
+var file_gophkeeper_proto_depIdxs = []int32{
+	16, // 0: gophkeeper.v1.Item.updated_at:type_name -> google.protobuf.Timestamp
+	14, // 1: gophkeeper.v1.ItemChange.base_versions:type_name -> gophkeeper.v1.ItemChange.BaseVersionsEntry
+	3,  // 2: gophkeeper.v1.SyncRequest.changes:type_name -> gophkeeper.v1.ItemChange
+	15, // 3: gophkeeper.v1.SyncRequest.resolutions:type_name -> gophkeeper.v1.SyncRequest.ResolutionsEntry
+	2,  // 4: gophkeeper.v1.Conflict.server_item:type_name -> gophkeeper.v1.Item
+	5,  // 5: gophkeeper.v1.SyncResponse.applied:type_name -> gophkeeper.v1.Applied
+	6,  // 6: gophkeeper.v1.SyncResponse.conflicts:type_name -> gophkeeper.v1.Conflict
+	2,  // 7: gophkeeper.v1.SyncResponse.server_changes:type_name -> gophkeeper.v1.Item
+	16, // 8: gophkeeper.v1.SyncResponse.server_time:type_name -> google.protobuf.Timestamp
+	8,  // 9: gophkeeper.v1.SyncResponse.collections:type_name -> gophkeeper.v1.Collection
+	9,  // 10: gophkeeper.v1.Collection.items:type_name -> gophkeeper.v1.CollectionItem
+	16, // 11: gophkeeper.v1.CollectionItem.updated_at:type_name -> google.protobuf.Timestamp
+	0,  // 12: gophkeeper.v1.GophKeeper.Register:input_type -> gophkeeper.v1.Credentials
+	0,  // 13: gophkeeper.v1.GophKeeper.Login:input_type -> gophkeeper.v1.Credentials
+	4,  // 14: gophkeeper.v1.GophKeeper.Sync:input_type -> gophkeeper.v1.SyncRequest
+	10, // 15: gophkeeper.v1.GophKeeper.UploadBlob:input_type -> gophkeeper.v1.BlobChunk
+	12, // 16: gophkeeper.v1.GophKeeper.DownloadBlob:input_type -> gophkeeper.v1.DownloadBlobRequest
+	13, // 17: gophkeeper.v1.GophKeeper.GetItem:input_type -> gophkeeper.v1.GetItemRequest
+	1,  // 18: gophkeeper.v1.GophKeeper.Register:output_type -> gophkeeper.v1.AuthResponse
+	1,  // 19: gophkeeper.v1.GophKeeper.Login:output_type -> gophkeeper.v1.AuthResponse
+	7,  // 20: gophkeeper.v1.GophKeeper.Sync:output_type -> gophkeeper.v1.SyncResponse
+	11, // 21: gophkeeper.v1.GophKeeper.UploadBlob:output_type -> gophkeeper.v1.UploadBlobResponse
+	10, // 22: gophkeeper.v1.GophKeeper.DownloadBlob:output_type -> gophkeeper.v1.BlobChunk
+	2,  // 23: gophkeeper.v1.GophKeeper.GetItem:output_type -> gophkeeper.v1.Item
+	18, // [18:24] is the sub-list for method output_type
+	12, // [12:18] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
+}
+
+func init() { file_gophkeeper_proto_init() }
+func file_gophkeeper_proto_init() {
+	if File_gophkeeper_proto != nil {
+		return
+	}
+	file_gophkeeper_proto_msgTypes[3].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_gophkeeper_proto_rawDesc), len(file_gophkeeper_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   16,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_gophkeeper_proto_goTypes,
+		DependencyIndexes: file_gophkeeper_proto_depIdxs,
+		MessageInfos:      file_gophkeeper_proto_msgTypes,
+	}.Build()
+	File_gophkeeper_proto = out.File
+	file_gophkeeper_proto_goTypes = nil
+	file_gophkeeper_proto_depIdxs = nil
+}
@@ -0,0 +1,176 @@
+package repo
+
+import (
+	"GophKeeper/internal/model"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrUserNotFound — пользователя с таким id нет.
+var ErrUserNotFound = errors.New("user not found")
+
+// BlobStats — сводка по блобам для gkserver admin gc-stats.
+type BlobStats struct {
+	Blobs int64 // метаданных блобов в БД
+	Bytes int64 // их суммарный размер
+
+	// Orphaned — блобы, на которые не ссылаются ни записи, ни история версий
+	// (в том числе только что загруженные, чья запись ещё не синхронизирована).
+	Orphaned      int64
+	OrphanedBytes int64
+
+	Inline int64 // содержимое ещё хранится в БД (см. migrate-blobs)
+
+	Tombstones       int64 // удалённые записи, хранимые для синхронизации
+	Versions         int64 // предыдущие версии записей
+	OrphanedVersions int64 // версии записей, которых уже нет
+}
+
+// AdminRepository — состояние пользователей и служебная статистика (gkserver admin).
+type AdminRepository interface {
+	// ListUsers возвращает всех пользователей по возрастанию id.
+	ListUsers(ctx context.Context) ([]model.User, error)
+
+	// State возвращает состояние пользователя (ErrUserNotFound); без строки состояния —
+	// активное состояние по умолчанию.
+	State(ctx context.Context, userID int64) (*model.UserState, error)
+
+	// SetDisabled отключает или включает пользователя.
+	SetDisabled(ctx context.Context, userID int64, disabled bool) error
+
+	// RevokeSessions делает недействительными токены, выданные пользователю не позже at.
+	RevokeSessions(ctx context.Context, userID int64, at time.Time) error
+
+	// DeleteUser удаляет пользователя (ErrUserNotFound) со всеми данными в одной транзакции
+	// и возвращает id его блобов: их содержимое удаляет вызывающий. Организации,
+	// оставшиеся без участников, удаляются вместе с коллекциями.
+	DeleteUser(ctx context.Context, userID int64) ([]string, error)
+
+	// BlobStats собирает статистику блобов, удалённых записей и истории версий.
+	BlobStats(ctx context.Context) (*BlobStats, error)
+}
+
+type adminRepo struct {
+	db *gorm.DB
+}
+
+// NewAdminRepository создаёт реализацию административного репозитория.
+func NewAdminRepository(db *gorm.DB) AdminRepository {
+	return &adminRepo{db: db}
+}
+
+func (r *adminRepo) ListUsers(ctx context.Context) ([]model.User, error) {
+	var out []model.User
+	err := r.db.WithContext(ctx).Order("id asc").Find(&out).Error
+	return out, err
+}
+
+func (r *adminRepo) State(ctx context.Context, userID int64) (*model.UserState, error) {
+	// одним запросом: проверка токена выполняется на каждый авторизованный запрос
+	var rows []model.UserState
+	err := r.db.WithContext(ctx).Model(&model.User{}).
+		Select("users.id AS user_id, COALESCE(user_states.disabled, ?) AS disabled, "+
+			"user_states.sessions_revoked_at, user_states.updated_at", false).
+		Joins("LEFT JOIN user_states ON user_states.user_id = users.id").
+		Where("users.id = ?", userID).
+		Limit(1).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, ErrUserNotFound
+	}
+	return &rows[0], nil
+}
+
+func (r *adminRepo) SetDisabled(ctx context.Context, userID int64, disabled bool) error {
+	return r.upsertState(ctx, &model.UserState{UserID: userID, Disabled: disabled}, "disabled")
+}
+
+func (r *adminRepo) RevokeSessions(ctx context.Context, userID int64, at time.Time) error {
+	// точность времени выдачи токена и timestamptz PostgreSQL — микросекунды
+	at = at.UTC().Truncate(time.Microsecond)
+	return r.upsertState(ctx, &model.UserState{UserID: userID, SessionsRevokedAt: &at}, "sessions_revoked_at")
+}
+
+// upsertState создаёт строку состояния или меняет в существующей только column.
+func (r *adminRepo) upsertState(ctx context.Context, s *model.UserState, column string) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{column, "updated_at"}),
+	}).Create(s).Error
+}
+
+func (r *adminRepo) DeleteUser(ctx context.Context, userID int64) ([]string, error) {
+	var blobIDs []string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Delete(&model.User{}, userID)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrUserNotFound
+		}
+		// Записи, ключи, доли, устройства и членства удаляются каскадно;
+		// у блобов, истории и счётчика изменений внешнего ключа нет.
+		if err := tx.Model(&model.Blob{}).Where("user_id = ?", userID).Pluck("id", &blobIDs).Error; err != nil {
+			return err
+		}
+		for _, mdl := range []any{&model.Blob{}, &model.ItemVersion{}, &model.UserChangeSeq{}} {
+			if err := tx.Where("user_id = ?", userID).Delete(mdl).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Where("NOT EXISTS (?)",
+			tx.Model(&model.OrgMember{}).Select("1").Where("org_members.org_id = organizations.id"),
+		).Delete(&model.Organization{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return blobIDs, nil
+}
+
+func (r *adminRepo) BlobStats(ctx context.Context) (*BlobStats, error) {
+	db := r.db.WithContext(ctx)
+	var st BlobStats
+	sum := func(q *gorm.DB, count, bytes *int64) error {
+		var row struct{ N, Size int64 }
+		if err := q.Select("COUNT(*) AS n, COALESCE(SUM(size), 0) AS size").Scan(&row).Error; err != nil {
+			return err
+		}
+		*count, *bytes = row.N, row.Size
+		return nil
+	}
+	if err := sum(db.Model(&model.Blob{}), &st.Blobs, &st.Bytes); err != nil {
+		return nil, err
+	}
+	orphaned := db.Model(&model.Blob{}).
+		Where("NOT EXISTS (?)", db.Model(&model.Item{}).Select("1").Where("items.blob_id = blobs.id")).
+		Where("NOT EXISTS (?)", db.Model(&model.ItemVersion{}).Select("1").Where("item_versions.blob_id = blobs.id"))
+	if err := sum(orphaned, &st.Orphaned, &st.OrphanedBytes); err != nil {
+		return nil, err
+	}
+	counts := []struct {
+		q   *gorm.DB
+		dst *int64
+	}{
+		{db.Model(&model.Blob{}).Where("cipher IS NOT NULL"), &st.Inline},
+		{db.Model(&model.Item{}).Where("deleted = ?", true), &st.Tombstones},
+		{db.Model(&model.ItemVersion{}), &st.Versions},
+		{db.Model(&model.ItemVersion{}).
+			Where("NOT EXISTS (?)", db.Model(&model.Item{}).Select("1").Where("items.id = item_versions.item_id")),
+			&st.OrphanedVersions},
+	}
+	for _, c := range counts {
+		if err := c.q.Count(c.dst).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &st, nil
+}
@@ -0,0 +1,115 @@
+package repo
+
+import (
+	"GophKeeper/internal/model"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestAdminRepository_State(t *testing.T) {
+	db := newFileDB(t)
+	ctx := context.Background()
+	m, err := NewMigrator(db)
+	require.NoError(t, err)
+	_, err = m.Up(ctx)
+	require.NoError(t, err)
+
+	users := NewUserRepository(db)
+	alice, err := users.CreateUser(ctx, &model.User{Login: "alice", Password: "h"})
+	require.NoError(t, err)
+	_, err = users.CreateUser(ctx, &model.User{Login: "bob", Password: "h"})
+	require.NoError(t, err)
+
+	r := NewAdminRepository(db)
+	list, err := r.ListUsers(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "alice", list[0].Login)
+
+	st, err := r.State(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.UserState{UserID: alice.ID}, *st)
+	_, err = r.State(ctx, 999)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	require.NoError(t, r.SetDisabled(ctx, alice.ID, true))
+	revoked := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, r.RevokeSessions(ctx, alice.ID, revoked))
+	st, err = r.State(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.True(t, st.Disabled, "отзыв сессий не меняет отключение")
+	require.NotNil(t, st.SessionsRevokedAt)
+	assert.True(t, revoked.Equal(*st.SessionsRevokedAt))
+
+	require.NoError(t, r.SetDisabled(ctx, alice.ID, false))
+	st, err = r.State(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.False(t, st.Disabled)
+	assert.NotNil(t, st.SessionsRevokedAt, "включение не возвращает отозванные токены")
+}
+
+func TestAdminRepository_DeleteUserAndBlobStats(t *testing.T) {
+	db := newFileDB(t)
+	ctx := context.Background()
+	m, err := NewMigrator(db)
+	require.NoError(t, err)
+	_, err = m.Up(ctx)
+	require.NoError(t, err)
+
+	users := NewUserRepository(db)
+	alice, err := users.CreateUser(ctx, &model.User{Login: "alice", Password: "h"})
+	require.NoError(t, err)
+	bob, err := users.CreateUser(ctx, &model.User{Login: "bob", Password: "h"})
+	require.NoError(t, err)
+
+	blobs := NewBlobRepository(db)
+	aliceBlob, bobBlob, orphan := uuid.NewString(), uuid.NewString(), uuid.NewString()
+	for _, b := range []struct {
+		user int64
+		id   string
+		size int64
+	}{{alice.ID, aliceBlob, 10}, {bob.ID, bobBlob, 20}, {bob.ID, orphan, 5}} {
+		_, err := blobs.CreateIfAbsent(ctx, b.user, b.id, []byte("n"), b.size)
+		require.NoError(t, err)
+	}
+	items := NewItemRepository(db)
+	require.NoError(t, items.Create(ctx, &model.Item{ID: uuid.NewString(), UserID: alice.ID, Name: "a", BlobID: &aliceBlob}))
+	bobItem := &model.Item{ID: uuid.NewString(), UserID: bob.ID, Name: "b", BlobID: &bobBlob}
+	require.NoError(t, items.Create(ctx, bobItem))
+	_, err = items.UpdateWithVersion(ctx, bob.ID, bobItem.ID, 1, map[string]any{"deleted": true})
+	require.NoError(t, err)
+
+	orgs := NewOrgRepository(db)
+	require.NoError(t, orgs.CreateOrg(ctx, &model.Organization{Name: "solo"}, alice.ID,
+		&model.Collection{ID: uuid.NewString(), Name: "default"},
+		&model.CollectionKey{EphemeralKey: []byte("e"), WrappedKey: []byte("k"), WrappedKeyNonce: []byte("n")}))
+
+	r := NewAdminRepository(db)
+	st, err := r.BlobStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, BlobStats{Blobs: 3, Bytes: 35, Orphaned: 1, OrphanedBytes: 5, Tombstones: 1, Versions: 1}, *st)
+
+	ids, err := r.DeleteUser(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{aliceBlob}, ids)
+	_, err = users.GetUserByLogin(ctx, "alice")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	all, err := items.ListAll(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Empty(t, all)
+	_, err = orgs.GetOrgByName(ctx, "solo")
+	assert.ErrorIs(t, err, ErrOrgNotFound, "организация без участников удаляется")
+
+	st, err = r.BlobStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), st.Blobs)
+
+	_, err = r.DeleteUser(ctx, alice.ID)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
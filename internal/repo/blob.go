@@ -8,11 +8,27 @@ import (
 	"gorm.io/gorm/clause"
 )
 
-// BlobRepository минимальный контракт доступа к Blob.
+// BlobRepository минимальный контракт доступа к метаданным Blob.
+// Содержимое блобов хранится отдельно, в BlobStore.
 type BlobRepository interface {
 	// CreateIfAbsent пытается создать запись. Если существует — ничего не делает.
 	// Возвращает created=true если запись была создана в этой операции.
-	CreateIfAbsent(ctx context.Context, id string, cipher, nonce []byte) (created bool, err error)
+	CreateIfAbsent(ctx context.Context, userID int64, id string, nonce []byte, size int64) (created bool, err error)
+
+	// SumSizeByUser возвращает суммарный размер блобов пользователя в байтах.
+	SumSizeByUser(ctx context.Context, userID int64) (int64, error)
+
+	// GetByID возвращает метаданные блоба по id (и содержимое, если оно ещё хранится в БД).
+	GetByID(ctx context.Context, id string) (*model.Blob, error)
+
+	// ListInline возвращает до limit блобов, содержимое которых ещё хранится в БД.
+	ListInline(ctx context.Context, limit int) ([]model.Blob, error)
+
+	// ClearInline удаляет содержимое блоба из БД после переноса в BlobStore.
+	ClearInline(ctx context.Context, id string, size int64) error
+
+	// Delete удаляет метаданные блоба.
+	Delete(ctx context.Context, id string) error
 }
 
 type blobRepo struct {
@@ -25,8 +41,8 @@ func NewBlobRepository(db *gorm.DB) BlobRepository {
 }
 
 // CreateIfAbsent создает Blob в БД, если его ещё нет.
-func (r *blobRepo) CreateIfAbsent(ctx context.Context, id string, cipher, nonce []byte) (bool, error) {
-	b := &model.Blob{ID: id, Cipher: cipher, Nonce: nonce}
+func (r *blobRepo) CreateIfAbsent(ctx context.Context, userID int64, id string, nonce []byte, size int64) (bool, error) {
+	b := &model.Blob{ID: id, UserID: userID, Nonce: nonce, Size: size}
 	tx := r.db.WithContext(ctx).Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "id"}},
 		DoNothing: true,
@@ -36,3 +52,56 @@ func (r *blobRepo) CreateIfAbsent(ctx context.Context, id string, cipher, nonce
 	}
 	return tx.RowsAffected > 0, nil
 }
+
+// SumSizeByUser суммирует размеры блобов пользователя.
+func (r *blobRepo) SumSizeByUser(ctx context.Context, userID int64) (int64, error) {
+	var total int64
+	err := r.db.WithContext(ctx).Model(&model.Blob{}).
+		Where("user_id = ?", userID).
+		Select("COALESCE(SUM(size), 0)").
+		Scan(&total).Error
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// GetByID возвращает метаданные блоба; у блоба, ещё не перенесённого migrate-blobs,
+// заполнено и содержимое cipher.
+func (r *blobRepo) GetByID(ctx context.Context, id string) (*model.Blob, error) {
+	var b model.Blob
+	err := r.db.WithContext(ctx).
+		Select("id", "user_id", "nonce", "size", "cipher").
+		Where("id = ?", id).
+		First(&b).Error
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// ListInline возвращает блобы с непустым столбцом cipher.
+func (r *blobRepo) ListInline(ctx context.Context, limit int) ([]model.Blob, error) {
+	var blobs []model.Blob
+	err := r.db.WithContext(ctx).
+		Where("cipher IS NOT NULL").
+		Order("id asc").
+		Limit(limit).
+		Find(&blobs).Error
+	if err != nil {
+		return nil, err
+	}
+	return blobs, nil
+}
+
+// ClearInline обнуляет столбец cipher и фиксирует размер перенесённого содержимого.
+func (r *blobRepo) ClearInline(ctx context.Context, id string, size int64) error {
+	return r.db.WithContext(ctx).Model(&model.Blob{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"cipher": nil, "size": size}).Error
+}
+
+// Delete удаляет метаданные блоба по id.
+func (r *blobRepo) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.Blob{}).Error
+}
@@ -1,10 +1,12 @@
 package repo
 
 import (
+	"GophKeeper/internal/model"
 	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 )
 
 func TestBlobRepository_CreateIfAbsent_Idempotent(t *testing.T) {
@@ -13,12 +15,100 @@ func TestBlobRepository_CreateIfAbsent_Idempotent(t *testing.T) {
 	ctx := context.Background()
 
 	// первая вставка — created=true
-	created, err := r.CreateIfAbsent(ctx, "b1", []byte{1, 2}, []byte{3})
+	created, err := r.CreateIfAbsent(ctx, 1, "b1", []byte{3}, 2)
 	assert.NoError(t, err)
 	assert.True(t, created)
 
 	// повторная — created=false
-	created, err = r.CreateIfAbsent(ctx, "b1", []byte{9}, []byte{9})
+	created, err = r.CreateIfAbsent(ctx, 1, "b1", []byte{9}, 1)
 	assert.NoError(t, err)
 	assert.False(t, created)
+
+	got, err := r.GetByID(ctx, "b1")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{3}, got.Nonce)
+	assert.Equal(t, int64(2), got.Size)
+
+	_, err = r.GetByID(ctx, "missing")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestBlobRepository_ListAndClearInline(t *testing.T) {
+	db := newTestDB(t)
+	r := NewBlobRepository(db)
+	ctx := context.Background()
+
+	// блоб в устаревшем формате (содержимое в БД) и новый — только метаданные
+	assert.NoError(t, db.Create(&model.Blob{ID: "inl-1", Nonce: []byte{1}, Cipher: []byte{1, 2, 3}}).Error)
+	_, err := r.CreateIfAbsent(ctx, 1, "ext-1", []byte{1}, 10)
+	assert.NoError(t, err)
+
+	list, err := r.ListInline(ctx, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, list, 1) {
+		assert.Equal(t, "inl-1", list[0].ID)
+		assert.Equal(t, []byte{1, 2, 3}, list[0].Cipher)
+	}
+
+	assert.NoError(t, r.ClearInline(ctx, "inl-1", 3))
+	list, err = r.ListInline(ctx, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, list)
+
+	got, err := r.GetByID(ctx, "inl-1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), got.Size)
+}
+
+func TestBlobRepository_GetByID_Inline(t *testing.T) {
+	db := newTestDB(t)
+	r := NewBlobRepository(db)
+	ctx := context.Background()
+
+	assert.NoError(t, db.Create(&model.Blob{ID: "inl-2", UserID: 4, Nonce: []byte{1}, Cipher: []byte{7, 8}}).Error)
+	got, err := r.GetByID(ctx, "inl-2")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), got.UserID)
+	assert.Equal(t, []byte{7, 8}, got.Cipher)
+
+	// после переноса содержимое в БД не остаётся
+	assert.NoError(t, r.ClearInline(ctx, "inl-2", 2))
+	got, err = r.GetByID(ctx, "inl-2")
+	assert.NoError(t, err)
+	assert.Empty(t, got.Cipher)
+}
+
+func TestBlobRepository_Delete(t *testing.T) {
+	db := newTestDB(t)
+	r := NewBlobRepository(db)
+	ctx := context.Background()
+
+	_, err := r.CreateIfAbsent(ctx, 1, "del-1", []byte{1}, 1)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Delete(ctx, "del-1"))
+	_, err = r.GetByID(ctx, "del-1")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.NoError(t, r.Delete(ctx, "del-1"))
+}
+
+func TestBlobRepository_SumSizeByUser(t *testing.T) {
+	db := newTestDB(t)
+	r := NewBlobRepository(db)
+	ctx := context.Background()
+
+	_, err := r.CreateIfAbsent(ctx, 77, "sum-1", []byte{1}, 100)
+	assert.NoError(t, err)
+	_, err = r.CreateIfAbsent(ctx, 77, "sum-2", []byte{1}, 23)
+	assert.NoError(t, err)
+	_, err = r.CreateIfAbsent(ctx, 78, "sum-3", []byte{1}, 1000)
+	assert.NoError(t, err)
+
+	total, err := r.SumSizeByUser(ctx, 77)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(123), total)
+
+	// у пользователя без блобов — ноль, а не ошибка
+	total, err = r.SumSizeByUser(ctx, 79)
+	assert.NoError(t, err)
+	assert.Zero(t, total)
 }
@@ -0,0 +1,34 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// ErrBlobNotFound возвращается BlobStore, если содержимого с таким id нет.
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore — хранилище содержимого блобов (зашифрованных байтов).
+// Метаданные (nonce, размер) хранятся в БД через BlobRepository.
+type BlobStore interface {
+	// Put сохраняет содержимое блоба. Повторная запись с тем же id перезаписывает данные.
+	Put(ctx context.Context, id string, data []byte) error
+
+	// Get возвращает содержимое блоба или ErrBlobNotFound.
+	Get(ctx context.Context, id string) ([]byte, error)
+
+	// Delete удаляет содержимое блоба. Отсутствие блоба ошибкой не считается.
+	Delete(ctx context.Context, id string) error
+}
+
+var blobIDRe = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+
+// validateBlobID не допускает в id символов, опасных для путей и ключей объектов.
+func validateBlobID(id string) error {
+	if !blobIDRe.MatchString(id) {
+		return fmt.Errorf("invalid blob id: %q", id)
+	}
+	return nil
+}
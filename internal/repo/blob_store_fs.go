@@ -0,0 +1,95 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// fsBlobStore хранит блобы файлами в локальном каталоге: <dir>/<id[:2]>/<id>.
+type fsBlobStore struct {
+	dir string
+}
+
+// NewFSBlobStore создаёт файловое хранилище блобов в каталоге dir (создаётся при необходимости).
+func NewFSBlobStore(dir string) (BlobStore, error) {
+	if dir == "" {
+		return nil, errors.New("empty blob dir")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &fsBlobStore{dir: dir}, nil
+}
+
+func (s *fsBlobStore) path(id string) string {
+	shard := id
+	if len(shard) > 2 {
+		shard = shard[:2]
+	}
+	return filepath.Join(s.dir, shard, id)
+}
+
+// Put пишет содержимое во временный файл и атомарно переименовывает его.
+func (s *fsBlobStore) Put(ctx context.Context, id string, data []byte) error {
+	if err := validateBlobID(id); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	p := s.path(id)
+	if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p), id+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+// Get читает содержимое блоба с диска.
+func (s *fsBlobStore) Get(ctx context.Context, id string) ([]byte, error) {
+	if err := validateBlobID(id); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(s.path(id))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrBlobNotFound
+		}
+		return nil, err
+	}
+	return b, nil
+}
+
+// Delete удаляет файл блоба.
+func (s *fsBlobStore) Delete(ctx context.Context, id string) error {
+	if err := validateBlobID(id); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := os.Remove(s.path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
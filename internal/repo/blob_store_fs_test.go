@@ -0,0 +1,54 @@
+package repo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFSBlobStore_PutGetDelete(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewFSBlobStore(dir)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	assert.NoError(t, s.Put(ctx, "ab12-cd", []byte("hello")))
+	// файл лежит в шард-подкаталоге, временных файлов не осталось
+	entries, err := os.ReadDir(filepath.Join(dir, "ab"))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	got, err := s.Get(ctx, "ab12-cd")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("hello"), got)
+
+	// перезапись
+	assert.NoError(t, s.Put(ctx, "ab12-cd", []byte("bye")))
+	got, _ = s.Get(ctx, "ab12-cd")
+	assert.Equal(t, []byte("bye"), got)
+
+	assert.NoError(t, s.Delete(ctx, "ab12-cd"))
+	_, err = s.Get(ctx, "ab12-cd")
+	assert.ErrorIs(t, err, ErrBlobNotFound)
+	// повторное удаление не ошибка
+	assert.NoError(t, s.Delete(ctx, "ab12-cd"))
+}
+
+func TestFSBlobStore_RejectsUnsafeIDs(t *testing.T) {
+	s, err := NewFSBlobStore(t.TempDir())
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	for _, id := range []string{"", "../etc", "a/b", `a\b`} {
+		assert.Error(t, s.Put(ctx, id, []byte{1}), id)
+		_, err := s.Get(ctx, id)
+		assert.Error(t, err, id)
+		assert.Error(t, s.Delete(ctx, id), id)
+	}
+
+	_, err = NewFSBlobStore("")
+	assert.Error(t, err)
+}
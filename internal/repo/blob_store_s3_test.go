@@ -0,0 +1,120 @@
+package repo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeS3 — минимальный in-process S3: PUT/GET/DELETE объектов с проверкой подписи SigV4.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	cfg     S3Config
+	now     time.Time
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	// пересчитываем подпись тем же алгоритмом и сравниваем
+	check := r.Clone(context.Background())
+	check.Header.Del("Authorization")
+	check.URL.Host = r.Host
+	signer := &s3BlobStore{cfg: f.cfg, now: func() time.Time { return f.now }}
+	signer.sign(check, body)
+	if r.Header.Get("Authorization") != check.Header.Get("Authorization") {
+		http.Error(w, "SignatureDoesNotMatch", http.StatusForbidden)
+		return
+	}
+	if r.Header.Get("X-Amz-Content-Sha256") != sha256Hex(body) {
+		http.Error(w, "XAmzContentSHA256Mismatch", http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		f.objects[r.URL.Path] = body
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		b, ok := f.objects[r.URL.Path]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(b)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newFakeS3Store(t *testing.T) (*fakeS3, BlobStore) {
+	t.Helper()
+	fixed := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	cfg := S3Config{Bucket: "gk", Region: "eu-central-1", AccessKey: "AK", SecretKey: "SK", Prefix: "blobs/"}
+	fake := &fakeS3{objects: map[string][]byte{}, cfg: cfg, now: fixed}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	cfg.Endpoint = srv.URL
+	st, err := NewS3BlobStore(cfg, srv.Client())
+	if err != nil {
+		t.Fatalf("new s3 store: %v", err)
+	}
+	st.(*s3BlobStore).now = func() time.Time { return fixed }
+	return fake, st
+}
+
+func TestS3BlobStore_PutGetDelete(t *testing.T) {
+	fake, st := newFakeS3Store(t)
+	ctx := context.Background()
+
+	assert.NoError(t, st.Put(ctx, "id-1", []byte("cipher")))
+	assert.Equal(t, []byte("cipher"), fake.objects["/gk/blobs/id-1"])
+
+	got, err := st.Get(ctx, "id-1")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("cipher"), got)
+
+	assert.NoError(t, st.Delete(ctx, "id-1"))
+	_, err = st.Get(ctx, "id-1")
+	assert.ErrorIs(t, err, ErrBlobNotFound)
+
+	assert.Error(t, st.Put(ctx, "../x", []byte{1}))
+}
+
+func TestS3BlobStore_BadSignatureSurfacesError(t *testing.T) {
+	fake, st := newFakeS3Store(t)
+	// сервер ожидает другой секрет — подпись не совпадёт
+	fake.cfg.SecretKey = "other"
+
+	err := st.Put(context.Background(), "id-2", []byte{1})
+	if assert.Error(t, err) {
+		assert.True(t, strings.Contains(err.Error(), "403"), err.Error())
+	}
+}
+
+func TestNewS3BlobStore_Validation(t *testing.T) {
+	_, err := NewS3BlobStore(S3Config{Bucket: "b", AccessKey: "a", SecretKey: "s"}, nil)
+	assert.Error(t, err)
+	_, err = NewS3BlobStore(S3Config{Endpoint: "http://x", Bucket: "b"}, nil)
+	assert.Error(t, err)
+	_, err = NewS3BlobStore(S3Config{Endpoint: "ftp://x", Bucket: "b", AccessKey: "a", SecretKey: "s"}, nil)
+	assert.Error(t, err)
+
+	st, err := NewS3BlobStore(S3Config{Endpoint: "http://x/", Bucket: "b", AccessKey: "a", SecretKey: "s"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "us-east-1", st.(*s3BlobStore).cfg.Region)
+}
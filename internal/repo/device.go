@@ -0,0 +1,65 @@
+package repo
+
+import (
+	"GophKeeper/internal/model"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrDeviceOwnedByOther — сертификат уже привязан к устройству другого пользователя.
+var ErrDeviceOwnedByOther = errors.New("device belongs to another user")
+
+// DeviceRepository — устройства пользователей, подтверждённые клиентскими сертификатами.
+type DeviceRepository interface {
+	// Bind привязывает отпечаток сертификата к пользователю (или отмечает вход
+	// с уже привязанного устройства). Чужой отпечаток — ErrDeviceOwnedByOther.
+	Bind(ctx context.Context, userID int64, fingerprint, name string) (*model.Device, error)
+
+	// ListByUser возвращает устройства пользователя, старые первыми.
+	ListByUser(ctx context.Context, userID int64) ([]model.Device, error)
+}
+
+type deviceRepo struct {
+	db *gorm.DB
+}
+
+// NewDeviceRepository создаёт реализацию репозитория устройств.
+func NewDeviceRepository(db *gorm.DB) DeviceRepository {
+	return &deviceRepo{db: db}
+}
+
+func (r *deviceRepo) Bind(ctx context.Context, userID int64, fingerprint, name string) (*model.Device, error) {
+	now := time.Now().UTC()
+	// Уникальный индекс по отпечатку решает гонку двух пользователей за один сертификат
+	d := &model.Device{UserID: userID, Fingerprint: fingerprint, Name: name, LastSeenAt: now}
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "fingerprint"}},
+		DoNothing: true,
+	}).Create(d).Error; err != nil {
+		return nil, err
+	}
+
+	var got model.Device
+	if err := r.db.WithContext(ctx).Where("fingerprint = ?", fingerprint).First(&got).Error; err != nil {
+		return nil, err
+	}
+	if got.UserID != userID {
+		return nil, ErrDeviceOwnedByOther
+	}
+	if !got.LastSeenAt.Equal(now) {
+		if err := r.db.WithContext(ctx).Model(&got).Update("last_seen_at", now).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &got, nil
+}
+
+func (r *deviceRepo) ListByUser(ctx context.Context, userID int64) ([]model.Device, error) {
+	var out []model.Device
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id asc").Find(&out).Error
+	return out, err
+}
@@ -0,0 +1,52 @@
+package repo
+
+import (
+	"GophKeeper/internal/model"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceRepository_Bind(t *testing.T) {
+	db := newFileDB(t)
+	ctx := context.Background()
+	m, err := NewMigrator(db)
+	require.NoError(t, err)
+	_, err = m.Up(ctx)
+	require.NoError(t, err)
+
+	users := NewUserRepository(db)
+	alice, err := users.CreateUser(ctx, &model.User{Login: "alice", Password: "h"})
+	require.NoError(t, err)
+	bob, err := users.CreateUser(ctx, &model.User{Login: "bob", Password: "h"})
+	require.NoError(t, err)
+
+	r := NewDeviceRepository(db)
+	d, err := r.Bind(ctx, alice.ID, "fp-laptop", "laptop")
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, d.UserID)
+	firstSeen := d.LastSeenAt
+
+	// повторный вход с того же устройства — та же запись, обновлён last_seen_at
+	again, err := r.Bind(ctx, alice.ID, "fp-laptop", "laptop")
+	require.NoError(t, err)
+	assert.Equal(t, d.ID, again.ID)
+	assert.False(t, again.LastSeenAt.Before(firstSeen))
+
+	// чужой сертификат не привязывается
+	_, err = r.Bind(ctx, bob.ID, "fp-laptop", "laptop")
+	assert.ErrorIs(t, err, ErrDeviceOwnedByOther)
+
+	_, err = r.Bind(ctx, alice.ID, "fp-phone", "phone")
+	require.NoError(t, err)
+	list, err := r.ListByUser(ctx, alice.ID)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "laptop", list[0].Name)
+	assert.Equal(t, "phone", list[1].Name)
+	list, err = r.ListByUser(ctx, bob.ID)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
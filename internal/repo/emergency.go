@@ -0,0 +1,128 @@
+package repo
+
+import (
+	"GophKeeper/internal/model"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrEmergencyNotFound — пользователь не назначен экстренным контактом владельца.
+var ErrEmergencyNotFound = errors.New("emergency access not found")
+
+// EmergencyRepository — экстренный доступ доверенных контактов к хранилищам владельцев.
+type EmergencyRepository interface {
+	// User возвращает id и открытый ключ пользователя login; ключ nil, если он не
+	// опубликован (ErrRecipientNotFound — пользователя нет).
+	User(ctx context.Context, login string) (int64, []byte, error)
+
+	// Save назначает контакт или заменяет прежнее назначение вместе с ключом и состоянием.
+	Save(ctx context.Context, e *model.EmergencyAccess) error
+
+	// Get возвращает экстренный доступ контакта к хранилищу владельца (ErrEmergencyNotFound).
+	Get(ctx context.Context, ownerID, contactID int64) (*model.EmergencyAccess, error)
+
+	// SetStatus меняет состояние и время запроса (ErrEmergencyNotFound).
+	SetStatus(ctx context.Context, ownerID, contactID int64, status string, requestedAt *time.Time) error
+
+	// Delete отзывает экстренный доступ (ErrEmergencyNotFound).
+	Delete(ctx context.Context, ownerID, contactID int64) error
+
+	// ListByOwner возвращает контакты владельца по логину контакта.
+	ListByOwner(ctx context.Context, ownerID int64) ([]model.EmergencyContact, error)
+
+	// ListByContact возвращает владельцев, назначивших пользователя контактом, по логину владельца.
+	ListByContact(ctx context.Context, contactID int64) ([]model.EmergencyContact, error)
+}
+
+type emergencyRepo struct {
+	db *gorm.DB
+}
+
+// NewEmergencyRepository создаёт реализацию репозитория экстренного доступа.
+func NewEmergencyRepository(db *gorm.DB) EmergencyRepository {
+	return &emergencyRepo{db: db}
+}
+
+func (r *emergencyRepo) User(ctx context.Context, login string) (int64, []byte, error) {
+	id, pub, err := NewShareRepository(r.db).Recipient(ctx, login)
+	if !errors.Is(err, ErrRecipientHasNoKey) {
+		return id, pub, err
+	}
+	u, err := NewUserRepository(r.db).GetUserByLogin(ctx, login)
+	if err != nil {
+		return 0, nil, err
+	}
+	return u.ID, nil, nil
+}
+
+func (r *emergencyRepo) Save(ctx context.Context, e *model.EmergencyAccess) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "owner_id"}, {Name: "contact_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"wait_days", "status", "requested_at", "ephemeral_key", "wrapped_key", "wrapped_key_nonce", "updated_at",
+		}),
+	}).Create(e).Error
+}
+
+func (r *emergencyRepo) Get(ctx context.Context, ownerID, contactID int64) (*model.EmergencyAccess, error) {
+	var e model.EmergencyAccess
+	err := r.db.WithContext(ctx).Where("owner_id = ? AND contact_id = ?", ownerID, contactID).First(&e).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrEmergencyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (r *emergencyRepo) SetStatus(ctx context.Context, ownerID, contactID int64, status string, requestedAt *time.Time) error {
+	res := r.db.WithContext(ctx).Model(&model.EmergencyAccess{}).
+		Where("owner_id = ? AND contact_id = ?", ownerID, contactID).
+		Updates(map[string]any{"status": status, "requested_at": requestedAt, "updated_at": time.Now().UTC()})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrEmergencyNotFound
+	}
+	return nil
+}
+
+func (r *emergencyRepo) Delete(ctx context.Context, ownerID, contactID int64) error {
+	res := r.db.WithContext(ctx).
+		Where("owner_id = ? AND contact_id = ?", ownerID, contactID).
+		Delete(&model.EmergencyAccess{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrEmergencyNotFound
+	}
+	return nil
+}
+
+func (r *emergencyRepo) ListByOwner(ctx context.Context, ownerID int64) ([]model.EmergencyContact, error) {
+	return r.list(ctx, "emergency_accesses.owner_id = ?", ownerID, "contacts.login asc")
+}
+
+func (r *emergencyRepo) ListByContact(ctx context.Context, contactID int64) ([]model.EmergencyContact, error) {
+	return r.list(ctx, "emergency_accesses.contact_id = ?", contactID, "owners.login asc")
+}
+
+func (r *emergencyRepo) list(ctx context.Context, where string, id int64, order string) ([]model.EmergencyContact, error) {
+	var out []model.EmergencyContact
+	err := r.db.WithContext(ctx).
+		Table("emergency_accesses").
+		Select("emergency_accesses.*, owners.login AS owner_login, contacts.login AS contact_login").
+		Joins("JOIN users owners ON owners.id = emergency_accesses.owner_id").
+		Joins("JOIN users contacts ON contacts.id = emergency_accesses.contact_id").
+		Where(where, id).
+		Order(order).
+		Scan(&out).Error
+	return out, err
+}
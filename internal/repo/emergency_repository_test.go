@@ -0,0 +1,85 @@
+package repo
+
+import (
+	"GophKeeper/internal/model"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmergencyRepository(t *testing.T) {
+	db := newFileDB(t)
+	ctx := context.Background()
+	m, err := NewMigrator(db)
+	require.NoError(t, err)
+	_, err = m.Up(ctx)
+	require.NoError(t, err)
+
+	users := NewUserRepository(db)
+	alice, err := users.CreateUser(ctx, &model.User{Login: "alice", Password: "h"})
+	require.NoError(t, err)
+	bob, err := users.CreateUser(ctx, &model.User{Login: "bob", Password: "h"})
+	require.NoError(t, err)
+	carol, err := users.CreateUser(ctx, &model.User{Login: "carol", Password: "h"})
+	require.NoError(t, err)
+	require.NoError(t, NewKeyRepository(db).SaveKeys(ctx, &model.UserKeys{UserID: bob.ID, PublicKey: []byte("pub-bob"), WrappedPrivateKey: []byte("w"), PrivateKeyNonce: []byte("n")}))
+
+	r := NewEmergencyRepository(db)
+	id, pub, err := r.User(ctx, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, bob.ID, id)
+	assert.Equal(t, []byte("pub-bob"), pub)
+	id, pub, err = r.User(ctx, "carol")
+	require.NoError(t, err)
+	assert.Equal(t, carol.ID, id)
+	assert.Nil(t, pub)
+	_, _, err = r.User(ctx, "dave")
+	assert.ErrorIs(t, err, ErrRecipientNotFound)
+
+	grant := func(owner, contact int64, key string) *model.EmergencyAccess {
+		return &model.EmergencyAccess{OwnerID: owner, ContactID: contact, WaitDays: 7, Status: model.EmergencyGranted,
+			EphemeralKey: []byte("e"), WrappedKey: []byte(key), WrappedKeyNonce: []byte("n")}
+	}
+	require.NoError(t, r.Save(ctx, grant(alice.ID, bob.ID, "k1")))
+	require.NoError(t, r.Save(ctx, grant(carol.ID, bob.ID, "k2")))
+	_, err = r.Get(ctx, bob.ID, alice.ID)
+	assert.ErrorIs(t, err, ErrEmergencyNotFound)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, r.SetStatus(ctx, alice.ID, bob.ID, model.EmergencyRequested, &now))
+	assert.ErrorIs(t, r.SetStatus(ctx, bob.ID, alice.ID, model.EmergencyApproved, nil), ErrEmergencyNotFound)
+	e, err := r.Get(ctx, alice.ID, bob.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.EmergencyRequested, e.Status)
+	require.NotNil(t, e.RequestedAt)
+	assert.True(t, now.Equal(*e.RequestedAt))
+
+	// повторное назначение заменяет ключ и сбрасывает запрос
+	g := grant(alice.ID, bob.ID, "k3")
+	g.WaitDays = 3
+	require.NoError(t, r.Save(ctx, g))
+	e, err = r.Get(ctx, alice.ID, bob.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("k3"), e.WrappedKey)
+	assert.Equal(t, 3, e.WaitDays)
+	assert.Equal(t, model.EmergencyGranted, e.Status)
+	assert.Nil(t, e.RequestedAt)
+
+	owners, err := r.ListByContact(ctx, bob.ID)
+	require.NoError(t, err)
+	require.Len(t, owners, 2)
+	assert.Equal(t, "alice", owners[0].OwnerLogin)
+	assert.Equal(t, "bob", owners[0].ContactLogin)
+	assert.Equal(t, "carol", owners[1].OwnerLogin)
+	contacts, err := r.ListByOwner(ctx, alice.ID)
+	require.NoError(t, err)
+	require.Len(t, contacts, 1)
+	assert.Equal(t, "bob", contacts[0].ContactLogin)
+	assert.Equal(t, []byte("k3"), contacts[0].WrappedKey)
+
+	require.NoError(t, r.Delete(ctx, alice.ID, bob.ID))
+	assert.ErrorIs(t, r.Delete(ctx, alice.ID, bob.ID), ErrEmergencyNotFound)
+}
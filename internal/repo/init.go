@@ -1,26 +1,97 @@
 package repo
 
 import (
-	"GophKeeper/internal/model"
+	"context"
 	"fmt"
+	"strings"
 
 	"gorm.io/driver/postgres"
+	gormsqlite "gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	_ "modernc.org/sqlite"
 )
 
-// InitDB подключается к БД, выполняет миграции и возвращает *gorm.DB
-func InitDB(dsn string) (*gorm.DB, error) {
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+// SQLiteScheme — префикс DATABASE_URI для встроенной БД SQLite: sqlite://<путь к файлу>.
+const SQLiteScheme = "sqlite://"
+
+// sqlitePragmas — параметры подключения SQLite: WAL и ожидание блокировки вместо
+// мгновенного SQLITE_BUSY, а транзакции сразу берут блокировку на запись, чтобы
+// параллельные записи ждали друг друга, а не падали при повышении блокировки.
+var sqlitePragmas = []string{
+	"_pragma=busy_timeout(5000)",
+	"_pragma=journal_mode(WAL)",
+	"_pragma=foreign_keys(1)",
+	"_txlock=immediate",
+}
+
+// OpenDB подключается к БД без проверки схемы (для gkserver migrate).
+// dsn — строка подключения PostgreSQL или sqlite://<путь> для SQLite.
+func OpenDB(dsn string) (*gorm.DB, error) {
+	db, err := gorm.Open(dialector(dsn), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Silent),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("gorm open: %w", err)
 	}
+	if err := db.Use(tracingPlugin{}); err != nil {
+		return nil, fmt.Errorf("gorm tracing: %w", err)
+	}
+	return db, nil
+}
 
-	if err := db.AutoMigrate(&model.User{}, &model.Blob{}, &model.Item{}); err != nil {
-		return nil, fmt.Errorf("auto-migrate: %w", err)
+// InitDB подключается к БД, проверяет, что все миграции применены, и возвращает *gorm.DB.
+// Схему меняет только gkserver migrate: при устаревшей схеме возвращается ErrSchemaOutdated.
+func InitDB(dsn string) (*gorm.DB, error) {
+	db, err := OpenDB(dsn)
+	if err != nil {
+		return nil, err
+	}
+	m, err := NewMigrator(db)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.RequireCurrent(context.Background()); err != nil {
+		return nil, err
+	}
+	if err := BackfillChangeSeq(context.Background(), db); err != nil {
+		return nil, err
 	}
 
 	return db, nil
 }
+
+// dialector выбирает драйвер по схеме dsn.
+func dialector(dsn string) gorm.Dialector {
+	if path, ok := strings.CutPrefix(dsn, SQLiteScheme); ok {
+		return gormsqlite.Dialector{DriverName: "sqlite", DSN: sqliteDSN(path)}
+	}
+	return postgres.Open(dsn)
+}
+
+// sqliteDSN строит DSN modernc.org/sqlite; собственные параметры пути сохраняются.
+func sqliteDSN(path string) string {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	return "file:" + path + sep + strings.Join(sqlitePragmas, "&")
+}
+
+// ReadinessCheck возвращает проверку готовности БД: соединение отвечает и все миграции применены.
+func ReadinessCheck(db *gorm.DB) (func(ctx context.Context) error, error) {
+	m, err := NewMigrator(db)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		if err := sqlDB.PingContext(ctx); err != nil {
+			return fmt.Errorf("ping database: %w", err)
+		}
+		return m.RequireCurrent(ctx)
+	}, nil
+}
@@ -0,0 +1,119 @@
+package repo
+
+import (
+	"GophKeeper/internal/model"
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialector(t *testing.T) {
+	assert.Equal(t, "postgres", dialector("postgres://u:p@localhost/gk").Name())
+	assert.Equal(t, "sqlite", dialector("sqlite:///tmp/gk.db").Name())
+
+	assert.Equal(t, "file:/tmp/gk.db?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)&_txlock=immediate",
+		sqliteDSN("/tmp/gk.db"))
+	assert.Equal(t, "file:gk.db?mode=rwc&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)&_txlock=immediate",
+		sqliteDSN("gk.db?mode=rwc"))
+}
+
+// Репозитории поверх sqlite:// ведут себя так же, как на PostgreSQL.
+func TestInitDB_SQLite(t *testing.T) {
+	dsn := SQLiteScheme + filepath.Join(t.TempDir(), "gk.db")
+	ctx := context.Background()
+	// без миграций сервер не стартует
+	_, err := InitDB(dsn)
+	require.ErrorIs(t, err, ErrSchemaOutdated)
+	migrateUp(t, dsn)
+	db, err := InitDB(dsn)
+	require.NoError(t, err)
+
+	u, err := NewUserRepository(db).CreateUser(ctx, &model.User{Login: "alice", Password: "h"})
+	require.NoError(t, err)
+	require.NotZero(t, u.ID)
+
+	// OnConflict DoNothing: повторная вставка блоба не ошибка и не перезапись
+	blobs := NewBlobRepository(db)
+	blobID := uuid.NewString()
+	created, err := blobs.CreateIfAbsent(ctx, u.ID, blobID, []byte{1}, 10)
+	require.NoError(t, err)
+	assert.True(t, created)
+	created, err = blobs.CreateIfAbsent(ctx, u.ID, blobID, []byte{2}, 20)
+	require.NoError(t, err)
+	assert.False(t, created)
+	total, err := blobs.SumSizeByUser(ctx, u.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), total)
+
+	// UUID-столбцы хранят строковые идентификаторы без преобразований
+	items := NewItemRepository(db)
+	id := uuid.NewString()
+	it := model.Item{ID: id, UserID: u.ID, Version: 1, BlobID: &blobID}
+	require.NoError(t, items.Create(ctx, &it))
+	got, err := items.GetByID(ctx, u.ID, id)
+	require.NoError(t, err)
+	assert.Equal(t, id, got.ID)
+	require.NotNil(t, got.BlobID)
+	assert.Equal(t, blobID, *got.BlobID)
+
+	// параллельные записи ждут блокировку, а не падают с SQLITE_BUSY
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			it := model.Item{ID: uuid.NewString(), UserID: u.ID, Version: 1}
+			if err := items.Create(ctx, &it); err != nil {
+				errs <- err
+				return
+			}
+			if _, err := items.UpdateWithVersion(ctx, u.ID, it.ID, 1, map[string]any{"name": fmt.Sprint(i)}); err != nil {
+				errs <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	changed, err := items.GetItemsChangedSince(ctx, u.ID, 0, 100)
+	require.NoError(t, err)
+	assert.Len(t, changed, 9)
+	seen := map[int64]bool{}
+	for _, c := range changed {
+		assert.False(t, seen[c.ChangeSeq], "номера изменений уникальны")
+		seen[c.ChangeSeq] = true
+	}
+
+	// данные переживают переподключение
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	require.NoError(t, sqlDB.Close())
+	db, err = InitDB(dsn)
+	require.NoError(t, err)
+	count, err := NewItemRepository(db).CountByUser(ctx, u.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(9), count)
+}
+
+func migrateUp(t *testing.T, dsn string) {
+	t.Helper()
+	db, err := OpenDB(dsn)
+	require.NoError(t, err)
+	m, err := NewMigrator(db)
+	require.NoError(t, err)
+	_, err = m.Up(context.Background())
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	require.NoError(t, sqlDB.Close())
+}
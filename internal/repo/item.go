@@ -3,37 +3,81 @@ package repo
 import (
 	"GophKeeper/internal/model"
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrItemExists — запись с таким id уже есть, в том числе у другого пользователя.
+var ErrItemExists = errors.New("item already exists")
+
 // ItemRepository определяет минимальный контракт доступа к Item для слоя сервиса.
 type ItemRepository interface {
 	// GetItemsUpdatedSince возвращает элементы пользователя, изменённые после указанного времени.
 	GetItemsUpdatedSince(ctx context.Context, userID int64, since time.Time) ([]model.Item, error)
 
+	// GetItemsChangedSince возвращает не более limit элементов пользователя (limit <= 0 — все)
+	// с номером изменения строго больше cursor, упорядоченные по номеру изменения.
+	GetItemsChangedSince(ctx context.Context, userID int64, cursor int64, limit int) ([]model.Item, error)
+
 	// GetByID возвращает элемент по id и userID.
 	GetByID(ctx context.Context, userID int64, id string) (*model.Item, error)
 
-	// Create вставляет новую запись.
+	// Create вставляет новую запись, назначая ей очередной номер изменения.
+	// Занятый id — ErrItemExists.
 	Create(ctx context.Context, it *model.Item) error
 
 	// UpdateWithVersion выполняет обновление c проверкой версии (OCC):
-	// WHERE id=? AND user_id=? AND version=?; увеличивает версию на 1 и возвращает новое значение версии.
+	// WHERE id=? AND user_id=? AND version=?; увеличивает версию на 1, назначает очередной
+	// номер изменения и возвращает новое значение версии. Обновление, которое ничего
+	// не меняет, не трогает запись и возвращает expectedVersion.
 	UpdateWithVersion(ctx context.Context, userID int64, id string, expectedVersion int64, updates map[string]any) (int64, error)
 
 	// ListAll возвращает все элементы пользователя (для вычисления missing_items).
 	ListAll(ctx context.Context, userID int64) ([]model.Item, error)
+
+	// CountByUser возвращает количество неудалённых элементов пользователя.
+	CountByUser(ctx context.Context, userID int64) (int64, error)
+
+	// ListVersions возвращает сохранённые предыдущие версии элемента (новые первыми).
+	ListVersions(ctx context.Context, userID int64, id string) ([]model.ItemVersion, error)
+
+	// GetVersion возвращает снимок элемента указанной версии.
+	GetVersion(ctx context.Context, userID int64, id string, version int64) (*model.ItemVersion, error)
+
+	// LatestChangeSeq возвращает номер последнего изменения пользователя (0 — изменений не было).
+	LatestChangeSeq(ctx context.Context, userID int64) (int64, error)
+
+	// Transaction выполняет fn в одной транзакции БД: все операции репозитория tx
+	// фиксируются вместе, если fn вернула nil, иначе откатываются.
+	Transaction(ctx context.Context, fn func(tx ItemRepository) error) error
+}
+
+// HistoryRetention ограничивает историю версий одного элемента (0 — без ограничения).
+type HistoryRetention struct {
+	MaxVersions int
+	MaxAge      time.Duration
 }
 
+// DefaultHistoryRetention — ограничения истории по умолчанию.
+var DefaultHistoryRetention = HistoryRetention{MaxVersions: 20}
+
 type itemRepo struct {
-	db *gorm.DB
+	db      *gorm.DB
+	history HistoryRetention
 }
 
-// NewItemRepository создаёт реализацию репозитория для Item.
+// NewItemRepository создаёт реализацию репозитория для Item с ограничениями истории по умолчанию.
 func NewItemRepository(db *gorm.DB) ItemRepository {
-	return &itemRepo{db: db}
+	return NewItemRepositoryWithHistory(db, DefaultHistoryRetention)
+}
+
+// NewItemRepositoryWithHistory создаёт репозиторий Item с заданными ограничениями истории версий.
+func NewItemRepositoryWithHistory(db *gorm.DB, history HistoryRetention) ItemRepository {
+	return &itemRepo{db: db, history: history}
 }
 
 // GetItemsUpdatedSince возвращает элементы, обновлённые после времени since.
@@ -49,6 +93,22 @@ func (r *itemRepo) GetItemsUpdatedSince(ctx context.Context, userID int64, since
 	return items, nil
 }
 
+// GetItemsChangedSince возвращает страницу элементов с change_seq > cursor.
+func (r *itemRepo) GetItemsChangedSince(ctx context.Context, userID int64, cursor int64, limit int) ([]model.Item, error) {
+	var items []model.Item
+	q := r.db.WithContext(ctx).
+		Where("user_id = ? AND change_seq > ?", userID, cursor).
+		Order("change_seq asc")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	err := q.Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 // GetByID возвращает элемент по идентификатору и пользователю.
 func (r *itemRepo) GetByID(ctx context.Context, userID int64, id string) (*model.Item, error) {
 	var it model.Item
@@ -61,33 +121,227 @@ func (r *itemRepo) GetByID(ctx context.Context, userID int64, id string) (*model
 
 // Create создаёт новую запись Item.
 func (r *itemRepo) Create(ctx context.Context, it *model.Item) error {
-	return r.db.WithContext(ctx).Create(it).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var n int64
+		if err := tx.Model(&model.Item{}).Where("id = ?", it.ID).Count(&n).Error; err != nil {
+			return err
+		}
+		if n > 0 {
+			return ErrItemExists
+		}
+		seq, err := nextChangeSeq(tx, it.UserID)
+		if err != nil {
+			return err
+		}
+		it.ChangeSeq = seq
+		if it.FieldVersions == nil {
+			it.FieldVersions = model.FieldVersions{}
+			for _, f := range model.FieldGroups {
+				it.FieldVersions[f] = it.Version
+			}
+		}
+		return tx.Create(it).Error
+	})
+}
+
+// LatestChangeSeq читает счётчик изменений пользователя.
+func (r *itemRepo) LatestChangeSeq(ctx context.Context, userID int64) (int64, error) {
+	var seqs []int64
+	err := r.db.WithContext(ctx).Model(&model.UserChangeSeq{}).
+		Where("user_id = ?", userID).
+		Pluck("seq", &seqs).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(seqs) == 0 {
+		return 0, nil
+	}
+	return seqs[0], nil
+}
+
+// Transaction выполняет fn с репозиторием, привязанным к транзакции.
+// Транзакции операций внутри fn становятся точками сохранения общей транзакции.
+func (r *itemRepo) Transaction(ctx context.Context, fn func(tx ItemRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&itemRepo{db: tx, history: r.history})
+	})
+}
+
+// BackfillChangeSeq назначает номера изменений записям, созданным до появления
+// курсора синхронизации (change_seq = 0), чтобы постраничная выдача их не пропускала.
+func BackfillChangeSeq(ctx context.Context, db *gorm.DB) error {
+	var userIDs []int64
+	if err := db.WithContext(ctx).Model(&model.Item{}).
+		Where("change_seq = ?", 0).
+		Distinct().Pluck("user_id", &userIDs).Error; err != nil {
+		return err
+	}
+	for _, userID := range userIDs {
+		err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var ids []string
+			if err := tx.Model(&model.Item{}).
+				Where("user_id = ? AND change_seq = ?", userID, 0).
+				Order("updated_at asc, id asc").
+				Pluck("id", &ids).Error; err != nil {
+				return err
+			}
+			for _, id := range ids {
+				seq, err := nextChangeSeq(tx, userID)
+				if err != nil {
+					return err
+				}
+				if err := tx.Model(&model.Item{}).
+					Where("id = ? AND user_id = ?", id, userID).
+					UpdateColumn("change_seq", seq).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("backfill change_seq for user %d: %w", userID, err)
+		}
+	}
+	return nil
+}
+
+// nextChangeSeq выдаёт следующий номер изменения пользователя. Строка счётчика
+// остаётся заблокированной до конца транзакции, поэтому записи одного пользователя
+// фиксируются в порядке своих номеров и курсор не пропускает изменения.
+func nextChangeSeq(tx *gorm.DB, userID int64) (int64, error) {
+	var seq int64
+	err := tx.Raw(`INSERT INTO user_change_seqs (user_id, seq) VALUES (?, 1)
+		ON CONFLICT (user_id) DO UPDATE SET seq = user_change_seqs.seq + 1
+		RETURNING seq`, userID).Scan(&seq).Error
+	if err != nil {
+		return 0, err
+	}
+	return seq, nil
 }
 
 // UpdateWithVersion обновляет запись с проверкой версии.
+// В той же транзакции прежнее состояние записи сохраняется в item_versions,
+// а история сокращается согласно ограничениям. Если updates не меняют ни одного
+// столбца, запись, её история и номер изменения остаются прежними, а возвращается
+// expectedVersion.
 func (r *itemRepo) UpdateWithVersion(ctx context.Context, userID int64, id string, expectedVersion int64, updates map[string]any) (int64, error) {
-	// Принудительно выставим updated_at и инкрементируем версию
 	now := time.Now().UTC()
-	if updates == nil {
-		updates = map[string]any{}
+	newVersion := expectedVersion + 1
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var prev model.Item
+		if err := tx.Where("id = ? AND user_id = ? AND version = ?", id, userID, expectedVersion).
+			First(&prev).Error; err != nil {
+			// версия не совпала или записи нет
+			return err
+		}
+		if !changesItem(&prev, updates) {
+			newVersion = expectedVersion
+			return nil
+		}
+		// Принудительно выставим updated_at и инкрементируем версию
+		updates["updated_at"] = now
+		updates["version"] = newVersion
+
+		seq, err := nextChangeSeq(tx, userID)
+		if err != nil {
+			return err
+		}
+		updates["change_seq"] = seq
+		updates["field_versions"] = changedFieldVersions(&prev, updates, newVersion)
+
+		snap := snapshotOf(&prev)
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&snap).Error; err != nil {
+			return err
+		}
+
+		res := tx.Model(&model.Item{}).
+			Where("id = ? AND user_id = ? AND version = ?", id, userID, expectedVersion).
+			Updates(updates)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return r.pruneHistory(tx, userID, id, now)
+	})
+	if err != nil {
+		return 0, err
 	}
-	updates["updated_at"] = now
+	return newVersion, nil
+}
 
-	newVersion := expectedVersion + 1
-	updates["version"] = newVersion
+// changesItem сообщает, меняет ли хотя бы один столбец updates значение записи prev.
+func changesItem(prev *model.Item, updates map[string]any) bool {
+	for col, val := range updates {
+		if !prev.ColumnEquals(col, val) {
+			return true
+		}
+	}
+	return false
+}
 
-	tx := r.db.WithContext(ctx).Model(&model.Item{}).
-		Where("id = ? AND user_id = ? AND version = ?", id, userID, expectedVersion).
-		Updates(updates)
+// changedFieldVersions отмечает группы полей, значения которых действительно меняются, новой версией.
+func changedFieldVersions(prev *model.Item, updates map[string]any, newVersion int64) model.FieldVersions {
+	fv := model.FieldVersions{}
+	for f, v := range prev.FieldVersions {
+		fv[f] = v
+	}
+	for col, val := range updates {
+		f := model.FieldOfColumn(col)
+		if f != "" && !prev.ColumnEquals(col, val) {
+			fv[f] = newVersion
+		}
+	}
+	return fv
+}
 
-	if tx.Error != nil {
-		return 0, tx.Error
+// pruneHistory удаляет версии сверх MaxVersions и старше MaxAge.
+func (r *itemRepo) pruneHistory(tx *gorm.DB, userID int64, id string, now time.Time) error {
+	if r.history.MaxAge > 0 {
+		if err := tx.Where("item_id = ? AND user_id = ? AND created_at < ?", id, userID, now.Add(-r.history.MaxAge)).
+			Delete(&model.ItemVersion{}).Error; err != nil {
+			return err
+		}
 	}
-	if tx.RowsAffected == 0 {
-		// версия не совпала или записи нет
-		return 0, gorm.ErrRecordNotFound
+	if r.history.MaxVersions > 0 {
+		var keep []int64
+		if err := tx.Model(&model.ItemVersion{}).
+			Where("item_id = ? AND user_id = ?", id, userID).
+			Order("version desc").Limit(r.history.MaxVersions).
+			Pluck("version", &keep).Error; err != nil {
+			return err
+		}
+		if len(keep) == r.history.MaxVersions {
+			if err := tx.Where("item_id = ? AND user_id = ? AND version < ?", id, userID, keep[len(keep)-1]).
+				Delete(&model.ItemVersion{}).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func snapshotOf(it *model.Item) model.ItemVersion {
+	return model.ItemVersion{
+		ItemID:         it.ID,
+		UserID:         it.UserID,
+		Version:        it.Version,
+		Deleted:        it.Deleted,
+		Name:           it.Name,
+		FileName:       it.FileName,
+		BlobID:         it.BlobID,
+		LoginCipher:    it.LoginCipher,
+		LoginNonce:     it.LoginNonce,
+		PasswordCipher: it.PasswordCipher,
+		PasswordNonce:  it.PasswordNonce,
+		TextCipher:     it.TextCipher,
+		TextNonce:      it.TextNonce,
+		CardCipher:     it.CardCipher,
+		CardNonce:      it.CardNonce,
+		ItemUpdatedAt:  it.UpdatedAt,
 	}
-	return newVersion, nil
 }
 
 // ListAll возвращает все элементы пользователя.
@@ -102,3 +356,40 @@ func (r *itemRepo) ListAll(ctx context.Context, userID int64) ([]model.Item, err
 	}
 	return items, nil
 }
+
+// CountByUser считает неудалённые элементы пользователя.
+func (r *itemRepo) CountByUser(ctx context.Context, userID int64) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Model(&model.Item{}).
+		Where("user_id = ? AND deleted = ?", userID, false).
+		Count(&n).Error
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// ListVersions возвращает историю версий элемента, новые первыми.
+func (r *itemRepo) ListVersions(ctx context.Context, userID int64, id string) ([]model.ItemVersion, error) {
+	var versions []model.ItemVersion
+	err := r.db.WithContext(ctx).
+		Where("item_id = ? AND user_id = ?", id, userID).
+		Order("version desc").
+		Find(&versions).Error
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// GetVersion возвращает снимок версии элемента.
+func (r *itemRepo) GetVersion(ctx context.Context, userID int64, id string, version int64) (*model.ItemVersion, error) {
+	var v model.ItemVersion
+	err := r.db.WithContext(ctx).
+		Where("item_id = ? AND user_id = ? AND version = ?", id, userID, version).
+		First(&v).Error
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
@@ -41,6 +41,10 @@ func TestItemRepository_Create_GetByID(t *testing.T) {
 	got, err = r.GetByID(ctx, 999, "i1")
 	assert.Nil(t, got)
 	assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+	// id уже занят, в том числе записью другого пользователя
+	dup := mkItem("i1", 999, 1, time.Now().UTC())
+	assert.ErrorIs(t, r.Create(ctx, &dup), ErrItemExists)
 }
 
 func TestItemRepository_UpdateWithVersion_SuccessAndConflict(t *testing.T) {
@@ -116,21 +120,289 @@ func TestItemRepository_ListAll_And_GetItemsUpdatedSince(t *testing.T) {
 	assert.Empty(t, none)
 }
 
-// точечный тест: UpdateWithVersion с updates=nil (должен инкрементнуть версию и обновить updated_at)
-func TestItemRepository_UpdateWithVersion_NilUpdates(t *testing.T) {
+// точечный тест: UpdateWithVersion без фактических изменений не трогает запись и историю
+func TestItemRepository_UpdateWithVersion_NoopUpdates(t *testing.T) {
 	db := newTestDB(t)
 	r := NewItemRepository(db)
 	ctx := context.Background()
 
 	base := mkItem("i3", 5, 7, time.Now().UTC().Add(-time.Minute))
+	base.Name = "n"
+	base.TextCipher = []byte{1}
 	assert.NoError(t, r.Create(ctx, &base))
 
-	newVer, err := r.UpdateWithVersion(ctx, 5, "i3", 7, nil)
-	assert.NoError(t, err)
-	assert.Equal(t, int64(8), newVer)
+	for _, updates := range []map[string]any{nil, {"name": "n", "text_cipher": []byte{1}, "blob_id": nil, "deleted": false}} {
+		newVer, err := r.UpdateWithVersion(ctx, 5, "i3", 7, updates)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(7), newVer)
+	}
 
 	got, err := r.GetByID(ctx, 5, "i3")
 	assert.NoError(t, err)
-	assert.Equal(t, int64(8), got.Version)
-	assert.WithinDuration(t, time.Now().UTC(), got.UpdatedAt, 2*time.Second)
+	assert.Equal(t, int64(7), got.Version)
+	assert.Equal(t, base.ChangeSeq, got.ChangeSeq)
+	assert.WithinDuration(t, base.UpdatedAt, got.UpdatedAt, time.Second)
+	versions, err := r.ListVersions(ctx, 5, "i3")
+	assert.NoError(t, err)
+	assert.Empty(t, versions)
+
+	// устаревшая версия — по-прежнему ошибка, даже без изменений
+	_, err = r.UpdateWithVersion(ctx, 5, "i3", 6, nil)
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+}
+
+func TestItemRepository_CountByUser_SkipsDeleted(t *testing.T) {
+	db := newTestDB(t)
+	r := NewItemRepository(db)
+	ctx := context.Background()
+
+	a := mkItem("cnt-a", 321, 1, time.Now())
+	b := mkItem("cnt-b", 321, 1, time.Now())
+	d := mkItem("cnt-d", 321, 1, time.Now())
+	d.Deleted = true
+	for _, it := range []*model.Item{&a, &b, &d} {
+		assert.NoError(t, r.Create(ctx, it))
+	}
+
+	n, err := r.CountByUser(ctx, 321)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	n, err = r.CountByUser(ctx, 322)
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestItemRepository_UpdateWithVersion_KeepsHistory(t *testing.T) {
+	db := newTestDB(t)
+	r := NewItemRepositoryWithHistory(db, HistoryRetention{MaxVersions: 2})
+	ctx := context.Background()
+
+	base := mkItem("hist-1", 55, 1, time.Now().UTC().Add(-time.Hour))
+	base.Name = "v1"
+	base.TextCipher = []byte("c1")
+	assert.NoError(t, r.Create(ctx, &base))
+
+	for i, name := range []string{"v2", "v3", "v4"} {
+		_, err := r.UpdateWithVersion(ctx, 55, "hist-1", int64(i+1), map[string]any{"name": name})
+		assert.NoError(t, err)
+	}
+
+	// хранятся только две последние предыдущие версии
+	versions, err := r.ListVersions(ctx, 55, "hist-1")
+	assert.NoError(t, err)
+	if assert.Len(t, versions, 2) {
+		assert.Equal(t, int64(3), versions[0].Version)
+		assert.Equal(t, "v3", versions[0].Name)
+		assert.Equal(t, int64(2), versions[1].Version)
+		assert.Equal(t, []byte("c1"), versions[1].TextCipher)
+	}
+
+	v, err := r.GetVersion(ctx, 55, "hist-1", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", v.Name)
+	_, err = r.GetVersion(ctx, 55, "hist-1", 1)
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+	_, err = r.GetVersion(ctx, 56, "hist-1", 2)
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+	// неудачное обновление не оставляет снимка
+	_, err = r.UpdateWithVersion(ctx, 55, "hist-1", 1, map[string]any{"name": "stale"})
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+	versions, _ = r.ListVersions(ctx, 55, "hist-1")
+	assert.Len(t, versions, 2)
+}
+
+func TestItemRepository_History_MaxAge(t *testing.T) {
+	db := newTestDB(t)
+	r := NewItemRepositoryWithHistory(db, HistoryRetention{MaxAge: time.Hour})
+	ctx := context.Background()
+
+	base := mkItem("hist-2", 56, 1, time.Now().UTC())
+	assert.NoError(t, r.Create(ctx, &base))
+	_, err := r.UpdateWithVersion(ctx, 56, "hist-2", 1, map[string]any{"name": "a"})
+	assert.NoError(t, err)
+
+	// состарим снимок, следующее обновление должно его удалить
+	assert.NoError(t, db.Model(&model.ItemVersion{}).Where("item_id = ?", "hist-2").
+		Update("created_at", time.Now().UTC().Add(-2*time.Hour)).Error)
+	_, err = r.UpdateWithVersion(ctx, 56, "hist-2", 2, map[string]any{"name": "b"})
+	assert.NoError(t, err)
+
+	versions, err := r.ListVersions(ctx, 56, "hist-2")
+	assert.NoError(t, err)
+	if assert.Len(t, versions, 1) {
+		assert.Equal(t, int64(2), versions[0].Version)
+	}
+}
+
+func TestItemRepository_ChangeSeq_Monotonic(t *testing.T) {
+	db := newTestDB(t)
+	r := NewItemRepository(db)
+	ctx := context.Background()
+
+	// одинаковый updated_at не мешает курсору
+	same := time.Now().UTC().Truncate(time.Second)
+	a := mkItem("seq-a", 77, 1, same)
+	b := mkItem("seq-b", 77, 1, same)
+	other := mkItem("seq-x", 78, 1, same)
+	for _, it := range []*model.Item{&a, &b, &other} {
+		assert.NoError(t, r.Create(ctx, it))
+	}
+	assert.Equal(t, int64(1), a.ChangeSeq)
+	assert.Equal(t, int64(2), b.ChangeSeq)
+	assert.Equal(t, int64(1), other.ChangeSeq) // счётчик свой у каждого пользователя
+
+	_, err := r.UpdateWithVersion(ctx, 77, "seq-a", 1, map[string]any{"name": "x"})
+	assert.NoError(t, err)
+
+	changed, err := r.GetItemsChangedSince(ctx, 77, 2, 0)
+	assert.NoError(t, err)
+	if assert.Len(t, changed, 1) {
+		assert.Equal(t, "seq-a", changed[0].ID)
+		assert.Equal(t, int64(3), changed[0].ChangeSeq)
+	}
+
+	all, err := r.GetItemsChangedSince(ctx, 77, 0, 0)
+	assert.NoError(t, err)
+	if assert.Len(t, all, 2) {
+		assert.Equal(t, "seq-b", all[0].ID)
+		assert.Equal(t, "seq-a", all[1].ID)
+	}
+
+	// страница из одной записи
+	page, err := r.GetItemsChangedSince(ctx, 77, 0, 1)
+	assert.NoError(t, err)
+	if assert.Len(t, page, 1) {
+		assert.Equal(t, "seq-b", page[0].ID)
+	}
+
+	// неудачное обновление не расходует номер
+	_, err = r.UpdateWithVersion(ctx, 77, "seq-a", 1, nil)
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+	c := mkItem("seq-c", 77, 1, same)
+	assert.NoError(t, r.Create(ctx, &c))
+	assert.Equal(t, int64(4), c.ChangeSeq)
+}
+
+func TestBackfillChangeSeq(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	// записи «до курсора»: change_seq = 0, вставлены в обход репозитория
+	old := time.Now().UTC().Add(-time.Hour)
+	for _, it := range []model.Item{mkItem("bf-2", 88, 1, old.Add(time.Minute)), mkItem("bf-1", 88, 1, old)} {
+		assert.NoError(t, db.Create(&it).Error)
+	}
+	assert.NoError(t, BackfillChangeSeq(ctx, db))
+
+	r := NewItemRepository(db)
+	items, err := r.GetItemsChangedSince(ctx, 88, 0, 0)
+	assert.NoError(t, err)
+	if assert.Len(t, items, 2) {
+		assert.Equal(t, "bf-1", items[0].ID)
+		assert.Equal(t, int64(1), items[0].ChangeSeq)
+		assert.Equal(t, "bf-2", items[1].ID)
+		assert.Equal(t, int64(2), items[1].ChangeSeq)
+	}
+
+	// новые записи продолжают последовательность
+	n := mkItem("bf-3", 88, 1, time.Now())
+	assert.NoError(t, r.Create(ctx, &n))
+	assert.Equal(t, int64(3), n.ChangeSeq)
+}
+
+func TestItemRepository_FieldVersions(t *testing.T) {
+	db := newTestDB(t)
+	r := NewItemRepository(db)
+	ctx := context.Background()
+
+	it := mkItem("fv1", 8, 1, time.Now().UTC())
+	it.Name = "a"
+	it.TextCipher = []byte{1}
+	assert.NoError(t, r.Create(ctx, &it))
+
+	got, err := r.GetByID(ctx, 8, "fv1")
+	assert.NoError(t, err)
+	for _, f := range model.FieldGroups {
+		assert.Equal(t, int64(1), got.FieldVersion(f), f)
+	}
+
+	// текст меняется, имя передано тем же значением — версия имени не сдвигается
+	_, err = r.UpdateWithVersion(ctx, 8, "fv1", 1, map[string]any{"name": "a", "text_cipher": []byte{2}})
+	assert.NoError(t, err)
+	got, err = r.GetByID(ctx, 8, "fv1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), got.FieldVersion(model.FieldText))
+	assert.Equal(t, int64(1), got.FieldVersion(model.FieldName))
+	assert.Equal(t, int64(1), got.FieldVersion(model.FieldPassword))
+}
+
+func TestItemRepository_Transaction_CommitAndRollback(t *testing.T) {
+	db := newTestDB(t)
+	r := NewItemRepository(db)
+	ctx := context.Background()
+
+	base := mkItem("t1", 7, 1, time.Now())
+	assert.NoError(t, r.Create(ctx, &base))
+
+	// ошибка fn откатывает и вставку, и обновление вместе с историей
+	err := r.Transaction(ctx, func(tx ItemRepository) error {
+		it := mkItem("t2", 7, 1, time.Now())
+		if err := tx.Create(ctx, &it); err != nil {
+			return err
+		}
+		if _, err := tx.UpdateWithVersion(ctx, 7, "t1", 1, map[string]any{"name": "changed"}); err != nil {
+			return err
+		}
+		return assert.AnError
+	})
+	assert.ErrorIs(t, err, assert.AnError)
+	_, err = r.GetByID(ctx, 7, "t2")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	got, err := r.GetByID(ctx, 7, "t1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), got.Version)
+	vers, err := r.ListVersions(ctx, 7, "t1")
+	assert.NoError(t, err)
+	assert.Empty(t, vers)
+
+	// успешная fn фиксирует все операции
+	err = r.Transaction(ctx, func(tx ItemRepository) error {
+		it := mkItem("t3", 7, 1, time.Now())
+		if err := tx.Create(ctx, &it); err != nil {
+			return err
+		}
+		_, err := tx.UpdateWithVersion(ctx, 7, "t1", 1, map[string]any{"name": "changed"})
+		return err
+	})
+	assert.NoError(t, err)
+	_, err = r.GetByID(ctx, 7, "t3")
+	assert.NoError(t, err)
+	got, err = r.GetByID(ctx, 7, "t1")
+	assert.NoError(t, err)
+	assert.Equal(t, "changed", got.Name)
+	assert.Equal(t, int64(2), got.Version)
+}
+
+func TestItemRepository_LatestChangeSeq(t *testing.T) {
+	db := newTestDB(t)
+	r := NewItemRepository(db)
+	ctx := context.Background()
+
+	seq, err := r.LatestChangeSeq(ctx, 909)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), seq)
+
+	a := mkItem("ls1", 909, 1, time.Now())
+	b := mkItem("ls2", 909, 1, time.Now())
+	assert.NoError(t, r.Create(ctx, &a))
+	assert.NoError(t, r.Create(ctx, &b))
+	_, err = r.UpdateWithVersion(ctx, 909, "ls1", 1, map[string]any{"name": "x"})
+	assert.NoError(t, err)
+
+	seq, err = r.LatestChangeSeq(ctx, 909)
+	assert.NoError(t, err)
+	got, _ := r.GetByID(ctx, 909, "ls1")
+	assert.Equal(t, got.ChangeSeq, seq)
 }
@@ -0,0 +1,65 @@
+package repo
+
+import (
+	"GophKeeper/internal/model"
+	"bytes"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrKeysNotFound — пользователь ещё не опубликовал ключи.
+var ErrKeysNotFound = errors.New("user keys not found")
+
+// KeyRepository — опубликованные ключи X25519 пользователей.
+type KeyRepository interface {
+	// GetKeys возвращает ключи пользователя или ErrKeysNotFound.
+	GetKeys(ctx context.Context, userID int64) (*model.UserKeys, error)
+
+	// SaveKeys сохраняет ключи пользователя, заменяя прежние. При смене открытого ключа
+	// открытые пользователю доли помечаются устаревшими (version 0): владельцы
+	// перешифруют их для нового ключа при следующей синхронизации.
+	SaveKeys(ctx context.Context, k *model.UserKeys) error
+}
+
+type keyRepo struct {
+	db *gorm.DB
+}
+
+// NewKeyRepository создаёт реализацию репозитория ключей.
+func NewKeyRepository(db *gorm.DB) KeyRepository {
+	return &keyRepo{db: db}
+}
+
+func (r *keyRepo) GetKeys(ctx context.Context, userID int64) (*model.UserKeys, error) {
+	var k model.UserKeys
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&k).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrKeysNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (r *keyRepo) SaveKeys(ctx context.Context, k *model.UserKeys) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var prev model.UserKeys
+		err := tx.Where("user_id = ?", k.UserID).First(&prev).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil && !bytes.Equal(prev.PublicKey, k.PublicKey) {
+			if err := tx.Model(&model.ItemShare{}).Where("recipient_id = ?", k.UserID).Update("version", 0).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"public_key", "wrapped_private_key", "private_key_nonce", "updated_at"}),
+		}).Create(k).Error
+	})
+}
@@ -0,0 +1,347 @@
+package repo
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Миграции схемы лежат в migrations/<диалект>/NNNN_имя.up.sql и NNNN_имя.down.sql.
+//
+//go:embed migrations
+var migrationsFS embed.FS
+
+// ErrSchemaOutdated — в БД применены не все миграции из бинарника.
+var ErrSchemaOutdated = errors.New("database schema is outdated")
+
+// advisoryLockKey — ключ pg_advisory_xact_lock, сериализующий параллельные запуски миграций.
+const advisoryLockKey = 7_400_400_040
+
+// Migration — одна версия схемы.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus — состояние миграции в БД; AppliedAt == nil — не применена.
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+}
+
+// Migrator применяет и откатывает встроенные миграции, учитывая их в schema_migrations.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// NewMigrator загружает миграции для диалекта db.
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	migrations, err := loadMigrations(migrationsFS, path.Join("migrations", db.Dialector.Name()))
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// loadMigrations читает пары up/down из dir, отсортированные по версии.
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+	byVersion := map[int64]*Migration{}
+	for _, e := range entries {
+		base, direction, ok := cutDirection(e.Name())
+		if e.IsDir() || !ok {
+			return nil, fmt.Errorf("unexpected migration file %q", e.Name())
+		}
+		num, name, _ := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(num, 10, 64)
+		if err != nil || version <= 0 || name == "" {
+			return nil, fmt.Errorf("bad migration file name %q (want NNNN_name.up.sql)", e.Name())
+		}
+		body, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		m := byVersion[version]
+		if m == nil {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migration %d has two names: %q and %q", version, m.Name, name)
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+	out := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if strings.TrimSpace(m.Up) == "" || strings.TrimSpace(m.Down) == "" {
+			return nil, fmt.Errorf("migration %04d_%s needs both up and down", m.Version, m.Name)
+		}
+		out = append(out, *m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
+
+func cutDirection(file string) (base, direction string, ok bool) {
+	if base, ok = strings.CutSuffix(file, ".up.sql"); ok {
+		return base, "up", true
+	}
+	if base, ok = strings.CutSuffix(file, ".down.sql"); ok {
+		return base, "down", true
+	}
+	return "", "", false
+}
+
+// ensureTable создаёт schema_migrations, если её ещё нет.
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	err := m.db.WithContext(ctx).Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    BIGINT PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL
+	)`).Error
+	if err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+	return nil
+}
+
+type appliedMigration struct {
+	Version   int64
+	AppliedAt time.Time
+}
+
+func (m *Migrator) applied(ctx context.Context, db *gorm.DB) (map[int64]time.Time, error) {
+	var rows []appliedMigration
+	if err := db.WithContext(ctx).Table("schema_migrations").Select("version, applied_at").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("read schema_migrations: %w", err)
+	}
+	out := make(map[int64]time.Time, len(rows))
+	for _, r := range rows {
+		out[r.Version] = r.AppliedAt
+	}
+	return out, nil
+}
+
+// Status возвращает все известные бинарнику миграции с отметкой о применении.
+// БД не меняется: без schema_migrations все миграции считаются неприменёнными.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied := map[int64]time.Time{}
+	if m.db.WithContext(ctx).Migrator().HasTable("schema_migrations") {
+		var err error
+		if applied, err = m.applied(ctx, m.db); err != nil {
+			return nil, err
+		}
+	}
+	out := make([]MigrationStatus, 0, len(m.migrations))
+	for _, mg := range m.migrations {
+		st := MigrationStatus{Version: mg.Version, Name: mg.Name}
+		if at, ok := applied[mg.Version]; ok {
+			st.AppliedAt = &at
+		}
+		out = append(out, st)
+	}
+	return out, nil
+}
+
+// Pending возвращает неприменённые миграции по возрастанию версии.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	status, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []Migration
+	for i, st := range status {
+		if st.AppliedAt == nil {
+			out = append(out, m.migrations[i])
+		}
+	}
+	return out, nil
+}
+
+// Up применяет все неприменённые миграции; каждая — в своей транзакции вместе с записью в schema_migrations.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var done []Migration
+	for _, mg := range pending {
+		ran, err := m.step(ctx, mg, true)
+		if err != nil {
+			return done, fmt.Errorf("migration %04d_%s up: %w", mg.Version, mg.Name, err)
+		}
+		if ran {
+			done = append(done, mg)
+		}
+	}
+	return done, nil
+}
+
+// Down откатывает steps последних применённых миграций.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+	status, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var done []Migration
+	for i := len(status) - 1; i >= 0 && len(done) < steps; i-- {
+		if status[i].AppliedAt == nil {
+			continue
+		}
+		mg := m.migrations[i]
+		ran, err := m.step(ctx, mg, false)
+		if err != nil {
+			return done, fmt.Errorf("migration %04d_%s down: %w", mg.Version, mg.Name, err)
+		}
+		if ran {
+			done = append(done, mg)
+		}
+	}
+	return done, nil
+}
+
+// step выполняет одну миграцию. Состояние перечитывается под блокировкой,
+// поэтому параллельный запуск не применит миграцию дважды (ran == false).
+func (m *Migrator) step(ctx context.Context, mg Migration, up bool) (ran bool, err error) {
+	err = m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if tx.Dialector.Name() == "postgres" {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", advisoryLockKey).Error; err != nil {
+				return err
+			}
+		}
+		applied, err := m.applied(ctx, tx)
+		if err != nil {
+			return err
+		}
+		if _, ok := applied[mg.Version]; ok == up {
+			return nil
+		}
+		if up {
+			if mg.Version == 1 {
+				if err := adoptLegacySchema(tx); err != nil {
+					return fmt.Errorf("adopt legacy schema: %w", err)
+				}
+			}
+			if err := tx.Exec(mg.Up).Error; err != nil {
+				return err
+			}
+			ran = true
+			return tx.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+				mg.Version, mg.Name, time.Now().UTC()).Error
+		}
+		if err := tx.Exec(mg.Down).Error; err != nil {
+			return err
+		}
+		ran = true
+		return tx.Exec("DELETE FROM schema_migrations WHERE version = ?", mg.Version).Error
+	})
+	return ran && err == nil, err
+}
+
+// legacyColumns — столбцы, появившиеся в моделях после схемы прежнего AutoMigrate.
+// Типы подходят обоим диалектам.
+var legacyColumns = []struct{ table, column, def string }{
+	{"blobs", "user_id", "BIGINT NOT NULL DEFAULT 0"},
+	{"blobs", "size", "BIGINT NOT NULL DEFAULT 0"},
+	{"items", "change_seq", "BIGINT NOT NULL DEFAULT 0"},
+	{"items", "field_versions", "TEXT"},
+}
+
+// sqliteBlobsRebuild пересоздаёт blobs с допускающим NULL cipher: SQLite не умеет
+// снимать NOT NULL со столбца. Определение совпадает с 0001_init.
+const sqliteBlobsRebuild = `CREATE TABLE blobs__adopt (
+    id      UUID PRIMARY KEY,
+    user_id INTEGER NOT NULL DEFAULT 0,
+    nonce   BLOB NOT NULL,
+    size    INTEGER NOT NULL DEFAULT 0,
+    cipher  BLOB
+);
+INSERT INTO blobs__adopt (id, user_id, nonce, size, cipher) SELECT id, user_id, nonce, size, cipher FROM blobs;
+DROP TABLE blobs;
+ALTER TABLE blobs__adopt RENAME TO blobs;`
+
+// legacyBackfills заполняют только что добавленные столбцы blobs по существующим данным:
+// владельца — по записям, ссылающимся на блоб, размер — по содержимому в БД.
+var legacyBackfills = map[string]string{
+	"blobs.user_id": `UPDATE blobs SET user_id = (SELECT MIN(items.user_id) FROM items WHERE items.blob_id = blobs.id)
+		WHERE EXISTS (SELECT 1 FROM items WHERE items.blob_id = blobs.id)`,
+	"blobs.size": `UPDATE blobs SET size = LENGTH(cipher) WHERE cipher IS NOT NULL`,
+}
+
+// adoptLegacySchema доводит таблицы, созданные прежним AutoMigrate, до базовой схемы:
+// CREATE TABLE IF NOT EXISTS в 0001_init их пропускает. Добавляет недостающие столбцы,
+// заполняет владельца и размер блобов (иначе они выпадают из квот, а LoadBlob не отдаёт
+// их владельцу) и снимает NOT NULL с blobs.cipher (иначе migrate-blobs не сможет
+// освободить строку). На пустой БД ничего не делает.
+func adoptLegacySchema(tx *gorm.DB) error {
+	mg := tx.Migrator()
+	for _, c := range legacyColumns {
+		if !mg.HasTable(c.table) || mg.HasColumn(c.table, c.column) {
+			continue
+		}
+		if err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", c.table, c.column, c.def)).Error; err != nil {
+			return fmt.Errorf("add %s.%s: %w", c.table, c.column, err)
+		}
+		key := c.table + "." + c.column
+		if q, ok := legacyBackfills[key]; ok {
+			if err := tx.Exec(q).Error; err != nil {
+				return fmt.Errorf("backfill %s: %w", key, err)
+			}
+		}
+	}
+	if !mg.HasTable("blobs") {
+		return nil
+	}
+	cols, err := mg.ColumnTypes("blobs")
+	if err != nil {
+		return err
+	}
+	for _, c := range cols {
+		if nullable, ok := c.Nullable(); c.Name() != "cipher" || !ok || nullable {
+			continue
+		}
+		if tx.Dialector.Name() == "sqlite" {
+			return tx.Exec(sqliteBlobsRebuild).Error
+		}
+		return tx.Exec("ALTER TABLE blobs ALTER COLUMN cipher DROP NOT NULL").Error
+	}
+	return nil
+}
+
+// RequireCurrent возвращает ErrSchemaOutdated, если есть неприменённые миграции.
+func (m *Migrator) RequireCurrent(ctx context.Context) error {
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: %d pending migration(s) starting at %04d_%s, run \"gkserver migrate up\"",
+			ErrSchemaOutdated, len(pending), pending[0].Version, pending[0].Name)
+	}
+	return nil
+}
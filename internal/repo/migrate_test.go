@@ -0,0 +1,290 @@
+package repo
+
+import (
+	"GophKeeper/internal/model"
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// newFileDB открывает пустую файловую SQLite без миграций.
+func newFileDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	return newFileDBAt(t, SQLiteScheme+filepath.Join(t.TempDir(), "gk.db"))
+}
+
+func newFileDBAt(t *testing.T, dsn string) *gorm.DB {
+	t.Helper()
+	db, err := OpenDB(dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func TestMigrator_UpDownStatus(t *testing.T) {
+	db := newFileDB(t)
+	ctx := context.Background()
+	m, err := NewMigrator(db)
+	require.NoError(t, err)
+
+	st, err := m.Status(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, st)
+	assert.Equal(t, int64(1), st[0].Version)
+	assert.Equal(t, "init", st[0].Name)
+	assert.Nil(t, st[0].AppliedAt)
+	assert.ErrorIs(t, m.RequireCurrent(ctx), ErrSchemaOutdated)
+
+	done, err := m.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, done, len(st))
+	require.NoError(t, m.RequireCurrent(ctx))
+	assert.True(t, db.Migrator().HasTable("items"))
+
+	// повторный up — ничего не делает
+	done, err = m.Up(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, done)
+
+	// down откатывает последнюю миграцию и снимает отметку
+	done, err = m.Down(ctx, len(st))
+	require.NoError(t, err)
+	require.Len(t, done, len(st))
+	assert.Equal(t, int64(1), done[len(done)-1].Version)
+	assert.False(t, db.Migrator().HasTable("items"))
+	pending, err := m.Pending(ctx)
+	require.NoError(t, err)
+	assert.Len(t, pending, len(st))
+
+	// откатывать больше нечего
+	done, err = m.Down(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, done)
+
+	_, err = m.Up(ctx)
+	require.NoError(t, err)
+	assert.True(t, db.Migrator().HasTable("items"))
+}
+
+// Схема после миграций содержит все столбцы и индексы моделей gorm.
+func TestMigrator_SchemaMatchesModels(t *testing.T) {
+	db := newFileDB(t)
+	m, err := NewMigrator(db)
+	require.NoError(t, err)
+	_, err = m.Up(context.Background())
+	require.NoError(t, err)
+	assertSchemaMatchesModels(t, db)
+}
+
+// assertSchemaMatchesModels проверяет, что в БД есть все таблицы, столбцы и индексы моделей.
+func assertSchemaMatchesModels(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	for _, mdl := range []any{&model.User{}, &model.Blob{}, &model.Item{}, &model.ItemVersion{}, &model.UserChangeSeq{}, &model.Device{}, &model.UserState{}} {
+		stmt := &gorm.Statement{DB: db}
+		require.NoError(t, stmt.Parse(mdl))
+		sch := stmt.Schema
+		require.True(t, db.Migrator().HasTable(mdl), sch.Table)
+		for _, f := range sch.Fields {
+			if f.DBName == "" {
+				continue
+			}
+			assert.True(t, db.Migrator().HasColumn(mdl, f.DBName), "%s.%s", sch.Table, f.DBName)
+		}
+		for _, idx := range sch.ParseIndexes() {
+			assert.True(t, db.Migrator().HasIndex(mdl, idx.Name), "%s: %s", sch.Table, idx.Name)
+		}
+	}
+}
+
+// База, созданная прежним AutoMigrate, принимает базовую миграцию без потери данных.
+func TestMigrator_AdoptsAutoMigratedDB(t *testing.T) {
+	db := newFileDB(t)
+	ctx := context.Background()
+	require.NoError(t, db.AutoMigrate(&model.User{}, &model.Blob{}, &model.Item{}, &model.ItemVersion{}, &model.UserChangeSeq{}))
+	u, err := NewUserRepository(db).CreateUser(ctx, &model.User{Login: "bob", Password: "h"})
+	require.NoError(t, err)
+
+	m, err := NewMigrator(db)
+	require.NoError(t, err)
+	_, err = m.Up(ctx)
+	require.NoError(t, err)
+
+	got, err := NewUserRepository(db).GetUserByLogin(ctx, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, u.ID, got.ID)
+}
+
+// Модели исходной версии сервера, схему которых создавал AutoMigrate.
+type (
+	baselineUser struct {
+		ID        int64  `gorm:"primaryKey;autoIncrement"`
+		Login     string `gorm:"uniqueIndex;not null"`
+		Password  string `gorm:"not null"`
+		CreatedAt time.Time
+	}
+	baselineBlob struct {
+		ID     string `gorm:"primaryKey;type:uuid"`
+		Cipher []byte `gorm:"not null"`
+		Nonce  []byte `gorm:"not null"`
+	}
+	baselineItem struct {
+		ID             string        `gorm:"primaryKey;type:uuid"`
+		UserID         int64         `gorm:"not null;index"`
+		User           *baselineUser `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+		Name           string        `gorm:"not null"`
+		FileName       string
+		BlobID         *string `gorm:"type:uuid;index"`
+		Version        int64   `gorm:"not null;default:1"`
+		Deleted        bool    `gorm:"not null;default:false"`
+		LoginCipher    []byte
+		LoginNonce     []byte
+		PasswordCipher []byte
+		PasswordNonce  []byte
+		TextCipher     []byte
+		TextNonce      []byte
+		CardCipher     []byte
+		CardNonce      []byte
+		CreatedAt      time.Time
+		UpdatedAt      time.Time
+	}
+)
+
+func (baselineUser) TableName() string { return "users" }
+func (baselineBlob) TableName() string { return "blobs" }
+func (baselineItem) TableName() string { return "items" }
+
+// База исходной версии (AutoMigrate без столбцов квот, курсора и версий полей)
+// принимает миграции: недостающие столбцы добавляются, данные сохраняются.
+func TestMigrator_AdoptsBaselineDB(t *testing.T) {
+	db := newFileDB(t)
+	ctx := context.Background()
+	require.NoError(t, db.AutoMigrate(&baselineUser{}, &baselineBlob{}, &baselineItem{}))
+	u := &baselineUser{Login: "bob", Password: "h"}
+	require.NoError(t, db.Create(u).Error)
+	blobID, itemID := uuid.NewString(), uuid.NewString()
+	require.NoError(t, db.Create(&baselineBlob{ID: blobID, Cipher: []byte("ciph"), Nonce: []byte("n")}).Error)
+	require.NoError(t, db.Create(&baselineItem{ID: itemID, UserID: u.ID, Name: "note", BlobID: &blobID}).Error)
+	// блоб, на который не ссылается ни одна запись, остаётся без владельца
+	orphanID := uuid.NewString()
+	require.NoError(t, db.Create(&baselineBlob{ID: orphanID, Cipher: []byte("o"), Nonce: []byte("n")}).Error)
+
+	m, err := NewMigrator(db)
+	require.NoError(t, err)
+	_, err = m.Up(ctx)
+	require.NoError(t, err)
+	require.NoError(t, m.RequireCurrent(ctx))
+	assertSchemaMatchesModels(t, db)
+
+	var blob model.Blob
+	require.NoError(t, db.First(&blob, "id = ?", blobID).Error)
+	assert.Equal(t, []byte("ciph"), blob.Cipher)
+	assert.Equal(t, []byte("n"), blob.Nonce)
+	// владелец и размер восстановлены: блоб учитывается в квоте и читается владельцем
+	assert.Equal(t, u.ID, blob.UserID)
+	assert.Equal(t, int64(4), blob.Size)
+	used, err := NewBlobRepository(db).SumSizeByUser(ctx, u.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), used)
+	var orphan model.Blob
+	require.NoError(t, db.First(&orphan, "id = ?", orphanID).Error)
+	assert.Zero(t, orphan.UserID)
+
+	// migrate-blobs освобождает cipher: столбец больше не NOT NULL
+	require.NoError(t, db.Model(&model.Blob{}).Where("id = ?", blobID).Update("cipher", nil).Error)
+
+	require.NoError(t, BackfillChangeSeq(ctx, db))
+	items, err := NewItemRepository(db).ListAll(ctx, u.ID)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, itemID, items[0].ID)
+	assert.Positive(t, items[0].ChangeSeq)
+}
+
+// Параллельные запуски применяют каждую миграцию ровно один раз.
+func TestMigrator_ConcurrentUp(t *testing.T) {
+	dsn := SQLiteScheme + filepath.Join(t.TempDir(), "gk.db")
+	var wg sync.WaitGroup
+	applied := make(chan int, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			db, err := OpenDB(dsn)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer func() {
+				if sqlDB, err := db.DB(); err == nil {
+					_ = sqlDB.Close()
+				}
+			}()
+			m, err := NewMigrator(db)
+			if !assert.NoError(t, err) {
+				return
+			}
+			done, err := m.Up(context.Background())
+			assert.NoError(t, err)
+			applied <- len(done)
+		}()
+	}
+	wg.Wait()
+	close(applied)
+	total := 0
+	for n := range applied {
+		total += n
+	}
+	m, err := NewMigrator(newFileDBAt(t, dsn))
+	require.NoError(t, err)
+	st, err := m.Status(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, len(st), total)
+}
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0002_add_x.up.sql":   {Data: []byte("ALTER TABLE t ADD x INT;")},
+		"m/0002_add_x.down.sql": {Data: []byte("ALTER TABLE t DROP x;")},
+		"m/0001_init.up.sql":    {Data: []byte("CREATE TABLE t (id INT);")},
+		"m/0001_init.down.sql":  {Data: []byte("DROP TABLE t;")},
+	}
+	ms, err := loadMigrations(fsys, "m")
+	require.NoError(t, err)
+	require.Len(t, ms, 2)
+	assert.Equal(t, int64(1), ms[0].Version)
+	assert.Equal(t, "add_x", ms[1].Name)
+	assert.Equal(t, "ALTER TABLE t DROP x;", ms[1].Down)
+
+	for name, fsys := range map[string]fstest.MapFS{
+		"no down":   {"m/0001_init.up.sql": {Data: []byte("x")}},
+		"bad name":  {"m/init.up.sql": {Data: []byte("x")}, "m/init.down.sql": {Data: []byte("x")}},
+		"stray":     {"m/README.md": {Data: []byte("x")}},
+		"two names": {"m/0001_a.up.sql": {Data: []byte("x")}, "m/0001_b.down.sql": {Data: []byte("x")}},
+	} {
+		_, err := loadMigrations(fsys, "m")
+		assert.Error(t, err, name)
+	}
+
+	// встроенные миграции есть для обоих диалектов и совпадают по версиям
+	pg, err := loadMigrations(migrationsFS, "migrations/postgres")
+	require.NoError(t, err)
+	lite, err := loadMigrations(migrationsFS, "migrations/sqlite")
+	require.NoError(t, err)
+	require.Equal(t, len(pg), len(lite))
+	for i := range pg {
+		assert.Equal(t, pg[i].Version, lite[i].Version)
+		assert.Equal(t, pg[i].Name, lite[i].Name)
+	}
+}
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS user_change_seqs;
+DROP TABLE IF EXISTS item_versions;
+DROP TABLE IF EXISTS items;
+DROP TABLE IF EXISTS blobs;
+DROP TABLE IF EXISTS users;
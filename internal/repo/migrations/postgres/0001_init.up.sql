@@ -0,0 +1,72 @@
+-- Базовая схема. IF NOT EXISTS: базы, созданные прежним AutoMigrate, принимают миграцию;
+-- недостающие в них столбцы Migrator добавляет перед ней (adoptLegacySchema).
+CREATE TABLE IF NOT EXISTS users (
+    id         BIGSERIAL PRIMARY KEY,
+    login      TEXT NOT NULL,
+    password   TEXT NOT NULL,
+    created_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_login ON users (login);
+
+CREATE TABLE IF NOT EXISTS blobs (
+    id      UUID PRIMARY KEY,
+    user_id BIGINT NOT NULL DEFAULT 0,
+    nonce   BYTEA NOT NULL,
+    size    BIGINT NOT NULL DEFAULT 0,
+    cipher  BYTEA
+);
+CREATE INDEX IF NOT EXISTS idx_blobs_user_id ON blobs (user_id);
+
+CREATE TABLE IF NOT EXISTS items (
+    id              UUID PRIMARY KEY,
+    user_id         BIGINT NOT NULL,
+    name            TEXT NOT NULL,
+    file_name       TEXT,
+    blob_id         UUID,
+    version         BIGINT NOT NULL DEFAULT 1,
+    deleted         BOOLEAN NOT NULL DEFAULT false,
+    change_seq      BIGINT NOT NULL DEFAULT 0,
+    field_versions  TEXT,
+    login_cipher    BYTEA,
+    login_nonce     BYTEA,
+    password_cipher BYTEA,
+    password_nonce  BYTEA,
+    text_cipher     BYTEA,
+    text_nonce      BYTEA,
+    card_cipher     BYTEA,
+    card_nonce      BYTEA,
+    created_at      TIMESTAMPTZ,
+    updated_at      TIMESTAMPTZ,
+    CONSTRAINT fk_items_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_items_user_id ON items (user_id);
+CREATE INDEX IF NOT EXISTS idx_items_blob_id ON items (blob_id);
+CREATE INDEX IF NOT EXISTS idx_items_change_seq ON items (change_seq);
+
+CREATE TABLE IF NOT EXISTS item_versions (
+    id              BIGSERIAL PRIMARY KEY,
+    item_id         UUID NOT NULL,
+    user_id         BIGINT NOT NULL,
+    version         BIGINT NOT NULL,
+    deleted         BOOLEAN NOT NULL DEFAULT false,
+    name            TEXT NOT NULL,
+    file_name       TEXT,
+    blob_id         UUID,
+    login_cipher    BYTEA,
+    login_nonce     BYTEA,
+    password_cipher BYTEA,
+    password_nonce  BYTEA,
+    text_cipher     BYTEA,
+    text_nonce      BYTEA,
+    card_cipher     BYTEA,
+    card_nonce      BYTEA,
+    item_updated_at TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_item_versions_item_version ON item_versions (item_id, version);
+CREATE INDEX IF NOT EXISTS idx_item_versions_user_id ON item_versions (user_id);
+
+CREATE TABLE IF NOT EXISTS user_change_seqs (
+    user_id BIGINT PRIMARY KEY,
+    seq     BIGINT NOT NULL DEFAULT 0
+);
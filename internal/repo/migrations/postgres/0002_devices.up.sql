@@ -0,0 +1,11 @@
+-- Устройства пользователей, подтверждённые клиентским сертификатом (mTLS).
+CREATE TABLE devices (
+    id           BIGSERIAL PRIMARY KEY,
+    user_id      BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    fingerprint  TEXT NOT NULL,
+    name         TEXT NOT NULL,
+    created_at   TIMESTAMPTZ,
+    last_seen_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX idx_devices_fingerprint ON devices (fingerprint);
+CREATE INDEX idx_devices_user_id ON devices (user_id);
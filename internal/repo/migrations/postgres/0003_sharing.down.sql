@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS item_shares;
+DROP TABLE IF EXISTS user_keys;
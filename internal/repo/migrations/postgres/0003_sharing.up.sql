@@ -0,0 +1,34 @@
+-- Открытые ключи X25519 пользователей (закрытый — зашифрован ключом хранилища клиента).
+CREATE TABLE user_keys (
+    user_id             BIGINT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    public_key          BYTEA NOT NULL,
+    wrapped_private_key BYTEA NOT NULL,
+    private_key_nonce   BYTEA NOT NULL,
+    created_at          TIMESTAMPTZ,
+    updated_at          TIMESTAMPTZ
+);
+
+-- Записи, открытые другим пользователям: поля зашифрованы ключом, запечатанным для получателя.
+CREATE TABLE item_shares (
+    id                BIGSERIAL PRIMARY KEY,
+    item_id           UUID NOT NULL REFERENCES items (id) ON DELETE CASCADE,
+    owner_id          BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    recipient_id      BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    version           BIGINT NOT NULL,
+    ephemeral_key     BYTEA NOT NULL,
+    wrapped_key       BYTEA NOT NULL,
+    wrapped_key_nonce BYTEA NOT NULL,
+    login_cipher      BYTEA,
+    login_nonce       BYTEA,
+    password_cipher   BYTEA,
+    password_nonce    BYTEA,
+    text_cipher       BYTEA,
+    text_nonce        BYTEA,
+    card_cipher       BYTEA,
+    card_nonce        BYTEA,
+    created_at        TIMESTAMPTZ,
+    updated_at        TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX idx_item_shares_item_recipient ON item_shares (item_id, recipient_id);
+CREATE INDEX idx_item_shares_owner_id ON item_shares (owner_id);
+CREATE INDEX idx_item_shares_recipient_id ON item_shares (recipient_id);
@@ -0,0 +1,58 @@
+-- Организации, участники с ролями и общие коллекции записей.
+CREATE TABLE organizations (
+    id         BIGSERIAL PRIMARY KEY,
+    name       TEXT NOT NULL,
+    created_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX idx_organizations_name ON organizations (name);
+
+CREATE TABLE org_members (
+    org_id     BIGINT NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    user_id    BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role       TEXT NOT NULL,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    PRIMARY KEY (org_id, user_id)
+);
+CREATE INDEX idx_org_members_user_id ON org_members (user_id);
+
+CREATE TABLE collections (
+    id         UUID PRIMARY KEY,
+    org_id     BIGINT NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    name       TEXT NOT NULL,
+    created_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX idx_collections_org_name ON collections (org_id, name);
+
+-- Ключ коллекции, запечатанный открытым ключом каждого участника.
+CREATE TABLE collection_keys (
+    collection_id     UUID NOT NULL REFERENCES collections (id) ON DELETE CASCADE,
+    user_id           BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    ephemeral_key     BYTEA NOT NULL,
+    wrapped_key       BYTEA NOT NULL,
+    wrapped_key_nonce BYTEA NOT NULL,
+    created_at        TIMESTAMPTZ,
+    updated_at        TIMESTAMPTZ,
+    PRIMARY KEY (collection_id, user_id)
+);
+CREATE INDEX idx_collection_keys_user_id ON collection_keys (user_id);
+
+CREATE TABLE collection_items (
+    id              UUID PRIMARY KEY,
+    collection_id   UUID NOT NULL REFERENCES collections (id) ON DELETE CASCADE,
+    name            TEXT NOT NULL,
+    version         BIGINT NOT NULL,
+    deleted         BOOLEAN NOT NULL DEFAULT FALSE,
+    login_cipher    BYTEA,
+    login_nonce     BYTEA,
+    password_cipher BYTEA,
+    password_nonce  BYTEA,
+    text_cipher     BYTEA,
+    text_nonce      BYTEA,
+    card_cipher     BYTEA,
+    card_nonce      BYTEA,
+    updated_by      BIGINT NOT NULL,
+    created_at      TIMESTAMPTZ,
+    updated_at      TIMESTAMPTZ
+);
+CREATE INDEX idx_collection_items_collection_id ON collection_items (collection_id);
@@ -0,0 +1,15 @@
+-- Экстренный доступ: ключ хранилища владельца, запечатанный для доверенного контакта.
+CREATE TABLE emergency_accesses (
+    owner_id          BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    contact_id        BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    wait_days         INTEGER NOT NULL,
+    status            TEXT NOT NULL,
+    requested_at      TIMESTAMPTZ,
+    ephemeral_key     BYTEA NOT NULL,
+    wrapped_key       BYTEA NOT NULL,
+    wrapped_key_nonce BYTEA NOT NULL,
+    created_at        TIMESTAMPTZ,
+    updated_at        TIMESTAMPTZ,
+    PRIMARY KEY (owner_id, contact_id)
+);
+CREATE INDEX idx_emergency_accesses_contact_id ON emergency_accesses (contact_id);
@@ -0,0 +1,72 @@
+-- Базовая схема. IF NOT EXISTS: базы, созданные прежним AutoMigrate, принимают миграцию;
+-- недостающие в них столбцы Migrator добавляет перед ней (adoptLegacySchema).
+CREATE TABLE IF NOT EXISTS users (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    login      TEXT NOT NULL,
+    password   TEXT NOT NULL,
+    created_at DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_login ON users (login);
+
+CREATE TABLE IF NOT EXISTS blobs (
+    id      UUID PRIMARY KEY,
+    user_id INTEGER NOT NULL DEFAULT 0,
+    nonce   BLOB NOT NULL,
+    size    INTEGER NOT NULL DEFAULT 0,
+    cipher  BLOB
+);
+CREATE INDEX IF NOT EXISTS idx_blobs_user_id ON blobs (user_id);
+
+CREATE TABLE IF NOT EXISTS items (
+    id              UUID PRIMARY KEY,
+    user_id         INTEGER NOT NULL,
+    name            TEXT NOT NULL,
+    file_name       TEXT,
+    blob_id         UUID,
+    version         INTEGER NOT NULL DEFAULT 1,
+    deleted         NUMERIC NOT NULL DEFAULT false,
+    change_seq      INTEGER NOT NULL DEFAULT 0,
+    field_versions  TEXT,
+    login_cipher    BLOB,
+    login_nonce     BLOB,
+    password_cipher BLOB,
+    password_nonce  BLOB,
+    text_cipher     BLOB,
+    text_nonce      BLOB,
+    card_cipher     BLOB,
+    card_nonce      BLOB,
+    created_at      DATETIME,
+    updated_at      DATETIME,
+    CONSTRAINT fk_items_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_items_user_id ON items (user_id);
+CREATE INDEX IF NOT EXISTS idx_items_blob_id ON items (blob_id);
+CREATE INDEX IF NOT EXISTS idx_items_change_seq ON items (change_seq);
+
+CREATE TABLE IF NOT EXISTS item_versions (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    item_id         UUID NOT NULL,
+    user_id         INTEGER NOT NULL,
+    version         INTEGER NOT NULL,
+    deleted         NUMERIC NOT NULL DEFAULT false,
+    name            TEXT NOT NULL,
+    file_name       TEXT,
+    blob_id         UUID,
+    login_cipher    BLOB,
+    login_nonce     BLOB,
+    password_cipher BLOB,
+    password_nonce  BLOB,
+    text_cipher     BLOB,
+    text_nonce      BLOB,
+    card_cipher     BLOB,
+    card_nonce      BLOB,
+    item_updated_at DATETIME,
+    created_at      DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_item_versions_item_version ON item_versions (item_id, version);
+CREATE INDEX IF NOT EXISTS idx_item_versions_user_id ON item_versions (user_id);
+
+CREATE TABLE IF NOT EXISTS user_change_seqs (
+    user_id INTEGER PRIMARY KEY,
+    seq     INTEGER NOT NULL DEFAULT 0
+);
@@ -0,0 +1,11 @@
+-- Устройства пользователей, подтверждённые клиентским сертификатом (mTLS).
+CREATE TABLE devices (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id      INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    fingerprint  TEXT NOT NULL,
+    name         TEXT NOT NULL,
+    created_at   DATETIME,
+    last_seen_at DATETIME
+);
+CREATE UNIQUE INDEX idx_devices_fingerprint ON devices (fingerprint);
+CREATE INDEX idx_devices_user_id ON devices (user_id);
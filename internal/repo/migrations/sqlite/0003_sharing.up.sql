@@ -0,0 +1,34 @@
+-- Открытые ключи X25519 пользователей (закрытый — зашифрован ключом хранилища клиента).
+CREATE TABLE user_keys (
+    user_id             INTEGER PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    public_key          BLOB NOT NULL,
+    wrapped_private_key BLOB NOT NULL,
+    private_key_nonce   BLOB NOT NULL,
+    created_at          DATETIME,
+    updated_at          DATETIME
+);
+
+-- Записи, открытые другим пользователям: поля зашифрованы ключом, запечатанным для получателя.
+CREATE TABLE item_shares (
+    id                INTEGER PRIMARY KEY AUTOINCREMENT,
+    item_id           UUID NOT NULL REFERENCES items (id) ON DELETE CASCADE,
+    owner_id          INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    recipient_id      INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    version           INTEGER NOT NULL,
+    ephemeral_key     BLOB NOT NULL,
+    wrapped_key       BLOB NOT NULL,
+    wrapped_key_nonce BLOB NOT NULL,
+    login_cipher      BLOB,
+    login_nonce       BLOB,
+    password_cipher   BLOB,
+    password_nonce    BLOB,
+    text_cipher       BLOB,
+    text_nonce        BLOB,
+    card_cipher       BLOB,
+    card_nonce        BLOB,
+    created_at        DATETIME,
+    updated_at        DATETIME
+);
+CREATE UNIQUE INDEX idx_item_shares_item_recipient ON item_shares (item_id, recipient_id);
+CREATE INDEX idx_item_shares_owner_id ON item_shares (owner_id);
+CREATE INDEX idx_item_shares_recipient_id ON item_shares (recipient_id);
@@ -0,0 +1,5 @@
+DROP TABLE collection_items;
+DROP TABLE collection_keys;
+DROP TABLE collections;
+DROP TABLE org_members;
+DROP TABLE organizations;
@@ -0,0 +1,58 @@
+-- Организации, участники с ролями и общие коллекции записей.
+CREATE TABLE organizations (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    name       TEXT NOT NULL,
+    created_at DATETIME
+);
+CREATE UNIQUE INDEX idx_organizations_name ON organizations (name);
+
+CREATE TABLE org_members (
+    org_id     INTEGER NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    user_id    INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role       TEXT NOT NULL,
+    created_at DATETIME,
+    updated_at DATETIME,
+    PRIMARY KEY (org_id, user_id)
+);
+CREATE INDEX idx_org_members_user_id ON org_members (user_id);
+
+CREATE TABLE collections (
+    id         UUID PRIMARY KEY,
+    org_id     INTEGER NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    name       TEXT NOT NULL,
+    created_at DATETIME
+);
+CREATE UNIQUE INDEX idx_collections_org_name ON collections (org_id, name);
+
+-- Ключ коллекции, запечатанный открытым ключом каждого участника.
+CREATE TABLE collection_keys (
+    collection_id     UUID NOT NULL REFERENCES collections (id) ON DELETE CASCADE,
+    user_id           INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    ephemeral_key     BLOB NOT NULL,
+    wrapped_key       BLOB NOT NULL,
+    wrapped_key_nonce BLOB NOT NULL,
+    created_at        DATETIME,
+    updated_at        DATETIME,
+    PRIMARY KEY (collection_id, user_id)
+);
+CREATE INDEX idx_collection_keys_user_id ON collection_keys (user_id);
+
+CREATE TABLE collection_items (
+    id              UUID PRIMARY KEY,
+    collection_id   UUID NOT NULL REFERENCES collections (id) ON DELETE CASCADE,
+    name            TEXT NOT NULL,
+    version         INTEGER NOT NULL,
+    deleted         BOOLEAN NOT NULL DEFAULT FALSE,
+    login_cipher    BLOB,
+    login_nonce     BLOB,
+    password_cipher BLOB,
+    password_nonce  BLOB,
+    text_cipher     BLOB,
+    text_nonce      BLOB,
+    card_cipher     BLOB,
+    card_nonce      BLOB,
+    updated_by      INTEGER NOT NULL,
+    created_at      DATETIME,
+    updated_at      DATETIME
+);
+CREATE INDEX idx_collection_items_collection_id ON collection_items (collection_id);
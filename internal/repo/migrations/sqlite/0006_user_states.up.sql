@@ -0,0 +1,7 @@
+-- Административное состояние пользователей: отключение и отзыв выданных токенов.
+CREATE TABLE user_states (
+    user_id             INTEGER PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    disabled            BOOLEAN NOT NULL DEFAULT FALSE,
+    sessions_revoked_at DATETIME,
+    updated_at          DATETIME
+);
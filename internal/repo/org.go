@@ -0,0 +1,354 @@
+package repo
+
+import (
+	"GophKeeper/internal/model"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrOrgNotFound — организации нет (или пользователь в ней не состоит).
+	ErrOrgNotFound = errors.New("organization not found")
+	// ErrOrgExists — организация с таким именем уже есть.
+	ErrOrgExists = errors.New("organization already exists")
+	// ErrOrgMemberNotFound — пользователь не состоит в организации.
+	ErrOrgMemberNotFound = errors.New("organization member not found")
+	// ErrCollectionNotFound — коллекции нет (или она недоступна пользователю).
+	ErrCollectionNotFound = errors.New("collection not found")
+	// ErrCollectionExists — в организации уже есть коллекция с таким именем.
+	ErrCollectionExists = errors.New("collection already exists")
+	// ErrCollectionItemConflict — версия записи коллекции не совпала или id уже занят.
+	ErrCollectionItemConflict = errors.New("collection item version conflict")
+)
+
+// OrgRepository — организации, их участники и общие коллекции записей.
+type OrgRepository interface {
+	// CreateOrg создаёт организацию с владельцем ownerID и первой коллекцией col,
+	// ключ которой запечатан для владельца (ErrOrgExists).
+	CreateOrg(ctx context.Context, org *model.Organization, ownerID int64, col *model.Collection, key *model.CollectionKey) error
+
+	// GetOrgByName возвращает организацию по имени (ErrOrgNotFound).
+	GetOrgByName(ctx context.Context, name string) (*model.Organization, error)
+
+	// User возвращает id и открытый ключ пользователя login
+	// (ErrRecipientNotFound, ErrRecipientHasNoKey).
+	User(ctx context.Context, login string) (int64, []byte, error)
+
+	// Member возвращает участника организации (ErrOrgMemberNotFound).
+	Member(ctx context.Context, orgID, userID int64) (*model.OrgMember, error)
+
+	// CountOwners возвращает число владельцев организации.
+	CountOwners(ctx context.Context, orgID int64) (int64, error)
+
+	// SaveMember добавляет участника или меняет его роль и сохраняет переданные
+	// ключи коллекций в одной транзакции.
+	SaveMember(ctx context.Context, m *model.OrgMember, keys []model.CollectionKey) error
+
+	// RemoveMember исключает участника и удаляет его ключи коллекций организации
+	// (ErrOrgMemberNotFound).
+	RemoveMember(ctx context.Context, orgID, userID int64) error
+
+	// ListMembers возвращает участников организации по логину.
+	ListMembers(ctx context.Context, orgID int64) ([]model.OrgMemberInfo, error)
+
+	// ListMemberships возвращает организации пользователя с его ролью и коллекциями.
+	ListMemberships(ctx context.Context, userID int64) ([]model.OrgMembership, error)
+
+	// ListCollections возвращает коллекции организации по имени.
+	ListCollections(ctx context.Context, orgID int64) ([]model.Collection, error)
+
+	// CreateCollection создаёт коллекцию вместе с ключами участников (ErrCollectionExists).
+	CreateCollection(ctx context.Context, col *model.Collection, keys []model.CollectionKey) error
+
+	// GetCollection возвращает коллекцию по id (ErrCollectionNotFound).
+	GetCollection(ctx context.Context, id string) (*model.Collection, error)
+
+	// SaveCollectionItem создаёт запись (expectedVersion 0) или обновляет её с проверкой
+	// версии; возвращает новую версию (ErrCollectionItemConflict).
+	SaveCollectionItem(ctx context.Context, it *model.CollectionItem, expectedVersion int64) (int64, error)
+
+	// CollectionsForUser возвращает коллекции, ключ которых запечатан для пользователя,
+	// со всеми их записями.
+	CollectionsForUser(ctx context.Context, userID int64) ([]model.CollectionSnapshot, error)
+}
+
+type orgRepo struct {
+	db *gorm.DB
+}
+
+// NewOrgRepository создаёт реализацию репозитория организаций.
+func NewOrgRepository(db *gorm.DB) OrgRepository {
+	return &orgRepo{db: db}
+}
+
+func (r *orgRepo) CreateOrg(ctx context.Context, org *model.Organization, ownerID int64, col *model.Collection, key *model.CollectionKey) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var n int64
+		if err := tx.Model(&model.Organization{}).Where("name = ?", org.Name).Count(&n).Error; err != nil {
+			return err
+		}
+		if n > 0 {
+			return ErrOrgExists
+		}
+		if err := tx.Create(org).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&model.OrgMember{OrgID: org.ID, UserID: ownerID, Role: model.RoleOwner}).Error; err != nil {
+			return err
+		}
+		col.OrgID = org.ID
+		if err := tx.Create(col).Error; err != nil {
+			return err
+		}
+		key.CollectionID, key.UserID = col.ID, ownerID
+		return tx.Create(key).Error
+	})
+}
+
+func (r *orgRepo) GetOrgByName(ctx context.Context, name string) (*model.Organization, error) {
+	var org model.Organization
+	err := r.db.WithContext(ctx).Where("name = ?", name).First(&org).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrOrgNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+func (r *orgRepo) User(ctx context.Context, login string) (int64, []byte, error) {
+	return NewShareRepository(r.db).Recipient(ctx, login)
+}
+
+func (r *orgRepo) Member(ctx context.Context, orgID, userID int64) (*model.OrgMember, error) {
+	var m model.OrgMember
+	err := r.db.WithContext(ctx).Where("org_id = ? AND user_id = ?", orgID, userID).First(&m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrOrgMemberNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *orgRepo) CountOwners(ctx context.Context, orgID int64) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Model(&model.OrgMember{}).
+		Where("org_id = ? AND role = ?", orgID, model.RoleOwner).
+		Count(&n).Error
+	return n, err
+}
+
+func (r *orgRepo) SaveMember(ctx context.Context, m *model.OrgMember, keys []model.CollectionKey) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "org_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"role", "updated_at"}),
+		}).Create(m).Error
+		if err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "collection_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"ephemeral_key", "wrapped_key", "wrapped_key_nonce", "updated_at"}),
+		}).Create(&keys).Error
+	})
+}
+
+func (r *orgRepo) RemoveMember(ctx context.Context, orgID, userID int64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("org_id = ? AND user_id = ?", orgID, userID).Delete(&model.OrgMember{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrOrgMemberNotFound
+		}
+		return tx.Where("user_id = ? AND collection_id IN (?)", userID,
+			tx.Model(&model.Collection{}).Select("id").Where("org_id = ?", orgID)).
+			Delete(&model.CollectionKey{}).Error
+	})
+}
+
+func (r *orgRepo) ListMembers(ctx context.Context, orgID int64) ([]model.OrgMemberInfo, error) {
+	var out []model.OrgMemberInfo
+	err := r.db.WithContext(ctx).
+		Table("org_members").
+		Select("org_members.user_id, users.login AS login, org_members.role, user_keys.public_key AS public_key").
+		Joins("JOIN users ON users.id = org_members.user_id").
+		Joins("LEFT JOIN user_keys ON user_keys.user_id = org_members.user_id").
+		Where("org_members.org_id = ?", orgID).
+		Order("users.login asc").
+		Scan(&out).Error
+	return out, err
+}
+
+func (r *orgRepo) ListMemberships(ctx context.Context, userID int64) ([]model.OrgMembership, error) {
+	var rows []struct {
+		model.Organization
+		Role string
+	}
+	err := r.db.WithContext(ctx).
+		Table("organizations").
+		Select("organizations.id, organizations.name, organizations.created_at, org_members.role AS role").
+		Joins("JOIN org_members ON org_members.org_id = organizations.id").
+		Where("org_members.user_id = ?", userID).
+		Order("organizations.name asc").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.OrgMembership, 0, len(rows))
+	for _, rw := range rows {
+		cols, err := r.ListCollections(ctx, rw.ID)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, model.OrgMembership{Organization: rw.Organization, Role: rw.Role, Collections: cols})
+	}
+	return out, nil
+}
+
+func (r *orgRepo) ListCollections(ctx context.Context, orgID int64) ([]model.Collection, error) {
+	var out []model.Collection
+	err := r.db.WithContext(ctx).Where("org_id = ?", orgID).Order("name asc").Find(&out).Error
+	return out, err
+}
+
+func (r *orgRepo) CreateCollection(ctx context.Context, col *model.Collection, keys []model.CollectionKey) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var n int64
+		if err := tx.Model(&model.Collection{}).Where("org_id = ? AND name = ?", col.OrgID, col.Name).Count(&n).Error; err != nil {
+			return err
+		}
+		if n > 0 {
+			return ErrCollectionExists
+		}
+		if err := tx.Create(col).Error; err != nil {
+			return err
+		}
+		for i := range keys {
+			keys[i].CollectionID = col.ID
+		}
+		if len(keys) == 0 {
+			return nil
+		}
+		return tx.Create(&keys).Error
+	})
+}
+
+func (r *orgRepo) GetCollection(ctx context.Context, id string) (*model.Collection, error) {
+	var col model.Collection
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&col).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCollectionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &col, nil
+}
+
+func (r *orgRepo) SaveCollectionItem(ctx context.Context, it *model.CollectionItem, expectedVersion int64) (int64, error) {
+	newVersion := expectedVersion + 1
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var prev model.CollectionItem
+		err := tx.Where("id = ?", it.ID).First(&prev).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if expectedVersion != 0 {
+				return ErrCollectionItemConflict
+			}
+			it.Version = newVersion
+			return tx.Create(it).Error
+		case err != nil:
+			return err
+		case prev.CollectionID != it.CollectionID || prev.Version != expectedVersion:
+			return ErrCollectionItemConflict
+		}
+		res := tx.Model(&model.CollectionItem{}).
+			Where("id = ? AND version = ?", it.ID, expectedVersion).
+			Updates(map[string]any{
+				"name":            it.Name,
+				"version":         newVersion,
+				"deleted":         it.Deleted,
+				"login_cipher":    it.LoginCipher,
+				"login_nonce":     it.LoginNonce,
+				"password_cipher": it.PasswordCipher,
+				"password_nonce":  it.PasswordNonce,
+				"text_cipher":     it.TextCipher,
+				"text_nonce":      it.TextNonce,
+				"card_cipher":     it.CardCipher,
+				"card_nonce":      it.CardNonce,
+				"updated_by":      it.UpdatedBy,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrCollectionItemConflict
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return newVersion, nil
+}
+
+func (r *orgRepo) CollectionsForUser(ctx context.Context, userID int64) ([]model.CollectionSnapshot, error) {
+	var rows []struct {
+		ID              string
+		OrgID           int64
+		Name            string
+		CreatedAt       time.Time
+		OrgName         string
+		Role            string
+		EphemeralKey    []byte
+		WrappedKey      []byte
+		WrappedKeyNonce []byte
+	}
+	err := r.db.WithContext(ctx).
+		Table("collection_keys").
+		Select("collections.id, collections.org_id, collections.name, collections.created_at, organizations.name AS org_name, org_members.role AS role, "+
+			"collection_keys.ephemeral_key, collection_keys.wrapped_key, collection_keys.wrapped_key_nonce").
+		Joins("JOIN collections ON collections.id = collection_keys.collection_id").
+		Joins("JOIN organizations ON organizations.id = collections.org_id").
+		Joins("JOIN org_members ON org_members.org_id = collections.org_id AND org_members.user_id = collection_keys.user_id").
+		Where("collection_keys.user_id = ?", userID).
+		Order("organizations.name asc, collections.name asc").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.CollectionSnapshot, 0, len(rows))
+	for _, rw := range rows {
+		snap := model.CollectionSnapshot{
+			Collection: model.Collection{ID: rw.ID, OrgID: rw.OrgID, Name: rw.Name, CreatedAt: rw.CreatedAt},
+			OrgName:    rw.OrgName,
+			Role:       rw.Role,
+			Key: model.CollectionKey{
+				CollectionID:    rw.ID,
+				UserID:          userID,
+				EphemeralKey:    rw.EphemeralKey,
+				WrappedKey:      rw.WrappedKey,
+				WrappedKeyNonce: rw.WrappedKeyNonce,
+			},
+		}
+		if err := r.db.WithContext(ctx).Where("collection_id = ?", rw.ID).
+			Order("name asc").Find(&snap.Items).Error; err != nil {
+			return nil, err
+		}
+		out = append(out, snap)
+	}
+	return out, nil
+}
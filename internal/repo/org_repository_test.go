@@ -0,0 +1,124 @@
+package repo
+
+import (
+	"GophKeeper/internal/model"
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrgRepository(t *testing.T) {
+	db := newFileDB(t)
+	ctx := context.Background()
+	m, err := NewMigrator(db)
+	require.NoError(t, err)
+	_, err = m.Up(ctx)
+	require.NoError(t, err)
+
+	users := NewUserRepository(db)
+	alice, err := users.CreateUser(ctx, &model.User{Login: "alice", Password: "h"})
+	require.NoError(t, err)
+	bob, err := users.CreateUser(ctx, &model.User{Login: "bob", Password: "h"})
+	require.NoError(t, err)
+	keys := NewKeyRepository(db)
+	require.NoError(t, keys.SaveKeys(ctx, &model.UserKeys{UserID: bob.ID, PublicKey: []byte("pub-bob"), WrappedPrivateKey: []byte("w"), PrivateKeyNonce: []byte("n")}))
+
+	r := NewOrgRepository(db)
+	org := &model.Organization{Name: "acme"}
+	col := &model.Collection{ID: uuid.NewString(), Name: "default"}
+	key := func() *model.CollectionKey {
+		return &model.CollectionKey{EphemeralKey: []byte("e"), WrappedKey: []byte("k"), WrappedKeyNonce: []byte("n")}
+	}
+	require.NoError(t, r.CreateOrg(ctx, org, alice.ID, col, key()))
+	assert.ErrorIs(t, r.CreateOrg(ctx, &model.Organization{Name: "acme"}, bob.ID,
+		&model.Collection{ID: uuid.NewString(), Name: "default"}, key()), ErrOrgExists)
+
+	got, err := r.GetOrgByName(ctx, "acme")
+	require.NoError(t, err)
+	assert.Equal(t, org.ID, got.ID)
+	_, err = r.GetOrgByName(ctx, "nope")
+	assert.ErrorIs(t, err, ErrOrgNotFound)
+
+	owner, err := r.Member(ctx, org.ID, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.RoleOwner, owner.Role)
+	_, err = r.Member(ctx, org.ID, bob.ID)
+	assert.ErrorIs(t, err, ErrOrgMemberNotFound)
+
+	bobID, pub, err := r.User(ctx, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, bob.ID, bobID)
+	assert.Equal(t, []byte("pub-bob"), pub)
+
+	// участник получает ключ коллекции вместе с ролью
+	bk := key()
+	bk.CollectionID, bk.UserID = col.ID, bob.ID
+	require.NoError(t, r.SaveMember(ctx, &model.OrgMember{OrgID: org.ID, UserID: bob.ID, Role: model.RoleReadOnly}, []model.CollectionKey{*bk}))
+	require.NoError(t, r.SaveMember(ctx, &model.OrgMember{OrgID: org.ID, UserID: bob.ID, Role: model.RoleMember}, nil))
+	members, err := r.ListMembers(ctx, org.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	assert.Equal(t, "alice", members[0].Login)
+	assert.Empty(t, members[0].PublicKey)
+	assert.Equal(t, model.RoleMember, members[1].Role, "повторное сохранение меняет роль")
+	assert.Equal(t, []byte("pub-bob"), members[1].PublicKey)
+	n, err := r.CountOwners(ctx, org.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	ops := &model.Collection{ID: uuid.NewString(), OrgID: org.ID, Name: "ops"}
+	require.NoError(t, r.CreateCollection(ctx, ops, []model.CollectionKey{{UserID: alice.ID, EphemeralKey: []byte("e"), WrappedKey: []byte("k"), WrappedKeyNonce: []byte("n")}}))
+	assert.ErrorIs(t, r.CreateCollection(ctx, &model.Collection{ID: uuid.NewString(), OrgID: org.ID, Name: "ops"}, nil), ErrCollectionExists)
+	_, err = r.GetCollection(ctx, uuid.NewString())
+	assert.ErrorIs(t, err, ErrCollectionNotFound)
+
+	orgs, err := r.ListMemberships(ctx, bob.ID)
+	require.NoError(t, err)
+	require.Len(t, orgs, 1)
+	assert.Equal(t, "acme", orgs[0].Name)
+	assert.Equal(t, model.RoleMember, orgs[0].Role)
+	require.Len(t, orgs[0].Collections, 2)
+	assert.Equal(t, "default", orgs[0].Collections[0].Name)
+
+	// записи коллекции: создание, обновление и конфликт версий
+	it := &model.CollectionItem{ID: uuid.NewString(), CollectionID: col.ID, Name: "wifi", PasswordCipher: []byte("c1"), UpdatedBy: alice.ID}
+	v, err := r.SaveCollectionItem(ctx, it, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), v)
+	_, err = r.SaveCollectionItem(ctx, it, 0)
+	assert.ErrorIs(t, err, ErrCollectionItemConflict)
+	upd := *it
+	upd.PasswordCipher, upd.UpdatedBy = []byte("c2"), bob.ID
+	v, err = r.SaveCollectionItem(ctx, &upd, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), v)
+	_, err = r.SaveCollectionItem(ctx, &upd, 1)
+	assert.ErrorIs(t, err, ErrCollectionItemConflict)
+
+	// bob видит только коллекции, ключ которых запечатан для него
+	snaps, err := r.CollectionsForUser(ctx, bob.ID)
+	require.NoError(t, err)
+	require.Len(t, snaps, 1)
+	assert.Equal(t, "acme", snaps[0].OrgName)
+	assert.Equal(t, model.RoleMember, snaps[0].Role)
+	assert.Equal(t, []byte("k"), snaps[0].Key.WrappedKey)
+	require.Len(t, snaps[0].Items, 1)
+	assert.Equal(t, []byte("c2"), snaps[0].Items[0].PasswordCipher)
+	assert.Equal(t, int64(2), snaps[0].Items[0].Version)
+	snaps, err = r.CollectionsForUser(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Len(t, snaps, 2)
+
+	// исключённый участник теряет ключи коллекций
+	require.NoError(t, r.RemoveMember(ctx, org.ID, bob.ID))
+	assert.ErrorIs(t, r.RemoveMember(ctx, org.ID, bob.ID), ErrOrgMemberNotFound)
+	snaps, err = r.CollectionsForUser(ctx, bob.ID)
+	require.NoError(t, err)
+	assert.Empty(t, snaps)
+	var left int64
+	require.NoError(t, db.Model(&model.CollectionKey{}).Where("user_id = ?", bob.ID).Count(&left).Error)
+	assert.Zero(t, left)
+}
@@ -1,7 +1,7 @@
 package repo
 
 import (
-	"GophKeeper/internal/model"
+	"context"
 	"testing"
 
 	gormsqlite "gorm.io/driver/sqlite"
@@ -17,9 +17,13 @@ func newTestDB(t *testing.T) *gorm.DB {
 	if err != nil {
 		t.Fatalf("failed to open sqlite (modernc): %v", err)
 	}
-	// Миграции для всех моделей, используемых в репозиториях
-	if err := db.AutoMigrate(&model.User{}, &model.Item{}, &model.Blob{}); err != nil {
-		t.Fatalf("failed to automigrate: %v", err)
+	// Схема — из тех же миграций, что применяет gkserver migrate up
+	m, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
 	}
 	return db
 }
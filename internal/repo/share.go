@@ -0,0 +1,118 @@
+package repo
+
+import (
+	"GophKeeper/internal/model"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrRecipientNotFound — пользователя-получателя нет.
+	ErrRecipientNotFound = errors.New("recipient not found")
+	// ErrRecipientHasNoKey — получатель ещё не опубликовал открытый ключ.
+	ErrRecipientHasNoKey = errors.New("recipient has no public key")
+	// ErrShareNotFound — запись не открыта этому получателю.
+	ErrShareNotFound = errors.New("share not found")
+)
+
+// ShareRepository — записи, открытые владельцами другим пользователям.
+type ShareRepository interface {
+	// Recipient возвращает id и открытый ключ пользователя login
+	// (ErrRecipientNotFound, ErrRecipientHasNoKey).
+	Recipient(ctx context.Context, login string) (int64, []byte, error)
+
+	// Upsert сохраняет долю записи для получателя, заменяя прежнюю.
+	Upsert(ctx context.Context, s *model.ItemShare) error
+
+	// Delete закрывает доступ получателя к записи владельца (ErrShareNotFound).
+	Delete(ctx context.Context, ownerID int64, itemID string, recipientID int64) error
+
+	// ListIncoming возвращает неудалённые записи, открытые пользователю, по имени.
+	ListIncoming(ctx context.Context, recipientID int64) ([]model.IncomingShare, error)
+
+	// ListOutgoing возвращает доли, выданные владельцем, с открытыми ключами получателей.
+	ListOutgoing(ctx context.Context, ownerID int64) ([]model.OutgoingShare, error)
+}
+
+type shareRepo struct {
+	db *gorm.DB
+}
+
+// NewShareRepository создаёт реализацию репозитория общих записей.
+func NewShareRepository(db *gorm.DB) ShareRepository {
+	return &shareRepo{db: db}
+}
+
+func (r *shareRepo) Recipient(ctx context.Context, login string) (int64, []byte, error) {
+	var u model.User
+	err := r.db.WithContext(ctx).Where("login = ?", login).First(&u).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil, ErrRecipientNotFound
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	k, err := NewKeyRepository(r.db).GetKeys(ctx, u.ID)
+	if errors.Is(err, ErrKeysNotFound) {
+		return 0, nil, ErrRecipientHasNoKey
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	return u.ID, k.PublicKey, nil
+}
+
+func (r *shareRepo) Upsert(ctx context.Context, s *model.ItemShare) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "item_id"}, {Name: "recipient_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"version", "ephemeral_key", "wrapped_key", "wrapped_key_nonce",
+			"login_cipher", "login_nonce", "password_cipher", "password_nonce",
+			"text_cipher", "text_nonce", "card_cipher", "card_nonce", "updated_at",
+		}),
+	}).Create(s).Error
+}
+
+func (r *shareRepo) Delete(ctx context.Context, ownerID int64, itemID string, recipientID int64) error {
+	res := r.db.WithContext(ctx).
+		Where("owner_id = ? AND item_id = ? AND recipient_id = ?", ownerID, itemID, recipientID).
+		Delete(&model.ItemShare{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrShareNotFound
+	}
+	return nil
+}
+
+func (r *shareRepo) ListIncoming(ctx context.Context, recipientID int64) ([]model.IncomingShare, error) {
+	var out []model.IncomingShare
+	err := r.db.WithContext(ctx).
+		Table("item_shares").
+		Select("item_shares.*, items.name AS name, users.login AS owner_login, items.version AS item_version").
+		Joins("JOIN items ON items.id = item_shares.item_id").
+		Joins("JOIN users ON users.id = item_shares.owner_id").
+		Where("item_shares.recipient_id = ? AND items.deleted = ?", recipientID, false).
+		Order("items.name asc, users.login asc").
+		Scan(&out).Error
+	return out, err
+}
+
+func (r *shareRepo) ListOutgoing(ctx context.Context, ownerID int64) ([]model.OutgoingShare, error) {
+	var out []model.OutgoingShare
+	err := r.db.WithContext(ctx).
+		Table("item_shares").
+		Select("item_shares.item_id, items.name AS name, users.login AS recipient_login, "+
+			"user_keys.public_key AS recipient_public_key, item_shares.version, items.version AS item_version").
+		Joins("JOIN items ON items.id = item_shares.item_id").
+		Joins("JOIN users ON users.id = item_shares.recipient_id").
+		Joins("JOIN user_keys ON user_keys.user_id = item_shares.recipient_id").
+		Where("item_shares.owner_id = ? AND items.deleted = ?", ownerID, false).
+		Order("items.name asc, users.login asc").
+		Scan(&out).Error
+	return out, err
+}
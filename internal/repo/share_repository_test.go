@@ -0,0 +1,92 @@
+package repo
+
+import (
+	"GophKeeper/internal/model"
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShareRepository(t *testing.T) {
+	db := newFileDB(t)
+	ctx := context.Background()
+	m, err := NewMigrator(db)
+	require.NoError(t, err)
+	_, err = m.Up(ctx)
+	require.NoError(t, err)
+
+	users := NewUserRepository(db)
+	alice, err := users.CreateUser(ctx, &model.User{Login: "alice", Password: "h"})
+	require.NoError(t, err)
+	bob, err := users.CreateUser(ctx, &model.User{Login: "bob", Password: "h"})
+	require.NoError(t, err)
+	_, err = users.CreateUser(ctx, &model.User{Login: "carol", Password: "h"})
+	require.NoError(t, err)
+
+	keys := NewKeyRepository(db)
+	_, err = keys.GetKeys(ctx, bob.ID)
+	assert.ErrorIs(t, err, ErrKeysNotFound)
+	require.NoError(t, keys.SaveKeys(ctx, &model.UserKeys{UserID: bob.ID, PublicKey: []byte("pub-1"), WrappedPrivateKey: []byte("w"), PrivateKeyNonce: []byte("n")}))
+	require.NoError(t, keys.SaveKeys(ctx, &model.UserKeys{UserID: bob.ID, PublicKey: []byte("pub-2"), WrappedPrivateKey: []byte("w"), PrivateKeyNonce: []byte("n")}))
+	k, err := keys.GetKeys(ctx, bob.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("pub-2"), k.PublicKey, "повторная публикация заменяет ключи")
+
+	r := NewShareRepository(db)
+	_, _, err = r.Recipient(ctx, "nobody")
+	assert.ErrorIs(t, err, ErrRecipientNotFound)
+	_, _, err = r.Recipient(ctx, "carol")
+	assert.ErrorIs(t, err, ErrRecipientHasNoKey)
+	bobID, pub, err := r.Recipient(ctx, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, bob.ID, bobID)
+	assert.Equal(t, []byte("pub-2"), pub)
+
+	items := NewItemRepository(db)
+	it := &model.Item{ID: uuid.NewString(), UserID: alice.ID, Name: "staging-db"}
+	require.NoError(t, items.Create(ctx, it))
+
+	share := &model.ItemShare{ItemID: it.ID, OwnerID: alice.ID, RecipientID: bob.ID, Version: 1,
+		EphemeralKey: []byte("e"), WrappedKey: []byte("k"), WrappedKeyNonce: []byte("n"), LoginCipher: []byte("c1")}
+	require.NoError(t, r.Upsert(ctx, share))
+	// повторная выдача заменяет шифртексты той же доли
+	require.NoError(t, r.Upsert(ctx, &model.ItemShare{ItemID: it.ID, OwnerID: alice.ID, RecipientID: bob.ID, Version: 1,
+		EphemeralKey: []byte("e"), WrappedKey: []byte("k"), WrappedKeyNonce: []byte("n"), LoginCipher: []byte("c2")}))
+
+	in, err := r.ListIncoming(ctx, bob.ID)
+	require.NoError(t, err)
+	require.Len(t, in, 1)
+	assert.Equal(t, "staging-db", in[0].Name)
+	assert.Equal(t, "alice", in[0].OwnerLogin)
+	assert.Equal(t, []byte("c2"), in[0].LoginCipher)
+	assert.Equal(t, it.Version, in[0].ItemVersion)
+
+	out, err := r.ListOutgoing(ctx, alice.ID)
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	assert.Equal(t, "bob", out[0].RecipientLogin)
+	assert.Equal(t, []byte("pub-2"), out[0].RecipientPublicKey)
+	assert.Equal(t, int64(1), out[0].Version)
+
+	// новый ключ получателя — доля устаревает и будет перешифрована владельцем
+	require.NoError(t, keys.SaveKeys(ctx, &model.UserKeys{UserID: bob.ID, PublicKey: []byte("pub-3"), WrappedPrivateKey: []byte("w"), PrivateKeyNonce: []byte("n")}))
+	out, err = r.ListOutgoing(ctx, alice.ID)
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	assert.Equal(t, int64(0), out[0].Version)
+	assert.Equal(t, []byte("pub-3"), out[0].RecipientPublicKey)
+
+	// удалённая владельцем запись получателю не показывается
+	_, err = items.UpdateWithVersion(ctx, alice.ID, it.ID, it.Version, map[string]any{"deleted": true})
+	require.NoError(t, err)
+	in, err = r.ListIncoming(ctx, bob.ID)
+	require.NoError(t, err)
+	assert.Empty(t, in)
+
+	assert.ErrorIs(t, r.Delete(ctx, bob.ID, it.ID, bob.ID), ErrShareNotFound, "закрыть доступ может только владелец")
+	require.NoError(t, r.Delete(ctx, alice.ID, it.ID, bob.ID))
+	assert.ErrorIs(t, r.Delete(ctx, alice.ID, it.ID, bob.ID), ErrShareNotFound)
+}
@@ -0,0 +1,89 @@
+package repo
+
+import (
+	"GophKeeper/internal/telemetry"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const tracingSpanKey = "gk:span"
+
+// tracingPlugin открывает span на каждый запрос gorm (gorm.create, gorm.query и т.д.)
+// как дочерний к span'у из контекста запроса.
+type tracingPlugin struct{}
+
+func (tracingPlugin) Name() string { return "gk:tracing" }
+
+func (tracingPlugin) Initialize(db *gorm.DB) error {
+	for _, op := range []string{"create", "query", "update", "delete", "row", "raw"} {
+		if err := registerTracing(db, op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func registerTracing(db *gorm.DB, op string) error {
+	cb := db.Callback()
+	var before, after func(name string, fn func(*gorm.DB)) error
+	switch op {
+	case "create":
+		before = cb.Create().Before("gorm:create").Register
+		after = cb.Create().After("gorm:create").Register
+	case "query":
+		before = cb.Query().Before("gorm:query").Register
+		after = cb.Query().After("gorm:query").Register
+	case "update":
+		before = cb.Update().Before("gorm:update").Register
+		after = cb.Update().After("gorm:update").Register
+	case "delete":
+		before = cb.Delete().Before("gorm:delete").Register
+		after = cb.Delete().After("gorm:delete").Register
+	case "row":
+		before = cb.Row().Before("gorm:row").Register
+		after = cb.Row().After("gorm:row").Register
+	case "raw":
+		before = cb.Raw().Before("gorm:raw").Register
+		after = cb.Raw().After("gorm:raw").Register
+	}
+	if err := before("gk:trace_before_"+op, startSpan("gorm."+op)); err != nil {
+		return err
+	}
+	return after("gk:trace_after_"+op, endSpan)
+}
+
+func startSpan(name string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		if tx.Statement.Context == nil {
+			return
+		}
+		ctx, span := telemetry.Start(tx.Statement.Context, name, trace.WithSpanKind(trace.SpanKindClient))
+		tx.Statement.Context = ctx
+		tx.InstanceSet(tracingSpanKey, span)
+	}
+}
+
+func endSpan(tx *gorm.DB) {
+	v, ok := tx.InstanceGet(tracingSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := v.(trace.Span)
+	if !ok {
+		return
+	}
+	span.SetAttributes(
+		attribute.String("db.system", tx.Dialector.Name()),
+		attribute.String("db.sql.table", tx.Statement.Table),
+		attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+	)
+	err := tx.Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// «не найдено» — обычный исход, не ошибка запроса
+		err = nil
+	}
+	telemetry.End(span, err)
+}
@@ -0,0 +1,44 @@
+package repo
+
+import (
+	"GophKeeper/internal/model"
+	"GophKeeper/internal/telemetry"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracingPlugin(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	db := newTestDB(t)
+	require.NoError(t, db.Use(tracingPlugin{}))
+	r := NewUserRepository(db)
+
+	ctx, parent := telemetry.Start(context.Background(), "request")
+	_, err := r.CreateUser(ctx, &model.User{Login: "traced", Password: "hash"})
+	require.NoError(t, err)
+	_, err = r.GetUserByLogin(ctx, "traced-missing")
+	require.Error(t, err)
+	parent.End()
+
+	byName := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range rec.Ended() {
+		byName[s.Name()] = s
+	}
+	for _, name := range []string{"gorm.create", "gorm.query"} {
+		s, ok := byName[name]
+		require.True(t, ok, name)
+		assert.Equal(t, parent.SpanContext().SpanID(), s.Parent().SpanID(), "запрос — дочерний спан запроса")
+		assert.NotEqual(t, codes.Error, s.Status().Code, "«не найдено» не считается ошибкой")
+	}
+}
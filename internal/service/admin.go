@@ -0,0 +1,165 @@
+package service
+
+import (
+	"GophKeeper/internal/model"
+	"GophKeeper/internal/repo"
+	"GophKeeper/internal/telemetry"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserInfo — пользователь с состоянием и использованием хранилища (gkserver admin users).
+type UserInfo struct {
+	model.User
+	State model.UserState
+	Usage Usage
+}
+
+// AdminService — операции администратора сервера над пользователями и хранилищем.
+type AdminService struct {
+	admin repo.AdminRepository
+	users repo.UserRepository
+	items *ItemService
+}
+
+// NewAdminService создаёт административный сервис поверх сервиса записей
+// (использование квот, блобы и организации пользователей).
+func NewAdminService(admin repo.AdminRepository, users repo.UserRepository, items *ItemService) *AdminService {
+	return &AdminService{admin: admin, users: users, items: items}
+}
+
+// Users возвращает всех пользователей с их состоянием и использованием хранилища.
+func (s *AdminService) Users(ctx context.Context) (_ []UserInfo, err error) {
+	ctx, span := telemetry.Start(ctx, "AdminService.Users")
+	defer func() { telemetry.End(span, err) }()
+
+	users, err := s.admin.ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]UserInfo, 0, len(users))
+	for _, u := range users {
+		st, err := s.admin.State(ctx, u.ID)
+		if err != nil {
+			return nil, fmt.Errorf("state of %s: %w", u.Login, err)
+		}
+		usage, err := s.items.Usage(ctx, u.ID)
+		if err != nil {
+			return nil, fmt.Errorf("usage of %s: %w", u.Login, err)
+		}
+		out = append(out, UserInfo{User: u, State: *st, Usage: usage})
+	}
+	return out, nil
+}
+
+// SetDisabled отключает или включает пользователя login. Отключение отзывает и выданные
+// токены, чтобы после включения прежние сессии не ожили.
+func (s *AdminService) SetDisabled(ctx context.Context, login string, disabled bool) (err error) {
+	ctx, span := telemetry.Start(ctx, "AdminService.SetDisabled")
+	defer func() { telemetry.End(span, err) }()
+
+	u, err := s.user(ctx, login)
+	if err != nil {
+		return err
+	}
+	if err := s.admin.SetDisabled(ctx, u.ID, disabled); err != nil {
+		return err
+	}
+	if !disabled {
+		return nil
+	}
+	return s.admin.RevokeSessions(ctx, u.ID, time.Now())
+}
+
+// RevokeSessions делает недействительными все выданные пользователю login токены.
+func (s *AdminService) RevokeSessions(ctx context.Context, login string) (err error) {
+	ctx, span := telemetry.Start(ctx, "AdminService.RevokeSessions")
+	defer func() { telemetry.End(span, err) }()
+
+	u, err := s.user(ctx, login)
+	if err != nil {
+		return err
+	}
+	return s.admin.RevokeSessions(ctx, u.ID, time.Now())
+}
+
+// DeleteUser удаляет пользователя login со всеми данными и содержимым его блобов и
+// возвращает число удалённых блобов. Единственного владельца организации, в которой
+// есть другие участники, удалить нельзя (ErrLastOwner): сначала нужно передать права.
+func (s *AdminService) DeleteUser(ctx context.Context, login string) (_ int, err error) {
+	ctx, span := telemetry.Start(ctx, "AdminService.DeleteUser")
+	defer func() { telemetry.End(span, err) }()
+
+	u, err := s.user(ctx, login)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.checkOrgOwnership(ctx, u.ID); err != nil {
+		return 0, err
+	}
+	blobIDs, err := s.admin.DeleteUser(ctx, u.ID)
+	if err != nil {
+		return 0, err
+	}
+	if s.items.blobStore == nil {
+		return 0, nil
+	}
+	// метаданных уже нет: неудалённое содержимое остаётся в хранилище без ссылок
+	deleted := 0
+	for _, id := range blobIDs {
+		if err := s.items.blobStore.Delete(ctx, id); err != nil {
+			return deleted, fmt.Errorf("delete blob %s: %w", id, err)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// BlobStats возвращает статистику блобов, удалённых записей и истории версий.
+func (s *AdminService) BlobStats(ctx context.Context) (_ *repo.BlobStats, err error) {
+	ctx, span := telemetry.Start(ctx, "AdminService.BlobStats")
+	defer func() { telemetry.End(span, err) }()
+
+	return s.admin.BlobStats(ctx)
+}
+
+// user находит пользователя по логину (repo.ErrUserNotFound).
+func (s *AdminService) user(ctx context.Context, login string) (*model.User, error) {
+	u, err := s.users.GetUserByLogin(ctx, login)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: %s", repo.ErrUserNotFound, login)
+	}
+	return u, err
+}
+
+// checkOrgOwnership не даёт удалить последнего владельца организации с другими участниками.
+func (s *AdminService) checkOrgOwnership(ctx context.Context, userID int64) error {
+	if s.items.orgs == nil {
+		return nil
+	}
+	memberships, err := s.items.orgs.ListMemberships(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, m := range memberships {
+		if m.Role != model.RoleOwner {
+			continue
+		}
+		owners, err := s.items.orgs.CountOwners(ctx, m.ID)
+		if err != nil {
+			return err
+		}
+		members, err := s.items.orgs.ListMembers(ctx, m.ID)
+		if err != nil {
+			return err
+		}
+		if owners == 1 && len(members) > 1 {
+			return fmt.Errorf("%w: %s", ErrLastOwner, m.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"GophKeeper/internal/model"
+	"GophKeeper/internal/repo"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type mockAdminRepo struct{ mock.Mock }
+
+func (m *mockAdminRepo) ListUsers(ctx context.Context) ([]model.User, error) {
+	args := m.Called(ctx)
+	out, _ := args.Get(0).([]model.User)
+	return out, args.Error(1)
+}
+
+func (m *mockAdminRepo) State(ctx context.Context, userID int64) (*model.UserState, error) {
+	args := m.Called(ctx, userID)
+	st, _ := args.Get(0).(*model.UserState)
+	return st, args.Error(1)
+}
+
+func (m *mockAdminRepo) SetDisabled(ctx context.Context, userID int64, disabled bool) error {
+	return m.Called(ctx, userID, disabled).Error(0)
+}
+
+func (m *mockAdminRepo) RevokeSessions(ctx context.Context, userID int64, at time.Time) error {
+	return m.Called(ctx, userID, at).Error(0)
+}
+
+func (m *mockAdminRepo) DeleteUser(ctx context.Context, userID int64) ([]string, error) {
+	args := m.Called(ctx, userID)
+	out, _ := args.Get(0).([]string)
+	return out, args.Error(1)
+}
+
+func (m *mockAdminRepo) BlobStats(ctx context.Context) (*repo.BlobStats, error) {
+	args := m.Called(ctx)
+	st, _ := args.Get(0).(*repo.BlobStats)
+	return st, args.Error(1)
+}
+
+var _ repo.AdminRepository = (*mockAdminRepo)(nil)
+
+// newAdminTestService — административный сервис с пользователем alice (id 1).
+func newAdminTestService() (*AdminService, *mockAdminRepo, *mockItemRepo, *mockBlobRepo, *memBlobStore) {
+	ar, ur := new(mockAdminRepo), new(mockUserRepo)
+	ir, br, bs := new(mockItemRepo), new(mockBlobRepo), newMemBlobStore()
+	ur.On("GetUserByLogin", mock.Anything, "alice").Return(&model.User{ID: 1, Login: "alice"}, nil).Maybe()
+	ur.On("GetUserByLogin", mock.Anything, "nobody").Return(nil, gorm.ErrRecordNotFound).Maybe()
+	items := NewItemService(ir, br, bs, zap.NewNop().Sugar())
+	return NewAdminService(ar, ur, items), ar, ir, br, bs
+}
+
+func TestAdminService_Users(t *testing.T) {
+	ctx := context.Background()
+	svc, ar, ir, br, _ := newAdminTestService()
+	ar.On("ListUsers", mock.Anything).Return([]model.User{{ID: 1, Login: "alice"}}, nil).Once()
+	ar.On("State", mock.Anything, int64(1)).Return(&model.UserState{UserID: 1, Disabled: true}, nil).Once()
+	ir.On("CountByUser", mock.Anything, int64(1)).Return(int64(3), nil).Once()
+	br.On("SumSizeByUser", mock.Anything, int64(1)).Return(int64(2048), nil).Once()
+
+	users, err := svc.Users(ctx)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, "alice", users[0].Login)
+	assert.True(t, users[0].State.Disabled)
+	assert.Equal(t, int64(3), users[0].Usage.Items)
+	assert.Equal(t, int64(2048), users[0].Usage.BlobBytes)
+}
+
+func TestAdminService_DisableAndRevoke(t *testing.T) {
+	ctx := context.Background()
+	svc, ar, _, _, _ := newAdminTestService()
+	ar.On("SetDisabled", mock.Anything, int64(1), true).Return(nil).Once()
+	ar.On("SetDisabled", mock.Anything, int64(1), false).Return(nil).Once()
+	// отключение отзывает токены, включение — нет
+	ar.On("RevokeSessions", mock.Anything, int64(1), mock.Anything).Return(nil).Twice()
+
+	require.NoError(t, svc.SetDisabled(ctx, "alice", true))
+	require.NoError(t, svc.SetDisabled(ctx, "alice", false))
+	require.NoError(t, svc.RevokeSessions(ctx, "alice"))
+	assert.ErrorIs(t, svc.RevokeSessions(ctx, "nobody"), repo.ErrUserNotFound)
+	assert.ErrorIs(t, svc.SetDisabled(ctx, "nobody", true), repo.ErrUserNotFound)
+	ar.AssertExpectations(t)
+}
+
+func TestAdminService_DeleteUser(t *testing.T) {
+	ctx := context.Background()
+	svc, ar, _, _, bs := newAdminTestService()
+	bs.data["b1"], bs.data["b2"], bs.data["other"] = []byte("1"), []byte("2"), []byte("3")
+	ar.On("DeleteUser", mock.Anything, int64(1)).Return([]string{"b1", "b2"}, nil).Once()
+
+	n, err := svc.DeleteUser(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, map[string][]byte{"other": []byte("3")}, bs.data)
+	_, err = svc.DeleteUser(ctx, "nobody")
+	assert.ErrorIs(t, err, repo.ErrUserNotFound)
+	ar.AssertExpectations(t)
+}
+
+func TestAdminService_DeleteUserLastOwner(t *testing.T) {
+	ctx := context.Background()
+	svc, ar, _, _, _ := newAdminTestService()
+	or := new(mockOrgRepo)
+	svc.items.SetOrgRepository(or)
+	or.On("ListMemberships", mock.Anything, int64(1)).Return([]model.OrgMembership{
+		{Organization: model.Organization{ID: 7, Name: "solo"}, Role: model.RoleOwner},
+		{Organization: model.Organization{ID: 8, Name: "acme"}, Role: model.RoleOwner},
+	}, nil)
+	or.On("CountOwners", mock.Anything, int64(7)).Return(int64(1), nil)
+	or.On("ListMembers", mock.Anything, int64(7)).Return([]model.OrgMemberInfo{{UserID: 1}}, nil)
+	or.On("CountOwners", mock.Anything, int64(8)).Return(int64(1), nil)
+	or.On("ListMembers", mock.Anything, int64(8)).Return([]model.OrgMemberInfo{{UserID: 1}, {UserID: 2}}, nil)
+
+	_, err := svc.DeleteUser(ctx, "alice")
+	assert.ErrorIs(t, err, ErrLastOwner)
+	assert.ErrorContains(t, err, "acme")
+	ar.AssertNotCalled(t, "DeleteUser", mock.Anything, mock.Anything)
+}
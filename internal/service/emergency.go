@@ -0,0 +1,208 @@
+package service
+
+import (
+	"GophKeeper/internal/model"
+	"GophKeeper/internal/repo"
+	"GophKeeper/internal/telemetry"
+	"context"
+	"errors"
+	"time"
+)
+
+// MaxEmergencyWaitDays — наибольший период ожидания экстренного доступа.
+const MaxEmergencyWaitDays = 90
+
+var (
+	// ErrEmergencySelf — попытка назначить экстренным контактом самого себя.
+	ErrEmergencySelf = errors.New("cannot designate yourself as an emergency contact")
+	// ErrInvalidWaitPeriod — период ожидания вне 1..MaxEmergencyWaitDays дней.
+	ErrInvalidWaitPeriod = errors.New("invalid waiting period")
+	// ErrEmergencyNotRequested — одобрять или отклонять нечего: контакт не запрашивал доступ.
+	ErrEmergencyNotRequested = errors.New("emergency access was not requested")
+	// ErrEmergencyPending — доступ ещё не открыт: нет запроса или не истёк период ожидания.
+	ErrEmergencyPending = errors.New("emergency access is not approved yet")
+)
+
+// SetEmergencyRepository задаёт репозиторий экстренного доступа.
+func (s *ItemService) SetEmergencyRepository(r repo.EmergencyRepository) {
+	s.emergency = r
+}
+
+// GrantEmergency назначает пользователя contactLogin экстренным контактом владельца с
+// периодом ожидания waitDays. key — ключ хранилища владельца, запечатанный открытым
+// ключом контакта. Повторное назначение заменяет ключ и сбрасывает запрос.
+func (s *ItemService) GrantEmergency(ctx context.Context, ownerID int64, contactLogin string, waitDays int, key *model.EmergencyAccess) (err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.GrantEmergency")
+	defer func() { telemetry.End(span, err) }()
+
+	if s.emergency == nil {
+		return errors.New("emergency repository not configured")
+	}
+	if waitDays < 1 || waitDays > MaxEmergencyWaitDays {
+		return ErrInvalidWaitPeriod
+	}
+	contactID, pub, err := s.emergency.User(ctx, contactLogin)
+	if err != nil {
+		return err
+	}
+	if contactID == ownerID {
+		return ErrEmergencySelf
+	}
+	if len(pub) == 0 {
+		return repo.ErrRecipientHasNoKey
+	}
+	key.OwnerID, key.ContactID, key.WaitDays = ownerID, contactID, waitDays
+	key.Status, key.RequestedAt = model.EmergencyGranted, nil
+	return s.emergency.Save(ctx, key)
+}
+
+// RevokeEmergency отзывает экстренный доступ контакта.
+func (s *ItemService) RevokeEmergency(ctx context.Context, ownerID int64, contactLogin string) (err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.RevokeEmergency")
+	defer func() { telemetry.End(span, err) }()
+
+	e, err := s.ownEmergency(ctx, ownerID, contactLogin)
+	if err != nil {
+		return err
+	}
+	return s.emergency.Delete(ctx, e.OwnerID, e.ContactID)
+}
+
+// ApproveEmergency открывает контакту доступ до истечения периода ожидания.
+func (s *ItemService) ApproveEmergency(ctx context.Context, ownerID int64, contactLogin string) (err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.ApproveEmergency")
+	defer func() { telemetry.End(span, err) }()
+
+	e, err := s.ownEmergency(ctx, ownerID, contactLogin)
+	if err != nil {
+		return err
+	}
+	switch e.EffectiveStatus(time.Now()) {
+	case model.EmergencyApproved:
+		return nil
+	case model.EmergencyRequested:
+		return s.emergency.SetStatus(ctx, e.OwnerID, e.ContactID, model.EmergencyApproved, e.RequestedAt)
+	}
+	return ErrEmergencyNotRequested
+}
+
+// RejectEmergency отклоняет запрос контакта (или закрывает уже открытый доступ):
+// контакт остаётся назначенным и может запросить доступ снова.
+func (s *ItemService) RejectEmergency(ctx context.Context, ownerID int64, contactLogin string) (err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.RejectEmergency")
+	defer func() { telemetry.End(span, err) }()
+
+	e, err := s.ownEmergency(ctx, ownerID, contactLogin)
+	if err != nil {
+		return err
+	}
+	if e.Status == model.EmergencyGranted {
+		return ErrEmergencyNotRequested
+	}
+	return s.emergency.SetStatus(ctx, e.OwnerID, e.ContactID, model.EmergencyGranted, nil)
+}
+
+// RequestEmergency запрашивает экстренный доступ к хранилищу владельца ownerLogin;
+// повторный запрос не сдвигает период ожидания.
+func (s *ItemService) RequestEmergency(ctx context.Context, contactID int64, ownerLogin string) (_ *model.EmergencyAccess, err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.RequestEmergency")
+	defer func() { telemetry.End(span, err) }()
+
+	e, err := s.grantedEmergency(ctx, contactID, ownerLogin)
+	if err != nil {
+		return nil, err
+	}
+	if e.Status == model.EmergencyGranted {
+		now := time.Now().UTC()
+		if err := s.emergency.SetStatus(ctx, e.OwnerID, e.ContactID, model.EmergencyRequested, &now); err != nil {
+			return nil, err
+		}
+		e.Status, e.RequestedAt = model.EmergencyRequested, &now
+	}
+	e.Status = e.EffectiveStatus(time.Now())
+	return e, nil
+}
+
+// EmergencyVault возвращает контакту ключ хранилища владельца, запечатанный для него,
+// и записи владельца — только если доступ открыт (иначе ErrEmergencyPending).
+func (s *ItemService) EmergencyVault(ctx context.Context, contactID int64, ownerLogin string) (_ *model.EmergencyAccess, _ []model.Item, err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.EmergencyVault")
+	defer func() { telemetry.End(span, err) }()
+
+	e, err := s.grantedEmergency(ctx, contactID, ownerLogin)
+	if err != nil {
+		return nil, nil, err
+	}
+	if e.EffectiveStatus(time.Now()) != model.EmergencyApproved {
+		return nil, nil, ErrEmergencyPending
+	}
+	items, err := s.ListItems(ctx, e.OwnerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	e.Status = model.EmergencyApproved
+	return e, items, nil
+}
+
+// EmergencyContacts возвращает экстренные контакты владельца с текущими состояниями.
+func (s *ItemService) EmergencyContacts(ctx context.Context, ownerID int64) (_ []model.EmergencyContact, err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.EmergencyContacts")
+	defer func() { telemetry.End(span, err) }()
+
+	if s.emergency == nil {
+		return nil, errors.New("emergency repository not configured")
+	}
+	list, err := s.emergency.ListByOwner(ctx, ownerID)
+	return withEffectiveStatus(list), err
+}
+
+// EmergencyGrants возвращает владельцев, назначивших пользователя экстренным контактом.
+func (s *ItemService) EmergencyGrants(ctx context.Context, contactID int64) (_ []model.EmergencyContact, err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.EmergencyGrants")
+	defer func() { telemetry.End(span, err) }()
+
+	if s.emergency == nil {
+		return nil, errors.New("emergency repository not configured")
+	}
+	list, err := s.emergency.ListByContact(ctx, contactID)
+	return withEffectiveStatus(list), err
+}
+
+// ownEmergency находит экстренный доступ контакта contactLogin к хранилищу владельца.
+func (s *ItemService) ownEmergency(ctx context.Context, ownerID int64, contactLogin string) (*model.EmergencyAccess, error) {
+	if s.emergency == nil {
+		return nil, errors.New("emergency repository not configured")
+	}
+	contactID, _, err := s.emergency.User(ctx, contactLogin)
+	if errors.Is(err, repo.ErrRecipientNotFound) {
+		return nil, repo.ErrEmergencyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.emergency.Get(ctx, ownerID, contactID)
+}
+
+// grantedEmergency находит экстренный доступ пользователя к хранилищу владельца ownerLogin;
+// несуществующий владелец неотличим от отсутствия доступа.
+func (s *ItemService) grantedEmergency(ctx context.Context, contactID int64, ownerLogin string) (*model.EmergencyAccess, error) {
+	if s.emergency == nil {
+		return nil, errors.New("emergency repository not configured")
+	}
+	ownerID, _, err := s.emergency.User(ctx, ownerLogin)
+	if errors.Is(err, repo.ErrRecipientNotFound) {
+		return nil, repo.ErrEmergencyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.emergency.Get(ctx, ownerID, contactID)
+}
+
+func withEffectiveStatus(list []model.EmergencyContact) []model.EmergencyContact {
+	now := time.Now()
+	for i := range list {
+		list[i].Status = list[i].EffectiveStatus(now)
+	}
+	return list
+}
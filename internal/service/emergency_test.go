@@ -0,0 +1,222 @@
+package service
+
+import (
+	"GophKeeper/internal/model"
+	"GophKeeper/internal/repo"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type mockEmergencyRepo struct{ mock.Mock }
+
+func (m *mockEmergencyRepo) User(ctx context.Context, login string) (int64, []byte, error) {
+	args := m.Called(ctx, login)
+	pub, _ := args.Get(1).([]byte)
+	return args.Get(0).(int64), pub, args.Error(2)
+}
+
+func (m *mockEmergencyRepo) Save(ctx context.Context, e *model.EmergencyAccess) error {
+	return m.Called(ctx, e).Error(0)
+}
+
+func (m *mockEmergencyRepo) Get(ctx context.Context, ownerID, contactID int64) (*model.EmergencyAccess, error) {
+	args := m.Called(ctx, ownerID, contactID)
+	e, _ := args.Get(0).(*model.EmergencyAccess)
+	return e, args.Error(1)
+}
+
+func (m *mockEmergencyRepo) SetStatus(ctx context.Context, ownerID, contactID int64, status string, requestedAt *time.Time) error {
+	return m.Called(ctx, ownerID, contactID, status, requestedAt).Error(0)
+}
+
+func (m *mockEmergencyRepo) Delete(ctx context.Context, ownerID, contactID int64) error {
+	return m.Called(ctx, ownerID, contactID).Error(0)
+}
+
+func (m *mockEmergencyRepo) ListByOwner(ctx context.Context, ownerID int64) ([]model.EmergencyContact, error) {
+	args := m.Called(ctx, ownerID)
+	out, _ := args.Get(0).([]model.EmergencyContact)
+	return out, args.Error(1)
+}
+
+func (m *mockEmergencyRepo) ListByContact(ctx context.Context, contactID int64) ([]model.EmergencyContact, error) {
+	args := m.Called(ctx, contactID)
+	out, _ := args.Get(0).([]model.EmergencyContact)
+	return out, args.Error(1)
+}
+
+var _ repo.EmergencyRepository = (*mockEmergencyRepo)(nil)
+
+// newEmergencyService — сервис с пользователями alice (1, владелец) и bob (2, контакт).
+func newEmergencyService() (*ItemService, *mockItemRepo, *mockEmergencyRepo) {
+	ir := new(mockItemRepo)
+	er := new(mockEmergencyRepo)
+	svc := NewItemService(ir, nil, nil, zap.NewNop().Sugar())
+	svc.SetEmergencyRepository(er)
+	er.On("User", mock.Anything, "alice").Return(int64(1), []byte("pub-a"), nil).Maybe()
+	er.On("User", mock.Anything, "bob").Return(int64(2), []byte("pub-b"), nil).Maybe()
+	er.On("User", mock.Anything, "carol").Return(int64(3), nil, nil).Maybe()
+	er.On("User", mock.Anything, "nobody").Return(int64(0), nil, repo.ErrRecipientNotFound).Maybe()
+	return svc, ir, er
+}
+
+func TestItemService_GrantEmergency(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		login   string
+		wait    int
+		wantErr error
+	}{
+		{name: "ok", login: "bob", wait: 7},
+		{name: "нулевой период", login: "bob", wait: 0, wantErr: ErrInvalidWaitPeriod},
+		{name: "слишком долгий период", login: "bob", wait: MaxEmergencyWaitDays + 1, wantErr: ErrInvalidWaitPeriod},
+		{name: "самому себе", login: "alice", wait: 7, wantErr: ErrEmergencySelf},
+		{name: "нет ключа", login: "carol", wait: 7, wantErr: repo.ErrRecipientHasNoKey},
+		{name: "нет пользователя", login: "nobody", wait: 7, wantErr: repo.ErrRecipientNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _, er := newEmergencyService()
+			if tt.wantErr == nil {
+				er.On("Save", mock.Anything, mock.MatchedBy(func(e *model.EmergencyAccess) bool {
+					return e.OwnerID == 1 && e.ContactID == 2 && e.WaitDays == tt.wait &&
+						e.Status == model.EmergencyGranted && e.RequestedAt == nil && string(e.WrappedKey) == "k"
+				})).Return(nil).Once()
+			}
+			requested := time.Now()
+			key := &model.EmergencyAccess{WrappedKey: []byte("k"), Status: model.EmergencyApproved, RequestedAt: &requested}
+			err := svc.GrantEmergency(ctx, 1, tt.login, tt.wait, key)
+			assert.ErrorIs(t, err, tt.wantErr)
+			er.AssertExpectations(t)
+		})
+	}
+}
+
+func TestItemService_EmergencyStateMachine(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	recent, expired := now.Add(-time.Hour), now.Add(-8*24*time.Hour)
+	access := func(status string, requestedAt *time.Time) *model.EmergencyAccess {
+		return &model.EmergencyAccess{OwnerID: 1, ContactID: 2, WaitDays: 7, Status: status, RequestedAt: requestedAt}
+	}
+
+	tests := []struct {
+		name      string
+		stored    *model.EmergencyAccess
+		action    func(svc *ItemService) error
+		setStatus string // ожидаемый вызов SetStatus; "" — без вызова
+		wantErr   error
+	}{
+		{name: "запрос", stored: access(model.EmergencyGranted, nil),
+			action:    func(svc *ItemService) error { _, err := svc.RequestEmergency(ctx, 2, "alice"); return err },
+			setStatus: model.EmergencyRequested},
+		{name: "повторный запрос не сдвигает период", stored: access(model.EmergencyRequested, &recent),
+			action: func(svc *ItemService) error { _, err := svc.RequestEmergency(ctx, 2, "alice"); return err }},
+		{name: "запрос без назначения", stored: nil,
+			action:  func(svc *ItemService) error { _, err := svc.RequestEmergency(ctx, 2, "alice"); return err },
+			wantErr: repo.ErrEmergencyNotFound},
+		{name: "запрос к неизвестному владельцу",
+			action:  func(svc *ItemService) error { _, err := svc.RequestEmergency(ctx, 2, "nobody"); return err },
+			wantErr: repo.ErrEmergencyNotFound},
+		{name: "одобрение запроса", stored: access(model.EmergencyRequested, &recent),
+			action:    func(svc *ItemService) error { return svc.ApproveEmergency(ctx, 1, "bob") },
+			setStatus: model.EmergencyApproved},
+		{name: "одобрять нечего", stored: access(model.EmergencyGranted, nil),
+			action:  func(svc *ItemService) error { return svc.ApproveEmergency(ctx, 1, "bob") },
+			wantErr: ErrEmergencyNotRequested},
+		{name: "отклонение запроса", stored: access(model.EmergencyRequested, &recent),
+			action:    func(svc *ItemService) error { return svc.RejectEmergency(ctx, 1, "bob") },
+			setStatus: model.EmergencyGranted},
+		{name: "отклонение после истечения периода закрывает доступ", stored: access(model.EmergencyRequested, &expired),
+			action:    func(svc *ItemService) error { return svc.RejectEmergency(ctx, 1, "bob") },
+			setStatus: model.EmergencyGranted},
+		{name: "отклонять нечего", stored: access(model.EmergencyGranted, nil),
+			action:  func(svc *ItemService) error { return svc.RejectEmergency(ctx, 1, "bob") },
+			wantErr: ErrEmergencyNotRequested},
+		{name: "хранилище до истечения периода", stored: access(model.EmergencyRequested, &recent),
+			action:  func(svc *ItemService) error { _, _, err := svc.EmergencyVault(ctx, 2, "alice"); return err },
+			wantErr: ErrEmergencyPending},
+		{name: "хранилище без запроса", stored: access(model.EmergencyGranted, nil),
+			action:  func(svc *ItemService) error { _, _, err := svc.EmergencyVault(ctx, 2, "alice"); return err },
+			wantErr: ErrEmergencyPending},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _, er := newEmergencyService()
+			if tt.stored != nil {
+				er.On("Get", mock.Anything, int64(1), int64(2)).Return(tt.stored, nil).Maybe()
+			} else {
+				er.On("Get", mock.Anything, int64(1), int64(2)).Return(nil, repo.ErrEmergencyNotFound).Maybe()
+			}
+			if tt.setStatus != "" {
+				er.On("SetStatus", mock.Anything, int64(1), int64(2), tt.setStatus, mock.Anything).Return(nil).Once()
+			}
+			assert.ErrorIs(t, tt.action(svc), tt.wantErr)
+			er.AssertExpectations(t)
+		})
+	}
+}
+
+func TestItemService_EmergencyVault(t *testing.T) {
+	ctx := context.Background()
+	expired := time.Now().Add(-8 * 24 * time.Hour)
+
+	svc, ir, er := newEmergencyService()
+	// владелец не отклонил запрос за 7 дней — доступ открыт без одобрения
+	er.On("Get", mock.Anything, int64(1), int64(2)).Return(&model.EmergencyAccess{
+		OwnerID: 1, ContactID: 2, WaitDays: 7, Status: model.EmergencyRequested, RequestedAt: &expired,
+		WrappedKey: []byte("k"),
+	}, nil).Once()
+	ir.On("ListAll", mock.Anything, int64(1)).Return([]model.Item{
+		{ID: "i1", Name: "wifi"}, {ID: "i2", Name: "old", Deleted: true},
+	}, nil).Once()
+
+	e, items, err := svc.EmergencyVault(ctx, 2, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, model.EmergencyApproved, e.Status)
+	assert.Equal(t, []byte("k"), e.WrappedKey)
+	require.Len(t, items, 1)
+	assert.Equal(t, "wifi", items[0].Name)
+	er.AssertExpectations(t)
+	ir.AssertExpectations(t)
+}
+
+func TestItemService_EmergencyLists(t *testing.T) {
+	ctx := context.Background()
+	recent, expired := time.Now().Add(-time.Hour), time.Now().Add(-8*24*time.Hour)
+
+	svc, _, er := newEmergencyService()
+	er.On("ListByOwner", mock.Anything, int64(1)).Return([]model.EmergencyContact{
+		{EmergencyAccess: model.EmergencyAccess{WaitDays: 7, Status: model.EmergencyRequested, RequestedAt: &recent}, ContactLogin: "bob"},
+		{EmergencyAccess: model.EmergencyAccess{WaitDays: 7, Status: model.EmergencyRequested, RequestedAt: &expired}, ContactLogin: "carol"},
+	}, nil).Once()
+	er.On("ListByContact", mock.Anything, int64(2)).Return([]model.EmergencyContact{
+		{EmergencyAccess: model.EmergencyAccess{WaitDays: 7, Status: model.EmergencyGranted}, OwnerLogin: "alice"},
+	}, nil).Once()
+
+	contacts, err := svc.EmergencyContacts(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, model.EmergencyRequested, contacts[0].Status)
+	assert.Equal(t, model.EmergencyApproved, contacts[1].Status)
+	grants, err := svc.EmergencyGrants(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, model.EmergencyGranted, grants[0].Status)
+}
+
+func TestItemService_RevokeEmergency(t *testing.T) {
+	ctx := context.Background()
+	svc, _, er := newEmergencyService()
+	er.On("Get", mock.Anything, int64(1), int64(2)).Return(&model.EmergencyAccess{OwnerID: 1, ContactID: 2}, nil).Once()
+	er.On("Delete", mock.Anything, int64(1), int64(2)).Return(nil).Once()
+
+	require.NoError(t, svc.RevokeEmergency(ctx, 1, "bob"))
+	assert.ErrorIs(t, svc.RevokeEmergency(ctx, 1, "nobody"), repo.ErrEmergencyNotFound)
+	er.AssertExpectations(t)
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"sync"
+)
+
+// ChangeEvent — уведомление о том, что данные пользователя изменились.
+// Событие лишь подсказывает клиенту синхронизироваться: сами изменения он получает через Sync.
+type ChangeEvent struct {
+	UserID  int64
+	Cursor  int64    // номер последнего изменения пользователя (0 — неизвестен)
+	ItemIDs []string // изменённые записи
+}
+
+// EventBroker рассылает события изменений подписчикам того же пользователя.
+// MemoryBroker работает в пределах одного процесса; для нескольких экземпляров
+// сервера реализацию можно заменить, например, на Postgres LISTEN/NOTIFY.
+type EventBroker interface {
+	// Publish доставляет событие текущим подписчикам ev.UserID.
+	Publish(ctx context.Context, ev ChangeEvent) error
+	// Subscribe подписывает на события пользователя. Канал закрывается
+	// после вызова cancel или завершения ctx.
+	Subscribe(ctx context.Context, userID int64) (events <-chan ChangeEvent, cancel func())
+}
+
+// subscriberBuffer — сколько событий ждёт медленного подписчика; при переполнении
+// старое событие вытесняется новым (для подсказки «синхронизируйся» важно только последнее).
+const subscriberBuffer = 8
+
+// MemoryBroker — внутрипроцессная реализация EventBroker.
+type MemoryBroker struct {
+	mu   sync.Mutex
+	subs map[int64]map[chan ChangeEvent]struct{}
+}
+
+// NewMemoryBroker создаёт пустой внутрипроцессный брокер.
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{subs: map[int64]map[chan ChangeEvent]struct{}{}}
+}
+
+// Publish рассылает событие без блокировки публикующего.
+func (b *MemoryBroker) Publish(_ context.Context, ev ChangeEvent) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs[ev.UserID] {
+		select {
+		case ch <- ev:
+			continue
+		default:
+		}
+		// буфер полон — вытесняем самое старое событие
+		select {
+		case <-ch:
+		default:
+		}
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+	return nil
+}
+
+// Subscribe регистрирует подписчика пользователя userID.
+func (b *MemoryBroker) Subscribe(ctx context.Context, userID int64) (<-chan ChangeEvent, func()) {
+	ch := make(chan ChangeEvent, subscriberBuffer)
+	b.mu.Lock()
+	if b.subs[userID] == nil {
+		b.subs[userID] = map[chan ChangeEvent]struct{}{}
+	}
+	b.subs[userID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs[userID], ch)
+			if len(b.subs[userID]) == 0 {
+				delete(b.subs, userID)
+			}
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+	go func() {
+		<-ctx.Done()
+		cancel()
+	}()
+	return ch, cancel
+}
+
+// SetEventBroker заменяет брокер событий изменений (по умолчанию — MemoryBroker).
+func (s *ItemService) SetEventBroker(b EventBroker) {
+	s.events = b
+}
+
+// SubscribeEvents подписывает на события изменений данных пользователя.
+func (s *ItemService) SubscribeEvents(ctx context.Context, userID int64) (<-chan ChangeEvent, func()) {
+	return s.events.Subscribe(ctx, userID)
+}
+
+// publishChanges уведомляет подписчиков пользователя об изменении записей ids.
+// Ошибки не влияют на результат операции: клиент всё равно получит изменения при следующем Sync.
+func (s *ItemService) publishChanges(ctx context.Context, userID int64, ids ...string) {
+	if len(ids) == 0 || s.events == nil {
+		return
+	}
+	ev := ChangeEvent{UserID: userID, ItemIDs: ids}
+	if seq, err := s.repo.LatestChangeSeq(ctx, userID); err == nil {
+		ev.Cursor = seq
+	} else {
+		s.log(ctx).Warnw("events: latest change seq failed", "user_id", userID, "error", err)
+	}
+	if err := s.events.Publish(ctx, ev); err != nil {
+		s.log(ctx).Warnw("events: publish failed", "user_id", userID, "error", err)
+	}
+}
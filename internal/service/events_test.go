@@ -0,0 +1,99 @@
+package service
+
+import (
+	"GophKeeper/internal/model"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestMemoryBroker_PublishToUserSubscribers(t *testing.T) {
+	b := NewMemoryBroker()
+	ctx := context.Background()
+	mine, cancelMine := b.Subscribe(ctx, 1)
+	defer cancelMine()
+	other, cancelOther := b.Subscribe(ctx, 2)
+	defer cancelOther()
+
+	assert.NoError(t, b.Publish(ctx, ChangeEvent{UserID: 1, Cursor: 5, ItemIDs: []string{"a"}}))
+	assert.Equal(t, ChangeEvent{UserID: 1, Cursor: 5, ItemIDs: []string{"a"}}, <-mine)
+	select {
+	case ev := <-other:
+		t.Fatalf("event leaked to another user: %+v", ev)
+	default:
+	}
+}
+
+func TestMemoryBroker_SlowSubscriberKeepsLatest(t *testing.T) {
+	b := NewMemoryBroker()
+	ch, cancel := b.Subscribe(context.Background(), 1)
+	defer cancel()
+	for i := 1; i <= subscriberBuffer+3; i++ {
+		assert.NoError(t, b.Publish(context.Background(), ChangeEvent{UserID: 1, Cursor: int64(i)}))
+	}
+	var last ChangeEvent
+	for len(ch) > 0 {
+		last = <-ch
+	}
+	assert.Equal(t, int64(subscriberBuffer+3), last.Cursor)
+}
+
+func TestMemoryBroker_CancelAndContextCloseChannel(t *testing.T) {
+	b := NewMemoryBroker()
+	ch, cancel := b.Subscribe(context.Background(), 1)
+	cancel()
+	cancel() // повторный вызов безопасен
+	_, ok := <-ch
+	assert.False(t, ok)
+
+	ctx, stop := context.WithCancel(context.Background())
+	ch2, _ := b.Subscribe(ctx, 1)
+	stop()
+	_, ok = <-ch2
+	assert.False(t, ok)
+	// после отписки публикация никого не блокирует
+	assert.NoError(t, b.Publish(context.Background(), ChangeEvent{UserID: 1}))
+}
+
+type recordingBroker struct {
+	*MemoryBroker
+	published []ChangeEvent
+}
+
+func (r *recordingBroker) Publish(ctx context.Context, ev ChangeEvent) error {
+	r.published = append(r.published, ev)
+	return r.MemoryBroker.Publish(ctx, ev)
+}
+
+func TestItemService_PublishesAppliedChanges(t *testing.T) {
+	ir := new(mockItemRepo)
+	svc := NewItemService(ir, new(mockBlobRepo), nil, zap.NewNop().Sugar())
+	rb := &recordingBroker{MemoryBroker: NewMemoryBroker()}
+	svc.SetEventBroker(rb)
+	ctx := context.Background()
+
+	ir.On("GetByID", mock.Anything, int64(3), "c1").Return((*model.Item)(nil), gorm.ErrRecordNotFound).Once()
+	ir.On("GetByID", mock.Anything, int64(3), "c2").Return(&model.Item{ID: "c2", UserID: 3, Version: 4, Name: "srv"}, nil).Once()
+	ir.On("Create", mock.Anything, mock.AnythingOfType("*model.Item")).Return(nil).Once()
+
+	_, err := svc.Sync(ctx, 3, SyncRequest{Changes: []SyncChange{
+		{ID: "c1", Version: ptrInt64(0), Name: ptrStr("new")},
+		{ID: "c2", Version: ptrInt64(1), Name: ptrStr("stale")},
+	}})
+	assert.NoError(t, err)
+	// конфликтующее изменение не публикуется
+	if assert.Len(t, rb.published, 1) {
+		assert.Equal(t, int64(3), rb.published[0].UserID)
+		assert.Equal(t, []string{"c1"}, rb.published[0].ItemIDs)
+	}
+
+	// батч без применённых изменений событий не порождает
+	_, err = svc.Sync(ctx, 3, SyncRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, rb.published, 1)
+	ir.AssertExpectations(t)
+}
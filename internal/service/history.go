@@ -0,0 +1,89 @@
+package service
+
+import (
+	"GophKeeper/internal/model"
+	"GophKeeper/internal/telemetry"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrHistoryVersionNotFound — запрошенной версии нет в истории элемента.
+var ErrHistoryVersionNotFound = errors.New("item version not found in history")
+
+// ItemHistory возвращает текущее состояние элемента (включая удалённый) и
+// сохранённые предыдущие версии, новые первыми.
+func (s *ItemService) ItemHistory(ctx context.Context, userID int64, id string) (_ *model.Item, _ []model.ItemVersion, err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.ItemHistory")
+	defer func() { telemetry.End(span, err) }()
+
+	current, err := s.repo.GetByID(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrItemNotFound
+		}
+		return nil, nil, err
+	}
+	versions, err := s.repo.ListVersions(ctx, userID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return current, versions, nil
+}
+
+// RestoreItemVersion записывает содержимое снимка version как новую версию элемента.
+// Текущее состояние при этом само попадает в историю, так что восстановление обратимо.
+func (s *ItemService) RestoreItemVersion(ctx context.Context, userID int64, id string, version int64) (_ *model.Item, err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.RestoreItemVersion")
+	defer func() { telemetry.End(span, err) }()
+
+	current, err := s.repo.GetByID(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrItemNotFound
+		}
+		return nil, err
+	}
+	snap, err := s.repo.GetVersion(ctx, userID, id, version)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrHistoryVersionNotFound
+		}
+		return nil, err
+	}
+	// восстановление удалённого элемента снова занимает место в квоте
+	if current.Deleted && !snap.Deleted && s.quota.MaxItems > 0 {
+		n, err := s.repo.CountByUser(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if n >= s.quota.MaxItems {
+			return nil, ErrQuotaExceeded
+		}
+	}
+	var blobID any
+	if snap.BlobID != nil && *snap.BlobID != "" {
+		blobID = *snap.BlobID
+	}
+	updates := map[string]any{
+		"name":            snap.Name,
+		"file_name":       snap.FileName,
+		"blob_id":         blobID,
+		"deleted":         snap.Deleted,
+		"login_cipher":    snap.LoginCipher,
+		"login_nonce":     snap.LoginNonce,
+		"password_cipher": snap.PasswordCipher,
+		"password_nonce":  snap.PasswordNonce,
+		"text_cipher":     snap.TextCipher,
+		"text_nonce":      snap.TextNonce,
+		"card_cipher":     snap.CardCipher,
+		"card_nonce":      snap.CardNonce,
+	}
+	it, err := s.updateWithVersion(ctx, userID, id, current.Version, updates)
+	if err != nil {
+		return nil, err
+	}
+	s.log(ctx).Infow("RestoreItemVersion: item restored", "user_id", userID, "item_id", id, "from_version", version, "new_version", it.Version)
+	return it, nil
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"GophKeeper/internal/model"
+	"GophKeeper/internal/repo"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	gormsqlite "gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	_ "modernc.org/sqlite"
+)
+
+// newSQLiteItemService — сервис поверх настоящих репозиториев (SQLite в памяти со схемой из миграций).
+func newSQLiteItemService(t *testing.T) (*ItemService, *gorm.DB) {
+	t.Helper()
+	dsn := "file:" + strings.ReplaceAll(t.Name(), "/", "_") + "?mode=memory&cache=shared"
+	db, err := gorm.Open(gormsqlite.Dialector{DriverName: "sqlite", DSN: dsn}, &gorm.Config{})
+	require.NoError(t, err)
+	m, err := repo.NewMigrator(db)
+	require.NoError(t, err)
+	_, err = m.Up(context.Background())
+	require.NoError(t, err)
+	store, err := repo.NewFSBlobStore(t.TempDir())
+	require.NoError(t, err)
+	return NewItemService(repo.NewItemRepository(db), repo.NewBlobRepository(db), store, zap.NewNop().Sugar()), db
+}
+
+func TestItemService_ItemHistory(t *testing.T) {
+	ir := new(mockItemRepo)
+	svc := NewItemService(ir, nil, nil, zap.NewNop().Sugar())
+	ctx := context.Background()
+
+	ir.On("GetByID", mock.Anything, int64(1), "h1").Return(&model.Item{ID: "h1", Version: 3, Deleted: true}, nil).Once()
+	ir.On("ListVersions", mock.Anything, int64(1), "h1").Return([]model.ItemVersion{{ItemID: "h1", Version: 2}, {ItemID: "h1", Version: 1}}, nil).Once()
+	cur, versions, err := svc.ItemHistory(ctx, 1, "h1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), cur.Version)
+	assert.Len(t, versions, 2)
+
+	ir.On("GetByID", mock.Anything, int64(1), "none").Return(nil, gorm.ErrRecordNotFound).Once()
+	_, _, err = svc.ItemHistory(ctx, 1, "none")
+	assert.ErrorIs(t, err, ErrItemNotFound)
+}
+
+func TestItemService_RestoreItemVersion(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("writes snapshot as new version", func(t *testing.T) {
+		ir := new(mockItemRepo)
+		svc := NewItemService(ir, nil, nil, zap.NewNop().Sugar())
+		ir.On("GetByID", mock.Anything, int64(1), "h1").Return(&model.Item{ID: "h1", Version: 5, Name: "bad"}, nil).Once()
+		ir.On("GetVersion", mock.Anything, int64(1), "h1", int64(3)).
+			Return(&model.ItemVersion{ItemID: "h1", Version: 3, Name: "good", PasswordCipher: []byte{7}}, nil).Once()
+		ir.On("UpdateWithVersion", mock.Anything, int64(1), "h1", int64(5), mock.MatchedBy(func(m map[string]any) bool {
+			pc, _ := m["password_cipher"].([]byte)
+			return m["name"] == "good" && m["deleted"] == false && m["blob_id"] == nil && len(pc) == 1
+		})).Return(int64(6), nil).Once()
+		ir.On("GetByID", mock.Anything, int64(1), "h1").Return(&model.Item{ID: "h1", Version: 6, Name: "good"}, nil).Once()
+
+		it, err := svc.RestoreItemVersion(ctx, 1, "h1", 3)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(6), it.Version)
+		ir.AssertExpectations(t)
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		ir := new(mockItemRepo)
+		svc := NewItemService(ir, nil, nil, zap.NewNop().Sugar())
+		ir.On("GetByID", mock.Anything, int64(1), "h1").Return(&model.Item{ID: "h1", Version: 5}, nil).Once()
+		ir.On("GetVersion", mock.Anything, int64(1), "h1", int64(9)).Return(nil, gorm.ErrRecordNotFound).Once()
+		_, err := svc.RestoreItemVersion(ctx, 1, "h1", 9)
+		assert.ErrorIs(t, err, ErrHistoryVersionNotFound)
+	})
+
+	t.Run("undelete respects item quota", func(t *testing.T) {
+		ir := new(mockItemRepo)
+		svc := NewItemService(ir, nil, nil, zap.NewNop().Sugar())
+		svc.SetQuota(Quota{MaxItems: 1})
+		ir.On("GetByID", mock.Anything, int64(1), "h1").Return(&model.Item{ID: "h1", Version: 5, Deleted: true}, nil).Once()
+		ir.On("GetVersion", mock.Anything, int64(1), "h1", int64(4)).Return(&model.ItemVersion{ItemID: "h1", Version: 4}, nil).Once()
+		ir.On("CountByUser", mock.Anything, int64(1)).Return(int64(1), nil).Once()
+		_, err := svc.RestoreItemVersion(ctx, 1, "h1", 4)
+		assert.ErrorIs(t, err, ErrQuotaExceeded)
+	})
+}
+
+func TestItemService_Sync_UnchangedKeepsHistory(t *testing.T) {
+	svc, _ := newSQLiteItemService(t)
+	ctx := context.Background()
+	name := "wifi"
+	ver := int64(0)
+	create := SyncChange{ID: "u1", Version: &ver, Name: &name, TextCipher: []byte{1}, TextNonce: []byte{2}}
+	_, err := svc.Sync(ctx, 1, SyncRequest{Changes: []SyncChange{create}})
+	require.NoError(t, err)
+	edit := create
+	edit.Version = ptrInt64(1)
+	edit.TextCipher = []byte{3}
+	res, err := svc.Sync(ctx, 1, SyncRequest{Changes: []SyncChange{edit}})
+	require.NoError(t, err)
+	require.Equal(t, []AppliedResult{{ID: "u1", NewVersion: 2}}, res.Applied)
+
+	// та же запись без правок дважды: ни новой версии, ни снимка в истории
+	same := edit
+	same.Version = ptrInt64(2)
+	for i := 0; i < 2; i++ {
+		res, err = svc.Sync(ctx, 1, SyncRequest{Changes: []SyncChange{same}})
+		require.NoError(t, err)
+		assert.Equal(t, []AppliedResult{{ID: "u1", NewVersion: 2}}, res.Applied)
+	}
+	cur, versions, err := svc.ItemHistory(ctx, 1, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), cur.Version)
+	assert.Len(t, versions, 1)
+}
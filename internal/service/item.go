@@ -1,10 +1,15 @@
 package service
 
 import (
+	"GophKeeper/internal/logging"
+	"GophKeeper/internal/metrics"
 	"GophKeeper/internal/model"
 	"GophKeeper/internal/repo"
+	"GophKeeper/internal/telemetry"
 	"context"
 	"errors"
+	"fmt"
+	"strconv"
 	"time"
 
 	"go.uber.org/zap"
@@ -13,22 +18,120 @@ import (
 
 // ItemService инкапсулирует бизнес-логику работы с Item.
 type ItemService struct {
-	repo     repo.ItemRepository
-	blobRepo repo.BlobRepository
-	logger   *zap.SugaredLogger
+	repo      repo.ItemRepository
+	blobRepo  repo.BlobRepository
+	blobStore repo.BlobStore
+	logger    *zap.SugaredLogger
+	quota     Quota
+	events    EventBroker
+	shares    repo.ShareRepository
+	orgs      repo.OrgRepository
+	emergency repo.EmergencyRepository
 }
 
 // NewItemService создаёт сервис Item.
-func NewItemService(r repo.ItemRepository, br repo.BlobRepository, logger *zap.SugaredLogger) *ItemService {
-	return &ItemService{repo: r, blobRepo: br, logger: logger}
+func NewItemService(r repo.ItemRepository, br repo.BlobRepository, bs repo.BlobStore, logger *zap.SugaredLogger) *ItemService {
+	return &ItemService{repo: r, blobRepo: br, blobStore: bs, logger: logger, events: NewMemoryBroker()}
 }
 
-// SaveBlob сохраняет блоб идемпотентно. Возвращает created=true, если блоб был создан.
-func (s *ItemService) SaveBlob(ctx context.Context, id string, cipher, nonce []byte) (bool, error) {
-	if s.blobRepo == nil {
-		return false, errors.New("blob repository not configured")
+// log — логгер с request_id и trace_id из ctx.
+func (s *ItemService) log(ctx context.Context) *zap.SugaredLogger {
+	return logging.FromContext(ctx, s.logger)
+}
+
+// SaveBlob сохраняет блоб пользователя идемпотентно. Возвращает created=true, если блоб был создан.
+// Сначала метаданные занимают id (CreateIfAbsent), и только создавший их запрос пишет
+// содержимое в BlobStore: параллельная загрузка с тем же id, в том числе другим
+// пользователем, не перезапишет чужие байты. Если запись содержимого не удалась,
+// метаданные удаляются. При превышении квоты возвращает ErrQuotaExceeded.
+func (s *ItemService) SaveBlob(ctx context.Context, userID int64, id string, cipher, nonce []byte) (_ bool, err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.SaveBlob")
+	defer func() { telemetry.End(span, err) }()
+
+	if s.blobRepo == nil || s.blobStore == nil {
+		return false, errors.New("blob storage not configured")
+	}
+	if _, err := s.blobRepo.GetByID(ctx, id); err == nil {
+		return false, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+	if err := s.checkBlobQuota(ctx, userID, int64(len(cipher))); err != nil {
+		return false, err
+	}
+	created, err := s.blobRepo.CreateIfAbsent(ctx, userID, id, nonce, int64(len(cipher)))
+	if err != nil || !created {
+		return false, err
+	}
+	if err := s.blobStore.Put(ctx, id, cipher); err != nil {
+		if derr := s.blobRepo.Delete(ctx, id); derr != nil {
+			s.log(ctx).Errorw("SaveBlob: release blob id", "blob_id", id, "error", derr)
+		}
+		return false, err
+	}
+	metrics.BlobBytesUploaded.Add(float64(len(cipher)))
+	return true, nil
+}
+
+// LoadBlob возвращает метаданные (в т.ч. nonce) и содержимое блоба пользователя.
+// Чужой или отсутствующий блоб — repo.ErrBlobNotFound.
+func (s *ItemService) LoadBlob(ctx context.Context, userID int64, id string) (_ *model.Blob, _ []byte, err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.LoadBlob")
+	defer func() { telemetry.End(span, err) }()
+
+	if s.blobRepo == nil || s.blobStore == nil {
+		return nil, nil, errors.New("blob storage not configured")
+	}
+	meta, err := s.blobRepo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && meta.UserID != userID) {
+		return nil, nil, repo.ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	// блоб, ещё не перенесённый migrate-blobs, хранится в БД
+	if len(meta.Cipher) > 0 {
+		return meta, meta.Cipher, nil
+	}
+	data, err := s.blobStore.Get(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return meta, data, nil
+}
+
+// MigrateInlineBlobs переносит содержимое блобов, хранящееся в БД, в BlobStore.
+// Обрабатывает блобы пачками по batchSize и возвращает количество перенесённых.
+func (s *ItemService) MigrateInlineBlobs(ctx context.Context, batchSize int) (_ int, err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.MigrateInlineBlobs")
+	defer func() { telemetry.End(span, err) }()
+
+	if s.blobRepo == nil || s.blobStore == nil {
+		return 0, errors.New("blob storage not configured")
+	}
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	moved := 0
+	for {
+		blobs, err := s.blobRepo.ListInline(ctx, batchSize)
+		if err != nil {
+			return moved, err
+		}
+		if len(blobs) == 0 {
+			return moved, nil
+		}
+		for _, b := range blobs {
+			if err := s.blobStore.Put(ctx, b.ID, b.Cipher); err != nil {
+				return moved, fmt.Errorf("put blob %s: %w", b.ID, err)
+			}
+			if err := s.blobRepo.ClearInline(ctx, b.ID, int64(len(b.Cipher))); err != nil {
+				return moved, fmt.Errorf("clear inline blob %s: %w", b.ID, err)
+			}
+			moved++
+			s.log(ctx).Infow("MigrateInlineBlobs: blob moved", "blob_id", b.ID, "size", len(b.Cipher))
+		}
 	}
-	return s.blobRepo.CreateIfAbsent(ctx, id, cipher, nonce)
 }
 
 // SyncChange описывает минимальную модель изменения элемента для сервиса.
@@ -49,13 +152,68 @@ type SyncChange struct {
 	TextNonce      []byte
 	CardCipher     []byte
 	CardNonce      []byte
+	// BaseVersions — для каждой изменённой клиентом группы полей (model.Field*)
+	// версия сервера, от которой клиент начал правку. Если задано, при расхождении
+	// версий сервер сливает изменения по полям; поля вне BaseVersions клиент не менял.
+	BaseVersions map[string]int64
 }
 
+// Размер страницы server changes по умолчанию и максимальный.
+const (
+	DefaultSyncPageSize = 500
+	MaxSyncPageSize     = 1000
+)
+
 // SyncRequest вход сервиса синхронизации.
+// Cursor — номер последнего изменения, уже полученного клиентом (0 — полная синхронизация);
+// Limit — размер страницы server changes (0 — DefaultSyncPageSize);
+// LastSyncAt поддерживается для клиентов, ещё не перешедших на курсор (без постраничной выдачи).
 type SyncRequest struct {
+	Cursor     *int64
+	Limit      int
 	LastSyncAt *time.Time
 	Changes    []SyncChange
-	Resolve    *string // стратегия на весь батч: "client" | "server" (опционально)
+	Resolve    *string // стратегия на весь батч: "client" | "server" | "both" (опционально)
+	// Resolutions — стратегии для отдельных записей (id → "client" | "server" | "both");
+	// важнее общей Resolve. "both" на сервере равносильна "server": серверная версия
+	// остаётся, а клиент сохраняет свою копией под новым именем и UUID.
+	Resolutions map[string]string
+	// Atomic — применить батч в одной транзакции: либо все изменения, либо ни одного.
+	Atomic bool
+}
+
+// resolutionFor возвращает стратегию разрешения конфликта для записи id ("" — не задана).
+func (r SyncRequest) resolutionFor(id string) string {
+	if v, ok := r.Resolutions[id]; ok {
+		return v
+	}
+	if r.Resolve != nil {
+		return *r.Resolve
+	}
+	return ""
+}
+
+// ValidResolution проверяет стратегию разрешения конфликта: client|server|both.
+func ValidResolution(strategy string) bool {
+	return strategy == "client" || strategy == "server" || strategy == "both"
+}
+
+// FormatSyncCursor кодирует номер изменения в курсор синхронизации.
+// Клиенты должны считать курсор непрозрачной строкой.
+func FormatSyncCursor(seq int64) string {
+	return strconv.FormatInt(seq, 10)
+}
+
+// ParseSyncCursor разбирает курсор синхронизации, полученный от клиента.
+func ParseSyncCursor(s string) (int64, error) {
+	c, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if c < 0 {
+		return 0, errors.New("negative cursor")
+	}
+	return c, nil
 }
 
 // SyncResult результат синхронизации.
@@ -63,7 +221,12 @@ type SyncResult struct {
 	Applied       []AppliedResult
 	Conflicts     []ConflictResult
 	ServerChanges []model.Item
+	Cursor        int64 // курсор для следующего запроса (только если запрошены server changes)
+	HasMore       bool  // после Cursor есть ещё изменения — нужен следующий запрос
 	ServerTime    time.Time
+	// Collections — доступные пользователю коллекции организаций; заполняется на последней
+	// странице изменений (nil — не запрашивались или не удалось получить).
+	Collections []model.CollectionSnapshot
 }
 
 type AppliedResult struct {
@@ -71,14 +234,21 @@ type AppliedResult struct {
 	NewVersion int64  `json:"new_version"`
 }
 
+// ReasonAtomicAborted — изменение не применено: атомарный батч откатен из-за конфликта в другом изменении.
+const ReasonAtomicAborted = "atomic_aborted"
+
 type ConflictResult struct {
 	ID         string      `json:"id"`
 	Reason     string      `json:"reason"`
 	ServerItem interface{} `json:"server_item,omitempty"`
+	Fields     []string    `json:"fields,omitempty"` // группы полей, изменённые обеими сторонами
 }
 
 // Sync выполняет синхронизацию items.
-func (s *ItemService) Sync(ctx context.Context, userID int64, req SyncRequest) (SyncResult, error) {
+func (s *ItemService) Sync(ctx context.Context, userID int64, req SyncRequest) (_ SyncResult, err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.Sync")
+	defer func() { telemetry.End(span, err) }()
+
 	res := SyncResult{
 		Applied:       make([]AppliedResult, 0, len(req.Changes)),
 		Conflicts:     make([]ConflictResult, 0),
@@ -86,7 +256,132 @@ func (s *ItemService) Sync(ctx context.Context, userID int64, req SyncRequest) (
 		ServerTime:    time.Now().UTC(),
 	}
 
-	// Основной цикл по изменениям
+	// Текущее количество items нужно только при ограничении на их число
+	var itemCount int64
+	if s.quota.MaxItems > 0 && len(req.Changes) > 0 {
+		n, err := s.repo.CountByUser(ctx, userID)
+		if err != nil {
+			return res, err
+		}
+		itemCount = n
+	}
+
+	if req.Atomic && len(req.Changes) > 0 {
+		if err := s.applyChangesAtomic(ctx, userID, req, itemCount, &res); err != nil {
+			s.log(ctx).Errorw("Sync: atomic batch failed",
+				"user_id", userID,
+				"error", err,
+			)
+			return res, err
+		}
+	} else {
+		s.applyChanges(ctx, s.repo, userID, req, itemCount, &res)
+	}
+	reasons := make([]string, 0, len(res.Conflicts))
+	for _, c := range res.Conflicts {
+		reasons = append(reasons, c.Reason)
+	}
+	metrics.ObserveSync(len(req.Changes), reasons)
+	if len(res.Applied) > 0 {
+		ids := make([]string, 0, len(res.Applied))
+		for _, a := range res.Applied {
+			ids = append(ids, a.ID)
+		}
+		s.publishChanges(ctx, userID, ids...)
+	}
+
+	switch {
+	case req.Cursor != nil:
+		res.Cursor = *req.Cursor
+		limit := syncPageSize(req.Limit)
+		// запрашиваем на одну запись больше, чтобы узнать, есть ли следующая страница
+		items, err := s.repo.GetItemsChangedSince(ctx, userID, *req.Cursor, limit+1)
+		if err != nil {
+			// без изменений курсор клиента не сдвигается — ничего не будет потеряно
+			s.log(ctx).Errorw("Sync: get server changes failed",
+				"user_id", userID,
+				"cursor", *req.Cursor,
+				"error", err,
+			)
+			break
+		}
+		if len(items) > limit {
+			items = items[:limit]
+			res.HasMore = true
+		}
+		res.ServerChanges = items
+		for _, it := range items {
+			if it.ChangeSeq > res.Cursor {
+				res.Cursor = it.ChangeSeq
+			}
+		}
+	case req.LastSyncAt != nil:
+		epoch := time.Unix(0, 0).UTC()
+		var items []model.Item
+		var err error
+		if req.LastSyncAt.UTC().Equal(epoch) {
+			items, err = s.repo.ListAll(ctx, userID)
+		} else {
+			items, err = s.repo.GetItemsUpdatedSince(ctx, userID, *req.LastSyncAt)
+		}
+		if err == nil {
+			res.ServerChanges = items
+		} else {
+			s.log(ctx).Errorw("Sync: get server changes failed",
+				"user_id", userID,
+				"since", req.LastSyncAt.UTC().Format(time.RFC3339),
+				"error", err,
+			)
+		}
+	}
+
+	if s.orgs != nil && (req.Cursor != nil || req.LastSyncAt != nil) && !res.HasMore {
+		cols, err := s.orgs.CollectionsForUser(ctx, userID)
+		if err != nil {
+			// без коллекций клиент сохранит прежний их снимок
+			s.log(ctx).Errorw("Sync: get collections failed",
+				"user_id", userID,
+				"error", err,
+			)
+		} else {
+			res.Collections = cols
+		}
+	}
+
+	res.ServerTime = time.Now().UTC()
+	return res, nil
+}
+
+// errSyncAborted откатывает транзакцию атомарной синхронизации.
+var errSyncAborted = errors.New("atomic sync aborted")
+
+// applyChangesAtomic применяет весь батч в одной транзакции БД по принципу «всё или ничего»:
+// при любом конфликте транзакция откатывается, а изменения, которые иначе были бы
+// применены, возвращаются конфликтами ReasonAtomicAborted.
+func (s *ItemService) applyChangesAtomic(ctx context.Context, userID int64, req SyncRequest, itemCount int64, res *SyncResult) error {
+	err := s.repo.Transaction(ctx, func(tx repo.ItemRepository) error {
+		s.applyChanges(ctx, tx, userID, req, itemCount, res)
+		if len(res.Conflicts) > 0 {
+			return errSyncAborted
+		}
+		return nil
+	})
+	if errors.Is(err, errSyncAborted) {
+		for _, a := range res.Applied {
+			res.Conflicts = append(res.Conflicts, ConflictResult{ID: a.ID, Reason: ReasonAtomicAborted})
+		}
+		res.Applied = res.Applied[:0]
+		return nil
+	}
+	if err != nil {
+		res.Applied = res.Applied[:0]
+	}
+	return err
+}
+
+// applyChanges применяет изменения батча через r по одному: каждое изменение
+// либо попадает в res.Applied, либо становится конфликтом в res.Conflicts.
+func (s *ItemService) applyChanges(ctx context.Context, r repo.ItemRepository, userID int64, req SyncRequest, itemCount int64, res *SyncResult) {
 	for _, ch := range req.Changes {
 		// Нормализуем version
 		clientVer := int64(-1)
@@ -95,17 +390,25 @@ func (s *ItemService) Sync(ctx context.Context, userID int64, req SyncRequest) (
 		}
 
 		// Загружаем текущую запись
-		current, err := s.repo.GetByID(ctx, userID, ch.ID)
+		current, err := r.GetByID(ctx, userID, ch.ID)
 		if err != nil {
 			// Если записи нет
 			if errors.Is(err, repoNotFound(err)) {
 				// Создание допускается только если version==0
 				if clientVer == 0 {
+					if s.itemTooLarge(ch, nil) {
+						res.Conflicts = append(res.Conflicts, ConflictResult{ID: ch.ID, Reason: ReasonItemTooLarge})
+						continue
+					}
+					if s.quota.MaxItems > 0 && itemCount >= s.quota.MaxItems {
+						res.Conflicts = append(res.Conflicts, ConflictResult{ID: ch.ID, Reason: ReasonQuotaExceeded})
+						continue
+					}
 					it := buildItemFromChange(userID, ch)
 					it.Version = 1
 					it.UpdatedAt = time.Now().UTC()
-					if err := s.repo.Create(ctx, &it); err != nil {
-						s.logger.Errorw("Sync: create item failed",
+					if err := r.Create(ctx, &it); err != nil {
+						s.log(ctx).Errorw("Sync: create item failed",
 							"user_id", userID,
 							"item_id", ch.ID,
 							"error", err,
@@ -114,6 +417,7 @@ func (s *ItemService) Sync(ctx context.Context, userID int64, req SyncRequest) (
 						res.Conflicts = append(res.Conflicts, ConflictResult{ID: ch.ID, Reason: "internal_error"})
 						continue
 					}
+					itemCount++
 					res.Applied = append(res.Applied, AppliedResult{ID: ch.ID, NewVersion: 1})
 					continue
 				}
@@ -122,7 +426,7 @@ func (s *ItemService) Sync(ctx context.Context, userID int64, req SyncRequest) (
 				continue
 			}
 			// другая ошибка чтения
-			s.logger.Errorw("Sync: get item failed",
+			s.log(ctx).Errorw("Sync: get item failed",
 				"user_id", userID,
 				"item_id", ch.ID,
 				"error", err,
@@ -131,100 +435,109 @@ func (s *ItemService) Sync(ctx context.Context, userID int64, req SyncRequest) (
 			continue
 		}
 
-		// Запись найдена
-		if ch.Deleted != nil && *ch.Deleted {
-			// Обработка удаления как флага — та же логика OCC
-		}
-
-		if ch.Version != nil && *ch.Version == current.Version {
-			// Версии совпали — применяем
-			updates := buildPatchFromChange(ch, current)
-			newVer, err := s.repo.UpdateWithVersion(ctx, userID, ch.ID, current.Version, updates)
+		// update применяет updates поверх текущей версии. Снятие пометки удаления снова
+		// занимает место в квоте на количество записей.
+		update := func(updates map[string]any, failMsg string) {
+			delta := liveDelta(current, updates)
+			if delta > 0 && s.quota.MaxItems > 0 && itemCount >= s.quota.MaxItems {
+				res.Conflicts = append(res.Conflicts, ConflictResult{ID: ch.ID, Reason: ReasonQuotaExceeded})
+				return
+			}
+			newVer, err := r.UpdateWithVersion(ctx, userID, ch.ID, current.Version, updates)
 			if err != nil {
-				s.logger.Errorw("Sync: update with version failed",
+				s.log(ctx).Errorw(failMsg,
 					"user_id", userID,
 					"item_id", ch.ID,
 					"expected_version", current.Version,
 					"error", err,
 				)
 				res.Conflicts = append(res.Conflicts, ConflictResult{ID: ch.ID, Reason: "internal_error"})
-				continue
+				return
 			}
+			itemCount += delta
 			res.Applied = append(res.Applied, AppliedResult{ID: ch.ID, NewVersion: newVer})
+		}
+
+		// Запись найдена
+		if s.itemTooLarge(ch, current) {
+			res.Conflicts = append(res.Conflicts, ConflictResult{ID: ch.ID, Reason: ReasonItemTooLarge})
+			continue
+		}
+		if ch.Deleted != nil && *ch.Deleted {
+			// Обработка удаления как флага — та же логика OCC
+		}
+
+		if ch.Version != nil && *ch.Version == current.Version {
+			// Версии совпали — применяем
+			update(buildPatchFromChange(ch, current), "Sync: update with version failed")
 			continue
 		}
 
 		// Конфликт версий
-		// Если передан явный флаг разрешения на уровне батча
-		if req.Resolve != nil {
-			switch *req.Resolve {
+		// Если передана явная стратегия для записи или для всего батча
+		if strategy := req.resolutionFor(ch.ID); strategy != "" {
+			switch strategy {
 			case "client":
 				// Применяем поверх серверной версии, независимо от clientVer
-				updates := buildPatchFromChange(ch, current)
-				newVer, err := s.repo.UpdateWithVersion(ctx, userID, ch.ID, current.Version, updates)
-				if err != nil {
-					s.logger.Errorw("Sync: force client resolve update failed",
-						"user_id", userID,
-						"item_id", ch.ID,
-						"expected_version", current.Version,
-						"error", err,
-					)
-					res.Conflicts = append(res.Conflicts, ConflictResult{ID: ch.ID, Reason: "internal_error"})
-					continue
-				}
-				res.Applied = append(res.Applied, AppliedResult{ID: ch.ID, NewVersion: newVer})
+				update(buildPatchFromChange(ch, current), "Sync: force client resolve update failed")
 				continue
-			case "server":
-				// На запрос resolve=server возвращаем полный снэпшот server_item
+			case "server", "both":
+				// На запрос resolve=server (и both) возвращаем полный снэпшот server_item
 				res.Conflicts = append(res.Conflicts, ConflictResult{ID: ch.ID, Reason: "version_conflict", ServerItem: fullServerView(current)})
 				continue
 			}
 		}
 
-		// Попытка авторазрешения: клиент прислал поля только туда, где на сервере пусто
-		if onlyFillsEmptyFields(ch, current) {
-			updates := buildPatchFromChange(ch, current)
-			newVer, err := s.repo.UpdateWithVersion(ctx, userID, ch.ID, current.Version, updates)
-			if err != nil {
-				s.logger.Errorw("Sync: auto-resolve update failed",
-					"user_id", userID,
-					"item_id", ch.ID,
-					"expected_version", current.Version,
-					"error", err,
-				)
-				res.Conflicts = append(res.Conflicts, ConflictResult{ID: ch.ID, Reason: "internal_error"})
+		// Трёхстороннее слияние по полям: конфликт только для полей, изменённых обеими сторонами
+		if ch.BaseVersions != nil {
+			updates, conflicting := mergeFieldPatch(ch, current)
+			if len(conflicting) > 0 {
+				res.Conflicts = append(res.Conflicts, ConflictResult{ID: ch.ID, Reason: "version_conflict", ServerItem: minimalServerView(current), Fields: conflicting})
 				continue
 			}
-			res.Applied = append(res.Applied, AppliedResult{ID: ch.ID, NewVersion: newVer})
+			if len(updates) == 0 {
+				// правки клиента уже совпадают с сервером
+				res.Applied = append(res.Applied, AppliedResult{ID: ch.ID, NewVersion: current.Version})
+				continue
+			}
+			update(updates, "Sync: field merge update failed")
+			continue
+		}
+
+		// Попытка авторазрешения: клиент прислал поля только туда, где на сервере пусто
+		if onlyFillsEmptyFields(ch, current) {
+			update(buildPatchFromChange(ch, current), "Sync: auto-resolve update failed")
 			continue
 		}
 
 		// Иначе — конфликт без авторазрешения, отдаём минимальный вид
 		res.Conflicts = append(res.Conflicts, ConflictResult{ID: ch.ID, Reason: "version_conflict", ServerItem: minimalServerView(current)})
 	}
+}
 
-	if req.LastSyncAt != nil {
-		epoch := time.Unix(0, 0).UTC()
-		var items []model.Item
-		var err error
-		if req.LastSyncAt.UTC().Equal(epoch) {
-			items, err = s.repo.ListAll(ctx, userID)
-		} else {
-			items, err = s.repo.GetItemsUpdatedSince(ctx, userID, *req.LastSyncAt)
-		}
-		if err == nil {
-			res.ServerChanges = items
-		} else {
-			s.logger.Errorw("Sync: get server changes failed",
-				"user_id", userID,
-				"since", req.LastSyncAt.UTC().Format(time.RFC3339),
-				"error", err,
-			)
-		}
+// liveDelta — на сколько изменится число неудалённых записей, если применить updates к cur.
+func liveDelta(cur *model.Item, updates map[string]any) int64 {
+	deleted, ok := updates["deleted"].(bool)
+	switch {
+	case !ok || deleted == cur.Deleted:
+		return 0
+	case deleted:
+		return -1
+	default:
+		return 1
 	}
+}
 
-	res.ServerTime = time.Now().UTC()
-	return res, nil
+// syncPageSize нормализует запрошенный размер страницы server changes.
+func syncPageSize(limit int) int {
+	switch {
+	case limit <= 0:
+		return DefaultSyncPageSize
+	case limit > MaxSyncPageSize:
+		return MaxSyncPageSize
+	default:
+		return limit
+	}
 }
 
 // repoNotFound проверяет признак отсутствия записи (gorm.ErrRecordNotFound)
@@ -353,6 +666,36 @@ func buildPatchFromChange(ch SyncChange, current *model.Item) map[string]any {
 	return patch
 }
 
+// mergeFieldPatch строит патч из полей, изменённых клиентом (ch.BaseVersions).
+// Поле конфликтует, если сервер менял его после базовой версии клиента и значения различаются.
+// Возвращает патч и отсортированный список конфликтующих групп полей.
+func mergeFieldPatch(ch SyncChange, cur *model.Item) (map[string]any, []string) {
+	patch := map[string]any{}
+	conflicted := map[string]bool{}
+	for col, val := range buildPatchFromChange(ch, cur) {
+		field := model.FieldOfColumn(col)
+		base, edited := ch.BaseVersions[field]
+		if field == "" || !edited || cur.ColumnEquals(col, val) {
+			continue
+		}
+		if cur.FieldVersion(field) > base {
+			conflicted[field] = true
+			continue
+		}
+		patch[col] = val
+	}
+	if len(conflicted) == 0 {
+		return patch, nil
+	}
+	fields := make([]string, 0, len(conflicted))
+	for _, f := range model.FieldGroups {
+		if conflicted[f] {
+			fields = append(fields, f)
+		}
+	}
+	return nil, fields
+}
+
 func onlyFillsEmptyFields(ch SyncChange, cur *model.Item) bool {
 	// Проверяем только те поля, которые клиент прислал; если хотя бы одно поле перезаписывает непустое значение — не авторазрешаем
 	if ch.Name != nil && cur.Name != "" {
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"GophKeeper/internal/model"
+	"GophKeeper/internal/repo"
+	"GophKeeper/internal/telemetry"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Ошибки CRUD-операций над отдельными items.
+var (
+	ErrItemNotFound    = errors.New("item not found")
+	ErrItemExists      = errors.New("item already exists")
+	ErrVersionMismatch = errors.New("item version mismatch")
+	ErrItemTooLarge    = errors.New("item too large")
+)
+
+// ListItems возвращает неудалённые элементы пользователя.
+func (s *ItemService) ListItems(ctx context.Context, userID int64) (_ []model.Item, err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.ListItems")
+	defer func() { telemetry.End(span, err) }()
+
+	items, err := s.repo.ListAll(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]model.Item, 0, len(items))
+	for _, it := range items {
+		if !it.Deleted {
+			res = append(res, it)
+		}
+	}
+	return res, nil
+}
+
+// GetItem возвращает неудалённый элемент пользователя или ErrItemNotFound.
+func (s *ItemService) GetItem(ctx context.Context, userID int64, id string) (_ *model.Item, err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.GetItem")
+	defer func() { telemetry.End(span, err) }()
+
+	it, err := s.repo.GetByID(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrItemNotFound
+		}
+		return nil, err
+	}
+	if it.Deleted {
+		return nil, ErrItemNotFound
+	}
+	return it, nil
+}
+
+// CreateItem создаёт элемент с версией 1. Квоты проверяются так же, как в Sync.
+func (s *ItemService) CreateItem(ctx context.Context, userID int64, ch SyncChange) (_ *model.Item, err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.CreateItem")
+	defer func() { telemetry.End(span, err) }()
+
+	if _, err := s.repo.GetByID(ctx, userID, ch.ID); err == nil {
+		return nil, ErrItemExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if s.itemTooLarge(ch, nil) {
+		return nil, ErrItemTooLarge
+	}
+	if s.quota.MaxItems > 0 {
+		n, err := s.repo.CountByUser(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if n >= s.quota.MaxItems {
+			return nil, ErrQuotaExceeded
+		}
+	}
+	it := buildItemFromChange(userID, ch)
+	it.Deleted = false
+	it.Version = 1
+	it.UpdatedAt = time.Now().UTC()
+	if err := s.repo.Create(ctx, &it); err != nil {
+		if errors.Is(err, repo.ErrItemExists) {
+			// id занят записью другого пользователя
+			return nil, ErrItemExists
+		}
+		return nil, err
+	}
+	s.publishChanges(ctx, userID, it.ID)
+	return &it, nil
+}
+
+// UpdateItem применяет изменение к элементу с проверкой версии (OCC).
+// Возвращает обновлённый элемент; ErrVersionMismatch, если expectedVersion устарела.
+func (s *ItemService) UpdateItem(ctx context.Context, userID int64, id string, expectedVersion int64, ch SyncChange) (_ *model.Item, err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.UpdateItem")
+	defer func() { telemetry.End(span, err) }()
+
+	current, err := s.GetItem(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if current.Version != expectedVersion {
+		return nil, ErrVersionMismatch
+	}
+	if s.itemTooLarge(ch, current) {
+		return nil, ErrItemTooLarge
+	}
+	return s.updateWithVersion(ctx, userID, id, expectedVersion, buildPatchFromChange(ch, current))
+}
+
+// DeleteItem помечает элемент удалённым (soft delete) с проверкой версии.
+func (s *ItemService) DeleteItem(ctx context.Context, userID int64, id string, expectedVersion int64) (_ *model.Item, err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.DeleteItem")
+	defer func() { telemetry.End(span, err) }()
+
+	current, err := s.GetItem(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if current.Version != expectedVersion {
+		return nil, ErrVersionMismatch
+	}
+	return s.updateWithVersion(ctx, userID, id, expectedVersion, map[string]any{"deleted": true})
+}
+
+func (s *ItemService) updateWithVersion(ctx context.Context, userID int64, id string, expectedVersion int64, updates map[string]any) (*model.Item, error) {
+	if _, err := s.repo.UpdateWithVersion(ctx, userID, id, expectedVersion, updates); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// запись была изменена между чтением и обновлением
+			return nil, ErrVersionMismatch
+		}
+		return nil, err
+	}
+	s.publishChanges(ctx, userID, id)
+	it, err := s.repo.GetByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return it, nil
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"GophKeeper/internal/model"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestItemService_ListItems_SkipsDeleted(t *testing.T) {
+	ir := new(mockItemRepo)
+	svc := NewItemService(ir, nil, nil, zap.NewNop().Sugar())
+	ir.On("ListAll", mock.Anything, int64(1)).Return([]model.Item{{ID: "a"}, {ID: "b", Deleted: true}}, nil).Once()
+
+	list, err := svc.ListItems(context.Background(), 1)
+	assert.NoError(t, err)
+	if assert.Len(t, list, 1) {
+		assert.Equal(t, "a", list[0].ID)
+	}
+
+	ir.On("ListAll", mock.Anything, int64(2)).Return(nil, errors.New("db")).Once()
+	_, err = svc.ListItems(context.Background(), 2)
+	assert.Error(t, err)
+}
+
+func TestItemService_GetItem(t *testing.T) {
+	ir := new(mockItemRepo)
+	svc := NewItemService(ir, nil, nil, zap.NewNop().Sugar())
+	ctx := context.Background()
+
+	ir.On("GetByID", mock.Anything, int64(1), "ok").Return(&model.Item{ID: "ok"}, nil).Once()
+	ir.On("GetByID", mock.Anything, int64(1), "del").Return(&model.Item{ID: "del", Deleted: true}, nil).Once()
+	ir.On("GetByID", mock.Anything, int64(1), "none").Return(nil, gorm.ErrRecordNotFound).Once()
+	ir.On("GetByID", mock.Anything, int64(1), "err").Return(nil, errors.New("db")).Once()
+
+	it, err := svc.GetItem(ctx, 1, "ok")
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", it.ID)
+	_, err = svc.GetItem(ctx, 1, "del")
+	assert.ErrorIs(t, err, ErrItemNotFound)
+	_, err = svc.GetItem(ctx, 1, "none")
+	assert.ErrorIs(t, err, ErrItemNotFound)
+	_, err = svc.GetItem(ctx, 1, "err")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrItemNotFound)
+}
+
+func TestItemService_CreateItem(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("created with version 1", func(t *testing.T) {
+		ir := new(mockItemRepo)
+		svc := NewItemService(ir, nil, nil, zap.NewNop().Sugar())
+		ir.On("GetByID", mock.Anything, int64(1), "n1").Return(nil, gorm.ErrRecordNotFound).Once()
+		ir.On("Create", mock.Anything, mock.MatchedBy(func(it *model.Item) bool {
+			return it.ID == "n1" && it.Name == "mail" && it.Version == 1 && it.UserID == 1
+		})).Return(nil).Once()
+		it, err := svc.CreateItem(ctx, 1, SyncChange{ID: "n1", Name: ptrStr("mail")})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), it.Version)
+		ir.AssertExpectations(t)
+	})
+
+	t.Run("exists", func(t *testing.T) {
+		ir := new(mockItemRepo)
+		svc := NewItemService(ir, nil, nil, zap.NewNop().Sugar())
+		ir.On("GetByID", mock.Anything, int64(1), "n1").Return(&model.Item{ID: "n1"}, nil).Once()
+		_, err := svc.CreateItem(ctx, 1, SyncChange{ID: "n1"})
+		assert.ErrorIs(t, err, ErrItemExists)
+	})
+
+	t.Run("quotas", func(t *testing.T) {
+		ir := new(mockItemRepo)
+		svc := NewItemService(ir, nil, nil, zap.NewNop().Sugar())
+		svc.SetQuota(Quota{MaxItems: 1, MaxItemBytes: 4})
+		ir.On("GetByID", mock.Anything, int64(1), mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+		_, err := svc.CreateItem(ctx, 1, SyncChange{ID: "big", TextCipher: make([]byte, 5)})
+		assert.ErrorIs(t, err, ErrItemTooLarge)
+
+		ir.On("CountByUser", mock.Anything, int64(1)).Return(int64(1), nil).Once()
+		_, err = svc.CreateItem(ctx, 1, SyncChange{ID: "n2"})
+		assert.ErrorIs(t, err, ErrQuotaExceeded)
+	})
+}
+
+func TestItemService_UpdateItem(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success returns fresh item", func(t *testing.T) {
+		ir := new(mockItemRepo)
+		svc := NewItemService(ir, nil, nil, zap.NewNop().Sugar())
+		ir.On("GetByID", mock.Anything, int64(1), "u1").Return(&model.Item{ID: "u1", Version: 3}, nil).Once()
+		ir.On("UpdateWithVersion", mock.Anything, int64(1), "u1", int64(3), mock.MatchedBy(func(m map[string]any) bool {
+			return m["name"] == "new"
+		})).Return(int64(4), nil).Once()
+		ir.On("GetByID", mock.Anything, int64(1), "u1").Return(&model.Item{ID: "u1", Name: "new", Version: 4}, nil).Once()
+
+		it, err := svc.UpdateItem(ctx, 1, "u1", 3, SyncChange{ID: "u1", Name: ptrStr("new")})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), it.Version)
+		ir.AssertExpectations(t)
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		ir := new(mockItemRepo)
+		svc := NewItemService(ir, nil, nil, zap.NewNop().Sugar())
+		ir.On("GetByID", mock.Anything, int64(1), "u1").Return(&model.Item{ID: "u1", Version: 3}, nil).Once()
+		_, err := svc.UpdateItem(ctx, 1, "u1", 2, SyncChange{ID: "u1"})
+		assert.ErrorIs(t, err, ErrVersionMismatch)
+	})
+
+	t.Run("concurrent write between read and update", func(t *testing.T) {
+		ir := new(mockItemRepo)
+		svc := NewItemService(ir, nil, nil, zap.NewNop().Sugar())
+		ir.On("GetByID", mock.Anything, int64(1), "u1").Return(&model.Item{ID: "u1", Version: 3}, nil).Once()
+		ir.On("UpdateWithVersion", mock.Anything, int64(1), "u1", int64(3), mock.Anything).Return(int64(0), gorm.ErrRecordNotFound).Once()
+		_, err := svc.UpdateItem(ctx, 1, "u1", 3, SyncChange{ID: "u1"})
+		assert.ErrorIs(t, err, ErrVersionMismatch)
+	})
+}
+
+func TestItemService_DeleteItem(t *testing.T) {
+	ir := new(mockItemRepo)
+	svc := NewItemService(ir, nil, nil, zap.NewNop().Sugar())
+	ctx := context.Background()
+
+	ir.On("GetByID", mock.Anything, int64(1), "d1").Return(&model.Item{ID: "d1", Version: 1}, nil).Once()
+	ir.On("UpdateWithVersion", mock.Anything, int64(1), "d1", int64(1), map[string]any{"deleted": true}).Return(int64(2), nil).Once()
+	ir.On("GetByID", mock.Anything, int64(1), "d1").Return(&model.Item{ID: "d1", Version: 2, Deleted: true}, nil).Once()
+
+	it, err := svc.DeleteItem(ctx, 1, "d1", 1)
+	assert.NoError(t, err)
+	assert.True(t, it.Deleted)
+
+	// повторное удаление — элемента уже нет
+	ir.On("GetByID", mock.Anything, int64(1), "d1").Return(&model.Item{ID: "d1", Version: 2, Deleted: true}, nil).Once()
+	_, err = svc.DeleteItem(ctx, 1, "d1", 2)
+	assert.ErrorIs(t, err, ErrItemNotFound)
+	ir.AssertExpectations(t)
+}
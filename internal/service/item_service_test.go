@@ -10,6 +10,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -47,45 +48,201 @@ func (m *mockItemRepo) ListAll(ctx context.Context, userID int64) ([]model.Item,
 	return nil, args.Error(1)
 }
 
+func (m *mockItemRepo) CountByUser(ctx context.Context, userID int64) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockItemRepo) ListVersions(ctx context.Context, userID int64, id string) ([]model.ItemVersion, error) {
+	args := m.Called(ctx, userID, id)
+	if v, ok := args.Get(0).([]model.ItemVersion); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockItemRepo) GetVersion(ctx context.Context, userID int64, id string, version int64) (*model.ItemVersion, error) {
+	args := m.Called(ctx, userID, id, version)
+	if v, ok := args.Get(0).(*model.ItemVersion); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockItemRepo) LatestChangeSeq(ctx context.Context, userID int64) (int64, error) {
+	return 0, nil
+}
+
+// Transaction выполняет fn без реальной транзакции: мок не хранит состояние.
+func (m *mockItemRepo) Transaction(ctx context.Context, fn func(tx repo.ItemRepository) error) error {
+	return fn(m)
+}
+
+func (m *mockItemRepo) GetItemsChangedSince(ctx context.Context, userID int64, cursor int64, limit int) ([]model.Item, error) {
+	args := m.Called(ctx, userID, cursor, limit)
+	if v, ok := args.Get(0).([]model.Item); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 var _ repo.ItemRepository = (*mockItemRepo)(nil)
 
 type mockBlobRepo struct{ mock.Mock }
 
-func (m *mockBlobRepo) CreateIfAbsent(ctx context.Context, id string, cipher, nonce []byte) (bool, error) {
-	args := m.Called(ctx, id, cipher, nonce)
+func (m *mockBlobRepo) CreateIfAbsent(ctx context.Context, userID int64, id string, nonce []byte, size int64) (bool, error) {
+	args := m.Called(ctx, userID, id, nonce, size)
 	return args.Bool(0), args.Error(1)
 }
+func (m *mockBlobRepo) GetByID(ctx context.Context, id string) (*model.Blob, error) {
+	args := m.Called(ctx, id)
+	if v, ok := args.Get(0).(*model.Blob); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *mockBlobRepo) ListInline(ctx context.Context, limit int) ([]model.Blob, error) {
+	args := m.Called(ctx, limit)
+	if v, ok := args.Get(0).([]model.Blob); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *mockBlobRepo) ClearInline(ctx context.Context, id string, size int64) error {
+	return m.Called(ctx, id, size).Error(0)
+}
+
+func (m *mockBlobRepo) Delete(ctx context.Context, id string) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *mockBlobRepo) SumSizeByUser(ctx context.Context, userID int64) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
 
 var _ repo.BlobRepository = (*mockBlobRepo)(nil)
 
+// memBlobStore — простое in-memory хранилище блобов для тестов сервиса
+type memBlobStore struct {
+	data   map[string][]byte
+	putErr error
+}
+
+func newMemBlobStore() *memBlobStore { return &memBlobStore{data: map[string][]byte{}} }
+
+func (m *memBlobStore) Put(_ context.Context, id string, data []byte) error {
+	if m.putErr != nil {
+		return m.putErr
+	}
+	m.data[id] = data
+	return nil
+}
+func (m *memBlobStore) Get(_ context.Context, id string) ([]byte, error) {
+	b, ok := m.data[id]
+	if !ok {
+		return nil, repo.ErrBlobNotFound
+	}
+	return b, nil
+}
+func (m *memBlobStore) Delete(_ context.Context, id string) error {
+	delete(m.data, id)
+	return nil
+}
+
+var _ repo.BlobStore = (*memBlobStore)(nil)
+
 func TestItemService_SaveBlob(t *testing.T) {
 	br := new(mockBlobRepo)
 	ir := new(mockItemRepo)
-	svc := NewItemService(ir, br, zap.NewNop().Sugar())
+	store := newMemBlobStore()
+	svc := NewItemService(ir, br, store, zap.NewNop().Sugar())
 	ctx := context.Background()
 
-	br.On("CreateIfAbsent", mock.Anything, "b1", []byte{1, 2}, []byte{3}).Return(true, nil).Once()
-	created, err := svc.SaveBlob(ctx, "b1", []byte{1, 2}, []byte{3})
+	// новый блоб: содержимое в store, метаданные в БД
+	br.On("GetByID", mock.Anything, "b1").Return(nil, gorm.ErrRecordNotFound).Once()
+	br.On("CreateIfAbsent", mock.Anything, int64(7), "b1", []byte{3}, int64(2)).Return(true, nil).Once()
+	created, err := svc.SaveBlob(ctx, 7, "b1", []byte{1, 2}, []byte{3})
 	assert.NoError(t, err)
 	assert.True(t, created)
+	assert.Equal(t, []byte{1, 2}, store.data["b1"])
 
-	br.On("CreateIfAbsent", mock.Anything, "b1", []byte{1, 2}, []byte{3}).Return(false, nil).Once()
-	created, err = svc.SaveBlob(ctx, "b1", []byte{1, 2}, []byte{3})
+	// повторная загрузка: метаданные уже есть — store не трогаем
+	store.data["b1"] = []byte{7}
+	br.On("GetByID", mock.Anything, "b1").Return(&model.Blob{ID: "b1"}, nil).Once()
+	created, err = svc.SaveBlob(ctx, 7, "b1", []byte{1, 2}, []byte{3})
 	assert.NoError(t, err)
 	assert.False(t, created)
+	assert.Equal(t, []byte{7}, store.data["b1"])
+
+	// ошибка БД при создании метаданных
+	br.On("GetByID", mock.Anything, "b2").Return(nil, gorm.ErrRecordNotFound).Once()
+	br.On("CreateIfAbsent", mock.Anything, int64(7), "b2", mock.Anything, mock.Anything).Return(false, errors.New("db")).Once()
+	created, err = svc.SaveBlob(ctx, 7, "b2", []byte{9}, []byte{9})
+	assert.Error(t, err)
+	assert.False(t, created)
 
-	br.On("CreateIfAbsent", mock.Anything, "b2", mock.Anything, mock.Anything).Return(false, errors.New("db")).Once()
-	created, err = svc.SaveBlob(ctx, "b2", []byte{9}, []byte{9})
+	// параллельная загрузка с тем же id заняла метаданные раньше: её байты не перезаписываются
+	store.data["b5"] = []byte{5}
+	br.On("GetByID", mock.Anything, "b5").Return(nil, gorm.ErrRecordNotFound).Once()
+	br.On("CreateIfAbsent", mock.Anything, int64(8), "b5", []byte{1}, int64(1)).Return(false, nil).Once()
+	created, err = svc.SaveBlob(ctx, 8, "b5", []byte{9}, []byte{1})
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, []byte{5}, store.data["b5"])
+
+	// ошибка хранилища — занятые метаданные освобождаются
+	store.putErr = errors.New("disk full")
+	br.On("GetByID", mock.Anything, "b3").Return(nil, gorm.ErrRecordNotFound).Once()
+	br.On("CreateIfAbsent", mock.Anything, int64(7), "b3", []byte{1}, int64(1)).Return(true, nil).Once()
+	br.On("Delete", mock.Anything, "b3").Return(nil).Once()
+	created, err = svc.SaveBlob(ctx, 7, "b3", []byte{1}, []byte{1})
 	assert.Error(t, err)
 	assert.False(t, created)
 
+	// ошибка чтения метаданных
+	br.On("GetByID", mock.Anything, "b4").Return(nil, errors.New("conn")).Once()
+	_, err = svc.SaveBlob(ctx, 7, "b4", []byte{1}, []byte{1})
+	assert.Error(t, err)
+
 	br.AssertExpectations(t)
 }
 
+func TestItemService_MigrateInlineBlobs(t *testing.T) {
+	br := new(mockBlobRepo)
+	store := newMemBlobStore()
+	svc := NewItemService(new(mockItemRepo), br, store, zap.NewNop().Sugar())
+	ctx := context.Background()
+
+	br.On("ListInline", mock.Anything, 2).Return([]model.Blob{
+		{ID: "m1", Cipher: []byte{1}},
+		{ID: "m2", Cipher: []byte{2, 2}},
+	}, nil).Once()
+	br.On("ClearInline", mock.Anything, "m1", int64(1)).Return(nil).Once()
+	br.On("ClearInline", mock.Anything, "m2", int64(2)).Return(nil).Once()
+	br.On("ListInline", mock.Anything, 2).Return([]model.Blob{}, nil).Once()
+
+	moved, err := svc.MigrateInlineBlobs(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, moved)
+	assert.Equal(t, []byte{2, 2}, store.data["m2"])
+	br.AssertExpectations(t)
+
+	// ошибка записи в хранилище прерывает миграцию, содержимое в БД не очищается
+	br2 := new(mockBlobRepo)
+	store.putErr = errors.New("s3 down")
+	svc = NewItemService(new(mockItemRepo), br2, store, zap.NewNop().Sugar())
+	br2.On("ListInline", mock.Anything, 100).Return([]model.Blob{{ID: "m3", Cipher: []byte{3}}}, nil).Once()
+	moved, err = svc.MigrateInlineBlobs(ctx, 0)
+	assert.Error(t, err)
+	assert.Equal(t, 0, moved)
+	br2.AssertExpectations(t)
+}
+
 func TestItemService_Sync_EmptyBatch(t *testing.T) {
 	ir := new(mockItemRepo)
 	br := new(mockBlobRepo)
-	svc := NewItemService(ir, br, zap.NewNop().Sugar())
+	svc := NewItemService(ir, br, nil, zap.NewNop().Sugar())
 	ctx := context.Background()
 
 	res, err := svc.Sync(ctx, 7, SyncRequest{Changes: nil})
@@ -101,9 +258,53 @@ func ptrInt64(v int64) *int64 { return &v }
 func ptrBool(v bool) *bool    { return &v }
 func ptrStr(s string) *string { return &s }
 
+func TestItemService_LoadBlob(t *testing.T) {
+	br := new(mockBlobRepo)
+	store := newMemBlobStore()
+	svc := NewItemService(new(mockItemRepo), br, store, zap.NewNop().Sugar())
+	ctx := context.Background()
+
+	store.data["b1"] = []byte{1, 2}
+	br.On("GetByID", mock.Anything, "b1").Return(&model.Blob{ID: "b1", UserID: 7, Nonce: []byte{3}}, nil)
+	meta, data, err := svc.LoadBlob(ctx, 7, "b1")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{3}, meta.Nonce)
+	assert.Equal(t, []byte{1, 2}, data)
+
+	// чужой блоб неотличим от отсутствующего
+	_, _, err = svc.LoadBlob(ctx, 8, "b1")
+	assert.ErrorIs(t, err, repo.ErrBlobNotFound)
+	br.On("GetByID", mock.Anything, "none").Return(nil, gorm.ErrRecordNotFound).Once()
+	_, _, err = svc.LoadBlob(ctx, 7, "none")
+	assert.ErrorIs(t, err, repo.ErrBlobNotFound)
+}
+
+func TestItemService_LoadBlob_Inline(t *testing.T) {
+	svc, db := newSQLiteItemService(t)
+	ctx := context.Background()
+	const id = "3f1c8a52-0d4e-4b7a-9c61-5e2f7a9b0c11"
+
+	// содержимое ещё в БД (до migrate-blobs)
+	require.NoError(t, db.Create(&model.Blob{ID: id, UserID: 7, Nonce: []byte{3}, Cipher: []byte{5, 6}}).Error)
+	meta, data, err := svc.LoadBlob(ctx, 7, id)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{3}, meta.Nonce)
+	assert.Equal(t, []byte{5, 6}, data)
+	_, _, err = svc.LoadBlob(ctx, 8, id)
+	assert.ErrorIs(t, err, repo.ErrBlobNotFound)
+
+	// после переноса то же содержимое читается из BlobStore
+	moved, err := svc.MigrateInlineBlobs(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, moved)
+	_, data, err = svc.LoadBlob(ctx, 7, id)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{5, 6}, data)
+}
+
 func TestItemService_SaveBlob_ErrWhenNilRepo(t *testing.T) {
-	svc := NewItemService(new(mockItemRepo), nil, zap.NewNop().Sugar())
-	_, err := svc.SaveBlob(context.Background(), "id1", []byte{1}, []byte{2})
+	svc := NewItemService(new(mockItemRepo), nil, nil, zap.NewNop().Sugar())
+	_, err := svc.SaveBlob(context.Background(), 7, "id1", []byte{1}, []byte{2})
 	assert.Error(t, err)
 }
 
@@ -112,7 +313,7 @@ func TestItemService_Sync_MainBranches(t *testing.T) {
 	t.Run("create on not found with version 0", func(t *testing.T) {
 		ir := new(mockItemRepo)
 		br := new(mockBlobRepo)
-		svc := NewItemService(ir, br, logger)
+		svc := NewItemService(ir, br, nil, logger)
 		ctx := context.Background()
 
 		// не найдено
@@ -132,7 +333,7 @@ func TestItemService_Sync_MainBranches(t *testing.T) {
 
 	t.Run("not found with non-zero version -> conflict not_found", func(t *testing.T) {
 		ir := new(mockItemRepo)
-		svc := NewItemService(ir, new(mockBlobRepo), logger)
+		svc := NewItemService(ir, new(mockBlobRepo), nil, logger)
 		ctx := context.Background()
 
 		ir.On("GetByID", mock.Anything, int64(7), "item2").Return((*model.Item)(nil), gorm.ErrRecordNotFound).Once()
@@ -146,7 +347,7 @@ func TestItemService_Sync_MainBranches(t *testing.T) {
 
 	t.Run("version match -> update applied", func(t *testing.T) {
 		ir := new(mockItemRepo)
-		svc := NewItemService(ir, new(mockBlobRepo), logger)
+		svc := NewItemService(ir, new(mockBlobRepo), nil, logger)
 		ctx := context.Background()
 		current := &model.Item{ID: "item3", UserID: 7, Version: 5}
 
@@ -163,7 +364,7 @@ func TestItemService_Sync_MainBranches(t *testing.T) {
 
 	t.Run("version conflict with resolve=client -> forced update", func(t *testing.T) {
 		ir := new(mockItemRepo)
-		svc := NewItemService(ir, new(mockBlobRepo), logger)
+		svc := NewItemService(ir, new(mockBlobRepo), nil, logger)
 		ctx := context.Background()
 		current := &model.Item{ID: "item4", UserID: 7, Version: 10}
 		resolve := "client"
@@ -180,7 +381,7 @@ func TestItemService_Sync_MainBranches(t *testing.T) {
 
 	t.Run("version conflict with resolve=server -> conflict with server view", func(t *testing.T) {
 		ir := new(mockItemRepo)
-		svc := NewItemService(ir, new(mockBlobRepo), logger)
+		svc := NewItemService(ir, new(mockBlobRepo), nil, logger)
 		ctx := context.Background()
 		current := &model.Item{ID: "item5", UserID: 7, Version: 3, UpdatedAt: time.Now()}
 		resolve := "server"
@@ -202,7 +403,7 @@ func TestItemService_Sync_MainBranches(t *testing.T) {
 
 	t.Run("auto-fill empty fields -> applied", func(t *testing.T) {
 		ir := new(mockItemRepo)
-		svc := NewItemService(ir, new(mockBlobRepo), logger)
+		svc := NewItemService(ir, new(mockBlobRepo), nil, logger)
 		ctx := context.Background()
 		// у текущей записи пустые поля логина
 		current := &model.Item{ID: "item6", UserID: 7, Version: 1, UpdatedAt: time.Now()}
@@ -218,7 +419,7 @@ func TestItemService_Sync_MainBranches(t *testing.T) {
 
 	t.Run("GetByID returns other error -> internal_error conflict", func(t *testing.T) {
 		ir := new(mockItemRepo)
-		svc := NewItemService(ir, new(mockBlobRepo), logger)
+		svc := NewItemService(ir, new(mockBlobRepo), nil, logger)
 		ctx := context.Background()
 
 		ir.On("GetByID", mock.Anything, int64(7), "item7").Return((*model.Item)(nil), errors.New("db down")).Once()
@@ -232,7 +433,7 @@ func TestItemService_Sync_MainBranches(t *testing.T) {
 
 	t.Run("Create fails -> internal_error conflict", func(t *testing.T) {
 		ir := new(mockItemRepo)
-		svc := NewItemService(ir, new(mockBlobRepo), logger)
+		svc := NewItemService(ir, new(mockBlobRepo), nil, logger)
 		ctx := context.Background()
 
 		ir.On("GetByID", mock.Anything, int64(7), "item8").Return((*model.Item)(nil), gorm.ErrRecordNotFound).Once()
@@ -247,7 +448,7 @@ func TestItemService_Sync_MainBranches(t *testing.T) {
 
 	t.Run("UpdateWithVersion fails -> internal_error conflict", func(t *testing.T) {
 		ir := new(mockItemRepo)
-		svc := NewItemService(ir, new(mockBlobRepo), logger)
+		svc := NewItemService(ir, new(mockBlobRepo), nil, logger)
 		ctx := context.Background()
 		current := &model.Item{ID: "item9", UserID: 7, Version: 4}
 
@@ -266,7 +467,7 @@ func TestItemService_Sync_ServerChangesRetrieval(t *testing.T) {
 	logger := zap.NewNop().Sugar()
 	t.Run("epoch -> ListAll", func(t *testing.T) {
 		ir := new(mockItemRepo)
-		svc := NewItemService(ir, new(mockBlobRepo), logger)
+		svc := NewItemService(ir, new(mockBlobRepo), nil, logger)
 		ctx := context.Background()
 		epoch := time.Unix(0, 0).UTC()
 
@@ -281,7 +482,7 @@ func TestItemService_Sync_ServerChangesRetrieval(t *testing.T) {
 
 	t.Run("since time -> GetItemsUpdatedSince", func(t *testing.T) {
 		ir := new(mockItemRepo)
-		svc := NewItemService(ir, new(mockBlobRepo), logger)
+		svc := NewItemService(ir, new(mockBlobRepo), nil, logger)
 		ctx := context.Background()
 		since := time.Now().UTC().Add(-time.Hour)
 
@@ -296,7 +497,7 @@ func TestItemService_Sync_ServerChangesRetrieval(t *testing.T) {
 
 	t.Run("errors on retrieval -> no crash, empty ServerChanges", func(t *testing.T) {
 		ir := new(mockItemRepo)
-		svc := NewItemService(ir, new(mockBlobRepo), logger)
+		svc := NewItemService(ir, new(mockBlobRepo), nil, logger)
 		ctx := context.Background()
 		since := time.Now().UTC().Add(-time.Hour)
 
@@ -309,9 +510,92 @@ func TestItemService_Sync_ServerChangesRetrieval(t *testing.T) {
 	})
 }
 
+func TestItemService_Sync_Cursor(t *testing.T) {
+	logger := zap.NewNop().Sugar()
+	t.Run("zero cursor, default page, cursor = max change_seq", func(t *testing.T) {
+		ir := new(mockItemRepo)
+		svc := NewItemService(ir, new(mockBlobRepo), nil, logger)
+		ir.On("GetItemsChangedSince", mock.Anything, int64(7), int64(0), DefaultSyncPageSize+1).
+			Return([]model.Item{{ID: "i1", ChangeSeq: 4}, {ID: "i2", ChangeSeq: 9}}, nil).Once()
+
+		res, err := svc.Sync(context.Background(), 7, SyncRequest{Cursor: ptrInt64(0)})
+		assert.NoError(t, err)
+		assert.Len(t, res.ServerChanges, 2)
+		assert.Equal(t, int64(9), res.Cursor)
+		assert.False(t, res.HasMore)
+		ir.AssertExpectations(t)
+	})
+
+	t.Run("page limit -> has_more and cursor of last returned item", func(t *testing.T) {
+		ir := new(mockItemRepo)
+		svc := NewItemService(ir, new(mockBlobRepo), nil, logger)
+		ir.On("GetItemsChangedSince", mock.Anything, int64(7), int64(0), 3).
+			Return([]model.Item{{ID: "i1", ChangeSeq: 1}, {ID: "i2", ChangeSeq: 2}, {ID: "i3", ChangeSeq: 5}}, nil).Once()
+
+		res, err := svc.Sync(context.Background(), 7, SyncRequest{Cursor: ptrInt64(0), Limit: 2})
+		assert.NoError(t, err)
+		assert.Len(t, res.ServerChanges, 2)
+		assert.Equal(t, int64(2), res.Cursor)
+		assert.True(t, res.HasMore)
+		ir.AssertExpectations(t)
+	})
+
+	t.Run("limit is clamped to MaxSyncPageSize", func(t *testing.T) {
+		ir := new(mockItemRepo)
+		svc := NewItemService(ir, new(mockBlobRepo), nil, logger)
+		ir.On("GetItemsChangedSince", mock.Anything, int64(7), int64(1), MaxSyncPageSize+1).Return([]model.Item{}, nil).Once()
+		_, err := svc.Sync(context.Background(), 7, SyncRequest{Cursor: ptrInt64(1), Limit: 1_000_000})
+		assert.NoError(t, err)
+		ir.AssertExpectations(t)
+	})
+
+	t.Run("cursor -> GetItemsChangedSince, unchanged when nothing new", func(t *testing.T) {
+		ir := new(mockItemRepo)
+		svc := NewItemService(ir, new(mockBlobRepo), nil, logger)
+		ir.On("GetItemsChangedSince", mock.Anything, int64(7), int64(9), DefaultSyncPageSize+1).Return([]model.Item{}, nil).Once()
+
+		res, err := svc.Sync(context.Background(), 7, SyncRequest{Cursor: ptrInt64(9)})
+		assert.NoError(t, err)
+		assert.Empty(t, res.ServerChanges)
+		assert.Equal(t, int64(9), res.Cursor)
+		ir.AssertExpectations(t)
+	})
+
+	t.Run("retrieval error keeps client cursor", func(t *testing.T) {
+		ir := new(mockItemRepo)
+		svc := NewItemService(ir, new(mockBlobRepo), nil, logger)
+		ir.On("GetItemsChangedSince", mock.Anything, int64(7), int64(3), DefaultSyncPageSize+1).Return(nil, errors.New("db")).Once()
+
+		res, err := svc.Sync(context.Background(), 7, SyncRequest{Cursor: ptrInt64(3)})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), res.Cursor)
+	})
+}
+
+func TestItemService_Sync_UnchangedKeepsCursor(t *testing.T) {
+	svc, _ := newSQLiteItemService(t)
+	ctx := context.Background()
+	create := SyncChange{ID: "c1", Version: ptrInt64(0), Name: ptrStr("mail"), PasswordCipher: []byte{1}, PasswordNonce: []byte{2}}
+	res, err := svc.Sync(ctx, 3, SyncRequest{Changes: []SyncChange{create}, Cursor: ptrInt64(0)})
+	require.NoError(t, err)
+	require.Len(t, res.ServerChanges, 1)
+	cursor := res.Cursor
+
+	// клиент дважды подряд присылает ту же запись без правок: новых изменений нет
+	same := create
+	same.Version = ptrInt64(1)
+	for i := 0; i < 2; i++ {
+		res, err = svc.Sync(ctx, 3, SyncRequest{Changes: []SyncChange{same}, Cursor: &cursor})
+		require.NoError(t, err)
+		assert.Equal(t, []AppliedResult{{ID: "c1", NewVersion: 1}}, res.Applied)
+		assert.Empty(t, res.ServerChanges, "sync %d", i+1)
+		assert.Equal(t, cursor, res.Cursor)
+	}
+}
+
 func TestItemService_Sync_VersionConflict_MinimalServerView(t *testing.T) {
 	ir := new(mockItemRepo)
-	svc := NewItemService(ir, new(mockBlobRepo), zap.NewNop().Sugar())
+	svc := NewItemService(ir, new(mockBlobRepo), nil, zap.NewNop().Sugar())
 	ctx := context.Background()
 	now := time.Now().UTC()
 	current := &model.Item{ID: "ic1", UserID: 7, Version: 2, UpdatedAt: now, Name: "n", FileName: "f"}
@@ -338,9 +622,143 @@ func TestItemService_Sync_VersionConflict_MinimalServerView(t *testing.T) {
 	ir.AssertExpectations(t)
 }
 
+func TestItemService_Sync_FieldMerge(t *testing.T) {
+	ctx := context.Background()
+	// сервер на версии 3: пароль менялся в версии 3, текст — в версии 1
+	newCurrent := func() *model.Item {
+		return &model.Item{
+			ID: "fm1", UserID: 7, Version: 3, Name: "n",
+			PasswordCipher: []byte{3}, PasswordNonce: []byte{3},
+			TextCipher: []byte{1}, TextNonce: []byte{1},
+			FieldVersions: model.FieldVersions{model.FieldName: 1, model.FieldPassword: 3, model.FieldText: 1},
+		}
+	}
+
+	t.Run("disjoint fields are merged", func(t *testing.T) {
+		ir := new(mockItemRepo)
+		svc := NewItemService(ir, new(mockBlobRepo), nil, zap.NewNop().Sugar())
+		ir.On("GetByID", mock.Anything, int64(7), "fm1").Return(newCurrent(), nil).Once()
+		ir.On("UpdateWithVersion", mock.Anything, int64(7), "fm1", int64(3), mock.MatchedBy(func(u map[string]any) bool {
+			// применяется только текст; устаревший пароль клиента не затирает серверный
+			_, hasPass := u["password_cipher"]
+			return len(u) == 2 && !hasPass && assert.ObjectsAreEqual([]byte{2}, u["text_cipher"])
+		})).Return(int64(4), nil).Once()
+
+		res, err := svc.Sync(ctx, 7, SyncRequest{Changes: []SyncChange{{
+			ID: "fm1", Version: ptrInt64(1), Name: ptrStr("n"),
+			PasswordCipher: []byte{1}, PasswordNonce: []byte{1},
+			TextCipher: []byte{2}, TextNonce: []byte{2},
+			BaseVersions: map[string]int64{model.FieldText: 1},
+		}}})
+		assert.NoError(t, err)
+		assert.Empty(t, res.Conflicts)
+		if assert.Len(t, res.Applied, 1) {
+			assert.Equal(t, int64(4), res.Applied[0].NewVersion)
+		}
+		ir.AssertExpectations(t)
+	})
+
+	t.Run("field changed on both sides conflicts", func(t *testing.T) {
+		ir := new(mockItemRepo)
+		svc := NewItemService(ir, new(mockBlobRepo), nil, zap.NewNop().Sugar())
+		ir.On("GetByID", mock.Anything, int64(7), "fm1").Return(newCurrent(), nil).Once()
+
+		res, err := svc.Sync(ctx, 7, SyncRequest{Changes: []SyncChange{{
+			ID: "fm1", Version: ptrInt64(1),
+			PasswordCipher: []byte{9}, PasswordNonce: []byte{9},
+			TextCipher: []byte{2}, TextNonce: []byte{2},
+			BaseVersions: map[string]int64{model.FieldPassword: 1, model.FieldText: 1},
+		}}})
+		assert.NoError(t, err)
+		assert.Empty(t, res.Applied)
+		if assert.Len(t, res.Conflicts, 1) {
+			assert.Equal(t, "version_conflict", res.Conflicts[0].Reason)
+			assert.Equal(t, []string{model.FieldPassword}, res.Conflicts[0].Fields)
+		}
+		ir.AssertNotCalled(t, "UpdateWithVersion", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("same value on both sides is not a conflict", func(t *testing.T) {
+		ir := new(mockItemRepo)
+		svc := NewItemService(ir, new(mockBlobRepo), nil, zap.NewNop().Sugar())
+		ir.On("GetByID", mock.Anything, int64(7), "fm1").Return(newCurrent(), nil).Once()
+
+		res, err := svc.Sync(ctx, 7, SyncRequest{Changes: []SyncChange{{
+			ID: "fm1", Version: ptrInt64(1),
+			PasswordCipher: []byte{3}, PasswordNonce: []byte{3},
+			BaseVersions: map[string]int64{model.FieldPassword: 1},
+		}}})
+		assert.NoError(t, err)
+		assert.Empty(t, res.Conflicts)
+		if assert.Len(t, res.Applied, 1) {
+			assert.Equal(t, int64(3), res.Applied[0].NewVersion)
+		}
+		ir.AssertExpectations(t)
+	})
+
+	t.Run("legacy item without field versions is conservative", func(t *testing.T) {
+		ir := new(mockItemRepo)
+		svc := NewItemService(ir, new(mockBlobRepo), nil, zap.NewNop().Sugar())
+		cur := newCurrent()
+		cur.FieldVersions = nil
+		ir.On("GetByID", mock.Anything, int64(7), "fm1").Return(cur, nil).Once()
+
+		res, err := svc.Sync(ctx, 7, SyncRequest{Changes: []SyncChange{{
+			ID: "fm1", Version: ptrInt64(1),
+			TextCipher: []byte{2}, TextNonce: []byte{2},
+			BaseVersions: map[string]int64{model.FieldText: 1},
+		}}})
+		assert.NoError(t, err)
+		if assert.Len(t, res.Conflicts, 1) {
+			assert.Equal(t, []string{model.FieldText}, res.Conflicts[0].Fields)
+		}
+	})
+}
+
+func TestItemService_Sync_PerItemResolutions(t *testing.T) {
+	ir := new(mockItemRepo)
+	svc := NewItemService(ir, new(mockBlobRepo), nil, zap.NewNop().Sugar())
+	ctx := context.Background()
+	a := &model.Item{ID: "ra", UserID: 7, Version: 4, Name: "a"}
+	b := &model.Item{ID: "rb", UserID: 7, Version: 6, Name: "b", TextCipher: []byte{1}}
+	c := &model.Item{ID: "rc", UserID: 7, Version: 2, Name: "c"}
+	ir.On("GetByID", mock.Anything, int64(7), "ra").Return(a, nil).Once()
+	ir.On("GetByID", mock.Anything, int64(7), "rb").Return(b, nil).Once()
+	ir.On("GetByID", mock.Anything, int64(7), "rc").Return(c, nil).Once()
+	// ra: персональная стратегия client важнее общей server
+	ir.On("UpdateWithVersion", mock.Anything, int64(7), "ra", int64(4), mock.Anything).Return(int64(5), nil).Once()
+
+	server := "server"
+	res, err := svc.Sync(ctx, 7, SyncRequest{
+		Resolve:     &server,
+		Resolutions: map[string]string{"ra": "client", "rc": "both"},
+		Changes: []SyncChange{
+			{ID: "ra", Version: ptrInt64(1), Name: ptrStr("a2")},
+			{ID: "rb", Version: ptrInt64(1), Name: ptrStr("b2")},
+			{ID: "rc", Version: ptrInt64(1), Name: ptrStr("c2")},
+		},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, res.Applied, 1) {
+		assert.Equal(t, AppliedResult{ID: "ra", NewVersion: 5}, res.Applied[0])
+	}
+	if assert.Len(t, res.Conflicts, 2) {
+		// rb и rc — общая стратегия server: полный снимок с шифрованными полями
+		full, ok := res.Conflicts[0].ServerItem.(map[string]any)
+		if assert.True(t, ok) {
+			assert.Equal(t, []byte{1}, full["text_cipher"])
+		}
+		// rc — both: клиенту нужен тот же полный снимок, что и при server
+		assert.Equal(t, "rc", res.Conflicts[1].ID)
+		_, ok = res.Conflicts[1].ServerItem.(map[string]any)
+		assert.True(t, ok)
+	}
+	ir.AssertExpectations(t)
+}
+
 func TestItemService_Sync_UpdatePatchContent(t *testing.T) {
 	ir := new(mockItemRepo)
-	svc := NewItemService(ir, new(mockBlobRepo), zap.NewNop().Sugar())
+	svc := NewItemService(ir, new(mockBlobRepo), nil, zap.NewNop().Sugar())
 	ctx := context.Background()
 	blobID := "BID"
 	current := &model.Item{ID: "p1", UserID: 7, Version: 1, Name: "cur", FileName: "file", BlobID: &blobID}
@@ -385,7 +803,7 @@ func TestItemService_Sync_UpdatePatchContent(t *testing.T) {
 
 func TestItemService_Sync_CreateFieldMapping(t *testing.T) {
 	ir := new(mockItemRepo)
-	svc := NewItemService(ir, new(mockBlobRepo), zap.NewNop().Sugar())
+	svc := NewItemService(ir, new(mockBlobRepo), nil, zap.NewNop().Sugar())
 	ctx := context.Background()
 
 	// не найдено в репозитории
@@ -425,3 +843,52 @@ func TestItemService_Sync_CreateFieldMapping(t *testing.T) {
 	assert.NoError(t, err)
 	ir.AssertExpectations(t)
 }
+
+func TestItemService_Sync_AtomicAbortsWholeBatch(t *testing.T) {
+	ir := new(mockItemRepo)
+	svc := NewItemService(ir, new(mockBlobRepo), nil, zap.NewNop().Sugar())
+	ctx := context.Background()
+	ok := &model.Item{ID: "a1", UserID: 7, Version: 2, Name: "ok"}
+	stale := &model.Item{ID: "a2", UserID: 7, Version: 5, Name: "srv"}
+	ir.On("GetByID", mock.Anything, int64(7), "a1").Return(ok, nil).Once()
+	ir.On("GetByID", mock.Anything, int64(7), "a2").Return(stale, nil).Once()
+	ir.On("UpdateWithVersion", mock.Anything, int64(7), "a1", int64(2), mock.Anything).Return(int64(3), nil).Once()
+
+	res, err := svc.Sync(ctx, 7, SyncRequest{
+		Atomic: true,
+		Changes: []SyncChange{
+			{ID: "a1", Version: ptrInt64(2), Name: ptrStr("ok2")},
+			{ID: "a2", Version: ptrInt64(1), Name: ptrStr("mine")},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, res.Applied)
+	if assert.Len(t, res.Conflicts, 2) {
+		assert.Equal(t, "a2", res.Conflicts[0].ID)
+		assert.Equal(t, "version_conflict", res.Conflicts[0].Reason)
+		assert.Equal(t, ConflictResult{ID: "a1", Reason: ReasonAtomicAborted}, res.Conflicts[1])
+	}
+	ir.AssertExpectations(t)
+}
+
+func TestItemService_Sync_AtomicAppliesAll(t *testing.T) {
+	ir := new(mockItemRepo)
+	svc := NewItemService(ir, new(mockBlobRepo), nil, zap.NewNop().Sugar())
+	ctx := context.Background()
+	ir.On("GetByID", mock.Anything, int64(7), "n1").Return((*model.Item)(nil), gorm.ErrRecordNotFound).Once()
+	ir.On("Create", mock.Anything, mock.AnythingOfType("*model.Item")).Return(nil).Once()
+	ir.On("GetByID", mock.Anything, int64(7), "n2").Return(&model.Item{ID: "n2", UserID: 7, Version: 1}, nil).Once()
+	ir.On("UpdateWithVersion", mock.Anything, int64(7), "n2", int64(1), mock.Anything).Return(int64(2), nil).Once()
+
+	res, err := svc.Sync(ctx, 7, SyncRequest{
+		Atomic: true,
+		Changes: []SyncChange{
+			{ID: "n1", Version: ptrInt64(0), Name: ptrStr("new")},
+			{ID: "n2", Version: ptrInt64(1), Name: ptrStr("upd")},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, res.Conflicts)
+	assert.Equal(t, []AppliedResult{{ID: "n1", NewVersion: 1}, {ID: "n2", NewVersion: 2}}, res.Applied)
+	ir.AssertExpectations(t)
+}
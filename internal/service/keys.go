@@ -0,0 +1,71 @@
+package service
+
+import (
+	"GophKeeper/internal/model"
+	"GophKeeper/internal/repo"
+	"GophKeeper/internal/telemetry"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// publicKeyLen — длина открытого ключа X25519.
+const publicKeyLen = 32
+
+// ErrInvalidPublicKey — открытый ключ не является ключом X25519.
+var ErrInvalidPublicKey = errors.New("public key must be 32 bytes (X25519)")
+
+// SetKeyRepository задаёт репозиторий ключей пользователей (нужен для share).
+func (s *UserService) SetKeyRepository(r repo.KeyRepository) {
+	s.keys = r
+}
+
+// PublishKeys сохраняет пару ключей пользователя: открытый ключ и закрытый,
+// зашифрованный ключом хранилища клиента.
+func (s *UserService) PublishKeys(ctx context.Context, k *model.UserKeys) (err error) {
+	ctx, span := telemetry.Start(ctx, "UserService.PublishKeys")
+	defer func() { telemetry.End(span, err) }()
+
+	if s.keys == nil {
+		return errors.New("key repository not configured")
+	}
+	if len(k.PublicKey) != publicKeyLen {
+		return ErrInvalidPublicKey
+	}
+	return s.keys.SaveKeys(ctx, k)
+}
+
+// Keys возвращает опубликованные ключи пользователя (repo.ErrKeysNotFound, если их нет).
+func (s *UserService) Keys(ctx context.Context, userID int64) (_ *model.UserKeys, err error) {
+	ctx, span := telemetry.Start(ctx, "UserService.Keys")
+	defer func() { telemetry.End(span, err) }()
+
+	if s.keys == nil {
+		return nil, errors.New("key repository not configured")
+	}
+	return s.keys.GetKeys(ctx, userID)
+}
+
+// PublicKey возвращает открытый ключ пользователя login (repo.ErrKeysNotFound,
+// если пользователя нет или он не опубликовал ключ).
+func (s *UserService) PublicKey(ctx context.Context, login string) (_ []byte, err error) {
+	ctx, span := telemetry.Start(ctx, "UserService.PublicKey")
+	defer func() { telemetry.End(span, err) }()
+
+	if s.keys == nil {
+		return nil, errors.New("key repository not configured")
+	}
+	u, err := s.repo.GetUserByLogin(ctx, login)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, repo.ErrKeysNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	k, err := s.keys.GetKeys(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	return k.PublicKey, nil
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"GophKeeper/internal/model"
+	"GophKeeper/internal/repo"
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type mockKeyRepo struct{ mock.Mock }
+
+func (m *mockKeyRepo) GetKeys(ctx context.Context, userID int64) (*model.UserKeys, error) {
+	args := m.Called(ctx, userID)
+	k, _ := args.Get(0).(*model.UserKeys)
+	return k, args.Error(1)
+}
+
+func (m *mockKeyRepo) SaveKeys(ctx context.Context, k *model.UserKeys) error {
+	return m.Called(ctx, k).Error(0)
+}
+
+var _ repo.KeyRepository = (*mockKeyRepo)(nil)
+
+func TestUserService_Keys(t *testing.T) {
+	ctx := context.Background()
+	ur := new(mockUserRepo)
+	kr := new(mockKeyRepo)
+	svc := NewUserService(ur)
+	svc.SetKeyRepository(kr)
+
+	pub := bytes.Repeat([]byte{1}, 32)
+	assert.ErrorIs(t, svc.PublishKeys(ctx, &model.UserKeys{UserID: 1, PublicKey: []byte("short")}), ErrInvalidPublicKey)
+
+	kr.On("SaveKeys", mock.Anything, mock.MatchedBy(func(k *model.UserKeys) bool { return k.UserID == 1 })).Return(nil).Once()
+	assert.NoError(t, svc.PublishKeys(ctx, &model.UserKeys{UserID: 1, PublicKey: pub}))
+
+	ur.On("GetUserByLogin", mock.Anything, "alice").Return(&model.User{ID: 1, Login: "alice"}, nil).Once()
+	kr.On("GetKeys", mock.Anything, int64(1)).Return(&model.UserKeys{UserID: 1, PublicKey: pub}, nil).Once()
+	got, err := svc.PublicKey(ctx, "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, pub, got)
+
+	ur.On("GetUserByLogin", mock.Anything, "nobody").Return(nil, gorm.ErrRecordNotFound).Once()
+	_, err = svc.PublicKey(ctx, "nobody")
+	assert.ErrorIs(t, err, repo.ErrKeysNotFound, "неизвестный логин неотличим от пользователя без ключа")
+
+	kr.AssertExpectations(t)
+	ur.AssertExpectations(t)
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"GophKeeper/internal/model"
+	"GophKeeper/internal/repo"
+	"GophKeeper/internal/telemetry"
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrForbidden — роли пользователя в организации недостаточно для операции.
+	ErrForbidden = errors.New("insufficient organization role")
+	// ErrLastOwner — у организации должен остаться хотя бы один владелец.
+	ErrLastOwner = errors.New("organization must keep at least one owner")
+	// ErrInvalidRole — неизвестная роль участника.
+	ErrInvalidRole = errors.New("invalid organization role")
+	// ErrMissingCollectionKey — не передан ключ коллекции, нужный участнику.
+	ErrMissingCollectionKey = errors.New("missing collection key")
+)
+
+// DefaultCollectionName — имя первой коллекции новой организации.
+const DefaultCollectionName = "default"
+
+// roleRank упорядочивает роли: чем больше, тем больше прав; 0 — неизвестная роль.
+var roleRank = map[string]int{
+	model.RoleReadOnly: 1,
+	model.RoleMember:   2,
+	model.RoleAdmin:    3,
+	model.RoleOwner:    4,
+}
+
+// roleAtLeast сообщает, что роль role не ниже min.
+func roleAtLeast(role, min string) bool {
+	return roleRank[role] >= roleRank[min]
+}
+
+// SetOrgRepository задаёт репозиторий организаций (нужен для org и коллекций в sync).
+func (s *ItemService) SetOrgRepository(r repo.OrgRepository) {
+	s.orgs = r
+}
+
+// CreateOrg создаёт организацию, делая пользователя её владельцем. Вместе с ней
+// создаётся коллекция collectionName (по умолчанию DefaultCollectionName), ключ которой
+// запечатан открытым ключом владельца.
+func (s *ItemService) CreateOrg(ctx context.Context, userID int64, name, collectionName string, key *model.CollectionKey) (_ *model.OrgMembership, err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.CreateOrg")
+	defer func() { telemetry.End(span, err) }()
+
+	if s.orgs == nil {
+		return nil, errors.New("org repository not configured")
+	}
+	if collectionName == "" {
+		collectionName = DefaultCollectionName
+	}
+	org := &model.Organization{Name: name}
+	col := &model.Collection{ID: uuid.NewString(), Name: collectionName}
+	if err := s.orgs.CreateOrg(ctx, org, userID, col, key); err != nil {
+		return nil, err
+	}
+	return &model.OrgMembership{Organization: *org, Role: model.RoleOwner, Collections: []model.Collection{*col}}, nil
+}
+
+// Orgs возвращает организации пользователя с его ролью и коллекциями.
+func (s *ItemService) Orgs(ctx context.Context, userID int64) (_ []model.OrgMembership, err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.Orgs")
+	defer func() { telemetry.End(span, err) }()
+
+	if s.orgs == nil {
+		return nil, errors.New("org repository not configured")
+	}
+	return s.orgs.ListMemberships(ctx, userID)
+}
+
+// OrgMembers возвращает участников организации; доступно любому её участнику.
+func (s *ItemService) OrgMembers(ctx context.Context, userID int64, orgName string) (_ []model.OrgMemberInfo, err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.OrgMembers")
+	defer func() { telemetry.End(span, err) }()
+
+	org, _, err := s.orgAccess(ctx, userID, orgName)
+	if err != nil {
+		return nil, err
+	}
+	return s.orgs.ListMembers(ctx, org.ID)
+}
+
+// CreateCollection создаёт коллекцию организации (admin и выше). keys — ключ коллекции,
+// запечатанный для участников по логину; ключ самого пользователя обязателен.
+func (s *ItemService) CreateCollection(ctx context.Context, userID int64, orgName, name string, keys map[string]model.CollectionKey) (_ *model.Collection, err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.CreateCollection")
+	defer func() { telemetry.End(span, err) }()
+
+	org, me, err := s.orgAccess(ctx, userID, orgName)
+	if err != nil {
+		return nil, err
+	}
+	if !roleAtLeast(me.Role, model.RoleAdmin) {
+		return nil, ErrForbidden
+	}
+	members, err := s.orgs.ListMembers(ctx, org.ID)
+	if err != nil {
+		return nil, err
+	}
+	byLogin := make(map[string]int64, len(members))
+	for _, m := range members {
+		byLogin[m.Login] = m.UserID
+	}
+	sealed := make([]model.CollectionKey, 0, len(keys))
+	hasOwn := false
+	for login, k := range keys {
+		id, ok := byLogin[login]
+		if !ok {
+			return nil, repo.ErrOrgMemberNotFound
+		}
+		k.UserID = id
+		hasOwn = hasOwn || id == userID
+		sealed = append(sealed, k)
+	}
+	if !hasOwn {
+		return nil, ErrMissingCollectionKey
+	}
+	col := &model.Collection{ID: uuid.NewString(), OrgID: org.ID, Name: name}
+	if err := s.orgs.CreateCollection(ctx, col, sealed); err != nil {
+		return nil, err
+	}
+	return col, nil
+}
+
+// SetOrgMember добавляет пользователя login в организацию или меняет его роль.
+// Приглашать может admin и выше; назначать и менять владельцев и админов — только
+// владелец. Новому участнику нужны ключи всех коллекций организации, запечатанные
+// его открытым ключом; существующему — только обновляемые.
+func (s *ItemService) SetOrgMember(ctx context.Context, userID int64, orgName, login, role string, keys []model.CollectionKey) (err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.SetOrgMember")
+	defer func() { telemetry.End(span, err) }()
+
+	if roleRank[role] == 0 {
+		return ErrInvalidRole
+	}
+	org, me, err := s.orgAccess(ctx, userID, orgName)
+	if err != nil {
+		return err
+	}
+	targetID, _, err := s.orgs.User(ctx, login)
+	if err != nil {
+		return err
+	}
+	prev, err := s.orgs.Member(ctx, org.ID, targetID)
+	if err != nil && !errors.Is(err, repo.ErrOrgMemberNotFound) {
+		return err
+	}
+	if err := checkManage(me, prev, role); err != nil {
+		return err
+	}
+	if prev != nil && prev.Role == model.RoleOwner && role != model.RoleOwner {
+		if err := s.keepOwner(ctx, org.ID); err != nil {
+			return err
+		}
+	}
+
+	cols, err := s.orgs.ListCollections(ctx, org.ID)
+	if err != nil {
+		return err
+	}
+	given := make(map[string]bool, len(keys))
+	for i := range keys {
+		keys[i].UserID = targetID
+		given[keys[i].CollectionID] = true
+	}
+	known := make(map[string]bool, len(cols))
+	for _, c := range cols {
+		known[c.ID] = true
+		if prev == nil && !given[c.ID] {
+			return ErrMissingCollectionKey
+		}
+	}
+	for id := range given {
+		if !known[id] {
+			return repo.ErrCollectionNotFound
+		}
+	}
+	return s.orgs.SaveMember(ctx, &model.OrgMember{OrgID: org.ID, UserID: targetID, Role: role}, keys)
+}
+
+// RemoveOrgMember исключает пользователя login из организации вместе с его ключами
+// коллекций. Участник может выйти сам; исключать других может admin и выше
+// (владельцев и админов — только владелец). Последнего владельца исключить нельзя.
+func (s *ItemService) RemoveOrgMember(ctx context.Context, userID int64, orgName, login string) (err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.RemoveOrgMember")
+	defer func() { telemetry.End(span, err) }()
+
+	org, me, err := s.orgAccess(ctx, userID, orgName)
+	if err != nil {
+		return err
+	}
+	targetID, _, err := s.orgs.User(ctx, login)
+	if errors.Is(err, repo.ErrRecipientNotFound) || errors.Is(err, repo.ErrRecipientHasNoKey) {
+		return repo.ErrOrgMemberNotFound
+	}
+	if err != nil {
+		return err
+	}
+	target, err := s.orgs.Member(ctx, org.ID, targetID)
+	if err != nil {
+		return err
+	}
+	if targetID != userID {
+		if err := checkManage(me, target, target.Role); err != nil {
+			return err
+		}
+	}
+	if target.Role == model.RoleOwner {
+		if err := s.keepOwner(ctx, org.ID); err != nil {
+			return err
+		}
+	}
+	return s.orgs.RemoveMember(ctx, org.ID, targetID)
+}
+
+// SaveCollectionItem создаёт или обновляет запись коллекции (member и выше).
+// Поля зашифрованы ключом коллекции; expectedVersion 0 — новая запись.
+// Несовпадение версии — ErrVersionMismatch.
+func (s *ItemService) SaveCollectionItem(ctx context.Context, userID int64, it *model.CollectionItem, expectedVersion int64) (_ int64, err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.SaveCollectionItem")
+	defer func() { telemetry.End(span, err) }()
+
+	if s.orgs == nil {
+		return 0, errors.New("org repository not configured")
+	}
+	col, err := s.orgs.GetCollection(ctx, it.CollectionID)
+	if err != nil {
+		return 0, err
+	}
+	me, err := s.orgs.Member(ctx, col.OrgID, userID)
+	if errors.Is(err, repo.ErrOrgMemberNotFound) {
+		return 0, repo.ErrCollectionNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !roleAtLeast(me.Role, model.RoleMember) {
+		return 0, ErrForbidden
+	}
+	it.UpdatedBy = userID
+	v, err := s.orgs.SaveCollectionItem(ctx, it, expectedVersion)
+	if errors.Is(err, repo.ErrCollectionItemConflict) {
+		return 0, ErrVersionMismatch
+	}
+	return v, err
+}
+
+// orgAccess возвращает организацию и членство в ней пользователя;
+// для не-участника организация не существует (repo.ErrOrgNotFound).
+func (s *ItemService) orgAccess(ctx context.Context, userID int64, orgName string) (*model.Organization, *model.OrgMember, error) {
+	if s.orgs == nil {
+		return nil, nil, errors.New("org repository not configured")
+	}
+	org, err := s.orgs.GetOrgByName(ctx, orgName)
+	if err != nil {
+		return nil, nil, err
+	}
+	me, err := s.orgs.Member(ctx, org.ID, userID)
+	if errors.Is(err, repo.ErrOrgMemberNotFound) {
+		return nil, nil, repo.ErrOrgNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return org, me, nil
+}
+
+// keepOwner не даёт лишить организацию последнего владельца.
+func (s *ItemService) keepOwner(ctx context.Context, orgID int64) error {
+	n, err := s.orgs.CountOwners(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if n <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// checkManage проверяет, что участник me может назначить роль role участнику target
+// (nil — новому): нужен admin и выше, а для ролей owner и admin — владелец.
+func checkManage(me, target *model.OrgMember, role string) error {
+	if !roleAtLeast(me.Role, model.RoleAdmin) {
+		return ErrForbidden
+	}
+	privileged := roleAtLeast(role, model.RoleAdmin) || (target != nil && roleAtLeast(target.Role, model.RoleAdmin))
+	if privileged && me.Role != model.RoleOwner {
+		return ErrForbidden
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"GophKeeper/internal/model"
+	"GophKeeper/internal/repo"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type mockOrgRepo struct{ mock.Mock }
+
+func (m *mockOrgRepo) CreateOrg(ctx context.Context, org *model.Organization, ownerID int64, col *model.Collection, key *model.CollectionKey) error {
+	return m.Called(ctx, org, ownerID, col, key).Error(0)
+}
+
+func (m *mockOrgRepo) GetOrgByName(ctx context.Context, name string) (*model.Organization, error) {
+	args := m.Called(ctx, name)
+	org, _ := args.Get(0).(*model.Organization)
+	return org, args.Error(1)
+}
+
+func (m *mockOrgRepo) User(ctx context.Context, login string) (int64, []byte, error) {
+	args := m.Called(ctx, login)
+	pub, _ := args.Get(1).([]byte)
+	return args.Get(0).(int64), pub, args.Error(2)
+}
+
+func (m *mockOrgRepo) Member(ctx context.Context, orgID, userID int64) (*model.OrgMember, error) {
+	args := m.Called(ctx, orgID, userID)
+	mem, _ := args.Get(0).(*model.OrgMember)
+	return mem, args.Error(1)
+}
+
+func (m *mockOrgRepo) CountOwners(ctx context.Context, orgID int64) (int64, error) {
+	args := m.Called(ctx, orgID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockOrgRepo) SaveMember(ctx context.Context, mem *model.OrgMember, keys []model.CollectionKey) error {
+	return m.Called(ctx, mem, keys).Error(0)
+}
+
+func (m *mockOrgRepo) RemoveMember(ctx context.Context, orgID, userID int64) error {
+	return m.Called(ctx, orgID, userID).Error(0)
+}
+
+func (m *mockOrgRepo) ListMembers(ctx context.Context, orgID int64) ([]model.OrgMemberInfo, error) {
+	args := m.Called(ctx, orgID)
+	out, _ := args.Get(0).([]model.OrgMemberInfo)
+	return out, args.Error(1)
+}
+
+func (m *mockOrgRepo) ListMemberships(ctx context.Context, userID int64) ([]model.OrgMembership, error) {
+	args := m.Called(ctx, userID)
+	out, _ := args.Get(0).([]model.OrgMembership)
+	return out, args.Error(1)
+}
+
+func (m *mockOrgRepo) ListCollections(ctx context.Context, orgID int64) ([]model.Collection, error) {
+	args := m.Called(ctx, orgID)
+	out, _ := args.Get(0).([]model.Collection)
+	return out, args.Error(1)
+}
+
+func (m *mockOrgRepo) CreateCollection(ctx context.Context, col *model.Collection, keys []model.CollectionKey) error {
+	return m.Called(ctx, col, keys).Error(0)
+}
+
+func (m *mockOrgRepo) GetCollection(ctx context.Context, id string) (*model.Collection, error) {
+	args := m.Called(ctx, id)
+	col, _ := args.Get(0).(*model.Collection)
+	return col, args.Error(1)
+}
+
+func (m *mockOrgRepo) SaveCollectionItem(ctx context.Context, it *model.CollectionItem, expectedVersion int64) (int64, error) {
+	args := m.Called(ctx, it, expectedVersion)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockOrgRepo) CollectionsForUser(ctx context.Context, userID int64) ([]model.CollectionSnapshot, error) {
+	args := m.Called(ctx, userID)
+	out, _ := args.Get(0).([]model.CollectionSnapshot)
+	return out, args.Error(1)
+}
+
+var _ repo.OrgRepository = (*mockOrgRepo)(nil)
+
+func orgLogin(id int64) string { return "u" + strconv.FormatInt(id, 10) }
+
+// newOrgTestService — сервис с организацией acme (id 10) из участников с ролями roles
+// (user id → роль) и коллекцией c1; пользователь с id N имеет логин "uN".
+func newOrgTestService(roles map[int64]string) (*ItemService, *mockOrgRepo) {
+	or := new(mockOrgRepo)
+	svc := NewItemService(new(mockItemRepo), nil, nil, zap.NewNop().Sugar())
+	svc.SetOrgRepository(or)
+
+	or.On("GetOrgByName", mock.Anything, "acme").Return(&model.Organization{ID: 10, Name: "acme"}, nil).Maybe()
+	or.On("GetOrgByName", mock.Anything, mock.Anything).Return(nil, repo.ErrOrgNotFound).Maybe()
+	owners := int64(0)
+	for id, role := range roles {
+		or.On("Member", mock.Anything, int64(10), id).Return(&model.OrgMember{OrgID: 10, UserID: id, Role: role}, nil).Maybe()
+		if role == model.RoleOwner {
+			owners++
+		}
+	}
+	or.On("Member", mock.Anything, int64(10), mock.Anything).Return(nil, repo.ErrOrgMemberNotFound).Maybe()
+	for _, id := range []int64{1, 2, 3, 4, 5} {
+		or.On("User", mock.Anything, orgLogin(id)).Return(id, []byte("pub"), nil).Maybe()
+	}
+	or.On("CountOwners", mock.Anything, int64(10)).Return(owners, nil).Maybe()
+	or.On("ListCollections", mock.Anything, int64(10)).Return([]model.Collection{{ID: "c1", OrgID: 10, Name: "default"}}, nil).Maybe()
+	return svc, or
+}
+
+func TestItemService_SetOrgMember(t *testing.T) {
+	ctx := context.Background()
+	// 1 — владелец, 2 — админ, 3 — участник, 4 — только чтение, 5 — не в организации
+	roles := map[int64]string{1: model.RoleOwner, 2: model.RoleAdmin, 3: model.RoleMember, 4: model.RoleReadOnly}
+	key := []model.CollectionKey{{CollectionID: "c1", WrappedKey: []byte("k")}}
+
+	tests := []struct {
+		name    string
+		caller  int64
+		org     string
+		login   string
+		role    string
+		keys    []model.CollectionKey
+		wantErr error
+	}{
+		{name: "админ приглашает участника", caller: 2, login: "u5", role: model.RoleMember, keys: key},
+		{name: "владелец назначает админа", caller: 1, login: "u3", role: model.RoleAdmin},
+		{name: "владелец передаёт владение", caller: 1, login: "u2", role: model.RoleOwner},
+		{name: "админ не назначает админов", caller: 2, login: "u5", role: model.RoleAdmin, keys: key, wantErr: ErrForbidden},
+		{name: "админ не понижает админа", caller: 2, login: "u2", role: model.RoleMember, wantErr: ErrForbidden},
+		{name: "участник не приглашает", caller: 3, login: "u5", role: model.RoleReadOnly, keys: key, wantErr: ErrForbidden},
+		{name: "чужая организация", caller: 5, login: "u4", role: model.RoleMember, wantErr: repo.ErrOrgNotFound},
+		{name: "нет организации", caller: 1, org: "nope", login: "u5", role: model.RoleMember, wantErr: repo.ErrOrgNotFound},
+		{name: "неизвестная роль", caller: 1, login: "u5", role: "root", keys: key, wantErr: ErrInvalidRole},
+		{name: "новому нужны все ключи", caller: 1, login: "u5", role: model.RoleMember, wantErr: ErrMissingCollectionKey},
+		{name: "ключ чужой коллекции", caller: 1, login: "u3", role: model.RoleMember,
+			keys: []model.CollectionKey{{CollectionID: "x"}}, wantErr: repo.ErrCollectionNotFound},
+		{name: "последний владелец", caller: 1, login: "u1", role: model.RoleAdmin, wantErr: ErrLastOwner},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, or := newOrgTestService(roles)
+			org := tt.org
+			if org == "" {
+				org = "acme"
+			}
+			if tt.wantErr == nil {
+				or.On("SaveMember", mock.Anything, mock.MatchedBy(func(m *model.OrgMember) bool {
+					return m.OrgID == 10 && orgLogin(m.UserID) == tt.login && m.Role == tt.role
+				}), mock.Anything).Return(nil).Once()
+			}
+			err := svc.SetOrgMember(ctx, tt.caller, org, tt.login, tt.role, tt.keys)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			or.AssertExpectations(t)
+		})
+	}
+}
+
+func TestItemService_RemoveOrgMember(t *testing.T) {
+	ctx := context.Background()
+	roles := map[int64]string{1: model.RoleOwner, 2: model.RoleAdmin, 3: model.RoleMember, 4: model.RoleReadOnly}
+
+	tests := []struct {
+		name    string
+		caller  int64
+		login   string
+		wantErr error
+	}{
+		{name: "админ исключает участника", caller: 2, login: "u3"},
+		{name: "участник выходит сам", caller: 4, login: "u4"},
+		{name: "владелец исключает админа", caller: 1, login: "u2"},
+		{name: "админ не исключает админа", caller: 2, login: "u1", wantErr: ErrForbidden},
+		{name: "участник не исключает других", caller: 3, login: "u4", wantErr: ErrForbidden},
+		{name: "не участник", caller: 1, login: "u5", wantErr: repo.ErrOrgMemberNotFound},
+		{name: "последний владелец", caller: 1, login: "u1", wantErr: ErrLastOwner},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, or := newOrgTestService(roles)
+			if tt.wantErr == nil {
+				id, _ := strconv.ParseInt(strings.TrimPrefix(tt.login, "u"), 10, 64)
+				or.On("RemoveMember", mock.Anything, int64(10), id).Return(nil).Once()
+			}
+			err := svc.RemoveOrgMember(ctx, tt.caller, "acme", tt.login)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			or.AssertExpectations(t)
+		})
+	}
+}
+
+func TestItemService_CreateCollection(t *testing.T) {
+	ctx := context.Background()
+	roles := map[int64]string{1: model.RoleOwner, 3: model.RoleMember}
+	members := []model.OrgMemberInfo{{UserID: 1, Login: "u1", Role: model.RoleOwner}, {UserID: 3, Login: "u3", Role: model.RoleMember}}
+	k := model.CollectionKey{WrappedKey: []byte("k")}
+
+	svc, or := newOrgTestService(roles)
+	or.On("ListMembers", mock.Anything, int64(10)).Return(members, nil)
+	_, err := svc.CreateCollection(ctx, 3, "acme", "ops", map[string]model.CollectionKey{"u3": k})
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = svc.CreateCollection(ctx, 1, "acme", "ops", map[string]model.CollectionKey{"u3": k})
+	assert.ErrorIs(t, err, ErrMissingCollectionKey)
+	_, err = svc.CreateCollection(ctx, 1, "acme", "ops", map[string]model.CollectionKey{"u1": k, "u5": k})
+	assert.ErrorIs(t, err, repo.ErrOrgMemberNotFound)
+
+	or.On("CreateCollection", mock.Anything, mock.MatchedBy(func(c *model.Collection) bool {
+		return c.OrgID == 10 && c.Name == "ops" && c.ID != ""
+	}), mock.MatchedBy(func(keys []model.CollectionKey) bool { return len(keys) == 2 })).Return(nil).Once()
+	col, err := svc.CreateCollection(ctx, 1, "acme", "ops", map[string]model.CollectionKey{"u1": k, "u3": k})
+	require.NoError(t, err)
+	assert.Equal(t, "ops", col.Name)
+	or.AssertExpectations(t)
+}
+
+func TestItemService_SaveCollectionItem(t *testing.T) {
+	ctx := context.Background()
+	roles := map[int64]string{1: model.RoleOwner, 4: model.RoleReadOnly}
+	svc, or := newOrgTestService(roles)
+	or.On("GetCollection", mock.Anything, "c1").Return(&model.Collection{ID: "c1", OrgID: 10}, nil)
+	or.On("GetCollection", mock.Anything, "x").Return(nil, repo.ErrCollectionNotFound)
+
+	_, err := svc.SaveCollectionItem(ctx, 4, &model.CollectionItem{CollectionID: "c1"}, 0)
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = svc.SaveCollectionItem(ctx, 5, &model.CollectionItem{CollectionID: "c1"}, 0)
+	assert.ErrorIs(t, err, repo.ErrCollectionNotFound, "не участнику коллекция не видна")
+	_, err = svc.SaveCollectionItem(ctx, 1, &model.CollectionItem{CollectionID: "x"}, 0)
+	assert.ErrorIs(t, err, repo.ErrCollectionNotFound)
+
+	or.On("SaveCollectionItem", mock.Anything, mock.MatchedBy(func(it *model.CollectionItem) bool { return it.UpdatedBy == 1 }), int64(0)).
+		Return(int64(1), nil).Once()
+	v, err := svc.SaveCollectionItem(ctx, 1, &model.CollectionItem{ID: "i1", CollectionID: "c1"}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), v)
+
+	or.On("SaveCollectionItem", mock.Anything, mock.Anything, int64(1)).Return(int64(0), repo.ErrCollectionItemConflict).Once()
+	_, err = svc.SaveCollectionItem(ctx, 1, &model.CollectionItem{ID: "i1", CollectionID: "c1"}, 1)
+	assert.ErrorIs(t, err, ErrVersionMismatch)
+}
+
+func TestItemService_Sync_Collections(t *testing.T) {
+	ctx := context.Background()
+	ir := new(mockItemRepo)
+	or := new(mockOrgRepo)
+	svc := NewItemService(ir, nil, nil, zap.NewNop().Sugar())
+	svc.SetOrgRepository(or)
+	snaps := []model.CollectionSnapshot{{Collection: model.Collection{ID: "c1"}, OrgName: "acme"}}
+
+	// последняя страница несёт коллекции
+	ir.On("GetItemsChangedSince", mock.Anything, int64(7), int64(0), DefaultSyncPageSize+1).Return([]model.Item{}, nil).Once()
+	or.On("CollectionsForUser", mock.Anything, int64(7)).Return(snaps, nil).Once()
+	res, err := svc.Sync(ctx, 7, SyncRequest{Cursor: ptrInt64(0)})
+	require.NoError(t, err)
+	assert.Equal(t, snaps, res.Collections)
+
+	// ошибка не ломает синхронизацию: коллекций в ответе просто нет
+	ir.On("GetItemsChangedSince", mock.Anything, int64(7), int64(0), DefaultSyncPageSize+1).Return([]model.Item{}, nil).Once()
+	or.On("CollectionsForUser", mock.Anything, int64(7)).Return(nil, errors.New("db down")).Once()
+	res, err = svc.Sync(ctx, 7, SyncRequest{Cursor: ptrInt64(0)})
+	require.NoError(t, err)
+	assert.Nil(t, res.Collections)
+
+	// без запроса изменений коллекции не нужны
+	res, err = svc.Sync(ctx, 7, SyncRequest{})
+	require.NoError(t, err)
+	assert.Nil(t, res.Collections)
+	or.AssertExpectations(t)
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"GophKeeper/internal/model"
+	"GophKeeper/internal/telemetry"
+	"context"
+	"errors"
+)
+
+// ErrQuotaExceeded возвращается, когда операция превысила бы квоту пользователя.
+var ErrQuotaExceeded = errors.New("storage quota exceeded")
+
+// Причины отказа в Sync при превышении квот.
+const (
+	ReasonQuotaExceeded = "quota_exceeded"
+	ReasonItemTooLarge  = "item_too_large"
+)
+
+// Quota — ограничения на объём данных одного пользователя. Нулевое значение — без ограничения.
+type Quota struct {
+	MaxItems     int64 // количество неудалённых items
+	MaxBlobBytes int64 // суммарный размер блобов
+	MaxItemBytes int64 // суммарный размер шифртекстов одного item
+}
+
+// Usage — текущее использование хранилища пользователем и действующие лимиты.
+type Usage struct {
+	Items     int64
+	BlobBytes int64
+	Quota     Quota
+}
+
+// SetQuota задаёт квоты, применяемые в Sync и SaveBlob.
+func (s *ItemService) SetQuota(q Quota) {
+	s.quota = q
+}
+
+// Usage возвращает текущее использование хранилища пользователем.
+func (s *ItemService) Usage(ctx context.Context, userID int64) (_ Usage, err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.Usage")
+	defer func() { telemetry.End(span, err) }()
+
+	u := Usage{Quota: s.quota}
+	n, err := s.repo.CountByUser(ctx, userID)
+	if err != nil {
+		return u, err
+	}
+	u.Items = n
+	if s.blobRepo != nil {
+		b, err := s.blobRepo.SumSizeByUser(ctx, userID)
+		if err != nil {
+			return u, err
+		}
+		u.BlobBytes = b
+	}
+	return u, nil
+}
+
+// checkBlobQuota проверяет, что новый блоб размера size помещается в квоту пользователя.
+func (s *ItemService) checkBlobQuota(ctx context.Context, userID int64, size int64) error {
+	if s.quota.MaxBlobBytes <= 0 {
+		return nil
+	}
+	used, err := s.blobRepo.SumSizeByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if used+size > s.quota.MaxBlobBytes {
+		return ErrQuotaExceeded
+	}
+	return nil
+}
+
+// itemTooLarge сообщает, превысит ли item после применения изменения лимит на размер шифртекстов.
+// cur == nil для новых записей.
+func (s *ItemService) itemTooLarge(ch SyncChange, cur *model.Item) bool {
+	if s.quota.MaxItemBytes <= 0 {
+		return false
+	}
+	return itemCipherSize(ch, cur) > s.quota.MaxItemBytes
+}
+
+// itemCipherSize считает размер шифртекстов item после применения изменения:
+// присланные поля заменяют текущие, остальные берутся из cur.
+func itemCipherSize(ch SyncChange, cur *model.Item) int64 {
+	pick := func(changed, current []byte) int64 {
+		if changed != nil {
+			return int64(len(changed))
+		}
+		return int64(len(current))
+	}
+	if cur == nil {
+		cur = &model.Item{}
+	}
+	return pick(ch.LoginCipher, cur.LoginCipher) + pick(ch.LoginNonce, cur.LoginNonce) +
+		pick(ch.PasswordCipher, cur.PasswordCipher) + pick(ch.PasswordNonce, cur.PasswordNonce) +
+		pick(ch.TextCipher, cur.TextCipher) + pick(ch.TextNonce, cur.TextNonce) +
+		pick(ch.CardCipher, cur.CardCipher) + pick(ch.CardNonce, cur.CardNonce)
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"GophKeeper/internal/model"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestItemService_Sync_ItemCountQuota(t *testing.T) {
+	ir := new(mockItemRepo)
+	svc := NewItemService(ir, new(mockBlobRepo), nil, zap.NewNop().Sugar())
+	svc.SetQuota(Quota{MaxItems: 2})
+	ctx := context.Background()
+
+	ir.On("CountByUser", mock.Anything, int64(7)).Return(int64(1), nil).Once()
+	ir.On("GetByID", mock.Anything, int64(7), mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+	ir.On("Create", mock.Anything, mock.AnythingOfType("*model.Item")).Return(nil).Once()
+
+	res, err := svc.Sync(ctx, 7, SyncRequest{Changes: []SyncChange{
+		{ID: "q1", Version: ptrInt64(0)},
+		{ID: "q2", Version: ptrInt64(0)},
+	}})
+	assert.NoError(t, err)
+	if assert.Len(t, res.Applied, 1) {
+		assert.Equal(t, "q1", res.Applied[0].ID)
+	}
+	if assert.Len(t, res.Conflicts, 1) {
+		assert.Equal(t, "q2", res.Conflicts[0].ID)
+		assert.Equal(t, ReasonQuotaExceeded, res.Conflicts[0].Reason)
+	}
+	ir.AssertExpectations(t)
+}
+
+func TestItemService_Sync_UndeleteCountsAgainstQuota(t *testing.T) {
+	ir := new(mockItemRepo)
+	svc := NewItemService(ir, new(mockBlobRepo), nil, zap.NewNop().Sugar())
+	svc.SetQuota(Quota{MaxItems: 2})
+	ctx := context.Background()
+
+	// 2 живые записи из 2; live удаляется и освобождает место, затем одна из двух
+	// удалённых восстанавливается, а вторая упирается в квоту
+	ir.On("CountByUser", mock.Anything, int64(7)).Return(int64(2), nil).Once()
+	ir.On("GetByID", mock.Anything, int64(7), "live").Return(&model.Item{ID: "live", Version: 1}, nil).Once()
+	ir.On("GetByID", mock.Anything, int64(7), "t1").Return(&model.Item{ID: "t1", Version: 2, Deleted: true}, nil).Once()
+	ir.On("GetByID", mock.Anything, int64(7), "t2").Return(&model.Item{ID: "t2", Version: 2, Deleted: true}, nil).Once()
+	ir.On("UpdateWithVersion", mock.Anything, int64(7), "live", int64(1), map[string]any{"deleted": true}).Return(int64(2), nil).Once()
+	ir.On("UpdateWithVersion", mock.Anything, int64(7), "t1", int64(2), map[string]any{"deleted": false}).Return(int64(3), nil).Once()
+
+	res, err := svc.Sync(ctx, 7, SyncRequest{Changes: []SyncChange{
+		{ID: "live", Version: ptrInt64(1), Deleted: ptrBool(true)},
+		{ID: "t1", Version: ptrInt64(2), Deleted: ptrBool(false)},
+		{ID: "t2", Version: ptrInt64(2), Deleted: ptrBool(false)},
+	}})
+	assert.NoError(t, err)
+	assert.Len(t, res.Applied, 2)
+	if assert.Len(t, res.Conflicts, 1) {
+		assert.Equal(t, "t2", res.Conflicts[0].ID)
+		assert.Equal(t, ReasonQuotaExceeded, res.Conflicts[0].Reason)
+	}
+	ir.AssertExpectations(t)
+}
+
+func TestItemService_Sync_CountErrorFailsBatch(t *testing.T) {
+	ir := new(mockItemRepo)
+	svc := NewItemService(ir, new(mockBlobRepo), nil, zap.NewNop().Sugar())
+	svc.SetQuota(Quota{MaxItems: 2})
+
+	ir.On("CountByUser", mock.Anything, int64(7)).Return(int64(0), errors.New("db")).Once()
+	_, err := svc.Sync(context.Background(), 7, SyncRequest{Changes: []SyncChange{{ID: "q1", Version: ptrInt64(0)}}})
+	assert.Error(t, err)
+}
+
+func TestItemService_Sync_ItemSizeQuota(t *testing.T) {
+	ir := new(mockItemRepo)
+	svc := NewItemService(ir, new(mockBlobRepo), nil, zap.NewNop().Sugar())
+	svc.SetQuota(Quota{MaxItemBytes: 10})
+	ctx := context.Background()
+
+	// новая запись больше лимита
+	ir.On("GetByID", mock.Anything, int64(7), "big").Return(nil, gorm.ErrRecordNotFound).Once()
+	// обновление: текущий пароль 6 байт + присланный текст 6 байт = 12 > 10
+	ir.On("GetByID", mock.Anything, int64(7), "upd").Return(&model.Item{ID: "upd", Version: 1, PasswordCipher: make([]byte, 6)}, nil).Once()
+	// замена пароля меньшим значением укладывается в лимит
+	ir.On("GetByID", mock.Anything, int64(7), "ok").Return(&model.Item{ID: "ok", Version: 1, PasswordCipher: make([]byte, 6)}, nil).Once()
+	ir.On("UpdateWithVersion", mock.Anything, int64(7), "ok", int64(1), mock.Anything).Return(int64(2), nil).Once()
+
+	res, err := svc.Sync(ctx, 7, SyncRequest{Changes: []SyncChange{
+		{ID: "big", Version: ptrInt64(0), TextCipher: make([]byte, 11)},
+		{ID: "upd", Version: ptrInt64(1), TextCipher: make([]byte, 6)},
+		{ID: "ok", Version: ptrInt64(1), PasswordCipher: make([]byte, 4), TextCipher: make([]byte, 6)},
+	}})
+	assert.NoError(t, err)
+	if assert.Len(t, res.Conflicts, 2) {
+		assert.Equal(t, ReasonItemTooLarge, res.Conflicts[0].Reason)
+		assert.Equal(t, ReasonItemTooLarge, res.Conflicts[1].Reason)
+	}
+	if assert.Len(t, res.Applied, 1) {
+		assert.Equal(t, "ok", res.Applied[0].ID)
+	}
+	ir.AssertExpectations(t)
+}
+
+func TestItemService_SaveBlob_Quota(t *testing.T) {
+	br := new(mockBlobRepo)
+	store := newMemBlobStore()
+	svc := NewItemService(new(mockItemRepo), br, store, zap.NewNop().Sugar())
+	svc.SetQuota(Quota{MaxBlobBytes: 10})
+	ctx := context.Background()
+
+	br.On("GetByID", mock.Anything, mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+	br.On("SumSizeByUser", mock.Anything, int64(7)).Return(int64(8), nil)
+
+	// 8 + 3 > 10 — отказ, в хранилище ничего не пишется
+	_, err := svc.SaveBlob(ctx, 7, "qb1", []byte{1, 2, 3}, []byte{1})
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+	assert.Empty(t, store.data)
+
+	// 8 + 2 == 10 — укладывается
+	br.On("CreateIfAbsent", mock.Anything, int64(7), "qb2", []byte{1}, int64(2)).Return(true, nil).Once()
+	created, err := svc.SaveBlob(ctx, 7, "qb2", []byte{1, 2}, []byte{1})
+	assert.NoError(t, err)
+	assert.True(t, created)
+}
+
+func TestItemService_Usage(t *testing.T) {
+	ir := new(mockItemRepo)
+	br := new(mockBlobRepo)
+	svc := NewItemService(ir, br, nil, zap.NewNop().Sugar())
+	q := Quota{MaxItems: 100, MaxBlobBytes: 1 << 20, MaxItemBytes: 4096}
+	svc.SetQuota(q)
+	ctx := context.Background()
+
+	ir.On("CountByUser", mock.Anything, int64(7)).Return(int64(3), nil).Once()
+	br.On("SumSizeByUser", mock.Anything, int64(7)).Return(int64(512), nil).Once()
+	u, err := svc.Usage(ctx, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, Usage{Items: 3, BlobBytes: 512, Quota: q}, u)
+
+	ir.On("CountByUser", mock.Anything, int64(8)).Return(int64(0), errors.New("db")).Once()
+	_, err = svc.Usage(ctx, 8)
+	assert.Error(t, err)
+}
+
+func Test_itemCipherSize(t *testing.T) {
+	cur := &model.Item{LoginCipher: make([]byte, 5), LoginNonce: make([]byte, 12)}
+	// без изменений — размер текущих полей
+	assert.Equal(t, int64(17), itemCipherSize(SyncChange{}, cur))
+	// заменённое поле учитывается по новому значению
+	assert.Equal(t, int64(14), itemCipherSize(SyncChange{LoginCipher: make([]byte, 2)}, cur))
+	// новая запись
+	assert.Equal(t, int64(3), itemCipherSize(SyncChange{CardCipher: make([]byte, 3)}, nil))
+}
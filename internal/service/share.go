@@ -0,0 +1,93 @@
+package service
+
+import (
+	"GophKeeper/internal/model"
+	"GophKeeper/internal/repo"
+	"GophKeeper/internal/telemetry"
+	"context"
+	"errors"
+)
+
+var (
+	// ErrShareWithSelf — попытка открыть запись самому себе.
+	ErrShareWithSelf = errors.New("cannot share an item with yourself")
+	// ErrShareFileItem — содержимое файлов не передаётся в долях.
+	ErrShareFileItem = errors.New("file items cannot be shared")
+)
+
+// SetShareRepository задаёт репозиторий общих записей (нужен для share).
+func (s *ItemService) SetShareRepository(r repo.ShareRepository) {
+	s.shares = r
+}
+
+// ShareItem открывает запись владельца получателю recipientLogin. share содержит поля,
+// зашифрованные ключом записи, и этот ключ, запечатанный открытым ключом получателя;
+// share.Version должна совпадать с текущей версией записи (иначе ErrVersionMismatch).
+func (s *ItemService) ShareItem(ctx context.Context, ownerID int64, itemID, recipientLogin string, share *model.ItemShare) (err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.ShareItem")
+	defer func() { telemetry.End(span, err) }()
+
+	if s.shares == nil {
+		return errors.New("share repository not configured")
+	}
+	it, err := s.GetItem(ctx, ownerID, itemID)
+	if err != nil {
+		return err
+	}
+	if it.BlobID != nil && *it.BlobID != "" {
+		return ErrShareFileItem
+	}
+	if share.Version != it.Version {
+		return ErrVersionMismatch
+	}
+	recipientID, _, err := s.shares.Recipient(ctx, recipientLogin)
+	if err != nil {
+		return err
+	}
+	if recipientID == ownerID {
+		return ErrShareWithSelf
+	}
+	share.ItemID, share.OwnerID, share.RecipientID = itemID, ownerID, recipientID
+	return s.shares.Upsert(ctx, share)
+}
+
+// RevokeShare закрывает получателю доступ к записи владельца.
+func (s *ItemService) RevokeShare(ctx context.Context, ownerID int64, itemID, recipientLogin string) (err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.RevokeShare")
+	defer func() { telemetry.End(span, err) }()
+
+	if s.shares == nil {
+		return errors.New("share repository not configured")
+	}
+	recipientID, _, err := s.shares.Recipient(ctx, recipientLogin)
+	if errors.Is(err, repo.ErrRecipientHasNoKey) || errors.Is(err, repo.ErrRecipientNotFound) {
+		return repo.ErrShareNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return s.shares.Delete(ctx, ownerID, itemID, recipientID)
+}
+
+// IncomingShares возвращает записи, открытые пользователю другими (только чтение).
+func (s *ItemService) IncomingShares(ctx context.Context, userID int64) (_ []model.IncomingShare, err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.IncomingShares")
+	defer func() { telemetry.End(span, err) }()
+
+	if s.shares == nil {
+		return nil, errors.New("share repository not configured")
+	}
+	return s.shares.ListIncoming(ctx, userID)
+}
+
+// OutgoingShares возвращает доли, выданные пользователем; доли с Version меньше
+// ItemVersion клиент владельца перешифровывает из текущей версии записи.
+func (s *ItemService) OutgoingShares(ctx context.Context, ownerID int64) (_ []model.OutgoingShare, err error) {
+	ctx, span := telemetry.Start(ctx, "ItemService.OutgoingShares")
+	defer func() { telemetry.End(span, err) }()
+
+	if s.shares == nil {
+		return nil, errors.New("share repository not configured")
+	}
+	return s.shares.ListOutgoing(ctx, ownerID)
+}
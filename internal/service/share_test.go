@@ -0,0 +1,115 @@
+package service
+
+import (
+	"GophKeeper/internal/model"
+	"GophKeeper/internal/repo"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type mockShareRepo struct{ mock.Mock }
+
+func (m *mockShareRepo) Recipient(ctx context.Context, login string) (int64, []byte, error) {
+	args := m.Called(ctx, login)
+	pub, _ := args.Get(1).([]byte)
+	return args.Get(0).(int64), pub, args.Error(2)
+}
+
+func (m *mockShareRepo) Upsert(ctx context.Context, s *model.ItemShare) error {
+	return m.Called(ctx, s).Error(0)
+}
+
+func (m *mockShareRepo) Delete(ctx context.Context, ownerID int64, itemID string, recipientID int64) error {
+	return m.Called(ctx, ownerID, itemID, recipientID).Error(0)
+}
+
+func (m *mockShareRepo) ListIncoming(ctx context.Context, recipientID int64) ([]model.IncomingShare, error) {
+	args := m.Called(ctx, recipientID)
+	out, _ := args.Get(0).([]model.IncomingShare)
+	return out, args.Error(1)
+}
+
+func (m *mockShareRepo) ListOutgoing(ctx context.Context, ownerID int64) ([]model.OutgoingShare, error) {
+	args := m.Called(ctx, ownerID)
+	out, _ := args.Get(0).([]model.OutgoingShare)
+	return out, args.Error(1)
+}
+
+var _ repo.ShareRepository = (*mockShareRepo)(nil)
+
+func TestItemService_ShareItem(t *testing.T) {
+	ctx := context.Background()
+	blob := "b1"
+
+	tests := []struct {
+		name    string
+		item    *model.Item
+		itemErr error
+		version int64
+		login   string
+		wantErr error
+		upsert  bool
+	}{
+		{name: "ok", item: &model.Item{ID: "i1", Version: 3}, version: 3, login: "bob", upsert: true},
+		{name: "запись изменилась", item: &model.Item{ID: "i1", Version: 4}, version: 3, login: "bob", wantErr: ErrVersionMismatch},
+		{name: "нет записи", itemErr: gorm.ErrRecordNotFound, version: 1, login: "bob", wantErr: ErrItemNotFound},
+		{name: "удалённая запись", item: &model.Item{ID: "i1", Version: 3, Deleted: true}, version: 3, login: "bob", wantErr: ErrItemNotFound},
+		{name: "файл", item: &model.Item{ID: "i1", Version: 3, BlobID: &blob}, version: 3, login: "bob", wantErr: ErrShareFileItem},
+		{name: "нет получателя", item: &model.Item{ID: "i1", Version: 3}, version: 3, login: "nobody", wantErr: repo.ErrRecipientNotFound},
+		{name: "самому себе", item: &model.Item{ID: "i1", Version: 3}, version: 3, login: "alice", wantErr: ErrShareWithSelf},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ir := new(mockItemRepo)
+			sr := new(mockShareRepo)
+			svc := NewItemService(ir, nil, nil, zap.NewNop().Sugar())
+			svc.SetShareRepository(sr)
+
+			ir.On("GetByID", mock.Anything, int64(1), "i1").Return(tt.item, tt.itemErr).Once()
+			sr.On("Recipient", mock.Anything, "bob").Return(int64(2), []byte("pub"), nil).Maybe()
+			sr.On("Recipient", mock.Anything, "alice").Return(int64(1), []byte("pub"), nil).Maybe()
+			sr.On("Recipient", mock.Anything, "nobody").Return(int64(0), nil, repo.ErrRecipientNotFound).Maybe()
+			if tt.upsert {
+				sr.On("Upsert", mock.Anything, mock.MatchedBy(func(s *model.ItemShare) bool {
+					return s.ItemID == "i1" && s.OwnerID == 1 && s.RecipientID == 2
+				})).Return(nil).Once()
+			}
+
+			err := svc.ShareItem(ctx, 1, "i1", tt.login, &model.ItemShare{Version: tt.version})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			sr.AssertExpectations(t)
+		})
+	}
+}
+
+func TestItemService_RevokeShare(t *testing.T) {
+	ctx := context.Background()
+	sr := new(mockShareRepo)
+	svc := NewItemService(new(mockItemRepo), nil, nil, zap.NewNop().Sugar())
+	svc.SetShareRepository(sr)
+
+	sr.On("Recipient", mock.Anything, "bob").Return(int64(2), []byte("pub"), nil).Once()
+	sr.On("Delete", mock.Anything, int64(1), "i1", int64(2)).Return(nil).Once()
+	assert.NoError(t, svc.RevokeShare(ctx, 1, "i1", "bob"))
+
+	sr.On("Recipient", mock.Anything, "carol").Return(int64(0), nil, repo.ErrRecipientHasNoKey).Once()
+	assert.ErrorIs(t, svc.RevokeShare(ctx, 1, "i1", "carol"), repo.ErrShareNotFound)
+	sr.AssertExpectations(t)
+}
+
+func TestItemService_SharesNotConfigured(t *testing.T) {
+	svc := NewItemService(new(mockItemRepo), nil, nil, zap.NewNop().Sugar())
+	_, err := svc.IncomingShares(context.Background(), 1)
+	assert.Error(t, err)
+	_, err = svc.OutgoingShares(context.Background(), 1)
+	assert.Error(t, err)
+}
@@ -3,25 +3,85 @@ package service
 import (
 	"GophKeeper/internal/model"
 	"GophKeeper/internal/repo"
+	"GophKeeper/internal/telemetry"
 	"context"
 	"errors"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
 type UserService struct {
-	repo repo.UserRepository
+	repo    repo.UserRepository
+	devices repo.DeviceRepository
+	keys    repo.KeyRepository
+	admin   repo.AdminRepository
 }
 
 var ErrLoginTaken = errors.New("login already in use")
 
+// ErrDeviceTaken — клиентский сертификат привязан к устройству другого пользователя.
+var ErrDeviceTaken = errors.New("client certificate is bound to another user")
+
+// ErrUserDisabled — учётная запись отключена администратором.
+var ErrUserDisabled = errors.New("user is disabled")
+
 // NewUserService создаёт сервис пользователей
 func NewUserService(repo repo.UserRepository) *UserService {
 	return &UserService{repo: repo}
 }
 
+// SetDeviceRepository задаёт репозиторий устройств (нужен при mTLS).
+func (s *UserService) SetDeviceRepository(r repo.DeviceRepository) {
+	s.devices = r
+}
+
+// SetAdminRepository задаёт репозиторий состояния пользователей: без него
+// отключение пользователей и отзыв сессий не проверяются.
+func (s *UserService) SetAdminRepository(r repo.AdminRepository) {
+	s.admin = r
+}
+
+// SessionActive сообщает, действителен ли токен пользователя, выданный в issuedAt:
+// пользователь существует, не отключён и его сессии не отзывались после выдачи.
+func (s *UserService) SessionActive(ctx context.Context, userID int64, issuedAt time.Time) (_ bool, err error) {
+	ctx, span := telemetry.Start(ctx, "UserService.SessionActive")
+	defer func() { telemetry.End(span, err) }()
+
+	if s.admin == nil {
+		return true, nil
+	}
+	st, err := s.admin.State(ctx, userID)
+	if errors.Is(err, repo.ErrUserNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return st.SessionActive(issuedAt), nil
+}
+
+// BindDevice привязывает клиентский сертификат (отпечаток и имя) к устройствам пользователя.
+// Повторный вход с того же устройства допустим; чужой сертификат — ErrDeviceTaken.
+func (s *UserService) BindDevice(ctx context.Context, userID int64, fingerprint, name string) (err error) {
+	ctx, span := telemetry.Start(ctx, "UserService.BindDevice")
+	defer func() { telemetry.End(span, err) }()
+
+	if s.devices == nil {
+		return errors.New("device repository not configured")
+	}
+	_, err = s.devices.Bind(ctx, userID, fingerprint, name)
+	if errors.Is(err, repo.ErrDeviceOwnedByOther) {
+		return ErrDeviceTaken
+	}
+	return err
+}
+
 // Register регистрирует нового пользователя
-func (s *UserService) Register(ctx context.Context, login, password string) (*model.User, error) {
+func (s *UserService) Register(ctx context.Context, login, password string) (_ *model.User, err error) {
+	ctx, span := telemetry.Start(ctx, "UserService.Register")
+	defer func() { telemetry.End(span, err) }()
+
 	existing, _ := s.repo.GetUserByLogin(ctx, login)
 	if existing != nil {
 		return nil, ErrLoginTaken
@@ -41,7 +101,10 @@ func (s *UserService) Register(ctx context.Context, login, password string) (*mo
 }
 
 // Login проверяет логин/пароль и возвращает пользователя
-func (s *UserService) Login(ctx context.Context, login, password string) (*model.User, error) {
+func (s *UserService) Login(ctx context.Context, login, password string) (_ *model.User, err error) {
+	ctx, span := telemetry.Start(ctx, "UserService.Login")
+	defer func() { telemetry.End(span, err) }()
+
 	user, err := s.repo.GetUserByLogin(ctx, login)
 	if err != nil {
 		return nil, err
@@ -50,6 +113,15 @@ func (s *UserService) Login(ctx context.Context, login, password string) (*model
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
 		return nil, errors.New("invalid credentials")
 	}
+	if s.admin != nil {
+		st, err := s.admin.State(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if st.Disabled {
+			return nil, ErrUserDisabled
+		}
+	}
 
 	return user, nil
 }
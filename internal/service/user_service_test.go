@@ -5,6 +5,7 @@ import (
 	"GophKeeper/internal/repo"
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -89,4 +90,88 @@ func TestUserService_Login(t *testing.T) {
 		assert.Error(t, err)
 		m.AssertExpectations(t)
 	})
+
+	t.Run("disabled user", func(t *testing.T) {
+		m.ExpectedCalls = nil
+		m.On("GetUserByLogin", mock.Anything, "alice").Return(&model.User{ID: 2, Login: "alice", Password: string(hash)}, nil).Once()
+		ar := new(mockAdminRepo)
+		ar.On("State", mock.Anything, int64(2)).Return(&model.UserState{UserID: 2, Disabled: true}, nil).Once()
+		svc := NewUserService(m)
+		svc.SetAdminRepository(ar)
+
+		user, err := svc.Login(ctx, "alice", "secret")
+		assert.Nil(t, user)
+		assert.ErrorIs(t, err, ErrUserDisabled)
+		m.AssertExpectations(t)
+	})
+}
+
+func TestUserService_SessionActive(t *testing.T) {
+	ctx := context.Background()
+	issued := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	revokedBefore, revokedAfter := issued.Add(-time.Minute), issued.Add(500*time.Millisecond)
+	revokedJustBefore := issued.Add(-300 * time.Millisecond)
+	tests := []struct {
+		name  string
+		state *model.UserState
+		err   error
+		want  bool
+	}{
+		{name: "без состояния", state: &model.UserState{UserID: 1}, want: true},
+		{name: "отключён", state: &model.UserState{UserID: 1, Disabled: true}},
+		{name: "отозваны до выдачи", state: &model.UserState{UserID: 1, SessionsRevokedAt: &revokedBefore}, want: true},
+		{name: "отозваны позже в ту же секунду", state: &model.UserState{UserID: 1, SessionsRevokedAt: &revokedAfter}},
+		{name: "выдан после отзыва в ту же секунду", state: &model.UserState{UserID: 1, SessionsRevokedAt: &revokedJustBefore}, want: true},
+		{name: "пользователь удалён", err: repo.ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ar := new(mockAdminRepo)
+			ar.On("State", mock.Anything, int64(1)).Return(tt.state, tt.err).Once()
+			svc := NewUserService(new(mockUserRepo))
+			svc.SetAdminRepository(ar)
+
+			ok, err := svc.SessionActive(ctx, 1, issued)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, ok)
+		})
+	}
+
+	// без репозитория состояния проверка не выполняется
+	ok, err := NewUserService(new(mockUserRepo)).SessionActive(ctx, 1, issued)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+// мок для repo.DeviceRepository
+type mockDeviceRepo struct{ mock.Mock }
+
+func (m *mockDeviceRepo) Bind(ctx context.Context, userID int64, fingerprint, name string) (*model.Device, error) {
+	args := m.Called(ctx, userID, fingerprint, name)
+	if d, ok := args.Get(0).(*model.Device); ok {
+		return d, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockDeviceRepo) ListByUser(ctx context.Context, userID int64) ([]model.Device, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]model.Device), args.Error(1)
+}
+
+var _ repo.DeviceRepository = (*mockDeviceRepo)(nil)
+
+func TestUserService_BindDevice(t *testing.T) {
+	ctx := context.Background()
+	svc := NewUserService(new(mockUserRepo))
+	assert.Error(t, svc.BindDevice(ctx, 1, "fp", "laptop"), "без репозитория устройств")
+
+	d := new(mockDeviceRepo)
+	svc.SetDeviceRepository(d)
+	d.On("Bind", mock.Anything, int64(1), "fp", "laptop").Return(&model.Device{ID: 1, UserID: 1}, nil).Once()
+	d.On("Bind", mock.Anything, int64(2), "fp", "laptop").Return(nil, repo.ErrDeviceOwnedByOther).Once()
+
+	assert.NoError(t, svc.BindDevice(ctx, 1, "fp", "laptop"))
+	assert.ErrorIs(t, svc.BindDevice(ctx, 2, "fp", "laptop"), ErrDeviceTaken)
+	d.AssertExpectations(t)
 }
@@ -0,0 +1,50 @@
+package tlsutil
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"os"
+)
+
+// EnableClientAuth включает проверку клиентских сертификатов, выпущенных CA из caFile.
+// Сертификат запрашивается, но не обязателен на уровне TLS: /healthz и /metrics доступны
+// без него, а авторизованные запросы требуют его через привязку токена (middleware.WithCertAuth).
+func EnableClientAuth(conf *tls.Config, caFile string) error {
+	pemBytes, err := os.ReadFile(caFile)
+	if err != nil {
+		return fmt.Errorf("read client CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pemBytes) {
+		return fmt.Errorf("client CA %s: no PEM certificates", caFile)
+	}
+	conf.ClientCAs = pool
+	conf.ClientAuth = tls.VerifyClientCertIfGiven
+	return nil
+}
+
+// Fingerprint — отпечаток сертификата: SHA-256 от DER в base64url без дополнения
+// (формат x5t#S256 из RFC 8705).
+func Fingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// PeerFingerprint — отпечаток проверенного клиентского сертификата соединения или "".
+func PeerFingerprint(cs *tls.ConnectionState) string {
+	if cs == nil || len(cs.PeerCertificates) == 0 {
+		return ""
+	}
+	return Fingerprint(cs.PeerCertificates[0])
+}
+
+// PeerName — CommonName клиентского сертификата соединения (имя устройства) или "".
+func PeerName(cs *tls.ConnectionState) string {
+	if cs == nil || len(cs.PeerCertificates) == 0 {
+		return ""
+	}
+	return cs.PeerCertificates[0].Subject.CommonName
+}
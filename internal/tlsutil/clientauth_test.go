@@ -0,0 +1,120 @@
+package tlsutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clientPair выпускает самоподписанный клиентский сертификат (он же CA для EnableClientAuth).
+func clientPair(t *testing.T, dir, cn string) (certFile, keyFile string, cert tls.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	certFile, keyFile = filepath.Join(dir, cn+".pem"), filepath.Join(dir, cn+".key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600))
+	cert, err = tls.LoadX509KeyPair(certFile, keyFile)
+	require.NoError(t, err)
+	return certFile, keyFile, cert
+}
+
+func TestEnableClientAuth(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := devPair(t, dir)
+	r, err := NewCertReloader(certFile, keyFile)
+	require.NoError(t, err)
+	caFile, _, laptop := clientPair(t, dir, "laptop")
+	_, _, stranger := clientPair(t, dir, "stranger")
+
+	conf := ServerConfig(r)
+	require.NoError(t, EnableClientAuth(conf, caFile))
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, PeerName(r.TLS)+" "+PeerFingerprint(r.TLS))
+		}),
+		TLSConfig: conf,
+		ErrorLog:  log.New(io.Discard, "", 0),
+	}
+	go func() { _ = srv.ServeTLS(ln, "", "") }()
+	defer srv.Close()
+	url := "https://" + ln.Addr().String()
+
+	pemBytes, err := os.ReadFile(certFile)
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(pemBytes))
+	get := func(certs ...tls.Certificate) (string, error) {
+		c := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certs}}}
+		resp, err := c.Get(url)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		return string(b), err
+	}
+
+	// без сертификата соединение разрешено, но отпечатка нет
+	body, err := get()
+	require.NoError(t, err)
+	assert.Equal(t, " ", body)
+
+	leaf, err := x509.ParseCertificate(laptop.Certificate[0])
+	require.NoError(t, err)
+	body, err = get(laptop)
+	require.NoError(t, err)
+	assert.Equal(t, "laptop "+Fingerprint(leaf), body)
+
+	// сертификат не от доверенного CA клиент не предъявляет: устройство не опознано
+	body, err = get(stranger)
+	require.NoError(t, err)
+	assert.Equal(t, " ", body)
+
+	assert.Error(t, EnableClientAuth(ServerConfig(r), filepath.Join(dir, "missing.pem")))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "empty.pem"), []byte("nothing"), 0o644))
+	assert.Error(t, EnableClientAuth(ServerConfig(r), filepath.Join(dir, "empty.pem")))
+}
+
+func TestFingerprint(t *testing.T) {
+	_, _, c := clientPair(t, t.TempDir(), "phone")
+	leaf, err := x509.ParseCertificate(c.Certificate[0])
+	require.NoError(t, err)
+	fp := Fingerprint(leaf)
+	assert.Len(t, fp, 43) // 32 байта SHA-256 в base64url без дополнения
+	assert.Equal(t, fp, PeerFingerprint(&tls.ConnectionState{PeerCertificates: []*x509.Certificate{leaf}}))
+	assert.Empty(t, PeerFingerprint(nil))
+	assert.Empty(t, PeerName(&tls.ConnectionState{}))
+}
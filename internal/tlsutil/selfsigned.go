@@ -0,0 +1,143 @@
+package tlsutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Файлы самоподписанного сертификата в каталоге dev-режима.
+const (
+	DevCertFile = "cert.pem"
+	DevKeyFile  = "key.pem"
+)
+
+// devCertValidity — срок действия самоподписанного сертификата; за devCertRenewBefore до
+// истечения он перевыпускается.
+const (
+	devCertValidity    = 365 * 24 * time.Hour
+	devCertRenewBefore = 30 * 24 * time.Hour
+)
+
+// EnsureSelfSigned возвращает пути к самоподписанному сертификату и ключу в dir.
+// Сертификат выпускается один раз и переиспользуется между запусками, чтобы клиенты,
+// доверившие ему, не получали новый; перевыпускается, если истекает или не покрывает hosts.
+func EnsureSelfSigned(dir string, hosts []string) (certFile, keyFile string, err error) {
+	certFile = filepath.Join(dir, DevCertFile)
+	keyFile = filepath.Join(dir, DevKeyFile)
+	if ok, err := devCertUsable(certFile, keyFile, hosts); err != nil {
+		return "", "", err
+	} else if ok {
+		return certFile, keyFile, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", "", fmt.Errorf("create TLS dir: %w", err)
+	}
+	certPEM, keyPEM, err := selfSigned(hosts, time.Now())
+	if err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		return "", "", fmt.Errorf("write TLS key: %w", err)
+	}
+	if err := os.WriteFile(certFile, certPEM, 0o644); err != nil {
+		return "", "", fmt.Errorf("write TLS certificate: %w", err)
+	}
+	return certFile, keyFile, nil
+}
+
+// devCertUsable сообщает, можно ли переиспользовать сохранённый сертификат.
+func devCertUsable(certFile, keyFile string, hosts []string) (bool, error) {
+	raw, err := os.ReadFile(certFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if _, err := os.Stat(keyFile); err != nil {
+		return false, nil
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return false, nil
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return false, nil
+	}
+	if time.Now().Add(devCertRenewBefore).After(cert.NotAfter) {
+		return false, nil
+	}
+	for _, h := range hosts {
+		if cert.VerifyHostname(h) != nil {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// selfSigned выпускает сертификат ECDSA P-256 для hosts (имена и IP-адреса).
+func selfSigned(hosts []string, now time.Time) (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"GophKeeper dev"}, CommonName: "GophKeeper dev"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(devCertValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else {
+			tmpl.DNSNames = append(tmpl.DNSNames, h)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
+
+// DevHosts — имена для самоподписанного сертификата: localhost, петлевые адреса и хост из addr.
+func DevHosts(addrs ...string) []string {
+	hosts := []string{"localhost", "127.0.0.1", "::1"}
+	seen := map[string]bool{"localhost": true, "127.0.0.1": true, "::1": true}
+	for _, a := range addrs {
+		h, _, err := net.SplitHostPort(a)
+		if err != nil || h == "" || seen[h] || h == "0.0.0.0" || h == "::" {
+			continue
+		}
+		seen[h] = true
+		hosts = append(hosts, h)
+	}
+	return hosts
+}
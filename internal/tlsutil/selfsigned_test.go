@@ -0,0 +1,72 @@
+package tlsutil
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseCert(t *testing.T, file string) *x509.Certificate {
+	t.Helper()
+	raw, err := os.ReadFile(file)
+	require.NoError(t, err)
+	block, _ := pem.Decode(raw)
+	require.NotNil(t, block)
+	c, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	return c
+}
+
+func TestEnsureSelfSigned_PersistsAndReuses(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, err := EnsureSelfSigned(dir, DevHosts("gk.local:8443"))
+	require.NoError(t, err)
+	c := parseCert(t, certFile)
+	for _, h := range []string{"localhost", "127.0.0.1", "::1", "gk.local"} {
+		assert.NoError(t, c.VerifyHostname(h), h)
+	}
+	st, err := os.Stat(keyFile)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), st.Mode().Perm())
+
+	// повторный запуск — тот же сертификат
+	_, _, err = EnsureSelfSigned(dir, DevHosts("gk.local:8443"))
+	require.NoError(t, err)
+	assert.Equal(t, c.SerialNumber, parseCert(t, certFile).SerialNumber)
+
+	// новый хост — перевыпуск
+	_, _, err = EnsureSelfSigned(dir, DevHosts("other.local:8443"))
+	require.NoError(t, err)
+	c2 := parseCert(t, certFile)
+	assert.NotEqual(t, c.SerialNumber, c2.SerialNumber)
+	assert.NoError(t, c2.VerifyHostname("other.local"))
+}
+
+func TestEnsureSelfSigned_RenewsExpiring(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, err := EnsureSelfSigned(dir, DevHosts())
+	require.NoError(t, err)
+
+	// подменяем сертификат почти истёкшим
+	certPEM, keyPEM, err := selfSigned(DevHosts(), time.Now().Add(-devCertValidity+24*time.Hour))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0o644))
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+	old := parseCert(t, certFile)
+
+	_, _, err = EnsureSelfSigned(dir, DevHosts())
+	require.NoError(t, err)
+	renewed := parseCert(t, certFile)
+	assert.NotEqual(t, old.SerialNumber, renewed.SerialNumber)
+	assert.True(t, renewed.NotAfter.After(time.Now().Add(devCertRenewBefore)))
+}
+
+func TestDevHosts(t *testing.T) {
+	assert.Equal(t, []string{"localhost", "127.0.0.1", "::1", "gk.example"},
+		DevHosts("gk.example:8081", "localhost:8082", "0.0.0.0:1", "bad"))
+}
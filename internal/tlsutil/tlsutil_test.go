@@ -0,0 +1,148 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func devPair(t *testing.T, dir string) (string, string) {
+	t.Helper()
+	certFile, keyFile, err := EnsureSelfSigned(dir, DevHosts())
+	require.NoError(t, err)
+	return certFile, keyFile
+}
+
+func leaf(t *testing.T, r *CertReloader) *x509.Certificate {
+	t.Helper()
+	c, err := r.GetCertificate(nil)
+	require.NoError(t, err)
+	x, err := x509.ParseCertificate(c.Certificate[0])
+	require.NoError(t, err)
+	return x
+}
+
+func TestCertReloader_Reload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := devPair(t, dir)
+	r, err := NewCertReloader(certFile, keyFile)
+	require.NoError(t, err)
+	first := leaf(t, r).SerialNumber
+
+	// новый сертификат на диске подхватывается только по Reload
+	require.NoError(t, os.Remove(certFile))
+	_, _ = devPair(t, dir)
+	assert.Equal(t, first, leaf(t, r).SerialNumber)
+	require.NoError(t, r.Reload())
+	second := leaf(t, r).SerialNumber
+	assert.NotEqual(t, first, second)
+
+	// битый файл — ошибка, прежний сертификат остаётся
+	require.NoError(t, os.WriteFile(certFile, []byte("garbage"), 0o644))
+	assert.Error(t, r.Reload())
+	assert.Equal(t, second, leaf(t, r).SerialNumber)
+
+	_, err = NewCertReloader(filepath.Join(dir, "missing.pem"), keyFile)
+	assert.Error(t, err)
+}
+
+func TestServerConfig_ModernTLSOnly(t *testing.T) {
+	certFile, keyFile := devPair(t, t.TempDir())
+	r, err := NewCertReloader(certFile, keyFile)
+	require.NoError(t, err)
+
+	// httptest.StartTLS подставляет свой сертификат, поэтому сервер поднимаем вручную
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}),
+		TLSConfig: ServerConfig(r),
+		ErrorLog:  log.New(io.Discard, "", 0),
+	}
+	go func() { _ = srv.ServeTLS(ln, "", "") }()
+	defer srv.Close()
+	url := "https://" + ln.Addr().String()
+
+	pem, err := os.ReadFile(certFile)
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(pem))
+
+	client := func(maxVersion uint16) *http.Client {
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, MaxVersion: maxVersion}}}
+	}
+	resp, err := client(0).Get(url)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, uint16(tls.VersionTLS13), resp.TLS.Version)
+
+	resp, err = client(tls.VersionTLS12).Get(url)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, uint16(tls.VersionTLS12), resp.TLS.Version)
+
+	_, err = client(tls.VersionTLS11).Get(url)
+	assert.Error(t, err, "TLS 1.1 отклоняется")
+}
+
+// Reload во время работы: новые соединения получают новый сертификат, открытые не рвутся.
+func TestServerConfig_ReloadKeepsConnections(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := devPair(t, dir)
+	r, err := NewCertReloader(certFile, keyFile)
+	require.NoError(t, err)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := &http.Server{
+		Handler:   http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}),
+		TLSConfig: ServerConfig(r),
+		ErrorLog:  log.New(io.Discard, "", 0),
+	}
+	go func() { _ = srv.ServeTLS(ln, "", "") }()
+	defer srv.Close()
+	url := "https://" + ln.Addr().String()
+
+	roots := x509.NewCertPool()
+	addRoot := func() {
+		pem, err := os.ReadFile(certFile)
+		require.NoError(t, err)
+		require.True(t, roots.AppendCertsFromPEM(pem))
+	}
+	addRoot()
+	get := func(c *http.Client) *x509.Certificate {
+		resp, err := c.Get(url)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		return resp.TLS.PeerCertificates[0]
+	}
+	newClient := func() *http.Client {
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+	}
+
+	old := newClient()
+	before := get(old)
+
+	require.NoError(t, os.Remove(certFile))
+	_, _ = devPair(t, dir)
+	addRoot()
+	require.NoError(t, r.Reload())
+
+	after := get(newClient())
+	assert.NotEqual(t, before.SerialNumber, after.SerialNumber)
+	// keep-alive соединение открыто до перезагрузки и продолжает работать
+	still := get(old)
+	assert.Equal(t, before.SerialNumber, still.SerialNumber)
+}